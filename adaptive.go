@@ -0,0 +1,220 @@
+package goSpider
+
+import (
+	"errors"
+	"golang.org/x/net/html"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// AdaptiveOptions configures ParallelRequestsAdaptive. The zero value is usable.
+type AdaptiveOptions struct {
+	MinWorkers    int                              // workers per domain never go below it; 1 if not positive
+	MaxWorkers    int                              // workers of all domains together; DefaultWorkers if not positive
+	Delay         time.Duration                    // delay before each request, see ParallelRequests
+	Window        int                              // results of a domain between adjustments; 10 if not positive
+	MaxErrorRate  float64                          // error rate of a window above which a domain slows down; 0.1 if not positive
+	TargetLatency time.Duration                    // mean latency of a window above which a domain slows down; none if zero
+	Cooldown      time.Duration                    // pause of a domain after a 429 or 503 response; 5s if not positive
+	OnAdjust      func(domain string, workers int) // called when the workers of a domain change, e.g. for metrics
+	Router        Router                           // picks the crawler of each request instead of crawlerFunc, if set
+}
+
+// adaptiveDomain is the state of the requests of a domain in ParallelRequestsAdaptive.
+type adaptiveDomain struct {
+	workers     int // requests allowed at once
+	active      int // requests running
+	results     int // results in the current window
+	errors      int
+	latency     time.Duration // total latency of the current window
+	pausedUntil time.Time
+}
+
+// adaptiveScheduler hands the requests to the workers of ParallelRequestsAdaptive, at most domain.workers at a time
+// per domain.
+type adaptiveScheduler struct {
+	options AdaptiveOptions
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []Request
+	domains map[string]*adaptiveDomain
+}
+
+// ParallelRequestsAdaptive is ParallelRequests with the number of workers tuned per domain of the search strings, so
+// a crawl runs as fast as each site allows without being blocked. With a Router, heterogeneous batches share the
+// workers, see ParallelRequestsWithRouter. Each domain starts with MinWorkers workers, gains one
+// after each Window of healthy results, and is halved when the error rate or the mean latency of a window is too high.
+// A 429 or 503 status, see StatusError, halves the workers of its domain at once and pauses it for the Cooldown.
+// Example:
+//
+//	fetcher := goSpider.NewHTTPFetcher(30 * time.Second)
+//	results, err := goSpider.ParallelRequestsAdaptive(requests, goSpider.AdaptiveOptions{
+//		MaxWorkers:    20,
+//		TargetLatency: 3 * time.Second,
+//		OnAdjust: func(domain string, workers int) {
+//			log.Printf("%s: %d workers", domain, workers)
+//		},
+//	}, fetcher.Fetch)
+func ParallelRequestsAdaptive(requests []Request, options AdaptiveOptions, crawlerFunc func(string) (*html.Node, error)) ([]PageSource, error) {
+	if options.MinWorkers <= 0 {
+		options.MinWorkers = 1
+	}
+	if options.MaxWorkers <= 0 {
+		options.MaxWorkers = DefaultWorkers
+	}
+	if options.MinWorkers > options.MaxWorkers {
+		options.MinWorkers = options.MaxWorkers
+	}
+	if options.Window <= 0 {
+		options.Window = 10
+	}
+	if options.MaxErrorRate <= 0 {
+		options.MaxErrorRate = 0.1
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = 5 * time.Second
+	}
+
+	s := &adaptiveScheduler{
+		options: options,
+		pending: append([]Request(nil), requests...),
+		domains: map[string]*adaptiveDomain{},
+	}
+	s.cond = sync.NewCond(&s.mu)
+	crawl := searchCrawler(crawlerFunc)
+	if options.Router != nil {
+		crawl = options.Router.crawl
+	}
+
+	resultCh := make(chan PageSource, len(requests))
+	var wg sync.WaitGroup
+	for i := 0; i < options.MaxWorkers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			for {
+				req, domain, ok := s.next()
+				if !ok {
+					return
+				}
+				log.Printf("Worker %d processing request: %s", workerID, req.SearchString)
+				time.Sleep(options.Delay)
+				start := time.Now()
+				pageSource, err := crawl(req)
+				s.done(domain, err, time.Since(start))
+				resultCh <- newPageSource(req, pageSource, err)
+			}
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	var results []PageSource
+	var errorOnApiRequests error
+	for result := range resultCh {
+		if result.Error != nil {
+			errorOnApiRequests = result.Error
+		}
+		results = append(results, result)
+	}
+	return results, errorOnApiRequests
+}
+
+// requestDomain returns the host of a search string that is a URL, or "" for the other search strings.
+func requestDomain(search string) string {
+	u, err := url.Parse(search)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// domain returns the state of a domain, creating it with the minimum of workers.
+func (s *adaptiveScheduler) domain(name string) *adaptiveDomain {
+	d, ok := s.domains[name]
+	if !ok {
+		d = &adaptiveDomain{workers: s.options.MinWorkers}
+		s.domains[name] = d
+	}
+	return d
+}
+
+// next waits for a pending request whose domain has a free worker and is not paused, and marks it active. It returns
+// false when no request is pending.
+func (s *adaptiveScheduler) next() (Request, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.pending) > 0 {
+		now := time.Now()
+		for i, req := range s.pending {
+			name := requestDomain(req.SearchString)
+			d := s.domain(name)
+			if d.active >= d.workers || now.Before(d.pausedUntil) {
+				continue
+			}
+			d.active++
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return req, name, true
+		}
+		s.cond.Wait()
+	}
+	return Request{}, "", false
+}
+
+// done records the outcome of a request of the domain, adjusts its workers and wakes the waiting workers.
+func (s *adaptiveScheduler) done(name string, err error, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.cond.Broadcast()
+
+	d := s.domain(name)
+	d.active--
+	var statusError *StatusError
+	if errors.As(err, &statusError) &&
+		(statusError.StatusCode == http.StatusTooManyRequests || statusError.StatusCode == http.StatusServiceUnavailable) {
+		d.pausedUntil = time.Now().Add(s.options.Cooldown)
+		time.AfterFunc(s.options.Cooldown, s.cond.Broadcast)
+		s.adjust(name, d, d.workers/2)
+		return
+	}
+
+	d.results++
+	d.latency += latency
+	if err != nil {
+		d.errors++
+	}
+	if d.results < s.options.Window {
+		return
+	}
+	errorRate := float64(d.errors) / float64(d.results)
+	meanLatency := d.latency / time.Duration(d.results)
+	if errorRate > s.options.MaxErrorRate || s.options.TargetLatency > 0 && meanLatency > s.options.TargetLatency {
+		s.adjust(name, d, d.workers/2)
+	} else {
+		s.adjust(name, d, d.workers+1)
+	}
+}
+
+// adjust sets the workers of the domain within the limits and starts a new window.
+func (s *adaptiveScheduler) adjust(name string, d *adaptiveDomain, workers int) {
+	if workers < s.options.MinWorkers {
+		workers = s.options.MinWorkers
+	}
+	if workers > s.options.MaxWorkers {
+		workers = s.options.MaxWorkers
+	}
+	d.results, d.errors, d.latency = 0, 0, 0
+	if workers == d.workers {
+		return
+	}
+	log.Printf("Adjusting workers of %q from %d to %d", name, d.workers, workers)
+	d.workers = workers
+	if s.options.OnAdjust != nil {
+		s.options.OnAdjust(name, workers)
+	}
+}
@@ -0,0 +1,84 @@
+package goSpider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestParallelRequestsAdaptive(t *testing.T) {
+	var mu sync.Mutex
+	active := map[string]int{}
+	peak := map[string]int{}
+	limited := 0
+	crawler := func(search string) (*html.Node, error) {
+		domain := requestDomain(search)
+		mu.Lock()
+		active[domain]++
+		if active[domain] > peak[domain] {
+			peak[domain] = active[domain]
+		}
+		// slow.example.com blocks more than 2 requests at once
+		blocked := domain == "slow.example.com" && active[domain] > 2
+		if blocked {
+			limited++
+		}
+		mu.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+		mu.Lock()
+		active[domain]--
+		mu.Unlock()
+		if blocked {
+			return nil, &StatusError{StatusCode: 429, URL: search}
+		}
+		return &html.Node{Type: html.TextNode, Data: search}, nil
+	}
+
+	var requests []Request
+	for i := 0; i < 60; i++ {
+		requests = append(requests,
+			Request{SearchString: fmt.Sprintf("https://fast.example.com/%d", i)},
+			Request{SearchString: fmt.Sprintf("https://slow.example.com/%d", i)})
+	}
+	var adjustments []string
+	results, err := ParallelRequestsAdaptive(requests, AdaptiveOptions{
+		MaxWorkers: 8,
+		Window:     5,
+		Cooldown:   20 * time.Millisecond,
+		OnAdjust: func(domain string, workers int) {
+			adjustments = append(adjustments, fmt.Sprintf("%s=%d", domain, workers))
+		},
+	}, crawler)
+
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, but got %d", len(requests), len(results))
+	}
+	var statusError *StatusError
+	if limited > 0 && !errors.As(err, &statusError) {
+		t.Errorf("Expected the last error to be returned, but got %v", err)
+	}
+	if peak["fast.example.com"] < 3 {
+		t.Errorf("Expected the workers of the healthy domain to grow, but the peak was %d: %v", peak["fast.example.com"],
+			adjustments)
+	}
+	if limited > 10 {
+		t.Errorf("Expected the rate limited domain to slow down, but it was limited %d times: %v", limited, adjustments)
+	}
+}
+
+func TestRequestDomain(t *testing.T) {
+	for search, expected := range map[string]string{
+		"https://esaj.tjsp.jus.br/cpopg/show.do?processo=1": "esaj.tjsp.jus.br",
+		"http://localhost:8080/page":                        "localhost",
+		"1017927-35.2023.8.26.0008":                         "",
+	} {
+		if domain := requestDomain(search); domain != expected {
+			t.Errorf("requestDomain(%q) = %q, expected %q", search, domain, expected)
+		}
+	}
+}
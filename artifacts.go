@@ -0,0 +1,330 @@
+package goSpider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArtifactManifestName is the name of the manifest in artifact bundles.
+const ArtifactManifestName = "manifest.json"
+
+// ArtifactFile describes a file of an artifact bundle.
+type ArtifactFile struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`
+	Modified time.Time `json:"modified"`
+}
+
+// ArtifactManifest lists the files of an artifact bundle. It is stored as the first entry of the bundle.
+type ArtifactManifest struct {
+	Job     string         `json:"job"`
+	Created time.Time      `json:"created"`
+	Files   []ArtifactFile `json:"files"`
+}
+
+// artifactSource is a file to bundle with its name in the bundle.
+type artifactSource struct {
+	path string
+	name string
+}
+
+// BundleArtifacts packages the outputs of a job, such as its results file, screenshots, HTML snapshots and logs, into
+// a zip or tar.gz archive, chosen by the extension of archivePath (".zip", ".tar.gz" or ".tgz"). Files are stored by
+// their base name and directories with their files under their base name, after a manifest listing the size and
+// SHA-256 of every file.
+// Example:
+//
+//	manifest, err := goSpider.BundleArtifacts("output/tjsp-2024-05-01.zip", "tjsp", "output/lawsuits.csv", "screenshots", "crawl.log")
+func BundleArtifacts(archivePath, job string, paths ...string) (*ArtifactManifest, error) {
+	format, err := archiveFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to create archive: %w", err)
+	}
+	manifest, err := WriteArtifacts(file, format, job, paths...)
+	closeErr := file.Close()
+	if err == nil && closeErr != nil {
+		err = fmt.Errorf("error - failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// WriteArtifacts writes the bundle of BundleArtifacts to w in format, "zip" or "tar.gz".
+func WriteArtifacts(w io.Writer, format, job string, paths ...string) (*ArtifactManifest, error) {
+	if format != "zip" && format != "tar.gz" {
+		return nil, fmt.Errorf("error - unsupported archive format: %s", format)
+	}
+	bundle, err := newArtifactBundle(job, paths)
+	if err != nil {
+		return nil, err
+	}
+	err = bundle.write(w, format)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to write archive: %w", err)
+	}
+	return bundle.manifest, nil
+}
+
+// ArtifactHandler serves the artifact bundles of the jobs whose outputs are in the subdirectories of dir: a request
+// for "<job>.zip" or "<job>.tar.gz" bundles the directory dir/<job>. Mount it under a prefix with http.StripPrefix.
+// Example:
+//
+//	http.Handle("/artifacts/", http.StripPrefix("/artifacts/", goSpider.ArtifactHandler("jobs")))
+func ArtifactHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		format, err := archiveFormat(name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		job := name[:strings.LastIndex(name, ".")]
+		if format == "tar.gz" {
+			job = strings.TrimSuffix(job, ".tar")
+		}
+		if job == "" || job == "." || job == ".." || strings.ContainsAny(job, `/\`) {
+			http.NotFound(w, r)
+			return
+		}
+		jobDir := filepath.Join(dir, job)
+		if info, err := os.Stat(jobDir); err != nil || !info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		bundle, err := newArtifactBundle(job, []string{jobDir})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		contentType := "application/zip"
+		if format == "tar.gz" {
+			contentType = "application/gzip"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		if r.Method == http.MethodHead {
+			return
+		}
+		err = bundle.write(w, format)
+		if err != nil {
+			// the response has started, so the error can only abort it
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
+
+// artifactBundle is a manifest with the files it lists.
+type artifactBundle struct {
+	manifest *ArtifactManifest
+	data     []byte // the encoded manifest
+	sources  []artifactSource
+}
+
+// newArtifactBundle describes the files of the paths.
+func newArtifactBundle(job string, paths []string) (*artifactBundle, error) {
+	sources, err := artifactSources(paths)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ArtifactManifest{Job: job, Created: time.Now().UTC(), Files: []ArtifactFile{}}
+	for _, source := range sources {
+		file, err := describeArtifact(source)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to encode manifest: %w", err)
+	}
+	return &artifactBundle{manifest: manifest, data: data, sources: sources}, nil
+}
+
+// write writes the bundle in format.
+func (b *artifactBundle) write(w io.Writer, format string) error {
+	if format == "zip" {
+		return b.writeZip(w)
+	}
+	return b.writeTarGz(w)
+}
+
+// archiveFormat returns the archive format of a file name.
+func archiveFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip", nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz", nil
+	}
+	return "", fmt.Errorf("error - unsupported archive format: %s", name)
+}
+
+// artifactSources returns the regular files of the paths, walking directories, with their names in the bundle.
+func artifactSources(paths []string) ([]artifactSource, error) {
+	var sources []artifactSource
+	seen := map[string]bool{}
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("error - failed to read artifact: %w", err)
+		}
+		base := filepath.Base(root)
+		if !info.IsDir() {
+			if !seen[base] {
+				seen[base] = true
+				sources = append(sources, artifactSource{path: root, name: base})
+			}
+			continue
+		}
+		var files []artifactSource
+		err = filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			relative, err := filepath.Rel(root, file)
+			if err != nil {
+				return err
+			}
+			name := path.Join(base, filepath.ToSlash(relative))
+			if !seen[name] {
+				seen[name] = true
+				files = append(files, artifactSource{path: file, name: name})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error - failed to read artifacts: %w", err)
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+		sources = append(sources, files...)
+	}
+	return sources, nil
+}
+
+// describeArtifact returns the manifest entry of a file.
+func describeArtifact(source artifactSource) (ArtifactFile, error) {
+	file, err := os.Open(source.path)
+	if err != nil {
+		return ArtifactFile{}, fmt.Errorf("error - failed to read artifact: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return ArtifactFile{}, fmt.Errorf("error - failed to read artifact: %w", err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return ArtifactFile{}, fmt.Errorf("error - failed to read artifact: %w", err)
+	}
+	return ArtifactFile{
+		Name:     source.name,
+		Size:     size,
+		SHA256:   hex.EncodeToString(hash.Sum(nil)),
+		Modified: info.ModTime().UTC(),
+	}, nil
+}
+
+// writeZip writes the manifest and the files as a zip archive.
+func (b *artifactBundle) writeZip(w io.Writer) error {
+	manifest := b.manifest
+	archive := zip.NewWriter(w)
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: ArtifactManifestName, Method: zip.Deflate,
+		Modified: manifest.Created})
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(b.data)
+	if err != nil {
+		return err
+	}
+	for i, source := range b.sources {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: source.name, Method: zip.Deflate,
+			Modified: manifest.Files[i].Modified})
+		if err != nil {
+			return err
+		}
+		err = copyArtifact(entry, source, manifest.Files[i].Size)
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// writeTarGz writes the manifest and the files as a gzip compressed tar archive.
+func (b *artifactBundle) writeTarGz(w io.Writer) error {
+	manifest := b.manifest
+	compressed := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressed)
+	err := archive.WriteHeader(&tar.Header{Name: ArtifactManifestName, Mode: 0644, Size: int64(len(b.data)),
+		ModTime: manifest.Created, Typeflag: tar.TypeReg})
+	if err != nil {
+		return err
+	}
+	_, err = archive.Write(b.data)
+	if err != nil {
+		return err
+	}
+	for i, source := range b.sources {
+		err = archive.WriteHeader(&tar.Header{Name: source.name, Mode: 0644, Size: manifest.Files[i].Size,
+			ModTime: manifest.Files[i].Modified, Typeflag: tar.TypeReg})
+		if err != nil {
+			return err
+		}
+		err = copyArtifact(archive, source, manifest.Files[i].Size)
+		if err != nil {
+			return err
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+// copyArtifact copies the size bytes of the file described in the manifest, so a file growing meanwhile, such as a
+// log, can't break the archive.
+func copyArtifact(w io.Writer, source artifactSource, size int64) error {
+	file, err := os.Open(source.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.CopyN(w, file, size)
+	return err
+}
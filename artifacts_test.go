@@ -0,0 +1,132 @@
+package goSpider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestArtifacts writes the outputs of a job in dir and returns their paths.
+func writeTestArtifacts(t *testing.T, dir string) []string {
+	files := map[string]string{
+		"lawsuits.csv":             "number\n1\n",
+		"screenshots/page-1.png":   "png",
+		"screenshots/a/page-0.png": "png0",
+		"crawl.log":                "started\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return []string{filepath.Join(dir, "lawsuits.csv"), filepath.Join(dir, "screenshots"), filepath.Join(dir, "crawl.log")}
+}
+
+func TestBundleArtifactsZip(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeTestArtifacts(t, dir)
+	archivePath := filepath.Join(t.TempDir(), "tjsp.zip")
+
+	manifest, err := BundleArtifacts(archivePath, "tjsp", paths...)
+	if err != nil {
+		t.Fatalf("BundleArtifacts error: %v", err)
+	}
+	var names []string
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+	}
+	want := []string{"lawsuits.csv", "screenshots/a/page-0.png", "screenshots/page-1.png", "crawl.log"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+	if manifest.Files[0].Size != 9 || manifest.Files[0].SHA256 == "" || manifest.Job != "tjsp" {
+		t.Errorf("Expected the size and hash of each file, got %+v", manifest)
+	}
+
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	defer archive.Close()
+	if len(archive.File) != 5 || archive.File[0].Name != ArtifactManifestName {
+		t.Fatalf("Expected the manifest and 4 files, got %d entries", len(archive.File))
+	}
+	reader, _ := archive.File[0].Open()
+	var stored ArtifactManifest
+	json.NewDecoder(reader).Decode(&stored)
+	reader.Close()
+	if len(stored.Files) != 4 || stored.Files[1].SHA256 != manifest.Files[1].SHA256 {
+		t.Errorf("Expected the manifest in the archive, got %+v", stored)
+	}
+	reader, _ = archive.File[1].Open()
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "number\n1\n" {
+		t.Errorf("Expected the file content, got %q", data)
+	}
+
+	if _, err := BundleArtifacts(filepath.Join(t.TempDir(), "tjsp.rar"), "tjsp", paths...); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestArtifactHandler(t *testing.T) {
+	jobs := t.TempDir()
+	writeTestArtifacts(t, filepath.Join(jobs, "tjsp"))
+	server := httptest.NewServer(http.StripPrefix("/artifacts/", ArtifactHandler(jobs)))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/artifacts/tjsp.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "application/gzip" {
+		t.Fatalf("Expected a tar.gz bundle, got %d %s", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(response.Body)
+	compressed, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Expected a gzip stream: %v", err)
+	}
+	archive := tar.NewReader(compressed)
+	var names []string
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected a tar archive: %v", err)
+		}
+		names = append(names, header.Name)
+	}
+	want := []string{ArtifactManifestName, "tjsp/crawl.log", "tjsp/lawsuits.csv", "tjsp/screenshots/a/page-0.png",
+		"tjsp/screenshots/page-1.png"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected entries %v, got %v", want, names)
+	}
+
+	for _, path := range []string{"/artifacts/missing.zip", "/artifacts/tjsp.rar", "/artifacts/..%2Ftjsp.zip"} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected %s to be not found, got %d", path, response.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"strings"
+	"sync"
+)
+
+// FailedAsset is a resource of the page that failed to load.
+type FailedAsset struct {
+	URL          string
+	ResourceType string
+	StatusCode   int64  // the HTTP status of responses with status 400 or above, otherwise 0
+	Error        string // the network error, e.g. net::ERR_NAME_NOT_RESOLVED
+}
+
+// PageAssets lists the missing assets of a page.
+type PageAssets struct {
+	URL          string
+	Failed       []FailedAsset
+	BrokenImages []string // sources of images that loaded without content
+}
+
+// assetTracker collects the failed resource loads of the current document.
+type assetTracker struct {
+	mu       sync.Mutex
+	requests map[network.RequestID]*network.Request
+	failed   []FailedAsset
+}
+
+// TrackAssets starts collecting the resources that fail to load, either with a network error or an HTTP status of 400
+// or above. The collection is reset on every navigation of the main frame, and read with CheckAssets. Requests blocked
+// with BlockResourceTypes are not reported.
+// Example:
+//
+//	err := nav.TrackAssets()
+//	err = nav.OpenURL("https://www.example.com")
+//	assets, err := nav.CheckAssets()
+func (nav *Navigator) TrackAssets() error {
+	if nav.assets != nil {
+		return nil
+	}
+	tracker := &assetTracker{
+		requests: map[network.RequestID]*network.Request{},
+	}
+
+	chromedp.ListenTarget(nav.Ctx, func(ev interface{}) {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				tracker.failed = nil
+			}
+		case *network.EventRequestWillBeSent:
+			tracker.requests[ev.RequestID] = ev.Request
+		case *network.EventResponseReceived:
+			if ev.Response.Status >= 400 {
+				tracker.failed = append(tracker.failed, FailedAsset{
+					URL:          ev.Response.URL,
+					ResourceType: ev.Type.String(),
+					StatusCode:   ev.Response.Status,
+				})
+			}
+		case *network.EventLoadingFailed:
+			request := tracker.requests[ev.RequestID]
+			delete(tracker.requests, ev.RequestID)
+			if request == nil || ev.Canceled || strings.Contains(ev.ErrorText, "ERR_BLOCKED_BY_CLIENT") {
+				return
+			}
+			tracker.failed = append(tracker.failed, FailedAsset{
+				URL:          request.URL,
+				ResourceType: ev.Type.String(),
+				Error:        ev.ErrorText,
+			})
+		case *network.EventLoadingFinished:
+			delete(tracker.requests, ev.RequestID)
+		}
+	})
+
+	err := chromedp.Run(nav.Ctx, network.Enable())
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to track assets: %v\n", err)
+		return fmt.Errorf("error - failed to track assets: %w", classifyError(err))
+	}
+	nav.assets = tracker
+	nav.Logger.Println("Tracking failed assets")
+	return nil
+}
+
+// CheckAssets returns the resources of the current page that failed to load since TrackAssets was called or the page
+// was opened, and the images that loaded without content (zero natural size). Images blocked with BlockResourceTypes
+// are reported as broken.
+// Example:
+//
+//	assets, err := nav.CheckAssets()
+//	for _, asset := range assets.Failed {
+//		fmt.Println(asset.URL, asset.StatusCode, asset.Error)
+//	}
+func (nav *Navigator) CheckAssets() (PageAssets, error) {
+	if nav.assets == nil {
+		return PageAssets{}, fmt.Errorf("error - assets are not tracked, call TrackAssets first")
+	}
+
+	var assets PageAssets
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Location(&assets.URL),
+		chromedp.Evaluate(`Array.from(document.images)
+			.filter(function(img) { return img.complete && img.getAttribute("src") && img.naturalWidth === 0; })
+			.map(function(img) { return img.currentSrc || img.src; })`, &assets.BrokenImages),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to check assets: %v\n", err)
+		return PageAssets{}, fmt.Errorf("error - failed to check assets: %w", classifyError(err))
+	}
+
+	nav.assets.mu.Lock()
+	assets.Failed = append([]FailedAsset(nil), nav.assets.failed...)
+	nav.assets.mu.Unlock()
+
+	nav.Logger.Printf("Found %d failed assets and %d broken images\n", len(assets.Failed), len(assets.BrokenImages))
+	return assets, nil
+}
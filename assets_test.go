@@ -0,0 +1,51 @@
+package goSpider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAssets(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	_, err := nav.CheckAssets()
+	if err == nil {
+		t.Errorf("Expected an error checking assets before tracking them")
+	}
+
+	err = nav.TrackAssets()
+	if err != nil {
+		t.Fatalf("TrackAssets error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/assets.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	assets, err := nav.CheckAssets()
+	if err != nil {
+		t.Fatalf("CheckAssets error: %v", err)
+	}
+	failed := map[string]int64{}
+	for _, asset := range assets.Failed {
+		failed[asset.URL[strings.LastIndex(asset.URL, "/")+1:]] = asset.StatusCode
+	}
+	if failed["missing.css"] != 404 || failed["missing.png"] != 404 || len(failed) != 2 {
+		t.Errorf("Expected the missing stylesheet and image, got %+v", assets.Failed)
+	}
+	if len(assets.BrokenImages) != 1 || !strings.HasSuffix(assets.BrokenImages[0], "/files/missing.png") {
+		t.Errorf("Expected the missing image to be broken, got %v", assets.BrokenImages)
+	}
+
+	// failures are reset when the page changes
+	err = nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	assets, err = nav.CheckAssets()
+	if err != nil || len(assets.Failed) != 0 {
+		t.Errorf("Expected no failed assets on the new page, got %+v: %v", assets.Failed, err)
+	}
+}
@@ -0,0 +1,156 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/chromedp"
+	"strings"
+)
+
+// AXNode is a node of the accessibility tree of a page, see GetAccessibilityTree.
+type AXNode struct {
+	Role        string                 // the ARIA role, e.g. "button", "link", "row" or "StaticText"
+	Name        string                 // the accessible name, e.g. the label of a button
+	Value       string                 // the value of a field
+	Description string                 // the accessible description
+	Properties  map[string]interface{} // states and attributes, e.g. "checked", "level", "disabled" or "url"
+	Children    []*AXNode
+}
+
+// GetAccessibilityTree returns the accessibility tree of the page: the roles, names and values the browser exposes to
+// screen readers, computed from the DOM, ARIA attributes and labels. It stays readable on pages whose markup is
+// obfuscated, and its nodes can be found with FindAll and turned into a selector with GetByRole.
+// Example:
+//
+//	tree, err := nav.GetAccessibilityTree()
+//	for _, row := range tree.FindAll("row", "") {
+//		for _, cell := range row.FindAll("cell", "") {
+//			fmt.Print(cell.Name, "\t")
+//		}
+//		fmt.Println()
+//	}
+func (nav *Navigator) GetAccessibilityTree() (*AXNode, error) {
+	var nodes []*accessibility.Node
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		nodes, err = accessibility.GetFullAXTree().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get accessibility tree: %v\n", err)
+		return nil, fmt.Errorf("error - failed to get accessibility tree: %w", classifyError(err))
+	}
+
+	tree := buildAXTree(nodes)
+	if tree == nil {
+		nav.Logger.Println("Error - Failed to get accessibility tree: empty tree")
+		return nil, withKind(ErrElementNotFound, fmt.Errorf("error - failed to get accessibility tree: empty tree"))
+	}
+	return tree, nil
+}
+
+// buildAXTree links the nodes returned by the browser into a tree. Ignored nodes, such as layout containers, are
+// replaced by their children, and inline text boxes, which repeat the text of their parent, are left out.
+func buildAXTree(nodes []*accessibility.Node) *AXNode {
+	byID := make(map[accessibility.NodeID]*accessibility.Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.NodeID] = node
+	}
+
+	var build func(node *accessibility.Node) []*AXNode
+	build = func(node *accessibility.Node) []*AXNode {
+		role := axString(node.Role)
+		if role == "InlineTextBox" {
+			return nil
+		}
+		var children []*AXNode
+		for _, id := range node.ChildIDs {
+			if child, ok := byID[id]; ok {
+				children = append(children, build(child)...)
+			}
+		}
+		if node.Ignored {
+			return children
+		}
+
+		ax := &AXNode{
+			Role:        role,
+			Name:        axString(node.Name),
+			Value:       axString(node.Value),
+			Description: axString(node.Description),
+			Children:    children,
+		}
+		for _, property := range node.Properties {
+			if property.Value == nil {
+				continue
+			}
+			var value interface{}
+			if json.Unmarshal(property.Value.Value, &value) != nil {
+				continue
+			}
+			if ax.Properties == nil {
+				ax.Properties = make(map[string]interface{})
+			}
+			ax.Properties[string(property.Name)] = value
+		}
+		return []*AXNode{ax}
+	}
+
+	for _, node := range nodes {
+		if _, ok := byID[node.ParentID]; node.ParentID == "" || !ok {
+			roots := build(node)
+			if len(roots) == 1 {
+				return roots[0]
+			}
+			return &AXNode{Role: "RootWebArea", Children: roots}
+		}
+	}
+	return nil
+}
+
+// axString returns an accessibility value as text.
+func axString(value *accessibility.Value) string {
+	if value == nil || len(value.Value) == 0 {
+		return ""
+	}
+	var v interface{}
+	if json.Unmarshal(value.Value, &v) != nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// FindAll returns the nodes under n, n included, with the given role and accessible name, in document order. An empty
+// role or name matches any.
+// Example:
+//
+//	links := tree.FindAll("link", "")
+func (n *AXNode) FindAll(role, name string) []*AXNode {
+	var found []*AXNode
+	if (role == "" || n.Role == role) && (name == "" || n.Name == name) {
+		found = append(found, n)
+	}
+	for _, child := range n.Children {
+		found = append(found, child.FindAll(role, name)...)
+	}
+	return found
+}
+
+// Text returns the accessible names of the text under n, joined by spaces.
+// Example:
+//
+//	text := tree.FindAll("dialog", "")[0].Text()
+func (n *AXNode) Text() string {
+	var text []string
+	for _, node := range n.FindAll("StaticText", "") {
+		if node.Name != "" {
+			text = append(text, node.Name)
+		}
+	}
+	return strings.Join(text, " ")
+}
@@ -0,0 +1,80 @@
+package goSpider
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/mailru/easyjson"
+)
+
+func axValue(value string) *accessibility.Value {
+	return &accessibility.Value{Type: accessibility.ValueTypeString, Value: easyjson.RawMessage(value)}
+}
+
+func TestBuildAXTree(t *testing.T) {
+	nodes := []*accessibility.Node{
+		{NodeID: "1", Role: axValue(`"RootWebArea"`), Name: axValue(`"Consulta"`), ChildIDs: []accessibility.NodeID{"2"}},
+		{NodeID: "2", ParentID: "1", Ignored: true, Role: axValue(`"none"`), ChildIDs: []accessibility.NodeID{"3", "5"}},
+		{NodeID: "3", ParentID: "2", Role: axValue(`"button"`), Name: axValue(`"Pesquisar"`),
+			ChildIDs: []accessibility.NodeID{"4"},
+			Properties: []*accessibility.Property{
+				{Name: accessibility.PropertyNameFocusable, Value: &accessibility.Value{Value: easyjson.RawMessage(`true`)}},
+			}},
+		{NodeID: "4", ParentID: "3", Role: axValue(`"StaticText"`), Name: axValue(`"Pesquisar"`),
+			ChildIDs: []accessibility.NodeID{"6"}},
+		{NodeID: "6", ParentID: "4", Role: axValue(`"InlineTextBox"`), Name: axValue(`"Pesquisar"`)},
+		{NodeID: "5", ParentID: "2", Role: axValue(`"heading"`), Name: axValue(`"Processos"`),
+			Properties: []*accessibility.Property{
+				{Name: accessibility.PropertyNameLevel, Value: &accessibility.Value{Value: easyjson.RawMessage(`2`)}},
+			}},
+	}
+
+	tree := buildAXTree(nodes)
+	if tree == nil || tree.Role != "RootWebArea" || tree.Name != "Consulta" {
+		t.Fatalf("Unexpected root: %+v", tree)
+	}
+	if len(tree.Children) != 2 {
+		t.Fatalf("Expected the children of the ignored node to be hoisted, but got %d children", len(tree.Children))
+	}
+	button := tree.Children[0]
+	if button.Role != "button" || button.Properties["focusable"] != true {
+		t.Errorf("Unexpected button: %+v", button)
+	}
+	if len(button.Children) != 1 || len(button.Children[0].Children) != 0 {
+		t.Errorf("Expected the inline text boxes to be left out, but got %+v", button.Children)
+	}
+	if headings := tree.FindAll("heading", ""); len(headings) != 1 || headings[0].Properties["level"] != float64(2) {
+		t.Errorf("Unexpected headings: %+v", headings)
+	}
+	if buttons := tree.FindAll("button", "Pesquisar"); len(buttons) != 1 {
+		t.Errorf("Expected one button, but got %d", len(buttons))
+	}
+	if text := tree.Text(); text != "Pesquisar" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+	if buildAXTree(nil) != nil {
+		t.Error("Expected no tree without nodes")
+	}
+}
+
+func TestGetAccessibilityTree(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/elements.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	tree, err := nav.GetAccessibilityTree()
+	if err != nil {
+		t.Fatalf("GetAccessibilityTree error: %v", err)
+	}
+	if tree.Role != "RootWebArea" {
+		t.Errorf("Expected the root to be the page, but got %q", tree.Role)
+	}
+	if len(tree.FindAll("button", "")) == 0 {
+		t.Error("Expected the buttons of the page in the tree")
+	}
+}
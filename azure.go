@@ -0,0 +1,193 @@
+package goSpider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// azureStorageVersion is the Blob service API version of the requests, which supports OAuth tokens.
+const azureStorageVersion = "2021-08-06"
+
+// AzureBlobStorage is a Storage that keeps the files as block blobs of an Azure Storage container. Requests are
+// authorized with a shared access signature (SAS) when SASToken is set, or else with an access token of the managed
+// identity of the Azure VM, container or function running the crawler. It is safe for concurrent use.
+type AzureBlobStorage struct {
+	ContainerURL     string // e.g. "https://account.blob.core.windows.net/crawls"
+	SASToken         string // the SAS query string, e.g. "sv=2022-11-02&ss=b&sig=..."
+	ClientID         string // the client id of a user-assigned managed identity, the system-assigned one if empty
+	IdentityEndpoint string // the managed identity token endpoint, the Azure Instance Metadata Service by default
+	Client           *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewAzureBlobStorage creates an AzureBlobStorage in the container, authorized with the SAS token, or with the
+// managed identity when sasToken is empty.
+// Example:
+//
+//	storage := goSpider.NewAzureBlobStorage("https://account.blob.core.windows.net/crawls", os.Getenv("AZURE_SAS_TOKEN"))
+//	monitor.Storage = storage
+func NewAzureBlobStorage(containerURL, sasToken string) *AzureBlobStorage {
+	return &AzureBlobStorage{
+		ContainerURL:     strings.TrimRight(containerURL, "/"),
+		SASToken:         strings.TrimPrefix(sasToken, "?"),
+		IdentityEndpoint: "http://169.254.169.254/metadata/identity/oauth2/token",
+		Client:           &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Put uploads data as the blob of key with the content type.
+func (s *AzureBlobStorage) Put(key string, data []byte, contentType string) error {
+	header := http.Header{"X-Ms-Blob-Type": {"BlockBlob"}}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	response, err := s.do(http.MethodPut, key, data, header)
+	if err != nil {
+		return fmt.Errorf("error - failed to put %s: %w", key, err)
+	}
+	response.Body.Close()
+	return nil
+}
+
+// Get downloads the blob of key.
+func (s *AzureBlobStorage) Get(key string) ([]byte, error) {
+	response, err := s.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to get %s: %w", key, err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Exists reports whether the blob of key exists.
+func (s *AzureBlobStorage) Exists(key string) (bool, error) {
+	response, err := s.do(http.MethodHead, key, nil, nil)
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error - failed to stat %s: %w", key, err)
+	}
+	response.Body.Close()
+	return true, nil
+}
+
+// do sends an authorized request for the blob of key. Responses with a status other than 2xx return a StatusError.
+func (s *AzureBlobStorage) do(method, key string, data []byte, header http.Header) (*http.Response, error) {
+	blobURL, err := s.blobURL(key)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(method, blobURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to create request: %w", err)
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	request.Header.Set("X-Ms-Version", azureStorageVersion)
+	if s.SASToken == "" {
+		token, err := s.identityToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := s.client().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return nil, &StatusError{StatusCode: response.StatusCode, URL: strings.SplitN(blobURL, "?", 2)[0]}
+	}
+	return response, nil
+}
+
+// blobURL returns the URL of the blob of key, with the SAS token. Keys can't escape the container.
+func (s *AzureBlobStorage) blobURL(key string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(key, "/") {
+		if segment != "" && segment != "." && segment != ".." {
+			segments = append(segments, url.PathEscape(segment))
+		}
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("error - invalid storage key: %s", key)
+	}
+	blobURL := strings.TrimRight(s.ContainerURL, "/") + "/" + strings.Join(segments, "/")
+	if s.SASToken != "" {
+		blobURL += "?" + strings.TrimPrefix(s.SASToken, "?")
+	}
+	return blobURL, nil
+}
+
+// identityToken returns an access token of the managed identity for Azure Storage, cached until shortly before it
+// expires.
+func (s *AzureBlobStorage) identityToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expires) {
+		return s.token, nil
+	}
+
+	endpoint := s.IdentityEndpoint
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+	}
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {"https://storage.azure.com/"}}
+	if s.ClientID != "" {
+		query.Set("client_id", s.ClientID)
+	}
+	request, err := http.NewRequest(http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("error - failed to create request: %w", err)
+	}
+	request.Header.Set("Metadata", "true")
+	response, err := s.client().Do(request)
+	if err != nil {
+		return "", fmt.Errorf("error - failed to request managed identity token: %w", err)
+	}
+	defer response.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresOn   string `json:"expires_on"`
+		Error       string `json:"error_description"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil || response.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("error - failed to get managed identity token: status %d %s", response.StatusCode, token.Error)
+	}
+
+	s.token = token.AccessToken
+	// renew five minutes before the token expires
+	s.expires = time.Now().Add(time.Hour)
+	if expiresOn, err := strconv.ParseInt(token.ExpiresOn, 10, 64); err == nil {
+		s.expires = time.Unix(expiresOn, 0)
+	}
+	s.expires = s.expires.Add(-5 * time.Minute)
+	return s.token, nil
+}
+
+func (s *AzureBlobStorage) client() *http.Client {
+	if s.Client == nil {
+		return http.DefaultClient
+	}
+	return s.Client
+}
@@ -0,0 +1,112 @@
+package goSpider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestBlobServer serves a container at /crawls, accepting requests for which authorized returns true.
+func newTestBlobServer(blobs map[string]string, authorized func(r *http.Request) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) || r.Header.Get("X-Ms-Version") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/crawls/")
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("X-Ms-Blob-Type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			blobs[name] = r.Header.Get("Content-Type") + ":" + string(data)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet, http.MethodHead:
+			blob, ok := blobs[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			io.WriteString(w, blob[strings.Index(blob, ":")+1:])
+		}
+	}))
+}
+
+func TestAzureBlobStorageSAS(t *testing.T) {
+	blobs := map[string]string{}
+	server := newTestBlobServer(blobs, func(r *http.Request) bool {
+		return r.URL.Query().Get("sig") == "secret" && r.Header.Get("Authorization") == ""
+	})
+	defer server.Close()
+
+	storage := NewAzureBlobStorage(server.URL+"/crawls/", "?sv=2022-11-02&sig=secret")
+	err := storage.Put("snapshots/a b.txt", []byte("data"), "text/plain")
+	if err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+	if blobs["snapshots/a b.txt"] != "text/plain:data" {
+		t.Errorf("Expected the blob with its content type, got %v", blobs)
+	}
+	data, err := storage.Get("snapshots/a b.txt")
+	if err != nil || string(data) != "data" {
+		t.Errorf("Expected the stored data, got %q: %v", data, err)
+	}
+	exists, err := storage.Exists("snapshots/a b.txt")
+	if err != nil || !exists {
+		t.Errorf("Expected the blob to exist: %v", err)
+	}
+	exists, err = storage.Exists("snapshots/missing.txt")
+	if err != nil || exists {
+		t.Errorf("Expected the blob not to exist: %v", err)
+	}
+
+	// keys can't escape the container
+	err = storage.Put("../escaped.txt", []byte("data"), "")
+	if err != nil || blobs["escaped.txt"] == "" {
+		t.Errorf("Expected the blob inside the container, got %v: %v", blobs, err)
+	}
+	if err := storage.Put("", nil, ""); err == nil {
+		t.Errorf("Expected an error for an empty key")
+	}
+	_, err = NewAzureBlobStorage(server.URL+"/crawls", "sig=wrong").Get("snapshots/a b.txt")
+	if err == nil || strings.Contains(err.Error(), "wrong") {
+		t.Errorf("Expected a forbidden error without the SAS token, got %v", err)
+	}
+}
+
+func TestAzureBlobStorageManagedIdentity(t *testing.T) {
+	tokens := 0
+	identity := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != "https://storage.azure.com/" ||
+			r.URL.Query().Get("client_id") != "identity-id" {
+			http.Error(w, `{"error_description":"invalid request"}`, http.StatusBadRequest)
+			return
+		}
+		tokens++
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_on":"%d"}`, tokens, time.Now().Add(time.Hour).Unix())
+	}))
+	defer identity.Close()
+	blobs := map[string]string{}
+	server := newTestBlobServer(blobs, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer token-1"
+	})
+	defer server.Close()
+
+	storage := NewAzureBlobStorage(server.URL+"/crawls", "")
+	storage.IdentityEndpoint = identity.URL
+	storage.ClientID = "identity-id"
+	err := storage.Put("a.txt", []byte("data"), "text/plain")
+	if err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+	data, err := storage.Get("a.txt")
+	if err != nil || string(data) != "data" || tokens != 1 {
+		t.Errorf("Expected the stored data with a cached token, got %q after %d tokens: %v", data, tokens, err)
+	}
+}
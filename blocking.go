@@ -0,0 +1,109 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// ResourceType is the type of resource a page requests, as reported by Chrome.
+type ResourceType = network.ResourceType
+
+// Resource types that can be blocked with BlockResourceTypes, or affected by EnableChaos.
+const (
+	Document   ResourceType = network.ResourceTypeDocument
+	Image      ResourceType = network.ResourceTypeImage
+	Font       ResourceType = network.ResourceTypeFont
+	Media      ResourceType = network.ResourceTypeMedia
+	Stylesheet ResourceType = network.ResourceTypeStylesheet
+	Script     ResourceType = network.ResourceTypeScript
+	XHR        ResourceType = network.ResourceTypeXHR
+	Ping       ResourceType = network.ResourceTypePing
+)
+
+// FastScrape blocks the resources that are not needed to extract data from the DOM: images, fonts, media and stylesheets.
+// Scripts are kept, so pages rendered by JavaScript still work. Elements keep their layout boxes without stylesheets,
+// but visibility checks that depend on css may change.
+var FastScrape = []ResourceType{Image, Font, Media, Stylesheet}
+
+// Analytics are the URL patterns of common analytics, tag manager and ad tracking services, to be blocked with
+// BlockURLs.
+var Analytics = []string{
+	"*google-analytics.com*",
+	"*googletagmanager.com*",
+	"*doubleclick.net*",
+	"*googlesyndication.com*",
+	"*connect.facebook.net*",
+	"*hotjar.com*",
+	"*clarity.ms*",
+	"*scorecardresearch.com*",
+	"*nr-data.net*",
+	"*segment.io*",
+	"*mixpanel.com*",
+}
+
+// BlockResourceTypes makes the browser fail every request for the given resource types, through Fetch interception,
+// which cuts load time and bandwidth on heavy pages without affecting DOM extraction. Each call replaces the types
+// blocked before; calling it without types stops blocking. It combines with RestrictNavigation, EnableChaos and
+// cassettes, which share Fetch interception: blocked requests never reach chaos or the cassette.
+// Example:
+//
+//	err := nav.BlockResourceTypes(goSpider.Image, goSpider.Font, goSpider.Media, goSpider.Stylesheet)
+//	err = nav.BlockResourceTypes(goSpider.FastScrape...)
+func (nav *Navigator) BlockResourceTypes(types ...ResourceType) error {
+	var rule *interceptRule
+	if len(types) > 0 {
+		rule = &interceptRule{types: types, handle: func(paused *fetch.EventRequestPaused) func(ctx context.Context) bool {
+			return func(ctx context.Context) bool {
+				_ = chromedp.Run(ctx, fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient))
+				return true
+			}
+		}}
+	}
+	err := nav.setInterceptRule(interceptBlocking, rule)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to block resources: %v\n", err)
+		return fmt.Errorf("error - failed to block resources: %w", classifyError(err))
+	}
+	if rule == nil {
+		nav.Logger.Printf("Stopped blocking resources\n")
+		return nil
+	}
+	nav.Logger.Printf("Blocking resource types: %v\n", types)
+	return nil
+}
+
+// BlockURLs makes the browser fail every request whose URL matches one of the patterns, where "*" matches any
+// characters, such as the analytics and ad services of Analytics. Each call replaces the patterns blocked before;
+// calling it without patterns stops blocking. It uses the Network domain, so it combines with BlockResourceTypes and
+// the other Fetch interceptions.
+// Example:
+//
+//	err := nav.BlockURLs(append(goSpider.Analytics, "*.woff2", "*/banners/*")...)
+func (nav *Navigator) BlockURLs(patterns ...string) error {
+	if patterns == nil {
+		patterns = []string{}
+	}
+	err := chromedp.Run(nav.Ctx, network.Enable(), network.SetBlockedURLS(patterns))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to block URLs: %v\n", err)
+		return fmt.Errorf("error - failed to block URLs: %w", classifyError(err))
+	}
+	if len(patterns) == 0 {
+		nav.Logger.Printf("Stopped blocking URLs\n")
+		return nil
+	}
+	nav.Logger.Printf("Blocking URLs: %v\n", patterns)
+	return nil
+}
+
+// resourcePatterns returns the Fetch patterns that intercept every request of the given resource types.
+func resourcePatterns(types []ResourceType) []*fetch.RequestPattern {
+	patterns := make([]*fetch.RequestPattern, 0, len(types))
+	for _, resourceType := range types {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*", ResourceType: resourceType})
+	}
+	return patterns
+}
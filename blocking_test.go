@@ -0,0 +1,95 @@
+package goSpider
+
+import (
+	"testing"
+)
+
+func TestResourcePatterns(t *testing.T) {
+	patterns := resourcePatterns(FastScrape)
+	if len(patterns) != 4 {
+		t.Fatalf("Expected 4 patterns, got %d", len(patterns))
+	}
+	for i, pattern := range patterns {
+		if pattern.URLPattern != "*" || pattern.ResourceType != FastScrape[i] {
+			t.Errorf("Unexpected pattern %d: %+v", i, pattern)
+		}
+	}
+}
+
+func TestBlockResourceTypes(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+
+	err := nav.BlockResourceTypes(FastScrape...)
+	if err != nil {
+		t.Fatalf("BlockResourceTypes error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/images.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	text, err := nav.GetElement("#caption")
+	if err != nil || text != "Photo caption" {
+		t.Errorf("Expected the DOM to be extracted, got %q: %v", text, err)
+	}
+	width, err := nav.EvaluateScript(`document.getElementById("photo").naturalWidth`)
+	if err != nil || width != float64(0) {
+		t.Errorf("Expected the image to be blocked, got width %v: %v", width, err)
+	}
+	style, err := nav.GetComputedStyle("#title", "color")
+	if err != nil || style["color"] == "rgb(255, 0, 0)" {
+		t.Errorf("Expected the stylesheet to be blocked, got %v: %v", style, err)
+	}
+
+	err = nav.BlockResourceTypes()
+	if err != nil {
+		t.Fatalf("BlockResourceTypes error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/images.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	width, err = nav.EvaluateScript(`document.getElementById("photo").naturalWidth`)
+	if err != nil || width != float64(1) {
+		t.Errorf("Expected the image to load after unblocking, got width %v: %v", width, err)
+	}
+}
+
+func TestBlockURLs(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.BlockURLs(append(Analytics, "*/pixel.png")...)
+	if err != nil {
+		t.Fatalf("BlockURLs error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/images.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	width, err := nav.EvaluateScript(`document.getElementById("photo").naturalWidth`)
+	if err != nil || width != float64(0) {
+		t.Errorf("Expected the image to be blocked, got width %v: %v", width, err)
+	}
+	style, err := nav.GetComputedStyle("#title", "color")
+	if err != nil || style["color"] != "rgb(255, 0, 0)" {
+		t.Errorf("Expected the stylesheet not to be blocked, got %v: %v", style, err)
+	}
+
+	err = nav.BlockURLs()
+	if err != nil {
+		t.Fatalf("BlockURLs error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/images.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	width, err = nav.EvaluateScript(`document.getElementById("photo").naturalWidth`)
+	if err != nil || width != float64(1) {
+		t.Errorf("Expected the image to load after unblocking, got width %v: %v", width, err)
+	}
+}
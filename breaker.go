@@ -0,0 +1,173 @@
+package goSpider
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"log"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit of a domain in a CircuitBreaker.
+type CircuitState int
+
+// States of a circuit.
+const (
+	CircuitClosed   CircuitState = iota // requests pass
+	CircuitOpen                         // requests fail with ErrCircuitOpen until the cooldown ends
+	CircuitHalfOpen                     // a single probe request passes; its outcome closes or reopens the circuit
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// circuit is the state of a domain in a CircuitBreaker.
+type circuit struct {
+	state    CircuitState
+	failures int // consecutive failures
+	openedAt time.Time
+	probing  bool // a half-open probe is running
+}
+
+// CircuitBreaker stops the requests to a domain after consecutive failures, such as blocks or CAPTCHA detections, so a
+// run doesn't burn its time on a blocked host while the other domains continue. After Threshold consecutive failures
+// the circuit of the domain opens and its requests fail with ErrCircuitOpen for the Cooldown; then a single probe
+// request is let through, which closes the circuit if it succeeds or opens it again if it fails. It is safe for
+// concurrent use.
+type CircuitBreaker struct {
+	Threshold     int                                     // consecutive failures that open a circuit; 5 if not positive
+	Cooldown      time.Duration                           // time a circuit stays open; 1 minute if not positive
+	IsFailure     func(err error) bool                    // errors that count as failures; every error if nil
+	OnStateChange func(domain string, state CircuitState) // called when the circuit of a domain changes, e.g. to alert
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreaker creates a CircuitBreaker opening the circuit of a domain after threshold consecutive failures for
+// cooldown.
+// Example:
+//
+//	breaker := goSpider.NewCircuitBreaker(5, 10*time.Minute)
+//	breaker.IsFailure = func(err error) bool {
+//		return errors.Is(err, goSpider.ErrCaptchaDetected) || errors.Is(err, goSpider.ErrNavigationFailed)
+//	}
+//	results, err := goSpider.ParallelRequests(requests, 10, 0, breaker.Wrap(fetcher.Fetch))
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// Wrap returns a crawler that fails with ErrCircuitOpen, without calling crawler, while the circuit of the domain of
+// the search string is open, see requestDomain, and records the outcome of the other calls. The requests that failed
+// with ErrCircuitOpen can be crawled again later, see PageSource.Retry.
+func (b *CircuitBreaker) Wrap(crawler CrawlerFunc) CrawlerFunc {
+	return func(search string) (*html.Node, error) {
+		domain := requestDomain(search)
+		err := b.Allow(domain)
+		if err != nil {
+			return nil, err
+		}
+		page, err := crawler(search)
+		b.Record(domain, err)
+		return page, err
+	}
+}
+
+// Allow returns nil if a request to the domain may be made, or ErrCircuitOpen. Each allowed request must be followed
+// by Record with its outcome.
+func (b *CircuitBreaker) Allow(domain string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(domain)
+	switch c.state {
+	case CircuitOpen:
+		if time.Now().Sub(c.openedAt) < b.cooldown() {
+			return withKind(ErrCircuitOpen, fmt.Errorf("error - circuit of %s is open", domain))
+		}
+		b.setState(domain, c, CircuitHalfOpen)
+		c.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return withKind(ErrCircuitOpen, fmt.Errorf("error - circuit of %s is half-open", domain))
+		}
+		c.probing = true
+	}
+	return nil
+}
+
+// Record records the outcome of a request to the domain allowed by Allow.
+func (b *CircuitBreaker) Record(domain string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(domain)
+	failed := err != nil && (b.IsFailure == nil || b.IsFailure(err))
+
+	if c.state == CircuitHalfOpen {
+		c.probing = false
+		if failed {
+			c.openedAt = time.Now()
+			b.setState(domain, c, CircuitOpen)
+		} else {
+			c.failures = 0
+			b.setState(domain, c, CircuitClosed)
+		}
+		return
+	}
+	if !failed {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	threshold := b.Threshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if c.state == CircuitClosed && c.failures >= threshold {
+		c.openedAt = time.Now()
+		b.setState(domain, c, CircuitOpen)
+	}
+}
+
+// State returns the state of the circuit of the domain.
+func (b *CircuitBreaker) State(domain string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.circuit(domain).state
+}
+
+// circuit returns the circuit of the domain, creating it closed.
+func (b *CircuitBreaker) circuit(domain string) *circuit {
+	if b.circuits == nil {
+		b.circuits = map[string]*circuit{}
+	}
+	c, ok := b.circuits[domain]
+	if !ok {
+		c = &circuit{}
+		b.circuits[domain] = c
+	}
+	return c
+}
+
+// setState changes the state of the circuit and reports it.
+func (b *CircuitBreaker) setState(domain string, c *circuit, state CircuitState) {
+	c.state = state
+	log.Printf("Circuit of %q is %s", domain, state)
+	if b.OnStateChange != nil {
+		b.OnStateChange(domain, state)
+	}
+}
+
+func (b *CircuitBreaker) cooldown() time.Duration {
+	if b.Cooldown <= 0 {
+		return time.Minute
+	}
+	return b.Cooldown
+}
@@ -0,0 +1,110 @@
+package goSpider
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	breaker := NewCircuitBreaker(3, 50*time.Millisecond)
+	breaker.OnStateChange = func(domain string, state CircuitState) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, domain+" "+state.String())
+	}
+
+	blocked := true
+	calls := map[string]int{}
+	crawler := breaker.Wrap(func(search string) (*html.Node, error) {
+		domain := requestDomain(search)
+		calls[domain]++
+		if domain == "blocked.example.com" && blocked {
+			return nil, withKind(ErrCaptchaDetected, errors.New("captcha"))
+		}
+		return &html.Node{Type: html.TextNode, Data: search}, nil
+	})
+
+	for i := 0; i < 5; i++ {
+		_, err := crawler("https://blocked.example.com/page")
+		if i >= 3 && !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected request %d to fail with ErrCircuitOpen, but got %v", i, err)
+		}
+		_, err = crawler("https://ok.example.com/page")
+		if err != nil {
+			t.Errorf("Expected the other domain to continue, but got %v", err)
+		}
+	}
+	if calls["blocked.example.com"] != 3 || breaker.State("blocked.example.com") != CircuitOpen {
+		t.Errorf("Expected the circuit to open after 3 failures, but got %d calls in state %s",
+			calls["blocked.example.com"], breaker.State("blocked.example.com"))
+	}
+
+	// a failed probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	_, err := crawler("https://blocked.example.com/page")
+	if !errors.Is(err, ErrCaptchaDetected) || breaker.State("blocked.example.com") != CircuitOpen {
+		t.Errorf("Expected the probe to fail and open the circuit, but got %v in state %s", err,
+			breaker.State("blocked.example.com"))
+	}
+
+	// a single probe passes while half-open, and closes the circuit when it succeeds
+	time.Sleep(60 * time.Millisecond)
+	blocked = false
+	if err := breaker.Allow("blocked.example.com"); err != nil {
+		t.Fatalf("Expected the probe to be allowed, but got %v", err)
+	}
+	if err := breaker.Allow("blocked.example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a second request to wait for the probe, but got %v", err)
+	}
+	breaker.Record("blocked.example.com", nil)
+	_, err = crawler("https://blocked.example.com/page")
+	if err != nil || breaker.State("blocked.example.com") != CircuitClosed {
+		t.Errorf("Expected the circuit to close, but got %v in state %s", err, breaker.State("blocked.example.com"))
+	}
+
+	expected := []string{
+		"blocked.example.com open",
+		"blocked.example.com half-open",
+		"blocked.example.com open",
+		"blocked.example.com half-open",
+		"blocked.example.com closed",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected changes %v, but got %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected changes %v, but got %v", expected, changes)
+			break
+		}
+	}
+}
+
+func TestCircuitBreakerIsFailure(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.IsFailure = func(err error) bool {
+		return errors.Is(err, ErrCaptchaDetected)
+	}
+	for i := 0; i < 3; i++ {
+		breaker.Record("example.com", ErrElementNotFound)
+	}
+	if breaker.State("example.com") != CircuitClosed {
+		t.Error("Expected errors that are not failures to keep the circuit closed")
+	}
+	breaker.Record("example.com", ErrCaptchaDetected)
+	breaker.Record("example.com", nil)
+	breaker.Record("example.com", ErrCaptchaDetected)
+	if breaker.State("example.com") != CircuitClosed {
+		t.Error("Expected a success to reset the consecutive failures")
+	}
+	breaker.Record("example.com", ErrCaptchaDetected)
+	if breaker.State("example.com") != CircuitOpen {
+		t.Error("Expected the circuit to open after 2 consecutive failures")
+	}
+}
@@ -0,0 +1,164 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+)
+
+// Browser is the interface of the methods of a Navigator. Code that drives a browser can accept a Browser instead of a
+// *Navigator, so it can be unit tested with a mock, without launching Chrome. A mock can embed the interface and
+// implement only the methods under test.
+// Example:
+//
+//	type fakeBrowser struct {
+//		goSpider.Browser
+//		pageSource *html.Node
+//	}
+//
+//	func (b *fakeBrowser) OpenURL(url string) error             { return nil }
+//	func (b *fakeBrowser) GetPageSource() (*html.Node, error) { return b.pageSource, nil }
+//
+//	lawsuit, err := crawler.Lawsuit(&fakeBrowser{pageSource: fixture}, "1017927-35.2023.8.26.0008")
+type Browser interface {
+	SetTimeOut(timeOut time.Duration)
+	GetTimeOut() time.Duration
+	SetTimeouts(timeouts Timeouts)
+	GetTimeouts() Timeouts
+	GetLogger() *log.Logger
+	Close()
+
+	// navigation
+	OpenURL(url string) error
+	GetCurrentURL() (string, error)
+	ReloadPage(retryCount int) error
+	WaitPageLoad() (string, error)
+	SwitchToFrame(selector string) error
+	SwitchToDefaultContent() error
+	Fetch(url string) (*html.Node, error)
+	FetchFile(url string) ([]byte, string, error)
+
+	// windows
+	WindowID() (string, error)
+	Windows() ([]Window, error)
+	ResizeWindow(width, height int64) error
+	WaitForClose(timeout time.Duration) error
+
+	// login
+	Login(url, username, password, usernameSelector, passwordSelector, loginButtonSelector string, messageFailedSuccess string) error
+	LoginAccountsGoogle(email, password string) error
+	LoginWithGoogle(url string) error
+
+	// cookies
+	GetCookies() ([]*network.Cookie, error)
+	SetCookies(cookies []*network.Cookie) error
+	DeleteCookie(name, domain string) error
+	ExportCookiesJSON(path string) error
+	ImportCookiesJSON(path string) error
+	SaveSession(path string) error
+	RestoreSession(path string) error
+
+	// page content
+	GetPageSource() (*html.Node, error)
+	GetPageSourceOf(selector string) (*html.Node, error)
+	GetPageSourceWithFrames() (*html.Node, error)
+	StreamPageSource(maxBytes int64) (io.ReadCloser, error)
+	GetElement(selector string) (string, error)
+	GetElementAttribute(selector, attribute string) (string, error)
+	GetElementBox(selector string) (ElementBox, error)
+	GetComputedStyle(selector string, properties ...string) (map[string]string, error)
+	ExtractLinks() ([]string, error)
+	CaptureScreenshot(nameFile string) error
+	ElementScreenshot(selector string) ([]byte, error)
+	CompareWithBaseline(path string, threshold float64) (ScreenshotDiff, error)
+	SaveImageBase64(selector, outputPath, prefixClean string) (string, error)
+	SavePageComplete(dir string) error
+	SaveMHTML(path string) error
+	OCRScreenshot(ocr OCR) (OCRResult, error)
+	OCRElement(selector string, ocr OCR) (OCRResult, error)
+
+	// elements
+	WaitForElement(selector string, timeout time.Duration) error
+	WaitForNode(selector string, timeout time.Duration) error
+	WaitForSelectorCount(selector string, n int, match CountMatch, timeout time.Duration) error
+	ElementExists(selector string) (bool, error)
+	CountElements(selector string) (int, error)
+	ResolveSelector(selector string) (string, error)
+	FindByText(text string, match TextMatch) (string, error)
+	GetByRole(role, name string) (string, error)
+	GetAccessibilityTree() (*AXNode, error)
+	MakeElementVisible(selector string) error
+
+	// interaction
+	ClickButton(selector string) error
+	UnsafeClickButton(selector string) error
+	ClickElement(selector string) error
+	ClickByText(text string) error
+	ClickAndWait(selector string, condition WaitCondition, timeout time.Duration) error
+	CheckRadioButton(selector string) error
+	UncheckRadioButton(selector string) error
+	FillField(selector string, value string) error
+	UnsafeFillField(selector string, value string) error
+	FillForm(selector string, data map[string]string) error
+	FillRichText(selector, content string) error
+	SelectDropdown(selector, value string) error
+	SelectCustomDropdown(trigger, optionText string) error
+	ReadClipboard() (string, error)
+	WriteClipboard(text string) error
+	PasteClipboard(selector string) error
+	Shortcut(keys string) error
+	ShortcutOn(selector, keys string) error
+	TypeOver(selector, value string) error
+	HandleAlert() error
+	ExecuteScript(script string) error
+	EvaluateScript(script string) (interface{}, error)
+	Datepicker(date, calendarButtonSelector, calendarButtonGoBack, calendarButtonsTableXpath, calendarButtonTR string) error
+	DatepickerWithLayout(date, layout, calendarButtonSelector, calendarButtonGoBack, calendarButtonGoForward, calendarButtonsTableXpath, calendarButtonTR string) error
+	DatepickerDropdown(date, layout, calendarButtonSelector, monthSelectSelector, yearSelectSelector, calendarButtonsTableXpath, calendarButtonTR string) error
+
+	// downloads
+	EnableDownloads(dir string) error
+	WaitForDownload(dir string, timeout time.Duration, trigger func() error) (Download, error)
+	CaptureGeneratedFile(timeout time.Duration, trigger func() error) (GeneratedFile, error)
+	DownloadDocuments(linkSelector, frameSelector, dir string, timeout time.Duration) ([]DocumentFile, error)
+
+	// browser settings
+	BlockResourceTypes(types ...ResourceType) error
+	BlockURLs(patterns ...string) error
+	SetExtraHeaders(headers map[string]string) error
+	BypassServiceWorkers(bypass bool) error
+	UnregisterServiceWorkers() (int, error)
+	EmulateMedia(media string) error
+	EmulateColorScheme(scheme string) error
+	EmulateDevice(name string) error
+	SetGeolocation(latitude, longitude, accuracy float64) error
+	ClearGeolocation() error
+	GrantPermissions(origin string, permissions ...Permission) error
+	RestrictNavigation(allowedHostPatterns ...string) error
+	TrackAssets() error
+	CheckAssets() (PageAssets, error)
+	EnableSelfHealing(path string) error
+	RecordCassette(path string) (*Cassette, error)
+	ReplayCassette(path string) (*Cassette, error)
+	EjectCassette() error
+	EnableChaos(chaos *Chaos) error
+	Use(middleware ...Middleware)
+	AddInitScript(script string) (string, error)
+	RemoveInitScript(id string) error
+	RecordSession() (*SessionRecorder, error)
+	RecordTimeline(options TimelineOptions) (*TimelineRecorder, error)
+	RunDetectionChecks() (DetectionReport, error)
+
+	// raw CDP
+	CDP() context.Context
+	RunCDP(actions ...chromedp.Action) error
+	ExecuteCDP(method string, params interface{}) (json.RawMessage, error)
+}
+
+var _ Browser = (*Navigator)(nil)
@@ -0,0 +1,302 @@
+// Package browsers locates, downloads and verifies pinned builds of Chrome for Testing and chrome-headless-shell, so
+// crawls don't depend on whatever Chrome happens to be installed on the host. Use the path of a build with
+// goSpider.WithExecPath.
+package browsers
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Product is a build of Chrome for Testing.
+type Product string
+
+const (
+	// Chrome is the full Chrome browser, which runs headful or headless.
+	Chrome Product = "chrome"
+	// HeadlessShell is chrome-headless-shell, a lightweight build of Chrome that only runs headless.
+	HeadlessShell Product = "chrome-headless-shell"
+)
+
+const (
+	// DefaultVersion is the version of the builds installed when Installer.Version is not set.
+	DefaultVersion = "131.0.6778.85"
+	// DefaultBaseURL is the Chrome for Testing download bucket.
+	DefaultBaseURL = "https://storage.googleapis.com/chrome-for-testing-public"
+)
+
+// Installer installs a pinned build of a Product for a platform into a directory, once: later calls find the
+// installed build.
+type Installer struct {
+	Product  Product
+	Version  string // DefaultVersion if empty
+	Platform string // e.g. "linux64" or "mac-arm64", the platform of the host if empty, see Platform
+	Dir      string // the directory of the builds, DefaultDir if empty
+	BaseURL  string // DefaultBaseURL if empty
+	SHA256   string // the hex checksum the downloaded archive must have, unchecked if empty
+	Client   *http.Client
+}
+
+// Install returns the path of the binary of a Product, downloading it from the Chrome for Testing bucket when it
+// isn't installed yet.
+// Example:
+//
+//	path, err := browsers.Install(browsers.HeadlessShell, "")
+//	nav := goSpider.NewNavigator("", true, goSpider.WithExecPath(path))
+func Install(product Product, version string) (string, error) {
+	return (&Installer{Product: product, Version: version}).Install()
+}
+
+// DefaultDir returns the directory of the builds: goSpider/browsers in the user cache directory.
+func DefaultDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error - failed to find cache directory: %w", err)
+	}
+	return filepath.Join(cache, "goSpider", "browsers"), nil
+}
+
+// Platform returns the Chrome for Testing platform of the host.
+func Platform() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux64", nil
+	case "darwin/amd64":
+		return "mac-x64", nil
+	case "darwin/arm64":
+		return "mac-arm64", nil
+	case "windows/386":
+		return "win32", nil
+	case "windows/amd64":
+		return "win64", nil
+	}
+	return "", fmt.Errorf("error - no Chrome for Testing build for %s/%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// Path returns the path the binary of the build has once installed.
+func (i *Installer) Path() (string, error) {
+	dir, platform, err := i.location()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, binary(i.Product, platform)), nil
+}
+
+// Find returns the path of the binary of the build when it is installed.
+func (i *Installer) Find() (string, bool) {
+	path, err := i.Path()
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// Install returns the path of the binary of the build, downloading, extracting and verifying it when it isn't
+// installed. The build is extracted next to its final directory and moved there once complete, so an interrupted
+// install is never found.
+func (i *Installer) Install() (string, error) {
+	if path, ok := i.Find(); ok {
+		return path, nil
+	}
+	dir, platform, err := i.location()
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dir), os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("error - failed to create directory: %w", err)
+	}
+	archive, err := os.CreateTemp(filepath.Dir(dir), "download-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("error - failed to create archive: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	err = i.download(platform, archive)
+	archive.Close()
+	if err != nil {
+		return "", err
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(dir), "install-*")
+	if err != nil {
+		return "", fmt.Errorf("error - failed to create directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	err = extract(archive.Name(), staging)
+	if err != nil {
+		return "", err
+	}
+	err = Verify(filepath.Join(staging, binary(i.Product, platform)), i.version())
+	if err != nil {
+		return "", err
+	}
+	err = os.Rename(staging, dir)
+	if err != nil {
+		return "", fmt.Errorf("error - failed to install %s: %w", i.Product, err)
+	}
+	return filepath.Join(dir, binary(i.Product, platform)), nil
+}
+
+// Verify checks that the binary at path runs and, when version is set, that it reports the version.
+func Verify(path, version string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("error - failed to run %s: %w", path, err)
+	}
+	if version != "" && !strings.Contains(string(output), version) {
+		return fmt.Errorf("error - %s is not version %s: %s", path, version, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// location returns the directory of the build and its platform.
+func (i *Installer) location() (string, string, error) {
+	if i.Product != Chrome && i.Product != HeadlessShell {
+		return "", "", fmt.Errorf("error - unknown browser product: %q", i.Product)
+	}
+	platform := i.Platform
+	if platform == "" {
+		var err error
+		platform, err = Platform()
+		if err != nil {
+			return "", "", err
+		}
+	}
+	dir := i.Dir
+	if dir == "" {
+		var err error
+		dir, err = DefaultDir()
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return filepath.Join(dir, string(i.Product), i.version(), platform), platform, nil
+}
+
+func (i *Installer) version() string {
+	if i.Version == "" {
+		return DefaultVersion
+	}
+	return i.Version
+}
+
+// download writes the archive of the build to w, checking its checksum when set.
+func (i *Installer) download(platform string, w io.Writer) error {
+	baseURL := i.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	url := fmt.Sprintf("%s/%s/%s/%s-%s.zip", strings.TrimSuffix(baseURL, "/"), i.version(), platform, i.Product, platform)
+	client := i.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+
+	response, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("error - failed to download %s: %w", url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("error - failed to download %s: %s", url, response.Status)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(w, hash), response.Body)
+	if err != nil {
+		return fmt.Errorf("error - failed to download %s: %w", url, err)
+	}
+	if i.SHA256 != "" && !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), i.SHA256) {
+		return fmt.Errorf("error - checksum mismatch of %s", url)
+	}
+	return nil
+}
+
+// binary returns the path of the binary of a product in its extracted archive.
+func binary(product Product, platform string) string {
+	root := string(product) + "-" + platform
+	switch {
+	case product == Chrome && strings.HasPrefix(platform, "mac"):
+		return filepath.Join(root, "Google Chrome for Testing.app", "Contents", "MacOS", "Google Chrome for Testing")
+	case strings.HasPrefix(platform, "win"):
+		return filepath.Join(root, string(product)+".exe")
+	}
+	return filepath.Join(root, string(product))
+}
+
+// extract extracts the zip archive into dir, keeping the modes of the files.
+func extract(archive, dir string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("error - failed to open archive: %w", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		path := filepath.Join(dir, file.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("error - invalid path in archive: %s", file.Name)
+		}
+		err = extractFile(file, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractFile extracts a file, a directory or a symbolic link of an archive to path.
+func extractFile(file *zip.File, path string) error {
+	mode := file.Mode()
+	if mode.IsDir() {
+		return os.MkdirAll(path, os.ModePerm)
+	}
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error - failed to create directory: %w", err)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("error - failed to read %s: %w", file.Name, err)
+	}
+	defer src.Close()
+	if mode&os.ModeSymlink != 0 {
+		target, err := io.ReadAll(src)
+		if err != nil {
+			return fmt.Errorf("error - failed to read %s: %w", file.Name, err)
+		}
+		return os.Symlink(string(target), path)
+	}
+
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return fmt.Errorf("error - failed to create %s: %w", path, err)
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error - failed to extract %s: %w", file.Name, err)
+	}
+	return nil
+}
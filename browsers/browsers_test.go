@@ -0,0 +1,130 @@
+package browsers
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// buildArchive returns a zip archive of a fake build of product, whose binary prints version.
+func buildArchive(t *testing.T, product Product, platform, version string) []byte {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	header := &zip.FileHeader{Name: filepath.ToSlash(binary(product, platform)), Method: zip.Deflate}
+	header.SetMode(0755)
+	w, err := archive.CreateHeader(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("#!/bin/sh\necho 'Google Chrome for Testing " + version + "'\n"))
+	err = archive.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake build is a shell script")
+	}
+	data := buildArchive(t, HeadlessShell, "linux64", DefaultVersion)
+	var downloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads = append(downloads, r.URL.Path)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(data)
+	installer := &Installer{
+		Product:  HeadlessShell,
+		Platform: "linux64",
+		Dir:      t.TempDir(),
+		BaseURL:  server.URL,
+		SHA256:   hex.EncodeToString(sum[:]),
+	}
+	if _, ok := installer.Find(); ok {
+		t.Fatalf("Expected the build not to be installed")
+	}
+	path, err := installer.Install()
+	if err != nil {
+		t.Fatalf("Install error: %v", err)
+	}
+	expected := filepath.Join(installer.Dir, "chrome-headless-shell", DefaultVersion, "linux64",
+		"chrome-headless-shell-linux64", "chrome-headless-shell")
+	if path != expected {
+		t.Errorf("Expected the binary at %s, got %s", expected, path)
+	}
+	if len(downloads) != 1 || downloads[0] != "/"+DefaultVersion+"/linux64/chrome-headless-shell-linux64.zip" {
+		t.Errorf("Expected the archive of the pinned version, got %v", downloads)
+	}
+
+	// installed builds are found without downloading them again
+	path, err = installer.Install()
+	if err != nil || path != expected || len(downloads) != 1 {
+		t.Errorf("Expected the installed build, got %s after %d downloads (%v)", path, len(downloads), err)
+	}
+}
+
+func TestInstallFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake build is a shell script")
+	}
+	data := buildArchive(t, Chrome, "linux64", "130.0.6723.58")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	installer := &Installer{Product: Chrome, Platform: "linux64", Dir: t.TempDir(), BaseURL: server.URL, SHA256: "00"}
+	_, err := installer.Install()
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	// the build reports another version than the pinned one
+	installer.SHA256 = ""
+	_, err = installer.Install()
+	if err == nil || !strings.Contains(err.Error(), "is not version") {
+		t.Errorf("Expected a version mismatch, got %v", err)
+	}
+	if _, ok := installer.Find(); ok {
+		t.Errorf("Expected the failed build not to be installed")
+	}
+	entries, _ := os.ReadDir(filepath.Join(installer.Dir, "chrome", DefaultVersion))
+	if len(entries) != 0 {
+		t.Errorf("Expected the downloads to be cleaned up, got %v", entries)
+	}
+
+	installer.Product = "firefox"
+	if _, err := installer.Install(); err == nil {
+		t.Errorf("Expected an unknown product to fail")
+	}
+}
+
+func TestBinary(t *testing.T) {
+	for _, test := range []struct {
+		product  Product
+		platform string
+		expected string
+	}{
+		{Chrome, "linux64", "chrome-linux64/chrome"},
+		{Chrome, "mac-arm64", "chrome-mac-arm64/Google Chrome for Testing.app/Contents/MacOS/Google Chrome for Testing"},
+		{Chrome, "win64", "chrome-win64/chrome.exe"},
+		{HeadlessShell, "mac-x64", "chrome-headless-shell-mac-x64/chrome-headless-shell"},
+		{HeadlessShell, "win32", "chrome-headless-shell-win32/chrome-headless-shell.exe"},
+	} {
+		if path := filepath.ToSlash(binary(test.product, test.platform)); path != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, path)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package goSpider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PageCache keeps copies of fetched pages by normalized URL, so OpenURL and fetchers can skip the network while a fresh
+// copy exists. It is kept in memory or, when created with a directory, on disk so it survives between runs, which is
+// handy while developing extractors against production sites.
+type PageCache struct {
+	TTL       time.Duration            // how long a copy stays fresh; zero or negative never expires
+	DomainTTL map[string]time.Duration // TTL by domain, also applied to its subdomains
+	Bypass    bool                     // when true every lookup misses, but fetched pages are still stored
+
+	mu      sync.Mutex
+	dir     string
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a cached page, as kept in memory and saved on disk.
+type cacheEntry struct {
+	URL    string
+	Body   string
+	Stored time.Time
+}
+
+// NewPageCache creates a PageCache whose copies stay fresh for ttl. An empty dir keeps the pages in memory only.
+// Example:
+//
+//	cache, err := goSpider.NewPageCache("cache", 24*time.Hour)
+//	cache.DomainTTL["esaj.tjsp.jus.br"] = time.Hour
+//	nav.Cache = cache
+func NewPageCache(dir string, ttl time.Duration) (*PageCache, error) {
+	if dir != "" {
+		err := os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return nil, fmt.Errorf("error - failed to create cache directory: %w", classifyError(err))
+		}
+	}
+	return &PageCache{
+		TTL:       ttl,
+		DomainTTL: map[string]time.Duration{},
+		dir:       dir,
+		entries:   map[string]cacheEntry{},
+	}, nil
+}
+
+// Get returns the cached copy of rawURL when it is still fresh.
+func (c *PageCache) Get(rawURL string) (string, bool) {
+	if c.Bypass {
+		return "", false
+	}
+	key := NormalizeURL(rawURL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok && c.dir != "" {
+		data, err := os.ReadFile(c.path(key))
+		if err != nil || json.Unmarshal(data, &entry) != nil {
+			return "", false
+		}
+		ok = true
+	}
+	if !ok {
+		return "", false
+	}
+
+	ttl := c.ttl(key)
+	if ttl > 0 && time.Since(entry.Stored) > ttl {
+		return "", false
+	}
+	return entry.Body, true
+}
+
+// Set stores body as the copy of rawURL.
+func (c *PageCache) Set(rawURL, body string) error {
+	key := NormalizeURL(rawURL)
+	entry := cacheEntry{URL: key, Body: body, Stored: time.Now()}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dir == "" {
+		c.entries[key] = entry
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error - failed to encode cache entry: %w", classifyError(err))
+	}
+	err = os.WriteFile(c.path(key), data, 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to save cache entry: %w", classifyError(err))
+	}
+	return nil
+}
+
+// path returns the file where the entry of the normalized URL is saved.
+func (c *PageCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// ttl returns the TTL of the most specific domain of the normalized URL, or the cache TTL.
+func (c *PageCache) ttl(key string) time.Duration {
+	u, err := url.Parse(key)
+	if err != nil {
+		return c.TTL
+	}
+	host := u.Hostname()
+	for {
+		if ttl, ok := c.DomainTTL[host]; ok {
+			return ttl
+		}
+		dot := strings.Index(host, ".")
+		if dot < 0 {
+			return c.TTL
+		}
+		host = host[dot+1:]
+	}
+}
+
+// NormalizeURL returns the cache key of a URL: lowercase scheme and host, no default port or fragment,
+// and the query parameters sorted.
+// Example:
+//
+//	goSpider.NormalizeURL("HTTPS://Example.com:443/search?q=1&a=2#top") // https://example.com/search?a=2&q=1
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+
+	query := u.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// openCached loads the cached copy of url into the page. The copy is the rendered DOM without scripts, loaded with a
+// <base> pointing to url so relative links still resolve; GetCurrentURL reports about:blank.
+func (nav *Navigator) openCached(url, body string) error {
+	body = strings.Replace(body, "<head>", `<head><base href="`+html.EscapeString(url)+`">`, 1)
+	return chromedp.Run(nav.Ctx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			frameTree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(frameTree.Frame.ID, body).Do(ctx)
+		}),
+	)
+}
+
+// cachePage stores the rendered DOM of the page, without scripts, as the copy of url.
+func (nav *Navigator) cachePage(url string) error {
+	var body string
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(`(function() {
+			var root = document.documentElement.cloneNode(true);
+			root.querySelectorAll("script").forEach(function(script) { script.remove(); });
+			return root.outerHTML;
+		})()`, &body),
+	)
+	if err != nil {
+		return err
+	}
+	return nav.Cache.Set(url, body)
+}
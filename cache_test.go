@@ -0,0 +1,129 @@
+package goSpider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := map[string]string{
+		"HTTPS://Example.com:443/search?q=1&a=2#top": "https://example.com/search?a=2&q=1",
+		"http://example.com:80":                      "http://example.com/",
+		"http://example.com:8080/a?b=2&b=1":          "http://example.com:8080/a?b=1&b=2",
+	}
+	for rawURL, want := range tests {
+		got := NormalizeURL(rawURL)
+		if got != want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestPageCache(t *testing.T) {
+	cache, err := NewPageCache("", time.Hour)
+	if err != nil {
+		t.Fatalf("NewPageCache error: %v", err)
+	}
+	cache.DomainTTL["example.com"] = time.Nanosecond
+
+	err = cache.Set("https://www.example.org/page?b=2&a=1", "<html>org</html>")
+	if err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	body, ok := cache.Get("https://WWW.example.org/page?a=1&b=2#section")
+	if !ok || body != "<html>org</html>" {
+		t.Errorf("Expected a cache hit for the normalized URL, got %q %v", body, ok)
+	}
+
+	// the domain TTL also applies to subdomains
+	err = cache.Set("https://www.example.com/page", "<html>com</html>")
+	if err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Get("https://www.example.com/page"); ok {
+		t.Errorf("Expected the copy to expire with the domain TTL")
+	}
+
+	cache.Bypass = true
+	if _, ok := cache.Get("https://www.example.org/page?a=1&b=2"); ok {
+		t.Errorf("Expected a miss when bypassing the cache")
+	}
+}
+
+func TestPageCacheOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewPageCache(dir, 0)
+	if err != nil {
+		t.Fatalf("NewPageCache error: %v", err)
+	}
+	err = cache.Set("https://example.com/", "<html>saved</html>")
+	if err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+
+	reopened, err := NewPageCache(dir, 0)
+	if err != nil {
+		t.Fatalf("NewPageCache error: %v", err)
+	}
+	body, ok := reopened.Get("https://example.com")
+	if !ok || body != "<html>saved</html>" {
+		t.Errorf("Expected the copy saved on disk, got %q %v", body, ok)
+	}
+}
+
+func TestHTTPFetcherCache(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body><h1 id="title">Cached</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	cache, _ := NewPageCache("", time.Hour)
+	fetcher := NewHTTPFetcher(5 * time.Second)
+	fetcher.Cache = cache
+
+	for i := 0; i < 3; i++ {
+		pageSource, err := fetcher.Fetch(server.URL + "/page")
+		if err != nil {
+			t.Fatalf("Fetch error: %v", err)
+		}
+		title, err := FindNodes(pageSource, "//h1[@id='title']")
+		if err != nil || title[0].FirstChild.Data != "Cached" {
+			t.Errorf("Expected the cached title")
+		}
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", hits)
+	}
+}
+
+func TestOpenURLCache(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.Cache, _ = NewPageCache("", time.Hour)
+
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	if _, ok := nav.Cache.Get(server.URL + "/test.html"); !ok {
+		t.Fatalf("Expected the page to be cached")
+	}
+
+	server.Close()
+	err = nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL from cache error: %v", err)
+	}
+	title, err := nav.GetElement("h1")
+	if err != nil || title != "Main Content" {
+		t.Errorf("Expected the cached page content, got %q: %v", title, err)
+	}
+}
@@ -0,0 +1,303 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Interaction is a request recorded in a Cassette with its response.
+type Interaction struct {
+	Method   string               `json:"method"`
+	URL      string               `json:"url"`
+	Body     string               `json:"body,omitempty"` // the request body, for POST requests
+	Status   int64                `json:"status"`
+	Headers  []*fetch.HeaderEntry `json:"headers,omitempty"`
+	Response string               `json:"response,omitempty"` // the response body, base64 encoded
+}
+
+// Cassette holds the network traffic of a crawl, recorded by RecordCassette and replayed by ReplayCassette. Requests
+// are matched by method, URL and body; a request made several times gets the recorded responses in order, and the last
+// one after that. It is safe for concurrent use.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+	IgnoreParams []string      `json:"ignore_params,omitempty"` // query parameters ignored when matching, e.g. cache busters
+
+	mu     sync.Mutex
+	played map[string]int
+}
+
+// LoadCassette reads a cassette saved by Cassette.Save.
+// Example:
+//
+//	cassette, err := goSpider.LoadCassette("testdata/lawsuit.json")
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read cassette: %w", err)
+	}
+	cassette := &Cassette{}
+	err = json.Unmarshal(data, cassette)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to decode cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette as JSON to path.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error - failed to encode cassette: %w", err)
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to save cassette: %w", err)
+	}
+	return nil
+}
+
+// Add appends an interaction to the cassette.
+func (c *Cassette) Add(interaction Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, interaction)
+}
+
+// Match returns the recorded interaction for a request. Each call plays the next recorded interaction of the
+// request, repeating the last one once all of them were played.
+func (c *Cassette) Match(method, rawURL, body string) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := c.key(method, rawURL, body)
+	var matches []int
+	for i, interaction := range c.Interactions {
+		if c.key(interaction.Method, interaction.URL, interaction.Body) == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return Interaction{}, false
+	}
+	if c.played == nil {
+		c.played = make(map[string]int)
+	}
+	n := c.played[key]
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	c.played[key] = n + 1
+	return c.Interactions[matches[n]], true
+}
+
+// key identifies a request, without the ignored query parameters and the fragment of the URL.
+func (c *Cassette) key(method, rawURL, body string) string {
+	parsed, err := url.Parse(rawURL)
+	if err == nil {
+		parsed.Fragment = ""
+		if len(c.IgnoreParams) > 0 && parsed.RawQuery != "" {
+			query := parsed.Query()
+			for _, param := range c.IgnoreParams {
+				query.Del(param)
+			}
+			parsed.RawQuery = query.Encode()
+		}
+		rawURL = parsed.String()
+	}
+	return strings.ToUpper(method) + " " + rawURL + "\n" + body
+}
+
+// cassetteSession is the cassette in use by a Navigator, see RecordCassette and ReplayCassette.
+type cassetteSession struct {
+	cassette  *Cassette
+	path      string // where the recording is saved, empty when replaying
+	mu        sync.Mutex
+	ejected   bool           // set by EjectCassette, so no more requests are handled
+	recording sync.WaitGroup // the requests being handled
+}
+
+// start counts a request being handled, unless the cassette was ejected.
+func (s *cassetteSession) start() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ejected {
+		return false
+	}
+	s.recording.Add(1)
+	return true
+}
+
+// eject stops handling requests and waits for the ones being handled.
+func (s *cassetteSession) eject() {
+	s.mu.Lock()
+	s.ejected = true
+	s.mu.Unlock()
+	s.recording.Wait()
+}
+
+// RecordCassette records every request of the browser and its response, through Fetch interception, until
+// EjectCassette or Close saves them to the cassette file at path. Replaying the cassette later makes crawls
+// repeatable offline, so extractor regression tests don't break when the target site changes. It combines with
+// BlockResourceTypes, EnableChaos and RestrictNavigation, which share Fetch interception: the requests they fail are
+// not recorded.
+// Example:
+//
+//	_, err := nav.RecordCassette("testdata/lawsuit.json")
+//	lawsuit, err := crawler.Lawsuit(nav, "1017927-35.2023.8.26.0008")
+//	err = nav.EjectCassette()
+func (nav *Navigator) RecordCassette(path string) (*Cassette, error) {
+	cassette := &Cassette{}
+	session := &cassetteSession{cassette: cassette, path: path}
+	err := nav.useCassette(session, fetch.RequestStageResponse, func(ctx context.Context, paused *fetch.EventRequestPaused) bool {
+		interaction := Interaction{
+			Method: paused.Request.Method,
+			URL:    paused.Request.URL,
+			Body:   postData(paused.Request),
+			Status: paused.ResponseStatusCode,
+		}
+		for _, header := range paused.ResponseHeaders {
+			// the body is recorded decoded, so its encoding and length don't apply anymore
+			name := strings.ToLower(header.Name)
+			if name != "content-encoding" && name != "content-length" {
+				interaction.Headers = append(interaction.Headers, header)
+			}
+		}
+		// redirects have no body
+		if paused.ResponseErrorReason == "" && (paused.ResponseStatusCode < 300 || paused.ResponseStatusCode > 399) {
+			var body []byte
+			err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+				var err error
+				body, err = fetch.GetResponseBody(paused.RequestID).Do(ctx)
+				return err
+			}))
+			if err != nil {
+				nav.Logger.Printf("Error - Failed to record response of %s: %v\n", paused.Request.URL, err)
+			}
+			interaction.Response = base64.StdEncoding.EncodeToString(body)
+		}
+		if paused.ResponseErrorReason == "" {
+			cassette.Add(interaction)
+		}
+		return false
+	})
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to record cassette: %v\n", err)
+		return nil, fmt.Errorf("error - failed to record cassette: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Recording cassette: %s\n", path)
+	return cassette, nil
+}
+
+// ReplayCassette answers every request of the browser with the responses recorded in the cassette file at path,
+// through Fetch interception, without touching the network. Requests that were not recorded fail as if the browser
+// were offline, and are logged. IgnoreParams of the returned Cassette can be set before the first request.
+// Example:
+//
+//	cassette, err := nav.ReplayCassette("testdata/lawsuit.json")
+//	cassette.IgnoreParams = []string{"_"}
+//	lawsuit, err := crawler.Lawsuit(nav, "1017927-35.2023.8.26.0008")
+func (nav *Navigator) ReplayCassette(path string) (*Cassette, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to load cassette: %v\n", err)
+		return nil, err
+	}
+	session := &cassetteSession{cassette: cassette}
+	err = nav.useCassette(session, fetch.RequestStageRequest, func(ctx context.Context, paused *fetch.EventRequestPaused) bool {
+		interaction, ok := cassette.Match(paused.Request.Method, paused.Request.URL, postData(paused.Request))
+		if !ok {
+			nav.Logger.Printf("Error - Request not recorded in cassette: %s %s\n", paused.Request.Method, paused.Request.URL)
+			_ = chromedp.Run(ctx, fetch.FailRequest(paused.RequestID, network.ErrorReasonInternetDisconnected))
+			return true
+		}
+		fulfill := fetch.FulfillRequest(paused.RequestID, interaction.Status).WithResponseHeaders(interaction.Headers)
+		if interaction.Response != "" {
+			fulfill = fulfill.WithBody(interaction.Response)
+		}
+		_ = chromedp.Run(ctx, fulfill)
+		return true
+	})
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to replay cassette: %v\n", err)
+		return nil, fmt.Errorf("error - failed to replay cassette: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Replaying cassette: %s\n", path)
+	return cassette, nil
+}
+
+// EjectCassette stops recording or replaying the cassette, and saves the recorded one to its file.
+func (nav *Navigator) EjectCassette() error {
+	session := nav.cassette
+	if session == nil {
+		return nil
+	}
+	nav.cassette = nil
+	err := nav.setInterceptRule(interceptCassette, nil)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to stop intercepting requests: %v\n", err)
+	}
+	session.eject()
+
+	if session.path == "" {
+		return nil
+	}
+	err = session.cassette.Save(session.path)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to save cassette: %v\n", err)
+		return err
+	}
+	nav.Logger.Printf("Cassette saved: %s\n", session.path)
+	return nil
+}
+
+// useCassette replaces the cassette in use, saving the recorded one, and intercepts every request at the stage. handle
+// answers a request in a goroutine, returning false to leave it to the other interceptions, see interceptRule.
+func (nav *Navigator) useCassette(session *cassetteSession, stage fetch.RequestStage, handle func(ctx context.Context, paused *fetch.EventRequestPaused) bool) error {
+	err := nav.EjectCassette()
+	if err != nil {
+		return err
+	}
+
+	rule := &interceptRule{stage: stage, handle: func(paused *fetch.EventRequestPaused) func(ctx context.Context) bool {
+		// the requests paused after EjectCassette are left to the other interceptions
+		if !session.start() {
+			return nil
+		}
+		return func(ctx context.Context) bool {
+			defer session.recording.Done()
+			return handle(ctx, paused)
+		}
+	}}
+	err = nav.setInterceptRule(interceptCassette, rule)
+	if err != nil {
+		return err
+	}
+	nav.cassette = session
+	return nil
+}
+
+// postData returns the body of a request.
+func postData(request *network.Request) string {
+	if len(request.PostDataEntries) == 0 {
+		return ""
+	}
+	var body strings.Builder
+	for _, entry := range request.PostDataEntries {
+		data, err := base64.StdEncoding.DecodeString(entry.Bytes)
+		if err != nil {
+			return ""
+		}
+		body.Write(data)
+	}
+	return body.String()
+}
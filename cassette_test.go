@@ -0,0 +1,117 @@
+package goSpider
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestCassetteMatch(t *testing.T) {
+	cassette := &Cassette{IgnoreParams: []string{"_"}}
+	cassette.Add(Interaction{Method: "GET", URL: "https://example.com/list?page=1&_=1", Status: 200, Response: "first"})
+	cassette.Add(Interaction{Method: "GET", URL: "https://example.com/list?page=1&_=2", Status: 200, Response: "second"})
+	cassette.Add(Interaction{Method: "POST", URL: "https://example.com/search", Body: "q=1", Status: 302})
+
+	var played []string
+	for i := 0; i < 3; i++ {
+		interaction, ok := cassette.Match("GET", "https://example.com/list?_=99&page=1#top", "")
+		if !ok {
+			t.Fatalf("Expected the request to match")
+		}
+		played = append(played, interaction.Response)
+	}
+	if strings.Join(played, ",") != "first,second,second" {
+		t.Errorf("Expected the responses in order and then the last one, got %v", played)
+	}
+
+	if interaction, ok := cassette.Match("post", "https://example.com/search", "q=1"); !ok || interaction.Status != 302 {
+		t.Errorf("Expected the POST request to match, got %+v", interaction)
+	}
+	if _, ok := cassette.Match("POST", "https://example.com/search", "q=2"); ok {
+		t.Errorf("Expected a request with another body not to match")
+	}
+	if _, ok := cassette.Match("GET", "https://example.com/list?page=2", ""); ok {
+		t.Errorf("Expected another page not to match")
+	}
+}
+
+func TestCassetteSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := &Cassette{IgnoreParams: []string{"ts"}}
+	cassette.Add(Interaction{Method: "GET", URL: "https://example.com/", Status: 200,
+		Response: base64.StdEncoding.EncodeToString([]byte("<html></html>"))})
+	err := cassette.Save(path)
+	if err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette error: %v", err)
+	}
+	if len(loaded.Interactions) != 1 || loaded.Interactions[0].URL != "https://example.com/" ||
+		len(loaded.IgnoreParams) != 1 || loaded.IgnoreParams[0] != "ts" {
+		t.Errorf("Unexpected cassette: %+v", loaded)
+	}
+	if _, ok := loaded.Match("GET", "https://example.com/?ts=1", ""); !ok {
+		t.Errorf("Expected the ignored params to be loaded")
+	}
+
+	_, err = LoadCassette(filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil {
+		t.Errorf("Expected an error for a missing cassette")
+	}
+}
+
+func TestPostData(t *testing.T) {
+	request := &network.Request{PostDataEntries: []*network.PostDataEntry{
+		{Bytes: base64.StdEncoding.EncodeToString([]byte("a=1&"))},
+		{Bytes: base64.StdEncoding.EncodeToString([]byte("b=2"))},
+	}}
+	if body := postData(request); body != "a=1&b=2" {
+		t.Errorf("Expected the joined entries, got %q", body)
+	}
+	if body := postData(&network.Request{}); body != "" {
+		t.Errorf("Expected no body, got %q", body)
+	}
+}
+
+func TestRecordReplayCassette(t *testing.T) {
+	server := startTestServer()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	nav := setupNavigator(t)
+	_, err := nav.RecordCassette(path)
+	if err != nil {
+		t.Fatalf("RecordCassette error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	err = nav.EjectCassette()
+	if err != nil {
+		t.Fatalf("EjectCassette error: %v", err)
+	}
+	server.Close()
+
+	replay := setupNavigator(t)
+	cassette, err := replay.ReplayCassette(path)
+	if err != nil {
+		t.Fatalf("ReplayCassette error: %v", err)
+	}
+	if len(cassette.Interactions) == 0 {
+		t.Fatalf("Expected recorded interactions")
+	}
+	err = replay.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	text, err := replay.GetElement("h1")
+	if err != nil || text != "Main Content" {
+		t.Errorf("Expected the recorded page to be replayed, got %q: %v", text, err)
+	}
+}
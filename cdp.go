@@ -0,0 +1,68 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+	"github.com/mailru/easyjson"
+)
+
+// CDP returns the chromedp context of the browser tab of nav, an escape hatch for the features the Navigator lacks.
+// Actions run with chromedp.Run on it share the session of the Navigator: its page, cookies and listeners. With the
+// credentials of WithProxy, open the first page with the Navigator or RunCDP, which start answering the proxy.
+// Example:
+//
+//	err := chromedp.Run(nav.CDP(), emulation.SetGeolocationOverride().WithLatitude(-23.55).WithLongitude(-46.63))
+func (nav *Navigator) CDP() context.Context {
+	return nav.Ctx
+}
+
+// RunCDP runs chromedp actions, such as the commands of the cdproto packages, in the session of nav.
+// Example:
+//
+//	var metrics []*performance.Metric
+//	err := nav.RunCDP(
+//		performance.Enable(),
+//		chromedp.ActionFunc(func(ctx context.Context) (err error) {
+//			metrics, err = performance.GetMetrics().Do(ctx)
+//			return err
+//		}),
+//	)
+func (nav *Navigator) RunCDP(actions ...chromedp.Action) error {
+	err := chromedp.Run(nav.Ctx, append([]chromedp.Action{nav.startProxyAuth()}, actions...)...)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to run CDP actions: %v\n", err)
+		return fmt.Errorf("error - failed to run CDP actions: %w", classifyError(err))
+	}
+	return nil
+}
+
+// ExecuteCDP executes a CDP command by its method name in the session of nav, for commands without a cdproto type,
+// e.g. recent or experimental ones. params, unless nil, are encoded as JSON, and the result is returned as JSON.
+// Example:
+//
+//	result, err := nav.ExecuteCDP("Browser.getVersion", nil)
+//	result, err = nav.ExecuteCDP("Emulation.setTimezoneOverride", map[string]string{"timezoneId": "America/Sao_Paulo"})
+func (nav *Navigator) ExecuteCDP(method string, params interface{}) (json.RawMessage, error) {
+	var input easyjson.Marshaler
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid params of CDP command %s: %w", method, err)
+		}
+		raw := easyjson.RawMessage(data)
+		input = &raw
+	}
+
+	var result easyjson.RawMessage
+	err := chromedp.Run(nav.Ctx, nav.startProxyAuth(), chromedp.ActionFunc(func(ctx context.Context) error {
+		return cdp.Execute(ctx, method, input, &result)
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to execute CDP command %s: %v\n", method, err)
+		return nil, fmt.Errorf("error - failed to execute CDP command %s: %w", method, classifyError(err))
+	}
+	return json.RawMessage(result), nil
+}
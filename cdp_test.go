@@ -0,0 +1,29 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestExecuteCDPWithoutBrowser(t *testing.T) {
+	nav := &Navigator{Ctx: context.Background(), Logger: log.New(io.Discard, "", 0)}
+	if nav.CDP() != nav.Ctx {
+		t.Error("Expected the context of the Navigator")
+	}
+	_, err := nav.ExecuteCDP("Browser.getVersion", nil)
+	if !errors.Is(err, chromedp.ErrInvalidContext) {
+		t.Errorf("Expected an invalid context error without a browser, but got %v", err)
+	}
+	_, err = nav.ExecuteCDP("Emulation.setTimezoneOverride", map[string]interface{}{"timezoneId": make(chan int)})
+	if err == nil {
+		t.Error("Expected an error for params that can't be encoded")
+	}
+	if err := nav.RunCDP(chromedp.Reload()); !errors.Is(err, chromedp.ErrInvalidContext) {
+		t.Errorf("Expected an invalid context error without a browser, but got %v", err)
+	}
+}
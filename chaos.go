@@ -0,0 +1,254 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosConfig sets the rate of each fault injected by a Chaos, from 0 for never to 1 for every request.
+type ChaosConfig struct {
+	Seed          int64          // the seed of the random faults, so a failing run can be repeated; random if 0
+	DelayRate     float64        // requests delayed by a random time up to MaxDelay
+	MaxDelay      time.Duration  // the longest delay, one second if not positive
+	DropRate      float64        // requests failed with a network error
+	ErrorRate     float64        // requests answered with 503 Service Unavailable
+	CrashRate     float64        // requests that crash the browser, failed with ErrBrowserCrashed outside a browser
+	ResourceTypes []ResourceType // the browser resource types affected, see EnableChaos; all if empty
+}
+
+// ChaosStats counts the faults injected by a Chaos. Seed is the seed in use, which is random when the config has none.
+type ChaosStats struct {
+	Seed         int64
+	Requests     int64
+	Delays       int64
+	Drops        int64
+	ServerErrors int64
+	Crashes      int64
+}
+
+// chaosFault is the fault injected in a request, besides its delay.
+type chaosFault int
+
+const (
+	chaosNone chaosFault = iota
+	chaosDrop
+	chaosServerError
+	chaosCrash
+)
+
+// errChaosDropped is the cause of the requests dropped by a Chaos.
+var errChaosDropped = errors.New("chaos: request dropped")
+
+// Chaos injects random delays, dropped requests, 5xx responses and browser crashes into crawls, so retry, backoff and
+// recovery paths can be tested before they are needed in production. It wraps an http.RoundTripper, a Fetcher or the
+// requests of a Navigator. It is safe for concurrent use; the faults of a seed are repeated exactly when requests are
+// made in the same order.
+type Chaos struct {
+	config ChaosConfig
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	stats ChaosStats
+}
+
+// NewChaos creates a Chaos with the config.
+// Example:
+//
+//	chaos := goSpider.NewChaos(goSpider.ChaosConfig{Seed: 42, DelayRate: 0.2, DropRate: 0.05, ErrorRate: 0.1})
+//	sink := goSpider.NewHTTPSink("https://api.example.com/lawsuits")
+//	sink.Client.Transport = chaos.Transport(nil)
+func NewChaos(config ChaosConfig) *Chaos {
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = time.Second
+	}
+	return &Chaos{
+		config: config,
+		rand:   rand.New(rand.NewSource(config.Seed)),
+		stats:  ChaosStats{Seed: config.Seed},
+	}
+}
+
+// Stats returns the requests seen and the faults injected so far.
+func (c *Chaos) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// next draws the delay and the fault of a request.
+func (c *Chaos) next() (time.Duration, chaosFault) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Requests++
+
+	var delay time.Duration
+	if c.rand.Float64() < c.config.DelayRate {
+		delay = time.Duration(c.rand.Int63n(int64(c.config.MaxDelay)))
+		c.stats.Delays++
+	}
+	r := c.rand.Float64()
+	switch {
+	case r < c.config.CrashRate:
+		c.stats.Crashes++
+		return delay, chaosCrash
+	case r < c.config.CrashRate+c.config.DropRate:
+		c.stats.Drops++
+		return delay, chaosDrop
+	case r < c.config.CrashRate+c.config.DropRate+c.config.ErrorRate:
+		c.stats.ServerErrors++
+		return delay, chaosServerError
+	}
+	return delay, chaosNone
+}
+
+// Transport wraps base, http.DefaultTransport if nil, injecting faults into the requests of an http.Client. Crashes
+// are injected as dropped requests.
+// Example:
+//
+//	fetcher := goSpider.NewHTTPFetcher(30 * time.Second)
+//	fetcher.Client.Transport = chaos.Transport(fetcher.Client.Transport)
+func (c *Chaos) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return chaosTransport{chaos: c, base: base}
+}
+
+// chaosTransport is the http.RoundTripper of Chaos.Transport.
+type chaosTransport struct {
+	chaos *Chaos
+	base  http.RoundTripper
+}
+
+func (t chaosTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	delay, fault := t.chaos.next()
+	err := sleepContext(request.Context(), delay)
+	if err != nil {
+		return nil, err
+	}
+	switch fault {
+	case chaosDrop, chaosCrash:
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return nil, errChaosDropped
+	case chaosServerError:
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return &http.Response{
+			Status:     "503 Service Unavailable",
+			StatusCode: http.StatusServiceUnavailable,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:       io.NopCloser(strings.NewReader("chaos: service unavailable")),
+			Request:    request,
+		}, nil
+	}
+	return t.base.RoundTrip(request)
+}
+
+// Fetcher wraps a Fetcher, injecting faults into its fetches: dropped requests fail with ErrNavigationFailed, 5xx
+// responses with a StatusError and crashes with ErrBrowserCrashed.
+// Example:
+//
+//	results, err := goSpider.ParallelRequests(requests, 4, 0, chaos.Fetcher(pool).Fetch)
+func (c *Chaos) Fetcher(fetcher Fetcher) Fetcher {
+	return FetcherFunc(func(url string) (*html.Node, error) {
+		delay, fault := c.next()
+		time.Sleep(delay)
+		switch fault {
+		case chaosDrop:
+			return nil, fmt.Errorf("error - failed to fetch URL: %w", withKind(ErrNavigationFailed, errChaosDropped))
+		case chaosServerError:
+			return nil, &StatusError{StatusCode: http.StatusServiceUnavailable, URL: url}
+		case chaosCrash:
+			return nil, fmt.Errorf("error - failed to fetch URL: %w", withKind(ErrBrowserCrashed, errors.New("chaos: browser crashed")))
+		}
+		return fetcher.Fetch(url)
+	})
+}
+
+// EnableChaos injects the faults of chaos into the requests of the browser, through Fetch interception: delayed
+// requests are held back, dropped ones fail with a connection reset, 5xx ones are answered with 503 and crashes close
+// the browser, so the following calls fail with ErrBrowserCrashed. Only the ResourceTypes of the config are affected,
+// e.g. goSpider.Document for page loads only. It combines with BlockResourceTypes, RestrictNavigation and cassettes,
+// which share Fetch interception: the requests without a fault go on to the cassette being replayed or recorded.
+// Calling it with nil stops injecting faults.
+// Example:
+//
+//	chaos := goSpider.NewChaos(goSpider.ChaosConfig{Seed: 7, ErrorRate: 0.2, CrashRate: 0.01, ResourceTypes: []goSpider.ResourceType{goSpider.Document}})
+//	err := nav.EnableChaos(chaos)
+func (nav *Navigator) EnableChaos(chaos *Chaos) error {
+	var rule *interceptRule
+	if chaos != nil {
+		rule = &interceptRule{types: chaos.config.ResourceTypes, handle: func(paused *fetch.EventRequestPaused) func(ctx context.Context) bool {
+			// the faults are drawn in the order the requests are paused, so a seed repeats them
+			delay, fault := chaos.next()
+			if delay == 0 && fault == chaosNone {
+				return nil
+			}
+			return func(ctx context.Context) bool {
+				if sleepContext(ctx, delay) != nil {
+					return true
+				}
+				switch fault {
+				case chaosDrop:
+					_ = chromedp.Run(ctx, fetch.FailRequest(paused.RequestID, network.ErrorReasonConnectionReset))
+				case chaosServerError:
+					_ = chromedp.Run(ctx, fetch.FulfillRequest(paused.RequestID, http.StatusServiceUnavailable).
+						WithResponseHeaders([]*fetch.HeaderEntry{{Name: "Content-Type", Value: "text/plain; charset=utf-8"}}))
+				case chaosCrash:
+					nav.Logger.Printf("Chaos: crashing the browser on %s\n", paused.Request.URL)
+					_ = chromedp.Run(ctx, browser.Close())
+				default:
+					return false
+				}
+				return true
+			}
+		}}
+	}
+	err := nav.setInterceptRule(interceptChaos, rule)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to enable chaos: %v\n", err)
+		return fmt.Errorf("error - failed to enable chaos: %w", classifyError(err))
+	}
+	if rule == nil {
+		nav.Logger.Printf("Stopped chaos\n")
+		return nil
+	}
+	nav.Logger.Printf("Chaos enabled with seed %d\n", chaos.config.Seed)
+	return nil
+}
+
+// sleepContext waits for the delay or until ctx is done.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,143 @@
+package goSpider
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestChaosSeed(t *testing.T) {
+	config := ChaosConfig{Seed: 42, DelayRate: 0.5, MaxDelay: time.Millisecond, DropRate: 0.2, ErrorRate: 0.2, CrashRate: 0.1}
+	a, b := NewChaos(config), NewChaos(config)
+	for i := 0; i < 100; i++ {
+		delayA, faultA := a.next()
+		delayB, faultB := b.next()
+		if delayA != delayB || faultA != faultB {
+			t.Fatalf("Expected the same faults for the same seed at request %d", i)
+		}
+	}
+
+	stats := a.Stats()
+	if stats.Seed != 42 || stats.Requests != 100 || stats.Drops == 0 || stats.ServerErrors == 0 || stats.Crashes == 0 ||
+		stats.Delays == 0 || stats.Drops+stats.ServerErrors+stats.Crashes > 70 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if NewChaos(ChaosConfig{}).Stats().Seed == 0 {
+		t.Errorf("Expected a random seed when none is set")
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewChaos(ChaosConfig{ErrorRate: 1}).Transport(nil)}
+	response, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("Expected a 503 without reaching the server, got %d", response.StatusCode)
+	}
+
+	client.Transport = NewChaos(ChaosConfig{DropRate: 1}).Transport(nil)
+	_, err = client.Get(server.URL)
+	if !errors.Is(err, errChaosDropped) {
+		t.Errorf("Expected a dropped request, got %v", err)
+	}
+
+	client.Transport = NewChaos(ChaosConfig{}).Transport(nil)
+	response, err = client.Get(server.URL)
+	if err != nil || response.StatusCode != http.StatusOK || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expected the request to pass through, got %v", err)
+	}
+	response.Body.Close()
+}
+
+func TestChaosHTTPSinkRetries(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer server.Close()
+
+	chaos := NewChaos(ChaosConfig{Seed: 1, DropRate: 0.3, ErrorRate: 0.3})
+	sink := NewHTTPSink(server.URL)
+	sink.MaxRetries = 20
+	sink.RetryDelay = time.Microsecond
+	sink.Client.Transport = chaos.Transport(nil)
+	err := sink.Write([]Record{{"number": "1"}, {"number": "2"}, {"number": "3"}, {"number": "4"}})
+	if err != nil {
+		t.Fatalf("Expected the retries to recover, got %v", err)
+	}
+
+	stats := chaos.Stats()
+	if atomic.LoadInt32(&received) != 4 || stats.Drops+stats.ServerErrors == 0 {
+		t.Errorf("Expected 4 records delivered after faults, got %d with %+v", received, stats)
+	}
+}
+
+func TestChaosFetcher(t *testing.T) {
+	fetcher := FetcherFunc(func(url string) (*html.Node, error) {
+		return &html.Node{Type: html.DocumentNode}, nil
+	})
+
+	_, err := NewChaos(ChaosConfig{CrashRate: 1}).Fetcher(fetcher).Fetch("https://example.com")
+	if !errors.Is(err, ErrBrowserCrashed) {
+		t.Errorf("Expected ErrBrowserCrashed, got %v", err)
+	}
+	_, err = NewChaos(ChaosConfig{DropRate: 1}).Fetcher(fetcher).Fetch("https://example.com")
+	if !errors.Is(err, ErrNavigationFailed) {
+		t.Errorf("Expected ErrNavigationFailed, got %v", err)
+	}
+	_, err = NewChaos(ChaosConfig{ErrorRate: 1}).Fetcher(fetcher).Fetch("https://example.com")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a 503 StatusError, got %v", err)
+	}
+	pageSource, err := NewChaos(ChaosConfig{}).Fetcher(fetcher).Fetch("https://example.com")
+	if err != nil || pageSource == nil {
+		t.Errorf("Expected the fetch to pass through, got %v", err)
+	}
+}
+
+func TestEnableChaos(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	pool := NewNavigatorPool(PoolOptions{Size: 1, Headless: true, Timeout: 600 * time.Millisecond})
+	defer pool.Close()
+
+	err := pool.Do(func(nav *Navigator) error {
+		err := nav.EnableChaos(NewChaos(ChaosConfig{CrashRate: 1, ResourceTypes: []ResourceType{Document}}))
+		if err != nil {
+			t.Fatalf("EnableChaos error: %v", err)
+		}
+		return nav.OpenURL(server.URL + "/test.html")
+	})
+	if err == nil {
+		t.Fatalf("Expected the crash to fail the page load")
+	}
+
+	// the pool replaces the crashed browser
+	pageSource, err := pool.Fetch(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("Fetch error after the crash: %v", err)
+	}
+	text, err := ExtractText(pageSource, "//h1", "")
+	if err != nil || text != "Main Content" {
+		t.Errorf("Expected the page content, got %q: %v", text, err)
+	}
+	if pool.Metrics().BrowserRecycles != 1 {
+		t.Errorf("Expected the crashed browser to be restarted, got %+v", pool.Metrics())
+	}
+}
@@ -0,0 +1,82 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// clipboardAccess grants the page the permissions of the clipboard and emulates the focus the clipboard API requires,
+// since a headless page is never focused.
+func clipboardAccess() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		err := browser.GrantPermissions([]browser.PermissionType{
+			browser.PermissionTypeClipboardReadWrite,
+			browser.PermissionTypeClipboardSanitizedWrite,
+		}).Do(ctx)
+		if err != nil {
+			return err
+		}
+		return emulation.SetFocusEmulationEnabled(true).Do(ctx)
+	})
+}
+
+// awaitPromise makes chromedp.Evaluate wait for the promise the script returns.
+func awaitPromise(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
+
+// ReadClipboard returns the text in the clipboard, e.g. copied by a "copy to clipboard" button of the page.
+// Example:
+//
+//	err := nav.ClickButton("#copyLink")
+//	link, err := nav.ReadClipboard()
+func (nav *Navigator) ReadClipboard() (string, error) {
+	var text string
+	err := chromedp.Run(nav.Ctx,
+		clipboardAccess(),
+		chromedp.Evaluate(`navigator.clipboard.readText()`, &text, awaitPromise),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to read clipboard: %v\n", err)
+		return "", fmt.Errorf("error - failed to read clipboard: %w", classifyError(err))
+	}
+	return text, nil
+}
+
+// WriteClipboard puts text in the clipboard, e.g. for an input that only accepts pasted text, see PasteClipboard.
+// Example:
+//
+//	err := nav.WriteClipboard("1017927-35.2023.8.26.0008")
+//	err = nav.PasteClipboard("#numeroProcesso")
+func (nav *Navigator) WriteClipboard(text string) error {
+	err := chromedp.Run(nav.Ctx,
+		clipboardAccess(),
+		chromedp.Evaluate(fmt.Sprintf(`navigator.clipboard.writeText(%s)`, jsString(text)), nil, awaitPromise),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to write clipboard: %v\n", err)
+		return fmt.Errorf("error - failed to write clipboard: %w", classifyError(err))
+	}
+	nav.Logger.Println("Clipboard written successfully")
+	return nil
+}
+
+// PasteClipboard pastes the clipboard into the field with the given selector, as a user pressing Ctrl+V, for inputs
+// that ignore typed text and only accept pasted text, see ShortcutOn.
+// Example:
+//
+//	err := nav.WriteClipboard("1017927-35.2023.8.26.0008")
+//	err = nav.PasteClipboard("#numeroProcesso")
+func (nav *Navigator) PasteClipboard(selector string) error {
+	nav.Logger.Printf("Pasting clipboard into field with selector: %s\n", selector)
+	err := chromedp.Run(nav.Ctx, clipboardAccess())
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to paste clipboard: %v\n", err)
+		return fmt.Errorf("error - failed to paste clipboard: %w", classifyError(err))
+	}
+	return nav.ShortcutOn(selector, "Ctrl+V")
+}
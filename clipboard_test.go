@@ -0,0 +1,48 @@
+package goSpider
+
+import (
+	"testing"
+)
+
+func TestClipboard(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/clipboard.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	err = nav.ClickButton("#copy")
+	if err != nil {
+		t.Fatalf("ClickButton error: %v", err)
+	}
+	text, err := nav.ReadClipboard()
+	if err != nil {
+		t.Fatalf("ReadClipboard error: %v", err)
+	}
+	if text != "https://example.com/lawsuit/1017927" {
+		t.Errorf("Expected the copied link, but got %q", text)
+	}
+
+	err = nav.WriteClipboard("1017927-35.2023.8.26.0008")
+	if err != nil {
+		t.Fatalf("WriteClipboard error: %v", err)
+	}
+	err = nav.FillField("#pasteOnly", "typed")
+	if err != nil {
+		t.Fatalf("FillField error: %v", err)
+	}
+	err = nav.PasteClipboard("#pasteOnly")
+	if err != nil {
+		t.Fatalf("PasteClipboard error: %v", err)
+	}
+	value, err := nav.EvaluateScript(`document.getElementById("pasteOnly").value`)
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	if value != "1017927-35.2023.8.26.0008" {
+		t.Errorf("Expected the pasted number, but got %v", value)
+	}
+}
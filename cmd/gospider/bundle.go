@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/DanielFillol/goSpider"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// bundleCommand packages the outputs of a job into a zip or tar.gz archive and prints its manifest as JSON.
+func bundleCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	job := fs.String("job", "", "the name of the job in the manifest, the name of the archive by default")
+	err := parseFlags(fs, args, stderr, "bundle [flags] ARCHIVE PATH...")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	archive := fs.Arg(0)
+	if *job == "" {
+		*job = archiveJob(archive)
+	}
+	manifest, err := goSpider.BundleArtifacts(archive, *job, fs.Args()[1:]...)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(manifest)
+}
+
+// archiveJob returns the name of the archive file without its directory and extension, e.g. "tjsp" for
+// "output/tjsp.tar.gz".
+func archiveJob(archive string) string {
+	name := filepath.Base(archive)
+	for _, extension := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, extension) {
+			return strings.TrimSuffix(name, extension)
+		}
+	}
+	return name
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// crawlSpec is a crawl spec file: the fields to extract from each URL.
+type crawlSpec struct {
+	URLs    []string          `json:"urls"`
+	Fields  map[string]string `json:"fields"`  // XPath expressions by name
+	Workers int               `json:"workers"` // pages loaded at once, 1 if not positive
+	Delay   string            `json:"delay"`   // the delay before each page, e.g. "1s"
+	Static  bool              `json:"static"`  // request pages with plain HTTP instead of Chrome
+	Wait    string            `json:"wait"`    // a css selector or xpath to wait for before reading each page
+}
+
+// crawlResult is the output line of a crawled URL.
+type crawlResult struct {
+	URL    string              `json:"url"`
+	Fields map[string][]string `json:"fields,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// crawlCommand runs a crawl spec file.
+func crawlCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	options := addBrowserFlags(fs)
+	reportPath := fs.String("report", "", "write a JSON report of the run to `FILE` and print its summary")
+	err := parseFlags(fs, args, stderr, "crawl [flags] SPEC")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	spec, err := parseCrawlSpec(data)
+	if err != nil {
+		return err
+	}
+	settings := spec.options(options)
+	var pool *goSpider.NavigatorPool
+	if !settings.static {
+		pool = settings.newPool(spec.Workers)
+		defer pool.Close()
+	}
+	if *reportPath == "" {
+		return runCrawl(spec, settings.poolFetch(pool), stdout)
+	}
+
+	recorder := goSpider.NewRunRecorder(fs.Arg(0))
+	err = runCrawl(spec, recorder.Track(settings.poolFetch(pool)), stdout)
+	report := recorder.Report()
+	fmt.Fprint(stderr, report)
+	data, reportErr := report.JSON()
+	if reportErr == nil {
+		reportErr = os.WriteFile(*reportPath, data, 0644)
+	}
+	if reportErr != nil {
+		return reportErr
+	}
+	return err
+}
+
+// parseCrawlSpec decodes and checks a crawl spec.
+func parseCrawlSpec(data []byte) (crawlSpec, error) {
+	var spec crawlSpec
+	err := json.Unmarshal(data, &spec)
+	if err != nil {
+		return spec, fmt.Errorf("invalid spec: %w", err)
+	}
+	if len(spec.URLs) == 0 {
+		return spec, errors.New("invalid spec: no urls")
+	}
+	if len(spec.Fields) == 0 {
+		return spec, errors.New("invalid spec: no fields")
+	}
+	if spec.Delay != "" {
+		if _, err := time.ParseDuration(spec.Delay); err != nil {
+			return spec, fmt.Errorf("invalid spec delay: %w", err)
+		}
+	}
+	if spec.Workers <= 0 {
+		spec.Workers = 1
+	}
+	return spec, nil
+}
+
+// options returns the options overridden by the settings of the spec.
+func (spec crawlSpec) options(options *browserOptions) *browserOptions {
+	settings := *options
+	settings.static = settings.static || spec.Static
+	if spec.Wait != "" {
+		settings.wait = spec.Wait
+	}
+	return &settings
+}
+
+// runCrawl loads the URLs of the spec with fetch and writes a JSON line per URL, in the order of the spec. It fails
+// when a URL failed, after every URL was crawled.
+func runCrawl(spec crawlSpec, fetch func(url string) (*html.Node, error), w io.Writer) error {
+	delay, _ := time.ParseDuration(spec.Delay)
+	fields := make([]field, 0, len(spec.Fields))
+	for name, expression := range spec.Fields {
+		fields = append(fields, field{Name: name, Expression: expression})
+	}
+	requests := make([]goSpider.Request, len(spec.URLs))
+	for i, url := range spec.URLs {
+		requests[i] = goSpider.Request{SearchString: url}
+	}
+	pages, _ := goSpider.ParallelRequests(requests, spec.Workers, delay, fetch)
+
+	// ParallelRequests returns the pages as they finish
+	order := make(map[string]int, len(spec.URLs))
+	for i := len(spec.URLs) - 1; i >= 0; i-- {
+		order[spec.URLs[i]] = i
+	}
+	sort.SliceStable(pages, func(i, j int) bool { return order[pages[i].Request] < order[pages[j].Request] })
+
+	failed := 0
+	encoder := json.NewEncoder(w)
+	for _, p := range pages {
+		result := crawlResult{URL: p.Request}
+		err := p.Error
+		if err == nil {
+			result.Fields, err = extractFields(p.Page, fields)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		err = encoder.Encode(result)
+		if err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d URLs failed", failed, len(pages))
+	}
+	return nil
+}
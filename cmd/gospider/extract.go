@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+	"io"
+	"os"
+	"strings"
+)
+
+// field is a named XPath expression, or selector chain of expressions, to extract.
+type field struct {
+	Name       string
+	Expression string
+}
+
+// extractCommand prints the text of the nodes of each expression of a URL or file.
+func extractCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	options := addBrowserFlags(fs)
+	file := fs.String("file", "", "an HTML file to read instead of a URL")
+	err := parseFlags(fs, args, stderr, "extract [flags] URL|-file FILE name=XPATH...")
+	if err != nil {
+		return err
+	}
+
+	expressions := fs.Args()
+	var pageSource *html.Node
+	if *file != "" {
+		pageSource, err = parseFile(*file)
+	} else if len(expressions) > 0 {
+		var release func()
+		var fetcher goSpider.Fetcher
+		fetcher, release = options.fetcher()
+		defer release()
+		pageSource, err = fetcher.Fetch(expressions[0])
+		expressions = expressions[1:]
+	} else {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err != nil {
+		return err
+	}
+
+	fields, err := parseFields(expressions)
+	if err != nil {
+		return err
+	}
+	values, err := extractFields(pageSource, fields)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(stdout).Encode(values)
+}
+
+// parseFile parses an HTML file.
+func parseFile(path string) (*html.Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return html.Parse(f)
+}
+
+// parseFields parses "name=expression" arguments. An argument without a name, such as "//h1", is named after its
+// expression.
+func parseFields(args []string) ([]field, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no expression to extract")
+	}
+	fields := make([]field, 0, len(args))
+	for _, arg := range args {
+		name, expression, ok := strings.Cut(arg, "=")
+		// the "=" of a predicate, as in //*[@id="x"], doesn't follow a name
+		if !ok || name == "" || strings.ContainsAny(name, "/[(@") {
+			name, expression = arg, arg
+		}
+		if expression == "" {
+			return nil, fmt.Errorf("empty expression for %s", name)
+		}
+		fields = append(fields, field{Name: name, Expression: expression})
+	}
+	return fields, nil
+}
+
+// extractFields returns the text of the nodes of each field, an empty list when none matches.
+func extractFields(pageSource *html.Node, fields []field) (map[string][]string, error) {
+	values := make(map[string][]string, len(fields))
+	for _, f := range fields {
+		values[f.Name] = []string{}
+		nodes, err := goSpider.FindNodes(pageSource, f.Expression)
+		if errors.Is(err, goSpider.ErrElementNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for %s: %w", f.Name, err)
+		}
+		for _, node := range nodes {
+			values[f.Name] = append(values[f.Name], strings.Join(strings.Fields(htmlquery.InnerText(node)), " "))
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// page is the JSON output of fetch.
+type page struct {
+	URL   string   `json:"url"`
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Links []string `json:"links"`
+	HTML  string   `json:"html"`
+}
+
+// fetchCommand renders a URL and prints it.
+func fetchCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	options := addBrowserFlags(fs)
+	format := fs.String("format", "html", "the output format: html, text or json")
+	err := parseFlags(fs, args, stderr, "fetch [flags] URL")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	fetcher, release := options.fetcher()
+	defer release()
+	pageSource, err := fetcher.Fetch(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writePage(stdout, fs.Arg(0), pageSource, *format)
+}
+
+// writePage writes the page source in the format.
+func writePage(w io.Writer, pageURL string, pageSource *html.Node, format string) error {
+	switch format {
+	case "html":
+		source, err := goSpider.ParseHtmlToString(pageSource)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, source)
+		return err
+	case "text":
+		_, err := fmt.Fprintln(w, goSpider.NormalizedText(pageSource))
+		return err
+	case "json":
+		p, err := newPage(pageURL, pageSource)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(p)
+	}
+	return fmt.Errorf("unknown format %q, expected html, text or json", format)
+}
+
+// newPage describes the page source loaded from pageURL.
+func newPage(pageURL string, pageSource *html.Node) (page, error) {
+	source, err := goSpider.ParseHtmlToString(pageSource)
+	if err != nil {
+		return page{}, err
+	}
+	p := page{URL: pageURL, Text: goSpider.NormalizedText(pageSource), Links: links(pageURL, pageSource), HTML: source}
+	if title := htmlquery.FindOne(pageSource, "//title"); title != nil {
+		p.Title = strings.TrimSpace(htmlquery.InnerText(title))
+	}
+	return p, nil
+}
+
+// links returns the absolute URLs of the links of the page, in order, without duplicates.
+func links(pageURL string, pageSource *html.Node) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+	nodes, err := goSpider.FindNodes(pageSource, "//a[@href]")
+	if err != nil && !errors.Is(err, goSpider.ErrElementNotFound) {
+		return nil
+	}
+	seen := map[string]bool{}
+	result := []string{}
+	for _, node := range nodes {
+		for _, attr := range node.Attr {
+			if attr.Key != "href" {
+				continue
+			}
+			link, err := base.Parse(strings.TrimSpace(attr.Val))
+			if err != nil || (link.Scheme != "http" && link.Scheme != "https" && link.Scheme != "file") {
+				continue
+			}
+			link.Fragment = ""
+			if !seen[link.String()] {
+				seen[link.String()] = true
+				result = append(result, link.String())
+			}
+		}
+	}
+	return result
+}
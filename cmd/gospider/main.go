@@ -0,0 +1,182 @@
+/*
+Command gospider runs common goSpider tasks without writing Go.
+
+Usage:
+
+	gospider fetch [flags] URL                   render a URL and print its HTML, text or JSON
+	gospider extract [flags] URL|-file FILE name=XPATH...
+	                                             print the text of the nodes of each expression as JSON
+	gospider crawl [flags] SPEC                  run a crawl spec file and print a JSON line per URL
+	gospider run [flags] PIPELINE [name=value...]
+	                                             run a pipeline file, see package pipeline
+	gospider script [flags] SCRIPT [name=value...]
+	                                             run a Lua-like script file, see package script
+	gospider screenshot [flags] URL              save a PNG screenshot of a page or of an element
+	gospider repl [flags] [URL]                  open a browser and run commands typed interactively
+	gospider record [flags] URL                  record a session in a browser window as Go code or a pipeline
+	gospider bundle [flags] ARCHIVE PATH...      package the outputs of a job into a zip or tar.gz archive
+	gospider serve [flags]                       start an HTTP server with the same tasks
+
+Pages are rendered by headless Chrome, or requested with plain HTTP with -static. Run "gospider COMMAND -h" for the
+flags of a command. Logs are written to stderr, so the output can be piped.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+	"io"
+	"os"
+	"time"
+)
+
+// command runs a subcommand with its arguments, writing its output to stdout and its usage to stderr.
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"fetch":      fetchCommand,
+	"extract":    extractCommand,
+	"crawl":      crawlCommand,
+	"run":        runCommand,
+	"script":     scriptCommand,
+	"screenshot": screenshotCommand,
+	"repl":       replCommand,
+	"record":     recordCommand,
+	"bundle":     bundleCommand,
+	"serve":      serveCommand,
+}
+
+const usage = `Usage: gospider COMMAND [flags]
+
+Commands:
+  fetch       render a URL and print its HTML, text or JSON
+  extract     print the text of the nodes matching XPath expressions as JSON
+  crawl       run a crawl spec file and print a JSON line per URL
+  run         run a pipeline file and print the records it outputs as JSON lines
+  script      run a script file and print the records it emits as JSON lines
+  screenshot  save a PNG screenshot of a page or of an element
+  repl        open a browser and run commands such as open, click and text interactively
+  record      record a session in a browser window and print it as Go code or a pipeline
+  bundle      package the outputs of a job into a zip or tar.gz archive and print its manifest
+  serve       start an HTTP server with the same tasks
+
+Run "gospider COMMAND -h" for the flags of a command.
+`
+
+func main() {
+	stdout := os.Stdout
+	// the library logs to os.Stdout; keep stdout for the output
+	os.Stdout = os.Stderr
+	os.Exit(run(os.Args[1:], stdout, os.Stderr))
+}
+
+// run runs the command of args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "gospider: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	err := cmd(args[1:], stdout, stderr)
+	if err == flag.ErrHelp {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gospider %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// defaultTimeout is the default of the -timeout flags, longer than goSpider.DefaultTimeout as it is also the timeout
+// of the pages.
+const defaultTimeout = 10 * time.Second
+
+// browserOptions are the flags of the commands that load pages.
+type browserOptions struct {
+	static   bool
+	headless bool
+	timeout  time.Duration
+	wait     string
+}
+
+// addBrowserFlags adds the flags of browserOptions to fs.
+func addBrowserFlags(fs *flag.FlagSet) *browserOptions {
+	options := &browserOptions{}
+	fs.BoolVar(&options.static, "static", false, "request pages with plain HTTP instead of Chrome")
+	fs.BoolVar(&options.headless, "headless", true, "run Chrome without a window")
+	fs.DurationVar(&options.timeout, "timeout", defaultTimeout, "how long to wait for pages and elements")
+	fs.StringVar(&options.wait, "wait", "", "a css selector or xpath to wait for before reading the page")
+	return options
+}
+
+// newNavigator starts a Navigator with the options.
+func (o *browserOptions) newNavigator() *goSpider.Navigator {
+	nav := goSpider.NewNavigator("", o.headless)
+	nav.SetTimeOut(o.timeout)
+	return nav
+}
+
+// fetcher returns a Fetcher loading pages as set by the options, and a function releasing it.
+func (o *browserOptions) fetcher() (goSpider.Fetcher, func()) {
+	if o.static {
+		return goSpider.NewHTTPFetcher(o.timeout), func() {}
+	}
+	nav := o.newNavigator()
+	return goSpider.FetcherFunc(func(url string) (*html.Node, error) {
+		return o.load(nav, url)
+	}), nav.Close
+}
+
+// newPool starts a NavigatorPool of size Navigators with the options.
+func (o *browserOptions) newPool(size int) *goSpider.NavigatorPool {
+	return goSpider.NewNavigatorPool(goSpider.PoolOptions{Size: size, Headless: o.headless, Timeout: o.timeout})
+}
+
+// poolFetch returns a function loading pages as set by the options in the Navigators of pool, or with plain HTTP when
+// the options are static or pool is nil.
+func (o *browserOptions) poolFetch(pool *goSpider.NavigatorPool) func(url string) (*html.Node, error) {
+	if o.static || pool == nil {
+		return goSpider.NewHTTPFetcher(o.timeout).Fetch
+	}
+	return func(url string) (*html.Node, error) {
+		var pageSource *html.Node
+		err := pool.Do(func(nav *goSpider.Navigator) error {
+			var err error
+			pageSource, err = o.load(nav, url)
+			return err
+		})
+		return pageSource, err
+	}
+}
+
+// load opens url in nav, waits for the element of the options and returns the page source.
+func (o *browserOptions) load(nav goSpider.Browser, url string) (*html.Node, error) {
+	err := nav.OpenURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if o.wait != "" {
+		err = nav.WaitForElement(o.wait, o.timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nav.GetPageSource()
+}
+
+// parseFlags parses args, printing the usage of the command to stderr on errors.
+func parseFlags(fs *flag.FlagSet, args []string, stderr io.Writer, usage string) error {
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gospider %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs.Parse(args)
+}
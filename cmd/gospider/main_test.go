@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+)
+
+func startTestSite() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<html><head><title>Page %s</title></head><body>
+			<h1>Page %s</h1>
+			<ul><li>one</li><li>two</li></ul>
+			<a href="/next">Next</a> <a href="/next#top">Top</a> <a href="mailto:x@example.com">Mail</a>
+		</body></html>`, r.URL.Path, r.URL.Path)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "Commands:") {
+		t.Errorf("Expected the usage with code 2, but got %d: %q", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"nope"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), `unknown command "nope"`) {
+		t.Errorf("Expected an unknown command error with code 2, but got %d: %q", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"fetch", "-static"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "gospider fetch [flags] URL") {
+		t.Errorf("Expected the fetch usage with code 2, but got %d: %q", code, stderr.String())
+	}
+}
+
+func TestServePrintConfig(t *testing.T) {
+	t.Setenv("GOSPIDER_SERVER_POOL", "3")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"serve", "-print-config", "-addr", ":9090"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected code 0, but got %d: %q", code, stderr.String())
+	}
+	for _, line := range []string{"navigator.timeout = 10s (default)", "server.addr = :9090 (flag -addr)",
+		"server.pool = 3 (env GOSPIDER_SERVER_POOL)"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("Expected %q in the config, but got %q", line, stdout.String())
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields([]string{"title=//h1", `//*[@id="x"]`, "items=//li"})
+	if err != nil {
+		t.Fatalf("parseFields error: %v", err)
+	}
+	expected := []field{{"title", "//h1"}, {`//*[@id="x"]`, `//*[@id="x"]`}, {"items", "//li"}}
+	for i, f := range expected {
+		if fields[i] != f {
+			t.Errorf("Expected field %d to be %+v, but got %+v", i, f, fields[i])
+		}
+	}
+	if _, err := parseFields(nil); err == nil {
+		t.Error("Expected an error without expressions")
+	}
+	if _, err := parseFields([]string{"title="}); err == nil {
+		t.Error("Expected an error for an empty expression")
+	}
+}
+
+func TestFetchStatic(t *testing.T) {
+	site := startTestSite()
+	defer site.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"fetch", "-static", "-format", "json", site.URL + "/a"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected code 0, but got %d: %s", code, stderr.String())
+	}
+	var p page
+	err := json.Unmarshal(stdout.Bytes(), &p)
+	if err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if p.Title != "Page /a" || !strings.Contains(p.Text, "Page /a") {
+		t.Errorf("Expected the title and text of the page, but got %q and %q", p.Title, p.Text)
+	}
+	// the fragment link is the same page and mailto links are dropped
+	if len(p.Links) != 1 || p.Links[0] != site.URL+"/next" {
+		t.Errorf("Expected the absolute next link only, but got %v", p.Links)
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	err := os.WriteFile(path, []byte("<html><body><h1> The   title </h1><li>one</li><li>two</li></body></html>"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "-file", path, "title=//h1", "items=//li", "none=//table"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected code 0, but got %d: %s", code, stderr.String())
+	}
+	var values map[string][]string
+	err = json.Unmarshal(stdout.Bytes(), &values)
+	if err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if strings.Join(values["title"], "|") != "The title" || strings.Join(values["items"], "|") != "one|two" {
+		t.Errorf("Unexpected values %v", values)
+	}
+	if values["none"] == nil || len(values["none"]) != 0 {
+		t.Errorf("Expected an empty list for an expression without matches, but got %v", values["none"])
+	}
+}
+
+func TestCrawlStatic(t *testing.T) {
+	site := startTestSite()
+	defer site.Close()
+
+	spec := fmt.Sprintf(`{"urls": ["%[1]s/a", "%[1]s/missing", "%[1]s/b"], "fields": {"title": "//h1"}, "workers": 2, "static": true}`, site.URL)
+	path := filepath.Join(t.TempDir(), "spec.json")
+	err := os.WriteFile(path, []byte(spec), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	code := run([]string{"crawl", "-report", reportPath, path}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "1 of 3 URLs failed") {
+		t.Errorf("Expected code 1 for the missing page, but got %d: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, but got %q", stdout.String())
+	}
+	for i, suffix := range []string{"/a", "/missing", "/b"} {
+		var result crawlResult
+		err := json.Unmarshal([]byte(lines[i]), &result)
+		if err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", lines[i], err)
+		}
+		if result.URL != site.URL+suffix {
+			t.Errorf("Expected line %d to be %s, but got %s", i, suffix, result.URL)
+		}
+		if suffix == "/missing" {
+			if result.Error == "" {
+				t.Error("Expected an error for the missing page")
+			}
+		} else if strings.Join(result.Fields["title"], "") != "Page "+suffix {
+			t.Errorf("Expected the title of %s, but got %v", suffix, result.Fields)
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "3 pages, 2 ok, 1 failed") {
+		t.Errorf("Expected the summary of the run, but got %s", stderr.String())
+	}
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Expected a report: %v", err)
+	}
+	var report goSpider.RunReport
+	err = json.Unmarshal(data, &report)
+	if err != nil || report.Pages != 3 || report.Errors["status 404"] != 1 {
+		t.Errorf("Unexpected report %s: %v", data, err)
+	}
+}
+
+func TestParseCrawlSpec(t *testing.T) {
+	spec, err := parseCrawlSpec([]byte(`{"urls": ["http://example.com"], "fields": {"title": "//h1"}}`))
+	if err != nil || spec.Workers != 1 {
+		t.Errorf("Expected a spec with 1 worker, but got %+v (%v)", spec, err)
+	}
+	for _, invalid := range []string{
+		`{"fields": {"title": "//h1"}}`,
+		`{"urls": ["http://example.com"]}`,
+		`{"urls": ["http://example.com"], "fields": {"title": "//h1"}, "delay": "soon"}`,
+		`not json`,
+	} {
+		if _, err := parseCrawlSpec([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestServeStatic(t *testing.T) {
+	site := startTestSite()
+	defer site.Close()
+
+	s := &server{options: &browserOptions{static: true, timeout: 5 * time.Second}}
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(api.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("/fetch?format=text&url=" + site.URL + "/a")
+	if code != http.StatusOK || !strings.Contains(body, "Page /a") {
+		t.Errorf("Expected the text of the page, but got %d: %q", code, body)
+	}
+	code, body = get("/extract?field=items=//li&url=" + site.URL + "/a")
+	if code != http.StatusOK || strings.TrimSpace(body) != `{"items":["one","two"]}` {
+		t.Errorf("Expected the items of the page, but got %d: %q", code, body)
+	}
+	if code, _ = get("/fetch"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without url, but got %d", code)
+	}
+	if code, _ = get("/fetch?url=" + site.URL + "/missing"); code != http.StatusBadGateway {
+		t.Errorf("Expected 502 for a missing page, but got %d", code)
+	}
+	if code, _ = get("/screenshot?url=" + site.URL + "/a"); code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 for a screenshot on a static server, but got %d", code)
+	}
+
+	spec := fmt.Sprintf(`{"urls": ["%s/a"], "fields": {"title": "//h1"}}`, site.URL)
+	resp, err := http.Post(api.URL+"/crawl?report=true", "application/json", strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("POST /crawl error: %v", err)
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	var result crawlResult
+	err = decoder.Decode(&result)
+	if err != nil || strings.Join(result.Fields["title"], "") != "Page /a" {
+		t.Errorf("Expected the crawled title, but got %+v (%v)", result, err)
+	}
+	var report struct {
+		Report goSpider.RunReport `json:"report"`
+	}
+	err = decoder.Decode(&report)
+	if err != nil || report.Report.Pages != 1 || report.Report.OK != 1 {
+		t.Errorf("Expected the report of the crawl, but got %+v (%v)", report, err)
+	}
+	if code, _ = get("/crawl"); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /crawl, but got %d", code)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := parseVars([]string{"number=1017927-35.2023.8.26.0008", "query=a=b", "empty="})
+	if err != nil || vars["number"] != "1017927-35.2023.8.26.0008" || vars["query"] != "a=b" || vars["empty"] != "" {
+		t.Errorf("Unexpected variables %v (%v)", vars, err)
+	}
+	if _, err := parseVars([]string{"number"}); err == nil {
+		t.Error("Expected an error for a variable without a value")
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := goSpider.NewPageCache(filepath.Join(dir, "cache"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cache.Set("https://example.com/search", `<html><body><input id="q"></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "search.yaml")
+	pipelineFile := "steps:\n  - action: open\n    url: https://example.com/{{page}}\n  - action: fill\n    selector: \"#q\"\n    value: x\n"
+	err = os.WriteFile(path, []byte(pipelineFile), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-dry-run", "-snapshots", filepath.Join(dir, "cache"), path, "page=search"}, &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), "selector #q: 1 match") {
+		t.Errorf("Expected the checked plan, but got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	stdout.Reset()
+	code = run([]string{"run", "-dry-run", "-snapshots", filepath.Join(dir, "cache"), path, "page=other"}, &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), "selector #q\n") {
+		t.Errorf("Expected the plan without snapshot, but got %d: %s", code, stdout.String())
+	}
+}
+
+func TestServeRun(t *testing.T) {
+	s := &server{options: &browserOptions{static: true}}
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	post := func(path, body string) int {
+		resp, err := http.Post(api.URL+path, "application/yaml", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s error: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("/run", "steps:\n  - action: scroll\n"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid pipeline, but got %d", code)
+	}
+	if code := post("/run?var=number", "steps:\n  - action: open\n    url: https://example.com\n"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid variable, but got %d", code)
+	}
+	if code := post("/run", "steps:\n  - action: open\n    url: https://example.com\n"); code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 for a pipeline on a static server, but got %d", code)
+	}
+}
+
+func TestServeScript(t *testing.T) {
+	s := &server{options: &browserOptions{static: true}}
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	post := func(path, body string) int {
+		resp, err := http.Post(api.URL+path, "text/plain", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s error: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("/script", "open("); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid script, but got %d", code)
+	}
+	if code := post("/script?var=number", `open("https://example.com")`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid variable, but got %d", code)
+	}
+	if code := post("/script", `open("https://example.com")`); code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 for a script on a static server, but got %d", code)
+	}
+	nested := "return " + strings.Repeat("(", 2e5) + "1" + strings.Repeat(")", 2e5)
+	if code := post("/script", nested); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a deeply nested script, but got %d", code)
+	}
+	if code := post("/script", "return "+strings.Repeat("1 + ", maxBodySize)+"1"); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a script over the body limit, but got %d", code)
+	}
+}
+
+// replBrowser is a Browser over a single page whose selectors are xpaths.
+type replBrowser struct {
+	goSpider.Browser
+	page    string
+	opened  []string
+	clicked []string
+	filled  map[string]string
+}
+
+func (b *replBrowser) OpenURL(url string) error {
+	b.opened = append(b.opened, url)
+	return nil
+}
+
+func (b *replBrowser) ClickButton(selector string) error {
+	b.clicked = append(b.clicked, selector)
+	return nil
+}
+
+func (b *replBrowser) FillField(selector, value string) error {
+	b.filled[selector] = value
+	return nil
+}
+
+func (b *replBrowser) GetPageSource() (*html.Node, error) {
+	return html.Parse(strings.NewReader(b.page))
+}
+
+func (b *replBrowser) GetElement(selector string) (string, error) {
+	pageSource, _ := b.GetPageSource()
+	nodes, err := goSpider.FindNodes(pageSource, selector)
+	if err != nil {
+		return "", err
+	}
+	return htmlquery.InnerText(nodes[0]), nil
+}
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	results := filepath.Join(dir, "results.jsonl")
+	screenshots := filepath.Join(dir, "screenshots")
+	if err := os.WriteFile(results, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(screenshots, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(screenshots, "page.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	archive := filepath.Join(dir, "tjsp.tar.gz")
+	code := run([]string{"bundle", archive, results, screenshots}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected code 0, but got %d: %q", code, stderr.String())
+	}
+	var manifest goSpider.ArtifactManifest
+	if err := json.Unmarshal(stdout.Bytes(), &manifest); err != nil {
+		t.Fatalf("Expected the manifest as JSON, but got %q: %v", stdout.String(), err)
+	}
+	if manifest.Job != "tjsp" || len(manifest.Files) != 2 {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	if _, err := os.Stat(archive); err != nil {
+		t.Errorf("Expected the archive to be written: %v", err)
+	}
+
+	stdout.Reset()
+	code = run([]string{"bundle", "-job", "daily", filepath.Join(dir, "out.zip"), results}, &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), `"job": "daily"`) {
+		t.Errorf("Expected the job of -job, but got %d: %q", code, stdout.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"bundle", filepath.Join(dir, "out.rar"), results}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected code 1 for an unsupported archive, but got %d: %q", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"bundle", archive}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "gospider bundle [flags] ARCHIVE PATH...") {
+		t.Errorf("Expected the bundle usage with code 2, but got %d: %q", code, stderr.String())
+	}
+}
+
+func TestREPL(t *testing.T) {
+	nav := &replBrowser{page: `<html><body><h1 id="title">Lawsuit</h1></body></html>`, filled: map[string]string{}}
+	var out bytes.Buffer
+	r := &repl{nav: nav, out: &out}
+	input := "open https://example.com\nfill \"input[name='q']\" some text\nclick #search\ntext //h1\nscroll\nexit\ntext //h1\n"
+	err := r.run(plainLines{bufio.NewScanner(strings.NewReader(input))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nav.opened) != 1 || nav.filled["input[name='q']"] != "some text" || len(nav.clicked) != 1 {
+		t.Errorf("Unexpected commands run: %v %v %v", nav.opened, nav.filled, nav.clicked)
+	}
+	if out.String() != "Lawsuit\nerror: unknown command \"scroll\", type help for the commands\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestREPLComplete(t *testing.T) {
+	nav := &replBrowser{page: `<html><body><div id="main" class="box wide"><a href="https://example.com/next">Next</a>
+<a href="/relative">Relative</a><span class="boxed"></span></div></body></html>`}
+	r := &repl{nav: nav, urls: []string{"https://example.com/"}}
+	tests := map[string][]string{
+		"s":             {"screenshot", "source"},
+		"click .b":      {".box", ".boxed"},
+		"text div #":    {"#main"},
+		"fill #m":       {"#main"},
+		"fill #main #":  nil,
+		"open https":    {"https://example.com/", "https://example.com/next"},
+		"screenshot #m": nil,
+	}
+	for line, expected := range tests {
+		if completions := r.complete(line); strings.Join(completions, " ") != strings.Join(expected, " ") {
+			t.Errorf("complete(%q) = %v, expected %v", line, completions, expected)
+		}
+	}
+
+	var out bytes.Buffer
+	editor := &lineEditor{in: bufio.NewReader(strings.NewReader("cl\t.b\t\t\x7fxed\r\x1b[A\r\x04")), out: &out, fd: -1, complete: r.complete}
+	for _, expected := range []string{"click .boxed", "click .boxed"} {
+		line, err := editor.readLine("> ")
+		if err != nil || line != expected {
+			t.Errorf("Expected the line %q, but got %q (%v)", expected, line, err)
+		}
+	}
+	if _, err := editor.readLine("> "); err != io.EOF {
+		t.Errorf("Expected io.EOF on Ctrl-D, but got %v", err)
+	}
+	if !strings.Contains(out.String(), ".box  .boxed") {
+		t.Errorf("Expected the completions to be listed, but got %q", out.String())
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"io"
+	"os"
+)
+
+// recordCommand opens a browser window on a URL, records what the user does in it until Enter is pressed and prints
+// the Go code, or the pipeline, repeating it.
+func recordCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	options := &browserOptions{}
+	fs.DurationVar(&options.timeout, "timeout", defaultTimeout, "how long to wait for pages and elements")
+	format := fs.String("format", "go", "the output format: go or pipeline")
+	name := fs.String("name", "recorded", "the name of the pipeline")
+	output := fs.String("o", "-", "the file to write, - for stdout")
+	err := parseFlags(fs, args, stderr, "record [flags] URL")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*format != "go" && *format != "pipeline") {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	nav := options.newNavigator()
+	defer nav.Close()
+	recorder, err := nav.RecordSession()
+	if err != nil {
+		return err
+	}
+	err = nav.OpenURL(fs.Arg(0))
+	if err != nil {
+		recorder.Stop()
+		return err
+	}
+	fmt.Fprint(stderr, "Recording: use the browser window, then press Enter here to stop.\n")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	actions := recorder.Stop()
+
+	code := goSpider.GenerateGoCode(actions)
+	if *format == "pipeline" {
+		code = goSpider.GeneratePipeline(*name, actions)
+	}
+	if *output == "-" {
+		_, err = io.WriteString(stdout, code)
+		return err
+	}
+	return os.WriteFile(*output, []byte(code), 0644)
+}
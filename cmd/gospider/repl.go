@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// replPrompt is the prompt of the REPL on a terminal.
+const replPrompt = "gospider> "
+
+// replHelp lists the commands of the REPL.
+const replHelp = `Commands:
+  open URL                     open a page
+  click SELECTOR               click an element
+  fill SELECTOR TEXT           fill a field; quote the selector if it has spaces
+  text SELECTOR                print the text of an element
+  source [SELECTOR]            print the HTML of the page or of an element
+  screenshot FILE [SELECTOR]   save a PNG screenshot of the page or of an element
+  help                         print this help
+  exit                         close the browser and quit
+
+Selectors are css selectors or xpaths. Tab completes the commands, the ids and classes of the page and URLs.
+`
+
+// errQuit is returned by repl.exec for the exit command.
+var errQuit = errors.New("quit")
+
+// replCommand opens a Navigator and runs the commands typed on stdin, so selectors can be tried out one by one before
+// writing a crawl. Chrome shows its window unless -headless is set.
+func replCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	options := &browserOptions{}
+	fs.BoolVar(&options.headless, "headless", false, "run Chrome without a window")
+	fs.DurationVar(&options.timeout, "timeout", defaultTimeout, "how long to wait for pages and elements")
+	err := parseFlags(fs, args, stderr, "repl [flags] [URL]")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	nav := options.newNavigator()
+	defer nav.Close()
+	r := &repl{
+		nav: nav,
+		out: stdout,
+		screenshot: func(selector string) ([]byte, error) {
+			return pageScreenshot(nav, selector)
+		},
+	}
+	if fs.NArg() == 1 {
+		r.report(r.exec("open " + fs.Arg(0)))
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !isTerminal(fd) {
+		return r.run(plainLines{bufio.NewScanner(os.Stdin)})
+	}
+	fmt.Fprint(stdout, "Type help for the commands, tab to complete.\n")
+	return r.run(&lineEditor{in: bufio.NewReader(os.Stdin), out: stdout, fd: fd, complete: r.complete})
+}
+
+// repl is the state of a REPL session.
+type repl struct {
+	nav        goSpider.Browser
+	out        io.Writer
+	screenshot func(selector string) ([]byte, error)
+	page       *html.Node // the page source for completions, read again after each command changing the page
+	urls       []string   // the URLs opened, for completions
+}
+
+// lineReader reads the lines of the REPL.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// plainLines reads lines without prompts nor completions, e.g. when stdin is a file.
+type plainLines struct {
+	scanner *bufio.Scanner
+}
+
+func (l plainLines) readLine(string) (string, error) {
+	if !l.scanner.Scan() {
+		if err := l.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return l.scanner.Text(), nil
+}
+
+// run executes the lines until the end of the input or the exit command. Failing commands are reported without
+// stopping the session.
+func (r *repl) run(lines lineReader) error {
+	for {
+		line, err := lines.readLine(replPrompt)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = r.exec(strings.TrimSpace(line))
+		if err == errQuit {
+			return nil
+		}
+		r.report(err)
+	}
+}
+
+// report prints the error of a command, if any.
+func (r *repl) report(err error) {
+	if err != nil {
+		fmt.Fprintf(r.out, "error: %v\n", err)
+	}
+}
+
+// exec runs a command line.
+func (r *repl) exec(line string) error {
+	name, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	required := func(arg, usage string) error {
+		if arg == "" {
+			return fmt.Errorf("usage: %s", usage)
+		}
+		return nil
+	}
+
+	switch name {
+	case "":
+		return nil
+	case "help":
+		fmt.Fprint(r.out, replHelp)
+		return nil
+	case "exit", "quit":
+		return errQuit
+	case "open":
+		if err := required(rest, "open URL"); err != nil {
+			return err
+		}
+		r.page = nil
+		err := r.nav.OpenURL(rest)
+		if err != nil {
+			return err
+		}
+		r.urls = append(r.urls, rest)
+		return nil
+	case "click":
+		if err := required(rest, "click SELECTOR"); err != nil {
+			return err
+		}
+		r.page = nil
+		return r.nav.ClickButton(rest)
+	case "fill":
+		selector, value := splitArg(rest)
+		if err := required(value, "fill SELECTOR TEXT"); err != nil {
+			return err
+		}
+		r.page = nil
+		return r.nav.FillField(selector, value)
+	case "text":
+		if err := required(rest, "text SELECTOR"); err != nil {
+			return err
+		}
+		text, err := r.nav.GetElement(rest)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(r.out, text)
+		return nil
+	case "source":
+		var pageSource *html.Node
+		var err error
+		if rest == "" {
+			pageSource, err = r.nav.GetPageSource()
+		} else {
+			pageSource, err = r.nav.GetPageSourceOf(rest)
+		}
+		if err != nil {
+			return err
+		}
+		err = html.Render(r.out, pageSource)
+		fmt.Fprintln(r.out)
+		return err
+	case "screenshot":
+		file, selector := splitArg(rest)
+		if err := required(file, "screenshot FILE [SELECTOR]"); err != nil {
+			return err
+		}
+		image, err := r.screenshot(selector)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(file, image, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "saved %s\n", file)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q, type help for the commands", name)
+}
+
+// splitArg splits the first argument, which may be quoted, from the rest of the line.
+func splitArg(line string) (string, string) {
+	if len(line) > 0 && (line[0] == '"' || line[0] == '\'') {
+		if end := strings.IndexByte(line[1:], line[0]); end >= 0 {
+			return line[1 : end+1], strings.TrimSpace(line[end+2:])
+		}
+	}
+	arg, rest, _ := strings.Cut(line, " ")
+	return arg, strings.TrimSpace(rest)
+}
+
+// replCommands are the commands completed by the REPL.
+var replCommands = []string{"click", "exit", "fill", "help", "open", "screenshot", "source", "text"}
+
+// complete returns the completions of the last word of line: a command name, a URL for open, or an id or a class of
+// the page as a css selector for the commands taking a selector.
+func (r *repl) complete(line string) []string {
+	words := strings.Split(line, " ")
+	word := words[len(words)-1]
+	var candidates []string
+	switch {
+	case len(words) == 1:
+		candidates = replCommands
+	case words[0] == "open" && len(words) == 2:
+		candidates = r.urlCandidates()
+	case words[0] == "click" || words[0] == "text" || words[0] == "source",
+		words[0] == "fill" && len(words) == 2,
+		words[0] == "screenshot" && len(words) > 2:
+		candidates = r.selectorCandidates()
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, candidate)
+		}
+	}
+	return completions
+}
+
+// pageSource returns the page source for completions, or nil when it can't be read.
+func (r *repl) pageSource() *html.Node {
+	if r.page == nil {
+		r.page, _ = r.nav.GetPageSource()
+	}
+	return r.page
+}
+
+// selectorCandidates returns the ids and the classes of the page as css selectors.
+func (r *repl) selectorCandidates() []string {
+	seen := map[string]bool{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "id":
+					if attr.Val != "" && !strings.ContainsAny(attr.Val, " .#") {
+						seen["#"+attr.Val] = true
+					}
+				case "class":
+					for _, class := range strings.Fields(attr.Val) {
+						seen["."+class] = true
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	if page := r.pageSource(); page != nil {
+		walk(page)
+	}
+	return sortedKeys(seen)
+}
+
+// urlCandidates returns the URLs opened and the absolute links of the page.
+func (r *repl) urlCandidates() []string {
+	seen := map[string]bool{}
+	for _, url := range r.urls {
+		seen[url] = true
+	}
+	if page := r.pageSource(); page != nil {
+		nodes, _ := goSpider.FindNodes(page, "//a[starts-with(@href, 'http')]")
+		for _, n := range nodes {
+			for _, attr := range n.Attr {
+				if attr.Key == "href" {
+					seen[attr.Val] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// sortedKeys returns the keys of set in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lineEditor reads lines typed on a terminal in raw mode, echoing them itself, with tab completion and the history of
+// the session on the up and down keys.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int // the terminal put in raw mode while reading; none if negative
+	complete func(line string) []string
+	history  []string
+}
+
+// readLine reads a line. Ctrl-C discards the line and Ctrl-D on an empty line ends the input with io.EOF.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	var line []rune
+	position := len(e.history) // the entry of the history shown; the line being typed when past the end
+	fmt.Fprint(e.out, prompt)
+	for {
+		key, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			if len(line) > 0 {
+				e.history = append(e.history, string(line))
+			}
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", nil
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		case 8, 127: // backspace
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Fprint(e.out, "\b \b")
+			}
+		case '\t':
+			line = e.completeLine(prompt, line)
+		case 27: // escape sequences; only the up and down keys are handled
+			if next, _ := e.in.ReadByte(); next != '[' {
+				continue
+			}
+			switch code, _ := e.in.ReadByte(); {
+			case code == 'A' && position > 0:
+				position--
+			case code == 'B' && position < len(e.history):
+				position++
+			default:
+				continue
+			}
+			line = nil
+			if position < len(e.history) {
+				line = []rune(e.history[position])
+			}
+			e.redraw(prompt, string(line))
+		default:
+			if unicode.IsPrint(key) {
+				line = append(line, key)
+				fmt.Fprint(e.out, string(key))
+			}
+		}
+	}
+}
+
+// completeLine completes the last word of line: with the completion when there is one, with the prefix shared by the
+// completions when longer than the word, or else by listing them.
+func (e *lineEditor) completeLine(prompt string, line []rune) []rune {
+	if e.complete == nil {
+		return line
+	}
+	text := string(line)
+	start := strings.LastIndex(text, " ") + 1
+	completions := e.complete(text)
+	switch len(completions) {
+	case 0:
+		return line
+	case 1:
+		text = text[:start] + completions[0] + " "
+	default:
+		prefix := commonPrefix(completions)
+		if len(prefix) > len(text)-start {
+			text = text[:start] + prefix
+		} else {
+			fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(completions, "  "))
+		}
+	}
+	e.redraw(prompt, text)
+	return []rune(text)
+}
+
+// redraw replaces the line on the terminal.
+func (e *lineEditor) redraw(prompt, line string) {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", prompt, line)
+}
+
+// commonPrefix returns the prefix shared by the words.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/pipeline"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// runCommand runs a pipeline file, printing the records of its output steps without a destination as JSON lines. With
+// -dry-run, it prints the plan of the pipeline instead, without Chrome nor the network.
+func runCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	options := &browserOptions{}
+	fs.BoolVar(&options.headless, "headless", true, "run Chrome without a window")
+	fs.DurationVar(&options.timeout, "timeout", defaultTimeout, "how long to wait for pages and elements")
+	dir := fs.String("dir", ".", "the directory of the files of the output steps")
+	dryRun := fs.Bool("dry-run", false, "print the planned steps and selectors without running them")
+	snapshots := fs.String("snapshots", "", "a page cache directory to check the selectors of a dry run against")
+	err := parseFlags(fs, args, stderr, "run [flags] PIPELINE [name=value...]")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	p, err := pipeline.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	vars, err := parseVars(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return dryRunPipeline(p, vars, *snapshots, stdout, stderr)
+	}
+	nav := options.newNavigator()
+	defer nav.Close()
+	runner := &pipeline.Runner{Output: stdout, Dir: *dir, Getenv: os.Getenv}
+	_, err = runner.Run(nav, p, vars)
+	return err
+}
+
+// dryRunPipeline prints the plan of p, failing when a selector matches nothing in the snapshots.
+func dryRunPipeline(p *pipeline.Pipeline, vars map[string]string, snapshots string, stdout, stderr io.Writer) error {
+	var cache *goSpider.PageCache
+	if snapshots != "" {
+		var err error
+		// snapshots are checked however old they are
+		cache, err = goSpider.NewPageCache(snapshots, 100*365*24*time.Hour)
+		if err != nil {
+			return err
+		}
+	}
+	plan := pipeline.DryRun(p, vars, cache)
+	fmt.Fprint(stdout, plan)
+	problems := plan.Problems()
+	for _, problem := range problems {
+		fmt.Fprintln(stderr, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("dry run found %d problems", len(problems))
+	}
+	return nil
+}
+
+// parseVars parses "name=value" arguments.
+func parseVars(args []string) (map[string]string, error) {
+	vars := make(map[string]string, len(args))
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected name=value", arg)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
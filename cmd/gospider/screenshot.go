@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"github.com/DanielFillol/goSpider"
+	"github.com/chromedp/chromedp"
+	"io"
+	"os"
+)
+
+// screenshotCommand saves a PNG screenshot of a page, or of an element of the page.
+func screenshotCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("screenshot", flag.ContinueOnError)
+	options := addBrowserFlags(fs)
+	output := fs.String("o", "screenshot.png", "the PNG file to write, - for stdout")
+	selector := fs.String("selector", "", "a css selector or xpath of the element to capture instead of the page")
+	err := parseFlags(fs, args, stderr, "screenshot [flags] URL")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	nav := options.newNavigator()
+	defer nav.Close()
+	image, err := options.screenshot(nav, fs.Arg(0), *selector)
+	if err != nil {
+		return err
+	}
+	if *output == "-" {
+		_, err = stdout.Write(image)
+		return err
+	}
+	return os.WriteFile(*output, image, 0644)
+}
+
+// screenshot opens url in nav and returns a PNG screenshot of the element of selector, or of the whole page when
+// selector is empty.
+func (o *browserOptions) screenshot(nav *goSpider.Navigator, url, selector string) ([]byte, error) {
+	err := nav.OpenURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if o.wait != "" {
+		err = nav.WaitForElement(o.wait, o.timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pageScreenshot(nav, selector)
+}
+
+// pageScreenshot returns a PNG screenshot of the element of selector in the page open in nav, or of the whole page
+// when selector is empty.
+func pageScreenshot(nav *goSpider.Navigator, selector string) ([]byte, error) {
+	if selector != "" {
+		return nav.ElementScreenshot(selector)
+	}
+	var image []byte
+	err := chromedp.Run(nav.Ctx, chromedp.FullScreenshot(&image, 100))
+	return image, err
+}
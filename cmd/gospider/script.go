@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"github.com/DanielFillol/goSpider/script"
+	"io"
+	"os"
+)
+
+// scriptCommand runs a script file, printing the records it emits without a sink as JSON lines.
+func scriptCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("script", flag.ContinueOnError)
+	options := &browserOptions{}
+	fs.BoolVar(&options.headless, "headless", true, "run Chrome without a window")
+	fs.DurationVar(&options.timeout, "timeout", defaultTimeout, "how long to wait for pages and elements")
+	err := parseFlags(fs, args, stderr, "script [flags] SCRIPT [name=value...]")
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	s, err := script.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	vars, err := parseVars(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	nav := options.newNavigator()
+	defer nav.Close()
+	runner := &script.Runner{Output: stdout, Getenv: os.Getenv}
+	_, err = runner.Run(nav, s, vars)
+	return err
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/pipeline"
+	"github.com/DanielFillol/goSpider/script"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// scriptMaxSteps and scriptTimeout stop the runaway scripts sent to the server.
+	scriptMaxSteps = 1000000
+	scriptTimeout  = 5 * time.Minute
+	// maxBodySize limits the crawl specs, pipelines and scripts posted to the server.
+	maxBodySize = 1 << 20
+)
+
+// serveCommand starts an HTTP server running the tasks of the other commands. Its settings are layered by
+// goSpider.Config: defaults, the -config file, the GOSPIDER_* environment variables and the flags.
+func serveCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	config := goSpider.DefaultConfig()
+	config.Navigator.Timeout = defaultTimeout
+	flags := config.AddFlags(fs)
+	configFile := fs.String("config", "", "a JSON config file, see goSpider.Config")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration and exit")
+	options := &browserOptions{}
+	fs.BoolVar(&options.static, "static", false, "request pages with plain HTTP instead of Chrome")
+	fs.StringVar(&options.wait, "wait", "", "a css selector or xpath to wait for before reading the page")
+	err := parseFlags(fs, args, stderr, "serve [flags]")
+	if err != nil {
+		return err
+	}
+	err = config.Load(*configFile, os.Getenv, flags)
+	if err != nil {
+		return err
+	}
+	if *printConfig {
+		return goSpider.PrintEffectiveConfig(stdout, config)
+	}
+	options.headless = config.Navigator.Headless
+	options.timeout = config.Navigator.Timeout
+
+	s := &server{options: options, artifacts: config.Server.Artifacts}
+	if !options.static {
+		s.pool = goSpider.NewNavigatorPool(config.PoolOptions())
+		defer s.pool.Close()
+	}
+	fmt.Fprintf(stdout, "Listening on %s\n", config.Server.Addr)
+	return http.ListenAndServe(config.Server.Addr, s.handler())
+}
+
+// server serves the tasks of the commands over HTTP, rendering pages in a shared pool.
+type server struct {
+	options   *browserOptions
+	pool      *goSpider.NavigatorPool // nil when the options are static
+	artifacts string
+}
+
+// handler returns the routes of the server:
+//
+//	GET  /fetch?url=URL&format=html|text|json
+//	GET  /extract?url=URL&field=name=XPATH...
+//	GET  /screenshot?url=URL&selector=SELECTOR
+//	POST /crawl?report=true with a crawl spec, returning a JSON line per URL, and a line with the report of the run
+//	POST /run?var=name=value... with a pipeline, returning the records it outputs as JSON lines
+//	POST /script?var=name=value... with a script, returning the records it emits as JSON lines
+//	GET  /artifacts/JOB/... when an artifacts directory is set
+//
+// Pass static=true to request a page with plain HTTP instead of Chrome.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fetch", s.fetch)
+	mux.HandleFunc("/extract", s.extract)
+	mux.HandleFunc("/screenshot", s.screenshot)
+	mux.HandleFunc("/crawl", s.crawl)
+	mux.HandleFunc("/run", s.run)
+	mux.HandleFunc("/script", s.script)
+	if s.artifacts != "" {
+		mux.Handle("/artifacts/", http.StripPrefix("/artifacts/", goSpider.ArtifactHandler(s.artifacts)))
+	}
+	return mux
+}
+
+// requestOptions returns the options of the server overridden by the static parameter of r.
+func (s *server) requestOptions(r *http.Request) *browserOptions {
+	options := *s.options
+	options.static = options.static || r.URL.Query().Get("static") == "true"
+	return &options
+}
+
+func (s *server) fetch(w http.ResponseWriter, r *http.Request) {
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		http.Error(w, "missing url", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+	pageSource, err := s.requestOptions(r).poolFetch(s.pool)(url)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	err = writePage(w, url, pageSource, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func (s *server) extract(w http.ResponseWriter, r *http.Request) {
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		http.Error(w, "missing url", http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(r.URL.Query()["field"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pageSource, err := s.requestOptions(r).poolFetch(s.pool)(url)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	values, err := extractFields(pageSource, fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(values)
+}
+
+func (s *server) screenshot(w http.ResponseWriter, r *http.Request) {
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		http.Error(w, "missing url", http.StatusBadRequest)
+		return
+	}
+	if s.pool == nil {
+		http.Error(w, "screenshots need Chrome, the server is static", http.StatusNotImplemented)
+		return
+	}
+	var image []byte
+	err := s.pool.Do(func(nav *goSpider.Navigator) error {
+		var err error
+		image, err = s.options.screenshot(nav, url, r.URL.Query().Get("selector"))
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(image)
+}
+
+func (s *server) crawl(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST a crawl spec", http.StatusMethodNotAllowed)
+		return
+	}
+	data, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	spec, err := parseCrawlSpec(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	fetch := spec.options(s.requestOptions(r)).poolFetch(s.pool)
+	var recorder *goSpider.RunRecorder
+	if r.URL.Query().Get("report") == "true" {
+		recorder = goSpider.NewRunRecorder("crawl")
+		fetch = recorder.Track(fetch)
+	}
+	// failed URLs are reported in their lines
+	err = runCrawl(spec, fetch, w)
+	if err != nil {
+		log.Printf("Crawl: %v\n", err)
+	}
+	if recorder != nil {
+		json.NewEncoder(w).Encode(map[string]*goSpider.RunReport{"report": recorder.Report()})
+	}
+}
+
+func (s *server) run(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST a pipeline", http.StatusMethodNotAllowed)
+		return
+	}
+	data, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	p, err := pipeline.Parse(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	vars, err := parseVars(r.URL.Query()["var"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.pool == nil {
+		http.Error(w, "pipelines need Chrome, the server is static", http.StatusNotImplemented)
+		return
+	}
+
+	// pipelines sent to the server can't write files or read the environment
+	var output bytes.Buffer
+	runner := &pipeline.Runner{Output: &output}
+	err = s.pool.Do(func(nav *goSpider.Navigator) error {
+		_, err := runner.Run(nav, p, vars)
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Write(output.Bytes())
+}
+
+func (s *server) script(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST a script", http.StatusMethodNotAllowed)
+		return
+	}
+	data, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	sc, err := script.Parse("script", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	vars, err := parseVars(r.URL.Query()["var"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.pool == nil {
+		http.Error(w, "scripts need Chrome, the server is static", http.StatusNotImplemented)
+		return
+	}
+
+	// scripts sent to the server can't read the environment nor run forever
+	var output bytes.Buffer
+	runner := &script.Runner{Output: &output, MaxSteps: scriptMaxSteps, Timeout: scriptTimeout}
+	err = s.pool.Do(func(nav *goSpider.Navigator) error {
+		_, err := runner.Run(nav, sc, vars)
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Write(output.Bytes())
+}
+
+// readBody reads the body of the request up to maxBodySize, answering the request with an error when it can't.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("the body is larger than %d bytes", maxBodySize), http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// isTerminal reports whether fd is a terminal. Terminals are only supported on Linux and macOS, elsewhere the REPL
+// reads plain lines.
+func isTerminal(fd int) bool {
+	return false
+}
+
+// makeRaw fails as terminals are not supported.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("terminal not supported")
+}
@@ -0,0 +1,258 @@
+/*
+Package cnj parses, validates and formats lawsuit numbers in the unified CNJ format
+(NNNNNNN-DD.AAAA.J.TR.OOOO) defined by Resolution 65/2008 of the Conselho Nacional de Justiça.
+
+Validating numbers before handing them to a crawler avoids spending a browser session on inputs
+that the court systems would reject anyway.
+*/
+package cnj
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidFormat is returned when the input does not contain exactly the 20 digits of a CNJ number.
+	ErrInvalidFormat = errors.New("cnj: number must have 20 digits in the format NNNNNNN-DD.AAAA.J.TR.OOOO")
+	// ErrInvalidCheckDigits is returned when the DD segment does not match the computed check digits.
+	ErrInvalidCheckDigits = errors.New("cnj: invalid check digits")
+	// ErrInvalidSegment is returned when the J segment is not a known branch of the judiciary.
+	ErrInvalidSegment = errors.New("cnj: invalid judiciary segment")
+)
+
+// Number holds the segments of a CNJ lawsuit number.
+type Number struct {
+	Sequence    string // NNNNNNN: sequential number of the lawsuit in the origin unit
+	CheckDigits string // DD: check digits (ISO 7064 mod 97-10)
+	Year        string // AAAA: year the lawsuit was filed
+	Segment     string // J: branch of the judiciary
+	Court       string // TR: court within the segment
+	Origin      string // OOOO: origin unit
+}
+
+// Parse extracts the segments of a CNJ number and validates its check digits.
+// Both the formatted ("1017927-35.2023.8.26.0008") and the digits only ("10179273520238260008") forms are accepted.
+// Example:
+//
+//	n, err := cnj.Parse("1017927-35.2023.8.26.0008")
+func Parse(s string) (Number, error) {
+	digits := onlyDigits(s)
+	if len(digits) != 20 || !wellFormed(s) {
+		return Number{}, ErrInvalidFormat
+	}
+
+	n := Number{
+		Sequence:    digits[0:7],
+		CheckDigits: digits[7:9],
+		Year:        digits[9:13],
+		Segment:     digits[13:14],
+		Court:       digits[14:16],
+		Origin:      digits[16:20],
+	}
+
+	if _, ok := segmentNames[n.Segment]; !ok {
+		return Number{}, ErrInvalidSegment
+	}
+
+	dd, err := CheckDigits(n.Sequence, n.Year, n.Segment, n.Court, n.Origin)
+	if err != nil {
+		return Number{}, err
+	}
+	if dd != n.CheckDigits {
+		return Number{}, fmt.Errorf("%w: expected %s, got %s", ErrInvalidCheckDigits, dd, n.CheckDigits)
+	}
+
+	return n, nil
+}
+
+// Validate returns nil if s is a well-formed CNJ number with valid check digits.
+// Example:
+//
+//	err := cnj.Validate("1017927-35.2023.8.26.0008")
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// IsValid reports whether s is a well-formed CNJ number with valid check digits.
+func IsValid(s string) bool {
+	return Validate(s) == nil
+}
+
+// Normalize validates s and returns it in the canonical NNNNNNN-DD.AAAA.J.TR.OOOO format.
+// Example:
+//
+//	number, err := cnj.Normalize("10179273520238260008") // "1017927-35.2023.8.26.0008"
+func Normalize(s string) (string, error) {
+	n, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
+
+// CheckDigits computes the DD segment for the remaining segments of a CNJ number.
+// Example:
+//
+//	dd, err := cnj.CheckDigits("1017927", "2023", "8", "26", "0008") // "35"
+func CheckDigits(sequence, year, segment, court, origin string) (string, error) {
+	if len(sequence) != 7 || len(year) != 4 || len(segment) != 1 || len(court) != 2 || len(origin) != 4 {
+		return "", ErrInvalidFormat
+	}
+	digits := sequence + year + segment + court + origin + "00"
+	if onlyDigits(digits) != digits {
+		return "", ErrInvalidFormat
+	}
+	return fmt.Sprintf("%02d", 98-mod97(digits)), nil
+}
+
+// String returns the number in the canonical NNNNNNN-DD.AAAA.J.TR.OOOO format.
+func (n Number) String() string {
+	return n.Sequence + "-" + n.CheckDigits + "." + n.Year + "." + n.Segment + "." + n.Court + "." + n.Origin
+}
+
+// Digits returns the number as a string of 20 digits without separators.
+func (n Number) Digits() string {
+	return n.Sequence + n.CheckDigits + n.Year + n.Segment + n.Court + n.Origin
+}
+
+// FilingYear returns the AAAA segment as an int.
+func (n Number) FilingYear() int {
+	year, _ := strconv.Atoi(n.Year)
+	return year
+}
+
+// SegmentName returns the name of the branch of the judiciary encoded in the J segment.
+func (n Number) SegmentName() string {
+	return segmentNames[n.Segment]
+}
+
+// Tribunal returns the acronym of the court encoded in the J.TR segments, e.g. "TJSP", "TRF3" or "TRT2".
+// An empty string is returned when the combination is unknown.
+func (n Number) Tribunal() string {
+	switch n.Segment {
+	case "1":
+		return "STF"
+	case "2":
+		return "CNJ"
+	case "3":
+		return "STJ"
+	case "4":
+		if n.Court == "90" {
+			return "CJF"
+		}
+		if tr, err := strconv.Atoi(n.Court); err == nil && tr >= 1 && tr <= 6 {
+			return "TRF" + strconv.Itoa(tr)
+		}
+	case "5":
+		if n.Court == "90" {
+			return "TST"
+		}
+		if tr, err := strconv.Atoi(n.Court); err == nil && tr >= 1 && tr <= 24 {
+			return "TRT" + strconv.Itoa(tr)
+		}
+	case "6":
+		if uf, ok := states[n.Court]; ok {
+			return "TRE" + uf
+		}
+		if n.Court == "00" {
+			return "TSE"
+		}
+	case "7":
+		return "STM"
+	case "8":
+		if uf, ok := states[n.Court]; ok {
+			return "TJ" + uf
+		}
+	case "9":
+		switch n.Court {
+		case "13":
+			return "TJMMG"
+		case "21":
+			return "TJMRS"
+		case "26":
+			return "TJMSP"
+		}
+	}
+	return ""
+}
+
+// State returns the federation unit (UF) of state, electoral and state military courts, or an empty string.
+func (n Number) State() string {
+	switch n.Segment {
+	case "6", "8", "9":
+		return states[n.Court]
+	}
+	return ""
+}
+
+var segmentNames = map[string]string{
+	"1": "Supremo Tribunal Federal",
+	"2": "Conselho Nacional de Justiça",
+	"3": "Superior Tribunal de Justiça",
+	"4": "Justiça Federal",
+	"5": "Justiça do Trabalho",
+	"6": "Justiça Eleitoral",
+	"7": "Justiça Militar da União",
+	"8": "Justiça dos Estados e do Distrito Federal e Territórios",
+	"9": "Justiça Militar Estadual",
+}
+
+// states maps the TR segment of state-level courts to their federation unit.
+var states = map[string]string{
+	"01": "AC", "02": "AL", "03": "AP", "04": "AM", "05": "BA", "06": "CE", "07": "DF",
+	"08": "ES", "09": "GO", "10": "MA", "11": "MT", "12": "MS", "13": "MG", "14": "PA",
+	"15": "PB", "16": "PR", "17": "PE", "18": "PI", "19": "RJ", "20": "RN", "21": "RS",
+	"22": "RO", "23": "RR", "24": "SC", "25": "SE", "26": "SP", "27": "TO",
+}
+
+// mod97 computes the remainder of a long decimal string divided by 97 without overflowing.
+func mod97(digits string) int {
+	r := 0
+	for _, c := range digits {
+		r = (r*10 + int(c-'0')) % 97
+	}
+	return r
+}
+
+// onlyDigits strips every non-digit rune from s.
+func onlyDigits(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// wellFormed reports whether s is either a bare 20 digit string or uses the separators of the canonical format.
+func wellFormed(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) == 20 {
+		return onlyDigits(s) == s
+	}
+	if len(s) != 25 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 7:
+			if c != '-' {
+				return false
+			}
+		case 10, 15, 17, 20:
+			if c != '.' {
+				return false
+			}
+		default:
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package cnj
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	n, err := Parse("1017927-35.2023.8.26.0008")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	if n.Sequence != "1017927" || n.CheckDigits != "35" || n.Year != "2023" || n.Segment != "8" || n.Court != "26" || n.Origin != "0008" {
+		t.Errorf("Unexpected segments: %+v", n)
+	}
+	if n.Tribunal() != "TJSP" {
+		t.Errorf("Expected tribunal TJSP, but got: %s", n.Tribunal())
+	}
+	if n.State() != "SP" {
+		t.Errorf("Expected state SP, but got: %s", n.State())
+	}
+	if n.FilingYear() != 2023 {
+		t.Errorf("Expected year 2023, but got: %d", n.FilingYear())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"1017927-35.2023.8.26.0008",
+		"0002396-75.2013.8.26.0201",
+		"1551285-50.2021.8.26.0477",
+		"1000113-34.2018.5.02.0386",
+		"10245117020228260003",
+	}
+	for _, s := range valid {
+		if err := Validate(s); err != nil {
+			t.Errorf("Expected %s to be valid, but got: %v", s, err)
+		}
+	}
+
+	invalid := map[string]error{
+		"1017927-36.2023.8.26.0008": ErrInvalidCheckDigits,
+		"1017927-35.2023.8.26.000":  ErrInvalidFormat,
+		"1017927.35-2023.8.26.0008": ErrInvalidFormat,
+		"abc":                       ErrInvalidFormat,
+		"1017927-35.2023.0.26.0008": ErrInvalidSegment,
+	}
+	for s, expected := range invalid {
+		err := Validate(s)
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected %s to fail with %v, but got: %v", s, expected, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	s, err := Normalize("10179273520238260008")
+	if err != nil {
+		t.Fatalf("Normalize error: %v", err)
+	}
+	if s != "1017927-35.2023.8.26.0008" {
+		t.Errorf("Expected 1017927-35.2023.8.26.0008, but got: %s", s)
+	}
+}
+
+func TestCheckDigits(t *testing.T) {
+	dd, err := CheckDigits("1000113", "2018", "5", "02", "0386")
+	if err != nil {
+		t.Fatalf("CheckDigits error: %v", err)
+	}
+	if dd != "34" {
+		t.Errorf("Expected check digits 34, but got: %s", dd)
+	}
+}
+
+func TestTribunal(t *testing.T) {
+	cases := map[string]string{
+		"1000113-34.2018.5.02.0386": "TRT2",
+	}
+	for s, expected := range cases {
+		n, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		if n.Tribunal() != expected {
+			t.Errorf("Expected tribunal %s for %s, but got: %s", expected, s, n.Tribunal())
+		}
+	}
+}
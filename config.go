@@ -0,0 +1,320 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default settings of Navigators, crawls and the gospider web server.
+const (
+	DefaultTimeout    = 300 * time.Millisecond // timeout of the element waits of a Navigator, see SetTimeOut
+	DefaultWorkers    = 10                     // workers of the crawls of EvaluateParallelRequests
+	DefaultRounds     = 5                      // re-crawls of EvaluateParallelRequests
+	DefaultServerAddr = ":8080"
+	DefaultPoolSize   = 2 // browsers of the web server
+)
+
+// Config holds the settings of Navigators, crawls and the web server, built in layers by Load: defaults, a config
+// file, environment variables and flags, each overriding the previous ones.
+type Config struct {
+	Navigator NavigatorSettings
+	Crawl     CrawlSettings
+	Server    ServerSettings
+
+	sources map[string]string // where each setting was last set, see PrintEffectiveConfig
+}
+
+// NavigatorSettings are the settings of the Navigators of a Config.
+type NavigatorSettings struct {
+	Headless          bool
+	ProfilePath       string
+	Timeout           time.Duration // element waits
+	NavigationTimeout time.Duration
+	ScriptTimeout     time.Duration
+	DownloadTimeout   time.Duration
+}
+
+// CrawlSettings are the settings of the crawls of a Config, as passed to ParallelRequests and Paginate.
+type CrawlSettings struct {
+	Workers  int
+	Delay    time.Duration
+	MaxPages int
+}
+
+// ServerSettings are the settings of the web server of a Config.
+type ServerSettings struct {
+	Addr      string
+	Pool      int    // browsers rendering pages at once
+	Artifacts string // directory of job outputs, none if empty
+}
+
+// configSetting is a setting of a Config, named "section.key" in files, GOSPIDER_SECTION_KEY in the environment and
+// flag on the command line.
+type configSetting struct {
+	name  string
+	flag  string
+	usage string
+	field func(c *Config) interface{} // a pointer to the field
+}
+
+// configSettings are the settings of a Config, in the order they are printed.
+var configSettings = []configSetting{
+	{"navigator.headless", "headless", "run Chrome without a window", func(c *Config) interface{} { return &c.Navigator.Headless }},
+	{"navigator.profile", "profile", "the Chrome profile directory", func(c *Config) interface{} { return &c.Navigator.ProfilePath }},
+	{"navigator.timeout", "timeout", "how long to wait for elements", func(c *Config) interface{} { return &c.Navigator.Timeout }},
+	{"navigator.navigation_timeout", "navigation-timeout", "how long to wait for pages to load", func(c *Config) interface{} { return &c.Navigator.NavigationTimeout }},
+	{"navigator.script_timeout", "script-timeout", "how long to wait for scripts to run", func(c *Config) interface{} { return &c.Navigator.ScriptTimeout }},
+	{"navigator.download_timeout", "download-timeout", "how long to wait for downloads", func(c *Config) interface{} { return &c.Navigator.DownloadTimeout }},
+	{"crawl.workers", "workers", "the number of pages crawled at once", func(c *Config) interface{} { return &c.Crawl.Workers }},
+	{"crawl.delay", "delay", "the delay before each request", func(c *Config) interface{} { return &c.Crawl.Delay }},
+	{"crawl.max_pages", "max-pages", "the maximum number of pages paginated", func(c *Config) interface{} { return &c.Crawl.MaxPages }},
+	{"server.addr", "addr", "the address to listen on", func(c *Config) interface{} { return &c.Server.Addr }},
+	{"server.pool", "pool", "the number of browsers rendering pages at once", func(c *Config) interface{} { return &c.Server.Pool }},
+	{"server.artifacts", "artifacts", "a directory of job outputs to serve", func(c *Config) interface{} { return &c.Server.Artifacts }},
+}
+
+// envName returns the environment variable of the setting.
+func (s configSetting) envName() string {
+	return "GOSPIDER_" + strings.ToUpper(strings.ReplaceAll(s.name, ".", "_"))
+}
+
+// set parses the value of the setting in c.
+func (s configSetting) set(c *Config, value string) error {
+	var err error
+	switch field := s.field(c).(type) {
+	case *bool:
+		*field, err = strconv.ParseBool(value)
+	case *int:
+		*field, err = strconv.Atoi(value)
+	case *time.Duration:
+		*field, err = time.ParseDuration(value)
+	case *string:
+		*field = value
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", s.name, value)
+	}
+	return nil
+}
+
+// get returns the value of the setting in c.
+func (s configSetting) get(c *Config) interface{} {
+	switch field := s.field(c).(type) {
+	case *bool:
+		return *field
+	case *int:
+		return *field
+	case *time.Duration:
+		return *field
+	case *string:
+		return *field
+	}
+	return nil
+}
+
+// DefaultConfig returns the default settings.
+func DefaultConfig() *Config {
+	return &Config{
+		Navigator: NavigatorSettings{
+			Headless:          true,
+			Timeout:           DefaultTimeout,
+			NavigationTimeout: DefaultNavigationTimeout,
+			ScriptTimeout:     DefaultScriptTimeout,
+			DownloadTimeout:   DefaultDownloadTimeout,
+		},
+		Crawl:   CrawlSettings{Workers: DefaultWorkers, MaxPages: DefaultMaxPages},
+		Server:  ServerSettings{Addr: DefaultServerAddr, Pool: DefaultPoolSize},
+		sources: map[string]string{},
+	}
+}
+
+// ConfigFlags are the flags of the settings of a Config, see AddFlags.
+type ConfigFlags struct {
+	fs     *flag.FlagSet
+	values Config
+}
+
+// AddFlags adds a flag for each setting to fs, with the current settings of c as defaults: -headless, -profile,
+// -timeout, -navigation-timeout, -script-timeout, -download-timeout, -workers, -delay, -max-pages, -addr, -pool and
+// -artifacts. Load applies the flags set on the command line.
+// Example:
+//
+//	config := goSpider.DefaultConfig()
+//	flags := config.AddFlags(flag.CommandLine)
+//	configFile := flag.String("config", "", "a JSON config file")
+//	flag.Parse()
+//	err := config.Load(*configFile, os.Getenv, flags)
+func (c *Config) AddFlags(fs *flag.FlagSet) *ConfigFlags {
+	flags := &ConfigFlags{fs: fs, values: *c}
+	for _, s := range configSettings {
+		switch field := s.field(&flags.values).(type) {
+		case *bool:
+			fs.BoolVar(field, s.flag, *field, s.usage)
+		case *int:
+			fs.IntVar(field, s.flag, *field, s.usage)
+		case *time.Duration:
+			fs.DurationVar(field, s.flag, *field, s.usage)
+		case *string:
+			fs.StringVar(field, s.flag, *field, s.usage)
+		}
+	}
+	return flags
+}
+
+// Load applies the layers of settings over c: the JSON file at path, unless empty, the GOSPIDER_* environment
+// variables read by getenv, unless nil, and the flags set in flags, unless nil, and validates the result.
+//
+// The file has a section per struct of Config, e.g. {"navigator": {"timeout": "10s"}, "server": {"pool": 4}}, and
+// the environment variables are named after them, e.g. GOSPIDER_NAVIGATOR_TIMEOUT=10s.
+func (c *Config) Load(path string, getenv func(string) string, flags *ConfigFlags) error {
+	if c.sources == nil {
+		c.sources = map[string]string{}
+	}
+	if path != "" {
+		err := c.loadFile(path)
+		if err != nil {
+			return err
+		}
+	}
+	if getenv != nil {
+		for _, s := range configSettings {
+			value := getenv(s.envName())
+			if value == "" {
+				continue
+			}
+			err := s.set(c, value)
+			if err != nil {
+				return fmt.Errorf("error - invalid configuration: %s: %w", s.envName(), err)
+			}
+			c.sources[s.name] = "env " + s.envName()
+		}
+	}
+	if flags != nil {
+		set := map[string]bool{}
+		flags.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		for _, s := range configSettings {
+			if !set[s.flag] {
+				continue
+			}
+			// the value was parsed by the flag, so it is valid
+			s.set(c, fmt.Sprint(s.get(&flags.values)))
+			c.sources[s.name] = "flag -" + s.flag
+		}
+	}
+	return c.Validate()
+}
+
+// loadFile applies the settings of a JSON config file.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error - failed to read config file: %w", err)
+	}
+	var sections map[string]map[string]interface{}
+	err = json.Unmarshal(data, &sections)
+	if err != nil {
+		return fmt.Errorf("error - invalid config file %s: %w", path, err)
+	}
+	settings := make(map[string]configSetting, len(configSettings))
+	for _, s := range configSettings {
+		settings[s.name] = s
+	}
+	for section, values := range sections {
+		for key, value := range values {
+			s, ok := settings[section+"."+key]
+			if !ok {
+				return fmt.Errorf("error - invalid config file %s: unknown setting %s.%s", path, section, key)
+			}
+			err = s.set(c, fmt.Sprint(value))
+			if err != nil {
+				return fmt.Errorf("error - invalid config file %s: %w", path, err)
+			}
+			c.sources[s.name] = "file " + path
+		}
+	}
+	return nil
+}
+
+// Validate checks that the settings are usable.
+func (c *Config) Validate() error {
+	var problems []string
+	if c.Navigator.Timeout <= 0 {
+		problems = append(problems, "navigator.timeout must be positive")
+	}
+	if c.Navigator.NavigationTimeout <= 0 || c.Navigator.ScriptTimeout <= 0 || c.Navigator.DownloadTimeout <= 0 {
+		problems = append(problems, "navigator timeouts must be positive")
+	}
+	if c.Crawl.Workers < 1 {
+		problems = append(problems, "crawl.workers must be at least 1")
+	}
+	if c.Crawl.Delay < 0 {
+		problems = append(problems, "crawl.delay must not be negative")
+	}
+	if c.Crawl.MaxPages < 1 {
+		problems = append(problems, "crawl.max_pages must be at least 1")
+	}
+	if c.Server.Addr == "" {
+		problems = append(problems, "server.addr must be set")
+	}
+	if c.Server.Pool < 1 {
+		problems = append(problems, "server.pool must be at least 1")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("error - invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// PrintEffectiveConfig writes each setting of c with where it was set: default, file, env or flag.
+// Example:
+//
+//	goSpider.PrintEffectiveConfig(os.Stderr, config)
+//	// navigator.headless = true (default)
+//	// navigator.timeout = 10s (env GOSPIDER_NAVIGATOR_TIMEOUT)
+//	// server.pool = 4 (flag -pool)
+func PrintEffectiveConfig(w io.Writer, c *Config) error {
+	for _, s := range configSettings {
+		source := c.sources[s.name]
+		if source == "" {
+			source = "default"
+		}
+		_, err := fmt.Fprintf(w, "%s = %v (%s)\n", s.name, s.get(c), source)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewNavigator creates a Navigator with the navigator settings of c.
+func (c *Config) NewNavigator(options ...NavigatorOption) *Navigator {
+	nav := NewNavigator(c.Navigator.ProfilePath, c.Navigator.Headless, options...)
+	nav.SetTimeouts(c.Navigator.Timeouts())
+	return nav
+}
+
+// EvaluateOptions returns the options of EvaluateParallelRequestsWithOptions with the crawl settings of c.
+func (c *Config) EvaluateOptions() EvaluateOptions {
+	return EvaluateOptions{Workers: c.Crawl.Workers, Delay: c.Crawl.Delay}
+}
+
+// PoolOptions returns the options of a NavigatorPool of the server with the navigator settings of c.
+func (c *Config) PoolOptions() PoolOptions {
+	return PoolOptions{
+		Size:        c.Server.Pool,
+		ProfilePath: c.Navigator.ProfilePath,
+		Headless:    c.Navigator.Headless,
+		Timeouts:    c.Navigator.Timeouts(),
+	}
+}
+
+// Timeouts returns the timeouts of the settings.
+func (s NavigatorSettings) Timeouts() Timeouts {
+	return Timeouts{Navigation: s.NavigationTimeout, Element: s.Timeout, Script: s.ScriptTimeout, Download: s.DownloadTimeout}
+}
@@ -0,0 +1,88 @@
+package goSpider
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"navigator": {"timeout": "5s", "headless": false}, "server": {"pool": 4, "addr": ":9000"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"GOSPIDER_SERVER_POOL": "6", "GOSPIDER_NAVIGATOR_TIMEOUT": "7s",
+		"GOSPIDER_NAVIGATOR_NAVIGATION_TIMEOUT": "2m"}
+
+	config := DefaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := config.AddFlags(fs)
+	if err := fs.Parse([]string{"-timeout", "9s", "-workers", "3"}); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	err = config.Load(path, func(name string) string { return env[name] }, flags)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	// flags over the environment over the file over the defaults
+	if config.Navigator.Timeout != 9*time.Second || config.Navigator.Headless || config.Server.Pool != 6 ||
+		config.Server.Addr != ":9000" || config.Crawl.Workers != 3 || config.Crawl.MaxPages != DefaultMaxPages {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	timeouts := config.Navigator.Timeouts()
+	if timeouts.Navigation != 2*time.Minute || timeouts.Element != 9*time.Second || timeouts.Script != DefaultScriptTimeout {
+		t.Errorf("Unexpected timeouts %+v", timeouts)
+	}
+
+	var output bytes.Buffer
+	if err := PrintEffectiveConfig(&output, config); err != nil {
+		t.Fatalf("PrintEffectiveConfig error: %v", err)
+	}
+	for _, line := range []string{
+		"navigator.headless = false (file " + path + ")",
+		"navigator.timeout = 9s (flag -timeout)",
+		"navigator.navigation_timeout = 2m0s (env GOSPIDER_NAVIGATOR_NAVIGATION_TIMEOUT)",
+		"crawl.max_pages = 100 (default)",
+		"server.pool = 6 (env GOSPIDER_SERVER_POOL)",
+	} {
+		if !strings.Contains(output.String(), line+"\n") {
+			t.Errorf("Expected %q in the effective config, got:\n%s", line, output.String())
+		}
+	}
+}
+
+func TestConfigLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"unknown.json": `{"navigator": {"speed": "fast"}}`,
+		"invalid.json": `{"navigator": {"timeout": 10}}`,
+		"broken.json":  `{"navigator": `,
+		"zero.json":    `{"server": {"pool": 0}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := DefaultConfig().Load(path, nil, nil); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+
+	err := DefaultConfig().Load("", func(name string) string { return "maybe" }, nil)
+	if err == nil || !strings.Contains(err.Error(), "GOSPIDER_NAVIGATOR_HEADLESS") {
+		t.Errorf("Expected an error naming the environment variable, got %v", err)
+	}
+	if err := DefaultConfig().Load(filepath.Join(dir, "missing.json"), nil, nil); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GetCookies returns the cookies of the browser, of every domain, and keeps them in nav.Cookies, e.g. to capture a
+// session after Login.
+// Example:
+//
+//	err := nav.Login(loginURL, username, password, "#usernameField", "#passwordField", "#loginButton", "")
+//	cookies, err := nav.GetCookies()
+func (nav *Navigator) GetCookies() ([]*network.Cookie, error) {
+	var cookies []*network.Cookie
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cookies, err = storage.GetCookies().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get cookies: %v\n", err)
+		return nil, fmt.Errorf("error - failed to get cookies: %w", classifyError(err))
+	}
+	nav.Cookies = cookies
+	return cookies, nil
+}
+
+// SetCookies adds cookies to the browser, replacing the ones with the same name, domain and path, e.g. the session
+// captured by GetCookies in another Navigator.
+// Example:
+//
+//	err := fresh.SetCookies(cookies)
+//	err = fresh.OpenURL("https://esaj.tjsp.jus.br/cpopg/open.do")
+func (nav *Navigator) SetCookies(cookies []*network.Cookie) error {
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, cookie := range cookies {
+		params = append(params, cookieParam(cookie))
+	}
+	err := chromedp.Run(nav.Ctx, network.SetCookies(params))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to set cookies: %v\n", err)
+		return fmt.Errorf("error - failed to set cookies: %w", classifyError(err))
+	}
+	_, err = nav.GetCookies()
+	if err != nil {
+		return err
+	}
+	nav.Logger.Printf("%d cookies set\n", len(cookies))
+	return nil
+}
+
+// DeleteCookie deletes the cookies named name of domain, or of every domain when domain is empty.
+// Example:
+//
+//	err := nav.DeleteCookie("JSESSIONID", "esaj.tjsp.jus.br")
+func (nav *Navigator) DeleteCookie(name, domain string) error {
+	cookies, err := nav.GetCookies()
+	if err != nil {
+		return err
+	}
+	domain = strings.TrimPrefix(domain, ".")
+	for _, cookie := range cookies {
+		if cookie.Name != name || (domain != "" && strings.TrimPrefix(cookie.Domain, ".") != domain) {
+			continue
+		}
+		err = chromedp.Run(nav.Ctx, network.DeleteCookies(name).WithDomain(cookie.Domain).WithPath(cookie.Path))
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to delete cookie: %v\n", err)
+			return fmt.Errorf("error - failed to delete cookie: %w", classifyError(err))
+		}
+	}
+	_, err = nav.GetCookies()
+	if err != nil {
+		return err
+	}
+	nav.Logger.Printf("Cookie %s deleted\n", name)
+	return nil
+}
+
+// ExportCookiesJSON saves the cookies of the browser as JSON to path, which ImportCookiesJSON loads into a fresh
+// Navigator, so it starts authenticated without logging in again.
+// Example:
+//
+//	err := nav.ExportCookiesJSON("session/tjsp.json")
+func (nav *Navigator) ExportCookiesJSON(path string) error {
+	cookies, err := nav.GetCookies()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error - failed to encode cookies: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err == nil {
+		// the cookies are credentials, readable by the owner only
+		err = os.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to save cookies: %v\n", err)
+		return fmt.Errorf("error - failed to save cookies: %w", err)
+	}
+	nav.Logger.Printf("%d cookies saved to %s\n", len(cookies), path)
+	return nil
+}
+
+// ImportCookiesJSON adds the cookies saved by ExportCookiesJSON to path to the browser, skipping the expired ones.
+// Example:
+//
+//	nav := goSpider.NewNavigator("", true)
+//	err := nav.ImportCookiesJSON("session/tjsp.json")
+func (nav *Navigator) ImportCookiesJSON(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to read cookies: %v\n", err)
+		return fmt.Errorf("error - failed to read cookies: %w", err)
+	}
+	var cookies []*network.Cookie
+	err = json.Unmarshal(data, &cookies)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to decode cookies: %v\n", err)
+		return fmt.Errorf("error - failed to decode cookies: %w", err)
+	}
+	return nav.SetCookies(liveCookies(cookies, time.Now()))
+}
+
+// cookieParam returns the parameters setting cookie.
+func cookieParam(cookie *network.Cookie) *network.CookieParam {
+	param := &network.CookieParam{
+		Name:         cookie.Name,
+		Value:        cookie.Value,
+		Domain:       cookie.Domain,
+		Path:         cookie.Path,
+		Secure:       cookie.Secure,
+		HTTPOnly:     cookie.HTTPOnly,
+		SameSite:     cookie.SameSite,
+		Priority:     cookie.Priority,
+		SourceScheme: cookie.SourceScheme,
+		SourcePort:   cookie.SourcePort,
+		PartitionKey: cookie.PartitionKey,
+	}
+	if !cookie.Session && cookie.Expires > 0 {
+		expires := cdp.TimeSinceEpoch(time.Unix(0, int64(cookie.Expires*float64(time.Second))))
+		param.Expires = &expires
+	}
+	return param
+}
+
+// liveCookies returns the cookies not expired at now.
+func liveCookies(cookies []*network.Cookie, now time.Time) []*network.Cookie {
+	live := make([]*network.Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookie.Session || cookie.Expires <= 0 || cookie.Expires > float64(now.Unix()) {
+			live = append(live, cookie)
+		}
+	}
+	return live
+}
@@ -0,0 +1,85 @@
+package goSpider
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestCookies(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	expires := float64(time.Now().Add(time.Hour).Unix())
+	err = nav.SetCookies([]*network.Cookie{
+		{Name: "session", Value: "abc123", Domain: "127.0.0.1", Path: "/", Expires: expires},
+		{Name: "theme", Value: "dark", Domain: "127.0.0.1", Path: "/", Session: true},
+	})
+	if err != nil {
+		t.Fatalf("SetCookies error: %v", err)
+	}
+	cookie, err := nav.EvaluateScript(`document.cookie`)
+	if err != nil || cookie != "session=abc123; theme=dark" {
+		t.Errorf("Expected the cookies in the page, got %v: %v", cookie, err)
+	}
+	if len(nav.Cookies) != 2 {
+		t.Errorf("Expected the cookies in nav.Cookies, got %d", len(nav.Cookies))
+	}
+
+	path := filepath.Join(t.TempDir(), "session", "cookies.json")
+	err = nav.ExportCookiesJSON(path)
+	if err != nil {
+		t.Fatalf("ExportCookiesJSON error: %v", err)
+	}
+	err = nav.DeleteCookie("theme", "")
+	if err != nil {
+		t.Fatalf("DeleteCookie error: %v", err)
+	}
+	cookies, err := nav.GetCookies()
+	if err != nil || len(cookies) != 1 || cookies[0].Name != "session" {
+		t.Errorf("Expected the session cookie to be left, got %v: %v", cookies, err)
+	}
+
+	fresh := setupNavigator(t)
+	err = fresh.ImportCookiesJSON(path)
+	if err != nil {
+		t.Fatalf("ImportCookiesJSON error: %v", err)
+	}
+	err = fresh.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	cookie, err = fresh.EvaluateScript(`document.cookie`)
+	if err != nil || cookie != "session=abc123; theme=dark" {
+		t.Errorf("Expected the imported cookies in the page, got %v: %v", cookie, err)
+	}
+}
+
+func TestCookieParam(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	param := cookieParam(&network.Cookie{Name: "a", Value: "1", Domain: ".example.com", Path: "/", HTTPOnly: true,
+		Expires: float64(expires.Unix())})
+	if param.Expires == nil || !param.Expires.Time().Equal(expires) || !param.HTTPOnly || param.Domain != ".example.com" {
+		t.Errorf("Unexpected cookie param %+v", param)
+	}
+	if session := cookieParam(&network.Cookie{Name: "b", Expires: -1, Session: true}); session.Expires != nil {
+		t.Errorf("Expected a session cookie without expiry, got %v", session.Expires)
+	}
+
+	now := time.Now()
+	live := liveCookies([]*network.Cookie{
+		{Name: "expired", Expires: float64(now.Add(-time.Hour).Unix())},
+		{Name: "live", Expires: float64(now.Add(time.Hour).Unix())},
+		{Name: "session", Expires: -1, Session: true},
+	}, now)
+	if len(live) != 2 || live[0].Name != "live" || live[1].Name != "session" {
+		t.Errorf("Expected the expired cookie to be skipped, got %v", live)
+	}
+}
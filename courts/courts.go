@@ -0,0 +1,55 @@
+/*
+Package courts holds the lawsuit schema shared by the court-system crawlers (esaj, PJe, eproc, ...),
+so results from different tribunals can be stored and compared without per-system conversions.
+*/
+package courts
+
+// Lawsuit is the normalized result of a court-system crawl.
+type Lawsuit struct {
+	Number    string // CNJ number in the NNNNNNN-DD.AAAA.J.TR.OOOO format
+	Tribunal  string // tribunal acronym, e.g. "TJSP" or "TRF1"
+	System    string // court system the data was extracted from, e.g. "esaj" or "pje"
+	Cover     Cover
+	Appeals   []Cover // covers of the second instance appeals merged into this result, if any
+	Persons   []Person
+	Movements []Movement
+	Documents []Document
+}
+
+// Cover holds the header information of a lawsuit.
+type Cover struct {
+	Title       string
+	Tag         string
+	Class       string
+	Subject     string
+	Location    string
+	Unit        string
+	Judge       string
+	InitialDate string
+	Control     string
+	Field       string
+	Value       string
+	Error       string
+}
+
+// Person is a party of the lawsuit with its lawyers.
+type Person struct {
+	Pole    string
+	Name    string
+	Lawyers []string
+}
+
+// Movement is an entry of the lawsuit's docket.
+type Movement struct {
+	Date     string
+	Title    string
+	Text     string
+	Instance int // 1 for first instance, 2 for appeals, 0 when the system does not distinguish
+}
+
+// Document is a file attached to the lawsuit, usually opened through a viewer link.
+type Document struct {
+	Date  string
+	Title string
+	URL   string
+}
@@ -0,0 +1,372 @@
+/*
+Package eproc crawls lawsuits on eproc, the court system used by the federal courts of the 2nd and 4th regions
+(TRF2, TRF4 and their sections) and by some state courts, normalizing them into the courts.Lawsuit schema.
+*/
+package eproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/cnj"
+	"github.com/DanielFillol/goSpider/courts"
+	"golang.org/x/net/html"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Tribunals maps tribunal acronyms to the base URL of their eproc instance.
+var Tribunals = map[string]string{
+	"TRF2": "https://eproc.trf2.jus.br/eproc/",
+	"TRF4": "https://eproc.trf4.jus.br/eproc2trf4/",
+	"JFRS": "https://eproc.jfrs.jus.br/eprocV2/",
+	"JFSC": "https://eproc.jfsc.jus.br/eprocV2/",
+	"JFPR": "https://eproc.jfpr.jus.br/eprocV2/",
+	"TJRS": "https://eproc1g.tjrs.jus.br/eproc/",
+	"TJSC": "https://eproc1g.tjsc.jus.br/eproc/",
+	"TJTO": "https://eproc1.tjto.jus.br/eprocV2_prod_1grau/",
+}
+
+// registryCodes are the courts registry codes of the Tribunals that cnj.Number.Tribunal doesn't return: the federal
+// sections of the 4th region get the first instance numbers of their origins (70 for Paraná, 71 for Rio Grande do Sul
+// and 72 for Santa Catarina), leaving the other numbers of the region to TRF4.
+var registryCodes = map[string]string{
+	"JFPR": "4.04.70",
+	"JFRS": "4.04.71",
+	"JFSC": "4.04.72",
+}
+
+// init registers a Crawler for every known tribunal on the courts registry.
+func init() {
+	for tribunal := range Tribunals {
+		c, _ := New(tribunal)
+		code, ok := registryCodes[tribunal]
+		if !ok {
+			code = tribunal
+		}
+		courts.Register(code, c)
+	}
+}
+
+// Selectors holds the selectors used on the eproc pages.
+type Selectors struct {
+	UsernameField     string
+	PasswordField     string
+	LoginButton       string
+	LoginError        string
+	CertificateButton string
+	NumberField       string
+	SearchButton      string
+	DetailReady       string
+}
+
+// DefaultSelectors are the selectors of the standard eproc pages.
+var DefaultSelectors = Selectors{
+	UsernameField:     "#txtUsuario",
+	PasswordField:     "#pwdSenha",
+	LoginButton:       "#sbmEntrar",
+	LoginError:        "#divInfraExcecao",
+	CertificateButton: "#btnCertificado",
+	NumberField:       "#txtNumProcesso",
+	SearchButton:      "#sbmPesquisar",
+	DetailReady:       "#tblEventos",
+}
+
+// Crawler searches lawsuits on an eproc instance.
+type Crawler struct {
+	Tribunal  string
+	URL       string
+	Selectors Selectors
+	// WaitTimeout bounds the wait for the pages after login and search, which are much slower than nav.Timeout.
+	WaitTimeout time.Duration
+}
+
+// New creates a Crawler for one of the known Tribunals.
+// Example:
+//
+//	crawler, err := eproc.New("TRF4")
+func New(tribunal string) (*Crawler, error) {
+	u, ok := Tribunals[tribunal]
+	if !ok {
+		return nil, fmt.Errorf("error - unknown eproc tribunal: %s", tribunal)
+	}
+	return &Crawler{
+		Tribunal:    tribunal,
+		URL:         u,
+		Selectors:   DefaultSelectors,
+		WaitTimeout: 30 * time.Second,
+	}, nil
+}
+
+// LoginWithCredentials logs into eproc with a username and password.
+// Example:
+//
+//	err := crawler.LoginWithCredentials(nav, "username", "password")
+func (c *Crawler) LoginWithCredentials(nav goSpider.Browser, username, password string) error {
+	return nav.Login(c.URL, username, password, c.Selectors.UsernameField, c.Selectors.PasswordField, c.Selectors.LoginButton, c.Selectors.LoginError)
+}
+
+// LoginWithCertificate logs into eproc with the digital certificate installed on the browser profile.
+// Chrome only presents the certificate without a prompt when the profile sets the AutoSelectCertificateForUrls
+// policy for the eproc host, so the Navigator must be created with that profile.
+// Example:
+//
+//	nav := goSpider.NewNavigator("/path/to/profile/with/certificate", true)
+//	err := crawler.LoginWithCertificate(nav)
+func (c *Crawler) LoginWithCertificate(nav goSpider.Browser) error {
+	err := nav.OpenURL(c.URL)
+	if err != nil {
+		return err
+	}
+
+	err = nav.ClickButton(c.Selectors.CertificateButton)
+	if err != nil {
+		return err
+	}
+
+	err = nav.WaitForElement(c.Selectors.LoginError, nav.GetTimeOut())
+	if err == nil {
+		message, _ := nav.GetElement(c.Selectors.LoginError)
+		return fmt.Errorf("error - message: %v", message)
+	}
+
+	return nil
+}
+
+// Search looks up a lawsuit by its CNJ number on a logged in session and returns the page source of its details page.
+// Example:
+//
+//	pageSource, err := crawler.Search(nav, "5001234-17.2023.4.04.7100")
+func (c *Crawler) Search(nav goSpider.Browser, number string) (*html.Node, error) {
+	n, err := cnj.Parse(number)
+	if err != nil {
+		return nil, err
+	}
+
+	searchURL, err := c.searchURL()
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.OpenURL(searchURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// eproc accepts the number without separators only
+	err = nav.FillField(c.Selectors.NumberField, n.Digits())
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.ClickButton(c.Selectors.SearchButton)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.WaitForElement(c.Selectors.DetailReady, c.WaitTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error - lawsuit %s not found: %w", n, err)
+	}
+
+	return nav.GetPageSource()
+}
+
+// Lawsuit searches a lawsuit and extracts it into the shared schema.
+// Example:
+//
+//	lawsuit, err := crawler.Lawsuit(nav, "5001234-17.2023.4.04.7100")
+func (c *Crawler) Lawsuit(nav goSpider.Browser, number string) (courts.Lawsuit, error) {
+	pageSource, err := c.Search(nav, number)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	lawsuit, err := ExtractLawsuit(pageSource)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+	lawsuit.Tribunal = c.Tribunal
+	lawsuit.Documents = resolveDocuments(c.URL, lawsuit.Documents)
+	if lawsuit.Number == "" {
+		lawsuit.Number, _ = cnj.Normalize(number)
+	}
+
+	return lawsuit, nil
+}
+
+func (c *Crawler) searchURL() (string, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "", err
+	}
+	ref, _ := url.Parse("controlador.php?acao=processo_consultar")
+	return u.ResolveReference(ref).String(), nil
+}
+
+// ExtractLawsuit extracts cover, parties, events and their attachments from an eproc details page.
+// Example:
+//
+//	lawsuit, err := eproc.ExtractLawsuit(pageSource)
+func ExtractLawsuit(pageSource *html.Node) (courts.Lawsuit, error) {
+	cover, err := ExtractCover(pageSource)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	persons, err := ExtractPersons(pageSource)
+	if err != nil {
+		cover.Error = err.Error()
+	}
+
+	movements, documents, err := ExtractEvents(pageSource)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	number, _ := cnj.Normalize(cover.Title)
+	return courts.Lawsuit{
+		Number:    number,
+		System:    "eproc",
+		Cover:     cover,
+		Persons:   persons,
+		Movements: movements,
+		Documents: documents,
+	}, nil
+}
+
+// ExtractCover extracts the header fields of an eproc details page.
+func ExtractCover(pageSource *html.Node) (courts.Cover, error) {
+	var cover courts.Cover
+	fields := map[string]*string{
+		"//*[@id=\"txtNumProcesso\"]":           &cover.Title,
+		"//*[@id=\"txtSituacao\"]":              &cover.Tag,
+		"//*[@id=\"txtClasse\"]":                &cover.Class,
+		"//*[@id=\"tblAssuntos\"]//tr[2]/td[2]": &cover.Subject,
+		"//*[@id=\"txtOrgaoJulgador\"]":         &cover.Unit,
+		"//*[@id=\"txtMagistrado\"]":            &cover.Judge,
+		"//*[@id=\"txtAutuacao\"]":              &cover.InitialDate,
+		"//*[@id=\"txtValorCausa\"]":            &cover.Value,
+	}
+
+	var missing int
+	for xpath, value := range fields {
+		text, err := goSpider.ExtractText(pageSource, xpath, "\n")
+		if err != nil {
+			missing++
+			continue
+		}
+		*value = strings.Join(strings.Fields(text), " ")
+	}
+
+	if cover.Title == "" || missing >= 4 {
+		return courts.Cover{}, fmt.Errorf("error - could not extract cover, %d fields missing", missing)
+	}
+	return cover, nil
+}
+
+// ExtractPersons extracts the parties of the "Partes e Representantes" table, where each column is a pole.
+func ExtractPersons(pageSource *html.Node) ([]courts.Person, error) {
+	headers, err := goSpider.FindNodes(pageSource, "//*[@id=\"tblPartesERepresentantes\"]//tr[1]/th")
+	if err != nil {
+		return nil, err
+	}
+
+	var persons []courts.Person
+	for i, header := range headers {
+		pole, _ := goSpider.ExtractText(header, ".", "\n")
+		cells, err := goSpider.FindNodes(pageSource, fmt.Sprintf("//*[@id=\"tblPartesERepresentantes\"]//tr[position()>1]/td[%d]", i+1))
+		if err != nil {
+			continue
+		}
+		for _, cell := range cells {
+			name, err := goSpider.ExtractText(cell, "span[contains(@class,'infraNomeParte')]", "\n")
+			if err != nil {
+				continue
+			}
+
+			var lawyers []string
+			ll, err := goSpider.FindNodes(cell, "a[contains(@href,'oab') or contains(@class,'infraAdvogado')]")
+			if err == nil {
+				for _, l := range ll {
+					lawyer, err := goSpider.ExtractText(l, ".", "\n")
+					if err == nil {
+						lawyers = append(lawyers, strings.TrimSpace(lawyer))
+					}
+				}
+			}
+
+			persons = append(persons, courts.Person{
+				Pole:    strings.TrimSpace(pole),
+				Name:    strings.Join(strings.Fields(name), " "),
+				Lawyers: lawyers,
+			})
+		}
+	}
+
+	if len(persons) == 0 {
+		return nil, errors.New("could not find any person")
+	}
+	return persons, nil
+}
+
+// ExtractEvents extracts the events table, returning the movements and the documents attached to each event.
+func ExtractEvents(pageSource *html.Node) ([]courts.Movement, []courts.Document, error) {
+	rows, err := goSpider.ExtractTable(pageSource, "//*[@id=\"tblEventos\"]//tr[td]")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var movements []courts.Movement
+	var documents []courts.Document
+	for _, row := range rows {
+		event, _ := goSpider.ExtractText(row, "td[1]", "\n")
+		date, err := goSpider.ExtractText(row, "td[2]", "\n")
+		if err != nil {
+			continue
+		}
+		description, _ := goSpider.ExtractText(row, "td[3]", "\n")
+
+		movements = append(movements, courts.Movement{
+			Date:  strings.TrimSpace(date),
+			Title: strings.Join(strings.Fields(description), " "),
+			Text:  "Evento " + strings.TrimSpace(event),
+		})
+
+		links, err := goSpider.FindNodes(row, "td[5]//a[contains(@class,'infraLinkDocumento')]")
+		if err != nil {
+			continue
+		}
+		for _, link := range links {
+			title, _ := goSpider.ExtractText(link, ".", "\n")
+			href, _ := goSpider.ExtractText(link, "@href", "")
+			documents = append(documents, courts.Document{
+				Date:  strings.TrimSpace(date),
+				Title: strings.TrimSpace(title),
+				URL:   href,
+			})
+		}
+	}
+
+	if len(movements) == 0 {
+		return nil, nil, errors.New("error table: could not find any movements")
+	}
+	return movements, documents, nil
+}
+
+// resolveDocuments turns the relative attachment links into absolute URLs.
+func resolveDocuments(base string, documents []courts.Document) []courts.Document {
+	b, err := url.Parse(base)
+	if err != nil {
+		return documents
+	}
+	for i, d := range documents {
+		ref, err := url.Parse(d.URL)
+		if err != nil {
+			continue
+		}
+		documents[i].URL = b.ResolveReference(ref).String()
+	}
+	return documents
+}
@@ -0,0 +1,78 @@
+package eproc
+
+import (
+	"github.com/DanielFillol/goSpider/courts"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"strings"
+	"testing"
+)
+
+const detailPage = `<html><body>
+<span id="txtNumProcesso">5001234-17.2023.4.04.7100</span>
+<span id="txtAutuacao">10/03/2023 14:02:11</span>
+<span id="txtSituacao">MOVIMENTO</span>
+<span id="txtOrgaoJulgador">1ª Vara Federal de Porto Alegre</span>
+<span id="txtMagistrado">JUIZ FEDERAL</span>
+<span id="txtClasse">PROCEDIMENTO COMUM</span>
+<table id="tblPartesERepresentantes">
+<tr><th>AUTOR</th><th>RÉU</th></tr>
+<tr><td><span class="infraNomeParte">FULANO DE TAL</span><br><a class="infraAdvogado" href="#">CICRANO (RS012345)</a></td><td><span class="infraNomeParte">UNIÃO</span></td></tr>
+</table>
+<table id="tblEventos">
+<tr><th>Evento</th><th>Data/Hora</th><th>Descrição</th><th>Usuário</th><th>Documentos</th></tr>
+<tr><td>2</td><td>11/03/2023 09:00:00</td><td>Despacho</td><td>JUIZ</td><td><a class="infraLinkDocumento" href="controlador.php?acao=acessar_documento&amp;doc=2">DESP1</a></td></tr>
+<tr><td>1</td><td>10/03/2023 14:02:11</td><td>Distribuição por sorteio</td><td>SISTEMA</td><td></td></tr>
+</table>
+</body></html>`
+
+func TestExtractLawsuit(t *testing.T) {
+	pageSource, err := htmlquery.Parse(strings.NewReader(detailPage))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	lawsuit, err := ExtractLawsuit(pageSource)
+	if err != nil {
+		t.Fatalf("ExtractLawsuit error: %v", err)
+	}
+
+	if lawsuit.Cover.Unit != "1ª Vara Federal de Porto Alegre" {
+		t.Errorf("Expected unit 1ª Vara Federal de Porto Alegre, but got: %s", lawsuit.Cover.Unit)
+	}
+	if len(lawsuit.Persons) != 2 || lawsuit.Persons[0].Pole != "AUTOR" || lawsuit.Persons[1].Name != "UNIÃO" {
+		t.Errorf("Unexpected persons: %+v", lawsuit.Persons)
+	}
+	if len(lawsuit.Persons) > 0 && (len(lawsuit.Persons[0].Lawyers) != 1 || lawsuit.Persons[0].Lawyers[0] != "CICRANO (RS012345)") {
+		t.Errorf("Unexpected lawyers: %v", lawsuit.Persons[0].Lawyers)
+	}
+	if len(lawsuit.Movements) != 2 || lawsuit.Movements[0].Title != "Despacho" || lawsuit.Movements[0].Text != "Evento 2" {
+		t.Errorf("Unexpected movements: %+v", lawsuit.Movements)
+	}
+	if len(lawsuit.Documents) != 1 || lawsuit.Documents[0].Title != "DESP1" {
+		t.Errorf("Unexpected documents: %+v", lawsuit.Documents)
+	}
+
+	documents := resolveDocuments(Tribunals["TRF4"], lawsuit.Documents)
+	expected := "https://eproc.trf4.jus.br/eproc2trf4/controlador.php?acao=acessar_documento&doc=2"
+	if len(documents) != 1 || documents[0].URL != expected {
+		t.Errorf("Expected document URL %s, but got: %+v", expected, documents)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	tests := map[string]string{
+		"5001234-17.2023.4.04.7100": "JFRS",
+		"5001234-26.2023.4.04.7000": "JFPR",
+		"5001234-08.2023.4.04.7200": "JFSC",
+		"5001234-74.2023.4.04.0000": "TRF4",
+	}
+	for number, want := range tests {
+		extractor, err := courts.ExtractorFor(number)
+		if err != nil {
+			t.Fatalf("ExtractorFor(%s) error: %v", number, err)
+		}
+		if c, ok := extractor.(*Crawler); !ok || c.Tribunal != want {
+			t.Errorf("Expected %s to be dispatched to %s, got %+v", number, want, extractor)
+		}
+	}
+}
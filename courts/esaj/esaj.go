@@ -0,0 +1,450 @@
+/*
+Package esaj crawls lawsuits on e-SAJ (TJSP and other state courts), following the "Visualizar em 2º grau"
+links to the cposg appeals and merging both instances into a single courts.Lawsuit.
+*/
+package esaj
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/cnj"
+	"github.com/DanielFillol/goSpider/courts"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tribunals maps tribunal acronyms to the base URL of their e-SAJ instance.
+var Tribunals = map[string]string{
+	"TJSP": "https://esaj.tjsp.jus.br/",
+	"TJMS": "https://esaj.tjms.jus.br/",
+	"TJAL": "https://www2.tjal.jus.br/",
+	"TJCE": "https://esaj.tjce.jus.br/",
+	"TJAM": "https://consultasaj.tjam.jus.br/",
+	"TJAC": "https://esaj.tjac.jus.br/",
+}
+
+// init registers a Crawler for every known tribunal on the courts registry.
+func init() {
+	for tribunal := range Tribunals {
+		c, _ := New(tribunal)
+		courts.Register(tribunal, c)
+	}
+}
+
+// Selectors holds the selectors of the first instance (cpopg) search form.
+type Selectors struct {
+	NumberTypeRadio string
+	NumberField     string
+	SearchButton    string
+}
+
+// DefaultSelectors are the selectors of the standard e-SAJ search form.
+var DefaultSelectors = Selectors{
+	NumberTypeRadio: "#interna_NUMPROC > div > fieldset > label:nth-child(5)",
+	NumberField:     "#nuProcessoAntigoFormatado",
+	SearchButton:    "#botaoConsultarProcessos",
+}
+
+// Crawler searches lawsuits on an e-SAJ instance.
+type Crawler struct {
+	Tribunal  string
+	URL       string
+	Selectors Selectors
+	// FollowSecondInstance makes Lawsuit follow the "Visualizar em 2º grau" links and merge the appeals.
+	FollowSecondInstance bool
+	// WaitTimeout bounds the wait for the search results, which are much slower than nav.Timeout.
+	WaitTimeout time.Duration
+}
+
+// New creates a Crawler for one of the known Tribunals.
+// Example:
+//
+//	crawler, err := esaj.New("TJSP")
+func New(tribunal string) (*Crawler, error) {
+	u, ok := Tribunals[tribunal]
+	if !ok {
+		return nil, fmt.Errorf("error - unknown e-SAJ tribunal: %s", tribunal)
+	}
+	return &Crawler{
+		Tribunal:             tribunal,
+		URL:                  u,
+		Selectors:            DefaultSelectors,
+		FollowSecondInstance: true,
+		WaitTimeout:          30 * time.Second,
+	}, nil
+}
+
+// SearchFirstInstance looks up a lawsuit on cpopg and returns the page source of its details page.
+// Example:
+//
+//	pageSource, err := crawler.SearchFirstInstance(nav, "1017927-35.2023.8.26.0008")
+func (c *Crawler) SearchFirstInstance(nav goSpider.Browser, number string) (*html.Node, error) {
+	n, err := cnj.Normalize(number)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.OpenURL(c.resolve("cpopg/open.do"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.CheckRadioButton(c.Selectors.NumberTypeRadio)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.FillField(c.Selectors.NumberField, n)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.ClickButton(c.Selectors.SearchButton)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.WaitForElement("#numeroProcesso", c.WaitTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error - lawsuit %s not found: %w", n, err)
+	}
+
+	return nav.GetPageSource()
+}
+
+// SearchSecondInstance looks up the appeals of a lawsuit on cposg and returns the page source of each one.
+// Example:
+//
+//	pageSources, err := crawler.SearchSecondInstance(nav, "1017927-35.2023.8.26.0008")
+func (c *Crawler) SearchSecondInstance(nav goSpider.Browser, number string) ([]*html.Node, error) {
+	n, err := cnj.Parse(number)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("conversationId", "")
+	q.Set("paginaConsulta", "1")
+	q.Set("cbPesquisa", "NUMPROC")
+	q.Set("tipoNuProcesso", "UNIFICADO")
+	q.Set("numeroDigitoAnoUnificado", n.Sequence+"-"+n.CheckDigits+"."+n.Year)
+	q.Set("foroNumeroUnificado", n.Origin)
+	q.Set("dePesquisaNuUnificado", n.String())
+
+	return c.openSecondInstance(nav, c.resolve("cposg/search.do?"+q.Encode()))
+}
+
+// Lawsuit searches a lawsuit on the first instance and, when FollowSecondInstance is set, merges its appeals.
+// Example:
+//
+//	lawsuit, err := crawler.Lawsuit(nav, "1017927-35.2023.8.26.0008")
+func (c *Crawler) Lawsuit(nav goSpider.Browser, number string) (courts.Lawsuit, error) {
+	pageSource, err := c.SearchFirstInstance(nav, number)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	first, err := ExtractLawsuit(pageSource, 1)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+	first.Tribunal = c.Tribunal
+
+	if !c.FollowSecondInstance {
+		return first, nil
+	}
+
+	var appeals []courts.Lawsuit
+	for _, link := range SecondInstanceLinks(pageSource) {
+		pages, err := c.openSecondInstance(nav, c.resolve(link))
+		if err != nil {
+			nav.GetLogger().Printf("Error - Failed to open second instance %s: %v\n", link, err)
+			continue
+		}
+		for _, p := range pages {
+			appeal, err := ExtractLawsuit(p, 2)
+			if err != nil {
+				nav.GetLogger().Printf("Error - Failed to extract second instance: %v\n", err)
+				continue
+			}
+			appeals = append(appeals, appeal)
+		}
+	}
+
+	return Merge(first, appeals...), nil
+}
+
+// openSecondInstance opens a cposg URL, which either shows the appeal directly or lists several of them.
+func (c *Crawler) openSecondInstance(nav goSpider.Browser, u string) ([]*html.Node, error) {
+	err := nav.OpenURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSource, err := nav.GetPageSource()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = goSpider.FindNodes(pageSource, "//*[@id=\"numeroProcesso\"]"); err == nil {
+		return []*html.Node{pageSource}, nil
+	}
+
+	links := appealLinks(pageSource)
+	if len(links) == 0 {
+		return nil, errors.New("error - could not find any second instance lawsuit")
+	}
+
+	var pages []*html.Node
+	for _, link := range links {
+		err = nav.OpenURL(c.resolve(link))
+		if err != nil {
+			return nil, err
+		}
+		p, err := nav.GetPageSource()
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	return pages, nil
+}
+
+// resolve resolves a path relative to the tribunal's e-SAJ base URL.
+func (c *Crawler) resolve(ref string) string {
+	b, err := url.Parse(c.URL)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// SecondInstanceLinks returns the "Visualizar em 2º grau" links of a first instance details page.
+func SecondInstanceLinks(pageSource *html.Node) []string {
+	return hrefs(pageSource, "//a[contains(@href,'cposg') and (contains(normalize-space(.),'2º grau') or contains(normalize-space(.),'2º Grau'))]/@href")
+}
+
+// appealLinks returns the links of a cposg page listing several appeals, including the incident selection modal.
+func appealLinks(pageSource *html.Node) []string {
+	links := hrefs(pageSource, "//a[contains(@href,'cposg/show.do')]/@href")
+	for _, code := range hrefs(pageSource, "//input[@name='processoSelecionado']/@value") {
+		links = append(links, "cposg/show.do?processo.codigo="+url.QueryEscape(code))
+	}
+	return links
+}
+
+// hrefs returns the deduplicated values selected by an attribute xpath.
+func hrefs(pageSource *html.Node, xpath string) []string {
+	nodes, err := goSpider.FindNodes(pageSource, xpath)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var values []string
+	for _, n := range nodes {
+		v := strings.TrimSpace(htmlquery.InnerText(n))
+		if v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ExtractLawsuit extracts cover, parties and movements from a cpopg (instance 1) or cposg (instance 2) details page.
+// Example:
+//
+//	lawsuit, err := esaj.ExtractLawsuit(pageSource, 1)
+func ExtractLawsuit(pageSource *html.Node, instance int) (courts.Lawsuit, error) {
+	cover, err := ExtractCover(pageSource, instance)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	persons, err := ExtractPersons(pageSource)
+	if err != nil {
+		cover.Error = err.Error()
+	}
+
+	movements, err := ExtractMovements(pageSource, instance)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	number, _ := cnj.Normalize(cover.Title)
+	return courts.Lawsuit{
+		Number:    number,
+		System:    "esaj",
+		Cover:     cover,
+		Persons:   persons,
+		Movements: movements,
+	}, nil
+}
+
+// ExtractCover extracts the header of a details page. The cposg page uses different ids for location, unit and judge.
+func ExtractCover(pageSource *html.Node, instance int) (courts.Cover, error) {
+	var cover courts.Cover
+	fields := map[string]*string{
+		"//*[@id=\"numeroProcesso\"]":    &cover.Title,
+		"//*[@id=\"classeProcesso\"]":    &cover.Class,
+		"//*[@id=\"assuntoProcesso\"]":   &cover.Subject,
+		"//*[@id=\"valorAcaoProcesso\"]": &cover.Value,
+	}
+	if instance == 2 {
+		fields["//*[@id=\"situacaoProcesso\"]"] = &cover.Tag
+		fields["//*[@id=\"secaoProcesso\"]"] = &cover.Location
+		fields["//*[@id=\"orgaoJulgadorProcesso\"]"] = &cover.Unit
+		fields["//*[@id=\"relatorProcesso\"]"] = &cover.Judge
+		fields["//*[@id=\"areaProcesso\"]/span"] = &cover.Field
+	} else {
+		fields["//*[@id=\"labelSituacaoProcesso\"]"] = &cover.Tag
+		fields["//*[@id=\"foroProcesso\"]"] = &cover.Location
+		fields["//*[@id=\"varaProcesso\"]"] = &cover.Unit
+		fields["//*[@id=\"juizProcesso\"]"] = &cover.Judge
+		fields["//*[@id=\"dataHoraDistribuicaoProcesso\"]"] = &cover.InitialDate
+		fields["//*[@id=\"numeroControleProcesso\"]"] = &cover.Control
+		fields["//*[@id=\"areaProcesso\"]/span"] = &cover.Field
+	}
+
+	var missing int
+	for xpath, value := range fields {
+		text, err := goSpider.ExtractText(pageSource, xpath, "\n")
+		if err != nil {
+			missing++
+			continue
+		}
+		*value = strings.Join(strings.Fields(text), " ")
+	}
+	cover.Value = strings.TrimSpace(strings.TrimPrefix(cover.Value, "R$"))
+
+	if cover.Title == "" || missing >= 5 {
+		return courts.Cover{}, fmt.Errorf("error - could not extract cover, %d fields missing", missing)
+	}
+	return cover, nil
+}
+
+// ExtractPersons extracts the parties from the full parties table, falling back to the main parties table.
+func ExtractPersons(pageSource *html.Node) ([]courts.Person, error) {
+	rows, err := goSpider.FindNodes(pageSource, "//*[@id=\"tableTodasPartes\"]/tbody/tr")
+	if err != nil {
+		rows, err = goSpider.FindNodes(pageSource, "//*[@id=\"tablePartesPrincipais\"]/tbody/tr")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var persons []courts.Person
+	for _, row := range rows {
+		pole, err := goSpider.ExtractText(row, "td[1]", "\n")
+		if err != nil {
+			continue
+		}
+
+		texts, err := goSpider.FindNodes(row, "td[2]/text()")
+		if err != nil {
+			continue
+		}
+		var values []string
+		for _, t := range texts {
+			v := strings.Join(strings.Fields(htmlquery.InnerText(t)), " ")
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		persons = append(persons, courts.Person{
+			Pole:    strings.Join(strings.Fields(pole), " "),
+			Name:    values[0],
+			Lawyers: values[1:],
+		})
+	}
+
+	if len(persons) == 0 {
+		return nil, errors.New("could not find any person")
+	}
+	return persons, nil
+}
+
+// ExtractMovements extracts the full movements table, tagging each entry with its instance.
+func ExtractMovements(pageSource *html.Node, instance int) ([]courts.Movement, error) {
+	rows, err := goSpider.ExtractTable(pageSource, "//*[@id=\"tabelaTodasMovimentacoes\"]/tr")
+	if err != nil {
+		return nil, err
+	}
+
+	var movements []courts.Movement
+	for _, row := range rows {
+		date, err := goSpider.ExtractText(row, "td[1]", "\n")
+		if err != nil {
+			return nil, errors.New("error extracting table date: " + err.Error())
+		}
+		title, err := goSpider.ExtractText(row, "td[3]", "\n")
+		if err != nil {
+			return nil, errors.New("error extracting table title: " + err.Error())
+		}
+		text, _ := goSpider.ExtractText(row, "td[3]/span", "\n")
+
+		movements = append(movements, courts.Movement{
+			Date:     strings.TrimSpace(strings.ReplaceAll(date, "\t", "")),
+			Title:    strings.Join(strings.Fields(strings.Replace(title, text, "", 1)), " "),
+			Text:     strings.Join(strings.Fields(text), " "),
+			Instance: instance,
+		})
+	}
+	return movements, nil
+}
+
+// Merge combines a first instance lawsuit with its appeals: the appeal covers are kept in Appeals,
+// parties are deduplicated by pole and name, and movements of both instances are sorted from newest to oldest.
+// Example:
+//
+//	lawsuit := esaj.Merge(first, appeals...)
+func Merge(first courts.Lawsuit, appeals ...courts.Lawsuit) courts.Lawsuit {
+	merged := first
+	merged.Persons = append([]courts.Person{}, first.Persons...)
+	merged.Movements = append([]courts.Movement{}, first.Movements...)
+
+	seen := map[string]bool{}
+	for _, p := range first.Persons {
+		seen[p.Pole+"|"+p.Name] = true
+	}
+
+	for _, appeal := range appeals {
+		merged.Appeals = append(merged.Appeals, appeal.Cover)
+		for _, p := range appeal.Persons {
+			if !seen[p.Pole+"|"+p.Name] {
+				seen[p.Pole+"|"+p.Name] = true
+				merged.Persons = append(merged.Persons, p)
+			}
+		}
+		merged.Movements = append(merged.Movements, appeal.Movements...)
+		merged.Documents = append(merged.Documents, appeal.Documents...)
+	}
+
+	sort.SliceStable(merged.Movements, func(i, j int) bool {
+		return movementDate(merged.Movements[i]).After(movementDate(merged.Movements[j]))
+	})
+	return merged
+}
+
+// movementDate parses the dd/mm/yyyy date of a movement, returning the zero time when it is malformed.
+func movementDate(m courts.Movement) time.Time {
+	fields := strings.Fields(m.Date)
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+	t, _ := time.Parse("02/01/2006", fields[0])
+	return t
+}
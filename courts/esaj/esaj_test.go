@@ -0,0 +1,90 @@
+package esaj
+
+import (
+	"github.com/DanielFillol/goSpider/courts"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"strings"
+	"testing"
+)
+
+const firstInstancePage = `<html><body>
+<span id="numeroProcesso">1017927-35.2023.8.26.0008</span>
+<span id="labelSituacaoProcesso">Em andamento</span>
+<span id="classeProcesso">Procedimento Comum Cível</span>
+<span id="assuntoProcesso">Indenização por Dano Moral</span>
+<span id="foroProcesso">Foro Regional VIII - Tatuapé</span>
+<span id="varaProcesso">1ª Vara Cível</span>
+<span id="juizProcesso">JUIZ DE DIREITO</span>
+<div id="valorAcaoProcesso">R$         10.000,00</div>
+<a href="/cposg/search.do?cbPesquisa=NUMPROC&amp;dePesquisaNuUnificado=1017927-35.2023.8.26.0008">Visualizar em 2º grau</a>
+<table id="tableTodasPartes"><tbody>
+<tr><td><span>Reqte</span></td><td>FULANO DE TAL<br><span>Advogado:</span> CICRANO</td></tr>
+<tr><td><span>Reqdo</span></td><td>EMPRESA S.A.</td></tr>
+</tbody></table>
+<table><tbody id="tabelaTodasMovimentacoes">
+<tr><td>12/04/2023</td><td></td><td>Conclusos para Decisão<br><span>Decisão interlocutória</span></td></tr>
+<tr><td>10/03/2023</td><td></td><td>Distribuído Livremente<br><span></span></td></tr>
+</tbody></table>
+</body></html>`
+
+func TestExtractLawsuit(t *testing.T) {
+	pageSource, err := htmlquery.Parse(strings.NewReader(firstInstancePage))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	lawsuit, err := ExtractLawsuit(pageSource, 1)
+	if err != nil {
+		t.Fatalf("ExtractLawsuit error: %v", err)
+	}
+
+	if lawsuit.Number != "1017927-35.2023.8.26.0008" {
+		t.Errorf("Expected number 1017927-35.2023.8.26.0008, but got: %s", lawsuit.Number)
+	}
+	if lawsuit.Cover.Value != "10.000,00" {
+		t.Errorf("Expected value 10.000,00, but got: %s", lawsuit.Cover.Value)
+	}
+	if len(lawsuit.Persons) != 2 || lawsuit.Persons[0].Name != "FULANO DE TAL" || len(lawsuit.Persons[0].Lawyers) != 1 || lawsuit.Persons[0].Lawyers[0] != "CICRANO" {
+		t.Errorf("Unexpected persons: %+v", lawsuit.Persons)
+	}
+	if len(lawsuit.Movements) != 2 || lawsuit.Movements[0].Title != "Conclusos para Decisão" || lawsuit.Movements[0].Text != "Decisão interlocutória" {
+		t.Errorf("Unexpected movements: %+v", lawsuit.Movements)
+	}
+
+	links := SecondInstanceLinks(pageSource)
+	if len(links) != 1 || !strings.HasPrefix(links[0], "/cposg/search.do") {
+		t.Errorf("Unexpected second instance links: %v", links)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	first := courts.Lawsuit{
+		Cover:   courts.Cover{Title: "1017927-35.2023.8.26.0008"},
+		Persons: []courts.Person{{Pole: "Reqte", Name: "FULANO"}},
+		Movements: []courts.Movement{
+			{Date: "10/03/2023", Title: "Distribuído", Instance: 1},
+			{Date: "12/05/2023", Title: "Sentença", Instance: 1},
+		},
+	}
+	appeal := courts.Lawsuit{
+		Cover:   courts.Cover{Title: "1017927-35.2023.8.26.0008", Unit: "1ª Câmara"},
+		Persons: []courts.Person{{Pole: "Reqte", Name: "FULANO"}, {Pole: "Apelado", Name: "EMPRESA"}},
+		Movements: []courts.Movement{
+			{Date: "01/08/2023", Title: "Acórdão", Instance: 2},
+		},
+	}
+
+	merged := Merge(first, appeal)
+	if len(merged.Appeals) != 1 || merged.Appeals[0].Unit != "1ª Câmara" {
+		t.Errorf("Unexpected appeals: %+v", merged.Appeals)
+	}
+	if len(merged.Persons) != 2 {
+		t.Errorf("Expected 2 persons, but got: %+v", merged.Persons)
+	}
+	if len(merged.Movements) != 3 || merged.Movements[0].Title != "Acórdão" || merged.Movements[2].Title != "Distribuído" {
+		t.Errorf("Unexpected movements order: %+v", merged.Movements)
+	}
+	if len(first.Movements) != 2 {
+		t.Errorf("Merge must not modify the first instance movements")
+	}
+}
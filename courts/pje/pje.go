@@ -0,0 +1,438 @@
+/*
+Package pje crawls the public consultation pages of PJe (Processo Judicial Eletrônico) tribunals
+and normalizes them into the courts.Lawsuit schema.
+*/
+package pje
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/cnj"
+	"github.com/DanielFillol/goSpider/courts"
+	"golang.org/x/net/html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tribunals maps tribunal acronyms to the public consultation page of their first instance PJe.
+var Tribunals = map[string]string{
+	"TRF1":  "https://pje1g.trf1.jus.br/consultapublica/ConsultaPublica/listView.seam",
+	"TRF3":  "https://pje1g.trf3.jus.br/pje/ConsultaPublica/listView.seam",
+	"TJMG":  "https://pje-consulta-publica.tjmg.jus.br/pje/ConsultaPublica/listView.seam",
+	"TJBA":  "https://consultapublicapje.tjba.jus.br/pje/ConsultaPublica/listView.seam",
+	"TJDFT": "https://pje-consultapublica.tjdft.jus.br/consultapublica/ConsultaPublica/listView.seam",
+	"TJPE":  "https://pje.cloud.tjpe.jus.br/1g/ConsultaPublica/listView.seam",
+}
+
+// registryCodes are the courts registry codes of the Tribunals that cnj.Number.Tribunal doesn't return, such as
+// TJDFT, whose numbers have the TR segment 07 of the Distrito Federal.
+var registryCodes = map[string]string{
+	"TJDFT": "8.07",
+}
+
+// init registers a Crawler for every known tribunal on the courts registry.
+func init() {
+	for tribunal := range Tribunals {
+		c, _ := New(tribunal)
+		code, ok := registryCodes[tribunal]
+		if !ok {
+			code = tribunal
+		}
+		courts.Register(code, c)
+	}
+}
+
+// Selectors holds the selectors used on the PJe consultation pages.
+// The ids generated by JSF change between versions, so the defaults match on id suffixes and labels.
+type Selectors struct {
+	NumberField  string // input that receives the CNJ number
+	SearchButton string // button that submits the search
+	ResultLink   string // link to the lawsuit details on the results table
+	CaptchaImage string // CAPTCHA image shown by some instances, may be empty
+	CaptchaField string // input that receives the CAPTCHA answer
+	DetailReady  string // element present once the details page is loaded
+}
+
+// DefaultSelectors are the selectors of the standard PJe consultation pages.
+var DefaultSelectors = Selectors{
+	NumberField:  "input[id$='numProcesso-inputNumeroProcesso']",
+	SearchButton: "input[id$='searchProcessos']",
+	ResultLink:   "a[onclick*='DetalheProcessoConsultaPublica']",
+	CaptchaImage: "img[id$='captchaImg']",
+	CaptchaField: "input[id$='captchaInput']",
+	DetailReady:  "div.propertyView",
+}
+
+// CaptchaSolver receives the CAPTCHA image as PNG bytes and returns the text to be typed.
+type CaptchaSolver func(image []byte) (string, error)
+
+// Crawler searches lawsuits on a PJe instance.
+type Crawler struct {
+	Tribunal      string
+	URL           string
+	Selectors     Selectors
+	CaptchaSolver CaptchaSolver
+	// WaitTimeout bounds the wait for the results and details pages, which are much slower than nav.Timeout.
+	WaitTimeout time.Duration
+}
+
+// New creates a Crawler for one of the known Tribunals.
+// Example:
+//
+//	crawler, err := pje.New("TJMG")
+func New(tribunal string) (*Crawler, error) {
+	u, ok := Tribunals[tribunal]
+	if !ok {
+		return nil, fmt.Errorf("error - unknown PJe tribunal: %s", tribunal)
+	}
+	return &Crawler{
+		Tribunal:    tribunal,
+		URL:         u,
+		Selectors:   DefaultSelectors,
+		WaitTimeout: 30 * time.Second,
+	}, nil
+}
+
+// Search looks up a lawsuit by its CNJ number and returns the page source of its details page.
+// Example:
+//
+//	pageSource, err := crawler.Search(nav, "1000113-34.2018.5.02.0386")
+func (c *Crawler) Search(nav goSpider.Browser, number string) (*html.Node, error) {
+	n, err := cnj.Normalize(number)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.OpenURL(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.FillField(c.Selectors.NumberField, n)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.solveCaptcha(nav)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.ClickButton(c.Selectors.SearchButton)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.WaitForElement(c.Selectors.ResultLink, c.WaitTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error - lawsuit %s not found: %w", n, err)
+	}
+
+	onclick, err := nav.GetElementAttribute(c.Selectors.ResultLink, "onclick")
+	if err != nil {
+		return nil, err
+	}
+
+	detailURL, err := c.resolvePopupURL(onclick)
+	if err != nil {
+		return nil, err
+	}
+
+	// The details are opened in a popup by the page; navigating in place avoids handling a new target
+	err = nav.OpenURL(detailURL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nav.WaitForElement(c.Selectors.DetailReady, c.WaitTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return nav.GetPageSource()
+}
+
+// Lawsuit searches a lawsuit and extracts it into the shared schema.
+// Example:
+//
+//	lawsuit, err := crawler.Lawsuit(nav, "1000113-34.2018.5.02.0386")
+func (c *Crawler) Lawsuit(nav goSpider.Browser, number string) (courts.Lawsuit, error) {
+	pageSource, err := c.Search(nav, number)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	lawsuit, err := ExtractLawsuit(pageSource)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+	lawsuit.Tribunal = c.Tribunal
+	for i, d := range lawsuit.Documents {
+		if d.URL == "" {
+			continue
+		}
+		// the viewer links are relative to the instance, the downloaders need them absolute
+		if u, err := resolveURL(c.URL, d.URL); err == nil {
+			lawsuit.Documents[i].URL = u
+		}
+	}
+	if lawsuit.Number == "" {
+		lawsuit.Number, _ = cnj.Normalize(number)
+	}
+
+	return lawsuit, nil
+}
+
+// solveCaptcha fills the CAPTCHA answer when the instance shows one.
+func (c *Crawler) solveCaptcha(nav goSpider.Browser) error {
+	if c.Selectors.CaptchaImage == "" {
+		return nil
+	}
+	err := nav.WaitForElement(c.Selectors.CaptchaImage, nav.GetTimeOut())
+	if err != nil {
+		return nil // no CAPTCHA on this instance
+	}
+	if c.CaptchaSolver == nil {
+		return fmt.Errorf("error - no CaptchaSolver was configured: %w", goSpider.ErrCaptchaDetected)
+	}
+
+	image, err := nav.ElementScreenshot(c.Selectors.CaptchaImage)
+	if err != nil {
+		return fmt.Errorf("error - failed to capture CAPTCHA image: %w", err)
+	}
+
+	answer, err := c.CaptchaSolver(image)
+	if err != nil {
+		return fmt.Errorf("error - failed to solve CAPTCHA: %w", err)
+	}
+
+	return nav.FillField(c.Selectors.CaptchaField, answer)
+}
+
+var popupURL = regexp.MustCompile(`'([^']*DetalheProcessoConsultaPublica[^']*)'`)
+
+// resolvePopupURL extracts the details URL from the openPopUp call of a result link.
+func (c *Crawler) resolvePopupURL(onclick string) (string, error) {
+	m := popupURL.FindStringSubmatch(onclick)
+	if m == nil {
+		return "", fmt.Errorf("error - could not find details URL in: %s", onclick)
+	}
+	return resolveURL(c.URL, m[1])
+}
+
+// ExtractLawsuit extracts cover, parties, movements and documents from a PJe details page.
+// Example:
+//
+//	lawsuit, err := pje.ExtractLawsuit(pageSource)
+func ExtractLawsuit(pageSource *html.Node) (courts.Lawsuit, error) {
+	cover, err := ExtractCover(pageSource)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	persons, err := ExtractPersons(pageSource)
+	if err != nil {
+		cover.Error = err.Error()
+	}
+
+	movements, err := ExtractMovements(pageSource)
+	if err != nil {
+		return courts.Lawsuit{}, err
+	}
+
+	// Documents are hidden for lawsuits under seal, so their absence is not an error
+	documents, _ := ExtractDocuments(pageSource)
+
+	number, _ := cnj.Normalize(cover.Title)
+	return courts.Lawsuit{
+		Number:    number,
+		System:    "pje",
+		Cover:     cover,
+		Persons:   persons,
+		Movements: movements,
+		Documents: documents,
+	}, nil
+}
+
+// property returns the xpath of the value of a labeled field on the details page.
+func property(label string) string {
+	return "//div[contains(@class,'propertyView')][.//label[contains(normalize-space(.),'" + label + "')]]//div[contains(@class,'value')]"
+}
+
+// ExtractCover extracts the header fields of a PJe details page.
+func ExtractCover(pageSource *html.Node) (courts.Cover, error) {
+	var cover courts.Cover
+	fields := map[string]*string{
+		"Número Processo":      &cover.Title,
+		"Data da Distribuição": &cover.InitialDate,
+		"Classe Judicial":      &cover.Class,
+		"Assunto":              &cover.Subject,
+		"Jurisdição":           &cover.Location,
+		"Órgão Julgador":       &cover.Unit,
+		"Valor da causa":       &cover.Value,
+	}
+
+	var missing int
+	for label, value := range fields {
+		text, err := goSpider.ExtractText(pageSource, property(label), "\n")
+		if err != nil {
+			missing++
+			continue
+		}
+		*value = strings.Join(strings.Fields(text), " ")
+	}
+
+	if cover.Title == "" || missing >= 4 {
+		return courts.Cover{}, fmt.Errorf("error - could not extract cover, %d fields missing", missing)
+	}
+	return cover, nil
+}
+
+// ExtractPersons extracts the parties of every pole with their lawyers.
+func ExtractPersons(pageSource *html.Node) ([]courts.Person, error) {
+	var persons []courts.Person
+	for _, pole := range []string{"PoloAtivo", "PoloPassivo", "OutrosInteressados"} {
+		rows, err := goSpider.FindNodes(pageSource, "//table[contains(@id,'"+pole+"')]/tbody/tr")
+		if err != nil {
+			continue
+		}
+		for _, row := range rows {
+			text, err := goSpider.ExtractText(row, "td[1]/span[1]", "\n")
+			if err != nil {
+				continue
+			}
+			name, role := splitRole(text)
+			if role == "" {
+				role = pole
+			}
+
+			var lawyers []string
+			ll, err := goSpider.FindNodes(row, "td[1]/ul/li")
+			if err == nil {
+				for _, l := range ll {
+					lawyer, err := goSpider.ExtractText(l, ".", "\n")
+					if err == nil {
+						n, _ := splitRole(lawyer)
+						lawyers = append(lawyers, n)
+					}
+				}
+			}
+
+			persons = append(persons, courts.Person{
+				Pole:    role,
+				Name:    name,
+				Lawyers: lawyers,
+			})
+		}
+	}
+
+	if len(persons) == 0 {
+		return nil, errors.New("could not find any person")
+	}
+	return persons, nil
+}
+
+// ExtractMovements extracts the docket entries, which PJe renders as "dd/mm/yyyy hh:mm:ss - Title".
+func ExtractMovements(pageSource *html.Node) ([]courts.Movement, error) {
+	rows, err := goSpider.ExtractTable(pageSource, "//tbody[contains(@id,'processoEvento')]/tr")
+	if err != nil {
+		return nil, err
+	}
+
+	var movements []courts.Movement
+	for _, row := range rows {
+		text, err := goSpider.ExtractText(row, "td[1]", "\n")
+		if err != nil {
+			continue
+		}
+		date, title := splitDate(text)
+		movements = append(movements, courts.Movement{
+			Date:  date,
+			Title: title,
+		})
+	}
+
+	if len(movements) == 0 {
+		return nil, errors.New("error table: could not find any movements")
+	}
+	return movements, nil
+}
+
+// ExtractDocuments extracts the documents listed on the details page with their viewer URLs, as found on the page;
+// Crawler.Lawsuit resolves them against the URL of the instance.
+func ExtractDocuments(pageSource *html.Node) ([]courts.Document, error) {
+	rows, err := goSpider.ExtractTable(pageSource, "//tbody[contains(@id,'processoDocumentoGridTab')]/tr")
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []courts.Document
+	for _, row := range rows {
+		text, err := goSpider.ExtractText(row, "td[1]//a", "\n")
+		if err != nil {
+			continue
+		}
+		date, title := splitDate(text)
+
+		var link string
+		onclick, err := goSpider.ExtractText(row, "td[1]//a/@onclick", "")
+		if err == nil {
+			if m := documentURL.FindStringSubmatch(onclick); m != nil {
+				link = m[1]
+			}
+		}
+
+		documents = append(documents, courts.Document{
+			Date:  date,
+			Title: title,
+			URL:   link,
+		})
+	}
+
+	if len(documents) == 0 {
+		return nil, errors.New("could not find any document")
+	}
+	return documents, nil
+}
+
+var documentURL = regexp.MustCompile(`'([^']*\.seam\?[^']*)'`)
+
+// splitDate splits "dd/mm/yyyy hh:mm:ss - Title" into its date and title.
+func splitDate(text string) (string, string) {
+	text = strings.Join(strings.Fields(text), " ")
+	parts := strings.SplitN(text, " - ", 2)
+	if len(parts) != 2 {
+		return "", text
+	}
+	return parts[0], parts[1]
+}
+
+// splitRole splits "NAME - CPF: 000.000.000-00 (AUTOR)" into the name and the role.
+func splitRole(text string) (string, string) {
+	text = strings.Join(strings.Fields(text), " ")
+	var role string
+	if i := strings.LastIndex(text, "("); i >= 0 && strings.HasSuffix(text, ")") {
+		role = text[i+1 : len(text)-1]
+		text = strings.TrimSpace(text[:i])
+	}
+	if i := strings.Index(text, " - "); i >= 0 {
+		text = text[:i]
+	}
+	return text, role
+}
+
+// resolveURL resolves a reference found on the page against the page URL.
+func resolveURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
@@ -0,0 +1,190 @@
+package pje
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/courts"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+	"strings"
+	"testing"
+	"time"
+)
+
+const detailPage = `<html><body>
+<div class="propertyView"><div class="name"><label>Número Processo</label></div><div class="value"><div class="col-sm-12">1000113-34.2018.5.02.0386</div></div></div>
+<div class="propertyView"><div class="name"><label>Data da Distribuição</label></div><div class="value"><div class="col-sm-12">10/03/2018</div></div></div>
+<div class="propertyView"><div class="name"><label>Classe Judicial</label></div><div class="value"><div class="col-sm-12">PROCEDIMENTO COMUM CÍVEL (7)</div></div></div>
+<div class="propertyView"><div class="name"><label>Assunto</label></div><div class="value"><div class="col-sm-12">Indenização por Dano Moral</div></div></div>
+<div class="propertyView"><div class="name"><label>Jurisdição</label></div><div class="value"><div class="col-sm-12">Belo Horizonte</div></div></div>
+<div class="propertyView"><div class="name"><label>Órgão Julgador</label></div><div class="value"><div class="col-sm-12">1ª Vara Cível</div></div></div>
+<table id="j_id150:processoPartesPoloAtivoResumidoList"><tbody>
+<tr><td><span><span>FULANO DE TAL - CPF: 000.000.000-00 (AUTOR)</span></span><ul><li><small>CICRANO ADVOGADO - OAB MG123456 (ADVOGADO)</small></li></ul></td></tr>
+</tbody></table>
+<table id="j_id170:processoPartesPoloPassivoResumidoList"><tbody>
+<tr><td><span><span>EMPRESA S.A. - CNPJ: 00.000.000/0001-00 (RÉU)</span></span></td></tr>
+</tbody></table>
+<table><tbody id="j_id190:processoEvento:tb">
+<tr><td>12/04/2018 10:31:02 - Conclusos para decisão</td></tr>
+<tr><td>10/03/2018 09:00:00 - Distribuído por sorteio</td></tr>
+</tbody></table>
+<table><tbody id="j_id210:processoDocumentoGridTab:tb">
+<tr><td><a onclick="openPopUp('Documento','/pje/ConsultaPublica/DetalheProcessoConsultaPublica/documentoSemLoginHTML.seam?ca=abc123')">10/03/2018 09:00:00 - Petição Inicial</a></td></tr>
+</tbody></table>
+</body></html>`
+
+func TestExtractLawsuit(t *testing.T) {
+	pageSource, err := htmlquery.Parse(strings.NewReader(detailPage))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	lawsuit, err := ExtractLawsuit(pageSource)
+	if err != nil {
+		t.Fatalf("ExtractLawsuit error: %v", err)
+	}
+
+	if lawsuit.Number != "1000113-34.2018.5.02.0386" {
+		t.Errorf("Expected number 1000113-34.2018.5.02.0386, but got: %s", lawsuit.Number)
+	}
+	if lawsuit.Cover.Unit != "1ª Vara Cível" {
+		t.Errorf("Expected unit 1ª Vara Cível, but got: %s", lawsuit.Cover.Unit)
+	}
+	if len(lawsuit.Persons) != 2 {
+		t.Fatalf("Expected 2 persons, but got %d: %v", len(lawsuit.Persons), lawsuit.Persons)
+	}
+	if lawsuit.Persons[0].Name != "FULANO DE TAL" || lawsuit.Persons[0].Pole != "AUTOR" {
+		t.Errorf("Unexpected person: %+v", lawsuit.Persons[0])
+	}
+	if len(lawsuit.Persons[0].Lawyers) != 1 || lawsuit.Persons[0].Lawyers[0] != "CICRANO ADVOGADO" {
+		t.Errorf("Unexpected lawyers: %v", lawsuit.Persons[0].Lawyers)
+	}
+	if len(lawsuit.Movements) != 2 || lawsuit.Movements[0].Date != "12/04/2018 10:31:02" || lawsuit.Movements[0].Title != "Conclusos para decisão" {
+		t.Errorf("Unexpected movements: %v", lawsuit.Movements)
+	}
+	if len(lawsuit.Documents) != 1 || !strings.Contains(lawsuit.Documents[0].URL, "documentoSemLoginHTML.seam?ca=abc123") {
+		t.Errorf("Unexpected documents: %v", lawsuit.Documents)
+	}
+}
+
+func TestResolvePopupURL(t *testing.T) {
+	c, err := New("TJMG")
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+
+	u, err := c.resolvePopupURL("openPopUp('Consulta pública','/pje/ConsultaPublica/DetalheProcessoConsultaPublica/listView.seam?ca=abc')")
+	if err != nil {
+		t.Fatalf("resolvePopupURL error: %v", err)
+	}
+
+	expected := "https://pje-consulta-publica.tjmg.jus.br/pje/ConsultaPublica/DetalheProcessoConsultaPublica/listView.seam?ca=abc"
+	if u != expected {
+		t.Errorf("Expected %s, but got: %s", expected, u)
+	}
+}
+
+// fakeBrowser serves the details page and records the actions of a crawler.
+type fakeBrowser struct {
+	goSpider.Browser
+	actions  []string
+	waitErrs map[string]error // errors of WaitForElement by selector
+}
+
+func (b *fakeBrowser) OpenURL(url string) error {
+	b.actions = append(b.actions, "open "+url)
+	return nil
+}
+
+func (b *fakeBrowser) FillField(selector, value string) error {
+	b.actions = append(b.actions, "fill "+value)
+	return nil
+}
+
+func (b *fakeBrowser) WaitForElement(selector string, timeout time.Duration) error {
+	return b.waitErrs[selector]
+}
+
+func (b *fakeBrowser) ElementScreenshot(selector string) ([]byte, error) {
+	return []byte("png"), nil
+}
+
+func (b *fakeBrowser) ClickButton(selector string) error {
+	b.actions = append(b.actions, "click "+selector)
+	return nil
+}
+
+func (b *fakeBrowser) GetElementAttribute(selector, attribute string) (string, error) {
+	return "openPopUp('Consulta','/pje/ConsultaPublica/DetalheProcessoConsultaPublica/listView.seam?ca=abc')", nil
+}
+
+func (b *fakeBrowser) GetPageSource() (*html.Node, error) {
+	return htmlquery.Parse(strings.NewReader(detailPage))
+}
+
+func (b *fakeBrowser) GetTimeOut() time.Duration {
+	return time.Second
+}
+
+func TestLawsuitWithMockBrowser(t *testing.T) {
+	c, err := New("TJMG")
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	c.CaptchaSolver = func(image []byte) (string, error) { return "x7k2", nil }
+
+	browser := &fakeBrowser{}
+	lawsuit, err := c.Lawsuit(browser, "10001133420185020386")
+	if err != nil {
+		t.Fatalf("Lawsuit error: %v", err)
+	}
+	if lawsuit.Number != "1000113-34.2018.5.02.0386" || lawsuit.Tribunal != "TJMG" {
+		t.Errorf("Unexpected lawsuit: %s %s", lawsuit.Number, lawsuit.Tribunal)
+	}
+	document := "https://pje-consulta-publica.tjmg.jus.br/pje/ConsultaPublica/DetalheProcessoConsultaPublica/documentoSemLoginHTML.seam?ca=abc123"
+	if len(lawsuit.Documents) != 1 || lawsuit.Documents[0].URL != document {
+		t.Errorf("Expected the document URL %s, but got: %v", document, lawsuit.Documents)
+	}
+	expected := []string{
+		"open " + c.URL,
+		"fill 1000113-34.2018.5.02.0386",
+		"fill x7k2",
+		"click " + c.Selectors.SearchButton,
+		"open https://pje-consulta-publica.tjmg.jus.br/pje/ConsultaPublica/DetalheProcessoConsultaPublica/listView.seam?ca=abc",
+	}
+	if strings.Join(browser.actions, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected actions %v, but got: %v", expected, browser.actions)
+	}
+}
+
+func TestLawsuitErrorKinds(t *testing.T) {
+	c, err := New("TJMG")
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+
+	_, err = c.Lawsuit(&fakeBrowser{}, "10001133420185020386")
+	if !errors.Is(err, goSpider.ErrCaptchaDetected) {
+		t.Errorf("Expected ErrCaptchaDetected without a CaptchaSolver, but got %v", err)
+	}
+
+	c.CaptchaSolver = func(image []byte) (string, error) { return "x7k2", nil }
+	browser := &fakeBrowser{waitErrs: map[string]error{
+		c.Selectors.ResultLink: fmt.Errorf("error - failed to wait for element: %w", goSpider.ErrTimeout),
+	}}
+	_, err = c.Lawsuit(browser, "10001133420185020386")
+	if !errors.Is(err, goSpider.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout when the lawsuit isn't found, but got %v", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	extractor, err := courts.ExtractorFor("5001234-54.2023.8.07.0001")
+	if err != nil {
+		t.Fatalf("ExtractorFor error: %v", err)
+	}
+	if c, ok := extractor.(*Crawler); !ok || c.Tribunal != "TJDFT" {
+		t.Errorf("Expected the number to be dispatched to TJDFT, got %+v", extractor)
+	}
+}
@@ -0,0 +1,107 @@
+package courts
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"github.com/DanielFillol/goSpider/cnj"
+	"sort"
+	"sync"
+)
+
+// ErrNoExtractor is returned by Dispatch when no extractor is registered for the tribunal of a number.
+var ErrNoExtractor = errors.New("courts: no extractor registered for tribunal")
+
+// Extractor crawls a lawsuit on a court system and returns it in the shared schema.
+type Extractor interface {
+	Lawsuit(nav goSpider.Browser, number string) (Lawsuit, error)
+}
+
+// ExtractorFunc adapts a function to the Extractor interface.
+type ExtractorFunc func(nav goSpider.Browser, number string) (Lawsuit, error)
+
+// Lawsuit calls f(nav, number).
+func (f ExtractorFunc) Lawsuit(nav goSpider.Browser, number string) (Lawsuit, error) {
+	return f(nav, number)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Extractor)
+)
+
+// Register makes an extractor available for a tribunal. The code is either the tribunal acronym
+// returned by cnj.Number.Tribunal ("TJSP", "TRF4"), the raw J.TR segments ("8.26", "4.04"), which take precedence,
+// or the J.TR segments followed by a prefix of the origin ("4.04.71" for the federal courts of Rio Grande do Sul),
+// which takes precedence over both, the longest prefix first.
+// Court packages call Register from their init function, so importing them is enough to enable dispatching:
+//
+//	import _ "github.com/DanielFillol/goSpider/courts/pje"
+//
+// Register panics if the extractor is nil or if the code is registered twice.
+func Register(code string, extractor Extractor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if extractor == nil {
+		panic("courts: Register extractor is nil")
+	}
+	if _, dup := registry[code]; dup {
+		panic("courts: Register called twice for tribunal " + code)
+	}
+	registry[code] = extractor
+}
+
+// Lookup returns the extractor registered for a tribunal code.
+func Lookup(code string) (Extractor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[code]
+	return e, ok
+}
+
+// Tribunals returns a sorted list of the registered tribunal codes.
+func Tribunals() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	codes := make([]string, 0, len(registry))
+	for code := range registry {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ExtractorFor returns the extractor that handles a CNJ number, based on its J.TR and origin segments.
+// Example:
+//
+//	extractor, err := courts.ExtractorFor("1017927-35.2023.8.26.0008")
+func ExtractorFor(number string) (Extractor, error) {
+	n, err := cnj.Parse(number)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(n.Origin); i > 0; i-- {
+		if e, ok := Lookup(n.Segment + "." + n.Court + "." + n.Origin[:i]); ok {
+			return e, nil
+		}
+	}
+	if e, ok := Lookup(n.Segment + "." + n.Court); ok {
+		return e, nil
+	}
+	if e, ok := Lookup(n.Tribunal()); ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("%w: %s.%s (%s)", ErrNoExtractor, n.Segment, n.Court, n.Tribunal())
+}
+
+// Dispatch routes a CNJ number to the extractor of its tribunal and returns the extracted lawsuit.
+// Example:
+//
+//	lawsuit, err := courts.Dispatch(nav, "1017927-35.2023.8.26.0008")
+func Dispatch(nav goSpider.Browser, number string) (Lawsuit, error) {
+	e, err := ExtractorFor(number)
+	if err != nil {
+		return Lawsuit{}, err
+	}
+	return e.Lawsuit(nav, number)
+}
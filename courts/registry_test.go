@@ -0,0 +1,57 @@
+package courts
+
+import (
+	"errors"
+	"github.com/DanielFillol/goSpider"
+	"testing"
+)
+
+func TestDispatch(t *testing.T) {
+	Register("TJSP", ExtractorFunc(func(nav goSpider.Browser, number string) (Lawsuit, error) {
+		return Lawsuit{Number: number, Tribunal: "TJSP"}, nil
+	}))
+	Register("5.02", ExtractorFunc(func(nav goSpider.Browser, number string) (Lawsuit, error) {
+		return Lawsuit{Number: number, Tribunal: "TRT2"}, nil
+	}))
+
+	lawsuit, err := Dispatch(nil, "1017927-35.2023.8.26.0008")
+	if err != nil {
+		t.Fatalf("Dispatch error: %v", err)
+	}
+	if lawsuit.Tribunal != "TJSP" {
+		t.Errorf("Expected tribunal TJSP, but got: %s", lawsuit.Tribunal)
+	}
+
+	lawsuit, err = Dispatch(nil, "1000113-34.2018.5.02.0386")
+	if err != nil {
+		t.Fatalf("Dispatch error: %v", err)
+	}
+	if lawsuit.Tribunal != "TRT2" {
+		t.Errorf("Expected tribunal TRT2, but got: %s", lawsuit.Tribunal)
+	}
+
+	_, err = Dispatch(nil, "0002396-75.2013.8.26.0201")
+	if err != nil {
+		t.Errorf("Dispatch error: %v", err)
+	}
+
+	_, err = ExtractorFor("5001234-17.2023.4.04.7100")
+	if !errors.Is(err, ErrNoExtractor) {
+		t.Errorf("Expected ErrNoExtractor, but got: %v", err)
+	}
+
+	Register("TRF3", ExtractorFunc(func(nav goSpider.Browser, number string) (Lawsuit, error) {
+		return Lawsuit{Number: number, Tribunal: "TRF3"}, nil
+	}))
+	Register("4.03.61", ExtractorFunc(func(nav goSpider.Browser, number string) (Lawsuit, error) {
+		return Lawsuit{Number: number, Tribunal: "JFSP"}, nil
+	}))
+	lawsuit, err = Dispatch(nil, "5001234-37.2023.4.03.6100")
+	if err != nil || lawsuit.Tribunal != "JFSP" {
+		t.Errorf("Expected the first instance to be dispatched by origin, but got: %s, %v", lawsuit.Tribunal, err)
+	}
+	lawsuit, err = Dispatch(nil, "5001234-04.2023.4.03.0000")
+	if err != nil || lawsuit.Tribunal != "TRF3" {
+		t.Errorf("Expected the second instance to be dispatched to the tribunal, but got: %s, %v", lawsuit.Tribunal, err)
+	}
+}
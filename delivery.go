@@ -0,0 +1,426 @@
+package goSpider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery uploads the result files of a crawl, such as CSV, JSONL or PDF files, to a destination.
+type Delivery interface {
+	Deliver(files ...string) error
+}
+
+// DeliveryFunc adapts a function to the Delivery interface.
+type DeliveryFunc func(files ...string) error
+
+// Deliver calls f(files...).
+func (f DeliveryFunc) Deliver(files ...string) error {
+	return f(files...)
+}
+
+// DeliverAfter wraps a crawl so that, when it succeeds, the files matching the glob patterns are delivered. Patterns
+// are matched after the crawl, so they can pick up files named by run, e.g. "output/lawsuits-*.csv".
+// Example:
+//
+//	delivery := goSpider.NewFTPDelivery("ftp.partner.com:21", "user", os.Getenv("FTP_PASSWORD"), "/incoming")
+//	err = scheduler.Register("tjsp", schedule, goSpider.DeliverAfter(crawl, delivery, "output/*.csv"))
+func DeliverAfter(crawl CrawlFunc, delivery Delivery, patterns ...string) CrawlFunc {
+	return func(ctx context.Context, pool *NavigatorPool) error {
+		err := crawl(ctx, pool)
+		if err != nil {
+			return err
+		}
+		var files []string
+		for _, pattern := range patterns {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return fmt.Errorf("error - invalid delivery pattern %s: %w", pattern, err)
+			}
+			files = append(files, matches...)
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		return delivery.Deliver(files...)
+	}
+}
+
+// FTPDelivery is a Delivery that uploads files to a directory of an FTP server, with explicit TLS (FTPS) when TLS is
+// set. Each file is uploaded under a ".part" name and renamed when complete, so the partner never picks up a partial
+// file; existing files of the same name are replaced.
+type FTPDelivery struct {
+	Address   string // "host:port", port 21 if missing
+	Username  string
+	Password  string
+	Dir       string // the remote directory, created if missing
+	TLS       bool
+	TLSConfig *tls.Config
+	Timeout   time.Duration // timeout of connecting and of each command
+}
+
+// ftpPassivePort matches the port of an EPSV reply, e.g. "229 Entering Extended Passive Mode (|||6446|)".
+var ftpPassivePort = regexp.MustCompile(`\(\|\|\|(\d+)\|\)`)
+
+// ftpPassiveAddress matches the address of a PASV reply, e.g. "227 Entering Passive Mode (192,168,1,2,25,46)".
+var ftpPassiveAddress = regexp.MustCompile(`(\d+),(\d+),(\d+),(\d+),(\d+),(\d+)`)
+
+// NewFTPDelivery creates an FTPDelivery uploading to dir on the server at address.
+// Example:
+//
+//	delivery := goSpider.NewFTPDelivery("ftp.partner.com:21", "user", os.Getenv("FTP_PASSWORD"), "/incoming")
+//	delivery.TLS = true
+//	err := delivery.Deliver("output/lawsuits.csv", "output/lawsuits.jsonl")
+func NewFTPDelivery(address, username, password, dir string) *FTPDelivery {
+	return &FTPDelivery{
+		Address:  address,
+		Username: username,
+		Password: password,
+		Dir:      dir,
+		Timeout:  30 * time.Second,
+	}
+}
+
+// ftpConn is an FTP control connection.
+type ftpConn struct {
+	delivery *FTPDelivery
+	host     string
+	conn     net.Conn
+	text     *textproto.Conn
+	config   *tls.Config // not nil when the connection is encrypted
+}
+
+// Deliver uploads the files, over a single connection.
+func (d *FTPDelivery) Deliver(files ...string) error {
+	c, err := d.connect()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	if d.Dir != "" {
+		err = c.changeDir(d.Dir)
+		if err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		err = c.upload(file)
+		if err != nil {
+			return fmt.Errorf("error - failed to deliver %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// connect opens the control connection and logs in.
+func (d *FTPDelivery) connect() (*ftpConn, error) {
+	address := d.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "21")
+	}
+	host, _, _ := net.SplitHostPort(address)
+	conn, err := net.DialTimeout("tcp", address, d.timeout())
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to connect to FTP server: %w", err)
+	}
+	c := &ftpConn{delivery: d, host: host, conn: conn, text: textproto.NewConn(conn)}
+	_, err = c.read(2)
+	if err != nil {
+		c.close()
+		return nil, fmt.Errorf("error - FTP server refused the connection: %w", err)
+	}
+
+	if d.TLS {
+		_, err = c.cmd(2, "AUTH TLS")
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("error - FTP server doesn't support TLS: %w", err)
+		}
+		config := d.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: host}
+		}
+		config = config.Clone()
+		if config.ClientSessionCache == nil {
+			// servers commonly require data connections to resume the session of the control connection
+			config.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+		}
+		c.conn = tls.Client(conn, config)
+		c.text = textproto.NewConn(c.conn)
+		c.config = config
+		for _, command := range []string{"PBSZ 0", "PROT P"} {
+			_, err = c.cmd(2, command)
+			if err != nil {
+				c.close()
+				return nil, err
+			}
+		}
+	}
+
+	username := d.Username
+	if username == "" {
+		username = "anonymous"
+	}
+	code, err := c.cmd(0, "USER %s", username)
+	if err == nil && code == 331 {
+		_, err = c.cmd(2, "PASS %s", d.Password)
+	} else if err == nil && code/100 != 2 {
+		err = fmt.Errorf("unexpected reply %d", code)
+	}
+	if err != nil {
+		c.close()
+		return nil, fmt.Errorf("error - FTP login failed: %w", err)
+	}
+	_, err = c.cmd(2, "TYPE I")
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// changeDir changes to the directory, creating its missing parts.
+func (c *ftpConn) changeDir(dir string) error {
+	if _, err := c.cmd(2, "CWD %s", dir); err == nil {
+		return nil
+	}
+	current := ""
+	if strings.HasPrefix(dir, "/") {
+		current = "/"
+	}
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		current = path.Join(current, part)
+		c.cmd(0, "MKD %s", current)
+	}
+	_, err := c.cmd(2, "CWD %s", dir)
+	if err != nil {
+		return fmt.Errorf("error - failed to change to FTP directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// upload stores the file under a temporary name and renames it.
+func (c *ftpConn) upload(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := filepath.Base(file)
+	data, err := c.passive()
+	if err != nil {
+		return err
+	}
+	_, err = c.cmd(1, "STOR %s.part", name)
+	if err != nil {
+		data.Close()
+		return err
+	}
+	_, err = io.Copy(data, f)
+	closeErr := data.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	_, err = c.read(2)
+	if err != nil {
+		return err
+	}
+
+	c.cmd(0, "DELE %s", name)
+	_, err = c.cmd(3, "RNFR %s.part", name)
+	if err != nil {
+		return err
+	}
+	_, err = c.cmd(2, "RNTO %s", name)
+	return err
+}
+
+// passive opens a data connection in extended passive mode, falling back to passive mode. The data connection always
+// goes to the host of the control connection, as servers behind NAT often reply with their private address.
+func (c *ftpConn) passive() (net.Conn, error) {
+	var port int
+	message, err := c.cmdMessage(2, "EPSV")
+	if match := ftpPassivePort.FindStringSubmatch(message); err == nil && match != nil {
+		port, _ = strconv.Atoi(match[1])
+	} else {
+		message, err = c.cmdMessage(2, "PASV")
+		match := ftpPassiveAddress.FindStringSubmatch(message)
+		if err != nil || match == nil {
+			return nil, fmt.Errorf("error - FTP server refused passive mode: %w", err)
+		}
+		high, _ := strconv.Atoi(match[5])
+		low, _ := strconv.Atoi(match[6])
+		port = high*256 + low
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(c.host, strconv.Itoa(port)), c.delivery.timeout())
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to open FTP data connection: %w", err)
+	}
+	if c.config != nil {
+		return tls.Client(conn, c.config), nil
+	}
+	return conn, nil
+}
+
+// cmd sends a command and reads its reply, which must start with the digit expect unless it is 0.
+func (c *ftpConn) cmd(expect int, format string, args ...interface{}) (int, error) {
+	c.setDeadline()
+	_, err := c.text.Cmd(format, args...)
+	if err != nil {
+		return 0, err
+	}
+	code, _, err := c.text.ReadResponse(expect)
+	return code, err
+}
+
+// cmdMessage sends a command and returns the message of its reply.
+func (c *ftpConn) cmdMessage(expect int, format string, args ...interface{}) (string, error) {
+	c.setDeadline()
+	_, err := c.text.Cmd(format, args...)
+	if err != nil {
+		return "", err
+	}
+	_, message, err := c.text.ReadResponse(expect)
+	return message, err
+}
+
+// read reads a reply, which must start with the digit expect.
+func (c *ftpConn) read(expect int) (int, error) {
+	c.setDeadline()
+	code, _, err := c.text.ReadResponse(expect)
+	return code, err
+}
+
+func (c *ftpConn) setDeadline() {
+	c.conn.SetDeadline(time.Now().Add(c.delivery.timeout()))
+}
+
+func (c *ftpConn) close() {
+	c.setDeadline()
+	c.text.Cmd("QUIT")
+	c.conn.Close()
+}
+
+func (d *FTPDelivery) timeout() time.Duration {
+	if d.Timeout <= 0 {
+		return 30 * time.Second
+	}
+	return d.Timeout
+}
+
+// SFTPDelivery is a Delivery that uploads files to a directory of an SSH server with the OpenSSH sftp command, which
+// must be installed, authenticating with a private key file. Like FTPDelivery, files are uploaded under a ".part" name
+// and renamed when complete. The host key must be in the known hosts file.
+type SFTPDelivery struct {
+	Address      string // "host:port", port 22 if missing
+	Username     string
+	IdentityFile string   // the private key file, the default keys of the user if empty
+	Dir          string   // the remote directory, created if missing
+	Path         string   // the sftp executable, "sftp" by default
+	Options      []string // extra ssh options, e.g. "StrictHostKeyChecking=accept-new"
+	Timeout      time.Duration
+}
+
+// NewSFTPDelivery creates an SFTPDelivery uploading to dir on the server at address.
+// Example:
+//
+//	delivery := goSpider.NewSFTPDelivery("sftp.partner.com:22", "crawler", "/etc/goSpider/id_ed25519", "/incoming")
+//	err := delivery.Deliver("output/lawsuits.csv")
+func NewSFTPDelivery(address, username, identityFile, dir string) *SFTPDelivery {
+	return &SFTPDelivery{
+		Address:      address,
+		Username:     username,
+		IdentityFile: identityFile,
+		Dir:          dir,
+		Path:         "sftp",
+		Timeout:      10 * time.Minute,
+	}
+}
+
+// Deliver uploads the files in a single sftp session.
+func (d *SFTPDelivery) Deliver(files ...string) error {
+	host, port, err := net.SplitHostPort(d.Address)
+	if err != nil {
+		host, port = d.Address, "22"
+	}
+	args := []string{"-b", "-", "-P", port, "-o", "BatchMode=yes"}
+	if d.IdentityFile != "" {
+		args = append(args, "-i", d.IdentityFile)
+	}
+	for _, option := range d.Options {
+		args = append(args, "-o", option)
+	}
+	destination := host
+	if d.Username != "" {
+		destination = d.Username + "@" + host
+	}
+	args = append(args, destination)
+
+	// commands starting with "-" may fail without aborting the batch
+	var batch strings.Builder
+	if d.Dir != "" {
+		current := ""
+		if strings.HasPrefix(d.Dir, "/") {
+			current = "/"
+		}
+		for _, part := range strings.Split(strings.Trim(d.Dir, "/"), "/") {
+			current = path.Join(current, part)
+			fmt.Fprintf(&batch, "-mkdir %s\n", sftpQuote(current))
+		}
+		fmt.Fprintf(&batch, "cd %s\n", sftpQuote(d.Dir))
+	}
+	for _, file := range files {
+		absolute, err := filepath.Abs(file)
+		if err != nil {
+			return fmt.Errorf("error - failed to deliver %s: %w", file, err)
+		}
+		name := filepath.Base(file)
+		fmt.Fprintf(&batch, "put %s %s\n", sftpQuote(absolute), sftpQuote(name+".part"))
+		fmt.Fprintf(&batch, "-rm %s\n", sftpQuote(name))
+		fmt.Fprintf(&batch, "rename %s %s\n", sftpQuote(name+".part"), sftpQuote(name))
+	}
+
+	ctx := context.Background()
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+	executable := d.Path
+	if executable == "" {
+		executable = "sftp"
+	}
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Stdin = strings.NewReader(batch.String())
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("error - sftp failed: %w: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// sftpQuote quotes an argument of an sftp batch command.
+func sftpQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,222 @@
+package goSpider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// startTestFTPServer starts an FTP server accepting user:secret, storing uploads in files by path and recording the
+// commands.
+func startTestFTPServer(t *testing.T, files map[string]string, commands *[]string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	var mu sync.Mutex
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+				dir, renameFrom := "/", ""
+				dirs := map[string]bool{"/": true}
+				var data net.Listener
+				reply("220 ready")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+					mu.Lock()
+					*commands = append(*commands, strings.TrimSpace(line))
+					mu.Unlock()
+					resolve := func(name string) string {
+						if strings.HasPrefix(name, "/") {
+							return name
+						}
+						return strings.TrimSuffix(dir, "/") + "/" + name
+					}
+					switch command {
+					case "USER":
+						reply("331 password required")
+					case "PASS":
+						if arg != "secret" {
+							reply("530 login incorrect")
+						} else {
+							reply("230 logged in")
+						}
+					case "TYPE":
+						reply("200 binary")
+					case "MKD":
+						dirs[resolve(arg)] = true
+						reply("257 created")
+					case "CWD":
+						if !dirs[resolve(arg)] {
+							reply("550 no such directory")
+						} else {
+							dir = resolve(arg)
+							reply("250 ok")
+						}
+					case "EPSV":
+						data, _ = net.Listen("tcp", "127.0.0.1:0")
+						reply("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+					case "STOR":
+						reply("150 opening data connection")
+						dataConn, err := data.Accept()
+						data.Close()
+						if err != nil {
+							return
+						}
+						content, _ := io.ReadAll(dataConn)
+						dataConn.Close()
+						mu.Lock()
+						files[resolve(arg)] = string(content)
+						mu.Unlock()
+						reply("226 transfer complete")
+					case "DELE":
+						reply("550 not found")
+					case "RNFR":
+						renameFrom = resolve(arg)
+						reply("350 ready")
+					case "RNTO":
+						mu.Lock()
+						files[resolve(arg)] = files[renameFrom]
+						delete(files, renameFrom)
+						mu.Unlock()
+						reply("250 renamed")
+					case "QUIT":
+						reply("221 bye")
+						return
+					default:
+						reply("502 not implemented")
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestFTPDelivery(t *testing.T) {
+	files := map[string]string{}
+	var commands []string
+	address := startTestFTPServer(t, files, &commands)
+
+	dir := t.TempDir()
+	csv := filepath.Join(dir, "lawsuits.csv")
+	jsonl := filepath.Join(dir, "lawsuits.jsonl")
+	os.WriteFile(csv, []byte("number\n1\n"), 0644)
+	os.WriteFile(jsonl, []byte(`{"number":"1"}`+"\n"), 0644)
+
+	delivery := NewFTPDelivery(address, "user", "secret", "/incoming/tjsp")
+	err := delivery.Deliver(csv, jsonl)
+	if err != nil {
+		t.Fatalf("Deliver error: %v", err)
+	}
+	want := map[string]string{
+		"/incoming/tjsp/lawsuits.csv":   "number\n1\n",
+		"/incoming/tjsp/lawsuits.jsonl": `{"number":"1"}` + "\n",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected the files in the remote directory, got %v", files)
+	}
+	stored := 0
+	for _, command := range commands {
+		if strings.HasPrefix(command, "STOR ") {
+			stored++
+			if !strings.HasSuffix(command, ".part") {
+				t.Errorf("Expected uploads under a temporary name, got %s", command)
+			}
+		}
+	}
+	if stored != 2 {
+		t.Errorf("Expected two uploads, got %v", commands)
+	}
+
+	err = NewFTPDelivery(address, "user", "wrong", "").Deliver(csv)
+	if err == nil || !strings.Contains(err.Error(), "login") {
+		t.Errorf("Expected a login error, got %v", err)
+	}
+}
+
+func TestSFTPDelivery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "sftp")
+	os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > \""+dir+"/args\"\ncat > \""+dir+"/batch\"\n"), 0755)
+	csv := filepath.Join(dir, "lawsuits.csv")
+	os.WriteFile(csv, []byte("number\n"), 0644)
+
+	delivery := NewSFTPDelivery("sftp.example.com:2222", "crawler", "/keys/id_ed25519", "/incoming")
+	delivery.Path = script
+	err := delivery.Deliver(csv)
+	if err != nil {
+		t.Fatalf("Deliver error: %v", err)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if strings.TrimSpace(string(args)) != "-b - -P 2222 -o BatchMode=yes -i /keys/id_ed25519 crawler@sftp.example.com" {
+		t.Errorf("Unexpected sftp arguments: %s", args)
+	}
+	batch, _ := os.ReadFile(filepath.Join(dir, "batch"))
+	want := `-mkdir "/incoming"
+cd "/incoming"
+put "` + csv + `" "lawsuits.csv.part"
+-rm "lawsuits.csv"
+rename "lawsuits.csv.part" "lawsuits.csv"
+`
+	if string(batch) != want {
+		t.Errorf("Expected batch\n%s\ngot\n%s", want, batch)
+	}
+
+	delivery.Path = filepath.Join(dir, "missing")
+	if err := delivery.Deliver(csv); err == nil {
+		t.Errorf("Expected an error without the sftp executable")
+	}
+}
+
+func TestDeliverAfter(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.csv"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "b.csv"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "c.log"), nil, 0644)
+
+	var delivered []string
+	delivery := DeliveryFunc(func(files ...string) error {
+		delivered = append(delivered, files...)
+		return nil
+	})
+	crawlErr := errors.New("crawl failed")
+	failing := DeliverAfter(func(ctx context.Context, pool *NavigatorPool) error { return crawlErr }, delivery,
+		filepath.Join(dir, "*.csv"))
+	if err := failing(context.Background(), nil); err != crawlErr || len(delivered) != 0 {
+		t.Errorf("Expected no delivery after a failed crawl, got %v: %v", delivered, err)
+	}
+
+	crawl := DeliverAfter(func(ctx context.Context, pool *NavigatorPool) error { return nil }, delivery,
+		filepath.Join(dir, "*.csv"))
+	err := crawl(context.Background(), nil)
+	want := []string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")}
+	if err != nil || !reflect.DeepEqual(delivered, want) {
+		t.Errorf("Expected %v to be delivered, got %v: %v", want, delivered, err)
+	}
+}
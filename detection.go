@@ -0,0 +1,254 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// DetectionCheck is the result of a fingerprinting signal checked by RunDetectionChecks.
+type DetectionCheck struct {
+	Name   string
+	Value  string // the value the page observed
+	Passed bool   // whether the value looks like a regular browser
+	Weight int    // the points of the check in the score
+	Advice string // how to fix a failed check
+}
+
+// DetectionReport is the result of RunDetectionChecks. Score is the weight of the passed checks, from 0 to 100: the
+// higher, the more the browser looks like a regular one.
+type DetectionReport struct {
+	Checks []DetectionCheck
+	Score  int
+}
+
+// Failed returns the checks that failed.
+func (r DetectionReport) Failed() []DetectionCheck {
+	var failed []DetectionCheck
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// String formats the report with a line per check and the score.
+func (r DetectionReport) String() string {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		status := "OK  "
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%s %s: %s", status, check.Name, check.Value)
+		if !check.Passed && check.Advice != "" {
+			fmt.Fprintf(&b, " (%s)", check.Advice)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Score: %d/100\n", r.Score)
+	return b.String()
+}
+
+// detectionSignals are the fingerprinting signals collected by detectionPage.
+type detectionSignals struct {
+	Webdriver     bool     `json:"webdriver"`
+	UserAgent     string   `json:"userAgent"`
+	Platform      string   `json:"platform"`
+	Plugins       int      `json:"plugins"`
+	Languages     []string `json:"languages"`
+	Chrome        bool     `json:"chrome"`
+	OuterWidth    int      `json:"outerWidth"`
+	OuterHeight   int      `json:"outerHeight"`
+	WebGLVendor   string   `json:"webglVendor"`
+	WebGLRenderer string   `json:"webglRenderer"`
+	CanvasHash    string   `json:"canvasHash"`
+}
+
+// detectionPage collects the signals that bot detection scripts commonly look at into window.detectionSignals.
+const detectionPage = `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"><title>Detection checks</title></head>
+<body>
+<canvas id="canvas" width="220" height="40"></canvas>
+<script>
+	var signals = {
+		webdriver: navigator.webdriver === true,
+		userAgent: navigator.userAgent,
+		platform: navigator.platform,
+		plugins: navigator.plugins ? navigator.plugins.length : 0,
+		languages: navigator.languages ? Array.prototype.slice.call(navigator.languages) : [],
+		chrome: typeof window.chrome === "object" && window.chrome !== null,
+		outerWidth: window.outerWidth,
+		outerHeight: window.outerHeight,
+		webglVendor: "",
+		webglRenderer: "",
+		canvasHash: ""
+	};
+	try {
+		var gl = document.createElement("canvas").getContext("webgl");
+		var info = gl && gl.getExtension("WEBGL_debug_renderer_info");
+		if (info) {
+			signals.webglVendor = gl.getParameter(info.UNMASKED_VENDOR_WEBGL);
+			signals.webglRenderer = gl.getParameter(info.UNMASKED_RENDERER_WEBGL);
+		}
+	} catch (e) {}
+	try {
+		var canvas = document.getElementById("canvas");
+		var context = canvas.getContext("2d");
+		context.textBaseline = "top";
+		context.font = "14px Arial";
+		context.fillStyle = "#f60";
+		context.fillRect(100, 1, 62, 20);
+		context.fillStyle = "#069";
+		context.fillText("goSpider fingerprint", 2, 15);
+		var data = canvas.toDataURL();
+		var blank = document.createElement("canvas");
+		blank.width = canvas.width;
+		blank.height = canvas.height;
+		if (data !== blank.toDataURL()) {
+			// FNV-1a hash of the image
+			var hash = 0x811c9dc5;
+			for (var i = 0; i < data.length; i++) {
+				hash ^= data.charCodeAt(i);
+				hash = Math.imul(hash, 0x01000193) >>> 0;
+			}
+			signals.canvasHash = ("0000000" + hash.toString(16)).slice(-8);
+		}
+	} catch (e) {}
+	window.detectionSignals = signals;
+</script>
+</body>
+</html>`
+
+// RunDetectionChecks opens a local checks page that evaluates the fingerprinting signals bot detection scripts look at,
+// such as the webdriver flag, a headless user agent, the plugin count and the canvas and WebGL rendering, and returns a
+// scored report, so a stealth configuration can be verified before it is used on a protected site. The current page is
+// left for the checks page.
+// Example:
+//
+//	report, err := nav.RunDetectionChecks()
+//	if err == nil && report.Score < 80 {
+//		log.Print(report)
+//	}
+func (nav *Navigator) RunDetectionChecks() (DetectionReport, error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, detectionPage)
+	}))
+	defer server.Close()
+
+	err := nav.OpenURL(server.URL)
+	if err != nil {
+		return DetectionReport{}, err
+	}
+	var signals detectionSignals
+	err = chromedp.Run(nav.Ctx, chromedp.Evaluate(`window.detectionSignals`, &signals))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to run detection checks: %v\n", err)
+		return DetectionReport{}, fmt.Errorf("error - failed to run detection checks: %w", classifyError(err))
+	}
+
+	report := scoreDetection(signals)
+	nav.Logger.Printf("Detection checks score: %d/100\n", report.Score)
+	return report, nil
+}
+
+// scoreDetection checks the signals and scores them.
+func scoreDetection(signals detectionSignals) DetectionReport {
+	uaPlatform := userAgentPlatform(signals.UserAgent)
+	renderer := strings.ToLower(signals.WebGLRenderer)
+	softwareRenderer := strings.Contains(renderer, "swiftshader") || strings.Contains(renderer, "llvmpipe") ||
+		strings.Contains(renderer, "software")
+
+	checks := []DetectionCheck{
+		{
+			Name:   "webdriver flag",
+			Value:  fmt.Sprint(signals.Webdriver),
+			Passed: !signals.Webdriver,
+			Weight: 30,
+			Advice: `launch Chrome with chromedp.Flag("disable-blink-features", "AutomationControlled") and without enable-automation`,
+		},
+		{
+			Name:   "headless user agent",
+			Value:  signals.UserAgent,
+			Passed: !strings.Contains(signals.UserAgent, "Headless"),
+			Weight: 25,
+			Advice: "set a regular user agent with chromedp.UserAgent",
+		},
+		{
+			Name:   "user agent platform",
+			Value:  signals.Platform,
+			Passed: uaPlatform == "" || strings.HasPrefix(strings.ToLower(signals.Platform), uaPlatform),
+			Weight: 10,
+			Advice: "use a user agent of the operating system the browser runs on",
+		},
+		{
+			Name:   "plugins",
+			Value:  fmt.Sprint(signals.Plugins),
+			Passed: signals.Plugins > 0,
+			Weight: 10,
+			Advice: "run Chrome in the new headless mode, or headful, which expose the PDF viewer plugins",
+		},
+		{
+			Name:   "languages",
+			Value:  strings.Join(signals.Languages, ","),
+			Passed: len(signals.Languages) > 0,
+			Weight: 5,
+			Advice: `set the language with chromedp.Flag("lang", "pt-BR")`,
+		},
+		{
+			Name:   "window.chrome",
+			Value:  fmt.Sprint(signals.Chrome),
+			Passed: signals.Chrome,
+			Weight: 5,
+			Advice: "run Chrome in the new headless mode, or headful",
+		},
+		{
+			Name:   "window size",
+			Value:  fmt.Sprintf("%dx%d", signals.OuterWidth, signals.OuterHeight),
+			Passed: signals.OuterWidth > 0 && signals.OuterHeight > 0,
+			Weight: 5,
+			Advice: "set the window size with chromedp.WindowSize",
+		},
+		{
+			Name:   "WebGL renderer",
+			Value:  strings.TrimSpace(signals.WebGLVendor + " " + signals.WebGLRenderer),
+			Passed: signals.WebGLRenderer != "" && !softwareRenderer,
+			Weight: 5,
+			Advice: "run Chrome with a GPU, without chromedp.DisableGPU",
+		},
+		{
+			Name:   "canvas hash",
+			Value:  signals.CanvasHash,
+			Passed: signals.CanvasHash != "",
+			Weight: 5,
+			Advice: "don't block canvas rendering, an empty canvas is a rare fingerprint",
+		},
+	}
+
+	report := DetectionReport{Checks: checks}
+	for _, check := range checks {
+		if check.Passed {
+			report.Score += check.Weight
+		}
+	}
+	return report
+}
+
+// userAgentPlatform returns the navigator.platform prefix of the operating system of the user agent, in lower case,
+// or "" when unknown.
+func userAgentPlatform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "Windows"):
+		return "win"
+	case strings.Contains(userAgent, "Macintosh"):
+		return "mac"
+	case strings.Contains(userAgent, "Linux"), strings.Contains(userAgent, "Android"):
+		return "linux"
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package goSpider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreDetection(t *testing.T) {
+	regular := detectionSignals{
+		UserAgent:     DefaultUserAgent,
+		Platform:      "Win32",
+		Plugins:       5,
+		Languages:     []string{"pt-BR", "pt"},
+		Chrome:        true,
+		OuterWidth:    1280,
+		OuterHeight:   800,
+		WebGLVendor:   "Google Inc. (NVIDIA)",
+		WebGLRenderer: "ANGLE (NVIDIA GeForce GTX 1060)",
+		CanvasHash:    "1a2b3c4d",
+	}
+	report := scoreDetection(regular)
+	if report.Score != 100 || len(report.Failed()) != 0 {
+		t.Errorf("Expected a regular browser to score 100, got %d:\n%s", report.Score, report)
+	}
+
+	headless := detectionSignals{
+		Webdriver:     true,
+		UserAgent:     "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36",
+		Platform:      "Linux x86_64",
+		WebGLRenderer: "Google SwiftShader",
+		CanvasHash:    "1a2b3c4d",
+	}
+	report = scoreDetection(headless)
+	if report.Score != 15 {
+		t.Errorf("Expected a headless browser to score 15, got %d:\n%s", report.Score, report)
+	}
+	text := report.String()
+	if !strings.Contains(text, "FAIL webdriver flag: true (") || !strings.Contains(text, "OK   canvas hash: 1a2b3c4d\n") ||
+		!strings.HasSuffix(text, "Score: 15/100\n") {
+		t.Errorf("Unexpected report:\n%s", text)
+	}
+
+	// a Windows user agent on Linux is inconsistent
+	mismatch := regular
+	mismatch.Platform = "Linux x86_64"
+	if report = scoreDetection(mismatch); report.Score != 90 {
+		t.Errorf("Expected the platform mismatch to fail, got %d:\n%s", report.Score, report)
+	}
+}
+
+func TestRunDetectionChecks(t *testing.T) {
+	nav := setupNavigator(t)
+	report, err := nav.RunDetectionChecks()
+	if err != nil {
+		t.Fatalf("RunDetectionChecks error: %v", err)
+	}
+	if len(report.Checks) != 9 || report.Score <= 0 {
+		t.Errorf("Unexpected report:\n%s", report)
+	}
+	for _, check := range report.Checks {
+		if check.Name == "headless user agent" && !check.Passed {
+			t.Errorf("Expected the user agent of headless Navigators to pass: %s", check.Value)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithHostResolverRules pins hostnames to IPs in the browser, e.g. to reach a staging environment under its production
+// name or to bypass split-horizon DNS. Wildcards such as "*.example.com" are accepted.
+// Example:
+//
+//	nav := goSpider.NewNavigator("", true, goSpider.WithHostResolverRules(map[string]string{
+//		"esaj.tjsp.jus.br": "10.0.0.12",
+//	}))
+func WithHostResolverRules(hosts map[string]string) NavigatorOption {
+	return func(config *navigatorConfig) {
+		config.allocatorOptions = append(config.allocatorOptions, chromedp.Flag("host-resolver-rules", hostResolverRules(hosts)))
+	}
+}
+
+// hostResolverRules formats hosts as the value of Chrome's --host-resolver-rules flag, sorted by host.
+func hostResolverRules(hosts map[string]string) string {
+	rules := make([]string, 0, len(hosts))
+	for host, ip := range hosts {
+		rules = append(rules, fmt.Sprintf("MAP %s %s", host, ip))
+	}
+	sort.Strings(rules)
+	return strings.Join(rules, ", ")
+}
+
+// HostDialer dials connections for HTTP clients, resolving pinned hostnames to fixed IPs and caching the DNS lookups of
+// the others, so thousands of requests to the same hosts don't repeat them. The TLS server name is still the hostname.
+type HostDialer struct {
+	Hosts  map[string]string                                        // hostname to IP overrides
+	TTL    time.Duration                                            // how long lookups are cached; zero or negative disables caching
+	Lookup func(ctx context.Context, host string) ([]string, error) // net.DefaultResolver.LookupHost by default
+	Dialer *net.Dialer
+
+	mu    sync.Mutex
+	cache map[string]dnsEntry
+}
+
+// dnsEntry is a cached lookup.
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// NewHostDialer creates a HostDialer with the hostname overrides and lookups cached for ttl.
+// Example:
+//
+//	dialer := goSpider.NewHostDialer(map[string]string{"esaj.tjsp.jus.br": "10.0.0.12"}, 5*time.Minute)
+//	fetcher := goSpider.NewHTTPFetcher(30 * time.Second)
+//	fetcher.UseDialer(dialer)
+func NewHostDialer(hosts map[string]string, ttl time.Duration) *HostDialer {
+	return &HostDialer{
+		Hosts:  hosts,
+		TTL:    ttl,
+		Lookup: net.DefaultResolver.LookupHost,
+		Dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		cache:  map[string]dnsEntry{},
+	}
+}
+
+// DialContext connects to addr, trying each resolved address of its host in order. It has the signature of
+// http.Transport.DialContext.
+func (d *HostDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := d.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range addrs {
+		conn, err := d.Dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// LookupHost returns the pinned IP of host, its cached addresses or the addresses of a fresh lookup.
+func (d *HostDialer) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if ip, ok := d.Hosts[host]; ok {
+		return []string{ip}, nil
+	}
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	d.mu.Lock()
+	entry, ok := d.cache[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := d.Lookup(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to resolve host %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("error - no addresses for host %s", host)
+	}
+	if d.TTL > 0 {
+		d.mu.Lock()
+		d.cache[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(d.TTL)}
+		d.mu.Unlock()
+	}
+	return addrs, nil
+}
+
+// UseDialer makes the fetcher connect through dialer, keeping the other settings of the default transport.
+func (f *HTTPFetcher) UseDialer(dialer *HostDialer) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	f.Client.Transport = transport
+}
@@ -0,0 +1,56 @@
+package goSpider
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHostResolverRules(t *testing.T) {
+	rules := hostResolverRules(map[string]string{"www.example.com": "10.0.0.2", "api.example.com": "10.0.0.1"})
+	if rules != "MAP api.example.com 10.0.0.1, MAP www.example.com 10.0.0.2" {
+		t.Errorf("Unexpected rules: %s", rules)
+	}
+}
+
+func TestHostDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1 id="title">` + r.Host + `</h1></body></html>`))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	lookups := 0
+	dialer := NewHostDialer(map[string]string{"staging.example.test": "127.0.0.1"}, time.Minute)
+	dialer.Lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"127.0.0.1"}, nil
+	}
+	fetcher := NewHTTPFetcher(5 * time.Second)
+	fetcher.UseDialer(dialer)
+
+	pageSource, err := fetcher.Fetch("http://staging.example.test:" + port + "/")
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	title, err := FindNodes(pageSource, "//h1[@id='title']")
+	if err != nil || title[0].FirstChild.Data != "staging.example.test:"+port {
+		t.Errorf("Expected the request to keep the pinned hostname")
+	}
+	if lookups != 0 {
+		t.Errorf("Expected pinned hosts not to be looked up, got %d lookups", lookups)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = fetcher.Fetch("http://cached.example.test:" + port + "/")
+		if err != nil {
+			t.Fatalf("Fetch error: %v", err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("Expected 1 cached lookup, got %d", lookups)
+	}
+}
@@ -0,0 +1,316 @@
+package goSpider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DocumentFile is a manifest entry for a document saved by DownloadDocuments.
+// Error is filled instead of aborting when a single document fails.
+type DocumentFile struct {
+	Index    int
+	Title    string
+	URL      string
+	FileName string
+	Path     string
+	Size     int64
+	SHA256   string
+	Error    string
+}
+
+// DownloadDocuments clicks every document link matched by linkSelector and saves the files into dir with
+// deterministic names ("001-peticao-inicial.pdf"), returning a manifest with one entry per link.
+// Links may trigger a download, open the document in a new tab or navigate the current tab to a PDF viewer;
+// tabs are closed and navigations reverted after the file is saved.
+//
+// Parameters:
+//   - linkSelector: the css selector of the document links
+//   - frameSelector: the css selector of the iframe holding the links; an empty string uses the main document
+//   - dir: the directory where the files are saved
+//   - timeout: the maximum time to wait for each document, the download timeout of nav if not positive
+//
+// Example:
+//
+//	manifest, err := nav.DownloadDocuments("a.document", "#documentsFrame", "downloads/1017927-35.2023.8.26.0008", 0)
+func (nav *Navigator) DownloadDocuments(linkSelector, frameSelector, dir string, timeout time.Duration) ([]DocumentFile, error) {
+	if timeout <= 0 {
+		timeout = nav.GetTimeouts().Download
+	}
+
+	err := nav.EnableDownloads(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := nav.documentLinks(linkSelector, frameSelector)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		nav.Logger.Printf("Error - No document links found: %s\n", linkSelector)
+		return nil, fmt.Errorf("error - no document links found: %s", linkSelector)
+	}
+
+	manifest := make([]DocumentFile, 0, len(links))
+	for i := range links {
+		document := DocumentFile{Index: i + 1}
+		err = nav.downloadDocument(&document, linkSelector, frameSelector, dir, timeout)
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to download document %d: %v\n", document.Index, err)
+			document.Error = err.Error()
+		}
+		manifest = append(manifest, document)
+	}
+
+	nav.Logger.Printf("Downloaded %d documents into %s\n", len(manifest), dir)
+	return manifest, nil
+}
+
+// documentLinks queries the document links, inside frameSelector when it is not empty.
+// The links are queried again for every document because navigating back invalidates the node ids.
+func (nav *Navigator) documentLinks(linkSelector, frameSelector string) ([]*cdp.Node, error) {
+	options := []chromedp.QueryOption{chromedp.ByQueryAll}
+	if frameSelector != "" {
+		var frames []*cdp.Node
+		err := chromedp.Run(nav.Ctx, withTimeout(nav.Timeout,
+			chromedp.Nodes(nav.firstMatch(frameSelector, nav.Timeout), &frames, chromedp.ByQuery)))
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to find frame: %v\n", err)
+			return nil, fmt.Errorf("error - failed to find frame: %w", classifyError(err))
+		}
+		options = append(options, chromedp.FromNode(frames[0]))
+	}
+
+	// alternatives of a selector chain are tried in order, inside the frame when there is one
+	var links []*cdp.Node
+	var err error
+	for _, alternative := range SplitSelectors(linkSelector) {
+		err = chromedp.Run(nav.Ctx, withTimeout(nav.Timeout, chromedp.Nodes(alternative, &links, options...)))
+		if err == nil && len(links) > 0 {
+			return links, nil
+		}
+	}
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to find document links: %v\n", err)
+		return nil, fmt.Errorf("error - failed to find document links: %w", classifyError(err))
+	}
+	return links, nil
+}
+
+// downloadDocument clicks the document link at document.Index and saves whatever it opens.
+func (nav *Navigator) downloadDocument(document *DocumentFile, linkSelector, frameSelector, dir string, timeout time.Duration) error {
+	links, err := nav.documentLinks(linkSelector, frameSelector)
+	if err != nil {
+		return err
+	}
+	if document.Index > len(links) {
+		return fmt.Errorf("error - document link %d is no longer on the page", document.Index)
+	}
+	link := links[document.Index-1]
+
+	err = nav.callOnNode(link.NodeID, `function() { return (this.innerText || this.textContent || this.title || "").trim(); }`, &document.Title)
+	if err != nil {
+		return err
+	}
+
+	previousURL, err := nav.GetCurrentURL()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(nav.Ctx, timeout)
+	defer cancel()
+
+	downloads := nav.watchDownloads(ctx, dir)
+	var tabURL string
+	tabs := chromedp.WaitNewTarget(ctx, func(info *target.Info) bool {
+		if info.URL == "" || info.URL == "about:blank" {
+			return false
+		}
+		tabURL = info.URL
+		return true
+	})
+
+	err = nav.callOnNode(link.NodeID, `function() { this.click(); }`, nil)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case started := <-downloads.begin:
+			download, err := downloads.complete(ctx, started)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(download.Path)
+			if err != nil {
+				return fmt.Errorf("error - failed to read downloaded file: %w", classifyError(err))
+			}
+			err = os.Remove(download.Path)
+			if err != nil {
+				return fmt.Errorf("error - failed to remove downloaded file: %w", classifyError(err))
+			}
+			document.URL = download.URL
+			return saveDocument(document, dir, data, documentExtension(download.SuggestedFilename, download.URL, ""))
+
+		case id, ok := <-tabs:
+			if !ok {
+				continue
+			}
+			document.URL = tabURL
+			err = chromedp.Run(nav.Ctx, target.CloseTarget(id))
+			if err != nil {
+				nav.Logger.Printf("Error - Failed to close document tab: %v\n", err)
+			}
+			data, contentType, err := nav.FetchFile(tabURL)
+			if err != nil {
+				return err
+			}
+			return saveDocument(document, dir, data, documentExtension("", tabURL, contentType))
+
+		case <-ticker.C:
+			currentURL, err := nav.GetCurrentURL()
+			if err != nil || currentURL == previousURL {
+				continue
+			}
+			document.URL = currentURL
+			data, contentType, err := nav.FetchFile(currentURL)
+			if err != nil {
+				return err
+			}
+			err = chromedp.Run(nav.Ctx, chromedp.NavigateBack(), chromedp.WaitReady("body"))
+			if err != nil {
+				return fmt.Errorf("error - failed to navigate back from document: %w", classifyError(err))
+			}
+			return saveDocument(document, dir, data, documentExtension("", currentURL, contentType))
+
+		case <-ctx.Done():
+			return fmt.Errorf("error - document link opened no download, tab or page: %w", classifyError(ctx.Err()))
+		}
+	}
+}
+
+// callOnNode calls the javascript function with the node as this, with a user gesture so popups are allowed.
+// The returned value is unmarshalled into res when it is not nil.
+func (nav *Navigator) callOnNode(nodeID cdp.NodeID, function string, res interface{}) error {
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		object, err := dom.ResolveNode().WithNodeID(nodeID).Do(ctx)
+		if err != nil {
+			return err
+		}
+		result, exception, err := runtime.CallFunctionOn(function).
+			WithObjectID(object.ObjectID).
+			WithReturnByValue(true).
+			WithUserGesture(true).
+			Do(ctx)
+		if err != nil {
+			return err
+		}
+		if exception != nil {
+			return exception
+		}
+		if res == nil || result.Value == nil {
+			return nil
+		}
+		return json.Unmarshal(result.Value, res)
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to call function on element: %v\n", err)
+		return fmt.Errorf("error - failed to call function on element: %w", classifyError(err))
+	}
+	return nil
+}
+
+// saveDocument writes data to its deterministic file name and fills the manifest entry.
+func saveDocument(document *DocumentFile, dir string, data []byte, extension string) error {
+	document.FileName = documentFileName(document.Index, document.Title, extension)
+	document.Path = filepath.Join(dir, document.FileName)
+
+	err := os.WriteFile(document.Path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to save document: %w", classifyError(err))
+	}
+
+	sum := sha256.Sum256(data)
+	document.SHA256 = hex.EncodeToString(sum[:])
+	document.Size = int64(len(data))
+	return nil
+}
+
+// documentFileName builds "<index>-<slug of title><extension>", e.g. "001-peticao-inicial.pdf".
+func documentFileName(index int, title, extension string) string {
+	var slug []rune
+	for _, r := range strings.ToLower(stripAccents(title)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			slug = append(slug, r)
+		case len(slug) > 0 && slug[len(slug)-1] != '-':
+			slug = append(slug, '-')
+		}
+	}
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+
+	name := strings.Trim(string(slug), "-")
+	if name == "" {
+		name = "document"
+	}
+	return fmt.Sprintf("%03d-%s%s", index, name, extension)
+}
+
+// stripAccents replaces the accented letters common in Portuguese documents with their plain versions.
+func stripAccents(s string) string {
+	return strings.NewReplacer(
+		"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+		"é", "e", "è", "e", "ê", "e", "ë", "e",
+		"í", "i", "ì", "i", "î", "i", "ï", "i",
+		"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+		"ú", "u", "ù", "u", "û", "u", "ü", "u",
+		"ç", "c", "ñ", "n",
+		"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ä", "A",
+		"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+		"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+		"Ó", "O", "Ò", "O", "Ô", "O", "Õ", "O", "Ö", "O",
+		"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+		"Ç", "C", "Ñ", "N",
+	).Replace(s)
+}
+
+// documentExtension picks the file extension from the suggested file name, the URL path or the content type, in that order.
+func documentExtension(suggestedFilename, rawURL, contentType string) string {
+	if extension := filepath.Ext(suggestedFilename); extension != "" {
+		return strings.ToLower(extension)
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		if extension := path.Ext(u.Path); extension != "" {
+			return strings.ToLower(extension)
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if extensions, err := mime.ExtensionsByType(mediaType); err == nil && len(extensions) > 0 {
+			return extensions[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package goSpider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDocumentFileName(t *testing.T) {
+	tests := []struct {
+		index     int
+		title     string
+		extension string
+		expected  string
+	}{
+		{1, "Petição Inicial", ".pdf", "001-peticao-inicial.pdf"},
+		{12, "  Decisão / Despacho (fls. 10)  ", ".pdf", "012-decisao-despacho-fls-10.pdf"},
+		{3, "", ".txt", "003-document.txt"},
+		{4, "***", "", "004-document"},
+	}
+
+	for _, test := range tests {
+		name := documentFileName(test.index, test.title, test.extension)
+		if name != test.expected {
+			t.Errorf("documentFileName(%d, %q, %q) = %s, expected %s", test.index, test.title, test.extension, name, test.expected)
+		}
+	}
+}
+
+func TestDocumentExtension(t *testing.T) {
+	tests := []struct {
+		suggestedFilename string
+		url               string
+		contentType       string
+		expected          string
+	}{
+		{"Decision.PDF", "https://www.example.com/download?id=1", "", ".pdf"},
+		{"", "https://www.example.com/files/decision.pdf?x=1", "text/html", ".pdf"},
+		{"", "https://www.example.com/download?id=1", "application/pdf", ".pdf"},
+		{"", "https://www.example.com/download?id=1", "", ""},
+	}
+
+	for _, test := range tests {
+		extension := documentExtension(test.suggestedFilename, test.url, test.contentType)
+		if extension != test.expected {
+			t.Errorf("documentExtension(%q, %q, %q) = %s, expected %s", test.suggestedFilename, test.url, test.contentType, extension, test.expected)
+		}
+	}
+}
+
+func TestDownloadDocuments(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/documents.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	dir := t.TempDir()
+	manifest, err := nav.DownloadDocuments("a.document", "", dir, 10*time.Second)
+	if err != nil {
+		t.Fatalf("DownloadDocuments error: %v", err)
+	}
+
+	expected := []string{"001-peticao-inicial.txt", "002-decisao.pdf", "003-peticao-inicial-copia.txt"}
+	if len(manifest) != len(expected) {
+		t.Fatalf("Expected %d documents, but got %d: %+v", len(expected), len(manifest), manifest)
+	}
+	for i, document := range manifest {
+		if document.Error != "" {
+			t.Errorf("Document %d failed: %s", document.Index, document.Error)
+			continue
+		}
+		if document.FileName != expected[i] {
+			t.Errorf("Expected file name %s, but got %s", expected[i], document.FileName)
+		}
+		info, err := os.Stat(filepath.Join(dir, document.FileName))
+		if err != nil {
+			t.Errorf("Stat error: %v", err)
+			continue
+		}
+		if info.Size() != document.Size || document.SHA256 == "" {
+			t.Errorf("Unexpected manifest entry: %+v", document)
+		}
+	}
+}
+
+func TestDownloadDocumentsInFrame(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/documents.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	manifest, err := nav.DownloadDocuments("a.frameDocument", "#documentsFrame", t.TempDir(), 10*time.Second)
+	if err != nil {
+		t.Fatalf("DownloadDocuments error: %v", err)
+	}
+
+	if len(manifest) != 1 || manifest[0].Error != "" || manifest[0].FileName != "001-sentenca.pdf" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+}
@@ -0,0 +1,337 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultDownloadTimeout is the time downloads wait when neither they nor the Navigator set a timeout, see Timeouts.
+const DefaultDownloadTimeout = 30 * time.Second
+
+// Download describes a file saved by the browser.
+type Download struct {
+	URL               string
+	SuggestedFilename string
+	Path              string
+	Size              int64
+}
+
+// EnableDownloads allows the browser to save downloads into dir, naming each file by its download GUID.
+// Example:
+//
+//	err := nav.EnableDownloads("downloads")
+func (nav *Navigator) EnableDownloads(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error - failed to resolve download directory: %w", classifyError(err))
+	}
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error - failed to create download directory: %w", classifyError(err))
+	}
+
+	err = chromedp.Run(nav.Ctx,
+		browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllowAndName).
+			WithDownloadPath(dir).
+			WithEventsEnabled(true),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to enable downloads: %v\n", err)
+		return fmt.Errorf("error - failed to enable downloads: %w", classifyError(err))
+	}
+	return nil
+}
+
+// WaitForDownload runs trigger and waits up to timeout, or the download timeout of nav if not positive, for the
+// download it starts to complete in dir.
+// The file is saved under its download GUID; Download.Path points to it.
+// Example:
+//
+//	download, err := nav.WaitForDownload("downloads", 30*time.Second, func() error {
+//		return nav.ClickButton("#downloadButton")
+//	})
+func (nav *Navigator) WaitForDownload(dir string, timeout time.Duration, trigger func() error) (Download, error) {
+	if timeout <= 0 {
+		timeout = nav.GetTimeouts().Download
+	}
+	err := nav.EnableDownloads(dir)
+	if err != nil {
+		return Download{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(nav.Ctx, timeout)
+	defer cancel()
+	watcher := nav.watchDownloads(ctx, dir)
+
+	err = trigger()
+	if err != nil {
+		return Download{}, err
+	}
+
+	select {
+	case started := <-watcher.begin:
+		return watcher.complete(ctx, started)
+	case <-ctx.Done():
+		nav.Logger.Printf("Error - Download did not start: %v\n", ctx.Err())
+		return Download{}, fmt.Errorf("error - download did not start: %w", classifyError(ctx.Err()))
+	}
+}
+
+// downloadWatcher collects the download events of the Navigator tab while its context is alive.
+type downloadWatcher struct {
+	nav      *Navigator
+	dir      string
+	begin    chan *browser.EventDownloadWillBegin
+	progress chan *browser.EventDownloadProgress
+}
+
+// watchDownloads starts listening for download events until ctx is done.
+func (nav *Navigator) watchDownloads(ctx context.Context, dir string) *downloadWatcher {
+	dir, _ = filepath.Abs(dir)
+	watcher := &downloadWatcher{
+		nav:      nav,
+		dir:      dir,
+		begin:    make(chan *browser.EventDownloadWillBegin, 1),
+		progress: make(chan *browser.EventDownloadProgress, 16),
+	}
+
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *browser.EventDownloadWillBegin:
+			select {
+			case watcher.begin <- ev:
+			default:
+			}
+		case *browser.EventDownloadProgress:
+			if ev.State != browser.DownloadProgressStateInProgress {
+				select {
+				case watcher.progress <- ev:
+				default:
+				}
+			}
+		}
+	})
+	return watcher
+}
+
+// complete waits for the started download to finish and returns the saved file.
+func (w *downloadWatcher) complete(ctx context.Context, started *browser.EventDownloadWillBegin) (Download, error) {
+	for {
+		select {
+		case ev := <-w.progress:
+			if ev.GUID != started.GUID {
+				continue
+			}
+			if ev.State == browser.DownloadProgressStateCanceled {
+				w.nav.Logger.Printf("Error - Download canceled: %s\n", started.URL)
+				return Download{}, fmt.Errorf("error - download canceled: %s", started.URL)
+			}
+
+			download := Download{
+				URL:               started.URL,
+				SuggestedFilename: started.SuggestedFilename,
+				Path:              filepath.Join(w.dir, started.GUID),
+			}
+			info, err := os.Stat(download.Path)
+			if err != nil {
+				return Download{}, fmt.Errorf("error - failed to stat downloaded file: %w", classifyError(err))
+			}
+			download.Size = info.Size()
+
+			w.nav.Logger.Printf("Download completed: %s (%d bytes)\n", download.URL, download.Size)
+			return download, nil
+		case <-ctx.Done():
+			w.nav.Logger.Printf("Error - Download did not complete: %v\n", ctx.Err())
+			return Download{}, fmt.Errorf("error - download did not complete: %w", classifyError(ctx.Err()))
+		}
+	}
+}
+
+// FetchFile downloads url from inside the current page, reusing its cookies and session, and returns
+// the content with its content type. It is useful for files shown in PDF viewers or opened in new tabs.
+// Example:
+//
+//	data, contentType, err := nav.FetchFile("https://www.example.com/document.pdf")
+func (nav *Navigator) FetchFile(url string) ([]byte, string, error) {
+	var dataURL string
+	err := chromedp.Run(nav.Ctx, withTimeout(nav.GetTimeouts().Download,
+		chromedp.Evaluate(fmt.Sprintf(`fetch(%s, {credentials: "include"})
+			.then(function(response) {
+				if (!response.ok) throw new Error("status " + response.status);
+				return response.blob();
+			})
+			.then(function(blob) {
+				return new Promise(function(resolve, reject) {
+					var reader = new FileReader();
+					reader.onload = function() { resolve(reader.result); };
+					reader.onerror = function() { reject(reader.error); };
+					reader.readAsDataURL(blob);
+				});
+			})`, jsString(url)), &dataURL, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
+	))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to fetch file: %v\n", err)
+		return nil, "", fmt.Errorf("error - failed to fetch file: %w", classifyError(err))
+	}
+
+	return decodeDataURL(dataURL)
+}
+
+// decodeDataURL splits a base64 data URL into its content and content type.
+func decodeDataURL(dataURL string) ([]byte, string, error) {
+	header, payload, found := strings.Cut(dataURL, ",")
+	if !found || !strings.HasPrefix(header, "data:") || !strings.HasSuffix(header, ";base64") {
+		return nil, "", fmt.Errorf("error - invalid data URL")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("error - failed to decode data URL: %w", classifyError(err))
+	}
+
+	contentType := strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+	return data, contentType, nil
+}
+
+// GeneratedFile is a file generated by a page, see CaptureGeneratedFile.
+type GeneratedFile struct {
+	URL         string // the blob:, data: or http URL of the file
+	Filename    string // the download attribute of the link, if any
+	ContentType string
+	Data        []byte
+}
+
+// captureScript hooks the ways a page saves a file it generates: links with a download attribute or to a blob: or
+// data: URL, clicked by the user or by the page even when detached from the document, as FileSaver.js does, and
+// window.open of blob: and data: URLs. The downloads are cancelled and their content is read as a data URL into
+// window.__goSpiderCapture.files, before the page can revoke the blob URLs. Running it again clears the files.
+const captureScript = `(function() {
+	if (window.__goSpiderCapture) {
+		window.__goSpiderCapture.files = [];
+		return;
+	}
+	var capture = window.__goSpiderCapture = {files: [], blobs: {}};
+	var createObjectURL = URL.createObjectURL;
+	URL.createObjectURL = function(object) {
+		var url = createObjectURL.apply(this, arguments);
+		if (object instanceof Blob) capture.blobs[url] = object;
+		return url;
+	};
+	function read(href) {
+		var source = capture.blobs[href] ? Promise.resolve(capture.blobs[href]) :
+			fetch(href, {credentials: "include"}).then(function(response) {
+				if (!response.ok) throw new Error("status " + response.status);
+				return response.blob();
+			});
+		return source.then(function(blob) {
+			return new Promise(function(resolve, reject) {
+				var reader = new FileReader();
+				reader.onload = function() { resolve(reader.result); };
+				reader.onerror = function() { reject(reader.error); };
+				reader.readAsDataURL(blob);
+			});
+		});
+	}
+	function captured(a) {
+		var href = a.href || "";
+		if (!href || !(a.hasAttribute("download") || /^(blob|data):/.test(href))) return false;
+		var file = {url: href, name: a.getAttribute("download") || "", data: "", error: ""};
+		capture.files.push(file);
+		read(href).then(function(data) { file.data = data; }, function(e) { file.error = String(e); });
+		return true;
+	}
+	document.addEventListener("click", function(e) {
+		var a = e.target.closest && e.target.closest("a");
+		if (a && captured(a)) e.preventDefault();
+	}, true);
+	var dispatchEvent = EventTarget.prototype.dispatchEvent;
+	EventTarget.prototype.dispatchEvent = function(event) {
+		if (this instanceof HTMLAnchorElement && event.type === "click" && !this.isConnected && captured(this)) return false;
+		return dispatchEvent.apply(this, arguments);
+	};
+	var click = HTMLAnchorElement.prototype.click;
+	HTMLAnchorElement.prototype.click = function() {
+		if (!this.isConnected && captured(this)) return;
+		return click.apply(this, arguments);
+	};
+	var open = window.open;
+	window.open = function(url) {
+		if (typeof url === "string" && /^(blob|data):/.test(url)) {
+			var a = document.createElement("a");
+			a.href = url;
+			captured(a);
+			return null;
+		}
+		return open.apply(this, arguments);
+	};
+})()`
+
+// CaptureGeneratedFile runs trigger and waits up to timeout, or the download timeout of nav if not positive, for the
+// current page to save a file it generates, such as the CSV of an "export" button in a single page application, and
+// returns its content instead of downloading it. It captures links with a download attribute, blob: URLs and data:
+// URLs, which WaitForDownload can't see. The page must already be open when it is called.
+// Example:
+//
+//	file, err := nav.CaptureGeneratedFile(10*time.Second, func() error {
+//		return nav.ClickButton("#exportCsv")
+//	})
+//	err = os.WriteFile(file.Filename, file.Data, 0644)
+func (nav *Navigator) CaptureGeneratedFile(timeout time.Duration, trigger func() error) (GeneratedFile, error) {
+	if timeout <= 0 {
+		timeout = nav.GetTimeouts().Download
+	}
+	err := chromedp.Run(nav.Ctx, chromedp.Evaluate(captureScript, nil))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to capture generated file: %v\n", err)
+		return GeneratedFile{}, fmt.Errorf("error - failed to capture generated file: %w", classifyError(err))
+	}
+	err = trigger()
+	if err != nil {
+		return GeneratedFile{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(nav.Ctx, timeout)
+	defer cancel()
+	for {
+		var files []struct {
+			URL   string `json:"url"`
+			Name  string `json:"name"`
+			Data  string `json:"data"`
+			Error string `json:"error"`
+		}
+		err = chromedp.Run(ctx, chromedp.Evaluate(`window.__goSpiderCapture ? window.__goSpiderCapture.files : []`, &files))
+		if err == nil && len(files) > 0 && (files[0].Data != "" || files[0].Error != "") {
+			file := files[0]
+			if file.Error != "" {
+				nav.Logger.Printf("Error - Failed to read generated file %s: %s\n", file.URL, file.Error)
+				return GeneratedFile{}, fmt.Errorf("error - failed to read generated file %s: %s", file.URL, file.Error)
+			}
+			data, contentType, err := decodeDataURL(file.Data)
+			if err != nil {
+				return GeneratedFile{}, err
+			}
+			nav.Logger.Printf("Generated file captured: %s (%d bytes)\n", file.URL, len(data))
+			return GeneratedFile{URL: file.URL, Filename: file.Name, ContentType: contentType, Data: data}, nil
+		}
+		if err == nil {
+			err = chromedp.Run(ctx, chromedp.Sleep(100*time.Millisecond))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			nav.Logger.Printf("Error - No generated file captured: %v\n", err)
+			return GeneratedFile{}, fmt.Errorf("error - no generated file captured: %w", classifyError(err))
+		}
+	}
+}
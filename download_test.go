@@ -0,0 +1,93 @@
+package goSpider
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeDataURL(t *testing.T) {
+	data, contentType, err := decodeDataURL("data:application/pdf;base64,JVBERi0xLjQ=")
+	if err != nil {
+		t.Fatalf("decodeDataURL error: %v", err)
+	}
+	if string(data) != "%PDF-1.4" {
+		t.Errorf("Expected %%PDF-1.4, but got %q", data)
+	}
+	if contentType != "application/pdf" {
+		t.Errorf("Expected application/pdf, but got %s", contentType)
+	}
+
+	_, _, err = decodeDataURL("not a data url")
+	if err == nil {
+		t.Errorf("Expected error for an invalid data URL")
+	}
+}
+
+func TestWaitForDownload(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/documents.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	dir := t.TempDir()
+	download, err := nav.WaitForDownload(dir, 10*time.Second, func() error {
+		return nav.ClickButton("#documents > li:nth-child(1) > a")
+	})
+	if err != nil {
+		t.Fatalf("WaitForDownload error: %v", err)
+	}
+
+	if download.SuggestedFilename != "initial.txt" {
+		t.Errorf("Expected initial.txt, but got %s", download.SuggestedFilename)
+	}
+	data, err := os.ReadFile(download.Path)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if string(data) != "Initial petition text\n" {
+		t.Errorf("Unexpected download content: %q", data)
+	}
+}
+
+func TestCaptureGeneratedFile(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/export.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	file, err := nav.CaptureGeneratedFile(5*time.Second, func() error {
+		return nav.ClickButton("#exportCsv")
+	})
+	if err != nil {
+		t.Fatalf("CaptureGeneratedFile error: %v", err)
+	}
+	if file.Filename != "lawsuits.csv" || file.ContentType != "text/csv" || !strings.HasPrefix(file.URL, "blob:") ||
+		!strings.HasPrefix(string(file.Data), "number,class\n") {
+		t.Errorf("Unexpected file %s %s %s: %q", file.URL, file.Filename, file.ContentType, file.Data)
+	}
+
+	file, err = nav.CaptureGeneratedFile(5*time.Second, func() error {
+		return nav.ClickButton("#dataLink")
+	})
+	if err != nil || file.Filename != "hello.txt" || string(file.Data) != "Hello" {
+		t.Errorf("Expected the data link to be captured, but got %+v: %v", file, err)
+	}
+
+	_, err = nav.CaptureGeneratedFile(500*time.Millisecond, func() error {
+		return nav.ClickButton("#nothing")
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a timeout without a file, but got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"strings"
+)
+
+// dropdownOptions is the XPath of the options of custom dropdown widgets: the ARIA options rendered by React Select and
+// most component libraries, the results of Select2 and of Chosen.
+const dropdownOptions = `//*[@role="option" or contains(concat(" ", normalize-space(@class), " "), " select2-results__option ") or contains(concat(" ", normalize-space(@class), " "), " active-result ")]`
+
+// jsTextInputFocused is a JavaScript expression reporting whether the focused element takes typed text.
+const jsTextInputFocused = `(function() {
+	var el = document.activeElement;
+	if (!el) return false;
+	if (el.isContentEditable) return true;
+	return el.tagName === "INPUT" && /^(text|search)$/.test(el.type);
+})()`
+
+// SelectCustomDropdown selects the option showing optionText in a dropdown widget, such as Select2, Chosen or React
+// Select, which SelectDropdown can't drive as they are not native select elements. It clicks trigger to open the widget,
+// types optionText into its search field when the widget focuses one, and clicks the visible option whose text is
+// optionText, or else contains it. Options are searched in the whole page, as these widgets render them in portals at
+// the end of the body.
+// Example:
+//
+//	err := nav.SelectCustomDropdown("#select2-comarca-container", "São Paulo")
+func (nav *Navigator) SelectCustomDropdown(trigger, optionText string) error {
+	nav.Logger.Printf("Selecting custom dropdown option %q with trigger: %s\n", optionText, trigger)
+	err := nav.ClickButton(trigger)
+	if err != nil {
+		return err
+	}
+
+	var typing bool
+	err = chromedp.Run(nav.Ctx, chromedp.Evaluate(jsTextInputFocused, &typing))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to find dropdown search field: %v\n", err)
+		return fmt.Errorf("error - failed to find dropdown search field: %w", classifyError(err))
+	}
+	if typing {
+		err = chromedp.Run(nav.Ctx, chromedp.KeyEvent(optionText))
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to type dropdown search: %v\n", err)
+			return fmt.Errorf("error - failed to type dropdown search: %w", classifyError(err))
+		}
+	}
+
+	option, err := nav.dropdownOption(optionText)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to find dropdown option: %v\n", err)
+		return fmt.Errorf("error - failed to find dropdown option: %w", err)
+	}
+	err = nav.ClickButton(option)
+	if err != nil {
+		return err
+	}
+	nav.Logger.Println("Custom dropdown option selected successfully")
+	return nil
+}
+
+// dropdownOption waits up to nav.Timeout for a visible option of a dropdown widget showing text, and returns its
+// absolute XPath, preferring the options whose text is text to the ones containing it.
+func (nav *Navigator) dropdownOption(text string) (string, error) {
+	literal := xpathLiteral(strings.Join(strings.Fields(text), " "))
+	exact := fmt.Sprintf("%s[normalize-space(.)=%s]", dropdownOptions, literal)
+	contains := fmt.Sprintf("%s[contains(normalize-space(.), %s)]", dropdownOptions, literal)
+
+	_, err := nav.waitForCondition(anyElementCondition([]string{exact, contains}), nav.Timeout)
+	if err != nil {
+		return "", classifyError(err)
+	}
+	for _, expression := range []string{exact, contains} {
+		elements, err := nav.queryElements(expression)
+		if err != nil {
+			return "", err
+		}
+		for _, element := range elements {
+			if element.Visible {
+				return element.Path, nil
+			}
+		}
+	}
+	return "", withKind(ErrElementNotFound, fmt.Errorf("no visible option %q", text))
+}
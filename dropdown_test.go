@@ -0,0 +1,42 @@
+package goSpider
+
+import (
+	"testing"
+)
+
+func TestSelectCustomDropdown(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/dropdown.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	// typed into the search field, the exact option is preferred to the longer one
+	err = nav.SelectCustomDropdown("#comarca", "São Paulo")
+	if err != nil {
+		t.Fatalf("SelectCustomDropdown error: %v", err)
+	}
+	// without a search field, the option containing the text is clicked
+	err = nav.SelectCustomDropdown("#vara", "2ª Vara")
+	if err != nil {
+		t.Fatalf("SelectCustomDropdown error: %v", err)
+	}
+
+	for selector, expected := range map[string]string{"#comarca": "São Paulo", "#vara": "2ª Vara Cível"} {
+		text, err := nav.GetElement(selector)
+		if err != nil {
+			t.Fatalf("GetElement error: %v", err)
+		}
+		if text != expected {
+			t.Errorf("Expected %s to show %q, but got %q", selector, expected, text)
+		}
+	}
+
+	err = nav.SelectCustomDropdown("#vara", "Vara Criminal")
+	if err == nil {
+		t.Errorf("Expected an error for a missing option")
+	}
+}
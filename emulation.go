@@ -0,0 +1,169 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/device"
+	"sort"
+	"strings"
+)
+
+// CSS media types emulated by EmulateMedia.
+const (
+	MediaScreen = "screen"
+	MediaPrint  = "print"
+)
+
+// Color schemes emulated by EmulateColorScheme.
+const (
+	ColorSchemeLight = "light"
+	ColorSchemeDark  = "dark"
+)
+
+// EmulateMedia renders the page with the stylesheets of a CSS media type, MediaPrint or MediaScreen, e.g. to capture
+// the print-only layout of a page before a screenshot or a PDF. An empty media type stops the emulation.
+// Example:
+//
+//	err := nav.EmulateMedia(goSpider.MediaPrint)
+//	err = nav.RunCDP(chromedp.ActionFunc(func(ctx context.Context) (err error) {
+//		pdf, _, err = page.PrintToPDF().Do(ctx)
+//		return err
+//	}))
+func (nav *Navigator) EmulateMedia(media string) error {
+	switch media {
+	case MediaScreen, MediaPrint, "":
+	default:
+		return fmt.Errorf("error - invalid media type %q", media)
+	}
+
+	err := nav.emulateMedia(media, nav.colorScheme)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to emulate media: %v\n", err)
+		return fmt.Errorf("error - failed to emulate media: %w", classifyError(err))
+	}
+	nav.media = media
+	nav.Logger.Printf("Emulating media %q\n", media)
+	return nil
+}
+
+// EmulateColorScheme renders the page with a preferred color scheme, ColorSchemeDark or ColorSchemeLight, for content
+// that depends on dark mode. An empty color scheme stops the emulation.
+// Example:
+//
+//	err := nav.EmulateColorScheme(goSpider.ColorSchemeDark)
+func (nav *Navigator) EmulateColorScheme(scheme string) error {
+	switch scheme {
+	case ColorSchemeLight, ColorSchemeDark, "":
+	default:
+		return fmt.Errorf("error - invalid color scheme %q", scheme)
+	}
+
+	err := nav.emulateMedia(nav.media, scheme)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to emulate color scheme: %v\n", err)
+		return fmt.Errorf("error - failed to emulate color scheme: %w", classifyError(err))
+	}
+	nav.colorScheme = scheme
+	nav.Logger.Printf("Emulating color scheme %q\n", scheme)
+	return nil
+}
+
+// emulateMedia sets both the media type and the color scheme, since each emulation replaces the previous one.
+func (nav *Navigator) emulateMedia(media, scheme string) error {
+	var features []*emulation.MediaFeature
+	if scheme != "" {
+		features = append(features, &emulation.MediaFeature{Name: "prefers-color-scheme", Value: scheme})
+	}
+	return chromedp.Run(nav.Ctx, emulation.SetEmulatedMedia().WithMedia(media).WithFeatures(features))
+}
+
+// Device is a device emulated by EmulateDevice.
+type Device struct {
+	Name      string
+	UserAgent string
+	Width     int64   // viewport width in CSS pixels
+	Height    int64   // viewport height in CSS pixels
+	Scale     float64 // device scale factor
+	Mobile    bool
+	Touch     bool
+}
+
+// Device returns the chromedp device info of d, so d can be used with chromedp.Emulate.
+func (d Device) Device() device.Info {
+	return device.Info{Name: d.Name, UserAgent: d.UserAgent, Width: d.Width, Height: d.Height, Scale: d.Scale,
+		Landscape: d.Width > d.Height, Mobile: d.Mobile, Touch: d.Touch}
+}
+
+// User agents of the devices of Devices.
+const (
+	iPhoneUserAgent  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+	iPadUserAgent    = "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+	androidUserAgent = "Mozilla/5.0 (Linux; Android 14; %s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36"
+)
+
+// Devices are the devices EmulateDevice knows by name. More can be added before emulating them.
+var Devices = map[string]Device{
+	"iPhone SE":         {Name: "iPhone SE", UserAgent: iPhoneUserAgent, Width: 375, Height: 667, Scale: 2, Mobile: true, Touch: true},
+	"iPhone 15":         {Name: "iPhone 15", UserAgent: iPhoneUserAgent, Width: 393, Height: 852, Scale: 3, Mobile: true, Touch: true},
+	"iPhone 15 Pro Max": {Name: "iPhone 15 Pro Max", UserAgent: iPhoneUserAgent, Width: 430, Height: 932, Scale: 3, Mobile: true, Touch: true},
+	"Pixel 7":           {Name: "Pixel 7", UserAgent: fmt.Sprintf(androidUserAgent, "Pixel 7"), Width: 412, Height: 915, Scale: 2.625, Mobile: true, Touch: true},
+	"Pixel 8":           {Name: "Pixel 8", UserAgent: fmt.Sprintf(androidUserAgent, "Pixel 8"), Width: 412, Height: 915, Scale: 2.625, Mobile: true, Touch: true},
+	"Galaxy S23":        {Name: "Galaxy S23", UserAgent: fmt.Sprintf(androidUserAgent, "SM-S911B"), Width: 360, Height: 780, Scale: 3, Mobile: true, Touch: true},
+	"iPad":              {Name: "iPad", UserAgent: iPadUserAgent, Width: 810, Height: 1080, Scale: 2, Mobile: true, Touch: true},
+	"iPad Mini":         {Name: "iPad Mini", UserAgent: iPadUserAgent, Width: 744, Height: 1133, Scale: 2, Mobile: true, Touch: true},
+	"iPad Pro 11":       {Name: "iPad Pro 11", UserAgent: iPadUserAgent, Width: 834, Height: 1194, Scale: 2, Mobile: true, Touch: true},
+}
+
+// EmulateDevice renders the pages as the device of Devices named name would, case insensitively, with its viewport,
+// scale factor, touch support and user agent, so mobile-only pages and layouts can be scraped. The suffix " landscape"
+// rotates the device, e.g. "iPad landscape". An empty name stops the emulation. The emulation applies to the pages
+// loaded afterwards, as sites pick their mobile version on load.
+// Example:
+//
+//	err := nav.EmulateDevice("iPhone 15")
+//	err = nav.OpenURL("https://m.example.com")
+func (nav *Navigator) EmulateDevice(name string) error {
+	action := chromedp.EmulateReset()
+	if name != "" {
+		d, err := lookupDevice(name)
+		if err != nil {
+			return err
+		}
+		action = chromedp.Emulate(d)
+	}
+
+	err := chromedp.Run(nav.Ctx, action)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to emulate device: %v\n", err)
+		return fmt.Errorf("error - failed to emulate device: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Emulating device %q\n", name)
+	return nil
+}
+
+// lookupDevice returns the device of Devices named name, case insensitively, rotated for the suffix " landscape".
+func lookupDevice(name string) (Device, error) {
+	base := strings.TrimSpace(name)
+	landscape := false
+	if lower := strings.ToLower(base); strings.HasSuffix(lower, " landscape") {
+		base = strings.TrimSpace(base[:len(base)-len(" landscape")])
+		landscape = true
+	}
+	for key, d := range Devices {
+		if !strings.EqualFold(key, base) {
+			continue
+		}
+		if landscape && d.Width < d.Height {
+			d.Width, d.Height = d.Height, d.Width
+		}
+		return d, nil
+	}
+
+	names := make([]string, 0, len(Devices))
+	for key := range Devices {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return Device{}, fmt.Errorf("error - unknown device %q, known devices: %s", name, strings.Join(names, ", "))
+}
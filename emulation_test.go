@@ -0,0 +1,104 @@
+package goSpider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmulateMedia(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/media.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	err = nav.EmulateMedia(MediaPrint)
+	if err != nil {
+		t.Fatalf("EmulateMedia error: %v", err)
+	}
+	display, err := nav.EvaluateScript(`getComputedStyle(document.getElementById("print")).display`)
+	if err != nil || display != "block" {
+		t.Errorf("Expected the print-only content to be shown, but got %v: %v", display, err)
+	}
+
+	err = nav.EmulateColorScheme(ColorSchemeDark)
+	if err != nil {
+		t.Fatalf("EmulateColorScheme error: %v", err)
+	}
+	background, err := nav.EvaluateScript(`getComputedStyle(document.body).backgroundColor`)
+	if err != nil || background != "rgb(0, 0, 0)" {
+		t.Errorf("Expected a dark background, but got %v: %v", background, err)
+	}
+	printing, err := nav.EvaluateScript(`matchMedia("print").matches`)
+	if err != nil || printing != true {
+		t.Errorf("Expected the print media to be kept with the color scheme, but got %v: %v", printing, err)
+	}
+
+	err = nav.EmulateMedia("")
+	if err != nil {
+		t.Fatalf("EmulateMedia error: %v", err)
+	}
+	display, err = nav.EvaluateScript(`getComputedStyle(document.getElementById("print")).display`)
+	if err != nil || display != "none" {
+		t.Errorf("Expected the print-only content to be hidden, but got %v: %v", display, err)
+	}
+}
+
+func TestEmulateMediaInvalid(t *testing.T) {
+	nav := &Navigator{}
+	if err := nav.EmulateMedia("tv"); err == nil {
+		t.Error("Expected an error for an unknown media type")
+	}
+	if err := nav.EmulateColorScheme("sepia"); err == nil {
+		t.Error("Expected an error for an unknown color scheme")
+	}
+}
+
+func TestEmulateDevice(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.EmulateDevice("iphone 15")
+	if err != nil {
+		t.Fatalf("EmulateDevice error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/media.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	state, err := nav.EvaluateScript(`[innerWidth, devicePixelRatio, navigator.maxTouchPoints > 0, /iPhone/.test(navigator.userAgent)].join(" ")`)
+	if err != nil || state != "393 3 true true" {
+		t.Errorf("Expected the viewport, scale, touch and user agent of the iPhone, got %v: %v", state, err)
+	}
+
+	err = nav.EmulateDevice("")
+	if err != nil {
+		t.Fatalf("EmulateDevice error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/media.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	mobile, err := nav.EvaluateScript(`/iPhone/.test(navigator.userAgent)`)
+	if err != nil || mobile != false {
+		t.Errorf("Expected the emulation to stop, got %v: %v", mobile, err)
+	}
+}
+
+func TestLookupDevice(t *testing.T) {
+	d, err := lookupDevice("Pixel 7")
+	if err != nil || d.Width != 412 || d.Height != 915 || !d.Mobile || !d.Touch {
+		t.Errorf("Expected the Pixel 7, got %+v: %v", d, err)
+	}
+	d, err = lookupDevice("ipad Landscape")
+	if err != nil || d.Width != 1080 || d.Height != 810 || !d.Device().Landscape {
+		t.Errorf("Expected the iPad rotated, got %+v: %v", d, err)
+	}
+	if _, err = lookupDevice("Nokia 3310"); err == nil || !strings.Contains(err.Error(), "iPhone 15") {
+		t.Errorf("Expected an error listing the known devices, got %v", err)
+	}
+}
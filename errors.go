@@ -0,0 +1,149 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+)
+
+// Errors returned by Navigator methods, wrapped with the details of the failure, so callers can branch with errors.Is
+// instead of matching error messages.
+// Example:
+//
+//	err := nav.ClickButton("#search")
+//	if errors.Is(err, goSpider.ErrElementNotFound) {
+//		// the layout changed
+//	} else if errors.Is(err, goSpider.ErrBrowserCrashed) {
+//		nav = goSpider.NewNavigator("", true)
+//	}
+var (
+	ErrElementNotFound  = errors.New("element not found")
+	ErrTimeout          = errors.New("timeout")
+	ErrNavigationFailed = errors.New("navigation failed")
+	ErrBrowserCrashed   = errors.New("browser crashed")
+	ErrLoginFailed      = errors.New("login failed")
+	ErrCaptchaDetected  = errors.New("captcha detected")
+	ErrRetriesExhausted = errors.New("retries exhausted")
+	ErrCircuitOpen      = errors.New("circuit open")
+	ErrNoProxies        = errors.New("no proxies")
+)
+
+// errorKinds are the sentinel errors set by classifyError.
+var errorKinds = []error{ErrElementNotFound, ErrTimeout, ErrNavigationFailed, ErrBrowserCrashed, ErrLoginFailed,
+	ErrCaptchaDetected}
+
+// kindError adds a sentinel error to the chain of an error, keeping its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind returns err with kind in its chain, or nil when err is nil.
+func withKind(kind, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// Messages of the Chrome DevTools Protocol and of chromedp by kind of failure.
+var (
+	notFoundMessages = []string{"could not find node", "no node with given id", "node not found", "no node found"}
+	crashMessages    = []string{"target crashed", "websocket: close", "use of closed network connection",
+		"broken pipe", "connection reset", "chrome failed to start", "browser closed"}
+)
+
+// classifyError adds to a chromedp or context error the sentinel error of its kind, if any. Errors that already have
+// a kind are returned as they are.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind) {
+			return err
+		}
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, chromedp.ErrPollingTimeout),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return withKind(ErrTimeout, err)
+	case errors.Is(err, chromedp.ErrNoResults):
+		return withKind(ErrElementNotFound, err)
+	case errors.Is(err, chromedp.ErrChannelClosed), errors.Is(err, chromedp.ErrInvalidContext),
+		errors.Is(err, chromedp.ErrInvalidTarget):
+		return withKind(ErrBrowserCrashed, err)
+	}
+	message := strings.ToLower(err.Error())
+	for _, marker := range notFoundMessages {
+		if strings.Contains(message, marker) {
+			return withKind(ErrElementNotFound, err)
+		}
+	}
+	for _, marker := range crashMessages {
+		if strings.Contains(message, marker) {
+			return withKind(ErrBrowserCrashed, err)
+		}
+	}
+	if strings.Contains(message, "net::err_") {
+		return withKind(ErrNavigationFailed, err)
+	}
+	return err
+}
+
+// captchaMarkers are found in the ids, classes, names and sources of captcha widgets, e.g. g-recaptcha, h-captcha and
+// cf-turnstile.
+var captchaMarkers = []string{"captcha", "cf-turnstile"}
+
+// hasCaptcha reports whether the page shows a captcha widget or image.
+func hasCaptcha(pageSource *html.Node) bool {
+	var found bool
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if found {
+			return
+		}
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if attr.Key != "id" && attr.Key != "class" && attr.Key != "src" && attr.Key != "name" {
+					continue
+				}
+				value := strings.ToLower(attr.Val)
+				for _, marker := range captchaMarkers {
+					if strings.Contains(value, marker) {
+						found = true
+						return
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(pageSource)
+	return found
+}
+
+// loginError returns err with ErrLoginFailed, and with ErrCaptchaDetected when the current page shows a captcha.
+func (nav *Navigator) loginError(err error) error {
+	if pageSource, sourceErr := nav.GetPageSource(); sourceErr == nil && hasCaptcha(pageSource) {
+		nav.Logger.Println("Error - Captcha detected on the login page")
+		return withKind(ErrCaptchaDetected, withKind(ErrLoginFailed, err))
+	}
+	return withKind(ErrLoginFailed, err)
+}
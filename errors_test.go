@@ -0,0 +1,76 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{context.DeadlineExceeded, ErrTimeout},
+		{fmt.Errorf("wait: %w", chromedp.ErrPollingTimeout), ErrTimeout},
+		{chromedp.ErrNoResults, ErrElementNotFound},
+		{errors.New("Could not find node with given id (-32000)"), ErrElementNotFound},
+		{chromedp.ErrChannelClosed, ErrBrowserCrashed},
+		{errors.New("websocket: close 1006 (abnormal closure): unexpected EOF"), ErrBrowserCrashed},
+		{errors.New("page load error net::ERR_NAME_NOT_RESOLVED"), ErrNavigationFailed},
+	}
+	for _, test := range tests {
+		err := fmt.Errorf("error - failed to click button: %w", classifyError(test.err))
+		if !errors.Is(err, test.kind) || !errors.Is(err, test.err) {
+			t.Errorf("Expected %v to be %v and keep its cause", test.err, test.kind)
+		}
+		if strings.Contains(err.Error(), test.kind.Error()) && !strings.Contains(test.err.Error(), test.kind.Error()) {
+			t.Errorf("Expected the message to be kept, got %q", err)
+		}
+	}
+
+	plain := errors.New("invalid selector")
+	if classifyError(plain) != plain || classifyError(nil) != nil {
+		t.Errorf("Expected errors of no kind to be returned as they are")
+	}
+	classified := classifyError(context.DeadlineExceeded)
+	if classifyError(fmt.Errorf("retry: %w", classified)) == nil || errors.Is(classified, ErrElementNotFound) {
+		t.Errorf("Expected a classified error to keep its kind only")
+	}
+}
+
+func TestWithKind(t *testing.T) {
+	err := withKind(ErrCaptchaDetected, withKind(ErrLoginFailed, errors.New("error - message: invalid captcha")))
+	if !errors.Is(err, ErrLoginFailed) || !errors.Is(err, ErrCaptchaDetected) || errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a login and captcha error, got %v", err)
+	}
+	if err.Error() != "error - message: invalid captcha" {
+		t.Errorf("Expected the message to be kept, got %q", err)
+	}
+	if withKind(ErrTimeout, nil) != nil {
+		t.Errorf("Expected nil for a nil error")
+	}
+}
+
+func TestHasCaptcha(t *testing.T) {
+	tests := map[string]bool{
+		`<form><div class="g-recaptcha" data-sitekey="x"></div></form>`:                true,
+		`<iframe src="https://challenges.cloudflare.com/cf-turnstile/frame"></iframe>`: true,
+		`<img id="imagemCaptcha" src="data:image/png;base64,">`:                        true,
+		`<form><input name="username"><p>Solve the captcha below</p></form>`:           false,
+	}
+	for source, want := range tests {
+		pageSource, err := html.Parse(strings.NewReader(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hasCaptcha(pageSource); got != want {
+			t.Errorf("hasCaptcha(%s) = %v, expected %v", source, got, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package goSpider
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"regexp"
+	"sync"
+)
+
+// ErrNoExtractor is returned by ExtractorRegistry.Extract when no extractor matches the url.
+var ErrNoExtractor = errors.New("no extractor")
+
+// Extractor extracts the data of the pages of the URLs it matches, such as the lawsuits of a court system.
+type Extractor interface {
+	Match(url string) bool
+	Extract(node *html.Node) (interface{}, error)
+}
+
+// URLExtractor is an Extractor of the URLs matching a regular expression.
+type URLExtractor struct {
+	Pattern *regexp.Regexp
+	Func    func(node *html.Node) (interface{}, error)
+}
+
+// Match reports whether the url matches e.Pattern.
+func (e *URLExtractor) Match(url string) bool {
+	return e.Pattern.MatchString(url)
+}
+
+// Extract calls e.Func(node).
+func (e *URLExtractor) Extract(node *html.Node) (interface{}, error) {
+	return e.Func(node)
+}
+
+// ExtractorRegistry picks the Extractor of a page by its URL, so a crawl over several sites applies the right one to
+// each page. Extractors are tried in the order they were registered. It is safe for concurrent use.
+type ExtractorRegistry struct {
+	mu         sync.RWMutex
+	extractors []Extractor
+}
+
+// DefaultExtractors is the registry of RegisterExtractor, where site-specific extractors register themselves as
+// plugins, usually from an init function.
+var DefaultExtractors = NewExtractorRegistry()
+
+// NewExtractorRegistry creates an empty ExtractorRegistry.
+func NewExtractorRegistry() *ExtractorRegistry {
+	return &ExtractorRegistry{}
+}
+
+// RegisterExtractor registers an Extractor in DefaultExtractors.
+// Example:
+//
+//	func init() {
+//		goSpider.RegisterExtractor(&goSpider.URLExtractor{
+//			Pattern: regexp.MustCompile(`^https://esaj\.tjsp\.jus\.br/cpopg/show\.do`),
+//			Func:    extractLawsuit,
+//		})
+//	}
+func RegisterExtractor(extractor Extractor) {
+	DefaultExtractors.Register(extractor)
+}
+
+// Register adds an Extractor to the registry.
+func (r *ExtractorRegistry) Register(extractor Extractor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.extractors = append(r.extractors, extractor)
+}
+
+// Lookup returns the first Extractor registered that matches the url.
+func (r *ExtractorRegistry) Lookup(url string) (Extractor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, extractor := range r.extractors {
+		if extractor.Match(url) {
+			return extractor, true
+		}
+	}
+	return nil, false
+}
+
+// Extract extracts the data of the page of the url with the Extractor matching it, failing with ErrNoExtractor when
+// none does.
+// Example:
+//
+//	data, err := goSpider.DefaultExtractors.Extract(url, pageSource)
+func (r *ExtractorRegistry) Extract(url string, node *html.Node) (interface{}, error) {
+	extractor, ok := r.Lookup(url)
+	if !ok {
+		return nil, fmt.Errorf("error - failed to extract %s: %w", url, ErrNoExtractor)
+	}
+	data, err := extractor.Extract(node)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to extract %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// Middleware returns a Middleware setting the Data of each successful result of ParallelRequestsWithMiddleware with the
+// Extractor matching its URL, or its Error when the extraction fails. Results without an Extractor are left as is.
+// Example:
+//
+//	chain := goSpider.MiddlewareChain{goSpider.DefaultExtractors.Middleware()}
+//	results, err := goSpider.ParallelRequestsWithMiddleware(requests, 4, 0, pool.Fetch, chain)
+//	for _, result := range results {
+//		lawsuit := result.Data.(Lawsuit)
+//	}
+func (r *ExtractorRegistry) Middleware() Middleware {
+	return Middleware{
+		OnResult: func(result *PageSource) bool {
+			if result.Error != nil || result.Page == nil {
+				return true
+			}
+			data, err := r.Extract(result.Request, result.Page)
+			switch {
+			case errors.Is(err, ErrNoExtractor):
+			case err != nil:
+				result.Error = err
+			default:
+				result.Data = data
+			}
+			return true
+		},
+	}
+}
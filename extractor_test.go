@@ -0,0 +1,84 @@
+package goSpider
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractorRegistry(t *testing.T) {
+	registry := NewExtractorRegistry()
+	registry.Register(&URLExtractor{
+		Pattern: regexp.MustCompile(`/gzip$`),
+		Func: func(node *html.Node) (interface{}, error) {
+			return ExtractText(node, "//*[@id='title']", "")
+		},
+	})
+	errLayout := errors.New("unknown layout")
+	registry.Register(&URLExtractor{
+		Pattern: regexp.MustCompile(`/latin1$`),
+		Func: func(node *html.Node) (interface{}, error) {
+			return nil, errLayout
+		},
+	})
+	// a later extractor matching the same URLs is not used
+	registry.Register(&URLExtractor{
+		Pattern: regexp.MustCompile(`.*`),
+		Func: func(node *html.Node) (interface{}, error) {
+			return "fallback", nil
+		},
+	})
+
+	extractor, ok := registry.Lookup("https://example.com/gzip")
+	if !ok || extractor.(*URLExtractor).Pattern.String() != `/gzip$` {
+		t.Errorf("Expected the first extractor matching the URL, got %v", extractor)
+	}
+	page, _ := html.Parse(strings.NewReader(`<h1 id="title">Lawsuit</h1>`))
+	data, err := registry.Extract("https://example.com/gzip", page)
+	if err != nil || data != "Lawsuit" {
+		t.Errorf("Expected the extracted title, got %v (%v)", data, err)
+	}
+	_, err = registry.Extract("https://example.com/latin1", page)
+	if !errors.Is(err, errLayout) {
+		t.Errorf("Expected the error of the extractor, got %v", err)
+	}
+	_, err = NewExtractorRegistry().Extract("https://example.com", page)
+	if !errors.Is(err, ErrNoExtractor) {
+		t.Errorf("Expected ErrNoExtractor from an empty registry, got %v", err)
+	}
+}
+
+func TestExtractorRegistryMiddleware(t *testing.T) {
+	server := startFetcherTestServer()
+	defer server.Close()
+
+	registry := NewExtractorRegistry()
+	registry.Register(&URLExtractor{
+		Pattern: regexp.MustCompile(`/gzip$`),
+		Func: func(node *html.Node) (interface{}, error) {
+			return ExtractText(node, "//*[@id='title']", "")
+		},
+	})
+	requests := []Request{
+		{SearchString: server.URL + "/gzip"},
+		{SearchString: server.URL + "/login"},
+	}
+	results, err := ParallelRequestsWithMiddleware(requests, 2, 0, NewHTTPFetcher(5*time.Second).Fetch,
+		MiddlewareChain{registry.Middleware()})
+	if err != nil {
+		t.Fatalf("ParallelRequestsWithMiddleware error: %v", err)
+	}
+	for _, result := range results {
+		expected := interface{}(nil)
+		if strings.HasSuffix(result.Request, "/gzip") {
+			expected = "Compressed"
+		}
+		if result.Data != expected {
+			t.Errorf("Expected the data of %s to be %v, got %v", result.Request, expected, result.Data)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package goSpider
+
+import (
+	"compress/gzip"
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the user agent sent by HTTPFetcher, the same one used by headless Navigators.
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// Fetcher fetches a page and returns its parsed HTML. Fetch has the crawlerFunc signature of ParallelRequests,
+// so any Fetcher can be passed as fetcher.Fetch.
+type Fetcher interface {
+	Fetch(url string) (*html.Node, error)
+}
+
+// FetcherFunc adapts a function to the Fetcher interface.
+type FetcherFunc func(url string) (*html.Node, error)
+
+// Fetch calls f(url).
+func (f FetcherFunc) Fetch(url string) (*html.Node, error) {
+	return f(url)
+}
+
+// StatusError is returned by HTTPFetcher when the response status is not 2xx.
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("error - unexpected status %d fetching URL: %s", e.StatusCode, e.URL)
+}
+
+// HTTPFetcher is a Fetcher for static pages that uses a plain http.Client instead of Chrome. It keeps cookies between
+// requests, follows redirects, decompresses gzip bodies and decodes the page charset (e.g. ISO-8859-1) to UTF-8.
+type HTTPFetcher struct {
+	Client *http.Client
+	Header http.Header
+	Logger *log.Logger
+	Cache  *PageCache // when set, fresh cached copies are returned without requesting the url
+}
+
+// NewHTTPFetcher creates an HTTPFetcher with a cookie jar, the given request timeout and the default user agent.
+// Example:
+//
+//	fetcher := goSpider.NewHTTPFetcher(30 * time.Second)
+//	results, err := goSpider.ParallelRequests(requests, 10, 0, fetcher.Fetch)
+func NewHTTPFetcher(timeout time.Duration) *HTTPFetcher {
+	jar, _ := cookiejar.New(nil)
+	header := http.Header{}
+	header.Set("User-Agent", DefaultUserAgent)
+	header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	header.Set("Accept-Language", "pt-BR,pt;q=0.9,en;q=0.8")
+
+	return &HTTPFetcher{
+		Client: &http.Client{Jar: jar, Timeout: timeout},
+		Header: header,
+		Logger: log.New(os.Stdout, "goSpider: ", log.LstdFlags),
+	}
+}
+
+// SetCookies adds cookies for the url to the fetcher cookie jar, e.g. cookies obtained by logging in with a Navigator.
+func (f *HTTPFetcher) SetCookies(rawURL string, cookies []*http.Cookie) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("error - failed to parse URL: %w", classifyError(err))
+	}
+	if f.Client.Jar == nil {
+		return fmt.Errorf("error - fetcher has no cookie jar")
+	}
+	f.Client.Jar.SetCookies(u, cookies)
+	return nil
+}
+
+// Fetch requests the url with GET and parses the response body. Responses with a status other than 2xx are errors.
+// Example:
+//
+//	pageSource, err := fetcher.Fetch("https://www.example.com")
+func (f *HTTPFetcher) Fetch(url string) (*html.Node, error) {
+	if f.Cache != nil {
+		if body, ok := f.Cache.Get(url); ok {
+			f.Logger.Printf("Fetched URL from cache: %s\n", url)
+			return html.Parse(strings.NewReader(body))
+		}
+	}
+
+	response, err := f.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	decoded, err := decodeBody(response)
+	if err != nil {
+		f.Logger.Printf("Error - Failed to decode body: %v\n", err)
+		return nil, fmt.Errorf("error - failed to decode body: %w", classifyError(err))
+	}
+	content, err := io.ReadAll(decoded)
+	if err != nil {
+		f.Logger.Printf("Error - Failed to read body: %v\n", err)
+		return nil, fmt.Errorf("error - failed to read body: %w", classifyError(err))
+	}
+	body := string(content)
+
+	if f.Cache != nil {
+		err = f.Cache.Set(url, body)
+		if err != nil {
+			f.Logger.Printf("Error - Failed to cache page: %v\n", err)
+		}
+	}
+
+	pageSource, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		f.Logger.Printf("Error - Failed to parse HTML: %v\n", err)
+		return nil, fmt.Errorf("error - failed to parse HTML: %w", classifyError(err))
+	}
+	return pageSource, nil
+}
+
+// Get requests the url with GET and the fetcher headers, and returns the response when its status is 2xx.
+// The caller must close the response body.
+func (f *HTTPFetcher) Get(url string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to create request: %w", classifyError(err))
+	}
+	for name, values := range f.Header {
+		request.Header[name] = values
+	}
+
+	f.Logger.Printf("Fetching URL: %s\n", url)
+	response, err := f.Client.Do(request)
+	if err != nil {
+		f.Logger.Printf("Error - Failed to fetch URL: %v\n", err)
+		return nil, fmt.Errorf("error - failed to fetch URL: %w", classifyError(err))
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		f.Logger.Printf("Error - Unexpected status %d fetching URL: %s\n", response.StatusCode, url)
+		return nil, &StatusError{StatusCode: response.StatusCode, URL: url}
+	}
+	return response, nil
+}
+
+// decodeBody returns the response body decompressed, when gzip was requested explicitly, and decoded to UTF-8.
+func decodeBody(response *http.Response) (io.Reader, error) {
+	var body io.Reader = response.Body
+	if response.Header.Get("Content-Encoding") == "gzip" && !response.Uncompressed {
+		gz, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = gz
+	}
+	return charset.NewReader(body, response.Header.Get("Content-Type"))
+}
+
+// Fetch opens the url and returns the page source, so a Navigator can be used wherever a Fetcher is expected. The
+// hooks of nav.Middleware run around it, and the page is the one returned by the OnAfterPage hooks.
+// Example:
+//
+//	results, err := goSpider.ParallelRequests(requests, 1, 0, nav.Fetch)
+func (nav *Navigator) Fetch(url string) (*html.Node, error) {
+	return nav.navigate(url, true)
+}
@@ -0,0 +1,86 @@
+package goSpider
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func startFetcherTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		http.Redirect(w, r, "/latin1", http.StatusFound)
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "abc" {
+			http.Error(w, "no session", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=ISO-8859-1")
+		// "Petição" in ISO-8859-1
+		w.Write([]byte("<html><body><h1 id=\"title\">Peti\xe7\xe3o</h1></body></html>"))
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`<html><body><h1 id="title">Compressed</h1></body></html>`))
+		gz.Close()
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := startFetcherTestServer()
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(5 * time.Second)
+
+	pageSource, err := fetcher.Fetch(server.URL + "/login")
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	title, err := ExtractText(pageSource, "//*[@id=\"title\"]", "")
+	if err != nil || title != "Petição" {
+		t.Errorf("Expected the redirected latin1 page to be decoded, but got %q (%v)", title, err)
+	}
+
+	// gzip requested explicitly is decompressed by the fetcher
+	fetcher.Header.Set("Accept-Encoding", "gzip")
+	pageSource, err = fetcher.Fetch(server.URL + "/gzip")
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	title, err = ExtractText(pageSource, "//*[@id=\"title\"]", "")
+	if err != nil || title != "Compressed" {
+		t.Errorf("Expected the gzip page to be decompressed, but got %q (%v)", title, err)
+	}
+
+	_, err = fetcher.Fetch(server.URL + "/missing")
+	if err == nil {
+		t.Errorf("Expected error for a 404 response")
+	}
+}
+
+func TestHTTPFetcherParallelRequests(t *testing.T) {
+	server := startFetcherTestServer()
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(5 * time.Second)
+	requests := []Request{
+		{SearchString: server.URL + "/gzip"},
+		{SearchString: server.URL + "/gzip"},
+	}
+
+	results, err := ParallelRequests(requests, 2, 0, fetcher.Fetch)
+	if err != nil {
+		t.Fatalf("ParallelRequests error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results, but got %d", len(results))
+	}
+}
@@ -0,0 +1,322 @@
+/*
+Package fixtures composes test pages programmatically and serves them from an httptest server, so integration
+tests of crawlers can exercise forms, iframes, delayed elements, new tab links, paginated tables and captchas
+without depending on live court websites.
+
+A Site holds the pages by path. Each Page is built by chaining its parts, which are rendered in order:
+
+	site := fixtures.NewSite()
+	site.Page("/search", "Search").
+		Form("searchForm", "/results", fixtures.Field{Name: "number", Label: "Number"}).
+		FakeCaptcha("captcha", "site-key")
+	site.Page("/results", "Results").
+		DelayedElement("loaded", "Results loaded", 500*time.Millisecond).
+		PaginatedTable("results", rows, 10)
+
+	server := site.Start()
+	defer server.Close()
+
+	nav.OpenURL(server.URL + "/search")
+*/
+package fixtures
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// captchaImage is a 1x1 PNG shown as the image of fake captchas.
+const captchaImage = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+// Site is a set of pages served by path. Requests to the pages are recorded, so tests can assert what a crawler
+// submitted. It is safe for concurrent use.
+type Site struct {
+	mu       sync.Mutex
+	pages    map[string]*Page
+	handlers map[string]http.Handler
+	requests map[string][]url.Values
+}
+
+// NewSite creates an empty Site.
+func NewSite() *Site {
+	return &Site{
+		pages:    make(map[string]*Page),
+		handlers: make(map[string]http.Handler),
+		requests: make(map[string][]url.Values),
+	}
+}
+
+// Page returns the page of path with the title, creating it if needed. Parts added to the page are rendered in
+// the order they are added.
+// Example:
+//
+//	site.Page("/", "Home").NewTabLink("details", "/details", "Details")
+func (s *Site) Page(path, title string) *Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page, ok := s.pages[path]
+	if !ok {
+		page = &Page{Title: title}
+		s.pages[path] = page
+	}
+	return page
+}
+
+// Handle serves path with a custom handler, e.g. for downloads or JSON endpoints. Handlers take precedence over
+// pages of the same path.
+// Example:
+//
+//	site.Handle("/files/report.pdf", http.FileServer(http.Dir("testdata")))
+func (s *Site) Handle(path string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[path] = handler
+}
+
+// Requests returns the query and form values of the requests made to path, in order. Forms submitted by a crawler
+// can be checked with it.
+// Example:
+//
+//	values := site.Requests("/results")
+//	if len(values) != 1 || values[0].Get("number") != "1017927-35.2023.8.26.0008" {
+//		t.Errorf("Unexpected submission: %v", values)
+//	}
+func (s *Site) Requests(path string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.requests[path]...)
+}
+
+// ServeHTTP serves the handler or page of the request path, or 404 Not Found.
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[r.URL.Path] = append(s.requests[r.URL.Path], r.Form)
+	handler, hasHandler := s.handlers[r.URL.Path]
+	page, hasPage := s.pages[r.URL.Path]
+	var body string
+	if !hasHandler && hasPage {
+		body = page.Render()
+	}
+	s.mu.Unlock()
+
+	switch {
+	case hasHandler:
+		handler.ServeHTTP(w, r)
+	case hasPage:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Start serves the site from a new httptest server. The caller must close the server.
+// Example:
+//
+//	server := site.Start()
+//	defer server.Close()
+func (s *Site) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// Field is an input of a Form. Fields with Options are rendered as a select and fields of type "submit" as the
+// submit button, which is added when no field has that type.
+type Field struct {
+	Name    string
+	Type    string // the input type, "text" if empty
+	Label   string
+	Value   string
+	Options []string
+}
+
+// Page is an HTML page composed of parts.
+type Page struct {
+	Title string
+
+	parts []string
+}
+
+// HTML adds raw markup to the page.
+func (p *Page) HTML(markup string) *Page {
+	p.parts = append(p.parts, markup)
+	return p
+}
+
+// Form adds a form with the fields, submitted with POST to action. Each field gets the id "<form id>_<name>".
+// Example:
+//
+//	page.Form("loginForm", "/login",
+//		fixtures.Field{Name: "username", Label: "User"},
+//		fixtures.Field{Name: "password", Type: "password", Label: "Password"})
+func (p *Page) Form(id, action string, fields ...Field) *Page {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<form id="%s" method="post" action="%s">`, attr(id), attr(action))
+	hasSubmit := false
+	for _, field := range fields {
+		fieldID := attr(id + "_" + field.Name)
+		if field.Type == "submit" {
+			hasSubmit = true
+			fmt.Fprintf(&b, `<button type="submit" id="%s" name="%s" value="%s">%s</button>`,
+				fieldID, attr(field.Name), attr(field.Value), html.EscapeString(field.Label))
+			continue
+		}
+		if field.Label != "" {
+			fmt.Fprintf(&b, `<label for="%s">%s</label>`, fieldID, html.EscapeString(field.Label))
+		}
+		if len(field.Options) > 0 {
+			fmt.Fprintf(&b, `<select id="%s" name="%s">`, fieldID, attr(field.Name))
+			for _, option := range field.Options {
+				selected := ""
+				if option == field.Value {
+					selected = " selected"
+				}
+				fmt.Fprintf(&b, `<option value="%s"%s>%s</option>`, attr(option), selected, html.EscapeString(option))
+			}
+			b.WriteString(`</select>`)
+			continue
+		}
+		fieldType := field.Type
+		if fieldType == "" {
+			fieldType = "text"
+		}
+		fmt.Fprintf(&b, `<input type="%s" id="%s" name="%s" value="%s">`,
+			attr(fieldType), fieldID, attr(field.Name), attr(field.Value))
+	}
+	if !hasSubmit {
+		fmt.Fprintf(&b, `<button type="submit" id="%s_submit">Submit</button>`, attr(id))
+	}
+	b.WriteString(`</form>`)
+	return p.HTML(b.String())
+}
+
+// IFrame adds an iframe showing src, usually another page of the site.
+// Example:
+//
+//	site.Page("/frame", "Frame").HTML(`<p id="inside">Inside the frame</p>`)
+//	site.Page("/", "Home").IFrame("content", "/frame")
+func (p *Page) IFrame(id, src string) *Page {
+	return p.HTML(fmt.Sprintf(`<iframe id="%s" name="%s" src="%s"></iframe>`, attr(id), attr(id), attr(src)))
+}
+
+// DelayedElement adds a div with the text, inserted into the page only after the delay, like content loaded by
+// ajax calls.
+// Example:
+//
+//	page.DelayedElement("results", "3 lawsuits found", time.Second)
+//	err := nav.WaitForElement("#results", 2*time.Second)
+func (p *Page) DelayedElement(id, text string, delay time.Duration) *Page {
+	return p.HTML(fmt.Sprintf(`<script>
+setTimeout(function () {
+	var element = document.createElement('div');
+	element.id = %s;
+	element.textContent = %s;
+	document.body.appendChild(element);
+}, %d);
+</script>`, jsValue(id), jsValue(text), delay.Milliseconds()))
+}
+
+// NewTabLink adds a link to href that opens in a new tab.
+func (p *Page) NewTabLink(id, href, text string) *Page {
+	return p.HTML(fmt.Sprintf(`<a id="%s" href="%s" target="_blank" rel="opener">%s</a>`,
+		attr(id), attr(href), html.EscapeString(text)))
+}
+
+// PaginatedTable adds a table with the rows, pageSize rows at a time. Clicking the link "#<id>Next" shows the next
+// page; its list item "#<id>NextItem" gets the class "disabled" on the last page, and the link is then hidden.
+// Example:
+//
+//	page.PaginatedTable("results", [][]string{{"1", "Lawsuit 1"}, {"2", "Lawsuit 2"}}, 1)
+//	rows, err := goSpider.Paginate(nav, "#resultsNext", 0, extractRows)
+func (p *Page) PaginatedTable(id string, rows [][]string, pageSize int) *Page {
+	if pageSize <= 0 {
+		pageSize = len(rows)
+	}
+	return p.HTML(fmt.Sprintf(`<table id="%[1]s"><tbody></tbody></table>
+<ul class="pagination"><li id="%[1]sNextItem"><a id="%[1]sNext" href="#">Next</a></li></ul>
+<script>
+(function () {
+	var rows = %[2]s, pageSize = %[3]d, page = 0;
+	var pages = Math.max(1, Math.ceil(rows.length / pageSize));
+	function render() {
+		var tbody = document.querySelector('#%[1]s tbody');
+		tbody.innerHTML = '';
+		rows.slice(page * pageSize, (page + 1) * pageSize).forEach(function (cells) {
+			var row = tbody.insertRow();
+			cells.forEach(function (cell) { row.insertCell().textContent = cell; });
+		});
+		if (page === pages - 1) {
+			document.getElementById('%[1]sNextItem').classList.add('disabled');
+			document.getElementById('%[1]sNext').style.display = 'none';
+		}
+	}
+	document.getElementById('%[1]sNext').addEventListener('click', function (event) {
+		event.preventDefault();
+		if (page < pages - 1) {
+			page++;
+			render();
+		}
+	});
+	render();
+})();
+</script>`, attr(id), jsValue(rows), pageSize))
+}
+
+// FakeCaptcha adds a captcha widget with the site key, as rendered by reCAPTCHA, along with an image captcha and
+// its answer field "<id>Answer". It never validates anything; it only lets tests exercise captcha detection and
+// the solving steps of a crawler.
+// Example:
+//
+//	page.FakeCaptcha("captcha", "6Lc-fake-site-key")
+//	err := nav.Login(server.URL+"/login", "user", "password", "#u", "#p", "#login", "#error")
+//	errors.Is(err, goSpider.ErrCaptchaDetected) // true when the login fails
+func (p *Page) FakeCaptcha(id, siteKey string) *Page {
+	return p.HTML(fmt.Sprintf(`<div id="%[1]s" class="g-recaptcha" data-sitekey="%[2]s">
+<img id="%[1]sImage" src="data:image/png;base64,%[3]s" alt="captcha">
+<input type="text" id="%[1]sAnswer" name="captcha">
+<textarea id="g-recaptcha-response" name="g-recaptcha-response" style="display: none;"></textarea>
+</div>`, attr(id), attr(siteKey), captchaImage))
+}
+
+// Render returns the HTML document of the page.
+func (p *Page) Render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"UTF-8\">\n<title>%s</title>\n</head>\n<body>\n",
+		html.EscapeString(p.Title))
+	for _, part := range p.parts {
+		b.WriteString(part)
+		b.WriteString("\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// CaptchaImage returns the PNG image shown by fake captchas.
+func CaptchaImage() []byte {
+	data, _ := base64.StdEncoding.DecodeString(captchaImage)
+	return data
+}
+
+// attr escapes s for an attribute value.
+func attr(s string) string {
+	return html.EscapeString(s)
+}
+
+// jsValue encodes v as a JavaScript literal that is safe inside a script element.
+func jsValue(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
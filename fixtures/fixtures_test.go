@@ -0,0 +1,172 @@
+package fixtures
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func get(t *testing.T, rawURL string) (int, string) {
+	t.Helper()
+	response, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatalf("GET %s error: %v", rawURL, err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	return response.StatusCode, string(body)
+}
+
+// findByID returns the element with the id in the document, or nil.
+func findByID(t *testing.T, document, id string) *html.Node {
+	t.Helper()
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var find func(*html.Node) *html.Node
+	find = func(node *html.Node) *html.Node {
+		for _, a := range node.Attr {
+			if a.Key == "id" && a.Val == id {
+				return node
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(root)
+}
+
+func attrOf(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func TestSitePages(t *testing.T) {
+	site := NewSite()
+	site.Page("/", "Home <1>").
+		Form("search", "/results",
+			Field{Name: "number", Label: "Number"},
+			Field{Name: "court", Options: []string{"TJSP", "TJMG"}, Value: "TJMG"}).
+		IFrame("content", "/frame").
+		NewTabLink("details", "/details?id=1", "Details").
+		FakeCaptcha("captcha", "site-key")
+	site.Page("/frame", "Frame").HTML(`<p id="inside">Inside</p>`)
+
+	server := site.Start()
+	defer server.Close()
+
+	status, body := get(t, server.URL+"/")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !strings.Contains(body, "<title>Home &lt;1&gt;</title>") {
+		t.Errorf("Expected an escaped title, got: %s", body)
+	}
+
+	form := findByID(t, body, "search")
+	if form == nil || attrOf(form, "action") != "/results" || attrOf(form, "method") != "post" {
+		t.Fatalf("Expected a form posting to /results, got: %s", body)
+	}
+	if field := findByID(t, body, "search_number"); field == nil || attrOf(field, "name") != "number" {
+		t.Errorf("Expected the number field")
+	}
+	if findByID(t, body, "search_court") == nil || !strings.Contains(body, `<option value="TJMG" selected>`) {
+		t.Errorf("Expected the court select with TJMG selected")
+	}
+	if findByID(t, body, "search_submit") == nil {
+		t.Errorf("Expected a submit button")
+	}
+	if frame := findByID(t, body, "content"); frame == nil || attrOf(frame, "src") != "/frame" {
+		t.Errorf("Expected the iframe")
+	}
+	if link := findByID(t, body, "details"); link == nil || attrOf(link, "target") != "_blank" {
+		t.Errorf("Expected a new tab link")
+	}
+	if captcha := findByID(t, body, "captcha"); captcha == nil || attrOf(captcha, "data-sitekey") != "site-key" {
+		t.Errorf("Expected the captcha widget")
+	}
+
+	status, body = get(t, server.URL+"/frame")
+	if status != http.StatusOK || findByID(t, body, "inside") == nil {
+		t.Errorf("Expected the frame page, got %d: %s", status, body)
+	}
+	status, _ = get(t, server.URL+"/missing")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing page, got %d", status)
+	}
+}
+
+func TestSiteRequests(t *testing.T) {
+	site := NewSite()
+	site.Page("/results", "Results").HTML(`<p id="done">Done</p>`)
+	site.Handle("/api", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	server := site.Start()
+	defer server.Close()
+
+	response, err := http.PostForm(server.URL+"/results?page=1", url.Values{"number": {"1017927-35.2023.8.26.0008"}})
+	if err != nil {
+		t.Fatalf("POST error: %v", err)
+	}
+	response.Body.Close()
+	_, body := get(t, server.URL+"/api")
+	if body != `{"ok":true}` {
+		t.Errorf("Expected the handler response, got: %s", body)
+	}
+
+	requests := site.Requests("/results")
+	if len(requests) != 1 || requests[0].Get("number") != "1017927-35.2023.8.26.0008" || requests[0].Get("page") != "1" {
+		t.Errorf("Expected the submitted values, got: %v", requests)
+	}
+	if len(site.Requests("/api")) != 1 || len(site.Requests("/other")) != 0 {
+		t.Errorf("Expected one request to /api and none to /other")
+	}
+}
+
+func TestPageScripts(t *testing.T) {
+	page := (&Page{Title: "Scripts"}).
+		DelayedElement("late", "</script><b>", 1500*time.Millisecond).
+		PaginatedTable("results", [][]string{{"1", "a"}, {"2", "b"}, {"3", "c"}}, 2)
+	document := page.Render()
+
+	if strings.Count(document, "</script>") != 2 {
+		t.Errorf("Expected the text to be escaped inside the script, got: %s", document)
+	}
+	if !strings.Contains(document, "}, 1500);") {
+		t.Errorf("Expected the delay in milliseconds, got: %s", document)
+	}
+	if !strings.Contains(document, `[["1","a"],["2","b"],["3","c"]], pageSize = 2`) {
+		t.Errorf("Expected the rows and page size, got: %s", document)
+	}
+	if findByID(t, document, "results") == nil || findByID(t, document, "resultsNext") == nil ||
+		findByID(t, document, "resultsNextItem") == nil {
+		t.Errorf("Expected the table and its next button")
+	}
+}
+
+func TestCaptchaImage(t *testing.T) {
+	_, err := png.Decode(bytes.NewReader(CaptchaImage()))
+	if err != nil {
+		t.Errorf("Expected a valid PNG: %v", err)
+	}
+}
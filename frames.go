@@ -0,0 +1,102 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+)
+
+// frameSource is the HTML of a document and of its frames, in document order, nil for the frames that can't be read.
+type frameSource struct {
+	HTML   string
+	Frames []*frameSource
+}
+
+// jsFrameSources is a JavaScript expression returning the frameSource of the page, reading the documents of the
+// frames the page can reach: the same-origin ones.
+const jsFrameSources = `(function() {
+	var read = function(doc) {
+		var frames = [];
+		doc.querySelectorAll("iframe, frame").forEach(function(frame) {
+			var inner = null;
+			try { inner = frame.contentDocument; } catch (e) {}
+			frames.push(inner && inner.documentElement ? read(inner) : null);
+		});
+		return {HTML: doc.documentElement.outerHTML, Frames: frames};
+	};
+	return read(document);
+})()`
+
+// GetPageSourceWithFrames is GetPageSource with the documents of the frames inlined: the html element of each frame
+// document is a child of its iframe or frame element, so FindNodes, ExtractTable and ExtractText can reach the content
+// of frames, e.g. "//iframe[@id='documentos']//table". Cross-origin frames, whose documents the page can't read, are
+// left empty.
+// Example:
+//
+//	pageSource, err := nav.GetPageSourceWithFrames()
+//	nodes, err := goSpider.FindNodes(pageSource, "//iframe[@id='documentsFrame']//a")
+func (nav *Navigator) GetPageSourceWithFrames() (*html.Node, error) {
+	nav.Logger.Println("Getting the HTML content of the page and its frames")
+	_, err := nav.WaitPageLoad()
+	if err != nil {
+		return nil, err
+	}
+
+	var source frameSource
+	err = chromedp.Run(nav.Ctx, chromedp.Evaluate(jsFrameSources, &source))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get page HTML: %v\n", err)
+		return nil, fmt.Errorf("error - failed to get page HTML: %w", classifyError(err))
+	}
+
+	pageSource, err := source.parse()
+	if err != nil {
+		nav.Logger.Printf("Error - failed to convert page HTML: %v", err)
+		return nil, fmt.Errorf("error - failed to convert page HTML: %w", classifyError(err))
+	}
+	nav.Logger.Println("Page HTML with frames retrieved successfully")
+	return pageSource, nil
+}
+
+// parse parses the document of s with the documents of its frames inlined.
+func (s *frameSource) parse() (*html.Node, error) {
+	doc, err := htmlquery.Parse(strings.NewReader(s.HTML))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, frame := range frameElements(doc) {
+		if i >= len(s.Frames) || s.Frames[i] == nil {
+			continue
+		}
+		inner, err := s.Frames[i].parse()
+		if err != nil {
+			return nil, err
+		}
+		for child := frame.FirstChild; child != nil; child = frame.FirstChild {
+			frame.RemoveChild(child)
+		}
+		for child := inner.FirstChild; child != nil; child = inner.FirstChild {
+			inner.RemoveChild(child)
+			if child.Type == html.ElementNode {
+				frame.AppendChild(child)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// frameElements returns the iframe and frame elements under n, in document order.
+func frameElements(n *html.Node) []*html.Node {
+	var frames []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && (child.DataAtom == atom.Iframe || child.DataAtom == atom.Frame) {
+			frames = append(frames, child)
+		}
+		frames = append(frames, frameElements(child)...)
+	}
+	return frames
+}
@@ -0,0 +1,64 @@
+package goSpider
+
+import (
+	"testing"
+)
+
+func TestFrameSourceParse(t *testing.T) {
+	source := frameSource{
+		HTML: `<html><body><iframe id="first">fallback</iframe><iframe id="blocked"></iframe></body></html>`,
+		Frames: []*frameSource{
+			{
+				HTML:   `<html><body><p>First</p><iframe id="nested"></iframe></body></html>`,
+				Frames: []*frameSource{{HTML: `<html><body><p>Nested</p></body></html>`}},
+			},
+			nil,
+		},
+	}
+	pageSource, err := source.parse()
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	for expression, expected := range map[string]string{
+		"//iframe[@id='first']/html/body/p":   "First",
+		"//iframe[@id='nested']//p":           "Nested",
+		"//iframe[@id='first']/text()":        "",
+		"//iframe[@id='blocked']//p":          "",
+		"//iframe[@id='first']//iframe//body": "Nested",
+	} {
+		text, err := ExtractText(pageSource, expression, "")
+		if expected == "" {
+			if err == nil && text != "" {
+				t.Errorf("Expected nothing at %s, got %q", expression, text)
+			}
+			continue
+		}
+		if err != nil || text != expected {
+			t.Errorf("Expected %q at %s, got %q (%v)", expected, expression, text, err)
+		}
+	}
+}
+
+func TestGetPageSourceWithFrames(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/documents.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	pageSource, err := nav.GetPageSourceWithFrames()
+	if err != nil {
+		t.Fatalf("GetPageSourceWithFrames error: %v", err)
+	}
+	text, err := ExtractText(pageSource, "//iframe[@id='documentsFrame']//a[@class='frameDocument']", "")
+	if err != nil {
+		t.Fatalf("ExtractText error: %v", err)
+	}
+	if text != "Sentença" {
+		t.Errorf("Expected the link inside the frame, but got %q", text)
+	}
+}
@@ -0,0 +1,80 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// Permission is a browser permission a page can ask for, as named by Chrome.
+type Permission = browser.PermissionType
+
+// Permissions that can be granted with GrantPermissions.
+const (
+	PermissionGeolocation   Permission = browser.PermissionTypeGeolocation
+	PermissionNotifications Permission = browser.PermissionTypeNotifications
+	PermissionClipboard     Permission = browser.PermissionTypeClipboardReadWrite
+	PermissionCamera        Permission = browser.PermissionTypeVideoCapture
+	PermissionMicrophone    Permission = browser.PermissionTypeAudioCapture
+)
+
+// SetGeolocation makes the page see the device at latitude and longitude, in degrees, with an accuracy in meters, and
+// grants the geolocation permission to every origin, so location-gated content, such as store locators and regional
+// pricing, is scraped deterministically. ClearGeolocation stops the override.
+// Example:
+//
+//	err := nav.SetGeolocation(-23.5505, -46.6333, 50)
+//	err = nav.OpenURL("https://www.example.com/lojas")
+func (nav *Navigator) SetGeolocation(latitude, longitude, accuracy float64) error {
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 || accuracy < 0 {
+		return fmt.Errorf("error - invalid geolocation %v, %v with accuracy %v", latitude, longitude, accuracy)
+	}
+	err := nav.GrantPermissions("", PermissionGeolocation)
+	if err != nil {
+		return err
+	}
+	err = chromedp.Run(nav.Ctx,
+		emulation.SetGeolocationOverride().WithLatitude(latitude).WithLongitude(longitude).WithAccuracy(accuracy),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to set geolocation: %v\n", err)
+		return fmt.Errorf("error - failed to set geolocation: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Geolocation set to %v, %v\n", latitude, longitude)
+	return nil
+}
+
+// ClearGeolocation stops the geolocation override of SetGeolocation.
+func (nav *Navigator) ClearGeolocation() error {
+	err := chromedp.Run(nav.Ctx, emulation.ClearGeolocationOverride())
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to clear geolocation: %v\n", err)
+		return fmt.Errorf("error - failed to clear geolocation: %w", classifyError(err))
+	}
+	nav.Logger.Println("Geolocation cleared")
+	return nil
+}
+
+// GrantPermissions grants the permissions to origin, such as "https://www.example.com", or to every origin when origin
+// is empty, so the page gets them without a prompt. Calling it without permissions resets the permissions granted.
+// Example:
+//
+//	err := nav.GrantPermissions("https://www.example.com", goSpider.PermissionGeolocation, goSpider.PermissionNotifications)
+func (nav *Navigator) GrantPermissions(origin string, permissions ...Permission) error {
+	var action chromedp.Action = browser.ResetPermissions()
+	if len(permissions) > 0 {
+		grant := browser.GrantPermissions(permissions)
+		if origin != "" {
+			grant = grant.WithOrigin(origin)
+		}
+		action = grant
+	}
+	err := chromedp.Run(nav.Ctx, action)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to grant permissions: %v\n", err)
+		return fmt.Errorf("error - failed to grant permissions: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Permissions granted: %v\n", permissions)
+	return nil
+}
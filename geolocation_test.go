@@ -0,0 +1,50 @@
+package goSpider
+
+import (
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestSetGeolocation(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	err = nav.SetGeolocation(-23.5505, -46.6333, 50)
+	if err != nil {
+		t.Fatalf("SetGeolocation error: %v", err)
+	}
+	var position string
+	err = chromedp.Run(nav.Ctx, chromedp.Evaluate(`new Promise(function(resolve, reject) {
+		navigator.geolocation.getCurrentPosition(function(p) {
+			resolve(p.coords.latitude + "," + p.coords.longitude + "," + p.coords.accuracy);
+		}, function(e) { reject(e.message); });
+	})`, &position, awaitPromise))
+	if err != nil || position != "-23.5505,-46.6333,50" {
+		t.Errorf("Expected the overridden position, got %v: %v", position, err)
+	}
+
+	err = nav.ClearGeolocation()
+	if err != nil {
+		t.Errorf("ClearGeolocation error: %v", err)
+	}
+	err = nav.GrantPermissions("")
+	if err != nil {
+		t.Errorf("GrantPermissions error: %v", err)
+	}
+}
+
+func TestSetGeolocationInvalid(t *testing.T) {
+	nav := &Navigator{}
+	if err := nav.SetGeolocation(91, 0, 10); err == nil {
+		t.Error("Expected an error for a latitude out of range")
+	}
+	if err := nav.SetGeolocation(0, 0, -1); err == nil {
+		t.Error("Expected an error for a negative accuracy")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/DanielFillol/goSpider/htmlQuery"
@@ -11,10 +12,10 @@ import (
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 	"io/ioutil"
 	"log"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,12 +23,36 @@ import (
 )
 
 // Navigator is a struct that holds the context for the ChromeDP session and a logger.
+// A Navigator drives a single tab and is not safe for concurrent use; use NewTab to get a Navigator per goroutine.
 type Navigator struct {
-	Ctx     context.Context
-	Cancel  context.CancelFunc
-	Logger  *log.Logger
-	Timeout time.Duration
-	Cookies []*network.Cookie
+	Ctx        context.Context
+	Cancel     context.CancelFunc
+	Logger     *log.Logger
+	Timeout    time.Duration
+	Cookies    []*network.Cookie
+	Locators   *LocatorStore   // recorded locators when self-healing is enabled, see EnableSelfHealing
+	Cache      *PageCache      // when set, OpenURL loads fresh cached copies instead of the network, see NewPageCache
+	Middleware MiddlewareChain // hooks run by OpenURL and Fetch, see Use
+
+	intercept   *interception     // the Fetch interception rules of the tab, see setInterceptRule
+	assets      *assetTracker     // failed resource loads, see TrackAssets
+	cassette    *cassetteSession  // the cassette being recorded or replayed, see RecordCassette
+	initScripts []initScript      // scripts run in every document, see AddInitScript
+	media       string            // the emulated CSS media type, see EmulateMedia
+	colorScheme string            // the emulated prefers-color-scheme, see EmulateColorScheme
+	timeouts    Timeouts          // the timeouts other than Timeout, see SetTimeouts
+	proxyAuth   *proxyCredentials // the credentials of the proxy, see WithProxy
+}
+
+// NavigatorOption configures the browser of a Navigator created by NewNavigator.
+type NavigatorOption func(config *navigatorConfig)
+
+// navigatorConfig collects the settings of the NavigatorOptions.
+type navigatorConfig struct {
+	allocatorOptions []chromedp.ExecAllocatorOption
+	headlessMode     HeadlessMode
+	execPath         string
+	proxyAuth        *proxyCredentials
 }
 
 // NewNavigator creates a new Navigator instance.
@@ -41,7 +66,8 @@ type Navigator struct {
 //	nav := goSpider.NewNavigator("/Users/USER_NAME/Library/Application Support/Google/Chrome/Profile 2", true, initialCookies)
 //
 // NewNavigator creates a new Navigator instance with enhanced logging for troubleshooting authentication issues.
-func NewNavigator(profilePath string, headless bool) *Navigator {
+// Options such as WithHostResolverRules configure the browser.
+func NewNavigator(profilePath string, headless bool, options ...NavigatorOption) *Navigator {
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
 		chromedp.NoDefaultBrowserCheck,
 		chromedp.DisableGPU,
@@ -66,6 +92,21 @@ func NewNavigator(profilePath string, headless bool) *Navigator {
 		opts = append(opts, chromedp.UserDataDir(profilePath))
 	}
 
+	config := &navigatorConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	opts = append(opts, config.allocatorOptions...)
+	var headlessShell string
+	if headless {
+		var modeOptions []chromedp.ExecAllocatorOption
+		modeOptions, headlessShell = headlessOptions(config.headlessMode, config.execPath)
+		opts = append(opts, modeOptions...)
+	}
+	if config.execPath != "" && headlessShell == "" {
+		opts = append(opts, chromedp.ExecPath(config.execPath))
+	}
+
 	allocCtx, cancelAllocCtx := chromedp.NewExecAllocator(context.Background(), opts...)
 	ctx, cancelCtx := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
 
@@ -76,22 +117,45 @@ func NewNavigator(profilePath string, headless bool) *Navigator {
 			cancelCtx()
 			cancelAllocCtx()
 		},
-		Logger:  logger,
-		Cookies: []*network.Cookie{},
+		Logger:    logger,
+		Cookies:   []*network.Cookie{},
+		intercept: &interception{},
+		proxyAuth: config.proxyAuth,
+	}
+
+	if headlessShell != "" {
+		logger.Printf("Using chrome-headless-shell: %s\n", headlessShell)
+	} else if headless && config.headlessMode == HeadlessShell {
+		logger.Printf("chrome-headless-shell not found, using Chrome headless\n")
+	}
+
+	if navigator.proxyAuth != nil {
+		navigator.authenticateProxy()
 	}
 
 	// Set standard timeout with enhanced logging
-	navigator.SetTimeOut(300 * time.Millisecond)
+	navigator.SetTimeOut(DefaultTimeout)
 	logger.Printf("Navigator initialized with timeout: %v\n", navigator.Timeout)
 
 	return navigator
 }
 
-// SetTimeOut sets a timeout for all the waiting functions on the package. The standard timeout of the Navigator is 300 ms.
+// SetTimeOut sets the timeout of the element waits of the Navigator, DefaultTimeout by default. Page loads, scripts and
+// downloads have timeouts of their own, see SetTimeouts.
 func (nav *Navigator) SetTimeOut(timeOut time.Duration) {
 	nav.Timeout = timeOut
 }
 
+// GetTimeOut returns the timeout of the waiting functions, as set by SetTimeOut.
+func (nav *Navigator) GetTimeOut() time.Duration {
+	return nav.Timeout
+}
+
+// GetLogger returns the logger of the Navigator.
+func (nav *Navigator) GetLogger() *log.Logger {
+	return nav.Logger
+}
+
 // GetElementAttribute retrieves the value of a specified attribute from an element identified by a CSS selector.
 // Parameters:
 // - selector: The CSS selector of the element.
@@ -100,6 +164,7 @@ func (nav *Navigator) SetTimeOut(timeOut time.Duration) {
 // - The value of the specified attribute.
 // - An error if the attribute value could not be retrieved.
 func (nav *Navigator) GetElementAttribute(selector, attribute string) (string, error) {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	var value string
 
 	err := nav.WaitForElement(selector, nav.Timeout)
@@ -111,13 +176,14 @@ func (nav *Navigator) GetElementAttribute(selector, attribute string) (string, e
 		chromedp.AttributeValue(selector, attribute, &value, nil),
 	)
 	if err != nil {
-		return "", fmt.Errorf("error getting attribute %s: %v", attribute, err)
+		return "", fmt.Errorf("error getting attribute %s: %w", attribute, classifyError(err))
 	}
 	return value, nil
 }
 
 // SwitchToFrame switches the context to the specified iframe.
 func (nav *Navigator) SwitchToFrame(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Println("Switching to frame", selector)
 
 	// Wait for the iframe to be visible
@@ -134,13 +200,13 @@ func (nav *Navigator) SwitchToFrame(selector string) error {
 				var iframe = document.querySelector('%s');
 				iframe.contentWindow.document.body.innerHTML`, selector), &res).Do(ctx)
 			if err != nil {
-				return fmt.Errorf("failed to switch to iframe: %v", err)
+				return fmt.Errorf("failed to switch to iframe: %w", classifyError(err))
 			}
 			return nil
 		}),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to switch to iframe: %v", err)
+		return fmt.Errorf("failed to switch to iframe: %w", classifyError(err))
 	}
 
 	nav.Logger.Println("SwitchToFrame", selector, "successfully")
@@ -158,33 +224,55 @@ func (nav *Navigator) SwitchToDefaultContent() error {
 				// Evaluate JavaScript to switch back to the top window
 				err := chromedp.Evaluate(`window.top.location.reload()`, nil).Do(ctx)
 				if err != nil {
-					return fmt.Errorf("failed to switch to default content: %v", err)
+					return fmt.Errorf("failed to switch to default content: %w", classifyError(err))
 				}
 				return nil
 			}),
 		},
 	)
 	if err != nil {
-		return fmt.Errorf("failed to switch to default content: %v", err)
+		return fmt.Errorf("failed to switch to default content: %w", classifyError(err))
 	}
 
 	nav.Logger.Println("Switch to default content successfully")
 	return nil
 }
 
-// OpenURL opens the specified URL in the current browser context.
+// OpenURL opens the specified URL in the current browser context. When nav.Cache is set, a fresh cached copy is loaded
+// instead and pages opened from the network are cached. The hooks of nav.Middleware run around it.
 // Example:
 //
 //	err := nav.OpenURL("https://www.example.com")
 func (nav *Navigator) OpenURL(url string) error {
+	_, err := nav.navigate(url, false)
+	return err
+}
+
+// openURL opens the url without the hooks of nav.Middleware.
+func (nav *Navigator) openURL(url string) error {
 	nav.Logger.Printf("Opening URL: %s\n", url)
+	if nav.Cache != nil {
+		if body, ok := nav.Cache.Get(url); ok {
+			err := nav.openCached(url, body)
+			if err != nil {
+				nav.Logger.Printf("Error - Failed to open cached URL: %v\n", err)
+				return fmt.Errorf("error - failed to open cached URL: %w", withKind(ErrNavigationFailed, classifyError(err)))
+			}
+			nav.Logger.Printf("URL opened from cache with URL: %s\n", url)
+			return nil
+		}
+	}
+
 	err := chromedp.Run(nav.Ctx,
-		chromedp.Navigate(url),
-		chromedp.WaitReady("body"), // Ensures the page is fully loaded
+		nav.startProxyAuth(),
+		withTimeout(nav.GetTimeouts().Navigation,
+			chromedp.Navigate(url),
+			chromedp.WaitReady("body"), // Ensures the page is fully loaded
+		),
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to open URL: %v\n", err)
-		return fmt.Errorf("error - failed to open URL: %v", err)
+		return fmt.Errorf("error - failed to open URL: %w", withKind(ErrNavigationFailed, classifyError(err)))
 	}
 
 	_, err = nav.WaitPageLoad()
@@ -192,6 +280,13 @@ func (nav *Navigator) OpenURL(url string) error {
 		return err
 	}
 
+	if nav.Cache != nil {
+		err = nav.cachePage(url)
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to cache page: %v\n", err)
+		}
+	}
+
 	nav.Logger.Printf("URL opened successfully with URL: %s\n", url)
 	return nil
 }
@@ -208,7 +303,7 @@ func (nav *Navigator) GetCurrentURL() (string, error) {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to extract current URL: %v\n", err)
-		return "", fmt.Errorf("error - failed to extract current URL: %v", err)
+		return "", fmt.Errorf("error - failed to extract current URL: %w", classifyError(err))
 	}
 	nav.Logger.Println("Current URL extracted successfully")
 	return currentURL, nil
@@ -225,26 +320,30 @@ func (nav *Navigator) Login(url, username, password, usernameSelector, passwordS
 		err := nav.OpenURL(url)
 		if err != nil {
 			nav.Logger.Printf("Error - Failed to open URL: %v\n", err)
-			return fmt.Errorf("error - failed to open URL: %v", err)
+			return fmt.Errorf("error - failed to open URL: %w", classifyError(err))
 		}
 	}
 
+	usernameSelector = nav.firstMatch(usernameSelector, nav.Timeout)
+	passwordSelector = nav.firstMatch(passwordSelector, nav.Timeout)
+	loginButtonSelector = nav.firstMatch(loginButtonSelector, nav.Timeout)
+
 	err := nav.WaitForElement(usernameSelector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = nav.WaitForElement(passwordSelector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = nav.WaitForElement(loginButtonSelector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -258,20 +357,20 @@ func (nav *Navigator) Login(url, username, password, usernameSelector, passwordS
 			err = nav.WaitForElement(messageFailedSuccess, nav.Timeout)
 			if err != nil {
 				nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-				return fmt.Errorf("error - failed waiting for element: %v", err)
+				return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 			}
 			message, err := nav.GetElement(messageFailedSuccess)
 			if err == nil {
 				nav.Logger.Printf("Error - Failed to log in: %v\n", err)
 				nav.Logger.Printf("Message found: %s", message)
-				return fmt.Errorf("error - message: %v", message)
+				return nav.loginError(fmt.Errorf("error - message: %v", message))
 			} else {
 				nav.Logger.Printf("Message was not found")
-				return fmt.Errorf("error - failed to log in: %v", err)
+				return nav.loginError(fmt.Errorf("error - failed to log in: %w", classifyError(err)))
 			}
 		}
 		nav.Logger.Printf("Error - Failed to log in: %v\n", err)
-		return fmt.Errorf("error - failed to log in: %v", err)
+		return nav.loginError(fmt.Errorf("error - failed to log in: %w", classifyError(err)))
 	}
 
 	//sometimes the page does accept the login information but still returns a error message
@@ -281,10 +380,10 @@ func (nav *Navigator) Login(url, username, password, usernameSelector, passwordS
 			message, err := nav.GetElement(messageFailedSuccess)
 			if err == nil {
 				nav.Logger.Printf("Message found: %s", message)
-				return fmt.Errorf("error - message: %v", message)
+				return nav.loginError(fmt.Errorf("error - message: %v", message))
 			} else {
 				nav.Logger.Printf("Message was not found")
-				return fmt.Errorf("error - failed to log in: %v", err)
+				return nav.loginError(fmt.Errorf("error - failed to log in: %w", classifyError(err)))
 			}
 		}
 	}
@@ -296,16 +395,16 @@ func (nav *Navigator) Login(url, username, password, usernameSelector, passwordS
 // LoginAccountsGoogle performs the Google login on the given URL
 func (nav *Navigator) LoginAccountsGoogle(email, password string) error {
 	nav.Logger.Printf("Opening URL: %s\n", "accounts.google.com")
-	err := chromedp.Run(nav.Ctx, chromedp.Navigate("https://accounts.google.com"))
+	err := chromedp.Run(nav.Ctx, nav.startProxyAuth(), chromedp.Navigate("https://accounts.google.com"))
 	if err != nil {
 		nav.Logger.Printf("Failed to open URL: %v\n", err)
-		return fmt.Errorf("failed to open URL: %v", err)
+		return fmt.Errorf("failed to open URL: %w", classifyError(err))
 	}
 
 	_, err = nav.WaitPageLoad()
 	if err != nil {
 		nav.Logger.Printf("Failed to WaitPageLoad: %v\n", err)
-		return fmt.Errorf("failed to WaitPageLoad: %v", err)
+		return fmt.Errorf("failed to WaitPageLoad: %w", classifyError(err))
 	}
 	time.Sleep(300 * time.Millisecond)
 
@@ -316,12 +415,12 @@ func (nav *Navigator) LoginAccountsGoogle(email, password string) error {
 		err = nav.WaitForElement("#yDmH0d > c-wiz > div > div:nth-child(2) > div > c-wiz > c-wiz > div > div.s7iwrf.gMPiLc.Kdcijb > div > div > header > h1", nav.Timeout)
 		if err != nil {
 			nav.Logger.Printf("Error - Failed to log in: %v\n", err)
-			return fmt.Errorf("error - failed to check login: %v", err)
+			return fmt.Errorf("error - failed to check login: %w", classifyError(err))
 		} else {
 			s, err := nav.GetElement("#yDmH0d > c-wiz > div > div:nth-child(2) > div > c-wiz > c-wiz > div > div.s7iwrf.gMPiLc.Kdcijb > div > div > header > h1")
 			if err != nil {
 				nav.Logger.Printf("Error - Failed to log in: %v\n", err)
-				return fmt.Errorf("error - failed to check login: %v", err)
+				return fmt.Errorf("error - failed to check login: %w", classifyError(err))
 			}
 			nav.Logger.Printf("Already logged in! \n%s", s)
 			return nil
@@ -331,34 +430,34 @@ func (nav *Navigator) LoginAccountsGoogle(email, password string) error {
 	err = nav.ClickButton(`#identifierNext`)
 	if err != nil {
 		nav.Logger.Printf("Failed to click the 'Next' button: %v\n", err)
-		return fmt.Errorf("failed to click the 'Next' button: %v", err)
+		return fmt.Errorf("failed to click the 'Next' button: %w", classifyError(err))
 	}
 
 	// Adding a small delay to allow the next page to load
 	_, err = nav.WaitPageLoad()
 	if err != nil {
 		nav.Logger.Printf("Failed to WaitPageLoad: %v\n", err)
-		return fmt.Errorf("failed to WaitPageLoad: %v", err)
+		return fmt.Errorf("failed to WaitPageLoad: %w", classifyError(err))
 	}
 	time.Sleep(2 * time.Second)
 
 	err = nav.FillField("#password > div.aCsJod.oJeWuf > div > div.Xb9hP > input", password)
 	if err != nil {
 		nav.Logger.Printf("Failed to fill the password field: %v\n", err)
-		return fmt.Errorf("failed to fill the password field: %v", err)
+		return fmt.Errorf("failed to fill the password field: %w", classifyError(err))
 	}
 
 	err = nav.ClickButton(`#passwordNext`)
 	if err != nil {
 		nav.Logger.Printf("Failed to click the 'Next' button for password: %v\n", err)
-		return fmt.Errorf("failed to click the 'Next' button for password: %v", err)
+		return fmt.Errorf("failed to click the 'Next' button for password: %w", classifyError(err))
 	}
 
 	// Adding a small delay to allow the next page to load
 	_, err = nav.WaitPageLoad()
 	if err != nil {
 		nav.Logger.Printf("Failed to WaitPageLoad: %v\n", err)
-		return fmt.Errorf("failed to WaitPageLoad: %v", err)
+		return fmt.Errorf("failed to WaitPageLoad: %w", classifyError(err))
 	}
 	time.Sleep(2 * time.Second)
 
@@ -368,7 +467,7 @@ func (nav *Navigator) LoginAccountsGoogle(email, password string) error {
 	err = nav.FillField("#idvPin", authCode)
 	if err != nil {
 		nav.Logger.Printf("Failed to fill the idvPin with code: %s\n field: %v\n", authCode, err)
-		return fmt.Errorf("failed to fill the idvPin with code: %s\n field: %v\n", authCode, err)
+		return fmt.Errorf("failed to fill the idvPin with code: %s\n field: %w\n", authCode, classifyError(err))
 	}
 
 	nav.Logger.Println("Google login completed successfully")
@@ -379,57 +478,34 @@ func (nav *Navigator) LoginAccountsGoogle(email, password string) error {
 func (nav *Navigator) LoginWithGoogle(url string) error {
 	nav.Logger.Printf("Opening URL: %s\n", url)
 	err := chromedp.Run(nav.Ctx,
+		nav.startProxyAuth(),
 		chromedp.Navigate(url),
 	)
 	if err != nil {
 		nav.Logger.Printf("Failed to open URL: %v\n", err)
-		return fmt.Errorf("failed to open URL: %v", err)
+		return fmt.Errorf("failed to open URL: %w", classifyError(err))
 	}
 
 	nav.Logger.Println("Clicking the 'Continuar com o Google' button")
-	err = nav.ClickButton(".SocialButton")
-	if err != nil {
-		nav.Logger.Printf("Alredy logged in: %v\n", err)
+	var clickErr error
+	newNav, err := nav.WaitForPopup(10*time.Second, func() error {
+		clickErr = nav.ClickButton(".SocialButton")
+		return clickErr
+	})
+	if clickErr != nil {
+		nav.Logger.Printf("Alredy logged in: %v\n", clickErr)
 		return nil
 		//nav.Logger.Printf("Failed to click the Google login button: %v\n", err)
-		//return fmt.Errorf("failed to click the Google login button: %v", err)
+		//return fmt.Errorf("failed to click the Google login button: %w", classifyError(err))
 	}
-
-	// Wait for the popup to appear and switch to it
-	nav.Logger.Println("Switching to the Google login popup")
-	var popupCtx context.Context
-	var popupCancel context.CancelFunc
-	for {
-		select {
-		case <-time.After(1 * time.Second):
-			targets, _ := chromedp.Targets(nav.Ctx)
-			if len(targets) > 1 {
-				for _, t := range targets {
-					if t.Type == "page" && t.TargetID != chromedp.FromContext(nav.Ctx).Target.TargetID {
-						popupCtx, popupCancel = chromedp.NewContext(nav.Ctx, chromedp.WithTargetID(targets[1].TargetID))
-						break
-					}
-				}
-			}
-		case <-time.After(10 * time.Second):
-			nav.Logger.Println("Failed to detect the Google login popup")
-			return fmt.Errorf("failed to detect the Google login popup")
-		}
-		if popupCtx != nil {
-			break
-		}
+	if err != nil {
+		nav.Logger.Println("Failed to detect the Google login popup")
+		return withKind(ErrLoginFailed, fmt.Errorf("failed to detect the Google login popup: %w", err))
 	}
 
-	// Ensure the popup context is cancelled after use
-	defer popupCancel()
-
-	// Create a new logger for the popup context
-	popupLogger := log.New(os.Stdout, "popup: ", log.LstdFlags)
-	newNav := Navigator{
-		Ctx:    popupCtx,
-		Cancel: popupCancel,
-		Logger: popupLogger,
-	}
+	// Ensure the popup is closed after use
+	defer newNav.Close()
+	newNav.Logger = log.New(os.Stdout, "popup: ", log.LstdFlags)
 
 	// Log the current URL of the popup
 	currentURL, err := newNav.GetCurrentURL()
@@ -442,30 +518,26 @@ func (nav *Navigator) LoginWithGoogle(url string) error {
 	// Check if the popup navigated to the Google login page
 	if !strings.Contains(currentURL, "accounts.google.com") {
 		nav.Logger.Printf("Popup did not navigate to Google login page, current URL: %s\n", currentURL)
-		return fmt.Errorf("popup did not navigate to Google login page")
+		return withKind(ErrNavigationFailed, fmt.Errorf("popup did not navigate to Google login page"))
 	}
 
-	// Increase the timeout for filling the form fields
-	popupCtx, popupCancel = context.WithTimeout(popupCtx, nav.Timeout)
-	defer popupCancel()
-
 	// Fill the Google login form
 	err = newNav.ClickElement("#container")
 	if err != nil {
 		nav.Logger.Printf("Failed to click element: %v\n", err)
-		return fmt.Errorf("failed to click element: %v", err)
+		return fmt.Errorf("failed to click element: %w", classifyError(err))
 	}
 
 	_, err = newNav.WaitPageLoad()
 	if err != nil {
 		nav.Logger.Printf("Failed to WaitPageLoad: %v\n", err)
-		return fmt.Errorf("failed to WaitPageLoad: %v", err)
+		return fmt.Errorf("failed to WaitPageLoad: %w", classifyError(err))
 	}
 
 	err = newNav.ClickButton("#credentials-picker > div.fFW7wc-ibnC6b-sM5MNb.TAKBxb")
 	if err != nil {
 		nav.Logger.Printf("Failed to click button: %v\n", err)
-		return fmt.Errorf("failed to click button: %v", err)
+		return fmt.Errorf("failed to click button: %w", classifyError(err))
 	}
 
 	newNav.Logger.Println("Google login completed successfully")
@@ -496,17 +568,32 @@ func (nav *Navigator) CaptureScreenshot(nameFile string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to capture screenshot: %v\n", err)
-		return fmt.Errorf("error - failed to capture screenshot: %v", err)
+		return fmt.Errorf("error - failed to capture screenshot: %w", classifyError(err))
 	}
 	err = ioutil.WriteFile(nameFile+"_screenshot.png", buf, 0644)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to save screenshot: %v\n", err)
-		return fmt.Errorf("error - failed to save screenshot: %v", err)
+		return fmt.Errorf("error - failed to save screenshot: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Screenshot saved successfully with name: %s\n", nameFile)
 	return nil
 }
 
+// ElementScreenshot captures a PNG screenshot of the element matched by selector, such as a CAPTCHA image.
+// Example:
+//
+//	image, err := nav.ElementScreenshot("#imagemCaptcha")
+func (nav *Navigator) ElementScreenshot(selector string) ([]byte, error) {
+	selector = nav.firstMatch(selector, nav.Timeout)
+	var buf []byte
+	err := chromedp.Run(nav.Ctx, chromedp.Screenshot(selector, &buf, chromedp.NodeVisible))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to capture element screenshot: %v\n", err)
+		return nil, fmt.Errorf("error - failed to capture element screenshot: %w", classifyError(err))
+	}
+	return buf, nil
+}
+
 // ReloadPage reloads the current page with retry logic
 // retryCount: number of times to retry reloading the page in case of failure
 // Returns an error if any
@@ -515,7 +602,7 @@ func (nav *Navigator) ReloadPage(retryCount int) error {
 	for i := 0; i < retryCount; i++ {
 		nav.Logger.Printf("Attempt %d: Reloading the page\n", i+1)
 		err = chromedp.Run(nav.Ctx,
-			chromedp.Reload(),
+			withTimeout(nav.GetTimeouts().Navigation, chromedp.Reload()),
 		)
 		if err == nil {
 			nav.Logger.Println("Page reloaded successfully")
@@ -525,19 +612,20 @@ func (nav *Navigator) ReloadPage(retryCount int) error {
 		time.Sleep(2 * time.Second)
 	}
 	nav.Logger.Printf("Error - Failed to reload page after %d attempts: %v\n", retryCount, err)
-	return fmt.Errorf("error - failed to reload page after %d attempts: %v", retryCount, err)
+	return fmt.Errorf("error - failed to reload page after %d attempts: %w", retryCount, classifyError(err))
 }
 
 // WaitPageLoad waits for the current page to fully load by checking the document.readyState property
-// It will retry until the page is fully loaded or the timeout of one minute is reached
+// It will retry until the page is fully loaded or the navigation timeout is reached, see SetTimeouts
 // Returns the page readyState as a string and an error if any
 func (nav *Navigator) WaitPageLoad() (string, error) {
 	start := time.Now()
+	timeout := nav.GetTimeouts().Navigation
 	var pageHTML string
 	for {
-		if time.Since(start) > time.Minute {
+		if time.Since(start) > timeout {
 			nav.Logger.Println("Error - Timeout waiting for page to fully load")
-			return "", fmt.Errorf("error - timeout waiting for page to fully load")
+			return "", fmt.Errorf("error - %w waiting for page to fully load", ErrTimeout)
 		}
 
 		err := chromedp.Run(nav.Ctx,
@@ -545,7 +633,7 @@ func (nav *Navigator) WaitPageLoad() (string, error) {
 		)
 		if err != nil {
 			nav.Logger.Printf("Error - Failed to check page readiness: %v\n", err)
-			return "", fmt.Errorf("error - failed to check page readiness: %v", err)
+			return "", fmt.Errorf("error - failed to check page readiness: %w", classifyError(err))
 		}
 
 		if pageHTML == "complete" {
@@ -580,49 +668,221 @@ func (nav *Navigator) GetPageSource() (*html.Node, error) {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to get page HTML: %v\n", err)
-		return nil, fmt.Errorf("error - failed to get page HTML: %v", err)
+		return nil, fmt.Errorf("error - failed to get page HTML: %w", classifyError(err))
 	}
 
 	htmlPgSrc, err := htmlquery.Parse(strings.NewReader(pageHTML))
 	if err != nil {
 		nav.Logger.Printf("Error - failed to convert page HTML: %v", err)
-		return nil, fmt.Errorf("error - failed to convert page HTML: %v", err)
+		return nil, fmt.Errorf("error - failed to convert page HTML: %w", classifyError(err))
 	}
 
 	nav.Logger.Println("Page HTML retrieved successfully")
 	return htmlPgSrc, nil
 }
 
+// GetPageSourceOf captures only the HTML of the element matching the css selector or xpath, instead of the whole page,
+// which saves memory and time on large pages when only one table or section is needed. The returned node is a document
+// holding the element, so it can be queried with FindNodes, ExtractTable and ExtractText like a full page source.
+// Example:
+//
+//	table, err := nav.GetPageSourceOf("#tabelaTodasMovimentacoes")
+func (nav *Navigator) GetPageSourceOf(selector string) (*html.Node, error) {
+	nav.Logger.Printf("Getting the HTML content of element with selector: %s\n", selector)
+	selector = nav.firstMatch(selector, nav.Timeout)
+
+	var fragment *struct {
+		HTML   string
+		Parent string
+	}
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var el = %s;
+			if (!el) return null;
+			return {HTML: el.outerHTML, Parent: el.parentElement ? el.parentElement.localName : "body"};
+		})()`, jsElement(selector)), &fragment),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get element HTML: %v\n", err)
+		return nil, fmt.Errorf("error - failed to get element HTML: %w", classifyError(err))
+	}
+	if fragment == nil {
+		nav.Logger.Printf("Error - Element not found: %s\n", selector)
+		return nil, fmt.Errorf("error - %w: %s", ErrElementNotFound, selector)
+	}
+
+	// parsing in the context of the parent keeps fragments such as table rows intact
+	parent := &html.Node{Type: html.ElementNode, Data: fragment.Parent, DataAtom: atom.Lookup([]byte(fragment.Parent))}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment.HTML), parent)
+	if err != nil {
+		nav.Logger.Printf("Error - failed to convert element HTML: %v", err)
+		return nil, fmt.Errorf("error - failed to convert element HTML: %w", classifyError(err))
+	}
+	document := &html.Node{Type: html.DocumentNode}
+	for _, node := range nodes {
+		document.AppendChild(node)
+	}
+
+	nav.Logger.Println("Element HTML retrieved successfully")
+	return document, nil
+}
+
 // WaitForElement waits for an element specified by the selector to be visible within the given timeout.
 // Example:
 //
 //	err := nav.WaitForElement("#elementID", 5*time.Second)
 func (nav *Navigator) WaitForElement(selector string, timeout time.Duration) error {
+	selector = nav.firstMatch(selector, timeout)
 	nav.Logger.Printf("Waiting for element with selector: %s to be visible\n", selector)
-	ctx, cancel := context.WithTimeout(nav.Ctx, timeout)
-	defer cancel()
-	err := chromedp.Run(ctx,
-		chromedp.WaitVisible(selector),
+	err := chromedp.Run(nav.Ctx,
+		withTimeout(timeout, chromedp.WaitVisible(selector)),
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to wait for element: %v\n", err)
-		return fmt.Errorf("error - failed to wait for element: %v", err)
+		return fmt.Errorf("error - failed to wait for element: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Element is now visible with selector: %s\n", selector)
 	return nil
 }
 
+// ElementExists reports whether at least one element matches the css selector or xpath, without waiting for it.
+// Example:
+//
+//	hasCaptcha, err := nav.ElementExists("#captcha")
+func (nav *Navigator) ElementExists(selector string) (bool, error) {
+	count, err := nav.CountElements(selector)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountElements returns the number of elements matching the css selector or xpath, without waiting for them.
+// Example:
+//
+//	rows, err := nav.CountElements("#table > tbody > tr")
+func (nav *Navigator) CountElements(selector string) (int, error) {
+	return nav.countElements(nav.firstMatch(selector, 0))
+}
+
+// countElements counts the elements matching a single css selector or xpath.
+func (nav *Navigator) countElements(selector string) (int, error) {
+	var count int
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(jsElements(selector)+".length", &count),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to count elements: %v\n", err)
+		return 0, fmt.Errorf("error - failed to count elements: %w", classifyError(err))
+	}
+	return count, nil
+}
+
+// ElementBox holds the geometry and visibility state of an element. Coordinates are in CSS pixels relative to the viewport.
+type ElementBox struct {
+	X          float64
+	Y          float64
+	Width      float64
+	Height     float64
+	Visible    bool   // not hidden through display, visibility or opacity and has a size
+	InViewport bool   // at least partially inside the viewport
+	Covered    bool   // another node is on top of the element center
+	CoveredBy  string // tag, id and classes of the covering node
+}
+
+// GetElementBox returns the bounding rect of the element, whether it is visible, inside the viewport and covered by another
+// node, so callers can decide to scroll it into view or wait before clicking.
+// Example:
+//
+//	box, err := nav.GetElementBox("#buttonID")
+//	if err == nil && box.Covered {
+//		fmt.Println("button covered by", box.CoveredBy)
+//	}
+func (nav *Navigator) GetElementBox(selector string) (ElementBox, error) {
+	selector = nav.firstMatch(selector, 0)
+	var box *ElementBox
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var el = %s;
+			if (!el) return null;
+			var rect = el.getBoundingClientRect();
+			var style = window.getComputedStyle(el);
+			var visible = style.display !== "none" && style.visibility !== "hidden" && parseFloat(style.opacity) > 0 && rect.width > 0 && rect.height > 0;
+			var inViewport = rect.bottom > 0 && rect.right > 0 && rect.top < window.innerHeight && rect.left < window.innerWidth;
+			var covered = false, coveredBy = "";
+			if (visible && inViewport) {
+				var top = document.elementFromPoint(rect.left + rect.width / 2, rect.top + rect.height / 2);
+				if (top && top !== el && !el.contains(top)) {
+					covered = true;
+					coveredBy = top.tagName.toLowerCase() + (top.id ? "#" + top.id : "") + (typeof top.className === "string" && top.className.trim() ? "." + top.className.trim().split(/\s+/).join(".") : "");
+				}
+			}
+			return {X: rect.x, Y: rect.y, Width: rect.width, Height: rect.height, Visible: visible, InViewport: inViewport, Covered: covered, CoveredBy: coveredBy};
+		})()`, jsElement(selector)), &box),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get element box: %v\n", err)
+		return ElementBox{}, fmt.Errorf("error - failed to get element box: %w", classifyError(err))
+	}
+	if box == nil {
+		nav.Logger.Printf("Error - Element not found: %s\n", selector)
+		return ElementBox{}, fmt.Errorf("error - %w: %s", ErrElementNotFound, selector)
+	}
+	return *box, nil
+}
+
+// GetComputedStyle returns the computed values of the given CSS properties of an element, or of every property when none
+// is given. It reads state encoded only in styles, such as the color of a status badge.
+// Example:
+//
+//	style, err := nav.GetComputedStyle("#statusBadge", "background-color", "display")
+//	active := style["background-color"] == "rgb(0, 128, 0)"
+func (nav *Navigator) GetComputedStyle(selector string, properties ...string) (map[string]string, error) {
+	selector = nav.firstMatch(selector, 0)
+	if properties == nil {
+		properties = []string{}
+	}
+	names, err := json.Marshal(properties)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to encode properties: %w", classifyError(err))
+	}
+
+	var style map[string]string
+	err = chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var el = %s;
+			if (!el) return null;
+			var computed = window.getComputedStyle(el);
+			var names = %s;
+			if (names.length === 0) names = Array.from(computed);
+			var style = {};
+			names.forEach(function(name) { style[name] = computed.getPropertyValue(name); });
+			return style;
+		})()`, jsElement(selector), names), &style),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get computed style: %v\n", err)
+		return nil, fmt.Errorf("error - failed to get computed style: %w", classifyError(err))
+	}
+	if style == nil {
+		nav.Logger.Printf("Error - Element not found: %s\n", selector)
+		return nil, fmt.Errorf("error - %w: %s", ErrElementNotFound, selector)
+	}
+	return style, nil
+}
+
 // ClickButton clicks a button specified by the selector.
 // Example:
 //
 //	err := nav.ClickButton("#buttonID")
 func (nav *Navigator) ClickButton(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Clicking button with selector: %s\n", selector)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -630,7 +890,7 @@ func (nav *Navigator) ClickButton(selector string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to click button: %v\n", err)
-		return fmt.Errorf("error - failed to click button: %v", err)
+		return fmt.Errorf("error - failed to click button: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Button clicked successfully with selector: %s\n", selector)
 
@@ -650,6 +910,7 @@ func (nav *Navigator) ClickButton(selector string) error {
 //
 //	err := nav.ClickButton("#buttonID")
 func (nav *Navigator) UnsafeClickButton(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Clicking button with selector: %s\n", selector)
 
 	err := chromedp.Run(nav.Ctx,
@@ -657,7 +918,7 @@ func (nav *Navigator) UnsafeClickButton(selector string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to click button: %v\n", err)
-		return fmt.Errorf("error - failed to click button: %v", err)
+		return fmt.Errorf("error - failed to click button: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Button clicked successfully with selector: %s\n", selector)
 
@@ -669,12 +930,13 @@ func (nav *Navigator) UnsafeClickButton(selector string) error {
 //
 //	err := nav.ClickElement("#elementID")
 func (nav *Navigator) ClickElement(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Clicking element with selector: %s\n", selector)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -682,7 +944,7 @@ func (nav *Navigator) ClickElement(selector string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed chromedp.ByID clicking element: %v\n", err)
-		return fmt.Errorf("error - Failed chromedp.ByID chromedp error: %v", err)
+		return fmt.Errorf("error - Failed chromedp.ByID chromedp error: %w", classifyError(err))
 	}
 
 	nav.Logger.Printf("Element clicked with selector: %s\n", selector)
@@ -694,12 +956,13 @@ func (nav *Navigator) ClickElement(selector string) error {
 //
 //	err := nav.CheckRadioButton("#radioButtonID")
 func (nav *Navigator) CheckRadioButton(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Selecting radio button with selector: %s\n", selector)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -707,7 +970,7 @@ func (nav *Navigator) CheckRadioButton(selector string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to select radio button: %v\n", err)
-		return fmt.Errorf("error - failed to select radio button: %v", err)
+		return fmt.Errorf("error - failed to select radio button: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Radio button selected successfully with selector: %s\n", selector)
 	return nil
@@ -718,12 +981,13 @@ func (nav *Navigator) CheckRadioButton(selector string) error {
 //
 //	err := nav.UncheckRadioButton("#checkboxID")
 func (nav *Navigator) UncheckRadioButton(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Unchecking checkbox with selector: %s\n", selector)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -731,7 +995,7 @@ func (nav *Navigator) UncheckRadioButton(selector string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to uncheck radio button: %v\n", err)
-		return fmt.Errorf("error - failed to uncheck radio button: %v", err)
+		return fmt.Errorf("error - failed to uncheck radio button: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Checkbox unchecked successfully with selector: %s\n", selector)
 	return nil
@@ -742,12 +1006,13 @@ func (nav *Navigator) UncheckRadioButton(selector string) error {
 //
 //	err := nav.FillField("#fieldID", "value")
 func (nav *Navigator) FillField(selector string, value string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Filling field with selector: %s\n", selector)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -755,7 +1020,7 @@ func (nav *Navigator) FillField(selector string, value string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to fill field with selector: %v\n", err)
-		return fmt.Errorf("error - failed to fill field with selector: %v", err)
+		return fmt.Errorf("error - failed to fill field with selector: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Field filled with selector: %s\n", selector)
 	return nil
@@ -766,6 +1031,7 @@ func (nav *Navigator) FillField(selector string, value string) error {
 //
 //	err := nav.FillField("#fieldID", "value")
 func (nav *Navigator) UnsafeFillField(selector string, value string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Filling field with selector: %s\n", selector)
 
 	err := chromedp.Run(nav.Ctx,
@@ -773,7 +1039,7 @@ func (nav *Navigator) UnsafeFillField(selector string, value string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to fill field with selector: %v\n", err)
-		return fmt.Errorf("error - failed to fill field with selector: %v", err)
+		return fmt.Errorf("error - failed to fill field with selector: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Field filled with selector: %s\n", selector)
 	return nil
@@ -791,7 +1057,7 @@ func (nav *Navigator) ExtractLinks() ([]string, error) {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to extract links: %v\n", err)
-		return nil, fmt.Errorf("error - failed to extract links: %v", err)
+		return nil, fmt.Errorf("error - failed to extract links: %w", classifyError(err))
 	}
 	nav.Logger.Println("Links extracted successfully")
 	return links, nil
@@ -806,12 +1072,13 @@ func (nav *Navigator) ExtractLinks() ([]string, error) {
 //	}
 //	err := nav.FillForm("#loginForm", formData)
 func (nav *Navigator) FillForm(selector string, data map[string]string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Filling form with selector: %s and data: %v\n", selector, data)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	tasks := []chromedp.Action{
@@ -825,7 +1092,7 @@ func (nav *Navigator) FillForm(selector string, data map[string]string) error {
 	err = chromedp.Run(nav.Ctx, tasks...)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to fill form: %v\n", err)
-		return fmt.Errorf("error - failed to fill form: %v", err)
+		return fmt.Errorf("error - failed to fill form: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Form filled and submitted successfully with selector: %s\n", selector)
 	return nil
@@ -858,7 +1125,7 @@ func (nav *Navigator) HandleAlert() error {
 	err := chromedp.Run(nav.Ctx, chromedp.Sleep(nav.Timeout))
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to handle alert: %v\n", err)
-		return fmt.Errorf("error - failed to handle alert: %v", err)
+		return fmt.Errorf("error - failed to handle alert: %w", classifyError(err))
 	}
 
 	nav.Logger.Println("JavaScript alert accepted successfully")
@@ -870,12 +1137,13 @@ func (nav *Navigator) HandleAlert() error {
 //
 //	err := nav.SelectDropdown("#dropdownID", "optionValue")
 func (nav *Navigator) SelectDropdown(selector, value string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Selecting dropdown option with selector: %s and value: %s\n", selector, value)
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return fmt.Errorf("error - failed waiting for element: %v", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -883,7 +1151,7 @@ func (nav *Navigator) SelectDropdown(selector, value string) error {
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to select dropdown option: %v\n", err)
-		return fmt.Errorf("error - failed to select dropdown option: %v", err)
+		return fmt.Errorf("error - failed to select dropdown option: %w", classifyError(err))
 	}
 	nav.Logger.Println("Dropdown option selected successfully")
 	return nil
@@ -895,11 +1163,11 @@ func (nav *Navigator) SelectDropdown(selector, value string) error {
 func (nav *Navigator) ExecuteScript(script string) error {
 	nav.Logger.Println("Executing script on the page")
 	err := chromedp.Run(nav.Ctx,
-		chromedp.Evaluate(script, nil),
+		withTimeout(nav.GetTimeouts().Script, chromedp.Evaluate(script, nil)),
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to execute script: %v\n", err)
-		return fmt.Errorf("error - failed to execute script: %v", err)
+		return fmt.Errorf("error - failed to execute script: %w", classifyError(err))
 	}
 	nav.Logger.Println("Script executed successfully")
 	return nil
@@ -909,11 +1177,11 @@ func (nav *Navigator) ExecuteScript(script string) error {
 func (nav *Navigator) EvaluateScript(script string) (interface{}, error) {
 	var result interface{}
 	err := chromedp.Run(nav.Ctx,
-		chromedp.Evaluate(script, &result),
+		withTimeout(nav.GetTimeouts().Script, chromedp.Evaluate(script, &result)),
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to evaluate script: %v\n", err)
-		return nil, fmt.Errorf("error - failed to evaluate script: %v", err)
+		return nil, fmt.Errorf("error - failed to evaluate script: %w", classifyError(err))
 	}
 	return result, nil
 }
@@ -923,13 +1191,14 @@ func (nav *Navigator) EvaluateScript(script string) (interface{}, error) {
 //
 //	text, err := nav.GetElement("#elementID")
 func (nav *Navigator) GetElement(selector string) (string, error) {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Getting element with selector: %s\n", selector)
 	var content string
 
 	err := nav.WaitForElement(selector, nav.Timeout)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
-		return "", fmt.Errorf("error - failed waiting for element: %v", err)
+		return "", fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
 	}
 
 	err = chromedp.Run(nav.Ctx,
@@ -937,7 +1206,7 @@ func (nav *Navigator) GetElement(selector string) (string, error) {
 	)
 	if err != nil && err.Error() != "could not find node" {
 		nav.Logger.Printf("Error - Failed to get element: %v\n", err)
-		return "", fmt.Errorf("error - failed to get element: %v", err)
+		return "", fmt.Errorf("error - failed to get element: %w", classifyError(err))
 	}
 	if content == "" {
 		nav.Logger.Printf("Element is empty with selector: %s\n", selector)
@@ -959,6 +1228,7 @@ func (nav *Navigator) GetElement(selector string) (string, error) {
 //
 //	err := nav.SaveImageBase64("#imagemCaptcha", "captcha.png", "data:image/png;base64,")
 func (nav *Navigator) SaveImageBase64(selector, outputPath, prefixClean string) (string, error) {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	var imageData string
 
 	// Run the tasks
@@ -1010,13 +1280,14 @@ func (nav *Navigator) SaveImageBase64(selector, outputPath, prefixClean string)
 
 // MakeElementVisible changes the style display of an element to nil
 func (nav *Navigator) MakeElementVisible(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
 	nav.Logger.Printf("Making CAPTCHA response field with selector: %s visible\n", selector)
 	err := chromedp.Run(nav.Ctx,
 		chromedp.Evaluate(fmt.Sprintf(`document.querySelector('%s').style.display = ""`, selector), nil),
 	)
 	if err != nil {
 		nav.Logger.Printf("Error - Failed to make element visible: %v\n", err)
-		return fmt.Errorf("error - failed to make element visible: %v", err)
+		return fmt.Errorf("error - failed to make element visible: %w", classifyError(err))
 	}
 	nav.Logger.Printf("Element with selector: %s is now visible\n", selector)
 	return nil
@@ -1030,47 +1301,132 @@ func (nav *Navigator) MakeElementVisible(selector string) error {
 //	calendarButtonsTableXpath: the xpath of the days table example: "//*[@id="ui-datepicker-div"]/table/tbody/tr";
 //	calendarButtonTR: the css selector of the days table row, example: "//*[@id="ui-datepicker-div"]/table/tbody/tr"
 func (nav *Navigator) Datepicker(date, calendarButtonSelector, calendarButtonGoBack, calendarButtonsTableXpath, calendarButtonTR string) error {
-	regex := `^(0[1-9]|[12][0-9]|3[01])/(0[1-9]|1[0-2])/[0-9]{4}$`
-	r := regexp.MustCompile(regex)
-	if r.MatchString(date) == false {
-		return errors.New("date does not match with dd/mm/aaaa")
-	}
+	return nav.DatepickerWithLayout(date, "02/01/2006", calendarButtonSelector, calendarButtonGoBack, "", calendarButtonsTableXpath, calendarButtonTR)
+}
 
-	parsedDate, err := time.Parse("02/01/2006", date)
+// DatepickerWithLayout deals with date-picker elements like Datepicker, but parses the date with a custom layout and
+// navigates forward or backward from the current month depending on the date, so future dates can be picked too.
+//
+//	date: the date to pick, formatted according to layout
+//	layout: a time.Parse layout, example: "2006-01-02"
+//	calendarButtonSelector: the css selector of the data-picker
+//	calendarButtonGoBack: the css selector of the go back button
+//	calendarButtonGoForward: the css selector of the go forward button, can be empty if only past dates are picked
+//	calendarButtonsTableXpath: the xpath of the days table example: "//*[@id="ui-datepicker-div"]/table/tbody/tr";
+//	calendarButtonTR: the css selector of the days table row, used on error messages
+//
+// Example:
+//
+//	err := nav.DatepickerWithLayout("2030-12-31", "2006-01-02", "#datepicker", ".ui-datepicker-prev", ".ui-datepicker-next", "//*[@id=\"ui-datepicker-div\"]/table/tbody/tr", "#ui-datepicker-div > table > tbody > tr:nth-child")
+func (nav *Navigator) DatepickerWithLayout(date, layout, calendarButtonSelector, calendarButtonGoBack, calendarButtonGoForward, calendarButtonsTableXpath, calendarButtonTR string) error {
+	parsedDate, err := time.Parse(layout, date)
 	if err != nil {
 		return errors.New("error parsing date: " + err.Error())
 	}
 
-	today := time.Now().Format("02/01/2006")
-	parseToday, err := time.Parse("02/01/2006", today)
+	// The picker opens on the current month, so the direction is given by the sign of the difference
+	months := monthsBetween(time.Now(), parsedDate)
+	button := calendarButtonGoBack
+	if months > 0 {
+		if calendarButtonGoForward == "" {
+			return errors.New("date is after the current month but no go forward button was given")
+		}
+		button = calendarButtonGoForward
+	} else {
+		months = -months
+	}
+
+	err = nav.ClickButton(calendarButtonSelector)
 	if err != nil {
-		return errors.New("error parsing today's date: " + err.Error())
+		return err
 	}
 
-	// Ensure startDate is before endDate
-	if parsedDate.After(parseToday) {
-		return errors.New("date must be older then today")
+	for i := 0; i < months; i++ {
+		err = chromedp.Run(nav.Ctx, chromedp.Click(button))
+		if err != nil {
+			return fmt.Errorf("error - failed to navigate the date-picker after %d of %d clicks: %w", i, months, classifyError(err))
+		}
+	}
+
+	return nav.pickDay(parsedDate, calendarButtonsTableXpath, calendarButtonTR)
+}
+
+// DatepickerDropdown deals with date-picker elements that have month and year <select> controls (common in jQuery UI and Bootstrap pickers),
+// choosing the month and year directly instead of clicking "previous" once per month.
+//
+//	date: the date to pick, formatted according to layout
+//	layout: a time.Parse layout, example: "02/01/2006"
+//	calendarButtonSelector: the css selector of the data-picker
+//	monthSelectSelector: the css selector of the month <select>, options may be valued 0-11 (jQuery UI) or 1-12
+//	yearSelectSelector: the css selector of the year <select>
+//	calendarButtonsTableXpath: the xpath of the days table example: "//*[@id="ui-datepicker-div"]/table/tbody/tr";
+//	calendarButtonTR: the css selector of the days table row, used on error messages
+//
+// Example:
+//
+//	err := nav.DatepickerDropdown("01/01/1990", "02/01/2006", "#datepicker", "select.ui-datepicker-month", "select.ui-datepicker-year", "//*[@id=\"ui-datepicker-div\"]/table/tbody/tr", "#ui-datepicker-div > table > tbody > tr:nth-child")
+func (nav *Navigator) DatepickerDropdown(date, layout, calendarButtonSelector, monthSelectSelector, yearSelectSelector, calendarButtonsTableXpath, calendarButtonTR string) error {
+	parsedDate, err := time.Parse(layout, date)
+	if err != nil {
+		return errors.New("error parsing date: " + err.Error())
 	}
-	years, months, _ := calculateDateDifference(parsedDate, parseToday)
 
 	err = nav.ClickButton(calendarButtonSelector)
 	if err != nil {
 		return err
 	}
 
-	i := 0
-	for {
-		err = chromedp.Run(nav.Ctx, chromedp.Click(calendarButtonGoBack))
-		if err != nil {
-			break
-		}
-		i++
-		if i == ((years * 12) + months) {
-			break
-		}
+	err = nav.WaitForElement(yearSelectSelector, nav.Timeout)
+	if err != nil {
+		return err
 	}
 
-	err = nav.WaitForElement(calendarButtonsTableXpath, time.Minute)
+	// The year goes first because pickers re-render the month options when the year changes
+	err = nav.selectDatepickerOption(yearSelectSelector, parsedDate.Year(), false)
+	if err != nil {
+		return err
+	}
+
+	err = nav.selectDatepickerOption(monthSelectSelector, int(parsedDate.Month()), true)
+	if err != nil {
+		return err
+	}
+
+	return nav.pickDay(parsedDate, calendarButtonsTableXpath, calendarButtonTR)
+}
+
+// selectDatepickerOption selects the option with the given numeric value on a date-picker <select> and dispatches the change event
+// the picker listens to. When isMonth is set and the options start at "0", the value is treated as zero based.
+func (nav *Navigator) selectDatepickerOption(selector string, value int, isMonth bool) error {
+	var selected bool
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var el = %s;
+			if (!el) return false;
+			var opts = Array.from(el.options);
+			var target = %d;
+			if (%t && opts.some(function(o) { return o.value === "0"; })) target = target - 1;
+			var opt = opts.find(function(o) { return parseInt(o.value, 10) === target; });
+			if (!opt) return false;
+			el.value = opt.value;
+			el.dispatchEvent(new Event("change", {bubbles: true}));
+			return true;
+		})()`, jsElement(selector), value, isMonth), &selected),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to select date-picker option: %v\n", err)
+		return fmt.Errorf("error - failed to select date-picker option: %w", classifyError(err))
+	}
+	if !selected {
+		nav.Logger.Printf("Error - Option %d not found on date-picker select: %s\n", value, selector)
+		return withKind(ErrElementNotFound, fmt.Errorf("error - option %d not found on date-picker select: %s", value, selector))
+	}
+	return nil
+}
+
+// pickDay clicks the day of the given date on the days table of an open date-picker.
+func (nav *Navigator) pickDay(date time.Time, calendarButtonsTableXpath, calendarButtonTR string) error {
+	err := nav.WaitForElement(calendarButtonsTableXpath, time.Minute)
 	if err != nil {
 		return err
 	}
@@ -1091,7 +1447,7 @@ func (nav *Navigator) Datepicker(date, calendarButtonSelector, calendarButtonGoB
 			if err != nil {
 				return err
 			}
-			if day == strconv.Itoa(parsedDate.Day()) {
+			if day == strconv.Itoa(date.Day()) {
 				err = nav.ClickButton(calendarButtonsTableXpath + "[" + strconv.Itoa(k+1) + "]/td[" + strconv.Itoa(l) + "]")
 				if err != nil {
 					return errors.New("error clicking button on calendar button: " + calendarButtonTR + "(" + strconv.Itoa(k) + ") > td:nth-child(" + strconv.Itoa(l) + "). Error code: " + err.Error())
@@ -1103,28 +1459,40 @@ func (nav *Navigator) Datepicker(date, calendarButtonSelector, calendarButtonGoB
 		}
 
 	}
-	return errors.New("could not pick date")
+	return withKind(ErrElementNotFound, errors.New("could not pick date"))
 }
-func calculateDateDifference(startDate, endDate time.Time) (int, int, int) {
-	years := endDate.Year() - startDate.Year()
-	months := int(endDate.Month()) - int(startDate.Month())
-	days := endDate.Day() - startDate.Day()
 
-	// Adjust the difference if necessary
-	if days < 0 {
-		// Borrow days from the previous month
-		months--
-		previousMonth := endDate.AddDate(0, -1, 0)
-		days += previousMonth.Day()
-	}
+// monthsBetween returns the number of calendar months from start to end, negative when end is before start.
+func monthsBetween(start, end time.Time) int {
+	return (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+}
 
-	if months < 0 {
-		// Borrow months from the previous year
-		years--
-		months += 12
+// jsString quotes s as a JavaScript string literal, so selectors and values can be safely embedded in scripts.
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// jsElement returns a JavaScript expression that evaluates to the first element matching a css selector or, when the
+// selector starts like an xpath ("/" or "("), to the first node matching the xpath.
+func jsElement(selector string) string {
+	if strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "(") {
+		return fmt.Sprintf(`document.evaluate(%s, document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue`, jsString(selector))
 	}
+	return fmt.Sprintf(`document.querySelector(%s)`, jsString(selector))
+}
 
-	return years, months, days
+// jsElements returns a JavaScript expression that evaluates to an array of all elements matching a css selector or xpath.
+func jsElements(selector string) string {
+	if strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "(") {
+		return fmt.Sprintf(`(function() {
+			var result = document.evaluate(%s, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			var nodes = [];
+			for (var i = 0; i < result.snapshotLength; i++) nodes.push(result.snapshotItem(i));
+			return nodes;
+		})()`, jsString(selector))
+	}
+	return fmt.Sprintf(`Array.from(document.querySelectorAll(%s))`, jsString(selector))
 }
 
 // ParseHtmlToString used for parsing html.node into string for debugging purposes
@@ -1143,6 +1511,15 @@ func ParseHtmlToString(pageSource *html.Node) (string, error) {
 //	nav.Close()
 func (nav *Navigator) Close() {
 	// nav.Logger.Println("Closing the Navigator instance")
+	if nav.Locators != nil {
+		err := nav.Locators.Save()
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to save locators: %v\n", err)
+		}
+	}
+	if nav.cassette != nil {
+		_ = nav.EjectCassette()
+	}
 	nav.Cancel()
 	nav.Logger.Println("Navigator instance closed successfully")
 }
@@ -1150,13 +1527,28 @@ func (nav *Navigator) Close() {
 // Request structure to hold user data
 type Request struct {
 	SearchString string
+	ID           string      // identifies the request in its PageSource, e.g. the key of the source record
+	Payload      interface{} // structured input of the crawler, see ParallelRequestsWithPayload
 }
 
 // PageSource structure to hold the HTML data
 type PageSource struct {
-	Page    *html.Node
-	Request string
-	Error   error
+	Page     *html.Node
+	Request  string
+	ID       string      // the ID of the request
+	Payload  interface{} // the Payload of the request
+	Error    error
+	Language string      // ISO 639-1 code of the language of the page, see DetectLanguage
+	Data     interface{} // the data extracted from the page, see ExtractorRegistry.Middleware
+}
+
+// Retry returns the request of the page source, with its ID and Payload, to crawl it again, e.g. in the evaluate
+// function of EvaluateParallelRequests.
+// Example:
+//
+//	newRequests = append(newRequests, result.Retry())
+func (p PageSource) Retry() Request {
+	return Request{SearchString: p.Request, ID: p.ID, Payload: p.Payload}
 }
 
 // RemovePageSource removes the element at index `s` from a slice of `PageSource` objects.
@@ -1188,6 +1580,40 @@ func RemoveRequest(slice []Request, s int) []Request {
 //
 // results, err := ParallelRequests(requests, numberOfWorkers, delay, crawlerFunc)
 func ParallelRequests(requests []Request, numberOfWorkers int, delay time.Duration, crawlerFunc func(string) (*html.Node, error)) ([]PageSource, error) {
+	return parallelRequests(requests, numberOfWorkers, delay, searchCrawler(crawlerFunc), nil)
+}
+
+// ParallelRequestsWithPayload is ParallelRequests with a crawlerFunc receiving the whole Request, for crawlers that
+// need structured input, such as several form fields or credentials per request. The ID and Payload of each request
+// are carried to its PageSource, so results can be correlated back to their source records.
+// Example:
+//
+//	type Search struct {
+//		Court, Number string
+//	}
+//	requests := []goSpider.Request{
+//		{ID: "row-1", Payload: Search{Court: "TJSP", Number: "1017927-35.2023.8.26.0008"}},
+//	}
+//	results, err := goSpider.ParallelRequestsWithPayload(requests, 2, time.Second, func(req goSpider.Request) (*html.Node, error) {
+//		search := req.Payload.(Search)
+//		...
+//	})
+//	for _, result := range results {
+//		fmt.Println(result.ID, result.Error)
+//	}
+func ParallelRequestsWithPayload(requests []Request, numberOfWorkers int, delay time.Duration, crawlerFunc func(Request) (*html.Node, error)) ([]PageSource, error) {
+	return parallelRequests(requests, numberOfWorkers, delay, crawlerFunc, nil)
+}
+
+// searchCrawler adapts a crawlerFunc of search strings to the crawlers of parallelRequests.
+func searchCrawler(crawlerFunc func(string) (*html.Node, error)) func(Request) (*html.Node, error) {
+	return func(req Request) (*html.Node, error) {
+		return crawlerFunc(req.SearchString)
+	}
+}
+
+// parallelRequests runs ParallelRequests with the request and result hooks of the chain, which may be nil.
+func parallelRequests(requests []Request, numberOfWorkers int, delay time.Duration, crawlerFunc func(Request) (*html.Node, error), chain MiddlewareChain) ([]PageSource, error) {
 	done := make(chan struct{})
 	defer close(done)
 
@@ -1204,12 +1630,7 @@ func ParallelRequests(requests []Request, numberOfWorkers int, delay time.Durati
 			for req := range inputCh {
 				log.Printf("Worker %d processing request: %s", workerID, req.SearchString)
 				time.Sleep(delay)
-				pageSource, err := crawlerFunc(req.SearchString)
-				resultCh <- PageSource{
-					Page:    pageSource,
-					Request: req.SearchString,
-					Error:   err,
-				}
+				resultCh <- chain.crawl(crawlerFunc, req, delay)
 			}
 		}(i)
 	}
@@ -1225,6 +1646,9 @@ func ParallelRequests(requests []Request, numberOfWorkers int, delay time.Durati
 	var errorOnApiRequests error
 
 	for result := range resultCh {
+		if !chain.result(&result) {
+			continue
+		}
 		if result.Error != nil {
 			errorOnApiRequests = result.Error
 		}
@@ -1234,6 +1658,18 @@ func ParallelRequests(requests []Request, numberOfWorkers int, delay time.Durati
 	return results, errorOnApiRequests
 }
 
+// newPageSource returns the result of crawling the request.
+func newPageSource(req Request, page *html.Node, err error) PageSource {
+	return PageSource{
+		Page:     page,
+		Request:  req.SearchString,
+		ID:       req.ID,
+		Payload:  req.Payload,
+		Error:    err,
+		Language: DetectLanguage(page),
+	}
+}
+
 // streamInputs streams the input requests into a channel.
 //
 // Parameters:
@@ -1262,7 +1698,8 @@ func streamInputs(done <-chan struct{}, requests []Request) <-chan Request {
 }
 
 // EvaluateParallelRequests iterates over a set of previous results, evaluates them using the provided evaluation function,
-// and handles re-crawling of problematic sources until all sources are valid or no further progress can be made.
+// and handles re-crawling of problematic sources until all sources are valid or DefaultRounds re-crawls were made, with
+// DefaultWorkers workers and no delay. See EvaluateParallelRequestsWithOptions to configure the re-crawls.
 //
 // Parameters:
 // - previousResults: A slice of PageSource objects containing the initial crawl results.
@@ -1273,7 +1710,8 @@ func streamInputs(done <-chan struct{}, requests []Request) <-chan Request {
 //
 // Returns:
 // - A slice of valid PageSource objects after all problematic sources have been re-crawled and evaluated.
-// - An error if there is a failure in the crawling process.
+// - An error if there is a failure in the crawling process, or ErrRetriesExhausted, with the valid results, if some
+// sources are still problematic after the last round.
 //
 // Example usage:
 //
@@ -1286,7 +1724,7 @@ func streamInputs(done <-chan struct{}, requests []Request) <-chan Request {
 //		for _, result := range previousResults {
 //			_, err := extractDataCover(result.Page, "")
 //			if err != nil {
-//				newRequests = append(newRequests, Request{SearchString: result.Request})
+//				newRequests = append(newRequests, result.Retry())
 //			} else {
 //				validResults = append(validResults, result)
 //			}
@@ -1295,22 +1733,67 @@ func streamInputs(done <-chan struct{}, requests []Request) <-chan Request {
 //		return newRequests, validResults
 //	}
 func EvaluateParallelRequests(previousResults []PageSource, crawlerFunc func(string) (*html.Node, error), evaluate func([]PageSource) ([]Request, []PageSource)) ([]PageSource, error) {
-	for {
+	results, failing, err := EvaluateParallelRequestsWithOptions(previousResults, crawlerFunc, evaluate, EvaluateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(failing) > 0 {
+		return results, withKind(ErrRetriesExhausted, fmt.Errorf("error - %d requests still failing after %d rounds", len(failing), DefaultRounds))
+	}
+	return results, nil
+}
+
+// EvaluateOptions configures the re-crawls of EvaluateParallelRequestsWithOptions.
+type EvaluateOptions struct {
+	Workers   int           // workers of each re-crawl; DefaultWorkers if not positive
+	Delay     time.Duration // delay before each request, see ParallelRequests
+	MaxRounds int           // re-crawls before giving up; DefaultRounds if not positive
+	Backoff   time.Duration // wait before the first re-crawl, doubled before each of the next ones; none if zero
+}
+
+// EvaluateParallelRequestsWithOptions evaluates the previous results like EvaluateParallelRequests, re-crawling the
+// problematic sources with the given workers and delay, waiting the backoff between rounds, for at most MaxRounds
+// rounds. It returns the valid results and the requests still failing after the last round, which are nil when every
+// source became valid.
+// Example:
+//
+//	results, failing, err := goSpider.EvaluateParallelRequestsWithOptions(resultsFirst, Crawler, Eval,
+//		goSpider.EvaluateOptions{Workers: 2, Delay: time.Second, MaxRounds: 3, Backoff: 5 * time.Second})
+//	for _, request := range failing {
+//		log.Printf("Giving up on %s", request.SearchString)
+//	}
+func EvaluateParallelRequestsWithOptions(previousResults []PageSource, crawlerFunc func(string) (*html.Node, error), evaluate func([]PageSource) ([]Request, []PageSource), options EvaluateOptions) ([]PageSource, []Request, error) {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	maxRounds := options.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = DefaultRounds
+	}
+
+	backoff := options.Backoff
+	for round := 1; ; round++ {
 		problematicPageSources, newResults := evaluate(previousResults)
 		if len(problematicPageSources) == 0 {
-			return newResults, nil
+			return newResults, nil, nil
+		}
+		if round > maxRounds {
+			log.Printf("Giving up on %d problematic sources after %d rounds", len(problematicPageSources), maxRounds)
+			return newResults, problematicPageSources, nil
 		}
 
-		log.Printf("Crawling %d problematic sources", len(problematicPageSources))
-		temporaryResults, err := ParallelRequests(problematicPageSources, 10, 0, crawlerFunc)
+		if backoff > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		log.Printf("Crawling %d problematic sources, round %d of %d", len(problematicPageSources), round, maxRounds)
+		temporaryResults, err := ParallelRequests(problematicPageSources, workers, options.Delay, crawlerFunc)
 		if err != nil {
-			return nil, fmt.Errorf("failed to crawl page sources, error: %s", err)
+			return nil, nil, fmt.Errorf("failed to crawl page sources, error: %w", err)
 		}
 
-		previousResults = newResults
-		for _, tempResult := range temporaryResults {
-			previousResults = append(previousResults, tempResult)
-		}
+		previousResults = append(newResults, temporaryResults...)
 	}
 }
 
@@ -1319,16 +1802,17 @@ func EvaluateParallelRequests(previousResults []PageSource, crawlerFunc func(str
 //
 //	tableData, err := goSpider.ExtractTableData(pageSource,"#tableID")
 func ExtractTable(pageSource *html.Node, tableRowsExpression string) ([]*html.Node, error) {
+	tableRowsExpression = firstExpressionMatch(pageSource, tableRowsExpression)
 	log.Printf("Extracting table data with selector: %s\n", tableRowsExpression)
 	rows, err := htmlquery.Find(pageSource, tableRowsExpression)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract table data, error: %s", err)
+		return nil, fmt.Errorf("failed to extract table data, error: %w", err)
 	}
 	if len(rows) > 0 {
 		return rows, nil
 	}
 	// log.Printf("Table data extracted successfully")
-	return nil, errors.New("could not find any table rows")
+	return nil, withKind(ErrElementNotFound, errors.New("could not find any table rows"))
 }
 
 // ExtractText extracts text content from nodes specified by the parent selectors.
@@ -1336,11 +1820,12 @@ func ExtractTable(pageSource *html.Node, tableRowsExpression string) ([]*html.No
 //
 //	textData, err := goSpider.ExtractText(pageSource,"#parent1", "\n")
 func ExtractText(node *html.Node, nodeExpression string, Dirt string) (string, error) {
+	nodeExpression = firstExpressionMatch(node, nodeExpression)
 	//log.Print("Extracting text from node")
 	var text string
 	tt, err := htmlquery.Find(node, nodeExpression)
 	if err != nil {
-		return "", fmt.Errorf("failed to extract text, error: %s", err)
+		return "", fmt.Errorf("failed to extract text, error: %w", err)
 	}
 	if len(tt) > 0 {
 		text = strings.TrimSpace(strings.Replace(htmlquery.InnerText(htmlquery.FindOne(node, nodeExpression)), Dirt, "", -1))
@@ -1348,7 +1833,7 @@ func ExtractText(node *html.Node, nodeExpression string, Dirt string) (string, e
 	}
 
 	//log.Printf("Text %v extracted successfully from node", nodeExpression)
-	return "", errors.New("could not find specified text")
+	return "", withKind(ErrElementNotFound, errors.New("could not find specified text"))
 }
 
 // FindNodes extracts nodes content from nodes specified by the parent selectors.
@@ -1356,12 +1841,23 @@ func ExtractText(node *html.Node, nodeExpression string, Dirt string) (string, e
 //
 //	nodeData, err := goSpider.FindNode(pageSource,"#parent1")
 func FindNodes(node *html.Node, nodeExpression string) ([]*html.Node, error) {
+	nodeExpression = firstExpressionMatch(node, nodeExpression)
 	n, err := htmlquery.Find(node, nodeExpression)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find nodes, error: %s", err)
+		return nil, fmt.Errorf("failed to find nodes, error: %w", err)
 	}
 	if len(n) > 0 {
 		return n, nil
 	}
-	return nil, errors.New("could not find specified node")
+	return nil, withKind(ErrElementNotFound, errors.New("could not find specified node"))
+}
+
+// nodeAttribute returns the value of the attribute of n, or an empty string when it is not set.
+func nodeAttribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
 }
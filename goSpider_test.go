@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -218,6 +219,48 @@ func TestGetPageSource(t *testing.T) {
 	}
 }
 
+func TestGetPageSourceOf(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/dynamic.html?rows=3&delay=10")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	err = nav.WaitForNode("#done", 2*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForNode error: %v", err)
+	}
+
+	table, err := nav.GetPageSourceOf("#dynamicTable")
+	if err != nil {
+		t.Fatalf("GetPageSourceOf error: %v", err)
+	}
+	rows, err := ExtractTable(table, "//table[@id='dynamicTable']/tbody/tr")
+	if err != nil || len(rows) != 3 {
+		t.Errorf("Expected 3 rows in the extracted table, got %d: %v", len(rows), err)
+	}
+	if _, err := FindNodes(table, "//div[@id='status']"); err == nil {
+		t.Errorf("Expected only the table subtree")
+	}
+
+	// fragments that need their parent, like table rows, are kept intact
+	row, err := nav.GetPageSourceOf("//table[@id='dynamicTable']/tbody/tr[2]")
+	if err != nil {
+		t.Fatalf("GetPageSourceOf error: %v", err)
+	}
+	text, err := ExtractText(row, "//tr/td", "")
+	if err != nil || text != "Row 2" {
+		t.Errorf("Expected the second row, got %q: %v", text, err)
+	}
+
+	_, err = nav.GetPageSourceOf("#missing")
+	if err == nil {
+		t.Errorf("Expected an error for a missing element")
+	}
+}
+
 func TestWaitForElement(t *testing.T) {
 	server := startTestServer()
 	defer server.Close()
@@ -231,6 +274,128 @@ func TestWaitForElement(t *testing.T) {
 	}
 }
 
+func TestElementExists(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/test.html")
+
+	exists, err := nav.ElementExists("#divInfraCaptcha")
+	if err != nil {
+		t.Fatalf("ElementExists error: %v", err)
+	}
+	if !exists {
+		t.Errorf("Expected #divInfraCaptcha to exist")
+	}
+
+	exists, err = nav.ElementExists("//*[@id=\"missingElement\"]")
+	if err != nil {
+		t.Fatalf("ElementExists error: %v", err)
+	}
+	if exists {
+		t.Errorf("Expected missing element to not exist")
+	}
+}
+
+func TestCountElements(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/test.html")
+
+	count, err := nav.CountElements("input[name=\"search\"]")
+	if err != nil {
+		t.Fatalf("CountElements error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 radio buttons, but got %d", count)
+	}
+
+	count, err = nav.CountElements("//*[@id=\"cbPesquisa\"]/option")
+	if err != nil {
+		t.Fatalf("CountElements error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 options, but got %d", count)
+	}
+}
+
+func TestGetElementBox(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	box, err := nav.GetElementBox("#visibleBox")
+	if err != nil {
+		t.Fatalf("GetElementBox error: %v", err)
+	}
+	if box.X != 20 || box.Y != 10 || box.Width != 100 || box.Height != 50 || !box.Visible || !box.InViewport || box.Covered {
+		t.Errorf("Unexpected box for #visibleBox: %+v", box)
+	}
+
+	box, err = nav.GetElementBox("#hiddenBox")
+	if err != nil {
+		t.Fatalf("GetElementBox error: %v", err)
+	}
+	if box.Visible {
+		t.Errorf("Expected #hiddenBox to be invisible: %+v", box)
+	}
+
+	box, err = nav.GetElementBox("#coveredBox")
+	if err != nil {
+		t.Fatalf("GetElementBox error: %v", err)
+	}
+	if !box.Covered || box.CoveredBy != "div#overlay.modal.backdrop" {
+		t.Errorf("Expected #coveredBox to be covered by the overlay: %+v", box)
+	}
+
+	box, err = nav.GetElementBox("#farBox")
+	if err != nil {
+		t.Fatalf("GetElementBox error: %v", err)
+	}
+	if !box.Visible || box.InViewport {
+		t.Errorf("Expected #farBox to be visible outside the viewport: %+v", box)
+	}
+
+	_, err = nav.GetElementBox("#missingElement")
+	if err == nil {
+		t.Errorf("Expected error for a missing element")
+	}
+}
+
+func TestGetComputedStyle(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	style, err := nav.GetComputedStyle("#activeBadge", "background-color", "display")
+	if err != nil {
+		t.Fatalf("GetComputedStyle error: %v", err)
+	}
+	if style["background-color"] != "rgb(0, 128, 0)" || style["display"] != "inline-block" {
+		t.Errorf("Unexpected style for #activeBadge: %v", style)
+	}
+
+	style, err = nav.GetComputedStyle("//*[@id=\"inactiveBadge\"]")
+	if err != nil {
+		t.Fatalf("GetComputedStyle error: %v", err)
+	}
+	if style["background-color"] != "rgb(255, 0, 0)" || style["visibility"] != "hidden" {
+		t.Errorf("Unexpected style for #inactiveBadge: %v", style)
+	}
+
+	_, err = nav.GetComputedStyle("#missingElement", "color")
+	if err == nil {
+		t.Errorf("Expected error for a missing element")
+	}
+}
+
 func TestClickButton(t *testing.T) {
 	server := startTestServer()
 	defer server.Close()
@@ -666,6 +831,76 @@ func TestDatepicker(t *testing.T) {
 	}
 }
 
+func TestMonthsBetween(t *testing.T) {
+	cases := []struct {
+		start, end string
+		expected   int
+	}{
+		{"15/10/2026", "20/09/2026", -1},
+		{"15/10/2026", "01/01/2000", -321},
+		{"15/10/2026", "31/10/2026", 0},
+		{"15/10/2026", "01/02/2027", 4},
+	}
+	for _, c := range cases {
+		start, _ := time.Parse("02/01/2006", c.start)
+		end, _ := time.Parse("02/01/2006", c.end)
+		if m := monthsBetween(start, end); m != c.expected {
+			t.Errorf("Expected %d months between %s and %s, but got: %d", c.expected, c.start, c.end, m)
+		}
+	}
+}
+
+func TestDatepickerWithLayout(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/datepicker.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	future := time.Now().AddDate(0, 3, 0)
+	date := time.Date(future.Year(), future.Month(), 15, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	err = nav.DatepickerWithLayout(date, "2006-01-02", "#datepickerButton", ".ui-datepicker-prev", ".ui-datepicker-next", "//*[@id=\"ui-datepicker-div\"]/table/tbody/tr", "#ui-datepicker-div > table > tbody > tr:nth-child")
+	if err != nil {
+		t.Fatalf("DatepickerWithLayout error: %v", err)
+	}
+
+	value, err := nav.EvaluateScript("document.querySelector('#datepicker').value")
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	expected := "15/" + future.Format("01/2006")
+	if value != expected {
+		t.Errorf("Expected date %s, but got: %v", expected, value)
+	}
+}
+
+func TestDatepickerDropdown(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/datepicker.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	err = nav.DatepickerDropdown("21/03/1995", "02/01/2006", "#datepickerButton", "select.ui-datepicker-month", "select.ui-datepicker-year", "//*[@id=\"ui-datepicker-div\"]/table/tbody/tr", "#ui-datepicker-div > table > tbody > tr:nth-child")
+	if err != nil {
+		t.Fatalf("DatepickerDropdown error: %v", err)
+	}
+
+	value, err := nav.EvaluateScript("document.querySelector('#datepicker').value")
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	if value != "21/03/1995" {
+		t.Errorf("Expected date 21/03/1995, but got: %v", value)
+	}
+}
+
 // Won't pass on test because 2FA requires input on the terminal by the user, for that reason alone the test will fail
 //// TestLoginGoogle tests google single logon
 //func TestLoginGoogle(t *testing.T) {
@@ -780,6 +1015,88 @@ func TestRequestsDataStruct(t *testing.T) {
 
 }
 
+func TestParallelRequestsWithPayload(t *testing.T) {
+	type search struct {
+		Court, Number string
+	}
+	requests := []Request{
+		{ID: "row-1", Payload: search{Court: "TJSP", Number: "1017927-35.2023.8.26.0008"}},
+		{ID: "row-2", Payload: search{Court: "TJRJ", Number: "0002396-75.2013.8.26.0201"}},
+	}
+	results, err := ParallelRequestsWithPayload(requests, 2, 0, func(req Request) (*html.Node, error) {
+		s := req.Payload.(search)
+		return &html.Node{Type: html.TextNode, Data: s.Court + " " + s.Number}, nil
+	})
+	if err != nil {
+		t.Fatalf("ParallelRequestsWithPayload error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, but got %d", len(results))
+	}
+	for _, result := range results {
+		s := result.Payload.(search)
+		if result.Page.Data != s.Court+" "+s.Number {
+			t.Errorf("Result %s does not match its payload: %q", result.ID, result.Page.Data)
+		}
+		if retry := result.Retry(); retry.ID != result.ID || retry.Payload != result.Payload {
+			t.Errorf("Expected the retry of %s to keep its ID and payload, but got %+v", result.ID, retry)
+		}
+	}
+}
+
+func TestEvaluateParallelRequestsWithOptions(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	crawler := func(search string) (*html.Node, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[search]++
+		return &html.Node{Type: html.TextNode, Data: fmt.Sprint(attempts[search])}, nil
+	}
+	// "flaky" is valid from its second crawl, "broken" never is
+	evaluate := func(results []PageSource) ([]Request, []PageSource) {
+		var requests []Request
+		var valid []PageSource
+		for _, result := range results {
+			if result.Request == "broken" || result.Request == "flaky" && result.Page.Data == "0" {
+				requests = append(requests, Request{SearchString: result.Request})
+			} else {
+				valid = append(valid, result)
+			}
+		}
+		return requests, valid
+	}
+	initial := []PageSource{
+		{Request: "ok", Page: &html.Node{Data: "0"}},
+		{Request: "flaky", Page: &html.Node{Data: "0"}},
+		{Request: "broken", Page: &html.Node{Data: "0"}},
+	}
+
+	start := time.Now()
+	results, failing, err := EvaluateParallelRequestsWithOptions(initial, crawler, evaluate,
+		EvaluateOptions{Workers: 2, MaxRounds: 3, Backoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("EvaluateParallelRequestsWithOptions error: %v", err)
+	}
+	if len(results) != 2 || len(failing) != 1 || failing[0].SearchString != "broken" {
+		t.Errorf("Expected ok and flaky valid and broken failing, but got %v and %v", results, failing)
+	}
+	if attempts["broken"] != 3 || attempts["flaky"] != 1 {
+		t.Errorf("Expected 3 rounds for broken and 1 for flaky, but got %v", attempts)
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("Expected backoffs of 10, 20 and 40ms, but took %v", elapsed)
+	}
+
+	results, err = EvaluateParallelRequests(initial, crawler, evaluate)
+	if !errors.Is(err, ErrRetriesExhausted) || len(results) != 2 {
+		t.Errorf("Expected ErrRetriesExhausted with the valid results, but got %v: %v", len(results), err)
+	}
+	if attempts["broken"] != 3+DefaultRounds {
+		t.Errorf("Expected %d more rounds, but got %d", DefaultRounds, attempts["broken"]-3)
+	}
+}
+
 func Eval(previousResults []PageSource) ([]Request, []PageSource) {
 	var newRequests []Request
 	var validResults []PageSource
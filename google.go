@@ -0,0 +1,207 @@
+package goSpider
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceAccount authenticates to Google APIs with the key of a service account, exchanging signed JWTs for access
+// tokens. Tokens are cached until shortly before they expire. It is safe for concurrent use.
+type ServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+	Client      *http.Client
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	tokens map[string]cachedToken // by scopes
+}
+
+// cachedToken is an access token with its expiration.
+type cachedToken struct {
+	token   string
+	expires time.Time
+}
+
+// LoadServiceAccount reads the JSON key file of a service account, as downloaded from the Google Cloud console.
+// Example:
+//
+//	account, err := goSpider.LoadServiceAccount("service-account.json")
+func LoadServiceAccount(path string) (*ServiceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read service account: %w", err)
+	}
+	account := &ServiceAccount{}
+	err = json.Unmarshal(data, account)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to parse service account: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("error - service account without client_email or private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	account.Client = &http.Client{Timeout: 30 * time.Second}
+	return account, nil
+}
+
+// Token returns an access token for the scopes, e.g. "https://www.googleapis.com/auth/spreadsheets".
+func (a *ServiceAccount) Token(scopes ...string) (string, error) {
+	scope := strings.Join(scopes, " ")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.tokens[scope]; ok && time.Now().Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	if a.key == nil {
+		key, err := parsePrivateKey(a.PrivateKey)
+		if err != nil {
+			return "", err
+		}
+		a.key = key
+	}
+	now := time.Now()
+	assertion, err := signJWT(a.key, map[string]interface{}{
+		"iss":   a.ClientEmail,
+		"scope": scope,
+		"aud":   a.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.PostForm(a.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error - failed to request access token: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("error - failed to read access token: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error_description"`
+	}
+	err = json.Unmarshal(body, &token)
+	if err != nil || response.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("error - failed to get access token: status %d %s", response.StatusCode, token.Error)
+	}
+
+	if a.tokens == nil {
+		a.tokens = map[string]cachedToken{}
+	}
+	// renew a minute before the token expires
+	a.tokens[scope] = cachedToken{token: token.AccessToken, expires: now.Add(time.Duration(token.ExpiresIn-60) * time.Second)}
+	return token.AccessToken, nil
+}
+
+// do sends a request authenticated for the scope with payload as JSON body, and decodes the JSON response into out
+// when it is not nil. Responses with a status other than 2xx return a StatusError.
+func (a *ServiceAccount) do(client *http.Client, scope, method, endpoint string, payload interface{}, out interface{}) error {
+	token, err := a.Token(scope)
+	if err != nil {
+		return err
+	}
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error - failed to encode payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("error - failed to create request: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &StatusError{StatusCode: response.StatusCode, URL: endpoint}
+	}
+	if out == nil {
+		return nil
+	}
+	err = json.NewDecoder(response.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("error - failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS#8 or PKCS#1 RSA private key.
+func parsePrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, fmt.Errorf("error - invalid private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error - invalid private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("error - private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// signJWT returns the claims as a JWT signed with RS256.
+func signJWT(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("error - failed to encode claims: %w", err)
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("error - failed to sign token: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
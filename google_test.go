@@ -0,0 +1,100 @@
+package goSpider
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestServiceAccount writes a service account key file using tokenURI and returns its path and key.
+func newTestServiceAccount(t *testing.T, tokenURI string) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "crawler@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, key
+}
+
+// tokenHandler answers token requests after checking the JWT signature and counts them.
+func tokenHandler(key **rsa.PrivateKey, requests *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || len(parts) != 3 {
+			http.Error(w, `{"error_description":"invalid grant"}`, http.StatusBadRequest)
+			return
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&(*key).PublicKey, crypto.SHA256, sum[:], signature) != nil {
+			http.Error(w, `{"error_description":"invalid signature"}`, http.StatusBadRequest)
+			return
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		json.Unmarshal(payload, &claims)
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600,"scope":%q}`, *requests, claims["scope"])
+	}
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	var key *rsa.PrivateKey
+	requests := 0
+	server := httptest.NewServer(tokenHandler(&key, &requests))
+	defer server.Close()
+
+	path, generated := newTestServiceAccount(t, server.URL)
+	key = generated
+	account, err := LoadServiceAccount(path)
+	if err != nil {
+		t.Fatalf("LoadServiceAccount error: %v", err)
+	}
+
+	token, err := account.Token(SheetsScope)
+	if err != nil || token != "token-1" {
+		t.Fatalf("Expected token-1, got %q: %v", token, err)
+	}
+	token, err = account.Token(SheetsScope)
+	if err != nil || token != "token-1" || requests != 1 {
+		t.Errorf("Expected the cached token, got %q after %d requests: %v", token, requests, err)
+	}
+	token, err = account.Token("https://www.googleapis.com/auth/pubsub")
+	if err != nil || token != "token-2" {
+		t.Errorf("Expected a new token for another scope, got %q: %v", token, err)
+	}
+
+	// a key the server doesn't know is rejected
+	key, _ = rsa.GenerateKey(rand.Reader, 1024)
+	account, _ = LoadServiceAccount(path)
+	_, err = account.Token(SheetsScope)
+	if err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("Expected an invalid signature error, got %v", err)
+	}
+}
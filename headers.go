@@ -0,0 +1,32 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// SetExtraHeaders sends the headers with every request the page makes afterwards, such as Authorization, Referer or
+// the custom headers some anti-bot checks expect, on top of the headers of the browser. Each call replaces the headers
+// set before; calling it with an empty map stops sending them.
+// Example:
+//
+//	err := nav.SetExtraHeaders(map[string]string{"Authorization": "Bearer " + token, "Referer": "https://www.example.com/"})
+//	err = nav.OpenURL("https://www.example.com/api/lawsuits")
+func (nav *Navigator) SetExtraHeaders(headers map[string]string) error {
+	extra := make(network.Headers, len(headers))
+	for name, value := range headers {
+		extra[name] = value
+	}
+	err := chromedp.Run(nav.Ctx, network.Enable(), network.SetExtraHTTPHeaders(extra))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to set extra headers: %v\n", err)
+		return fmt.Errorf("error - failed to set extra headers: %w", classifyError(err))
+	}
+	if len(headers) == 0 {
+		nav.Logger.Printf("Stopped sending extra headers\n")
+		return nil
+	}
+	nav.Logger.Printf("Sending %d extra headers\n", len(headers))
+	return nil
+}
@@ -0,0 +1,47 @@
+package goSpider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetExtraHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><p id="auth">` + r.Header.Get("Authorization") + `</p><p id="token">` +
+			r.Header.Get("X-Api-Token") + `</p></body></html>`))
+	}))
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.SetExtraHeaders(map[string]string{"Authorization": "Bearer s3cret", "X-Api-Token": "1017927"})
+	if err != nil {
+		t.Fatalf("SetExtraHeaders error: %v", err)
+	}
+	err = nav.OpenURL(server.URL)
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	auth, err := nav.GetElement("#auth")
+	if err != nil || auth != "Bearer s3cret" {
+		t.Errorf("Expected the Authorization header, got %q: %v", auth, err)
+	}
+	token, err := nav.GetElement("#token")
+	if err != nil || token != "1017927" {
+		t.Errorf("Expected the custom header, got %q: %v", token, err)
+	}
+
+	err = nav.SetExtraHeaders(nil)
+	if err != nil {
+		t.Fatalf("SetExtraHeaders error: %v", err)
+	}
+	err = nav.OpenURL(server.URL)
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	auth, err = nav.GetElement("#auth")
+	if err != nil || auth != "" {
+		t.Errorf("Expected no Authorization header after clearing, got %q: %v", auth, err)
+	}
+}
@@ -0,0 +1,136 @@
+package goSpider
+
+import (
+	"github.com/DanielFillol/goSpider/browsers"
+	"github.com/chromedp/chromedp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// HeadlessMode selects how Chrome runs when a Navigator is headless, see WithHeadlessMode.
+type HeadlessMode int
+
+const (
+	// HeadlessDefault runs Chrome with the --headless flag, whose meaning depends on the Chrome version.
+	HeadlessDefault HeadlessMode = iota
+	// HeadlessNew runs full Chrome with --headless=new, which behaves like a headful browser and is the hardest to
+	// tell apart from one.
+	HeadlessNew
+	// HeadlessShell runs the chrome-headless-shell binary, a lightweight build of Chrome for automation that uses less
+	// memory per Navigator, which adds up in large pools. It falls back to HeadlessDefault when the binary isn't found,
+	// see FindHeadlessShell.
+	HeadlessShell
+)
+
+// headlessShellEnv is the environment variable with the path of the chrome-headless-shell binary.
+const headlessShellEnv = "CHROME_HEADLESS_SHELL"
+
+// WithHeadlessMode selects the headless implementation used when the Navigator is headless; it has no effect on
+// headful Navigators.
+// Example:
+//
+//	nav := goSpider.NewNavigator("", true, goSpider.WithHeadlessMode(goSpider.HeadlessShell))
+//	pool := goSpider.NewNavigatorPool(goSpider.PoolOptions{Size: 16, Headless: true,
+//		Options: []goSpider.NavigatorOption{goSpider.WithHeadlessMode(goSpider.HeadlessShell)}})
+func WithHeadlessMode(mode HeadlessMode) NavigatorOption {
+	return func(config *navigatorConfig) {
+		config.headlessMode = mode
+	}
+}
+
+// WithExecPath runs the Chrome binary at path instead of the Chrome installed on the host, e.g. a pinned build installed
+// by the browsers package. With HeadlessShell, path is used as the chrome-headless-shell binary.
+// Example:
+//
+//	path, err := browsers.Install(browsers.Chrome, "")
+//	nav := goSpider.NewNavigator("", true, goSpider.WithExecPath(path))
+func WithExecPath(path string) NavigatorOption {
+	return func(config *navigatorConfig) {
+		config.execPath = path
+	}
+}
+
+// FindHeadlessShell returns the path of the chrome-headless-shell binary: the CHROME_HEADLESS_SHELL environment
+// variable, the chrome-headless-shell command in PATH, or the latest version installed by
+// "npx @puppeteer/browsers install chrome-headless-shell" in the working directory, by Puppeteer or Playwright in the
+// user cache or by the browsers package.
+// Example:
+//
+//	if path, ok := goSpider.FindHeadlessShell(); ok {
+//		fmt.Println("chrome-headless-shell found at", path)
+//	}
+func FindHeadlessShell() (string, bool) {
+	if path := os.Getenv(headlessShellEnv); path != "" {
+		return path, isExecutable(path)
+	}
+	for _, name := range []string{"chrome-headless-shell", "headless_shell"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, true
+		}
+	}
+
+	binary := "chrome-headless-shell"
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	patterns := []string{filepath.Join("chrome-headless-shell", "*", "chrome-headless-shell-*", binary)}
+	if cache, err := os.UserCacheDir(); err == nil {
+		patterns = append(patterns,
+			filepath.Join(cache, "puppeteer", "chrome-headless-shell", "*", "chrome-headless-shell-*", binary),
+			filepath.Join(cache, "ms-playwright", "chromium_headless_shell-*", "*", "headless_shell"))
+	}
+	if dir, err := browsers.DefaultDir(); err == nil {
+		patterns = append(patterns,
+			filepath.Join(dir, string(browsers.HeadlessShell), "*", "*", "chrome-headless-shell-*", binary))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		patterns = append(patterns,
+			filepath.Join(home, ".cache", "puppeteer", "chrome-headless-shell", "*", "chrome-headless-shell-*", binary))
+	}
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		// try the latest version first
+		sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+		for _, match := range matches {
+			if isExecutable(match) {
+				return match, true
+			}
+		}
+	}
+	return "", false
+}
+
+// headlessOptions returns the allocator options of a headless mode, and the chrome-headless-shell binary used, if any:
+// execPath when set.
+func headlessOptions(mode HeadlessMode, execPath string) ([]chromedp.ExecAllocatorOption, string) {
+	switch mode {
+	case HeadlessNew:
+		return []chromedp.ExecAllocatorOption{chromedp.Flag("headless", "new")}, ""
+	case HeadlessShell:
+		path, ok := execPath, execPath != ""
+		if !ok {
+			path, ok = FindHeadlessShell()
+		}
+		if !ok {
+			return nil, ""
+		}
+		// the shell only runs headless, in the old headless mode
+		return []chromedp.ExecAllocatorOption{
+			chromedp.ExecPath(path),
+			chromedp.Flag("headless", true),
+		}, path
+	}
+	return nil, ""
+}
+
+// isExecutable reports whether path is a regular file that can be executed.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
@@ -0,0 +1,67 @@
+package goSpider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindHeadlessShell(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "chrome-headless-shell")
+	err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(headlessShellEnv, binary)
+	path, ok := FindHeadlessShell()
+	if !ok || path != binary {
+		t.Errorf("Expected the binary of the environment variable, got %q %v", path, ok)
+	}
+	t.Setenv(headlessShellEnv, filepath.Join(dir, "missing"))
+	if _, ok := FindHeadlessShell(); ok {
+		t.Errorf("Expected a missing binary not to be found")
+	}
+
+	t.Setenv(headlessShellEnv, "")
+	t.Setenv("PATH", dir)
+	path, ok = FindHeadlessShell()
+	if !ok || path != binary {
+		t.Errorf("Expected the binary in PATH, got %q %v", path, ok)
+	}
+}
+
+func TestHeadlessOptions(t *testing.T) {
+	config := &navigatorConfig{}
+	WithHeadlessMode(HeadlessNew)(config)
+	if config.headlessMode != HeadlessNew {
+		t.Errorf("Expected the mode to be set")
+	}
+	if options, shell := headlessOptions(HeadlessNew, ""); len(options) != 1 || shell != "" {
+		t.Errorf("Expected the new headless flag only")
+	}
+	if options, _ := headlessOptions(HeadlessDefault, ""); len(options) != 0 {
+		t.Errorf("Expected no options for the default mode")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "chrome-headless-shell")
+	err := os.WriteFile(binary, []byte("#!/bin/sh\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(headlessShellEnv, binary)
+	if options, shell := headlessOptions(HeadlessShell, ""); len(options) != 2 || shell != binary {
+		t.Errorf("Expected the shell binary, got %q", shell)
+	}
+	t.Setenv(headlessShellEnv, filepath.Join(dir, "missing"))
+	if options, shell := headlessOptions(HeadlessShell, ""); len(options) != 0 || shell != "" {
+		t.Errorf("Expected a fallback to the default mode")
+	}
+
+	WithExecPath(binary)(config)
+	if options, shell := headlessOptions(HeadlessShell, config.execPath); len(options) != 2 || shell != binary {
+		t.Errorf("Expected the exec path as the shell binary, got %q", shell)
+	}
+}
@@ -0,0 +1,213 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"os"
+	"sync"
+	"time"
+)
+
+// Locators are the alternative ways to find an element, recorded when its selector matched.
+type Locators struct {
+	ID       string
+	Name     string
+	Text     string
+	XPath    string // relative to the closest ancestor with an id
+	Recorded time.Time
+}
+
+// HealingEvent records a selector that failed and the recorded alternative used instead.
+type HealingEvent struct {
+	Selector string
+	Healed   string
+	Time     time.Time
+}
+
+// LocatorStore keeps the recorded Locators by selector and the healing events of a Navigator.
+// It is saved as JSON so locators recorded in one run heal the selectors of the next ones.
+type LocatorStore struct {
+	mu       sync.Mutex
+	path     string
+	Locators map[string]Locators
+	Events   []HealingEvent
+}
+
+// NewLocatorStore creates a LocatorStore saved at path, loading the locators already recorded there.
+// An empty path keeps the locators in memory only.
+// Example:
+//
+//	store, err := goSpider.NewLocatorStore("locators.json")
+func NewLocatorStore(path string) (*LocatorStore, error) {
+	store := &LocatorStore{path: path, Locators: map[string]Locators{}}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read locator store: %w", classifyError(err))
+	}
+	err = json.Unmarshal(data, &store.Locators)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to decode locator store: %w", classifyError(err))
+	}
+	return store, nil
+}
+
+// Save writes the recorded locators to the store path. It does nothing for in-memory stores.
+func (s *LocatorStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.Locators, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error - failed to encode locator store: %w", classifyError(err))
+	}
+	err = os.WriteFile(s.path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to save locator store: %w", classifyError(err))
+	}
+	return nil
+}
+
+// Get returns the locators recorded for selector.
+func (s *LocatorStore) Get(selector string) (Locators, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	locators, ok := s.Locators[selector]
+	return locators, ok
+}
+
+// Set records the locators of selector.
+func (s *LocatorStore) Set(selector string, locators Locators) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Locators[selector] = locators
+}
+
+// HealingEvents returns a copy of the healing events so far.
+func (s *LocatorStore) HealingEvents() []HealingEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]HealingEvent(nil), s.Events...)
+}
+
+func (s *LocatorStore) addEvent(event HealingEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Events = append(s.Events, event)
+}
+
+// EnableSelfHealing turns on selector auto-healing: every time a selector matches, the id, name, text and relative XPath
+// of the element are recorded; when the selector later stops matching, the recorded alternatives are tried and the one
+// used is logged as a healing event. The locators are saved at path when the Navigator is closed.
+// Example:
+//
+//	err := nav.EnableSelfHealing("locators.json")
+func (nav *Navigator) EnableSelfHealing(path string) error {
+	store, err := NewLocatorStore(path)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to enable self-healing: %v\n", err)
+		return err
+	}
+	nav.Locators = store
+	nav.Logger.Printf("Self-healing enabled with %d recorded selectors\n", len(store.Locators))
+	return nil
+}
+
+// heal waits for selector like matchSelector; when it matches its locators are recorded, otherwise the recorded
+// alternatives are tried in order.
+func (nav *Navigator) heal(selector string, timeout time.Duration) (string, bool, error) {
+	matched, ok, err := nav.observeSelector(selector, timeout)
+	if err != nil {
+		return matched, false, err
+	}
+	if ok {
+		locators, err := nav.captureLocators(matched)
+		if err == nil {
+			nav.Locators.Set(selector, locators)
+		}
+		return matched, true, nil
+	}
+
+	locators, recorded := nav.Locators.Get(selector)
+	if !recorded {
+		return matched, false, nil
+	}
+	for _, alternative := range locators.alternatives() {
+		count, err := nav.countElements(alternative)
+		if err != nil {
+			return matched, false, err
+		}
+		if count > 0 {
+			nav.Logger.Printf("Healing - selector %s did not match, using recorded locator %s\n", selector, alternative)
+			nav.Locators.addEvent(HealingEvent{Selector: selector, Healed: alternative, Time: time.Now()})
+			return alternative, true, nil
+		}
+	}
+	return matched, false, nil
+}
+
+// alternatives returns the recorded locators as selectors, the most specific first.
+func (l Locators) alternatives() []string {
+	var alternatives []string
+	if l.ID != "" {
+		alternatives = append(alternatives, fmt.Sprintf("//*[@id=%s]", xpathLiteral(l.ID)))
+	}
+	if l.Name != "" {
+		alternatives = append(alternatives, fmt.Sprintf("//*[@name=%s]", xpathLiteral(l.Name)))
+	}
+	if l.XPath != "" {
+		alternatives = append(alternatives, l.XPath)
+	}
+	if l.Text != "" {
+		alternatives = append(alternatives, textXPath(l.Text, TextExact))
+	}
+	return alternatives
+}
+
+// captureLocators records the id, name, short text and relative XPath of the element matching selector.
+func (nav *Navigator) captureLocators(selector string) (Locators, error) {
+	var locators *Locators
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var el = %s;
+			if (!el) return null;
+			var relative = function(el) {
+				var parts = [];
+				for (; el && el.nodeType === 1; el = el.parentNode) {
+					if (el.id && parts.length > 0) return "//*[@id=" + JSON.stringify(el.id) + "]/" + parts.join("/");
+					var index = 1;
+					for (var sibling = el.previousElementSibling; sibling; sibling = sibling.previousElementSibling) {
+						if (sibling.nodeName === el.nodeName) index++;
+					}
+					parts.unshift(el.nodeName.toLowerCase() + "[" + index + "]");
+				}
+				return "/" + parts.join("/");
+			};
+			var text = (el.innerText || el.value || "").replace(/\s+/g, " ").trim();
+			return {
+				ID: el.id || "",
+				Name: el.getAttribute("name") || "",
+				Text: text.length <= 80 ? text : "",
+				XPath: relative(el)
+			};
+		})()`, jsElement(selector)), &locators),
+	)
+	if err != nil {
+		return Locators{}, err
+	}
+	if locators == nil {
+		return Locators{}, fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	locators.Recorded = time.Now()
+	return *locators, nil
+}
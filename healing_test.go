@@ -0,0 +1,91 @@
+package goSpider
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLocatorStoreSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locators.json")
+
+	store, err := NewLocatorStore(path)
+	if err != nil {
+		t.Fatalf("NewLocatorStore error: %v", err)
+	}
+	store.Set("#consultar", Locators{ID: "consultar", Text: "Consultar"})
+	err = store.Save()
+	if err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, err := NewLocatorStore(path)
+	if err != nil {
+		t.Fatalf("NewLocatorStore error: %v", err)
+	}
+	locators, ok := loaded.Get("#consultar")
+	if !ok || locators.ID != "consultar" || locators.Text != "Consultar" {
+		t.Errorf("Unexpected loaded locators: %+v", locators)
+	}
+}
+
+func TestLocatorsAlternatives(t *testing.T) {
+	locators := Locators{ID: "consultar", Name: "btnConsultar", XPath: "//*[@id=\"form\"]/button[2]", Text: "Consultar"}
+	alternatives := locators.alternatives()
+
+	expected := []string{
+		"//*[@id=\"consultar\"]",
+		"//*[@name=\"btnConsultar\"]",
+		"//*[@id=\"form\"]/button[2]",
+		textXPath("Consultar", TextExact),
+	}
+	if len(alternatives) != len(expected) {
+		t.Fatalf("Expected %d alternatives, but got %q", len(expected), alternatives)
+	}
+	for i := range expected {
+		if alternatives[i] != expected[i] {
+			t.Errorf("Expected alternative %d to be %s, but got %s", i, expected[i], alternatives[i])
+		}
+	}
+}
+
+func TestSelfHealing(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	err := nav.EnableSelfHealing("")
+	if err != nil {
+		t.Fatalf("EnableSelfHealing error: %v", err)
+	}
+
+	err = nav.ClickButton("#consultar")
+	if err != nil {
+		t.Fatalf("ClickButton error: %v", err)
+	}
+	locators, ok := nav.Locators.Get("#consultar")
+	if !ok || locators.ID != "consultar" || locators.XPath != "//*[@id=\"textSection\"]/button[2]" {
+		t.Fatalf("Unexpected recorded locators: %+v", locators)
+	}
+
+	// the site renames the button, the recorded relative xpath still finds it
+	err = nav.ExecuteScript(`document.getElementById("consultar").id = "btnConsultar"; document.getElementById("textResult").innerText = "";`)
+	if err != nil {
+		t.Fatalf("ExecuteScript error: %v", err)
+	}
+
+	err = nav.ClickButton("#consultar")
+	if err != nil {
+		t.Fatalf("ClickButton (healed) error: %v", err)
+	}
+	result, err := nav.GetElement("#textResult")
+	if err != nil || result != "consultar" {
+		t.Errorf("Expected the renamed button to be clicked, but got %q (%v)", result, err)
+	}
+
+	events := nav.Locators.HealingEvents()
+	if len(events) != 1 || events[0].Selector != "#consultar" || events[0].Healed != "//*[@id=\"textSection\"]/button[2]" {
+		t.Errorf("Unexpected healing events: %+v", events)
+	}
+}
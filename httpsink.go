@@ -0,0 +1,173 @@
+package goSpider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HTTPSink is a Sink that posts records as JSON to an endpoint: each record as an object when BatchSize is 1, or
+// batches of records as arrays. Failed requests are retried with exponential backoff on network errors, 429 and 5xx
+// statuses, honoring Retry-After. At most MaxInFlight requests run at once, across concurrent writers; Write blocks
+// while the limit is reached, so a slow endpoint slows the crawl down instead of piling up results in memory.
+type HTTPSink struct {
+	URL         string
+	Header      http.Header // extra headers, e.g. Authorization
+	BatchSize   int         // records per request, 1 if not positive
+	MaxRetries  int         // retries of a failed request
+	RetryDelay  time.Duration
+	MaxInFlight int // concurrent requests, 1 if not positive
+	Client      *http.Client
+	Logger      *log.Logger
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+// NewHTTPSink creates an HTTPSink posting each record to url, with 3 retries starting one second apart.
+// Example:
+//
+//	sink := goSpider.NewHTTPSink("https://api.example.com/lawsuits")
+//	sink.Header.Set("Authorization", "Bearer "+os.Getenv("API_TOKEN"))
+//	sink.BatchSize = 50
+//	sink.MaxInFlight = 4
+//	err := sink.Write(records)
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		URL:         url,
+		Header:      http.Header{},
+		BatchSize:   1,
+		MaxRetries:  3,
+		RetryDelay:  time.Second,
+		MaxInFlight: 1,
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		Logger:      log.New(os.Stdout, "goSpider: ", log.LstdFlags),
+	}
+}
+
+// Write posts the records in batches of BatchSize, returning the first error after every batch was tried.
+func (s *HTTPSink) Write(records []Record) error {
+	s.once.Do(func() {
+		inFlight := s.MaxInFlight
+		if inFlight <= 0 {
+			inFlight = 1
+		}
+		s.slots = make(chan struct{}, inFlight)
+	})
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		var payload interface{} = records[start:end]
+		if batchSize == 1 {
+			payload = records[start]
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("error - failed to encode records: %w", err)
+			}
+			mu.Unlock()
+			continue
+		}
+
+		s.slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-s.slots
+				wg.Done()
+			}()
+			err := s.post(body)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// post sends a body, retrying failures that may be temporary.
+func (s *HTTPSink) post(body []byte) error {
+	delay := s.RetryDelay
+	for attempt := 0; ; attempt++ {
+		wait, err := s.send(body)
+		if err == nil {
+			return nil
+		}
+		if wait < 0 || attempt >= s.MaxRetries {
+			s.logf("Error - Failed to post records: %v\n", err)
+			return fmt.Errorf("error - failed to post records: %w", err)
+		}
+		if wait == 0 {
+			wait = delay
+			delay *= 2
+		}
+		s.logf("Failed to post records: %v. Retrying in %v\n", err, wait)
+		time.Sleep(wait)
+	}
+}
+
+// send posts the body once. On failure it returns the time to wait before retrying: 0 for the backoff delay, the
+// Retry-After delay when given, or -1 when the request must not be retried.
+func (s *HTTPSink) send(body []byte) (time.Duration, error) {
+	request, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("error - failed to create request: %w", err)
+	}
+	for name, values := range s.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return 0, nil
+	}
+
+	err = &StatusError{StatusCode: response.StatusCode, URL: s.URL}
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode < 500 {
+		return -1, err
+	}
+	if seconds, parseErr := strconv.Atoi(response.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, err
+	}
+	return 0, err
+}
+
+func (s *HTTPSink) logf(format string, args ...interface{}) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	}
+}
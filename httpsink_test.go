@@ -0,0 +1,99 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPSink(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]Record
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		// the first request fails temporarily
+		if attempts == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var batch []Record
+		json.NewDecoder(r.Body).Decode(&batch)
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	sink.Header.Set("Authorization", "Bearer secret")
+	sink.BatchSize = 2
+	sink.RetryDelay = time.Millisecond
+	err := sink.Write([]Record{{"number": "1"}, {"number": "2"}, {"number": "3"}})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if attempts != 3 || len(batches) != 2 || len(batches[0]) != 2 || batches[1][0]["number"] != "3" {
+		t.Errorf("Expected two batches after a retry, got %v in %d attempts", batches, attempts)
+	}
+
+	// client errors are not retried
+	attempts = 0
+	sink.Header.Set("Authorization", "Bearer wrong")
+	err = sink.Write([]Record{{"number": "4"}})
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected a single failed attempt, got %d: %v", attempts, err)
+	}
+}
+
+func TestHTTPSinkSingleRecords(t *testing.T) {
+	var record Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&record)
+	}))
+	defer server.Close()
+
+	err := NewHTTPSink(server.URL).Write([]Record{{"number": "1"}})
+	if err != nil || record["number"] != "1" {
+		t.Errorf("Expected the record posted as an object, got %v: %v", record, err)
+	}
+}
+
+func TestHTTPSinkBackpressure(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	sink.MaxInFlight = 2
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sink.Write([]Record{{"a": 1.0}, {"b": 2.0}})
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+}
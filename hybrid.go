@@ -0,0 +1,213 @@
+package goSpider
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// FetchMode is how HybridFetcher fetches the pages of a domain.
+type FetchMode int
+
+const (
+	// ModeUnknown is used for domains not fetched yet; they are tried with HTTP first.
+	ModeUnknown FetchMode = iota
+	// ModeHTTP fetches with plain HTTP.
+	ModeHTTP
+	// ModeBrowser renders with the browser.
+	ModeBrowser
+)
+
+func (m FetchMode) String() string {
+	switch m {
+	case ModeHTTP:
+		return "http"
+	case ModeBrowser:
+		return "browser"
+	default:
+		return "unknown"
+	}
+}
+
+// HybridFetcher tries plain HTTP first and escalates to browser rendering when the page needs JavaScript (an empty body
+// filled by scripts, a <noscript> wall or a bot challenge page), remembering the mode of each domain so later requests
+// go straight to it. Browser fetches are serialized, since a single Navigator drives one tab.
+type HybridFetcher struct {
+	HTTP    *HTTPFetcher
+	Browser Fetcher
+
+	mu        sync.Mutex
+	browserMu sync.Mutex
+	modes     map[string]FetchMode
+}
+
+// NewHybridFetcher creates a HybridFetcher that escalates from the HTTP fetcher to the browser fetcher.
+// Example:
+//
+//	fetcher := goSpider.NewHybridFetcher(goSpider.NewHTTPFetcher(30*time.Second), nav)
+//	results, err := goSpider.ParallelRequests(requests, 10, 0, fetcher.Fetch)
+func NewHybridFetcher(httpFetcher *HTTPFetcher, browser Fetcher) *HybridFetcher {
+	return &HybridFetcher{HTTP: httpFetcher, Browser: browser, modes: map[string]FetchMode{}}
+}
+
+// Fetch fetches the url with the mode recorded for its domain, detecting the mode on the first request.
+func (f *HybridFetcher) Fetch(rawURL string) (*html.Node, error) {
+	host := hostOf(rawURL)
+	if f.Mode(host) == ModeBrowser {
+		return f.render(rawURL)
+	}
+
+	pageSource, err := f.HTTP.Fetch(rawURL)
+	var statusError *StatusError
+	if errors.As(err, &statusError) && (statusError.StatusCode == http.StatusForbidden || statusError.StatusCode == http.StatusServiceUnavailable) {
+		f.escalate(host, fmt.Sprintf("status %d", statusError.StatusCode))
+		return f.render(rawURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reason := RequiresBrowser(pageSource); reason != "" {
+		f.escalate(host, reason)
+		return f.render(rawURL)
+	}
+
+	if f.Mode(host) == ModeUnknown {
+		f.SetMode(host, ModeHTTP)
+	}
+	return pageSource, nil
+}
+
+// Mode returns the mode recorded for the host.
+func (f *HybridFetcher) Mode(host string) FetchMode {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.modes[host]
+}
+
+// SetMode records the mode of the host, e.g. to preload modes learned in a previous run.
+func (f *HybridFetcher) SetMode(host string, mode FetchMode) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.modes[host] = mode
+}
+
+// Modes returns a copy of the modes recorded by host.
+func (f *HybridFetcher) Modes() map[string]FetchMode {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	modes := make(map[string]FetchMode, len(f.modes))
+	for host, mode := range f.modes {
+		modes[host] = mode
+	}
+	return modes
+}
+
+func (f *HybridFetcher) escalate(host, reason string) {
+	f.HTTP.Logger.Printf("Escalating %s to browser rendering: %s\n", host, reason)
+	f.SetMode(host, ModeBrowser)
+}
+
+func (f *HybridFetcher) render(rawURL string) (*html.Node, error) {
+	if f.Browser == nil {
+		return nil, fmt.Errorf("error - page requires a browser but none was configured: %s", rawURL)
+	}
+	f.browserMu.Lock()
+	defer f.browserMu.Unlock()
+	return f.Browser.Fetch(rawURL)
+}
+
+// challengeMarkers are found in the HTML of common bot challenge and interstitial pages.
+var challengeMarkers = []string{
+	"just a moment...",
+	"attention required",
+	"checking your browser",
+	"cf-browser-verification",
+	"challenge-platform",
+	"ddos-guard",
+	"_incapsula_resource",
+	"captcha-delivery.com",
+}
+
+// RequiresBrowser reports why a page fetched with plain HTTP needs browser rendering, or an empty string when it does not.
+// Example:
+//
+//	if reason := goSpider.RequiresBrowser(pageSource); reason != "" {
+//		pageSource, err = nav.Fetch(url)
+//	}
+func RequiresBrowser(pageSource *html.Node) string {
+	rendered, err := ParseHtmlToString(pageSource)
+	if err != nil {
+		return ""
+	}
+	lower := strings.ToLower(rendered)
+	for _, marker := range challengeMarkers {
+		if strings.Contains(lower, marker) {
+			return "challenge page (" + marker + ")"
+		}
+	}
+
+	var text strings.Builder
+	var scripts int
+	var noscript string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "script":
+				scripts++
+				return
+			case "noscript":
+				noscript += strings.ToLower(innerText(n))
+				return
+			case "style", "head", "template":
+				return
+			}
+		}
+		if n.Type == html.TextNode {
+			text.WriteString(strings.TrimSpace(n.Data))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(pageSource)
+
+	visible := text.Len()
+	switch {
+	case strings.Contains(noscript, "javascript") && visible < 500:
+		return "noscript wall"
+	case scripts > 0 && visible < 50:
+		return "empty body"
+	}
+	return ""
+}
+
+// innerText concatenates the text nodes under n, including the raw text of <noscript>.
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// hostOf returns the host of a URL, or the URL itself when it cannot be parsed.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
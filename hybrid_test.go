@@ -0,0 +1,121 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startHybridTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/static", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body><h1 id="title">Static</h1><p>` + strings.Repeat("Content rendered on the server. ", 5) + `</p></body></html>`))
+	})
+	mux.HandleFunc("/spa", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body><div id="root"></div><script src="/app.js"></script></body></html>`))
+	})
+	mux.HandleFunc("/noscript", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body><noscript>You need to enable JavaScript to run this app.</noscript><div id="app"></div></body></html>`))
+	})
+	mux.HandleFunc("/challenge", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`<html><head><title>Just a moment...</title></head><body></body></html>`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRequiresBrowser(t *testing.T) {
+	tests := []struct {
+		page string
+		want string
+	}{
+		{`<html><body><h1>Static</h1><p>` + strings.Repeat("Server rendered text. ", 5) + `</p></body></html>`, ""},
+		{`<html><body><div id="root"></div><script src="/app.js"></script></body></html>`, "empty body"},
+		{`<html><body><noscript>Please enable JavaScript.</noscript></body></html>`, "noscript wall"},
+		{`<html><head><title>Attention Required! | Cloudflare</title></head><body>blocked</body></html>`, "challenge page (attention required)"},
+	}
+	for _, test := range tests {
+		pageSource, err := html.Parse(strings.NewReader(test.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := RequiresBrowser(pageSource)
+		if got != test.want {
+			t.Errorf("RequiresBrowser(%q) = %q, want %q", test.page, got, test.want)
+		}
+	}
+}
+
+func TestHybridFetcher(t *testing.T) {
+	server := startHybridTestServer()
+	defer server.Close()
+
+	var rendered []string
+	browser := FetcherFunc(func(url string) (*html.Node, error) {
+		rendered = append(rendered, url)
+		return html.Parse(strings.NewReader(`<html><body><h1 id="title">Rendered</h1></body></html>`))
+	})
+
+	tests := []struct {
+		path string
+		want string
+		mode FetchMode
+	}{
+		{"/static", "Static", ModeHTTP},
+		{"/spa", "Rendered", ModeBrowser},
+		{"/noscript", "Rendered", ModeBrowser},
+		{"/challenge", "Rendered", ModeBrowser},
+	}
+	for _, test := range tests {
+		fetcher := NewHybridFetcher(NewHTTPFetcher(5*time.Second), browser)
+		pageSource, err := fetcher.Fetch(server.URL + test.path)
+		if err != nil {
+			t.Fatalf("Fetch %s error: %v", test.path, err)
+		}
+		title, err := FindNodes(pageSource, "//h1[@id='title']")
+		if err != nil || title[0].FirstChild.Data != test.want {
+			t.Errorf("Fetch %s expected title %q", test.path, test.want)
+		}
+		if mode := fetcher.Mode(hostOf(server.URL)); mode != test.mode {
+			t.Errorf("Fetch %s expected mode %s, got %s", test.path, test.mode, mode)
+		}
+	}
+	if len(rendered) != 3 {
+		t.Errorf("Expected 3 browser renders, got %d: %v", len(rendered), rendered)
+	}
+
+	// once escalated, the domain skips the HTTP attempt
+	fetcher := NewHybridFetcher(NewHTTPFetcher(5*time.Second), browser)
+	fetcher.SetMode(hostOf(server.URL), ModeBrowser)
+	_, err := fetcher.Fetch(server.URL + "/static")
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	if len(rendered) != 4 {
+		t.Errorf("Expected the recorded browser mode to be used, got %d renders", len(rendered))
+	}
+}
+
+func TestHybridFetcherWithoutBrowser(t *testing.T) {
+	server := startHybridTestServer()
+	defer server.Close()
+
+	fetcher := NewHybridFetcher(NewHTTPFetcher(5*time.Second), nil)
+	_, err := fetcher.Fetch(server.URL + "/static")
+	if err != nil {
+		t.Errorf("Fetch error: %v", err)
+	}
+	if mode := fetcher.Mode(hostOf(server.URL)); mode != ModeHTTP {
+		t.Errorf("Expected http mode, got %s", mode)
+	}
+	_, err = fetcher.Fetch(server.URL + "/spa")
+	if err == nil {
+		t.Errorf("Expected an error escalating without a browser")
+	}
+}
@@ -0,0 +1,299 @@
+package goSpider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ImageRecord describes a stored image and where it was found.
+type ImageRecord struct {
+	Key         string // storage key of the image
+	SHA256      string
+	ContentType string
+	Size        int64
+	URLs        []string // URLs with this content
+	Sources     []string // pages linking to the image
+}
+
+// ImagePipeline collects the images of crawled pages, downloads them concurrently, filters them by size and type,
+// stores each distinct content once in a Storage and keeps a manifest linking every image to its pages.
+type ImagePipeline struct {
+	Fetcher      *HTTPFetcher
+	Storage      Storage
+	Workers      int      // concurrent downloads; 1 if not positive
+	MinSize      int64    // images smaller than MinSize bytes are skipped
+	MaxSize      int64    // images larger than MaxSize bytes are skipped; no limit if not positive
+	ContentTypes []string // accepted content types, e.g. "image/jpeg"; any image if empty
+	Prefix       string   // prefix of the storage keys, "images/" by default
+
+	mu       sync.Mutex
+	records  map[string]*ImageRecord // by hash
+	urls     map[string]string       // hash by URL, empty for skipped URLs
+	inFlight map[string]chan struct{}
+}
+
+// NewImagePipeline creates an ImagePipeline that stores the images in storage with workers concurrent downloads.
+// Example:
+//
+//	storage, err := goSpider.NewDirStorage("output")
+//	pipeline := goSpider.NewImagePipeline(storage, 8)
+//	pipeline.MinSize = 10 * 1024
+//	pipeline.ContentTypes = []string{"image/jpeg", "image/png"}
+//	err = pipeline.Collect(url, pageSource)
+//	err = pipeline.SaveManifest("images/manifest.json")
+func NewImagePipeline(storage Storage, workers int) *ImagePipeline {
+	return &ImagePipeline{
+		Fetcher:  NewHTTPFetcher(30 * time.Second),
+		Storage:  storage,
+		Workers:  workers,
+		Prefix:   "images/",
+		records:  map[string]*ImageRecord{},
+		urls:     map[string]string{},
+		inFlight: map[string]chan struct{}{},
+	}
+}
+
+// Collect downloads and stores the images of the page at pageURL. Images already downloaded are only linked to the
+// page. The first download error is returned after every image was tried.
+func (p *ImagePipeline) Collect(pageURL string, pageSource *html.Node) error {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return fmt.Errorf("error - failed to parse URL: %w", classifyError(err))
+	}
+	imageURLs := ImageURLs(base, pageSource)
+
+	workers := p.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for imageURL := range jobs {
+				err := p.collect(pageURL, imageURL)
+				if err != nil {
+					p.Fetcher.Logger.Printf("Error - Failed to collect image %s: %v\n", imageURL, err)
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, imageURL := range imageURLs {
+		jobs <- imageURL
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
+
+// collect downloads and stores one image, unless it was already processed, and links it to the page.
+func (p *ImagePipeline) collect(pageURL, imageURL string) error {
+	for {
+		p.mu.Lock()
+		if hash, done := p.urls[imageURL]; done {
+			if record := p.records[hash]; record != nil {
+				record.Sources = appendUnique(record.Sources, pageURL)
+			}
+			p.mu.Unlock()
+			return nil
+		}
+		wait, downloading := p.inFlight[imageURL]
+		if !downloading {
+			p.inFlight[imageURL] = make(chan struct{})
+			p.mu.Unlock()
+			break
+		}
+		p.mu.Unlock()
+		<-wait
+	}
+
+	hash, record, err := p.download(imageURL)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	close(p.inFlight[imageURL])
+	delete(p.inFlight, imageURL)
+	if err != nil {
+		return err
+	}
+	p.urls[imageURL] = hash
+	if record == nil {
+		return nil
+	}
+	if existing := p.records[hash]; existing != nil {
+		record = existing
+	} else {
+		p.records[hash] = record
+	}
+	record.URLs = appendUnique(record.URLs, imageURL)
+	record.Sources = appendUnique(record.Sources, pageURL)
+	return nil
+}
+
+// download fetches the image and stores it when it passes the filters. It returns an empty hash and no record for
+// images skipped by the filters.
+func (p *ImagePipeline) download(imageURL string) (string, *ImageRecord, error) {
+	response, err := p.Fetcher.Get(imageURL)
+	if err != nil {
+		return "", nil, err
+	}
+	defer response.Body.Close()
+
+	if p.MaxSize > 0 && response.ContentLength > p.MaxSize {
+		return "", nil, nil
+	}
+	var body io.Reader = response.Body
+	if p.MaxSize > 0 {
+		body = io.LimitReader(response.Body, p.MaxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", nil, fmt.Errorf("error - failed to read image: %w", classifyError(err))
+	}
+	size := int64(len(data))
+	if size < p.MinSize || (p.MaxSize > 0 && size > p.MaxSize) {
+		return "", nil, nil
+	}
+
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType, _, _ = mime.ParseMediaType(response.Header.Get("Content-Type"))
+	}
+	if !p.accepts(contentType) {
+		return "", nil, nil
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := p.Prefix + hash + imageExtension(contentType)
+	exists, err := p.Storage.Exists(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists {
+		err = p.Storage.Put(key, data, contentType)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return hash, &ImageRecord{Key: key, SHA256: hash, ContentType: contentType, Size: size}, nil
+}
+
+// accepts reports whether the content type passes the type filter.
+func (p *ImagePipeline) accepts(contentType string) bool {
+	if len(p.ContentTypes) == 0 {
+		return strings.HasPrefix(contentType, "image/")
+	}
+	for _, accepted := range p.ContentTypes {
+		if strings.EqualFold(accepted, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Manifest returns the stored images, sorted by key.
+func (p *ImagePipeline) Manifest() []ImageRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	manifest := make([]ImageRecord, 0, len(p.records))
+	for _, record := range p.records {
+		copied := *record
+		copied.URLs = append([]string(nil), record.URLs...)
+		copied.Sources = append([]string(nil), record.Sources...)
+		manifest = append(manifest, copied)
+	}
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Key < manifest[j].Key })
+	return manifest
+}
+
+// SaveManifest stores the manifest as JSON under key.
+func (p *ImagePipeline) SaveManifest(key string) error {
+	data, err := json.MarshalIndent(p.Manifest(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error - failed to encode manifest: %w", classifyError(err))
+	}
+	return p.Storage.Put(key, data, "application/json")
+}
+
+// ImageURLs returns the absolute URLs of the images of the page: the src and srcset of img and picture sources and the
+// lazy loading data-src attribute, without duplicates.
+func ImageURLs(base *url.URL, pageSource *html.Node) []string {
+	var urls []string
+	seen := map[string]bool{}
+	add := func(raw string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+		u, err := base.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		link := normalizeLink(u)
+		if !seen[link] {
+			seen[link] = true
+			urls = append(urls, link)
+		}
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.Data == "img" || n.Data == "source") {
+			add(nodeAttribute(n, "src"))
+			add(nodeAttribute(n, "data-src"))
+			for _, candidate := range strings.Split(nodeAttribute(n, "srcset"), ",") {
+				fields := strings.Fields(candidate)
+				if len(fields) > 0 {
+					add(fields[0])
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(pageSource)
+	return urls
+}
+
+// imageExtension returns the file extension of an image content type.
+func imageExtension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
+	case "image/bmp":
+		return ".bmp"
+	case "image/x-icon", "image/vnd.microsoft.icon":
+		return ".ico"
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"golang.org/x/net/html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImageURLs(t *testing.T) {
+	pageSource, err := html.Parse(strings.NewReader(`<html><body>
+		<img src="/a.png"><img src="/a.png#again"><img data-src="b.jpg" src="data:image/gif;base64,R0lGOD">
+		<picture><source srcset="/c.webp 1x, /d.webp 2x"><img src="https://cdn.example.com/e.png"></picture>
+	</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://example.com/page/")
+	want := []string{
+		"https://example.com/a.png",
+		"https://example.com/page/b.jpg",
+		"https://example.com/c.webp",
+		"https://example.com/d.webp",
+		"https://cdn.example.com/e.png",
+	}
+	if got := ImageURLs(base, pageSource); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImageURLs = %v, want %v", got, want)
+	}
+}
+
+func TestImagePipeline(t *testing.T) {
+	pixel, err := os.ReadFile("server/files/pixel.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logo.png", "/copy.png":
+			w.Write(pixel)
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	storage, err := NewDirStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline := NewImagePipeline(storage, 4)
+
+	page := func(body string) *html.Node {
+		node, err := html.Parse(strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	err = pipeline.Collect(server.URL+"/one", page(`<img src="/logo.png"><img src="/copy.png"><img src="/page.html">`))
+	if err != nil {
+		t.Fatalf("Collect error: %v", err)
+	}
+	err = pipeline.Collect(server.URL+"/two", page(`<img src="/logo.png"><img src="/missing.png">`))
+	if err == nil {
+		t.Errorf("Expected an error for the missing image")
+	}
+
+	manifest := pipeline.Manifest()
+	if len(manifest) != 1 {
+		t.Fatalf("Expected 1 deduplicated image, got %+v", manifest)
+	}
+	record := manifest[0]
+	if record.ContentType != "image/png" || !strings.HasSuffix(record.Key, ".png") || record.Size != int64(len(pixel)) {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if len(record.URLs) != 2 || !reflect.DeepEqual(record.Sources, []string{server.URL + "/one", server.URL + "/two"}) {
+		t.Errorf("Unexpected links in record %+v", record)
+	}
+	if exists, _ := storage.Exists(record.Key); !exists {
+		t.Errorf("Expected the image to be stored at %s", record.Key)
+	}
+
+	err = pipeline.SaveManifest("images/manifest.json")
+	if err != nil {
+		t.Fatalf("SaveManifest error: %v", err)
+	}
+	data, _ := storage.Get("images/manifest.json")
+	var saved []ImageRecord
+	if json.Unmarshal(data, &saved) != nil || len(saved) != 1 {
+		t.Errorf("Expected the saved manifest, got %s", data)
+	}
+
+	// size filters skip images
+	small := NewImagePipeline(storage, 1)
+	small.MinSize = int64(len(pixel)) + 1
+	err = small.Collect(server.URL+"/one", page(`<img src="/logo.png">`))
+	if err != nil || len(small.Manifest()) != 0 {
+		t.Errorf("Expected the image to be skipped by size: %v", err)
+	}
+}
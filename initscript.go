@@ -0,0 +1,59 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// initScript is a script registered with AddInitScript.
+type initScript struct {
+	id     page.ScriptIdentifier
+	source string
+}
+
+// AddInitScript registers a script that runs in every document nav opens, including frames, before the scripts of
+// the page, for stealth patches, polyfills or hooks on the functions of a site. It applies from the next navigation
+// and to the tabs opened with NewTab. It returns the identifier of the script for RemoveInitScript.
+// Example:
+//
+//	// keep the messages the site sends to its analytics
+//	id, err := nav.AddInitScript(`window.__sent = []; navigator.sendBeacon = (url, data) => window.__sent.push(data)`)
+//	err = nav.OpenURL("https://www.example.com")
+func (nav *Navigator) AddInitScript(script string) (string, error) {
+	var id page.ScriptIdentifier
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		id, err = page.AddScriptToEvaluateOnNewDocument(script).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to add init script: %v\n", err)
+		return "", fmt.Errorf("error - failed to add init script: %w", classifyError(err))
+	}
+	nav.initScripts = append(nav.initScripts, initScript{id: id, source: script})
+	nav.Logger.Printf("Init script %s added\n", id)
+	return string(id), nil
+}
+
+// RemoveInitScript stops running a script registered with AddInitScript in the documents opened next. The tabs
+// opened before keep running it.
+// Example:
+//
+//	err := nav.RemoveInitScript(id)
+func (nav *Navigator) RemoveInitScript(id string) error {
+	err := chromedp.Run(nav.Ctx, page.RemoveScriptToEvaluateOnNewDocument(page.ScriptIdentifier(id)))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to remove init script: %v\n", err)
+		return fmt.Errorf("error - failed to remove init script: %w", classifyError(err))
+	}
+	for i, script := range nav.initScripts {
+		if script.id == page.ScriptIdentifier(id) {
+			nav.initScripts = append(nav.initScripts[:i:i], nav.initScripts[i+1:]...)
+			break
+		}
+	}
+	nav.Logger.Printf("Init script %s removed\n", id)
+	return nil
+}
@@ -0,0 +1,53 @@
+package goSpider
+
+import (
+	"testing"
+)
+
+func TestAddInitScript(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	// the script runs before the scripts of the page, which can't have run yet
+	id, err := nav.AddInitScript(`window.__initScripts = document.scripts.length`)
+	if err != nil {
+		t.Fatalf("Error on AddInitScript: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("Error on OpenURL: %v", err)
+	}
+	result, err := nav.EvaluateScript(`window.__initScripts`)
+	if err != nil || result != float64(0) {
+		t.Errorf("Expected the init script to run before the page, but got %v (%v)", result, err)
+	}
+
+	tab, err := nav.NewTab()
+	if err != nil {
+		t.Fatalf("Error on NewTab: %v", err)
+	}
+	defer tab.Close()
+	err = tab.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("Error on OpenURL: %v", err)
+	}
+	if result, err := tab.EvaluateScript(`window.__initScripts`); err != nil || result != float64(0) {
+		t.Errorf("Expected the init script to run in the new tab, but got %v (%v)", result, err)
+	}
+
+	err = nav.RemoveInitScript(id)
+	if err != nil {
+		t.Fatalf("Error on RemoveInitScript: %v", err)
+	}
+	if len(nav.initScripts) != 0 {
+		t.Errorf("Expected no init scripts, but got %d", len(nav.initScripts))
+	}
+	err = nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("Error on OpenURL: %v", err)
+	}
+	if result, err := nav.EvaluateScript(`typeof window.__initScripts`); err != nil || result != "undefined" {
+		t.Errorf("Expected the removed init script not to run, but got %v (%v)", result, err)
+	}
+}
@@ -0,0 +1,146 @@
+package goSpider
+
+import (
+	"context"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/chromedp"
+	"sync"
+)
+
+// interceptKind identifies the Fetch interception rules of a Navigator, in the order they see a request.
+type interceptKind int
+
+const (
+	interceptRestriction interceptKind = iota // RestrictNavigation
+	interceptBlocking                         // BlockResourceTypes
+	interceptChaos                            // EnableChaos
+	interceptCassette                         // RecordCassette and ReplayCassette
+	interceptKinds
+)
+
+// interception holds the Fetch interception rules of a tab, shared by the copies of its Navigator.
+type interception struct {
+	mu    sync.Mutex
+	rules [interceptKinds]*interceptRule
+	stop  context.CancelFunc // removes the listener dispatching the paused requests to the rules
+	// proxyAuth is set once every request is intercepted for the authentication of the proxy, see startProxyAuth
+	proxyAuth bool
+}
+
+// interceptRule intercepts the requests of some resource types at a stage, see setInterceptRule.
+type interceptRule struct {
+	stage fetch.RequestStage // the stage the requests are paused at; the request stage if empty
+	types []ResourceType     // the resource types intercepted; all if empty
+	// handle decides how to answer a paused request, in the order the requests are paused. It returns nil to leave the
+	// request to the following rules, or a function answering it in a goroutine, which returns false when it leaves the
+	// request to the following rules after all, as for the requests delayed by chaos or recorded by a cassette.
+	handle func(paused *fetch.EventRequestPaused) func(ctx context.Context) bool
+}
+
+// matches reports whether the rule intercepts the paused request.
+func (r *interceptRule) matches(paused *fetch.EventRequestPaused) bool {
+	// requests paused at the response stage have a response status or error
+	responseStage := paused.ResponseStatusCode != 0 || paused.ResponseErrorReason != ""
+	if responseStage != (r.stage == fetch.RequestStageResponse) {
+		return false
+	}
+	if len(r.types) == 0 {
+		return true
+	}
+	for _, resourceType := range r.types {
+		if resourceType == paused.ResourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// patterns returns the Fetch patterns that pause the requests of the rule.
+func (r *interceptRule) patterns() []*fetch.RequestPattern {
+	if len(r.types) == 0 {
+		return []*fetch.RequestPattern{{URLPattern: "*", RequestStage: r.stage}}
+	}
+	patterns := resourcePatterns(r.types)
+	for _, pattern := range patterns {
+		pattern.RequestStage = r.stage
+	}
+	return patterns
+}
+
+// setInterceptRule replaces the interception rule of the kind, removing it when rule is nil, and updates the Fetch
+// patterns to the ones of the rules in use. BlockResourceTypes, RestrictNavigation, EnableChaos and cassettes share
+// the Fetch domain this way: a paused request goes through their rules in turn until one answers it, and is continued
+// when none does.
+func (nav *Navigator) setInterceptRule(kind interceptKind, rule *interceptRule) error {
+	nav.intercept.mu.Lock()
+	nav.intercept.rules[kind] = rule
+	nav.intercept.mu.Unlock()
+	return chromedp.Run(nav.Ctx, nav.updateInterception())
+}
+
+// updateInterception starts the listener dispatching the paused requests to the interception rules, if needed, and
+// intercepts the requests of the rules in use, stopping interception without any.
+func (nav *Navigator) updateInterception() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		intercept := nav.intercept
+		intercept.mu.Lock()
+		var patterns []*fetch.RequestPattern
+		for _, rule := range intercept.rules {
+			if rule != nil {
+				patterns = append(patterns, rule.patterns()...)
+			}
+		}
+		if intercept.stop == nil {
+			var listenCtx context.Context
+			listenCtx, intercept.stop = context.WithCancel(nav.Ctx)
+			chromedp.ListenTarget(listenCtx, func(ev interface{}) {
+				if paused, ok := ev.(*fetch.EventRequestPaused); ok {
+					intercept.mu.Lock()
+					rules := intercept.rules
+					intercept.mu.Unlock()
+					nav.dispatchPaused(listenCtx, paused, rules[:])
+				}
+			})
+		}
+		intercept.mu.Unlock()
+
+		if nav.proxyAuth != nil {
+			// every request is intercepted, and continued when no rule answers it, so the challenges of the proxy are
+			// answered for the requests outside the patterns of the rules too
+			patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*"})
+		}
+		if len(patterns) == 0 {
+			return fetch.Disable().Do(ctx)
+		}
+		err := nav.enableFetch(patterns).Do(ctx)
+		if err == nil && nav.proxyAuth != nil {
+			intercept.mu.Lock()
+			intercept.proxyAuth = true
+			intercept.mu.Unlock()
+		}
+		return err
+	})
+}
+
+// dispatchPaused runs the interception rules matching the paused request until one answers it, and continues it when
+// none does. Listeners must not block, so the answers are run in goroutines.
+func (nav *Navigator) dispatchPaused(ctx context.Context, paused *fetch.EventRequestPaused, rules []*interceptRule) {
+	for i, rule := range rules {
+		if rule == nil || !rule.matches(paused) {
+			continue
+		}
+		answer := rule.handle(paused)
+		if answer == nil {
+			continue
+		}
+		go func(next []*interceptRule) {
+			if !answer(ctx) {
+				nav.dispatchPaused(ctx, paused, next)
+			}
+		}(rules[i+1:])
+		return
+	}
+	go func() {
+		_ = chromedp.Run(ctx, fetch.ContinueRequest(paused.RequestID))
+	}()
+}
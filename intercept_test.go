@@ -0,0 +1,58 @@
+package goSpider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/chromedp/cdproto/fetch"
+)
+
+func TestInterceptRuleMatches(t *testing.T) {
+	images := &interceptRule{types: []ResourceType{Image}}
+	responses := &interceptRule{stage: fetch.RequestStageResponse}
+	requestStage := &fetch.EventRequestPaused{ResourceType: Image}
+	responseStage := &fetch.EventRequestPaused{ResourceType: Document, ResponseStatusCode: 200}
+
+	if !images.matches(requestStage) || images.matches(&fetch.EventRequestPaused{ResourceType: Script}) {
+		t.Error("Expected the rule to match its resource types only")
+	}
+	if images.matches(responseStage) || !responses.matches(responseStage) || responses.matches(requestStage) {
+		t.Error("Expected the rules to match their stage only")
+	}
+	patterns := responses.patterns()
+	if len(patterns) != 1 || patterns[0].URLPattern != "*" || patterns[0].RequestStage != fetch.RequestStageResponse {
+		t.Errorf("Unexpected patterns %+v", patterns)
+	}
+}
+
+func TestDispatchPaused(t *testing.T) {
+	var seen []string
+	done := make(chan bool)
+	rule := func(name string, answer func(ctx context.Context) bool) *interceptRule {
+		return &interceptRule{handle: func(paused *fetch.EventRequestPaused) func(ctx context.Context) bool {
+			seen = append(seen, name)
+			return answer
+		}}
+	}
+	rules := []*interceptRule{
+		rule("restriction", nil),
+		nil,
+		rule("chaos", func(ctx context.Context) bool { return false }),
+		rule("cassette", func(ctx context.Context) bool {
+			done <- true
+			return true
+		}),
+		rule("after", func(ctx context.Context) bool {
+			t.Error("Expected the rules after the answer to be skipped")
+			return true
+		}),
+	}
+
+	nav := &Navigator{}
+	nav.dispatchPaused(context.Background(), &fetch.EventRequestPaused{ResourceType: Document}, rules)
+	<-done
+	if !reflect.DeepEqual(seen, []string{"restriction", "chaos", "cassette"}) {
+		t.Errorf("Expected the rules in turn until one answers, got %v", seen)
+	}
+}
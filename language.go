@@ -0,0 +1,118 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// languageSamples are short texts from which the trigram profile of each language is built.
+var languageSamples = map[string]string{
+	"pt": `Todos os seres humanos nascem livres e iguais em dignidade e em direitos. Dotados de razão e de consciência,
+		devem agir uns para com os outros em espírito de fraternidade. O processo foi distribuído para a vara cível e o juiz
+		determinou a citação do réu. Não há informações sobre a decisão, que será publicada no diário oficial. A petição
+		inicial foi juntada aos autos com os documentos do autor, e as partes serão intimadas para a audiência de conciliação.
+		Também são consultadas as movimentações da ação e os valores da causa.`,
+	"en": `All human beings are born free and equal in dignity and rights. They are endowed with reason and conscience and
+		should act towards one another in a spirit of brotherhood. The case was assigned to the civil court and the judge
+		ordered the defendant to be served. There is no information about the decision, which will be published in the
+		official gazette. The initial complaint was filed with the documents of the plaintiff, and the parties will be
+		notified of the hearing. The proceedings and the amount of the claim can also be searched.`,
+	"es": `Todos los seres humanos nacen libres e iguales en dignidad y derechos y, dotados como están de razón y conciencia,
+		deben comportarse fraternalmente los unos con los otros. El proceso fue asignado al juzgado civil y el juez ordenó la
+		notificación del demandado. No hay información sobre la decisión, que será publicada en el boletín oficial. La demanda
+		inicial fue presentada con los documentos del actor, y las partes serán citadas para la audiencia de conciliación.
+		También se pueden consultar las actuaciones del procedimiento y la cuantía de la demanda.`,
+	"fr": `Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils sont doués de raison et de conscience
+		et doivent agir les uns envers les autres dans un esprit de fraternité. L'affaire a été attribuée au tribunal civil et
+		le juge a ordonné la signification au défendeur. Il n'y a pas d'information sur la décision, qui sera publiée au
+		journal officiel. La requête initiale a été déposée avec les pièces du demandeur, et les parties seront convoquées à
+		l'audience de conciliation. On peut aussi consulter les actes de la procédure et le montant de la demande.`,
+	"de": `Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind mit Vernunft und Gewissen begabt und
+		sollen einander im Geist der Brüderlichkeit begegnen. Das Verfahren wurde dem Zivilgericht zugewiesen und der Richter
+		hat die Zustellung an den Beklagten angeordnet. Es gibt keine Informationen über die Entscheidung, die im Amtsblatt
+		veröffentlicht wird. Die Klageschrift wurde mit den Unterlagen des Klägers eingereicht, und die Parteien werden zur
+		Güteverhandlung geladen. Auch die Verfahrensschritte und der Streitwert können abgefragt werden.`,
+	"it": `Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi sono dotati di ragione e di coscienza e
+		devono agire gli uni verso gli altri in spirito di fratellanza. Il procedimento è stato assegnato al tribunale civile
+		e il giudice ha disposto la notifica al convenuto. Non ci sono informazioni sulla decisione, che sarà pubblicata nella
+		gazzetta ufficiale. L'atto introduttivo è stato depositato con i documenti dell'attore, e le parti saranno convocate
+		per l'udienza di conciliazione. Si possono anche consultare gli atti del procedimento e il valore della causa.`,
+}
+
+// languageProfiles are the normalized trigram frequencies of each language.
+var languageProfiles = func() map[string]map[string]float64 {
+	profiles := make(map[string]map[string]float64, len(languageSamples))
+	for language, sample := range languageSamples {
+		profiles[language] = trigramProfile(sample)
+	}
+	return profiles
+}()
+
+// minLanguageLetters is the least number of letters needed to detect a language.
+const minLanguageLetters = 20
+
+// DetectLanguage returns the ISO 639-1 code of the language of the visible text of the node, comparing its character
+// trigrams with the profiles of Portuguese (pt), English (en), Spanish (es), French (fr), German (de) and Italian (it).
+// It returns an empty string when the text is too short to tell.
+// Example:
+//
+//	if goSpider.DetectLanguage(pageSource) == "pt" {
+//		data, err = extractPortuguese(pageSource)
+//	}
+func DetectLanguage(node *html.Node) string {
+	if node == nil {
+		return ""
+	}
+	return detectTextLanguage(NormalizedText(node))
+}
+
+// detectTextLanguage returns the language whose profile is the most similar to the trigrams of text.
+func detectTextLanguage(text string) string {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < minLanguageLetters {
+		return ""
+	}
+
+	profile := trigramProfile(text)
+	best, bestScore := "", 0.0
+	for language, languageProfile := range languageProfiles {
+		score := 0.0
+		for trigram, weight := range profile {
+			score += weight * languageProfile[trigram]
+		}
+		if score > bestScore || (score == bestScore && language < best) {
+			best, bestScore = language, score
+		}
+	}
+	return best
+}
+
+// trigramProfile returns the frequencies of the character trigrams of the words of text, padded with spaces,
+// normalized to unit length.
+func trigramProfile(text string) map[string]float64 {
+	counts := map[string]float64{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+
+	norm := 0.0
+	for _, count := range counts {
+		norm += count * count
+	}
+	norm = math.Sqrt(norm)
+	for trigram := range counts {
+		counts[trigram] /= norm
+	}
+	return counts
+}
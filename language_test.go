@@ -0,0 +1,35 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"pt": "Consulta de processos: a sentença foi proferida e as partes foram intimadas da decisão do tribunal.",
+		"en": "Search cases: the judgment was delivered and the parties were notified of the court decision.",
+		"es": "Consulta de expedientes: la sentencia fue dictada y las partes fueron notificadas de la resolución del tribunal.",
+		"fr": "Recherche des affaires : le jugement a été rendu et les parties ont été informées de la décision du tribunal.",
+		"de": "Suche nach Verfahren: das Urteil wurde verkündet und die Parteien wurden über die Entscheidung des Gerichts informiert.",
+		"it": "Ricerca dei procedimenti: la sentenza è stata pronunciata e le parti sono state informate della decisione del tribunale.",
+	}
+	for want, text := range tests {
+		pageSource, err := html.Parse(strings.NewReader("<html><body><p>" + text + "</p><script>var page = 1;</script></body></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := DetectLanguage(pageSource); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+
+	short, _ := html.Parse(strings.NewReader("<html><body><p>OK</p></body></html>"))
+	if got := DetectLanguage(short); got != "" {
+		t.Errorf("Expected no language for short text, got %q", got)
+	}
+	if got := DetectLanguage(nil); got != "" {
+		t.Errorf("Expected no language for a nil node, got %q", got)
+	}
+}
@@ -0,0 +1,260 @@
+package goSpider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BrokenLink is a link that failed, with the pages that point to it.
+type BrokenLink struct {
+	URL        string
+	StatusCode int    // 0 when the request failed
+	Error      string // set when the request failed
+	Sources    []string
+}
+
+// LinkReport is the result of LinkChecker.Check.
+type LinkReport struct {
+	Pages   int // internal pages crawled
+	Checked int // distinct links checked, including the crawled pages
+	Broken  []BrokenLink
+}
+
+// LinkChecker crawls the pages of a site with plain HTTP and checks every internal and external link they contain,
+// reporting the broken ones.
+type LinkChecker struct {
+	Fetcher  *HTTPFetcher
+	MaxPages int // internal pages crawled at most; no limit if not positive
+	Workers  int // concurrent requests checking external links; 1 if not positive
+}
+
+// NewLinkChecker creates a LinkChecker whose requests time out after timeout.
+// Example:
+//
+//	checker := goSpider.NewLinkChecker(30 * time.Second)
+//	checker.MaxPages = 500
+//	report, err := checker.Check("https://www.example.com")
+//	for _, link := range report.Broken {
+//		fmt.Println(link.URL, link.StatusCode, link.Sources)
+//	}
+func NewLinkChecker(timeout time.Duration) *LinkChecker {
+	return &LinkChecker{Fetcher: NewHTTPFetcher(timeout), Workers: 10}
+}
+
+// Check crawls the pages of the site of startURL and checks every link found. Internal links are requested with GET and
+// crawled; external links with HEAD, falling back to GET when HEAD is not supported. Redirects are followed.
+func (c *LinkChecker) Check(startURL string) (LinkReport, error) {
+	start, err := url.Parse(startURL)
+	if err != nil {
+		return LinkReport{}, fmt.Errorf("error - failed to parse URL: %w", classifyError(err))
+	}
+
+	sources := map[string][]string{}
+	status := map[string]linkStatus{}
+	queue := []string{normalizeLink(start)}
+	queued := map[string]bool{queue[0]: true}
+	crawled := map[string]bool{}
+	var external []string
+	pages := 0
+
+	for len(queue) > 0 && (c.MaxPages <= 0 || pages < c.MaxPages) {
+		page := queue[0]
+		queue = queue[1:]
+		pages++
+
+		final, links, result := c.crawl(page)
+		status[page] = result
+		// pages reached through redirects are the source of their links, and are only read once
+		if crawled[final] {
+			continue
+		}
+		crawled[final] = true
+		for _, link := range links {
+			sources[link] = appendUnique(sources[link], final)
+			if queued[link] {
+				continue
+			}
+			queued[link] = true
+			u, _ := url.Parse(link)
+			if u.Host == start.Host {
+				queue = append(queue, link)
+			} else {
+				external = append(external, link)
+			}
+		}
+	}
+	// internal links beyond MaxPages are still checked, without being crawled
+	external = append(external, queue...)
+
+	for link, result := range c.checkAll(external) {
+		status[link] = result
+	}
+
+	report := LinkReport{Pages: pages, Checked: len(status)}
+	for link, result := range status {
+		if result.ok() {
+			continue
+		}
+		report.Broken = append(report.Broken, BrokenLink{
+			URL:        link,
+			StatusCode: result.statusCode,
+			Error:      result.err,
+			Sources:    sources[link],
+		})
+	}
+	sort.Slice(report.Broken, func(i, j int) bool { return report.Broken[i].URL < report.Broken[j].URL })
+	c.Fetcher.Logger.Printf("Checked %d links in %d pages, %d broken\n", report.Checked, report.Pages, len(report.Broken))
+	return report, nil
+}
+
+// linkStatus is the result of requesting a link.
+type linkStatus struct {
+	statusCode int
+	err        string
+}
+
+func (s linkStatus) ok() bool {
+	return s.err == "" && s.statusCode >= 200 && s.statusCode <= 299
+}
+
+// crawl requests an internal page and returns its URL after redirects and the links of its HTML.
+func (c *LinkChecker) crawl(page string) (string, []string, linkStatus) {
+	response, err := c.Fetcher.Get(page)
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		return page, nil, linkStatus{statusCode: statusError.StatusCode}
+	}
+	if err != nil {
+		return page, nil, linkStatus{err: err.Error()}
+	}
+	defer response.Body.Close()
+
+	final := normalizeLink(response.Request.URL)
+	result := linkStatus{statusCode: response.StatusCode}
+	if !strings.Contains(response.Header.Get("Content-Type"), "html") {
+		return final, nil, result
+	}
+	body, err := decodeBody(response)
+	if err != nil {
+		return final, nil, result
+	}
+	pageSource, err := html.Parse(body)
+	if err != nil {
+		return final, nil, result
+	}
+	return final, extractLinks(response.Request.URL, pageSource), result
+}
+
+// checkAll checks the links concurrently.
+func (c *LinkChecker) checkAll(links []string) map[string]linkStatus {
+	workers := c.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	results := make(map[string]linkStatus, len(links))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				result := c.check(link)
+				mu.Lock()
+				results[link] = result
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, link := range links {
+		jobs <- link
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// check requests the link with HEAD, falling back to GET when the server rejects or fails HEAD requests.
+func (c *LinkChecker) check(link string) linkStatus {
+	result := c.request(http.MethodHead, link)
+	if result.ok() || result.statusCode == http.StatusNotFound || result.statusCode == http.StatusGone {
+		return result
+	}
+	return c.request(http.MethodGet, link)
+}
+
+func (c *LinkChecker) request(method, link string) linkStatus {
+	request, err := http.NewRequest(method, link, nil)
+	if err != nil {
+		return linkStatus{err: err.Error()}
+	}
+	for name, values := range c.Fetcher.Header {
+		request.Header[name] = values
+	}
+	response, err := c.Fetcher.Client.Do(request)
+	if err != nil {
+		return linkStatus{err: err.Error()}
+	}
+	response.Body.Close()
+	return linkStatus{statusCode: response.StatusCode}
+}
+
+// extractLinks returns the absolute http(s) URLs of the links of the page, without fragments and duplicates.
+func extractLinks(base *url.URL, pageSource *html.Node) []string {
+	if baseNode := htmlquery.FindOne(pageSource, "//base[@href]"); baseNode != nil {
+		if u, err := base.Parse(nodeAttribute(baseNode, "href")); err == nil {
+			base = u
+		}
+	}
+
+	var links []string
+	seen := map[string]bool{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		href := ""
+		if n.Type == html.ElementNode && n.Data == "a" {
+			href = strings.TrimSpace(nodeAttribute(n, "href"))
+		}
+		if href != "" {
+			u, err := base.Parse(href)
+			if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+				link := normalizeLink(u)
+				if !seen[link] {
+					seen[link] = true
+					links = append(links, link)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(pageSource)
+	return links
+}
+
+// normalizeLink returns the URL without its fragment.
+func normalizeLink(u *url.URL) string {
+	link := *u
+	link.Fragment = ""
+	link.RawFragment = ""
+	return link.String()
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,74 @@
+package goSpider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestLinkChecker(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer external.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>
+			<a href="/about#team">About</a>
+			<a href="/old">Old</a>
+			<a href="/missing">Missing</a>
+			<a href="mailto:contact@example.com">Mail</a>
+			<a name="anchor">Anchor</a>
+			<a href="` + external.URL + `/no-head">External</a>
+		</body></html>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><a href="/">Home</a><a href="/missing">Missing</a><a href="` + external.URL + `/gone">Gone</a></body></html>`))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/about", http.StatusMovedPermanently)
+	})
+	site := httptest.NewServer(mux)
+	defer site.Close()
+
+	report, err := NewLinkChecker(5 * time.Second).Check(site.URL + "/")
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+
+	if report.Pages != 4 {
+		t.Errorf("Expected 4 internal pages crawled, got %d", report.Pages)
+	}
+	if report.Checked != 6 {
+		t.Errorf("Expected 6 links checked, got %d", report.Checked)
+	}
+	want := []BrokenLink{
+		{URL: external.URL + "/gone", StatusCode: http.StatusNotFound, Sources: []string{site.URL + "/about"}},
+		{URL: site.URL + "/missing", StatusCode: http.StatusNotFound, Sources: []string{site.URL + "/", site.URL + "/about"}},
+	}
+	// Broken is sorted by URL, and the ports of the two servers decide which comes first
+	sort.Slice(want, func(i, j int) bool { return want[i].URL < want[j].URL })
+	if !reflect.DeepEqual(report.Broken, want) {
+		t.Errorf("Unexpected broken links %+v", report.Broken)
+	}
+}
@@ -0,0 +1,224 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"time"
+)
+
+// Middleware hooks into the requests and results of a crawl, so concerns such as refreshing a session, counting pages,
+// enriching or filtering results can be added without changing the crawl. Nil hooks are skipped.
+type Middleware struct {
+	// OnBeforeNavigate is called with the url before it is opened and returns the url to open, e.g. with a fresh token.
+	// An error skips the url.
+	OnBeforeNavigate func(url string) (string, error)
+	// OnAfterPage is called with the page of the url once opened and returns the page to keep. An error fails the url,
+	// e.g. when the page is a login form because the session expired.
+	OnAfterPage func(url string, page *html.Node) (*html.Node, error)
+	// OnResult is called with each result of ParallelRequestsWithMiddleware, which it may change, and returns false to
+	// drop it.
+	OnResult func(result *PageSource) bool
+	// OnError is called with the url as requested and its error, including the errors of the other hooks, and returns
+	// the error to report; nil ignores it.
+	OnError func(url string, err error) error
+	// BeforeRequest is called with each request of ParallelRequestsWithMiddleware before it is crawled, and may change
+	// it, e.g. to add credentials to its Payload. Returning a page or an error, e.g. from a cache, skips the crawl and
+	// the request hooks of the next middleware.
+	BeforeRequest func(req *Request) (*html.Node, error)
+	// AfterRequest is called with each request of ParallelRequestsWithMiddleware, its result and the attempt, from 1,
+	// once crawled. It may enrich or fail the result, and returns true to crawl the request again.
+	AfterRequest func(req Request, result *PageSource, attempt int) (retry bool)
+}
+
+// MiddlewareChain is a list of Middleware whose hooks run in order, each receiving the output of the previous one.
+type MiddlewareChain []Middleware
+
+// Wrap returns a crawlerFunc running the hooks of the chain around fetch, so the chain applies to any Fetcher, such as
+// an HTTPFetcher or a NavigatorPool.
+// Example:
+//
+//	var pages int64
+//	chain := goSpider.MiddlewareChain{{
+//		OnAfterPage: func(url string, page *html.Node) (*html.Node, error) {
+//			atomic.AddInt64(&pages, 1)
+//			return page, nil
+//		},
+//	}}
+//	results, err := goSpider.ParallelRequests(requests, 4, 0, chain.Wrap(pool.Fetch))
+func (c MiddlewareChain) Wrap(fetch func(url string) (*html.Node, error)) FetcherFunc {
+	return func(url string) (*html.Node, error) {
+		page, err := c.fetch(fetch, url)
+		if err != nil {
+			return nil, c.handleError(url, err)
+		}
+		return page, nil
+	}
+}
+
+// fetch runs the navigation hooks around fetch. Pages are only passed to OnAfterPage when fetch returns one.
+func (c MiddlewareChain) fetch(fetch func(url string) (*html.Node, error), url string) (*html.Node, error) {
+	for _, m := range c {
+		if m.OnBeforeNavigate == nil {
+			continue
+		}
+		var err error
+		url, err = m.OnBeforeNavigate(url)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := fetch(url)
+	if err != nil || page == nil {
+		return page, err
+	}
+	for _, m := range c {
+		if m.OnAfterPage == nil {
+			continue
+		}
+		page, err = m.OnAfterPage(url, page)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// handleError runs the OnError hooks, stopping when one ignores the error.
+func (c MiddlewareChain) handleError(url string, err error) error {
+	for _, m := range c {
+		if m.OnError == nil {
+			continue
+		}
+		err = m.OnError(url, err)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// crawl crawls req with the request hooks of the chain, again after delay while an AfterRequest hook asks for it.
+func (c MiddlewareChain) crawl(crawl func(Request) (*html.Node, error), req Request, delay time.Duration) PageSource {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+		}
+		result, retry := c.attempt(crawl, req, attempt)
+		if !retry {
+			return result
+		}
+	}
+}
+
+// attempt crawls req once, unless a BeforeRequest hook returns its result, and reports whether to crawl it again. Only
+// the middleware before the hook returning a result see it in AfterRequest.
+func (c MiddlewareChain) attempt(crawl func(Request) (*html.Node, error), req Request, attempt int) (PageSource, bool) {
+	var page *html.Node
+	var err error
+	hooks, skipped := c, false
+	for i, m := range c {
+		if m.BeforeRequest == nil {
+			continue
+		}
+		page, err = m.BeforeRequest(&req)
+		if page != nil || err != nil {
+			hooks, skipped = c[:i], true
+			break
+		}
+	}
+	if !skipped {
+		page, err = crawl(req)
+	}
+
+	result := newPageSource(req, page, err)
+	retry := false
+	for _, m := range hooks {
+		if m.AfterRequest != nil && m.AfterRequest(req, &result, attempt) {
+			retry = true
+		}
+	}
+	return result, retry
+}
+
+// result runs the OnResult hooks and returns whether the result is kept.
+func (c MiddlewareChain) result(result *PageSource) bool {
+	for _, m := range c {
+		if m.OnResult != nil && !m.OnResult(result) {
+			return false
+		}
+	}
+	return true
+}
+
+// afterPage reports whether a hook of the chain needs the pages.
+func (c MiddlewareChain) afterPage() bool {
+	for _, m := range c {
+		if m.OnAfterPage != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Use adds middleware to the hooks run by OpenURL and Fetch. Pages are only read for OpenURL when a Middleware has an
+// OnAfterPage hook, and results only exist for ParallelRequestsWithMiddleware.
+// Example:
+//
+//	nav.Use(goSpider.Middleware{
+//		OnAfterPage: func(url string, page *html.Node) (*html.Node, error) {
+//			if _, err := goSpider.FindNodes(page, "//*[@id='usernameForm']"); err == nil {
+//				return nil, errSessionExpired
+//			}
+//			return page, nil
+//		},
+//	})
+func (nav *Navigator) Use(middleware ...Middleware) {
+	nav.Middleware = append(nav.Middleware, middleware...)
+}
+
+// navigate opens the url through the middleware of nav and returns the page source when withSource is set or a
+// Middleware needs it.
+func (nav *Navigator) navigate(url string, withSource bool) (*html.Node, error) {
+	open := func(url string) (*html.Node, error) {
+		err := nav.openURL(url)
+		if err != nil || !(withSource || nav.Middleware.afterPage()) {
+			return nil, err
+		}
+		return nav.GetPageSource()
+	}
+	if len(nav.Middleware) == 0 {
+		return open(url)
+	}
+	return nav.Middleware.Wrap(open)(url)
+}
+
+// ParallelRequestsWithMiddleware is ParallelRequests with the hooks of the chain: BeforeRequest and AfterRequest around
+// each request, OnBeforeNavigate, OnAfterPage and OnError around each call of crawlerFunc, and OnResult on each
+// result, whose dropped results are left out of the results and of the returned error.
+// Example:
+//
+//	var cache sync.Map
+//	chain := goSpider.MiddlewareChain{{
+//		BeforeRequest: func(req *goSpider.Request) (*html.Node, error) {
+//			if page, ok := cache.Load(req.SearchString); ok {
+//				return page.(*html.Node), nil
+//			}
+//			return nil, nil
+//		},
+//		AfterRequest: func(req goSpider.Request, result *goSpider.PageSource, attempt int) bool {
+//			if result.Error == nil {
+//				cache.Store(req.SearchString, result.Page)
+//			}
+//			return errors.Is(result.Error, goSpider.ErrTimeout) && attempt < 3
+//		},
+//		OnResult: func(result *goSpider.PageSource) bool {
+//			return result.Error == nil && result.Language == "pt"
+//		},
+//	}}
+//	results, err := goSpider.ParallelRequestsWithMiddleware(requests, 10, 0, fetcher.Fetch, chain)
+func ParallelRequestsWithMiddleware(requests []Request, numberOfWorkers int, delay time.Duration, crawlerFunc func(string) (*html.Node, error), chain MiddlewareChain) ([]PageSource, error) {
+	if len(chain) > 0 {
+		crawlerFunc = chain.Wrap(crawlerFunc)
+	}
+	return parallelRequests(requests, numberOfWorkers, delay, searchCrawler(crawlerFunc), chain)
+}
@@ -0,0 +1,172 @@
+package goSpider
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestMiddlewareChainWrap(t *testing.T) {
+	server := startFetcherTestServer()
+	defer server.Close()
+
+	var pages int64
+	var failed []string
+	errSkipped := errors.New("skipped")
+	chain := MiddlewareChain{
+		{
+			// skip the login page, and fetch the other urls under the test server
+			OnBeforeNavigate: func(url string) (string, error) {
+				if url == "/login" {
+					return "", errSkipped
+				}
+				return server.URL + url, nil
+			},
+		},
+		{
+			OnAfterPage: func(url string, page *html.Node) (*html.Node, error) {
+				atomic.AddInt64(&pages, 1)
+				return page, nil
+			},
+			OnError: func(url string, err error) error {
+				failed = append(failed, url)
+				if errors.Is(err, errSkipped) {
+					return nil
+				}
+				return err
+			},
+		},
+	}
+	fetch := chain.Wrap(NewHTTPFetcher(5 * time.Second).Fetch)
+
+	page, err := fetch("/gzip")
+	if err != nil || page == nil || pages != 1 {
+		t.Errorf("Expected the page through the hooks, got %v after %d pages (%v)", page, pages, err)
+	}
+	// the error of a skipped url is ignored by OnError
+	page, err = fetch("/login")
+	if err != nil || page != nil {
+		t.Errorf("Expected the login page to be skipped, got %v (%v)", page, err)
+	}
+	_, err = fetch("/missing")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 404 {
+		t.Errorf("Expected the status error of the fetcher, got %v", err)
+	}
+	if pages != 1 || strings.Join(failed, " ") != "/login /missing" {
+		t.Errorf("Expected 1 page and the failed urls, got %d and %v", pages, failed)
+	}
+}
+
+func TestParallelRequestsWithMiddleware(t *testing.T) {
+	server := startFetcherTestServer()
+	defer server.Close()
+
+	errStale := errors.New("stale page")
+	chain := MiddlewareChain{{
+		// fail the pages without a title, as a stale session would
+		OnAfterPage: func(url string, page *html.Node) (*html.Node, error) {
+			if _, err := FindNodes(page, "//h1"); err != nil {
+				return nil, errStale
+			}
+			return page, nil
+		},
+		OnResult: func(result *PageSource) bool {
+			// drop the missing pages, and enrich the others
+			var statusErr *StatusError
+			if errors.As(result.Error, &statusErr) {
+				return false
+			}
+			result.Language = "pt"
+			return true
+		},
+	}}
+	requests := []Request{
+		{SearchString: server.URL + "/gzip"},
+		{SearchString: server.URL + "/missing"},
+		{SearchString: server.URL + "/gzip"},
+	}
+
+	results, err := ParallelRequestsWithMiddleware(requests, 2, 0, NewHTTPFetcher(5*time.Second).Fetch, chain)
+	if err != nil {
+		t.Fatalf("Expected the error of the dropped result to be left out, got %v", err)
+	}
+	if len(results) != 2 || results[0].Language != "pt" || results[1].Language != "pt" {
+		t.Errorf("Expected 2 enriched results, got %v", results)
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<p>Session expired</p>"))
+	}))
+	defer empty.Close()
+	results, err = ParallelRequestsWithMiddleware([]Request{{SearchString: empty.URL}}, 1, 0, NewHTTPFetcher(5*time.Second).Fetch, chain)
+	if !errors.Is(err, errStale) || len(results) != 1 || results[0].Page != nil {
+		t.Errorf("Expected the error of OnAfterPage, got %v (%v)", results, err)
+	}
+}
+
+func TestParallelRequestsWithRequestHooks(t *testing.T) {
+	server := startFetcherTestServer()
+	defer server.Close()
+
+	cached, _ := html.Parse(strings.NewReader("<h1>Cached</h1>"))
+	errFlaky := errors.New("flaky")
+	var crawls, flaky int64
+	var afterCache []string
+	chain := MiddlewareChain{
+		{
+			// retry the flaky request once, and record the requests it sees
+			AfterRequest: func(req Request, result *PageSource, attempt int) bool {
+				afterCache = append(afterCache, req.ID)
+				return errors.Is(result.Error, errFlaky) && attempt < 2
+			},
+		},
+		{
+			// serve the cached request, and move the others under the test server
+			BeforeRequest: func(req *Request) (*html.Node, error) {
+				if req.ID == "cached" {
+					return cached, nil
+				}
+				req.SearchString = server.URL + req.SearchString
+				return nil, nil
+			},
+			AfterRequest: func(req Request, result *PageSource, attempt int) bool {
+				if result.Error == nil {
+					result.Data = req.SearchString
+				}
+				return false
+			},
+		},
+	}
+	crawl := func(req Request) (*html.Node, error) {
+		atomic.AddInt64(&crawls, 1)
+		if req.ID == "flaky" && atomic.AddInt64(&flaky, 1) == 1 {
+			return nil, errFlaky
+		}
+		return NewHTTPFetcher(5 * time.Second).Fetch(req.SearchString)
+	}
+	requests := []Request{
+		{ID: "cached", SearchString: "/gzip"},
+		{ID: "flaky", SearchString: "/gzip"},
+	}
+
+	results, err := parallelRequests(requests, 1, 0, crawl, chain)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %v (%v)", results, err)
+	}
+	if results[0].Page != cached || results[0].Data != nil {
+		t.Errorf("Expected the cached page without the later hooks, got %+v", results[0])
+	}
+	if results[1].Request != server.URL+"/gzip" || results[1].Data != server.URL+"/gzip" {
+		t.Errorf("Expected the changed and enriched request, got %+v", results[1])
+	}
+	if crawls != 2 || strings.Join(afterCache, " ") != "cached flaky flaky" {
+		t.Errorf("Expected the flaky request to be crawled again, got %d crawls and %v", crawls, afterCache)
+	}
+}
@@ -0,0 +1,331 @@
+package goSpider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"golang.org/x/net/html"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot is the normalized text of a page at the time it was checked.
+type Snapshot struct {
+	URL  string
+	Text string
+	Hash string
+	Time time.Time
+}
+
+// ChangeEvent reports that the text of a monitored page changed since the previous snapshot.
+type ChangeEvent struct {
+	URL      string
+	Previous Snapshot
+	Current  Snapshot
+	Diff     []DiffLine
+}
+
+// DiffOp is the kind of change of a DiffLine.
+type DiffOp int
+
+const (
+	// DiffEqual lines are in both snapshots.
+	DiffEqual DiffOp = iota
+	// DiffInsert lines are only in the current snapshot.
+	DiffInsert
+	// DiffDelete lines are only in the previous snapshot.
+	DiffDelete
+)
+
+// DiffLine is a line of a diff between two snapshots.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// String formats the line like a unified diff: "+ added", "- removed" or "  unchanged".
+func (l DiffLine) String() string {
+	switch l.Op {
+	case DiffInsert:
+		return "+ " + l.Text
+	case DiffDelete:
+		return "- " + l.Text
+	default:
+		return "  " + l.Text
+	}
+}
+
+// Monitor re-crawls a set of URLs on a schedule and reports the changes of their text, e.g. to watch a case page for
+// new movements. Volatile regions such as clocks, counters or ads are removed with Ignore expressions before comparing.
+type Monitor struct {
+	Fetcher  Fetcher
+	Interval time.Duration
+	Ignore   []string // xpath expressions of volatile regions removed before comparing
+	OnChange func(event ChangeEvent)
+	Webhook  *ChangeWebhook // when set, every change is posted to it
+	Storage  Storage        // when set, the text of every new snapshot is stored under its SnapshotKey
+	Logger   *log.Logger
+
+	mu        sync.Mutex
+	urls      []string
+	snapshots map[string]Snapshot
+}
+
+// NewMonitor creates a Monitor that fetches the pages with fetcher every interval and calls onChange for every change.
+// Example:
+//
+//	monitor := goSpider.NewMonitor(goSpider.NewHTTPFetcher(30*time.Second), time.Hour, func(event goSpider.ChangeEvent) {
+//		for _, line := range event.Diff {
+//			if line.Op != goSpider.DiffEqual {
+//				fmt.Println(line)
+//			}
+//		}
+//	})
+//	monitor.Ignore = []string{"//div[@id='clock']"}
+//	monitor.Add("https://esaj.tjsp.jus.br/cpopg/show.do?processo.codigo=1")
+//	err := monitor.Run(ctx)
+func NewMonitor(fetcher Fetcher, interval time.Duration, onChange func(event ChangeEvent)) *Monitor {
+	return &Monitor{
+		Fetcher:   fetcher,
+		Interval:  interval,
+		OnChange:  onChange,
+		Logger:    log.New(os.Stdout, "goSpider: ", log.LstdFlags),
+		snapshots: map[string]Snapshot{},
+	}
+}
+
+// Add adds urls to the monitored pages.
+func (m *Monitor) Add(urls ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.urls = append(m.urls, urls...)
+}
+
+// Snapshots returns the last snapshot of every monitored page, e.g. to persist them between runs.
+func (m *Monitor) Snapshots() map[string]Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshots := make(map[string]Snapshot, len(m.snapshots))
+	for url, snapshot := range m.snapshots {
+		snapshots[url] = snapshot
+	}
+	return snapshots
+}
+
+// SetSnapshot sets the previous snapshot of a page, e.g. one persisted by an earlier run.
+func (m *Monitor) SetSnapshot(snapshot Snapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots[snapshot.URL] = snapshot
+}
+
+// Run checks every page immediately and then every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) error {
+	if m.Interval <= 0 {
+		return fmt.Errorf("error - monitor interval must be positive")
+	}
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		m.CheckAll()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CheckAll checks every monitored page once and returns the changes found. Pages that fail to load are logged and
+// checked again next time.
+func (m *Monitor) CheckAll() []ChangeEvent {
+	m.mu.Lock()
+	urls := append([]string(nil), m.urls...)
+	m.mu.Unlock()
+
+	var events []ChangeEvent
+	for _, url := range urls {
+		event, err := m.Check(url)
+		if err != nil {
+			m.Logger.Printf("Error - Failed to check page %s: %v\n", url, err)
+			continue
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+	return events
+}
+
+// Check fetches the page and compares it with its previous snapshot, returning the change or nil when the page is
+// unchanged or checked for the first time. OnChange is called and the Webhook is posted for the change.
+func (m *Monitor) Check(url string) (*ChangeEvent, error) {
+	pageSource, err := m.Fetcher.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	current, err := m.snapshot(url, pageSource)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	previous, ok := m.snapshots[url]
+	m.snapshots[url] = current
+	m.mu.Unlock()
+	if ok && previous.Hash == current.Hash {
+		return nil, nil
+	}
+	if m.Storage != nil {
+		err = m.Storage.Put(SnapshotKey(current), []byte(current.Text), "text/plain; charset=utf-8")
+		if err != nil {
+			m.Logger.Printf("Error - Failed to store snapshot of %s: %v\n", url, err)
+		}
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	event := &ChangeEvent{
+		URL:      url,
+		Previous: previous,
+		Current:  current,
+		Diff:     DiffLines(strings.Split(previous.Text, "\n"), strings.Split(current.Text, "\n")),
+	}
+	m.Logger.Printf("Page changed: %s\n", url)
+	if m.OnChange != nil {
+		m.OnChange(*event)
+	}
+	if m.Webhook != nil {
+		err = m.Webhook.Send(*event)
+		if err != nil {
+			m.Logger.Printf("Error - Failed to send change of %s: %v\n", url, err)
+		}
+	}
+	return event, nil
+}
+
+// snapshot removes the ignored regions of the page and returns its normalized text.
+func (m *Monitor) snapshot(url string, pageSource *html.Node) (Snapshot, error) {
+	for _, expression := range m.Ignore {
+		nodes, err := htmlquery.Find(pageSource, expression)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("error - invalid ignore expression %s: %w", expression, err)
+		}
+		for _, node := range nodes {
+			if node.Parent != nil {
+				node.Parent.RemoveChild(node)
+			}
+		}
+	}
+
+	text := NormalizedText(pageSource)
+	sum := sha256.Sum256([]byte(text))
+	return Snapshot{URL: url, Text: text, Hash: hex.EncodeToString(sum[:]), Time: time.Now()}, nil
+}
+
+// blockElements start a new line in NormalizedText.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "td": true,
+	"th": true, "tr": true, "ul": true,
+}
+
+// NormalizedText returns the visible text of a node, one line per block element, with whitespace collapsed and empty
+// lines removed, so formatting changes of the HTML don't count as changes of the page.
+// Example:
+//
+//	text := goSpider.NormalizedText(pageSource)
+func NormalizedText(node *html.Node) string {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		text := strings.Join(strings.Fields(line.String()), " ")
+		if text != "" {
+			lines = append(lines, text)
+		}
+		line.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			line.WriteString(n.Data)
+			line.WriteString(" ")
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template", "head":
+				return
+			}
+		case html.CommentNode:
+			return
+		}
+
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			flush()
+		}
+	}
+	walk(node)
+	flush()
+	return strings.Join(lines, "\n")
+}
+
+// DiffLines returns the line diff from previous to current, based on their longest common subsequence.
+func DiffLines(previous, current []string) []DiffLine {
+	// lcs[i][j] is the length of the longest common subsequence of previous[i:] and current[j:]
+	lcs := make([][]int, len(previous)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(current)+1)
+	}
+	for i := len(previous) - 1; i >= 0; i-- {
+		for j := len(current) - 1; j >= 0; j-- {
+			if previous[i] == current[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(previous) && j < len(current) {
+		switch {
+		case previous[i] == current[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: previous[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: previous[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: current[j]})
+			j++
+		}
+	}
+	for ; i < len(previous); i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: previous[i]})
+	}
+	for ; j < len(current); j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: current[j]})
+	}
+	return diff
+}
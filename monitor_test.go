@@ -0,0 +1,68 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizedText(t *testing.T) {
+	pageSource, err := html.Parse(strings.NewReader(`<html><head><title>Case</title><script>var x = 1;</script></head>
+		<body><h1>Case   1017927</h1><table><tr><td>10/01/2024</td><td>Distributed</td></tr></table>
+		<p>Judge:
+			<b>Maria</b></p></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Case 1017927\n10/01/2024\nDistributed\nJudge: Maria"
+	if got := NormalizedText(pageSource); got != want {
+		t.Errorf("NormalizedText = %q, want %q", got, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	want := []DiffLine{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffEqual, "c"}, {DiffInsert, "d"}}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("DiffLines = %v, want %v", diff, want)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	pages := []string{
+		`<html><body><div id="clock">10:00</div><ul><li>Distributed</li></ul></body></html>`,
+		`<html><body><div id="clock">10:05</div><ul><li>Distributed</li></ul></body></html>`,
+		`<html><body><div id="clock">10:10</div><ul><li>Distributed</li><li>Sentence</li></ul></body></html>`,
+	}
+	check := 0
+	fetcher := FetcherFunc(func(url string) (*html.Node, error) {
+		page := pages[check]
+		check++
+		return html.Parse(strings.NewReader(page))
+	})
+
+	var events []ChangeEvent
+	monitor := NewMonitor(fetcher, time.Hour, func(event ChangeEvent) {
+		events = append(events, event)
+	})
+	monitor.Ignore = []string{"//div[@id='clock']"}
+	monitor.Add("https://example.com/case")
+
+	for range pages {
+		monitor.CheckAll()
+	}
+
+	// the first check only records the snapshot and the clock change is ignored
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 change event, got %d", len(events))
+	}
+	want := []DiffLine{{DiffEqual, "Distributed"}, {DiffInsert, "Sentence"}}
+	if !reflect.DeepEqual(events[0].Diff, want) {
+		t.Errorf("Unexpected diff %v", events[0].Diff)
+	}
+	if snapshot := monitor.Snapshots()["https://example.com/case"]; snapshot.Text != "Distributed\nSentence" {
+		t.Errorf("Unexpected snapshot %q", snapshot.Text)
+	}
+}
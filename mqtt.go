@@ -0,0 +1,324 @@
+package goSpider
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MQTT control packet types.
+const (
+	mqttConnect    = 1
+	mqttConnAck    = 2
+	mqttPublish    = 3
+	mqttPubAck     = 4
+	mqttPubRec     = 5
+	mqttPubRel     = 6
+	mqttPubComp    = 7
+	mqttDisconnect = 14
+)
+
+// mqttTopicField matches the {field} placeholders of a topic.
+var mqttTopicField = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// MQTTSink is a Sink that publishes each record as a JSON message to an MQTT 3.1.1 broker. The topic may hold {field}
+// placeholders replaced by the values of the record, e.g. "lawsuits/{tribunal}". Messages are published with QoS 0
+// (at most once), 1 (at least once, waiting for PUBACK) or 2 (exactly once). The connection is opened on the first
+// write and reopened once when publishing fails. It is safe for concurrent use.
+type MQTTSink struct {
+	Broker    string // "tcp://host:1883", or "tls://host:8883" for TLS
+	ClientID  string
+	Username  string
+	Password  string
+	Topic     string
+	QoS       byte
+	Retain    bool
+	Timeout   time.Duration // timeout of connecting and of each publish acknowledgement
+	TLSConfig *tls.Config
+
+	mu       sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+	packetID uint16
+}
+
+// NewMQTTSink creates an MQTTSink publishing to topic on broker with the QoS level.
+// Example:
+//
+//	sink := goSpider.NewMQTTSink("tcp://broker.local:1883", "scrapers/{tribunal}/lawsuits", 1)
+//	sink.ClientID = "scraper-tjsp"
+//	defer sink.Close()
+//	err := sink.Write(records)
+func NewMQTTSink(broker, topic string, qos byte) *MQTTSink {
+	return &MQTTSink{
+		Broker:   broker,
+		ClientID: fmt.Sprintf("goSpider-%d", time.Now().UnixNano()),
+		Topic:    topic,
+		QoS:      qos,
+		Timeout:  30 * time.Second,
+	}
+}
+
+// Write publishes the records in order.
+func (s *MQTTSink) Write(records []Record) error {
+	if s.QoS > 2 {
+		return fmt.Errorf("error - invalid MQTT QoS: %d", s.QoS)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range records {
+		payload, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("error - failed to encode record: %w", err)
+		}
+		topic := s.topic(record)
+		err = s.publish(topic, payload)
+		if err != nil {
+			// the broker may have closed an idle connection
+			s.closeConn()
+			err = s.publish(topic, payload)
+		}
+		if err != nil {
+			s.closeConn()
+			return fmt.Errorf("error - failed to publish to %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// Close disconnects from the broker.
+func (s *MQTTSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	_, err := s.conn.Write([]byte{mqttDisconnect << 4, 0})
+	s.closeConn()
+	return err
+}
+
+// topic returns the topic of the record, with the placeholders replaced by the record values. Values can't add
+// levels or wildcards to the topic.
+func (s *MQTTSink) topic(record Record) string {
+	return mqttTopicField.ReplaceAllStringFunc(s.Topic, func(placeholder string) string {
+		value := cellValue(record[placeholder[1:len(placeholder)-1]])
+		return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(value)
+	})
+}
+
+// publish sends a PUBLISH packet and waits for its acknowledgement.
+func (s *MQTTSink) publish(topic string, payload []byte) error {
+	if s.conn == nil {
+		err := s.connect()
+		if err != nil {
+			return err
+		}
+	}
+
+	header := byte(mqttPublish<<4) | s.QoS<<1
+	if s.Retain {
+		header |= 1
+	}
+	body := mqttString(topic)
+	var id uint16
+	if s.QoS > 0 {
+		s.packetID++
+		if s.packetID == 0 {
+			s.packetID = 1
+		}
+		id = s.packetID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+	err := s.send(header, body)
+	if err != nil {
+		return err
+	}
+
+	switch s.QoS {
+	case 1:
+		return s.expect(mqttPubAck, id)
+	case 2:
+		err = s.expect(mqttPubRec, id)
+		if err != nil {
+			return err
+		}
+		err = s.send(mqttPubRel<<4|2, binary.BigEndian.AppendUint16(nil, id))
+		if err != nil {
+			return err
+		}
+		return s.expect(mqttPubComp, id)
+	}
+	return nil
+}
+
+// connect opens the connection and sends the CONNECT packet.
+func (s *MQTTSink) connect() error {
+	broker, err := url.Parse(s.Broker)
+	if err != nil {
+		return fmt.Errorf("error - invalid MQTT broker: %w", err)
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	switch broker.Scheme {
+	case "tcp", "mqtt":
+		conn, err = dialer.Dial("tcp", hostWithPort(broker, "1883"))
+	case "tls", "ssl", "mqtts":
+		config := s.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: broker.Hostname()}
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", hostWithPort(broker, "8883"), config)
+	default:
+		return fmt.Errorf("error - unsupported MQTT broker scheme: %s", broker.Scheme)
+	}
+	if err != nil {
+		return fmt.Errorf("error - failed to connect to MQTT broker: %w", err)
+	}
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+
+	flags := byte(0x02) // clean session
+	body := append(mqttString("MQTT"), 4)
+	if s.Username != "" {
+		flags |= 0x80
+	}
+	if s.Password != "" {
+		flags |= 0x40
+	}
+	body = append(body, flags, 0, 0) // no keep alive
+	body = append(body, mqttString(s.ClientID)...)
+	if s.Username != "" {
+		body = append(body, mqttString(s.Username)...)
+	}
+	if s.Password != "" {
+		body = append(body, mqttString(s.Password)...)
+	}
+	err = s.send(mqttConnect<<4, body)
+	if err != nil {
+		s.closeConn()
+		return err
+	}
+
+	packetType, response, err := s.read()
+	if err != nil {
+		s.closeConn()
+		return err
+	}
+	if packetType != mqttConnAck || len(response) != 2 || response[1] != 0 {
+		s.closeConn()
+		return fmt.Errorf("error - MQTT broker refused the connection: %v", response)
+	}
+	return nil
+}
+
+// hostWithPort returns the host of the broker URL with the default port when it has none.
+func hostWithPort(broker *url.URL, port string) string {
+	if broker.Port() != "" {
+		return broker.Host
+	}
+	return net.JoinHostPort(broker.Hostname(), port)
+}
+
+// send writes a packet.
+func (s *MQTTSink) send(header byte, body []byte) error {
+	s.setDeadline()
+	_, err := s.conn.Write(append(append([]byte{header}, mqttLength(len(body))...), body...))
+	return err
+}
+
+// expect reads the acknowledgement of the packet id.
+func (s *MQTTSink) expect(packetType byte, id uint16) error {
+	got, body, err := s.read()
+	if err != nil {
+		return err
+	}
+	if got != packetType || len(body) < 2 || binary.BigEndian.Uint16(body) != id {
+		return fmt.Errorf("error - unexpected MQTT packet %d for packet id %d", got, id)
+	}
+	return nil
+}
+
+// read reads a packet, returning its type and body.
+func (s *MQTTSink) read() (byte, []byte, error) {
+	s.setDeadline()
+	header, body, err := readMQTTPacket(s.reader)
+	return header >> 4, body, err
+}
+
+func (s *MQTTSink) setDeadline() {
+	if s.Timeout > 0 {
+		s.conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+}
+
+func (s *MQTTSink) closeConn() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn, s.reader = nil, nil
+	}
+}
+
+// readMQTTPacket reads a packet, returning its first byte, with the type and flags, and its body.
+func readMQTTPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, fmt.Errorf("error - invalid MQTT packet length")
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// mqttLength encodes the remaining length of a packet.
+func mqttLength(length int) []byte {
+	var encoded []byte
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		encoded = append(encoded, digit)
+		if length == 0 {
+			return encoded
+		}
+	}
+}
+
+// mqttString encodes a string with its length.
+func mqttString(s string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+}
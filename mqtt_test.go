@@ -0,0 +1,156 @@
+package goSpider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"testing"
+)
+
+// mqttMessage is a message received by the test broker.
+type mqttMessage struct {
+	Topic   string
+	QoS     byte
+	Payload Record
+}
+
+// startTestBroker accepts MQTT connections, acknowledging connections and QoS 1 and 2 messages, and sends the published messages to messages.
+// It also sends the client id and username of each connection to clients.
+func startTestBroker(t *testing.T, messages chan<- mqttMessage, clients chan<- string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					header, body, err := readMQTTPacket(reader)
+					if err != nil {
+						return
+					}
+					switch header >> 4 {
+					case mqttConnect:
+						// protocol name, level, flags, keep alive, then the client id and the username
+						clientID := string(body[12 : 12+int(binary.BigEndian.Uint16(body[10:]))])
+						rest := body[12+len(clientID):]
+						username := ""
+						if body[7]&0x80 != 0 {
+							username = string(rest[2 : 2+int(binary.BigEndian.Uint16(rest))])
+						}
+						clients <- clientID + ":" + username
+						conn.Write([]byte{mqttConnAck << 4, 2, 0, 0})
+					case mqttPublish:
+						topicLength := int(binary.BigEndian.Uint16(body))
+						message := mqttMessage{Topic: string(body[2 : 2+topicLength]), QoS: header >> 1 & 3}
+						payload := body[2+topicLength:]
+						if message.QoS > 0 {
+							id := payload[:2]
+							payload = payload[2:]
+							acknowledgement := byte(mqttPubAck)
+							if message.QoS == 2 {
+								acknowledgement = mqttPubRec
+							}
+							conn.Write(append([]byte{acknowledgement << 4, 2}, id...))
+						}
+						json.Unmarshal(payload, &message.Payload)
+						messages <- message
+					case mqttPubRel:
+						conn.Write(append([]byte{mqttPubComp << 4, 2}, body...))
+					case mqttDisconnect:
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestMQTTSink(t *testing.T) {
+	messages := make(chan mqttMessage, 10)
+	clients := make(chan string, 10)
+	broker := startTestBroker(t, messages, clients)
+
+	sink := NewMQTTSink(broker, "scrapers/{tribunal}/lawsuits", 1)
+	sink.ClientID = "scraper-tjsp"
+	sink.Username = "user"
+	sink.Password = "secret"
+	defer sink.Close()
+	err := sink.Write([]Record{
+		{"tribunal": "TJSP", "number": "1"},
+		{"tribunal": "TRF/1", "number": "2"},
+	})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+
+	if client := <-clients; client != "scraper-tjsp:user" {
+		t.Errorf("Unexpected client: %s", client)
+	}
+	first, second := <-messages, <-messages
+	if first.Topic != "scrapers/TJSP/lawsuits" || first.QoS != 1 || first.Payload["number"] != "1" {
+		t.Errorf("Unexpected first message: %+v", first)
+	}
+	if second.Topic != "scrapers/TRF_1/lawsuits" {
+		t.Errorf("Expected the slash of the value to be replaced, got %s", second.Topic)
+	}
+}
+
+func TestMQTTSinkReconnect(t *testing.T) {
+	messages := make(chan mqttMessage, 10)
+	clients := make(chan string, 10)
+	broker := startTestBroker(t, messages, clients)
+
+	sink := NewMQTTSink(broker, "lawsuits", 0)
+	err := sink.Write([]Record{{"number": "1"}})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	<-messages
+
+	// the broker dropped the connection
+	sink.conn.Close()
+	err = sink.Write([]Record{{"number": "2"}})
+	if err != nil {
+		t.Fatalf("Write error after the connection was closed: %v", err)
+	}
+	if message := <-messages; message.Payload["number"] != "2" || len(clients) != 2 {
+		t.Errorf("Expected the message to be published on a new connection, got %+v", message)
+	}
+
+	sink.QoS = 2
+	err = sink.Write([]Record{{"number": "3"}})
+	if message := <-messages; err != nil || message.QoS != 2 {
+		t.Errorf("Expected a QoS 2 message, got %+v: %v", message, err)
+	}
+
+	sink.QoS = 3
+	err = sink.Write([]Record{{"number": "3"}})
+	if err == nil {
+		t.Errorf("Expected an error for an invalid QoS")
+	}
+}
+
+func TestMQTTLength(t *testing.T) {
+	for length, want := range map[int][]byte{0: {0}, 127: {0x7f}, 128: {0x80, 0x01}, 16383: {0xff, 0x7f}, 321: {0xc1, 0x02}} {
+		got := mqttLength(length)
+		if string(got) != string(want) {
+			t.Errorf("mqttLength(%d) = %v, want %v", length, got, want)
+		}
+		header, body, err := readMQTTPacket(bufio.NewReader(
+			bytes.NewReader(append(append([]byte{mqttPublish << 4}, got...), make([]byte, length)...))))
+		if err != nil || header>>4 != mqttPublish || len(body) != length {
+			t.Errorf("readMQTTPacket of length %d = %d, %d: %v", length, header, len(body), err)
+		}
+	}
+}
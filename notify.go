@@ -0,0 +1,181 @@
+package goSpider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Notification is an operational alert, such as a failed crawl or a new movement on a watched case.
+type Notification struct {
+	Title   string
+	Message string
+	URL     string // an optional link, e.g. the changed page
+}
+
+// Notifier sends notifications to a team channel.
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(notification Notification) error
+
+// Notify calls f(notification).
+func (f NotifierFunc) Notify(notification Notification) error {
+	return f(notification)
+}
+
+// Notifiers sends each notification to every notifier.
+type Notifiers []Notifier
+
+// Notify sends the notification to every notifier, returning the first error after all were tried.
+func (n Notifiers) Notify(notification Notification) error {
+	var firstErr error
+	for _, notifier := range n {
+		err := notifier.Notify(notification)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SlackNotifier posts notifications to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// NewSlackNotifier creates a SlackNotifier for the incoming webhook URL.
+// Example:
+//
+//	notifier := goSpider.NewSlackNotifier("https://hooks.slack.com/services/T000/B000/XXXX")
+//	err := notifier.Notify(goSpider.Notification{Title: "Crawl failed", Message: err.Error()})
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Notify posts the notification, with the title in bold.
+func (s *SlackNotifier) Notify(notification Notification) error {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	var lines []string
+	if notification.Title != "" {
+		lines = append(lines, "*"+escape.Replace(notification.Title)+"*")
+	}
+	if notification.Message != "" {
+		lines = append(lines, escape.Replace(notification.Message))
+	}
+	if notification.URL != "" {
+		lines = append(lines, "<"+notification.URL+">")
+	}
+
+	_, err := postJSON(s.Client, s.WebhookURL, map[string]string{"text": strings.Join(lines, "\n")}, nil)
+	if err != nil {
+		return fmt.Errorf("error - failed to notify Slack: %w", err)
+	}
+	return nil
+}
+
+// TelegramNotifier sends notifications to a Telegram chat through a bot.
+type TelegramNotifier struct {
+	Token  string // the bot token
+	ChatID string // the chat id, or the @username of a channel
+	APIURL string // the Bot API URL, "https://api.telegram.org" by default
+	Client *http.Client
+}
+
+// NewTelegramNotifier creates a TelegramNotifier for the bot token and chat.
+// Example:
+//
+//	notifier := goSpider.NewTelegramNotifier(os.Getenv("TELEGRAM_TOKEN"), "-1001234567890")
+//	err := notifier.Notify(goSpider.Notification{Title: "New movement", Message: line.Text, URL: event.URL})
+func NewTelegramNotifier(token, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{
+		Token:  token,
+		ChatID: chatID,
+		APIURL: "https://api.telegram.org",
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Notify sends the notification as a message, with the title in bold.
+func (t *TelegramNotifier) Notify(notification Notification) error {
+	var lines []string
+	if notification.Title != "" {
+		lines = append(lines, "<b>"+html.EscapeString(notification.Title)+"</b>")
+	}
+	if notification.Message != "" {
+		lines = append(lines, html.EscapeString(notification.Message))
+	}
+	if notification.URL != "" {
+		lines = append(lines, html.EscapeString(notification.URL))
+	}
+
+	payload := map[string]interface{}{
+		"chat_id":                  t.ChatID,
+		"text":                     strings.Join(lines, "\n"),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	body, err := postJSON(t.Client, strings.TrimRight(t.APIURL, "/")+"/bot"+t.Token+"/sendMessage", payload, nil)
+	var response struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(body, &response) == nil && !response.OK {
+		return fmt.Errorf("error - failed to notify Telegram: %s", response.Description)
+	}
+	if err != nil {
+		// the URL holds the bot token
+		return fmt.Errorf("error - failed to notify Telegram: %v", strings.ReplaceAll(err.Error(), t.Token, "***"))
+	}
+	return nil
+}
+
+// postJSON posts payload as JSON with the extra header and returns the response body. Responses with a status other
+// than 2xx return a StatusError.
+func postJSON(client *http.Client, url string, payload interface{}, header http.Header) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to encode payload: %w", err)
+	}
+	return postBody(client, url, data, header)
+}
+
+// postBody posts the JSON data with the extra header and returns the response body, like postJSON.
+func postBody(client *http.Client, url string, data []byte, header http.Header) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to create request: %w", err)
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read response: %w", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return body, &StatusError{StatusCode: response.StatusCode, URL: url}
+	}
+	return body, nil
+}
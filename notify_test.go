@@ -0,0 +1,86 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlackNotifier(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(server.URL)
+	err := notifier.Notify(Notification{Title: "Crawl failed", Message: "status <503>", URL: "https://example.com/case"})
+	if err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+	want := "*Crawl failed*\nstatus &lt;503&gt;\n<https://example.com/case>"
+	if payload["text"] != want {
+		t.Errorf("Expected %q, got %q", want, payload["text"])
+	}
+
+	notifier.WebhookURL = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	err = notifier.Notify(Notification{Title: "Crawl failed"})
+	if err == nil {
+		t.Errorf("Expected an error for a failed webhook")
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	var path string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["chat_id"] != "42" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"ok":false,"description":"Bad Request: chat not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	notifier := NewTelegramNotifier("123:secret", "42")
+	notifier.APIURL = server.URL
+	err := notifier.Notify(Notification{Title: "New movement", Message: "Sentença <proferida>"})
+	if err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+	if path != "/bot123:secret/sendMessage" {
+		t.Errorf("Unexpected path: %s", path)
+	}
+	if payload["text"] != "<b>New movement</b>\nSentença &lt;proferida&gt;" || payload["parse_mode"] != "HTML" {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+
+	notifier.ChatID = "7"
+	err = notifier.Notify(Notification{Title: "New movement"})
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("Expected the Telegram error description, got %v", err)
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	var sent []string
+	record := func(name string, err error) Notifier {
+		return NotifierFunc(func(notification Notification) error {
+			sent = append(sent, name+":"+notification.Title)
+			return err
+		})
+	}
+	notifiers := Notifiers{record("a", fmt.Errorf("failed")), record("b", nil)}
+	err := notifiers.Notify(Notification{Title: "alert"})
+	if err == nil || len(sent) != 2 || sent[1] != "b:alert" {
+		t.Errorf("Expected every notifier to be called and the error returned, got %v: %v", sent, err)
+	}
+}
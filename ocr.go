@@ -0,0 +1,373 @@
+package goSpider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"image"
+	"image/color"
+	"image/png"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OCRWord is a word recognized by an OCR engine, with its bounding box in pixels.
+type OCRWord struct {
+	Text       string
+	Confidence float64 // from 0 to 100
+	Left       int
+	Top        int
+	Width      int
+	Height     int
+	Line       int // index of the line of the word, from 0
+}
+
+// OCRResult is the text recognized in an image.
+type OCRResult struct {
+	Text       string // the words, one line per text line
+	Confidence float64
+	Words      []OCRWord
+}
+
+// OCR recognizes the text of images, such as screenshots, CAPTCHA images and scanned documents.
+type OCR interface {
+	Recognize(image []byte) (OCRResult, error)
+}
+
+// OCRFunc adapts a function to the OCR interface.
+type OCRFunc func(image []byte) (OCRResult, error)
+
+// Recognize calls f(image).
+func (f OCRFunc) Recognize(image []byte) (OCRResult, error) {
+	return f(image)
+}
+
+// TesseractOCR is an OCR that runs the tesseract command line program, which must be installed with the data of the
+// languages used.
+type TesseractOCR struct {
+	Path        string   // the tesseract executable, "tesseract" by default
+	Languages   []string // tesseract language codes, e.g. "por" and "eng"; tesseract's default if empty
+	PageSegMode int      // the --psm page segmentation mode, e.g. 7 for a single line of text; tesseract's default if 0
+	Whitelist   string   // the only characters recognized, e.g. "0123456789" for numeric CAPTCHAs; any if empty
+	Timeout     time.Duration
+}
+
+// NewTesseractOCR creates a TesseractOCR for the given languages.
+// Example:
+//
+//	ocr := goSpider.NewTesseractOCR("por", "eng")
+//	result, err := ocr.Recognize(imageBytes)
+//	fmt.Println(result.Text, result.Confidence)
+func NewTesseractOCR(languages ...string) *TesseractOCR {
+	return &TesseractOCR{
+		Path:      "tesseract",
+		Languages: languages,
+		Timeout:   time.Minute,
+	}
+}
+
+// Recognize runs tesseract on the image (PNG, JPEG, TIFF...) and returns the recognized words with their confidence.
+func (t *TesseractOCR) Recognize(image []byte) (OCRResult, error) {
+	path := t.Path
+	if path == "" {
+		path = "tesseract"
+	}
+	args := []string{"stdin", "stdout"}
+	if len(t.Languages) > 0 {
+		args = append(args, "-l", strings.Join(t.Languages, "+"))
+	}
+	if t.PageSegMode != 0 {
+		args = append(args, "--psm", strconv.Itoa(t.PageSegMode))
+	}
+	if t.Whitelist != "" {
+		args = append(args, "-c", "tessedit_char_whitelist="+t.Whitelist)
+	}
+	args = append(args, "tsv")
+
+	ctx := context.Background()
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, path, args...)
+	command.Stdin = bytes.NewReader(image)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	err := command.Run()
+	if err != nil {
+		return OCRResult{}, fmt.Errorf("error - failed to run tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTesseractTSV(stdout.String()), nil
+}
+
+// parseTesseractTSV reads the words of the TSV output of tesseract. The confidence of the result is the mean
+// confidence of its words.
+func parseTesseractTSV(tsv string) OCRResult {
+	var result OCRResult
+	var lines []string
+	lineKeys := map[string]int{}
+	total := 0.0
+	for i, row := range strings.Split(strings.TrimRight(tsv, "\r\n"), "\n") {
+		fields := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		// level page_num block_num par_num line_num word_num left top width height conf text
+		if i == 0 || len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		if text == "" || err != nil || confidence < 0 {
+			continue
+		}
+		key := strings.Join(fields[1:5], ".")
+		line, found := lineKeys[key]
+		if !found {
+			line = len(lines)
+			lineKeys[key] = line
+			lines = append(lines, "")
+		}
+		if lines[line] != "" {
+			lines[line] += " "
+		}
+		lines[line] += text
+
+		word := OCRWord{Text: text, Confidence: confidence, Line: line}
+		word.Left, _ = strconv.Atoi(fields[6])
+		word.Top, _ = strconv.Atoi(fields[7])
+		word.Width, _ = strconv.Atoi(fields[8])
+		word.Height, _ = strconv.Atoi(fields[9])
+		result.Words = append(result.Words, word)
+		total += confidence
+	}
+	result.Text = strings.Join(lines, "\n")
+	if len(result.Words) > 0 {
+		result.Confidence = total / float64(len(result.Words))
+	}
+	return result
+}
+
+// OCRScreenshot recognizes the text of a screenshot of the current browser window, e.g. for content drawn in a canvas.
+// Example:
+//
+//	result, err := nav.OCRScreenshot(goSpider.NewTesseractOCR("por"))
+func (nav *Navigator) OCRScreenshot(ocr OCR) (OCRResult, error) {
+	var buf []byte
+	err := chromedp.Run(nav.Ctx, chromedp.CaptureScreenshot(&buf))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to capture screenshot: %v\n", err)
+		return OCRResult{}, fmt.Errorf("error - failed to capture screenshot: %w", classifyError(err))
+	}
+	return nav.recognize(ocr, buf)
+}
+
+// OCRElement recognizes the text of a screenshot of the element matched by selector, such as a CAPTCHA image.
+// Example:
+//
+//	ocr := goSpider.NewTesseractOCR("eng")
+//	ocr.PageSegMode = 7
+//	ocr.Whitelist = "0123456789"
+//	result, err := nav.OCRElement("#imagemCaptcha", ocr)
+//	if result.Confidence > 80 {
+//		err = nav.FillField("#captcha", result.Text)
+//	}
+func (nav *Navigator) OCRElement(selector string, ocr OCR) (OCRResult, error) {
+	buf, err := nav.ElementScreenshot(selector)
+	if err != nil {
+		return OCRResult{}, err
+	}
+	return nav.recognize(ocr, buf)
+}
+
+// recognize runs the OCR on the image, logging the result.
+func (nav *Navigator) recognize(ocr OCR, image []byte) (OCRResult, error) {
+	result, err := ocr.Recognize(image)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to recognize text: %v\n", err)
+		return OCRResult{}, err
+	}
+	nav.Logger.Printf("Recognized %d words with confidence %.1f\n", len(result.Words), result.Confidence)
+	return result, nil
+}
+
+// OCRPDF recognizes the text of the scanned pages of a PDF, returning one result per page. The images of each page
+// are recognized in order and their results joined; pages without images have an empty result. JPEG images and
+// uncompressed or Flate compressed gray and RGB images are supported, other image formats are skipped.
+// Example:
+//
+//	document, err := goSpider.ParsePDF(data)
+//	if strings.TrimSpace(document.Text()) == "" {
+//		pages, err := goSpider.OCRPDF(data, goSpider.NewTesseractOCR("por"))
+//	}
+func OCRPDF(data []byte, ocr OCR) ([]OCRResult, error) {
+	images, err := PDFPageImages(data)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]OCRResult, len(images))
+	for i, pageImages := range images {
+		var texts []string
+		total := 0.0
+		for _, pageImage := range pageImages {
+			result, err := ocr.Recognize(pageImage)
+			if err != nil {
+				return nil, fmt.Errorf("error - failed to recognize page %d: %w", i+1, err)
+			}
+			if result.Text != "" {
+				texts = append(texts, result.Text)
+			}
+			lines := 0
+			if len(results[i].Words) > 0 {
+				lines = results[i].Words[len(results[i].Words)-1].Line + 1
+			}
+			for _, word := range result.Words {
+				word.Line += lines
+				results[i].Words = append(results[i].Words, word)
+				total += word.Confidence
+			}
+		}
+		results[i].Text = strings.Join(texts, "\n")
+		if len(results[i].Words) > 0 {
+			results[i].Confidence = total / float64(len(results[i].Words))
+		}
+	}
+	return results, nil
+}
+
+// PDFPageImages returns the images drawn on each page of a PDF, as JPEG or PNG files, e.g. to run an OCR on scanned
+// documents. JPEG images are returned as stored; uncompressed or Flate compressed gray and RGB images are converted
+// to PNG, and other formats are skipped.
+func PDFPageImages(data []byte) ([][][]byte, error) {
+	if pdfEncryptPattern.Match(data) {
+		return nil, fmt.Errorf("error - encrypted PDFs are not supported")
+	}
+	file := parsePDFFile(data)
+	pages := file.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("error - no pages found in PDF")
+	}
+
+	images := make([][][]byte, len(pages))
+	for i, page := range pages {
+		xObjects := file.dict(page.resources["XObject"])
+		content := &pdfLexer{data: file.pageContent(page.dict)}
+		var operands []interface{}
+		for {
+			value, err := content.object()
+			if err != nil {
+				break
+			}
+			operator, ok := value.(pdfKeyword)
+			if !ok {
+				operands = append(operands, value)
+				continue
+			}
+			if operator == "Do" && len(operands) > 0 && xObjects != nil {
+				if name, ok := operands[len(operands)-1].(pdfName); ok {
+					if stream, ok := file.resolve(xObjects[name]).(pdfStream); ok && stream.dict["Subtype"] == pdfName("Image") {
+						if encoded := file.imageFile(stream); encoded != nil {
+							images[i] = append(images[i], encoded)
+						}
+					}
+				}
+			} else if operator == "BI" {
+				content.skipInlineImage()
+			}
+			operands = operands[:0]
+		}
+	}
+	return images, nil
+}
+
+// imageFile returns an image XObject as a JPEG or PNG file, or nil when its format is not supported.
+func (f *pdfFile) imageFile(stream pdfStream) []byte {
+	var filters []interface{}
+	switch filter := f.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{filter}
+	case []interface{}:
+		filters = filter
+	}
+	if len(filters) == 1 && (f.resolve(filters[0]) == pdfName("DCTDecode") || f.resolve(filters[0]) == pdfName("DCT")) {
+		return stream.raw
+	}
+
+	width, _ := f.resolve(stream.dict["Width"]).(float64)
+	height, _ := f.resolve(stream.dict["Height"]).(float64)
+	bits, _ := f.resolve(stream.dict["BitsPerComponent"]).(float64)
+	components := f.colorComponents(stream.dict["ColorSpace"])
+	if width <= 0 || height <= 0 || components == 0 || (bits != 8 && bits != 1) || (bits == 1 && components != 1) {
+		return nil
+	}
+	data, err := f.decodeStream(stream)
+	if err != nil {
+		return nil
+	}
+
+	w, h := int(width), int(height)
+	var img image.Image
+	if bits == 1 {
+		rowBytes := (w + 7) / 8
+		if len(data) < rowBytes*h {
+			return nil
+		}
+		gray := image.NewGray(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if data[y*rowBytes+x/8]&(0x80>>uint(x%8)) != 0 {
+					gray.Pix[y*gray.Stride+x] = 0xff
+				}
+			}
+		}
+		img = gray
+	} else if components == 1 {
+		if len(data) < w*h {
+			return nil
+		}
+		gray := image.NewGray(image.Rect(0, 0, w, h))
+		copy(gray.Pix, data[:w*h])
+		img = gray
+	} else {
+		if len(data) < 3*w*h {
+			return nil
+		}
+		rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+		for p := 0; p < w*h; p++ {
+			rgba.Set(p%w, p/w, color.RGBA{R: data[3*p], G: data[3*p+1], B: data[3*p+2], A: 0xff})
+		}
+		img = rgba
+	}
+
+	var buffer bytes.Buffer
+	if png.Encode(&buffer, img) != nil {
+		return nil
+	}
+	return buffer.Bytes()
+}
+
+// colorComponents returns the number of components of a gray or RGB color space, or 0 for other color spaces.
+func (f *pdfFile) colorComponents(value interface{}) int {
+	switch space := f.resolve(value).(type) {
+	case pdfName:
+		switch space {
+		case "DeviceGray", "CalGray", "G":
+			return 1
+		case "DeviceRGB", "CalRGB", "RGB":
+			return 3
+		}
+	case []interface{}:
+		if len(space) == 2 && f.resolve(space[0]) == pdfName("ICCBased") {
+			if n, ok := f.dict(space[1])["N"].(float64); ok && (n == 1 || n == 3) {
+				return int(n)
+			}
+		}
+		if len(space) >= 1 {
+			return f.colorComponents(space[0])
+		}
+	}
+	return 0
+}
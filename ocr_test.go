@@ -0,0 +1,139 @@
+package goSpider
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const tesseractTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t200\t60\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t10\t10\t180\t20\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t10\t10\t80\t20\t96.5\tProcesso\n" +
+	"5\t1\t1\t1\t1\t2\t100\t10\t90\t20\t91.5\t1017927\n" +
+	"5\t1\t1\t1\t2\t1\t10\t40\t60\t20\t88\tSentença\n" +
+	"5\t1\t1\t1\t2\t2\t80\t40\t10\t20\t95\t \n"
+
+func TestParseTesseractTSV(t *testing.T) {
+	result := parseTesseractTSV(tesseractTSV)
+	if result.Text != "Processo 1017927\nSentença" {
+		t.Errorf("Unexpected text: %q", result.Text)
+	}
+	if len(result.Words) != 3 || result.Confidence != 92 {
+		t.Errorf("Expected 3 words with confidence 92, got %d with %v", len(result.Words), result.Confidence)
+	}
+	word := result.Words[1]
+	if word.Text != "1017927" || word.Left != 100 || word.Width != 90 || word.Line != 0 || result.Words[2].Line != 1 {
+		t.Errorf("Unexpected word: %+v", word)
+	}
+}
+
+func TestTesseractOCR(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output.tsv")
+	err := os.WriteFile(output, []byte(tesseractTSV), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "tesseract")
+	err = os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+filepath.Join(dir, "args")+"\ncat > /dev/null\ncat "+output+"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ocr := NewTesseractOCR("por", "eng")
+	ocr.Path = script
+	ocr.PageSegMode = 7
+	ocr.Whitelist = "0123456789"
+	result, err := ocr.Recognize([]byte("image"))
+	if err != nil {
+		t.Fatalf("Recognize error: %v", err)
+	}
+	if len(result.Words) != 3 {
+		t.Errorf("Expected 3 words, got %+v", result.Words)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if string(args) != "stdin stdout -l por+eng --psm 7 -c tessedit_char_whitelist=0123456789 tsv\n" {
+		t.Errorf("Unexpected arguments: %s", args)
+	}
+
+	ocr.Path = filepath.Join(dir, "missing")
+	_, err = ocr.Recognize([]byte("image"))
+	if err == nil {
+		t.Errorf("Expected an error for a missing tesseract")
+	}
+}
+
+func TestOCRPDF(t *testing.T) {
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 6 0 R /Resources << /XObject << /Im1 7 0 R /Im2 8 0 R >> >> >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 9 0 R >>",
+		"stream:q 100 0 0 100 0 0 cm /Im1 Do Q q /Im2 Do Q",
+		"<< /Type /XObject /Subtype /Image /Width 2 /Height 2 /BitsPerComponent 8 /ColorSpace /DeviceGray /Length 4 >>\nstream\n\x00\xff\xff\x00\nendstream",
+		"<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceRGB /Filter /DCTDecode /Length 4 >>\nstream\nJPEG\nendstream",
+		"stream:BT (Text page) Tj ET",
+	)
+
+	images, err := PDFPageImages(data)
+	if err != nil {
+		t.Fatalf("PDFPageImages error: %v", err)
+	}
+	if len(images) != 2 || len(images[0]) != 2 || len(images[1]) != 0 {
+		t.Fatalf("Expected two images on the first page only, got %d pages", len(images))
+	}
+	decoded, err := png.Decode(bytes.NewReader(images[0][0]))
+	if err != nil || decoded.Bounds().Dx() != 2 {
+		t.Errorf("Expected the gray image as PNG: %v", err)
+	} else if r, _, _, _ := decoded.At(1, 0).RGBA(); r != 0xffff {
+		t.Errorf("Expected a white pixel, got %v", decoded.At(1, 0))
+	}
+	if string(images[0][1]) != "JPEG" {
+		t.Errorf("Expected the JPEG data as stored, got %q", images[0][1])
+	}
+
+	ocr := OCRFunc(func(image []byte) (OCRResult, error) {
+		if string(image) == "JPEG" {
+			return OCRResult{Text: "second", Confidence: 80, Words: []OCRWord{{Text: "second", Confidence: 80}}}, nil
+		}
+		return OCRResult{Text: "first", Confidence: 90, Words: []OCRWord{{Text: "first", Confidence: 90}}}, nil
+	})
+	results, err := OCRPDF(data, ocr)
+	if err != nil {
+		t.Fatalf("OCRPDF error: %v", err)
+	}
+	if len(results) != 2 || results[0].Text != "first\nsecond" || results[0].Confidence != 85 || results[1].Text != "" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if results[0].Words[1].Line != 1 {
+		t.Errorf("Expected the words of the second image on the next line, got %+v", results[0].Words)
+	}
+}
+
+func TestOCRElement(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/images.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	var captured []byte
+	ocr := OCRFunc(func(image []byte) (OCRResult, error) {
+		captured = image
+		return OCRResult{Text: "Gallery", Confidence: 95}, nil
+	})
+	result, err := nav.OCRElement("#title", ocr)
+	if err != nil {
+		t.Fatalf("OCRElement error: %v", err)
+	}
+	if result.Text != "Gallery" || !bytes.HasPrefix(captured, []byte("\x89PNG")) {
+		t.Errorf("Expected the element screenshot to be recognized, got %q", result.Text)
+	}
+}
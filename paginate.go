@@ -0,0 +1,171 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"time"
+)
+
+// DefaultMaxPages is the safety cap used by Paginate and LoadMore when maxPages is not positive.
+const DefaultMaxPages = 100
+
+// Paginate extracts rows from the current page, then repeatedly clicks the "next page" element and extracts again,
+// concatenating the results until the element disappears, is disabled, the page stops changing or maxPages is reached.
+// After each click the page is given up to the navigation timeout to change, see SetTimeouts, as pages paginated with
+// AJAX render the next page well after the click.
+//
+// Parameters:
+//   - nav: the Navigator positioned on the first page
+//   - nextPageSelector: the css selector or xpath of the "next page" element
+//   - maxPages: the maximum number of pages to extract, DefaultMaxPages if not positive
+//   - extractor: a function that extracts the rows of one page
+//
+// Example:
+//
+//	rows, err := goSpider.Paginate(nav, "#nextPage", 50, func(pageSource *html.Node) ([]string, error) {
+//		return extractRows(pageSource)
+//	})
+func Paginate[T any](nav *Navigator, nextPageSelector string, maxPages int, extractor func(pageSource *html.Node) ([]T, error)) ([]T, error) {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	pageSource, err := nav.GetPageSource()
+	if err != nil {
+		return nil, err
+	}
+	previous, err := nav.pageHTML()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []T
+	for page := 1; ; page++ {
+		rows, err := extractor(pageSource)
+		if err != nil {
+			return results, fmt.Errorf("error - failed to extract page %d: %w", page, err)
+		}
+		results = append(results, rows...)
+
+		if page >= maxPages {
+			nav.Logger.Printf("Pagination stopped at the max pages cap: %d\n", maxPages)
+			return results, nil
+		}
+		if !nav.clickable(nextPageSelector) {
+			nav.Logger.Printf("Pagination finished after %d pages\n", page)
+			return results, nil
+		}
+
+		err = nav.ClickButton(nextPageSelector)
+		if err != nil {
+			return results, err
+		}
+
+		current, changed, err := nav.waitPageChange(previous)
+		if err != nil {
+			return results, err
+		}
+		if !changed {
+			nav.Logger.Printf("Pagination finished after %d pages, the page did not change\n", page)
+			return results, nil
+		}
+		previous = current
+
+		pageSource, err = nav.GetPageSource()
+		if err != nil {
+			return results, err
+		}
+	}
+}
+
+// pageHTML returns the HTML of the current page.
+func (nav *Navigator) pageHTML() (string, error) {
+	var pageHTML string
+	err := chromedp.Run(nav.Ctx, chromedp.OuterHTML("html", &pageHTML, chromedp.ByQuery))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get page HTML: %v\n", err)
+		return "", fmt.Errorf("error - failed to get page HTML: %w", classifyError(err))
+	}
+	return pageHTML, nil
+}
+
+// waitPageChange polls the HTML of the page until it differs from previous, and returns it, or until the navigation
+// timeout passes, reporting that the page did not change. Pages replaced by a navigation meanwhile are polled again.
+func (nav *Navigator) waitPageChange(previous string) (string, bool, error) {
+	deadline := time.Now().Add(nav.GetTimeouts().Navigation)
+	for {
+		current, err := nav.pageHTML()
+		if err != nil && !isContextDestroyed(err) {
+			return "", false, err
+		}
+		if err == nil && current != previous {
+			return current, true, nil
+		}
+		if time.Now().After(deadline) {
+			return previous, false, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// LoadMore clicks a "load more" element until it disappears, is disabled, stops adding content or maxClicks is reached,
+// and then extracts the rows of the fully loaded page once.
+//
+// Example:
+//
+//	rows, err := goSpider.LoadMore(nav, "#loadMore", 20, extractRows)
+func LoadMore[T any](nav *Navigator, loadMoreSelector string, maxClicks int, extractor func(pageSource *html.Node) ([]T, error)) ([]T, error) {
+	if maxClicks <= 0 {
+		maxClicks = DefaultMaxPages
+	}
+
+	var previous string
+	for i := 0; i < maxClicks && nav.clickable(loadMoreSelector); i++ {
+		err := nav.ClickButton(loadMoreSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		var current string
+		err = chromedp.Run(nav.Ctx, chromedp.OuterHTML("body", &current, chromedp.ByQuery))
+		if err != nil {
+			return nil, fmt.Errorf("error - failed to get page HTML: %w", classifyError(err))
+		}
+		if current == previous {
+			break
+		}
+		previous = current
+	}
+
+	pageSource, err := nav.GetPageSource()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := extractor(pageSource)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to extract loaded page: %w", classifyError(err))
+	}
+	return rows, nil
+}
+
+// clickable reports whether the element is visible within nav.Timeout and not disabled, either through the disabled property,
+// aria-disabled or a "disabled" class on itself or its parent (common on pagination lists).
+func (nav *Navigator) clickable(selector string) bool {
+	selector = nav.firstMatch(selector, nav.Timeout)
+	err := chromedp.Run(nav.Ctx, withTimeout(nav.Timeout, chromedp.WaitVisible(selector)))
+	if err != nil {
+		return false
+	}
+
+	var enabled bool
+	err = chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var el = %s;
+			if (!el) return false;
+			var disabled = function(e) { return e && e.classList && e.classList.contains("disabled"); };
+			return !(el.disabled || el.getAttribute("aria-disabled") === "true" || disabled(el) || disabled(el.parentElement));
+		})()`, jsElement(selector)), &enabled),
+	)
+	return err == nil && enabled
+}
@@ -0,0 +1,170 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/DanielFillol/goSpider/fixtures"
+	"golang.org/x/net/html"
+	"testing"
+	"time"
+)
+
+func extractTexts(expression string) func(pageSource *html.Node) ([]string, error) {
+	return func(pageSource *html.Node) ([]string, error) {
+		nodes, err := FindNodes(pageSource, expression)
+		if err != nil {
+			return nil, err
+		}
+		var texts []string
+		for _, node := range nodes {
+			text, err := ExtractText(node, ".", "")
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, text)
+		}
+		return texts, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/pagination.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	rows, err := Paginate(nav, "#nextPage", 10, extractTexts("//*[@id=\"paginatedTable\"]/tbody/tr/td"))
+	if err != nil {
+		t.Fatalf("Paginate error: %v", err)
+	}
+
+	if len(rows) != 15 {
+		t.Fatalf("Expected 15 rows, but got %d: %v", len(rows), rows)
+	}
+	if rows[0] != "Row 1" || rows[14] != "Row 15" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestPaginateFixture(t *testing.T) {
+	var lawsuits [][]string
+	for i := 1; i <= 7; i++ {
+		lawsuits = append(lawsuits, []string{fmt.Sprintf("Lawsuit %d", i)})
+	}
+	site := fixtures.NewSite()
+	site.Page("/search", "Search").
+		Form("search", "/results", fixtures.Field{Name: "number"})
+	site.Page("/results", "Results").
+		DelayedElement("loaded", "7 lawsuits found", 200*time.Millisecond).
+		PaginatedTable("results", lawsuits, 3)
+	server := site.Start()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/search")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	err = nav.FillField("#search_number", "1017927-35.2023.8.26.0008")
+	if err != nil {
+		t.Fatalf("FillField error: %v", err)
+	}
+	err = nav.ClickButton("#search_submit")
+	if err != nil {
+		t.Fatalf("ClickButton error: %v", err)
+	}
+	err = nav.WaitForElement("#loaded", time.Second)
+	if err != nil {
+		t.Fatalf("WaitForElement error: %v", err)
+	}
+
+	requests := site.Requests("/results")
+	if len(requests) != 1 || requests[0].Get("number") != "1017927-35.2023.8.26.0008" {
+		t.Errorf("Expected the submitted number, got: %v", requests)
+	}
+	rows, err := Paginate(nav, "#resultsNext", 10, extractTexts("//*[@id=\"results\"]/tbody/tr/td"))
+	if err != nil {
+		t.Fatalf("Paginate error: %v", err)
+	}
+	if len(rows) != 7 || rows[0] != "Lawsuit 1" || rows[6] != "Lawsuit 7" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestPaginateSlowPages(t *testing.T) {
+	site := fixtures.NewSite()
+	site.Page("/results", "Results").HTML(`<table id="results"><tbody><tr><td>Page 1</td></tr></tbody></table>
+<a id="resultsNext" href="#">Next</a>
+<script>
+var page = 1;
+document.getElementById('resultsNext').addEventListener('click', function (event) {
+	event.preventDefault();
+	// the next page is rendered after longer than the click waits
+	setTimeout(function () {
+		page++;
+		document.querySelector('#results td').textContent = 'Page ' + page;
+		if (page === 3) document.getElementById('resultsNext').remove();
+	}, 1500);
+});
+</script>`)
+	server := site.Start()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.SetTimeouts(Timeouts{Navigation: 5 * time.Second})
+	err := nav.OpenURL(server.URL + "/results")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	rows, err := Paginate(nav, "#resultsNext", 10, extractTexts("//*[@id=\"results\"]/tbody/tr/td"))
+	if err != nil {
+		t.Fatalf("Paginate error: %v", err)
+	}
+	if len(rows) != 3 || rows[0] != "Page 1" || rows[2] != "Page 3" {
+		t.Errorf("Expected the 3 pages, but got %v", rows)
+	}
+}
+
+func TestPaginateMaxPages(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/pagination.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	rows, err := Paginate(nav, "#nextPage", 2, extractTexts("//*[@id=\"paginatedTable\"]/tbody/tr/td"))
+	if err != nil {
+		t.Fatalf("Paginate error: %v", err)
+	}
+
+	if len(rows) != 10 {
+		t.Errorf("Expected 10 rows, but got %d: %v", len(rows), rows)
+	}
+}
+
+func TestLoadMore(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/pagination.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	items, err := LoadMore(nav, "#loadMore", 10, extractTexts("//*[@id=\"loadMoreList\"]/li"))
+	if err != nil {
+		t.Fatalf("LoadMore error: %v", err)
+	}
+
+	if len(items) != 12 {
+		t.Errorf("Expected 12 items, but got %d: %v", len(items), items)
+	}
+}
@@ -0,0 +1,1211 @@
+package goSpider
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"golang.org/x/net/html"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// PDFDocument is the text and metadata extracted from a PDF.
+type PDFDocument struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate time.Time
+	ModDate      time.Time
+	Pages        []string // text of each page, one line per text line
+}
+
+// Text returns the text of every page, separated by blank lines.
+func (d *PDFDocument) Text() string {
+	return strings.Join(d.Pages, "\n\n")
+}
+
+// HTML returns the document as an HTML page, with the title in the head and a div with class "page" holding one
+// paragraph per text line for each page, so PDFs can go through the same extraction code as HTML pages.
+// Example:
+//
+//	document, err := goSpider.ParsePDF(data)
+//	pages, err := goSpider.FindNodes(document.HTML(), "//div[@class='page']")
+func (d *PDFDocument) HTML() *html.Node {
+	var b strings.Builder
+	b.WriteString("<html><head><title>" + html.EscapeString(d.Title) + "</title></head><body>")
+	for i, page := range d.Pages {
+		b.WriteString(fmt.Sprintf(`<div class="page" data-page="%d">`, i+1))
+		for _, line := range strings.Split(page, "\n") {
+			if line != "" {
+				b.WriteString("<p>" + html.EscapeString(line) + "</p>")
+			}
+		}
+		b.WriteString("</div>")
+	}
+	b.WriteString("</body></html>")
+	node, _ := html.Parse(strings.NewReader(b.String()))
+	return node
+}
+
+// ReadPDF extracts the text and metadata of the PDF file at path, e.g. a document saved by DownloadDocuments.
+// Example:
+//
+//	manifest, err := nav.DownloadDocuments("a.document", "", "downloads", 0)
+//	document, err := goSpider.ReadPDF(manifest[0].Path)
+func ReadPDF(path string) (*PDFDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read PDF: %w", err)
+	}
+	return ParsePDF(data)
+}
+
+// ParsePDF extracts the text of each page and the document information (title, author, dates...) of a PDF, e.g. the
+// content returned by FetchFile. Text is decoded with the ToUnicode maps and encodings of the fonts; scanned pages
+// without a text layer come out empty. Encrypted PDFs are not supported.
+// Example:
+//
+//	data, _, err := nav.FetchFile("https://www.example.com/decision.pdf")
+//	document, err := goSpider.ParsePDF(data)
+//	fmt.Println(document.Title, document.Text())
+func ParsePDF(data []byte) (*PDFDocument, error) {
+	header := data
+	if len(header) > 1024 {
+		header = header[:1024]
+	}
+	if !bytes.Contains(header, []byte("%PDF-")) {
+		return nil, fmt.Errorf("error - not a PDF file")
+	}
+	if pdfEncryptPattern.Match(data) {
+		return nil, fmt.Errorf("error - encrypted PDFs are not supported")
+	}
+
+	file := parsePDFFile(data)
+	pages := file.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("error - no pages found in PDF")
+	}
+
+	document := &PDFDocument{}
+	for _, page := range pages {
+		content := file.pageContent(page.dict)
+		text := &pdfTextBuilder{}
+		file.extractText(content, page.resources, text, 0)
+		document.Pages = append(document.Pages, text.String())
+	}
+
+	if info, ok := file.resolve(file.trailerRef(pdfInfoPattern)).(pdfDict); ok {
+		document.Title = pdfTextString(info["Title"])
+		document.Author = pdfTextString(info["Author"])
+		document.Subject = pdfTextString(info["Subject"])
+		document.Keywords = pdfTextString(info["Keywords"])
+		document.Creator = pdfTextString(info["Creator"])
+		document.Producer = pdfTextString(info["Producer"])
+		document.CreationDate = parsePDFDate(pdfTextString(info["CreationDate"]))
+		document.ModDate = parsePDFDate(pdfTextString(info["ModDate"]))
+	}
+	return document, nil
+}
+
+var (
+	pdfObjectPattern  = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfRootPattern    = regexp.MustCompile(`/Root\s+(\d+)\s+(\d+)\s+R`)
+	pdfInfoPattern    = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	pdfEncryptPattern = regexp.MustCompile(`/Encrypt\s*(\d+\s+\d+\s+R|<<)`)
+)
+
+// pdfName is a PDF name object, without the leading slash.
+type pdfName string
+
+// pdfKeyword is a bare keyword, such as an operator of a content stream.
+type pdfKeyword string
+
+// pdfRef is an indirect reference to an object.
+type pdfRef struct {
+	num, gen int
+}
+
+// pdfDict is a PDF dictionary.
+type pdfDict map[pdfName]interface{}
+
+// pdfStream is a stream object with its raw, still encoded, data.
+type pdfStream struct {
+	dict pdfDict
+	raw  []byte
+}
+
+// pdfLexer reads the objects of PDF files and content streams. Strings are returned as Go strings holding the raw
+// bytes, numbers as float64.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// skipWhitespace skips whitespace and comments.
+func (l *pdfLexer) skipWhitespace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else if !isPDFWhitespace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// object reads the next object, combining "num gen R" into a reference.
+func (l *pdfLexer) object() (interface{}, error) {
+	value, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) {
+		return value, nil
+	}
+	start := l.pos
+	gen, err := l.token()
+	if generation, ok := gen.(float64); ok && err == nil && generation == math.Trunc(generation) {
+		if keyword, err := l.token(); err == nil && keyword == pdfKeyword("R") {
+			return pdfRef{num: int(number), gen: int(generation)}, nil
+		}
+	}
+	l.pos = start
+	return value, nil
+}
+
+// token reads the next object without combining references.
+func (l *pdfLexer) token() (interface{}, error) {
+	l.skipWhitespace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodePDFName(string(l.data[start:l.pos]))), nil
+	case c == '(':
+		return l.literalString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dict()
+	case c == '<':
+		return l.hexString(), nil
+	case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return pdfKeyword(">>"), nil
+	case c == '[':
+		l.pos++
+		return l.array()
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := l.pos
+		for l.pos < len(l.data) && strings.IndexByte("+-.0123456789", l.data[l.pos]) >= 0 {
+			l.pos++
+		}
+		number, err := strconv.ParseFloat(string(l.data[start:l.pos]), 64)
+		if err != nil {
+			return 0.0, nil
+		}
+		return number, nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	switch keyword := string(l.data[start:l.pos]); keyword {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return pdfKeyword(keyword), nil
+	}
+}
+
+// dict reads a dictionary after its opening "<<".
+func (l *pdfLexer) dict() (pdfDict, error) {
+	dict := pdfDict{}
+	for {
+		key, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		if key == pdfKeyword(">>") {
+			return dict, nil
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue
+		}
+		value, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		dict[name] = value
+	}
+}
+
+// array reads an array after its opening "[".
+func (l *pdfLexer) array() ([]interface{}, error) {
+	var array []interface{}
+	for {
+		value, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		if value == pdfKeyword("]") {
+			return array, nil
+		}
+		array = append(array, value)
+	}
+}
+
+// literalString reads a string in parentheses, handling nested parentheses and escapes.
+func (l *pdfLexer) literalString() string {
+	var b []byte
+	depth := 0
+	l.pos++
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return string(b)
+			}
+			depth--
+		case '\\':
+			if l.pos >= len(l.data) {
+				return string(b)
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					octal := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						octal = octal*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(octal)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// hexString reads a string in angle brackets.
+func (l *pdfLexer) hexString() string {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFWhitespace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	decoded := make([]byte, hex.DecodedLen(len(digits)))
+	n, _ := hex.Decode(decoded, digits)
+	return string(decoded[:n])
+}
+
+// skipInlineImage skips the data of an inline image, from after its "BI" operator to its "EI" operator.
+func (l *pdfLexer) skipInlineImage() {
+	for {
+		value, err := l.token()
+		if err != nil || value == pdfKeyword("ID") {
+			break
+		}
+	}
+	for l.pos+2 < len(l.data) {
+		if isPDFWhitespace(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.data) || isPDFWhitespace(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+// decodePDFName replaces the #xx escapes of a name.
+func decodePDFName(name string) string {
+	if !strings.Contains(name, "#") {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if decoded, err := hex.DecodeString(name[i+1 : i+3]); err == nil {
+				b.Write(decoded)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// pdfFile holds the objects of a PDF by number.
+type pdfFile struct {
+	data    []byte
+	objects map[int]interface{}
+	fonts   map[pdfRef]*pdfFont
+}
+
+// parsePDFFile reads every object of the file, including those in object streams. The objects are found by scanning
+// the file instead of following the cross-reference table, so files with broken offsets still work; later definitions
+// of an object replace earlier ones, as in incremental updates.
+func parsePDFFile(data []byte) *pdfFile {
+	file := &pdfFile{data: data, objects: map[int]interface{}{}, fonts: map[pdfRef]*pdfFont{}}
+	for _, match := range pdfObjectPattern.FindAllSubmatchIndex(data, -1) {
+		if match[0] > 0 && !isPDFWhitespace(data[match[0]-1]) && !isPDFDelimiter(data[match[0]-1]) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		lexer := &pdfLexer{data: data, pos: match[1]}
+		value, err := lexer.object()
+		if err != nil {
+			continue
+		}
+		if dict, ok := value.(pdfDict); ok {
+			start := lexer.pos
+			if keyword, err := lexer.token(); err == nil && keyword == pdfKeyword("stream") {
+				value = pdfStream{dict: dict, raw: streamData(data, lexer.pos, dict)}
+			} else {
+				lexer.pos = start
+			}
+		}
+		file.objects[num] = value
+	}
+
+	numbers := make([]int, 0, len(file.objects))
+	for num := range file.objects {
+		numbers = append(numbers, num)
+	}
+	sort.Ints(numbers)
+	for _, num := range numbers {
+		stream, ok := file.objects[num].(pdfStream)
+		if ok && stream.dict["Type"] == pdfName("ObjStm") {
+			file.readObjectStream(stream)
+		}
+	}
+	return file
+}
+
+// streamData returns the data of the stream starting after the "stream" keyword at pos.
+func streamData(data []byte, pos int, dict pdfDict) []byte {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+	if length, ok := dict["Length"].(float64); ok && length >= 0 && pos+int(length) <= len(data) {
+		end := pos + int(length)
+		rest := data[end:]
+		if len(rest) > 32 {
+			rest = rest[:32]
+		}
+		rest = bytes.TrimLeft(rest, "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return data[pos:end]
+		}
+	}
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+// readObjectStream adds the objects of an object stream that are not defined directly in the file.
+func (f *pdfFile) readObjectStream(stream pdfStream) {
+	data, err := f.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	count, _ := stream.dict["N"].(float64)
+	first, _ := stream.dict["First"].(float64)
+	header := &pdfLexer{data: data}
+	for i := 0; i < int(count); i++ {
+		num, err1 := header.token()
+		offset, err2 := header.token()
+		number, ok1 := num.(float64)
+		position, ok2 := offset.(float64)
+		if err1 != nil || err2 != nil || !ok1 || !ok2 {
+			return
+		}
+		if _, defined := f.objects[int(number)]; defined {
+			continue
+		}
+		lexer := &pdfLexer{data: data, pos: int(first) + int(position)}
+		if lexer.pos >= len(data) {
+			continue
+		}
+		value, err := lexer.object()
+		if err == nil {
+			f.objects[int(number)] = value
+		}
+	}
+}
+
+// resolve follows references until a direct object.
+func (f *pdfFile) resolve(value interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		value = f.objects[ref.num]
+	}
+	return nil
+}
+
+// dict resolves value to a dictionary, using the dictionary of streams.
+func (f *pdfFile) dict(value interface{}) pdfDict {
+	switch value := f.resolve(value).(type) {
+	case pdfDict:
+		return value
+	case pdfStream:
+		return value.dict
+	}
+	return nil
+}
+
+// trailerRef returns the reference of the last trailer entry matched by pattern, such as /Root or /Info.
+func (f *pdfFile) trailerRef(pattern *regexp.Regexp) interface{} {
+	matches := pattern.FindAllSubmatch(f.data, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	last := matches[len(matches)-1]
+	num, _ := strconv.Atoi(string(last[1]))
+	gen, _ := strconv.Atoi(string(last[2]))
+	return pdfRef{num: num, gen: gen}
+}
+
+// decodeStream returns the data of the stream with its filters applied.
+func (f *pdfFile) decodeStream(stream pdfStream) ([]byte, error) {
+	var filters, parameters []interface{}
+	switch filter := f.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{filter}
+	case []interface{}:
+		filters = filter
+	}
+	switch parameter := f.resolve(stream.dict["DecodeParms"]).(type) {
+	case pdfDict:
+		parameters = []interface{}{parameter}
+	case []interface{}:
+		parameters = parameter
+	}
+
+	data := stream.raw
+	for i, filter := range filters {
+		var err error
+		switch f.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflate(data)
+			if err == nil && i < len(parameters) {
+				data, err = unpredict(data, f.dict(parameters[i]))
+			}
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			lexer := &pdfLexer{data: append(append([]byte{'<'}, data...), '>')}
+			data = []byte(lexer.hexString())
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if end := bytes.Index(data, []byte("~>")); end >= 0 {
+				data = data[:end]
+			}
+			decoded := make([]byte, 4*len(data)+4)
+			n, _, decodeErr := ascii85.Decode(decoded, data, true)
+			data, err = decoded[:n], decodeErr
+		default:
+			err = fmt.Errorf("error - unsupported PDF filter: %v", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate decompresses zlib data, keeping what was read from truncated streams.
+func inflate(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to inflate PDF stream: %w", err)
+	}
+	defer reader.Close()
+	inflated, err := io.ReadAll(reader)
+	if err != nil && len(inflated) == 0 {
+		return nil, fmt.Errorf("error - failed to inflate PDF stream: %w", err)
+	}
+	return inflated, nil
+}
+
+// unpredict reverses the PNG predictors of a FlateDecode stream.
+func unpredict(data []byte, parameters pdfDict) ([]byte, error) {
+	predictor, _ := parameters["Predictor"].(float64)
+	if predictor < 10 {
+		return data, nil
+	}
+	columns, colors, bits := 1.0, 1.0, 8.0
+	if value, ok := parameters["Columns"].(float64); ok {
+		columns = value
+	}
+	if value, ok := parameters["Colors"].(float64); ok {
+		colors = value
+	}
+	if value, ok := parameters["BitsPerComponent"].(float64); ok {
+		bits = value
+	}
+	pixelBytes := int(math.Max(1, colors*bits/8))
+	rowBytes := int(math.Ceil(columns * colors * bits / 8))
+	if rowBytes <= 0 {
+		return nil, fmt.Errorf("error - invalid PDF predictor parameters")
+	}
+
+	var out []byte
+	previous := make([]byte, rowBytes)
+	for start := 0; start+rowBytes+1 <= len(data); start += rowBytes + 1 {
+		kind := data[start]
+		row := append([]byte(nil), data[start+1:start+1+rowBytes]...)
+		for i := range row {
+			var left, upLeft byte
+			if i >= pixelBytes {
+				left, upLeft = row[i-pixelBytes], previous[i-pixelBytes]
+			}
+			up := previous[i]
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		previous = row
+	}
+	return out, nil
+}
+
+// paeth is the Paeth predictor of PNG.
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := absInt(p-int(a)), absInt(p-int(b)), absInt(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// pdfPage is a page dictionary with its inherited resources.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages returns the pages in document order, walking the page tree of the catalog. Files without a usable page tree
+// return their page objects in object number order.
+func (f *pdfFile) pages() []pdfPage {
+	var pages []pdfPage
+	visited := map[int]bool{}
+	var walk func(node interface{}, resources pdfDict)
+	walk = func(node interface{}, resources pdfDict) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		dict := f.dict(node)
+		if dict == nil {
+			return
+		}
+		if own := f.dict(dict["Resources"]); own != nil {
+			resources = own
+		}
+		if kids, ok := f.resolve(dict["Kids"]).([]interface{}); ok {
+			for _, kid := range kids {
+				walk(kid, resources)
+			}
+			return
+		}
+		if dict["Type"] == pdfName("Page") || dict["Contents"] != nil {
+			pages = append(pages, pdfPage{dict: dict, resources: resources})
+		}
+	}
+	if catalog := f.dict(f.trailerRef(pdfRootPattern)); catalog != nil {
+		walk(catalog["Pages"], nil)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	numbers := make([]int, 0, len(f.objects))
+	for num, value := range f.objects {
+		if dict, ok := value.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			numbers = append(numbers, num)
+		}
+	}
+	sort.Ints(numbers)
+	for _, num := range numbers {
+		dict := f.objects[num].(pdfDict)
+		resources := f.dict(dict["Resources"])
+		if resources == nil {
+			if parent := f.dict(dict["Parent"]); parent != nil {
+				resources = f.dict(parent["Resources"])
+			}
+		}
+		pages = append(pages, pdfPage{dict: dict, resources: resources})
+	}
+	return pages
+}
+
+// pageContent returns the decoded content streams of the page, concatenated.
+func (f *pdfFile) pageContent(page pdfDict) []byte {
+	var streams []interface{}
+	switch contents := f.resolve(page["Contents"]).(type) {
+	case pdfStream:
+		streams = []interface{}{contents}
+	case []interface{}:
+		streams = contents
+	}
+	var content []byte
+	for _, stream := range streams {
+		if stream, ok := f.resolve(stream).(pdfStream); ok {
+			data, err := f.decodeStream(stream)
+			if err == nil {
+				content = append(append(content, data...), '\n')
+			}
+		}
+	}
+	return content
+}
+
+// pdfTextBuilder accumulates the text of a page, collapsing repeated spaces and line breaks.
+type pdfTextBuilder struct {
+	b strings.Builder
+}
+
+func (t *pdfTextBuilder) write(text string) {
+	t.b.WriteString(text)
+}
+
+func (t *pdfTextBuilder) space() {
+	if s := t.b.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		t.b.WriteByte(' ')
+	}
+}
+
+func (t *pdfTextBuilder) newline() {
+	if s := t.b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		t.b.WriteByte('\n')
+	}
+}
+
+// String returns the text with each line trimmed and empty lines removed.
+func (t *pdfTextBuilder) String() string {
+	var lines []string
+	for _, line := range strings.Split(t.b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maxFormDepth limits the nesting of form XObjects followed when extracting text.
+const maxFormDepth = 8
+
+// extractText interprets the text operators of a content stream, writing the shown text to out. Line breaks are
+// inserted when the text moves to another line, and spaces for gaps of a fifth of an em or more in TJ arrays.
+func (f *pdfFile) extractText(content []byte, resources pdfDict, out *pdfTextBuilder, depth int) {
+	lexer := &pdfLexer{data: content}
+	var operands []interface{}
+	var font *pdfFont
+	var lineY float64
+	hasLine := false
+
+	for {
+		value, err := lexer.object()
+		if err != nil {
+			return
+		}
+		operator, ok := value.(pdfKeyword)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+
+		switch operator {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = f.font(resources, name)
+				}
+			}
+		case "Tj":
+			if len(operands) >= 1 {
+				if s, ok := operands[len(operands)-1].(string); ok {
+					out.write(font.decode(s))
+				}
+			}
+		case "'", "\"":
+			out.newline()
+			if len(operands) >= 1 {
+				if s, ok := operands[len(operands)-1].(string); ok {
+					out.write(font.decode(s))
+				}
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if array, ok := operands[len(operands)-1].([]interface{}); ok {
+					for _, item := range array {
+						switch item := item.(type) {
+						case string:
+							out.write(font.decode(item))
+						case float64:
+							if item <= -200 {
+								out.space()
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if y, ok := operands[len(operands)-1].(float64); ok && y != 0 {
+					out.newline()
+					lineY += y
+				} else {
+					out.space()
+				}
+			}
+		case "T*":
+			out.newline()
+		case "Tm":
+			if len(operands) >= 6 {
+				if y, ok := operands[len(operands)-1].(float64); ok {
+					if hasLine && math.Abs(y-lineY) > 1 {
+						out.newline()
+					} else {
+						out.space()
+					}
+					lineY, hasLine = y, true
+				}
+			}
+		case "ET":
+			out.space()
+		case "Do":
+			if len(operands) >= 1 && depth < maxFormDepth {
+				if name, ok := operands[len(operands)-1].(pdfName); ok {
+					f.extractForm(resources, name, out, depth)
+				}
+			}
+		case "BI":
+			lexer.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// extractForm extracts the text of the form XObject named name in the resources.
+func (f *pdfFile) extractForm(resources pdfDict, name pdfName, out *pdfTextBuilder, depth int) {
+	xObjects := f.dict(resources["XObject"])
+	if xObjects == nil {
+		return
+	}
+	form, ok := f.resolve(xObjects[name]).(pdfStream)
+	if !ok || form.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	content, err := f.decodeStream(form)
+	if err != nil {
+		return
+	}
+	formResources := f.dict(form.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+	out.newline()
+	f.extractText(content, formResources, out, depth+1)
+	out.newline()
+}
+
+// pdfFont decodes the strings shown with a font.
+type pdfFont struct {
+	codeBytes int               // bytes per character code
+	toUnicode map[uint32]string // text of each code, from the ToUnicode map
+	encoding  *[256]rune        // runes of the one byte codes of simple fonts
+}
+
+// font returns the font named name in the resources, or nil when it is not found.
+func (f *pdfFile) font(resources pdfDict, name pdfName) *pdfFont {
+	fonts := f.dict(resources["Font"])
+	if fonts == nil {
+		return nil
+	}
+	ref, isRef := fonts[name].(pdfRef)
+	if isRef {
+		if font, cached := f.fonts[ref]; cached {
+			return font
+		}
+	}
+	dict := f.dict(fonts[name])
+	if dict == nil {
+		return nil
+	}
+
+	font := &pdfFont{codeBytes: 1}
+	if dict["Subtype"] == pdfName("Type0") {
+		font.codeBytes = 2
+	} else {
+		font.encoding = f.fontEncoding(dict["Encoding"])
+	}
+	if stream, ok := f.resolve(dict["ToUnicode"]).(pdfStream); ok {
+		if data, err := f.decodeStream(stream); err == nil {
+			font.toUnicode, font.codeBytes = parseToUnicode(data, font.codeBytes)
+		}
+	}
+	if isRef {
+		f.fonts[ref] = font
+	}
+	return font
+}
+
+// fontEncoding returns the encoding of a simple font: WinAnsiEncoding, or the base encoding changed by the
+// Differences array.
+func (f *pdfFile) fontEncoding(value interface{}) *[256]rune {
+	encoding := winAnsiEncoding
+	dict := f.dict(value)
+	if dict == nil {
+		return &encoding
+	}
+	differences, _ := f.resolve(dict["Differences"]).([]interface{})
+	code := 0
+	for _, item := range differences {
+		switch item := item.(type) {
+		case float64:
+			code = int(item)
+		case pdfName:
+			if code >= 0 && code < 256 {
+				if r, ok := glyphRune(string(item)); ok {
+					encoding[code] = r
+				}
+			}
+			code++
+		}
+	}
+	return &encoding
+}
+
+// decode returns the text of a string shown with the font. A nil font decodes the string as WinAnsiEncoding.
+func (font *pdfFont) decode(s string) string {
+	if font == nil {
+		font = &pdfFont{codeBytes: 1}
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i += font.codeBytes {
+		var code uint32
+		for j := 0; j < font.codeBytes && i+j < len(s); j++ {
+			code = code<<8 | uint32(s[i+j])
+		}
+		if text, ok := font.toUnicode[code]; ok {
+			b.WriteString(text)
+			continue
+		}
+		switch {
+		case font.codeBytes > 1:
+			if r := rune(code); r >= ' ' && utf8.ValidRune(r) {
+				b.WriteRune(r)
+			}
+		case font.encoding != nil:
+			b.WriteRune(font.encoding[code])
+		default:
+			b.WriteRune(winAnsiEncoding[code])
+		}
+	}
+	return b.String()
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap. The code length comes from the
+// codespace ranges, defaulting to codeBytes.
+func parseToUnicode(data []byte, codeBytes int) (map[uint32]string, int) {
+	mappings := map[uint32]string{}
+	lexer := &pdfLexer{data: data}
+	var operands []interface{}
+	for {
+		value, err := lexer.object()
+		if err != nil {
+			break
+		}
+		operator, ok := value.(pdfKeyword)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+		switch operator {
+		case "endcodespacerange":
+			if len(operands) >= 1 {
+				if low, ok := operands[0].(string); ok && len(low) > 0 {
+					codeBytes = len(low)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				source, ok1 := operands[i].(string)
+				target, ok2 := operands[i+1].(string)
+				if ok1 && ok2 {
+					mappings[cmapCode(source)] = utf16BEString(target)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].(string)
+				high, ok2 := operands[i+1].(string)
+				if !ok1 || !ok2 || cmapCode(high) < cmapCode(low) || cmapCode(high)-cmapCode(low) > 0xFFFF {
+					continue
+				}
+				switch target := operands[i+2].(type) {
+				case string:
+					base := []rune(utf16BEString(target))
+					if len(base) == 0 {
+						continue
+					}
+					for code := cmapCode(low); code <= cmapCode(high); code++ {
+						runes := append([]rune(nil), base...)
+						runes[len(runes)-1] += rune(code - cmapCode(low))
+						mappings[code] = string(runes)
+					}
+				case []interface{}:
+					for j, item := range target {
+						if s, ok := item.(string); ok {
+							mappings[cmapCode(low)+uint32(j)] = utf16BEString(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return mappings, codeBytes
+}
+
+// cmapCode returns the big endian number of the bytes of a CMap string.
+func cmapCode(s string) uint32 {
+	var code uint32
+	for i := 0; i < len(s); i++ {
+		code = code<<8 | uint32(s[i])
+	}
+	return code
+}
+
+// utf16BEString decodes UTF-16BE bytes.
+func utf16BEString(s string) string {
+	if len(s)%2 == 1 {
+		s += "\x00"
+	}
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfTextString decodes a text string of the document information: UTF-16BE with a byte order mark, UTF-8 with a
+// byte order mark or PDFDocEncoding, read as WinAnsiEncoding.
+func pdfTextString(value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(s, "\xfe\xff"):
+		return utf16BEString(s[2:])
+	case strings.HasPrefix(s, "\xef\xbb\xbf"):
+		return s[3:]
+	}
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = winAnsiEncoding[s[i]]
+	}
+	return string(runes)
+}
+
+// parsePDFDate parses a PDF date such as "D:20230512103000-03'00'", returning the zero time when it is invalid.
+func parsePDFDate(s string) time.Time {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	digits := 0
+	for digits < len(s) && digits < 14 && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits < 4 {
+		return time.Time{}
+	}
+	value := s[:digits] + "0101000000"[digits-4:]
+	date, err := time.Parse("20060102150405", value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	zone := strings.ReplaceAll(s[digits:], "'", "")
+	if len(zone) >= 3 && (zone[0] == '+' || zone[0] == '-') {
+		hours, _ := strconv.Atoi(zone[1:3])
+		minutes := 0
+		if len(zone) >= 5 {
+			minutes, _ = strconv.Atoi(zone[3:5])
+		}
+		offset := hours*3600 + minutes*60
+		if zone[0] == '-' {
+			offset = -offset
+		}
+		date = time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), 0,
+			time.FixedZone("", offset))
+	}
+	return date
+}
+
+// winAnsiEncoding maps the codes of WinAnsiEncoding (Windows-1252) to runes.
+var winAnsiEncoding = func() [256]rune {
+	var encoding [256]rune
+	for i := range encoding {
+		encoding[i] = rune(i)
+	}
+	for i, r := range []rune("€\u0081‚ƒ„…†‡ˆ‰Š‹Œ\u008dŽ\u008f\u0090‘’“”•–—˜™š›œ\u009džŸ") {
+		encoding[0x80+i] = r
+	}
+	return encoding
+}()
+
+// accentedGlyphs lists the base letters and accented runes of each accent of the glyph names, e.g. "aacute".
+var accentedGlyphs = map[string]string{
+	"grave":      "AÀEÈIÌOÒUÙaàeèiìoòuù",
+	"acute":      "AÁEÉIÍOÓUÚYÝaáeéiíoóuúyý",
+	"circumflex": "AÂEÊIÎOÔUÛaâeêiîoôuû",
+	"tilde":      "AÃNÑOÕaãnñoõ",
+	"dieresis":   "AÄEËIÏOÖUÜaäeëiïoöuüyÿ",
+	"cedilla":    "CÇcç",
+	"ring":       "AÅaå",
+}
+
+// glyphNames maps the glyph names of the Latin fonts to runes.
+var glyphNames = func() map[string]rune {
+	names := map[string]rune{
+		"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%',
+		"ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')', "asterisk": '*', "plus": '+',
+		"comma": ',', "hyphen": '-', "period": '.', "slash": '/', "zero": '0', "one": '1', "two": '2', "three": '3',
+		"four": '4', "five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9', "colon": ':', "semicolon": ';',
+		"less": '<', "equal": '=', "greater": '>', "question": '?', "at": '@', "bracketleft": '[', "backslash": '\\',
+		"bracketright": ']', "asciicircum": '^', "underscore": '_', "grave": '`', "braceleft": '{', "bar": '|',
+		"braceright": '}', "asciitilde": '~', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+		"quotedblright": '”', "quotesinglbase": '‚', "quotedblbase": '„', "endash": '–', "emdash": '—', "bullet": '•',
+		"ellipsis": '…', "degree": '°', "ordfeminine": 'ª', "ordmasculine": 'º', "section": '§', "paragraph": '¶',
+		"copyright": '©', "registered": '®', "trademark": '™', "Euro": '€', "sterling": '£', "yen": '¥',
+		"germandbls": 'ß', "AE": 'Æ', "ae": 'æ', "OE": 'Œ', "oe": 'œ', "Oslash": 'Ø', "oslash": 'ø', "nbspace": ' ',
+		"guillemotleft": '«', "guillemotright": '»', "exclamdown": '¡', "questiondown": '¿', "fi": 'ﬁ', "fl": 'ﬂ',
+	}
+	for accent, letters := range accentedGlyphs {
+		runes := []rune(letters)
+		for i := 0; i+1 < len(runes); i += 2 {
+			names[string(runes[i])+accent] = runes[i+1]
+		}
+	}
+	return names
+}()
+
+// glyphRune returns the rune of a glyph name: a single letter, a known name or a uniXXXX or uXXXX name.
+func glyphRune(name string) (rune, bool) {
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if strings.HasPrefix(name, prefix) && len(name) >= len(prefix)+4 {
+			if code, err := strconv.ParseUint(name[len(prefix):len(prefix)+4], 16, 32); err == nil {
+				return rune(code), true
+			}
+		}
+	}
+	return 0, false
+}
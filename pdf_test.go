@@ -0,0 +1,95 @@
+package goSpider
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// buildPDF assembles a PDF from the bodies of objects 1..n, compressing the streams marked with a "stream:" prefix.
+func buildPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	for i, object := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n", i+1)
+		if content, found := bytes.CutPrefix([]byte(object), []byte("stream:")); found {
+			var compressed bytes.Buffer
+			writer := zlib.NewWriter(&compressed)
+			writer.Write(content)
+			writer.Close()
+			fmt.Fprintf(&b, "<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+			b.Write(compressed.Bytes())
+			b.WriteString("\nendstream")
+		} else {
+			b.WriteString(object)
+		}
+		b.WriteString("\nendobj\n")
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R /Info 2 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func TestReadPDF(t *testing.T) {
+	document, err := ReadPDF("server/files/decision.pdf")
+	if err != nil {
+		t.Fatalf("ReadPDF error: %v", err)
+	}
+	if len(document.Pages) != 1 || document.Pages[0] != "Decision text" {
+		t.Errorf("Expected one page with the decision text, got %q", document.Pages)
+	}
+}
+
+func TestParsePDF(t *testing.T) {
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Title <FEFF00440065006300690073 00E3006F> /Author (Tribunal de Justi\\347a) /CreationDate (D:20230512103000-03'00') >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 2 /Resources << /Font << /F1 8 0 R /F2 9 0 R >> >> >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 7 0 R >>",
+		"stream:BT /F1 12 Tf 72 720 Td (Processo n) Tj (\\272 1017927) Tj 0 -14 Td [(Senten) -10 (\\347a) -250 (proferida)] TJ ET",
+		"stream:BT /F2 12 Tf 1 0 0 1 72 720 Tm <00010002> Tj 1 0 0 1 72 700 Tm <0003> Tj ET",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Differences [186 /ordmasculine 231 /ccedilla] >> >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Arial /ToUnicode 10 0 R >>",
+		"stream:begincmap 1 begincodespacerange <0000> <FFFF> endcodespacerange "+
+			"1 beginbfchar <0003> <00E9> endbfchar 1 beginbfrange <0001> <0002> <0041> endbfrange endcmap",
+	)
+
+	document, err := ParsePDF(data)
+	if err != nil {
+		t.Fatalf("ParsePDF error: %v", err)
+	}
+	if len(document.Pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(document.Pages))
+	}
+	if document.Pages[0] != "Processo nº 1017927\nSentença proferida" {
+		t.Errorf("Unexpected text of the first page: %q", document.Pages[0])
+	}
+	if document.Pages[1] != "AB\né" {
+		t.Errorf("Unexpected text of the second page: %q", document.Pages[1])
+	}
+	if document.Title != "Decisão" || document.Author != "Tribunal de Justiça" {
+		t.Errorf("Unexpected metadata: %q %q", document.Title, document.Author)
+	}
+	want := time.Date(2023, 5, 12, 10, 30, 0, 0, time.FixedZone("", -3*3600))
+	if !document.CreationDate.Equal(want) {
+		t.Errorf("Expected creation date %v, got %v", want, document.CreationDate)
+	}
+
+	nodes, err := FindNodes(document.HTML(), "//div[@class='page'][2]/p")
+	if err != nil || len(nodes) != 2 || nodes[1].FirstChild.Data != "é" {
+		t.Errorf("Expected the second page as paragraphs, got %d nodes: %v", len(nodes), err)
+	}
+}
+
+func TestParsePDFErrors(t *testing.T) {
+	_, err := ParsePDF([]byte("<html></html>"))
+	if err == nil {
+		t.Errorf("Expected an error for a file that is not a PDF")
+	}
+	_, err = ParsePDF([]byte("%PDF-1.4\ntrailer << /Root 1 0 R /Encrypt 5 0 R >>"))
+	if err == nil {
+		t.Errorf("Expected an error for an encrypted PDF")
+	}
+}
@@ -0,0 +1,258 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Plan is the outcome of a dry run: the actions a pipeline would take, with its selectors checked against the cached
+// snapshots of its pages when available.
+type Plan struct {
+	Pipeline string
+	Actions  []PlannedAction
+}
+
+// PlannedAction is a step of a Plan.
+type PlannedAction struct {
+	Step      int // 1-based
+	Action    string
+	Condition string // the expanded Step.If
+	URL       string
+	Value     string
+	Snapshot  string // the URL of the cached page the selectors were checked on; none if empty
+	Selectors []PlannedSelector
+}
+
+// PlannedSelector is a selector or xpath of a PlannedAction.
+type PlannedSelector struct {
+	Role     string // "selector", "next", "rows", "field NAME", ...
+	Selector string
+	Checked  bool // whether it was checked against a snapshot
+	Matches  int
+	Problem  string // why it couldn't be checked, or the error of an invalid xpath
+}
+
+// DryRun plans the steps of p with vars, without a Browser or the network. The variables extracted while running
+// and the environment variables are left as references, so no secret is printed. When snapshots is set, the selectors
+// of each step are checked against the cached copy of the last page opened, see goSpider.PageCache.
+// Example:
+//
+//	cache, err := goSpider.NewPageCache("cache", 30*24*time.Hour)
+//	plan := pipeline.DryRun(p, vars, cache)
+//	fmt.Print(plan)
+func DryRun(p *Pipeline, vars map[string]string, snapshots *goSpider.PageCache) *Plan {
+	known := make(map[string]string)
+	for name, value := range p.Vars {
+		known[name] = value
+	}
+	for name, value := range vars {
+		known[name] = value
+	}
+	expand := func(value string) string {
+		return variable.ReplaceAllStringFunc(value, func(reference string) string {
+			if v, ok := known[variable.FindStringSubmatch(reference)[1]]; ok {
+				return v
+			}
+			return reference
+		})
+	}
+
+	plan := &Plan{Pipeline: p.Name}
+	var snapshotURL string
+	var snapshot *html.Node
+	for i, step := range p.Steps {
+		action := PlannedAction{Step: i + 1, Action: step.Action, Condition: expand(step.If), URL: expand(step.URL)}
+		switch step.Action {
+		case Open, Login:
+			snapshotURL, snapshot = "", nil
+			if snapshots != nil {
+				if body, ok := snapshots.Get(action.URL); ok {
+					snapshotURL = action.URL
+					snapshot, _ = html.Parse(strings.NewReader(body))
+				}
+			}
+		case Fill:
+			action.Value = expand(step.Value)
+		case Output:
+			action.Value = expand(step.To)
+		}
+		if step.Action == Login {
+			action.Value = expand(step.Username)
+		}
+		if snapshot != nil {
+			action.Snapshot = snapshotURL
+		}
+
+		context := snapshot
+		add := func(role, selector string, xpath bool) {
+			if selector == "" {
+				return
+			}
+			action.Selectors = append(action.Selectors, checkSelector(context, role, expand(selector), xpath))
+		}
+		if selector, ok := strings.CutPrefix(strings.TrimPrefix(action.Condition, "not "), "exists "); ok {
+			add("if", strings.TrimSpace(selector), false)
+		}
+		add("selector", step.Selector, false)
+		add("username", step.UsernameSelector, false)
+		add("password", step.PasswordSelector, false)
+		add("submit", step.SubmitSelector, false)
+		add("rows", step.Rows, true)
+		if step.Rows != "" && snapshot != nil {
+			// fields are relative to the rows
+			context = nil
+			if rows, err := goSpider.FindNodes(snapshot, expand(step.Rows)); err == nil {
+				context = rows[0]
+			}
+		}
+		names := make([]string, 0, len(step.Fields))
+		for name := range step.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add("field "+name, step.Fields[name], true)
+		}
+		context = snapshot
+		add("next", step.Next, false)
+		plan.Actions = append(plan.Actions, action)
+	}
+	return plan
+}
+
+// checkSelector counts the matches of a css selector or xpath, or of an xpath only when xpath is set, in the node of a
+// snapshot. It is left unchecked without a node.
+func checkSelector(node *html.Node, role, selector string, xpath bool) PlannedSelector {
+	result := PlannedSelector{Role: role, Selector: selector}
+	if node == nil {
+		return result
+	}
+	expression := selector
+	if !xpath && !strings.HasPrefix(selector, "/") && !strings.HasPrefix(selector, "(") {
+		var ok bool
+		expression, ok = cssToXPath(selector)
+		if !ok {
+			result.Problem = "css selector too complex to check"
+			return result
+		}
+	}
+	nodes, err := goSpider.FindNodes(node, expression)
+	if err != nil && !errors.Is(err, goSpider.ErrElementNotFound) {
+		result.Problem = err.Error()
+		return result
+	}
+	result.Checked = true
+	result.Matches = len(nodes)
+	return result
+}
+
+// cssCompound matches a compound css selector: a tag, an id, classes and attributes, e.g. "input#name.big[type=text]".
+var cssCompound = regexp.MustCompile(`^([a-zA-Z][\w-]*|\*)?((?:#[\w-]+|\.[\w-]+|\[[\w-]+(?:[~|^$*]?=(?:"[^"]*"|'[^']*'|[^\]]*))?\])*)$`)
+
+// cssPart matches a part of a compound css selector.
+var cssPart = regexp.MustCompile(`#[\w-]+|\.[\w-]+|\[([\w-]+)(?:=(?:"([^"]*)"|'([^']*)'|([^\]]*)))?\]`)
+
+// cssToXPath converts a css selector of compound selectors joined by descendant and child combinators to an xpath, or
+// reports that it is too complex.
+func cssToXPath(selector string) (string, bool) {
+	fields := strings.Fields(strings.ReplaceAll(selector, ">", " > "))
+	if len(fields) == 0 || strings.Contains(selector, ",") {
+		return "", false
+	}
+	var xpath strings.Builder
+	axis := "//"
+	for _, field := range fields {
+		if field == ">" {
+			if axis == "/" || xpath.Len() == 0 {
+				return "", false
+			}
+			axis = "/"
+			continue
+		}
+		match := cssCompound.FindStringSubmatch(field)
+		if match == nil || strings.ContainsAny(match[2], "~|^$*") {
+			return "", false
+		}
+		tag := match[1]
+		if tag == "" {
+			tag = "*"
+		}
+		xpath.WriteString(axis + tag)
+		for _, part := range cssPart.FindAllStringSubmatch(match[2], -1) {
+			switch {
+			case part[0][0] == '#':
+				fmt.Fprintf(&xpath, "[@id=%q]", part[0][1:])
+			case part[0][0] == '.':
+				fmt.Fprintf(&xpath, "[contains(concat(' ', normalize-space(@class), ' '), ' %s ')]", part[0][1:])
+			case strings.Contains(part[0], "="):
+				fmt.Fprintf(&xpath, "[@%s=%q]", part[1], part[2]+part[3]+part[4])
+			default:
+				fmt.Fprintf(&xpath, "[@%s]", part[1])
+			}
+		}
+		axis = "//"
+	}
+	if axis == "/" {
+		return "", false
+	}
+	return xpath.String(), true
+}
+
+// Problems returns a line per selector that matched nothing in its snapshot or couldn't be checked because it is
+// invalid.
+func (p *Plan) Problems() []string {
+	var problems []string
+	for _, action := range p.Actions {
+		for _, selector := range action.Selectors {
+			switch {
+			case selector.Checked && selector.Matches == 0:
+				problems = append(problems, fmt.Sprintf("step %d (%s): %s %s matches nothing in %s", action.Step,
+					action.Action, selector.Role, selector.Selector, action.Snapshot))
+			case selector.Problem != "" && !strings.HasPrefix(selector.Problem, "css"):
+				problems = append(problems, fmt.Sprintf("step %d (%s): %s %s: %s", action.Step, action.Action,
+					selector.Role, selector.Selector, selector.Problem))
+			}
+		}
+	}
+	return problems
+}
+
+// String formats the plan with a line per action and per selector.
+func (p *Plan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Pipeline %s: %d steps (dry run)\n", p.Pipeline, len(p.Actions))
+	for _, action := range p.Actions {
+		fmt.Fprintf(&sb, "%d. %s", action.Step, action.Action)
+		if action.URL != "" {
+			fmt.Fprintf(&sb, " %s", action.URL)
+		}
+		if action.Value != "" {
+			fmt.Fprintf(&sb, " %q", action.Value)
+		}
+		if action.Condition != "" {
+			fmt.Fprintf(&sb, " if %s", action.Condition)
+		}
+		if action.Snapshot != "" && (action.Action == Open || action.Action == Login) {
+			sb.WriteString(" (cached snapshot)")
+		}
+		sb.WriteString("\n")
+		for _, selector := range action.Selectors {
+			fmt.Fprintf(&sb, "   %s %s", selector.Role, selector.Selector)
+			switch {
+			case selector.Problem != "":
+				fmt.Fprintf(&sb, ": %s", selector.Problem)
+			case selector.Checked && selector.Matches == 1:
+				sb.WriteString(": 1 match")
+			case selector.Checked:
+				fmt.Fprintf(&sb, ": %d matches", selector.Matches)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanielFillol/goSpider"
+)
+
+func TestDryRun(t *testing.T) {
+	p, err := Parse([]byte(`
+name: movements
+vars:
+  number: "1"
+steps:
+  - action: login
+    url: https://example.com/login
+    username: "{{env.USER}}"
+    password: secret
+    username_selector: "#user"
+    password_selector: "#pass"
+    submit_selector: "#login"
+  - action: open
+    url: https://example.com/lawsuit/{{number}}
+  - action: fill
+    selector: "form > input#search[name=q]"
+    value: "{{token}}"
+  - action: paginate
+    if: "exists .movements"
+    rows: //table[@id='movements']//tr
+    fields:
+      date: ./td[1]
+      title: ./td[3]
+    next: a:not(.disabled)
+  - action: click
+    selector: "#missing"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := goSpider.NewPageCache("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cache.Set("https://example.com/lawsuit/42", `<html><body><form><input id="search" name="q"></form>
+<div class="x movements"><table id="movements"><tr><td>01/01</td><td></td><td>Filed</td></tr></table></div></body></html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := DryRun(p, map[string]string{"number": "42"}, cache)
+	if len(plan.Actions) != 5 {
+		t.Fatalf("Expected 5 actions, but got %d", len(plan.Actions))
+	}
+	login := plan.Actions[0]
+	if login.Value != "{{env.USER}}" || login.Snapshot != "" || strings.Contains(plan.String(), "secret") {
+		t.Errorf("Expected the login without environment variables, password nor snapshot, but got %+v", login)
+	}
+	if plan.Actions[1].URL != "https://example.com/lawsuit/42" || plan.Actions[1].Snapshot != plan.Actions[1].URL {
+		t.Errorf("Expected the open step on the cached snapshot, but got %+v", plan.Actions[1])
+	}
+	if fill := plan.Actions[2]; fill.Value != "{{token}}" || !fill.Selectors[0].Checked || fill.Selectors[0].Matches != 1 {
+		t.Errorf("Expected the fill selector to match once, but got %+v", fill)
+	}
+
+	matches := map[string]int{}
+	for _, selector := range plan.Actions[3].Selectors {
+		if !selector.Checked {
+			matches[selector.Role] = -1
+			continue
+		}
+		matches[selector.Role] = selector.Matches
+	}
+	expected := map[string]int{"if": 1, "rows": 1, "field date": 1, "field title": 1, "next": -1}
+	for role, n := range expected {
+		if matches[role] != n {
+			t.Errorf("Expected %d matches of %s, but got %d", n, role, matches[role])
+		}
+	}
+
+	problems := plan.Problems()
+	if len(problems) != 1 || !strings.Contains(problems[0], "step 5 (click): selector #missing matches nothing") {
+		t.Errorf("Expected the missing selector as the only problem, but got %q", problems)
+	}
+	if !strings.Contains(plan.String(), "2. open https://example.com/lawsuit/42 (cached snapshot)") {
+		t.Errorf("Unexpected plan:\n%s", plan)
+	}
+}
+
+func TestCSSToXPath(t *testing.T) {
+	tests := map[string]string{
+		"#id":                `//*[@id="id"]`,
+		"div.a > span":       `//div[contains(concat(' ', normalize-space(@class), ' '), ' a ')]/span`,
+		"ul li a[href]":      `//ul//li//a[@href]`,
+		"input[type='text']": `//input[@type="text"]`,
+		"a:hover":            "",
+		"a, b":               "",
+		"> a":                "",
+		"[href^=http]":       "",
+	}
+	for selector, expected := range tests {
+		xpath, ok := cssToXPath(selector)
+		if xpath != expected || ok != (expected != "") {
+			t.Errorf("cssToXPath(%q) = %q, %v, expected %q", selector, xpath, ok, expected)
+		}
+	}
+}
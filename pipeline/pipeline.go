@@ -0,0 +1,263 @@
+/*
+Package pipeline runs declarative crawls: a list of steps loaded from a JSON or YAML file, so a new site can be crawled
+from the gospider command or its HTTP server without writing Go.
+
+	name: tjsp
+	vars:
+	  number: 1017927-35.2023.8.26.0008
+	steps:
+	  - action: open
+	    url: https://esaj.tjsp.jus.br/cpopg/open.do
+	  - action: fill
+	    selector: "#numeroDigitoAnoUnificado"
+	    value: "{{number}}"
+	  - action: click
+	    selector: "#botaoConsultarProcessos"
+	  - action: wait
+	    selector: "#tablePartesPrincipais"
+	    timeout: 10s
+	  - action: extract
+	    fields:
+	      class: //*[@id='classeProcesso']
+	  - action: paginate
+	    if: "exists #movimentacoes"
+	    rows: //*[@id='tabelaTodasMovimentacoes']/tr
+	    fields:
+	      date: ./td[1]
+	      title: ./td[3]
+	    next: "#nextPage"
+	  - action: output
+	    to: movements.jsonl
+
+The actions are open, login, fill, click, wait, extract, paginate and output, see Step. Step values can refer to
+variables as {{name}}: the vars of the pipeline, the variables given to Run, the fields of extract steps without rows,
+and environment variables as {{env.NAME}} when the Runner allows it, which keeps credentials out of pipeline files. A step with an "if" condition
+runs only when the condition holds, see Step.If. As in any YAML, quote values starting with "#", which would start a
+comment, or with "{".
+
+A pipeline without output steps outputs its records at the end, as if it ended with an output step.
+*/
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Actions of the steps.
+const (
+	Open     = "open"
+	Login    = "login"
+	Fill     = "fill"
+	Click    = "click"
+	Wait     = "wait"
+	Extract  = "extract"
+	Paginate = "paginate"
+	Output   = "output"
+)
+
+// Pipeline is a declarative crawl, run step by step on a Browser.
+type Pipeline struct {
+	Name  string `json:"name"`
+	Vars  Vars   `json:"vars,omitempty"` // default values of the variables
+	Steps []Step `json:"steps"`
+}
+
+// Step is an action of a pipeline, with the settings of its action:
+//   - open: opens URL
+//   - login: opens URL and logs in with Username and Password, see goSpider.Navigator.Login
+//   - fill: fills the field of Selector with Value
+//   - click: clicks the element of Selector
+//   - wait: waits up to Timeout for the element of Selector, or waits Timeout when Selector is empty
+//   - extract: extracts a record of Fields, or a record of Fields per node of Rows
+//   - paginate: extracts like extract, then clicks Next and extracts again until Next disappears, the page stops
+//     changing or MaxPages pages were extracted
+//   - output: writes the records extracted since the last output step to To
+type Step struct {
+	Action string `json:"action"`
+	// If is a condition checked before the step, which is skipped when it doesn't hold: "exists SELECTOR",
+	// "A == B", "A != B" or a value, which holds unless it is empty, "false" or "0". "not" negates a condition, as
+	// in "not exists #captcha".
+	If string `json:"if,omitempty"`
+
+	URL      string `json:"url,omitempty"`
+	Selector string `json:"selector,omitempty"` // css selector or xpath
+	Value    string `json:"value,omitempty"`
+	Timeout  string `json:"timeout,omitempty"` // e.g. "10s"; the timeout of the Browser if empty
+
+	Username         string `json:"username,omitempty"`
+	Password         string `json:"password,omitempty"`
+	UsernameSelector string `json:"username_selector,omitempty"`
+	PasswordSelector string `json:"password_selector,omitempty"`
+	SubmitSelector   string `json:"submit_selector,omitempty"`
+	FailureMessage   string `json:"failure_message,omitempty"` // text shown when the login fails
+
+	// Rows is the xpath of the nodes of the records; Fields are relative to them, as in "./td[1]". Without Rows,
+	// Fields are xpaths of the page, each set as a variable too.
+	Rows     string            `json:"rows,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Next     string            `json:"next,omitempty"`      // css selector or xpath of the "next page" element
+	MaxPages int               `json:"max_pages,omitempty"` // goSpider.DefaultMaxPages if not positive
+
+	// To is the name of a sink of the Runner, a JSON lines file in the Runner directory, or the Runner output when
+	// empty.
+	To string `json:"to,omitempty"`
+}
+
+// Vars are the variables of a pipeline. Numbers and booleans are decoded as their text, so YAML values don't need
+// quotes.
+type Vars map[string]string
+
+// UnmarshalJSON decodes an object of strings, numbers and booleans.
+func (v *Vars) UnmarshalJSON(data []byte) error {
+	var values map[string]interface{}
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+	*v = make(Vars, len(values))
+	for name, value := range values {
+		switch value := value.(type) {
+		case string:
+			(*v)[name] = value
+		case float64:
+			(*v)[name] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			(*v)[name] = strconv.FormatBool(value)
+		case nil:
+			(*v)[name] = ""
+		default:
+			return fmt.Errorf("variable %s is not a string, a number or a boolean", name)
+		}
+	}
+	return nil
+}
+
+// Parse parses a pipeline in JSON, or in YAML when data isn't a JSON object, and checks its steps. YAML pipelines are
+// limited to block mappings and sequences, flow collections on one line, quoted and plain scalars and comments.
+// Example:
+//
+//	p, err := pipeline.Parse([]byte(`{"steps": [{"action": "open", "url": "https://example.com"}]}`))
+func Parse(data []byte) (*Pipeline, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		value, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid pipeline: %w", err)
+		}
+		data, err = json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid pipeline: %w", err)
+		}
+	}
+
+	var p Pipeline
+	err := json.Unmarshal(data, &p)
+	if err != nil {
+		return nil, fmt.Errorf("error - invalid pipeline: %w", err)
+	}
+	err = p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Load reads and parses a pipeline file.
+// Example:
+//
+//	p, err := pipeline.Load("tjsp.yaml")
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read pipeline: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks that the pipeline has steps and that each step has the settings of its action.
+func (p *Pipeline) Validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("error - invalid pipeline: no steps")
+	}
+	for i, step := range p.Steps {
+		err := step.validate()
+		if err != nil {
+			return fmt.Errorf("error - invalid pipeline step %d (%s): %w", i+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the settings of the step action.
+func (s Step) validate() error {
+	var missing string
+	switch s.Action {
+	case Open:
+		if s.URL == "" {
+			missing = "url"
+		}
+	case Login:
+		switch "" {
+		case s.URL:
+			missing = "url"
+		case s.Username:
+			missing = "username"
+		case s.Password:
+			missing = "password"
+		case s.UsernameSelector:
+			missing = "username_selector"
+		case s.PasswordSelector:
+			missing = "password_selector"
+		case s.SubmitSelector:
+			missing = "submit_selector"
+		}
+	case Fill, Click:
+		if s.Selector == "" {
+			missing = "selector"
+		}
+	case Wait:
+		if s.Selector == "" && s.Timeout == "" {
+			missing = "selector or timeout"
+		}
+	case Extract:
+		if len(s.Fields) == 0 {
+			missing = "fields"
+		}
+	case Paginate:
+		if len(s.Fields) == 0 {
+			missing = "fields"
+		} else if s.Next == "" {
+			missing = "next"
+		}
+	case Output:
+	case "":
+		return errors.New("missing action")
+	default:
+		return fmt.Errorf("unknown action %q", s.Action)
+	}
+	if missing != "" {
+		return fmt.Errorf("missing %s", missing)
+	}
+	if s.Timeout != "" {
+		if _, err := time.ParseDuration(s.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasOutput reports whether the pipeline has an output step.
+func (p *Pipeline) hasOutput() bool {
+	for _, step := range p.Steps {
+		if step.Action == Output {
+			return true
+		}
+	}
+	return false
+}
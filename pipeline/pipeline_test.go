@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	yamlPipeline := `
+# search a lawsuit
+name: search
+vars: {number: 1017927, retry: true}
+steps:
+  - action: open
+    url: https://example.com/search?q=a#b
+  - action: wait
+    timeout: 2s
+  - action: paginate
+    rows: //tr
+    fields:
+      date: ./td[1]   # the first cell
+    next: "#next"
+    max_pages: 5
+`
+	jsonPipeline := `{"name": "search", "vars": {"number": 1017927, "retry": true}, "steps": [
+		{"action": "open", "url": "https://example.com/search?q=a#b"},
+		{"action": "wait", "timeout": "2s"},
+		{"action": "paginate", "rows": "//tr", "fields": {"date": "./td[1]"}, "next": "#next", "max_pages": 5}
+	]}`
+
+	for name, data := range map[string]string{"yaml": yamlPipeline, "json": jsonPipeline} {
+		p, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("Parse %s error: %v", name, err)
+		}
+		if p.Name != "search" || p.Vars["number"] != "1017927" || p.Vars["retry"] != "true" {
+			t.Errorf("Unexpected %s pipeline %+v", name, p)
+		}
+		if len(p.Steps) != 3 || p.Steps[0].URL != "https://example.com/search?q=a#b" || p.Steps[1].Timeout != "2s" {
+			t.Fatalf("Unexpected %s steps %+v", name, p.Steps)
+		}
+		paginate := p.Steps[2]
+		if paginate.Next != "#next" || paginate.MaxPages != 5 || paginate.Fields["date"] != "./td[1]" {
+			t.Errorf("Unexpected %s paginate step %+v", name, paginate)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no steps":         `{"name": "empty"}`,
+		"unknown action":   `{"steps": [{"action": "scroll"}]}`,
+		"missing action":   `{"steps": [{"url": "https://example.com"}]}`,
+		"missing url":      `{"steps": [{"action": "open"}]}`,
+		"missing password": `{"steps": [{"action": "login", "url": "https://example.com", "username": "a"}]}`,
+		"missing next":     `{"steps": [{"action": "paginate", "fields": {"a": "//a"}}]}`,
+		"invalid timeout":  `{"steps": [{"action": "wait", "timeout": "soon"}]}`,
+		"invalid json":     `{"steps": [}`,
+		"invalid yaml":     "steps:\n  - action: open\n   url: https://example.com",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		} else if !strings.HasPrefix(err.Error(), "error - invalid pipeline") {
+			t.Errorf("Unexpected error for %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	err := os.WriteFile(path, []byte("steps:\n- action: open\n  url: https://example.com\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil || len(p.Steps) != 1 || p.Steps[0].URL != "https://example.com" {
+		t.Errorf("Expected a pipeline with an open step, got %+v (%v)", p, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
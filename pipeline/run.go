@@ -0,0 +1,333 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// variable matches a {{name}} reference.
+var variable = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+
+// Runner runs pipelines and delivers the records of their output steps.
+type Runner struct {
+	Sinks  map[string]goSpider.Sink // sinks of the output steps, by name
+	Output io.Writer                // JSON lines of the output steps without a destination; discarded if nil
+	Dir    string                   // directory of the files of the output steps; output to files fails if empty
+	// Getenv expands the {{env.NAME}} references, which fail when it is nil, as pipelines sent by others shouldn't
+	// read the environment.
+	Getenv func(name string) string
+}
+
+// Result is the outcome of a pipeline run.
+type Result struct {
+	Records []goSpider.Record // every record extracted, including the ones already output
+	Vars    map[string]string // the variables at the end of the run
+	Steps   int               // steps run, without the skipped ones
+}
+
+// run is the state of a pipeline run.
+type run struct {
+	runner  *Runner
+	nav     goSpider.Browser
+	result  *Result
+	pending []goSpider.Record // records extracted since the last output step
+}
+
+// Run runs the steps of p in nav. vars override the vars of the pipeline. It stops at the first failing step and
+// returns the result so far with the error.
+// Example:
+//
+//	p, err := pipeline.Load("tjsp.yaml")
+//	runner := &pipeline.Runner{Output: os.Stdout, Dir: ".", Getenv: os.Getenv}
+//	result, err := runner.Run(nav, p, map[string]string{"number": "1017927-35.2023.8.26.0008"})
+func (r *Runner) Run(nav goSpider.Browser, p *Pipeline, vars map[string]string) (*Result, error) {
+	state := &run{runner: r, nav: nav, result: &Result{Vars: make(map[string]string)}}
+	for name, value := range p.Vars {
+		state.result.Vars[name] = value
+	}
+	for name, value := range vars {
+		state.result.Vars[name] = value
+	}
+
+	logger := nav.GetLogger()
+	for i, step := range p.Steps {
+		if step.If != "" {
+			ok, err := state.condition(step.If)
+			if err != nil {
+				return state.result, fmt.Errorf("error - pipeline step %d (%s) failed: %w", i+1, step.Action, err)
+			}
+			if !ok {
+				logger.Printf("Pipeline %s: skipping step %d (%s), the condition %q doesn't hold\n", p.Name, i+1, step.Action, step.If)
+				continue
+			}
+		}
+		logger.Printf("Pipeline %s: running step %d (%s)\n", p.Name, i+1, step.Action)
+		err := state.step(step)
+		if err != nil {
+			logger.Printf("Error - Pipeline %s step %d (%s) failed: %v\n", p.Name, i+1, step.Action, err)
+			return state.result, fmt.Errorf("error - pipeline step %d (%s) failed: %w", i+1, step.Action, err)
+		}
+		state.result.Steps++
+	}
+	if !p.hasOutput() {
+		err := state.output("")
+		if err != nil {
+			return state.result, fmt.Errorf("error - pipeline output failed: %w", err)
+		}
+	}
+	return state.result, nil
+}
+
+// step runs a step whose condition holds.
+func (s *run) step(step Step) error {
+	// the settings referring to variables
+	var err error
+	expand := func(value string) string {
+		if err != nil {
+			return ""
+		}
+		var expanded string
+		expanded, err = s.expand(value)
+		return expanded
+	}
+	url, selector, value := expand(step.URL), expand(step.Selector), expand(step.Value)
+	if err != nil {
+		return err
+	}
+	timeout := s.nav.GetTimeOut()
+	if step.Timeout != "" {
+		timeout, _ = time.ParseDuration(step.Timeout)
+	}
+
+	switch step.Action {
+	case Open:
+		return s.nav.OpenURL(url)
+	case Login:
+		username, password := expand(step.Username), expand(step.Password)
+		if err != nil {
+			return err
+		}
+		return s.nav.Login(url, username, password, step.UsernameSelector, step.PasswordSelector, step.SubmitSelector, step.FailureMessage)
+	case Fill:
+		return s.nav.FillField(selector, value)
+	case Click:
+		return s.nav.ClickButton(selector)
+	case Wait:
+		if selector == "" {
+			time.Sleep(timeout)
+			return nil
+		}
+		return s.nav.WaitForElement(selector, timeout)
+	case Extract:
+		pageSource, err := s.nav.GetPageSource()
+		if err != nil {
+			return err
+		}
+		return s.extract(pageSource, step)
+	case Paginate:
+		return s.paginate(step)
+	case Output:
+		return s.output(expand(step.To))
+	}
+	return fmt.Errorf("unknown action %q", step.Action)
+}
+
+// extract adds the records of the step on the page. The fields of a record without rows are set as variables.
+func (s *run) extract(pageSource *html.Node, step Step) error {
+	names := make([]string, 0, len(step.Fields))
+	for name := range step.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := []*html.Node{pageSource}
+	if step.Rows != "" {
+		var err error
+		rows, err = goSpider.FindNodes(pageSource, step.Rows)
+		if errors.Is(err, goSpider.ErrElementNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		record := make(goSpider.Record, len(names))
+		for _, name := range names {
+			text, err := goSpider.ExtractText(row, step.Fields[name], "")
+			if err != nil && !errors.Is(err, goSpider.ErrElementNotFound) {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+			record[name] = strings.Join(strings.Fields(text), " ")
+			if step.Rows == "" {
+				s.result.Vars[name] = record[name].(string)
+			}
+		}
+		s.result.Records = append(s.result.Records, record)
+		s.pending = append(s.pending, record)
+	}
+	return nil
+}
+
+// paginate extracts the records of each page, clicking the next page element until it disappears, the page stops
+// changing or the max pages are extracted.
+func (s *run) paginate(step Step) error {
+	next, err := s.expand(step.Next)
+	if err != nil {
+		return err
+	}
+	maxPages := step.MaxPages
+	if maxPages <= 0 {
+		maxPages = goSpider.DefaultMaxPages
+	}
+
+	var previous string
+	for page := 1; ; page++ {
+		pageSource, err := s.nav.GetPageSource()
+		if err != nil {
+			return err
+		}
+		current, err := goSpider.ParseHtmlToString(pageSource)
+		if err != nil {
+			return err
+		}
+		if current == previous {
+			return nil
+		}
+		previous = current
+
+		err = s.extract(pageSource, step)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", page, err)
+		}
+		if page >= maxPages {
+			return nil
+		}
+		exists, err := s.nav.ElementExists(next)
+		if err != nil || !exists {
+			return err
+		}
+		err = s.nav.ClickButton(next)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// output delivers the pending records to a sink, a file or the output of the runner.
+func (s *run) output(to string) error {
+	records := s.pending
+	s.pending = nil
+	if len(records) == 0 {
+		return nil
+	}
+	if sink, ok := s.runner.Sinks[to]; ok {
+		return sink.Write(records)
+	}
+	if to == "" {
+		if s.runner.Output == nil {
+			return nil
+		}
+		return writeRecords(s.runner.Output, records)
+	}
+
+	if s.runner.Dir == "" {
+		return fmt.Errorf("no sink %q and output to files is disabled", to)
+	}
+	path := filepath.Join(s.runner.Dir, filepath.Clean("/"+to))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	err = writeRecords(f, records)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeRecords writes the records as JSON lines.
+func writeRecords(w io.Writer, records []goSpider.Record) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		err := encoder.Encode(record)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expand replaces the {{name}} references of value by the variables, or by the environment variable NAME for
+// {{env.NAME}}. It fails on undefined variables and on environment variables when the runner has no Getenv.
+func (s *run) expand(value string) (string, error) {
+	var err error
+	expanded := variable.ReplaceAllStringFunc(value, func(reference string) string {
+		name := variable.FindStringSubmatch(reference)[1]
+		if env, ok := strings.CutPrefix(name, "env."); ok {
+			if s.runner.Getenv == nil {
+				if err == nil {
+					err = fmt.Errorf("environment variable %q not allowed", env)
+				}
+				return ""
+			}
+			return s.runner.Getenv(env)
+		}
+		v, ok := s.result.Vars[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined variable %q", name)
+		}
+		return v
+	})
+	return expanded, err
+}
+
+// condition evaluates the condition of a step, see Step.If.
+func (s *run) condition(condition string) (bool, error) {
+	condition, err := s.expand(condition)
+	if err != nil {
+		return false, err
+	}
+	return s.evaluate(strings.TrimSpace(condition))
+}
+
+// evaluate evaluates an expanded condition.
+func (s *run) evaluate(condition string) (bool, error) {
+	if rest, ok := strings.CutPrefix(condition, "not "); ok {
+		holds, err := s.evaluate(strings.TrimSpace(rest))
+		return !holds, err
+	}
+	if selector, ok := strings.CutPrefix(condition, "exists "); ok {
+		return s.nav.ElementExists(strings.TrimSpace(selector))
+	}
+	if a, b, ok := strings.Cut(condition, "!="); ok {
+		return unquote(a) != unquote(b), nil
+	}
+	if a, b, ok := strings.Cut(condition, "=="); ok {
+		return unquote(a) == unquote(b), nil
+	}
+	switch unquote(condition) {
+	case "", "false", "0":
+		return false, nil
+	}
+	return true, nil
+}
+
+// unquote trims the spaces and the quotes around an operand of a condition.
+func unquote(operand string) string {
+	operand = strings.TrimSpace(operand)
+	if len(operand) >= 2 && (operand[0] == '"' || operand[0] == '\'') && operand[len(operand)-1] == operand[0] {
+		return operand[1 : len(operand)-1]
+	}
+	return operand
+}
@@ -0,0 +1,283 @@
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+)
+
+// siteBrowser is a Browser over HTML pages by URL. Clicking an element with a data-href attribute opens its page and
+// selectors are xpaths.
+type siteBrowser struct {
+	goSpider.Browser
+	pages  map[string]string
+	page   string
+	filled map[string]string
+	logins []string
+}
+
+func (b *siteBrowser) GetLogger() *log.Logger    { return log.New(io.Discard, "", 0) }
+func (b *siteBrowser) GetTimeOut() time.Duration { return time.Millisecond }
+func (b *siteBrowser) GetPageSource() (*html.Node, error) {
+	return html.Parse(strings.NewReader(b.pages[b.page]))
+}
+
+func (b *siteBrowser) OpenURL(url string) error {
+	if _, ok := b.pages[url]; !ok {
+		return errors.New("net::ERR_NAME_NOT_RESOLVED")
+	}
+	b.page = url
+	return nil
+}
+
+func (b *siteBrowser) find(selector string) ([]*html.Node, error) {
+	pageSource, _ := b.GetPageSource()
+	nodes, err := goSpider.FindNodes(pageSource, selector)
+	if errors.Is(err, goSpider.ErrElementNotFound) {
+		return nil, nil
+	}
+	return nodes, err
+}
+
+func (b *siteBrowser) ElementExists(selector string) (bool, error) {
+	nodes, err := b.find(selector)
+	return len(nodes) > 0, err
+}
+
+func (b *siteBrowser) WaitForElement(selector string, timeout time.Duration) error {
+	nodes, err := b.find(selector)
+	if err == nil && len(nodes) == 0 {
+		err = goSpider.ErrTimeout
+	}
+	return err
+}
+
+func (b *siteBrowser) FillField(selector, value string) error {
+	b.filled[selector] = value
+	return nil
+}
+
+func (b *siteBrowser) ClickButton(selector string) error {
+	nodes, err := b.find(selector)
+	if err != nil || len(nodes) == 0 {
+		return goSpider.ErrElementNotFound
+	}
+	for _, attr := range nodes[0].Attr {
+		if attr.Key == "data-href" {
+			return b.OpenURL(attr.Val)
+		}
+	}
+	return nil
+}
+
+func (b *siteBrowser) Login(url, username, password, usernameSelector, passwordSelector, loginButtonSelector string, messageFailedSuccess string) error {
+	b.logins = append(b.logins, username+":"+password)
+	return b.OpenURL(url)
+}
+
+func newSiteBrowser() *siteBrowser {
+	return &siteBrowser{filled: map[string]string{}, pages: map[string]string{
+		"https://example.com/search": `<h1>Search</h1><input id="number"><button id="go" data-href="https://example.com/results?page=1">Go</button>`,
+		"https://example.com/results?page=1": `<h1 id="title">Lawsuit 123</h1>
+			<table><tr><td>01/02</td><td>Filed</td></tr><tr><td>03/04</td><td>Decided</td></tr></table>
+			<a id="next" data-href="https://example.com/results?page=2">Next</a>`,
+		"https://example.com/results?page=2": `<h1 id="title">Lawsuit 123</h1>
+			<table><tr><td>05/06</td><td>Archived</td></tr></table>`,
+	}}
+}
+
+const searchPipeline = `
+name: search
+vars:
+  number: 123
+steps:
+  - action: open
+    url: https://example.com/search
+  - action: fill
+    selector: //*[@id='number']
+    value: "{{number}}"
+  - action: click
+    selector: //*[@id='go']
+  - action: wait
+    selector: //table
+  - action: extract
+    fields:
+      title: //*[@id='title']
+  - action: output
+    to: cover
+  - action: paginate
+    if: "{{title}} != ''"
+    rows: //tr
+    fields:
+      date: ./td[1]
+      text: ./td[2]
+    next: //*[@id='next']
+  - action: click
+    if: not exists //*[@id='captcha']
+    selector: //h1
+`
+
+func TestRun(t *testing.T) {
+	p, err := Parse([]byte(searchPipeline))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	nav := newSiteBrowser()
+	var covers []goSpider.Record
+	var output bytes.Buffer
+	runner := &Runner{Output: &output, Sinks: map[string]goSpider.Sink{
+		"cover": goSpider.SinkFunc(func(records []goSpider.Record) error {
+			covers = append(covers, records...)
+			return nil
+		}),
+	}}
+	result, err := runner.Run(nav, p, map[string]string{"number": "1017927-35.2023.8.26.0008"})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if nav.filled["//*[@id='number']"] != "1017927-35.2023.8.26.0008" {
+		t.Errorf("Expected the number variable to be filled, got %v", nav.filled)
+	}
+	if len(covers) != 1 || covers[0]["title"] != "Lawsuit 123" || result.Vars["title"] != "Lawsuit 123" {
+		t.Errorf("Expected the cover record in its sink and as a variable, got %v and %v", covers, result.Vars)
+	}
+	if len(result.Records) != 4 || result.Steps != 8 {
+		t.Errorf("Expected 4 records in 8 steps, got %d in %d", len(result.Records), result.Steps)
+	}
+	// the pipeline has an output step, so the movements extracted after it aren't output at the end
+	if output.Len() != 0 {
+		t.Errorf("Expected no output with an output step in the pipeline, got %q", output.String())
+	}
+	movements := result.Records[1:]
+	expected := []string{"01/02 Filed", "03/04 Decided", "05/06 Archived"}
+	for i, movement := range movements {
+		if movement["date"].(string)+" "+movement["text"].(string) != expected[i] {
+			t.Errorf("Expected movement %d to be %s, got %v", i, expected[i], movement)
+		}
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	p, err := Parse([]byte(`{"steps": [
+		{"action": "open", "url": "https://example.com/results?page=1"},
+		{"action": "extract", "rows": "//tr", "fields": {"date": "./td[1]"}},
+		{"action": "output", "to": "../movements.jsonl"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	_, err = (&Runner{}).Run(newSiteBrowser(), p, nil)
+	if err == nil {
+		t.Error("Expected an error for an output to a file without a directory")
+	}
+
+	dir := t.TempDir()
+	_, err = (&Runner{Dir: dir}).Run(newSiteBrowser(), p, nil)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	// the path is kept in the directory
+	data, err := os.ReadFile(filepath.Join(dir, "movements.jsonl"))
+	if err != nil {
+		t.Fatalf("Expected the output file in the directory: %v", err)
+	}
+	if string(data) != "{\"date\":\"01/02\"}\n{\"date\":\"03/04\"}\n" {
+		t.Errorf("Unexpected output %q", data)
+	}
+
+	// without output steps, the records are written to the output at the end
+	p.Steps = p.Steps[:2]
+	var output bytes.Buffer
+	_, err = (&Runner{Output: &output}).Run(newSiteBrowser(), p, nil)
+	if err != nil || output.String() != "{\"date\":\"01/02\"}\n{\"date\":\"03/04\"}\n" {
+		t.Errorf("Expected the records in the output, got %q (%v)", output.String(), err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline string
+		steps    int
+	}{
+		{"undefined variable", `{"steps": [{"action": "open", "url": "https://example.com/{{page}}"}]}`, 0},
+		{"failing step", `{"steps": [{"action": "open", "url": "https://example.com/search"}, {"action": "click", "selector": "//*[@id='missing']"}]}`, 1},
+		{"wait timeout", `{"steps": [{"action": "open", "url": "https://example.com/search"}, {"action": "wait", "selector": "//table"}]}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse([]byte(tt.pipeline))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			result, err := (&Runner{}).Run(newSiteBrowser(), p, nil)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if result.Steps != tt.steps {
+				t.Errorf("Expected %d steps before the error, got %d", tt.steps, result.Steps)
+			}
+		})
+	}
+}
+
+func TestRunLoginWithEnv(t *testing.T) {
+	t.Setenv("PIPELINE_PASSWORD", "secret")
+	p, err := Parse([]byte(`{"steps": [{"action": "login", "url": "https://example.com/search", "username": "{{user}}",
+		"password": "{{env.PIPELINE_PASSWORD}}", "username_selector": "#user", "password_selector": "#pass",
+		"submit_selector": "#login"}]}`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	nav := newSiteBrowser()
+	_, err = (&Runner{Getenv: os.Getenv}).Run(nav, p, map[string]string{"user": "daniel"})
+	if err != nil || len(nav.logins) != 1 || nav.logins[0] != "daniel:secret" {
+		t.Errorf("Expected a login with the variables, got %v (%v)", nav.logins, err)
+	}
+
+	// without Getenv, the environment can't be read
+	nav = newSiteBrowser()
+	_, err = (&Runner{}).Run(nav, p, map[string]string{"user": "daniel"})
+	if err == nil || len(nav.logins) != 0 {
+		t.Errorf("Expected an error for an environment variable without Getenv, got %v (%v)", nav.logins, err)
+	}
+}
+
+func TestCondition(t *testing.T) {
+	s := &run{runner: &Runner{}, nav: newSiteBrowser(), result: &Result{Vars: map[string]string{"status": "ok", "empty": ""}}}
+	s.nav.OpenURL("https://example.com/search")
+	tests := map[string]bool{
+		"exists //*[@id='go']":      true,
+		"exists //*[@id='captcha']": false,
+		"not exists //*[@id='go']":  false,
+		"{{status}} == ok":          true,
+		"{{status}} == 'ok'":        true,
+		"{{status}} != ok":          false,
+		"{{empty}}":                 false,
+		"not {{empty}}":             true,
+		"{{status}}":                true,
+		"false":                     false,
+		"0":                         false,
+	}
+	for condition, expected := range tests {
+		holds, err := s.condition(condition)
+		if err != nil || holds != expected {
+			t.Errorf("Expected %q to be %v, got %v (%v)", condition, expected, holds, err)
+		}
+	}
+	if _, err := s.condition("{{missing}}"); err == nil {
+		t.Error("Expected an error for an undefined variable")
+	}
+}
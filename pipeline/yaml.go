@@ -0,0 +1,348 @@
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML document without its indentation and comment.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML parses the YAML subset of pipeline files into JSON values: block mappings and sequences, flow sequences
+// and mappings on one line, quoted and plain scalars, and comments. Anchors, tags, multi-line scalars and multiple
+// documents are not supported.
+func parseYAML(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		text := strings.TrimRight(stripComment(line), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't indent YAML", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.i].number)
+	}
+	return value, nil
+}
+
+// yamlParser parses the block structure of the lines.
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// block parses the sequence or mapping starting at the current line, indented by indent.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.i].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+// sequence parses the items of a block sequence.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isSequenceItem(p.lines[p.i].text) {
+		line := p.lines[p.i]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if rest == "" {
+			p.i++
+			value, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+		if _, _, ok := splitKey(rest); ok || isSequenceItem(rest) {
+			// the item is a block starting on the line of its dash, as in "- action: open"
+			p.lines[p.i] = yamlLine{number: line.number, indent: line.indent + len(line.text) - len(rest), text: rest}
+			value, err := p.block(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+		value, err := scalar(rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		p.i++
+	}
+	return items, nil
+}
+
+// mapping parses the keys of a block mapping.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	values := map[string]interface{}{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent {
+		line := p.lines[p.i]
+		if isSequenceItem(line.text) {
+			return nil, fmt.Errorf("line %d: expected a key, found a sequence item", line.number)
+		}
+		key, rest, ok := splitKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a key", line.number)
+		}
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		p.i++
+		if rest != "" {
+			value, err := scalar(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+			continue
+		}
+		// a sequence can be indented like its key
+		if p.i < len(p.lines) && p.lines[p.i].indent == indent && isSequenceItem(p.lines[p.i].text) {
+			value, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+			continue
+		}
+		value, err := p.nested(indent)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// nested parses the block indented deeper than indent at the current line, or returns nil when there is none.
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.i >= len(p.lines) || p.lines[p.i].indent <= indent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.i].indent)
+}
+
+// isSequenceItem reports whether text is an item of a block sequence.
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits a "key: value" line. The key can be quoted.
+func splitKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 || !strings.HasPrefix(text[end+1:], ":") {
+			return "", "", false
+		}
+		key, err := scalar(text[:end+1], 0)
+		if err != nil {
+			return "", "", false
+		}
+		return key.(string), strings.TrimSpace(text[end+2:]), true
+	}
+	if text[0] == '[' || text[0] == '{' {
+		return "", "", false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// stripComment removes the comment of a line: a "#" at the start or after a space, outside quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:[{,-", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// closingQuote returns the index of the quote closing the quoted scalar at the start of text, or -1.
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// scalar parses a scalar or a flow collection.
+func scalar(text string, number int) (interface{}, error) {
+	switch text[0] {
+	case '"':
+		if closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("line %d: unterminated or trailing text after quoted value", number)
+		}
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted value: %w", number, err)
+		}
+		return value, nil
+	case '\'':
+		if closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("line %d: unterminated or trailing text after quoted value", number)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '[', '{':
+		value, rest, err := flow(text, number)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("line %d: trailing text after %s", number, text[:len(text)-len(rest)])
+		}
+		return value, nil
+	case '|', '>':
+		return nil, fmt.Errorf("line %d: multi-line values are not supported", number)
+	case '&', '*', '!':
+		return nil, fmt.Errorf("line %d: anchors, aliases and tags are not supported", number)
+	}
+
+	switch text {
+	case "null", "~":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXnN") {
+		return f, nil
+	}
+	return text, nil
+}
+
+// flow parses the flow collection at the start of text and returns the text after it.
+func flow(text string, number int) (interface{}, string, error) {
+	closing := byte(']')
+	if text[0] == '{' {
+		closing = '}'
+	}
+	var items []interface{}
+	values := map[string]interface{}{}
+	rest := strings.TrimLeft(text[1:], " ")
+	for {
+		if rest == "" {
+			return nil, "", fmt.Errorf("line %d: unterminated %c", number, text[0])
+		}
+		if rest[0] == closing {
+			break
+		}
+
+		var key string
+		if closing == '}' {
+			var ok bool
+			key, rest, ok = splitFlowKey(rest)
+			if !ok {
+				return nil, "", fmt.Errorf("line %d: expected a key in %c", number, text[0])
+			}
+		}
+		var value interface{}
+		var err error
+		if rest != "" && (rest[0] == '[' || rest[0] == '{') {
+			value, rest, err = flow(rest, number)
+		} else {
+			end := flowEnd(rest, closing)
+			if item := strings.TrimSpace(rest[:end]); item != "" {
+				value, err = scalar(item, number)
+			}
+			rest = rest[end:]
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if closing == '}' {
+			values[key] = value
+		} else {
+			items = append(items, value)
+		}
+
+		rest = strings.TrimLeft(rest, " ")
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimLeft(rest[1:], " ")
+		} else if rest == "" || rest[0] != closing {
+			return nil, "", fmt.Errorf("line %d: expected , or %c", number, closing)
+		}
+	}
+	if closing == '}' {
+		return values, rest[1:], nil
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	return items, rest[1:], nil
+}
+
+// splitFlowKey splits the "key: " at the start of the text of a flow mapping.
+func splitFlowKey(text string) (string, string, bool) {
+	end := flowEnd(text, '}')
+	key, value, ok := splitKey(text[:end])
+	if !ok {
+		return "", "", false
+	}
+	return key, value + text[end:], true
+}
+
+// flowEnd returns the index of the "," or closing character ending the flow item at the start of text.
+func flowEnd(text string, closing byte) int {
+	start := 0
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		if end := closingQuote(text); end >= 0 {
+			start = end + 1
+		}
+	}
+	for i := start; i < len(text); i++ {
+		if text[i] == ',' || text[i] == closing {
+			return i
+		}
+	}
+	return len(text)
+}
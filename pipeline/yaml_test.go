@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		yaml     string
+		expected string
+	}{
+		{"a: 1\nb: text\nc: true\nd: null\ne: 1.5", `{"a":1,"b":"text","c":true,"d":null,"e":1.5}`},
+		{"a:\n  b:\n    c: x\n  d: y", `{"a":{"b":{"c":"x"},"d":"y"}}`},
+		{"- 1\n- two\n-\n  a: b", `[1,"two",{"a":"b"}]`},
+		{"items:\n- a: 1\n  b: 2\n- c: 3", `{"items":[{"a":1,"b":2},{"c":3}]}`},
+		{"items:\n  - - 1\n    - 2\n  - [3, 4]", `{"items":[[1,2],[3,4]]}`},
+		{`a: "quoted: #not a comment"  # a comment`, `{"a":"quoted: #not a comment"}`},
+		{`a: 'it''s'`, `{"a":"it's"}`},
+		{`"quoted key": "line\nbreak"`, `{"quoted key":"line\nbreak"}`},
+		{`a: {b: 1, c: [x, "y, z"], d: {e: f}}`, `{"a":{"b":1,"c":["x","y, z"],"d":{"e":"f"}}}`},
+		{"a: []\nb: {}", `{"a":[],"b":{}}`},
+		{"url: https://example.com/a#b\nxpath: //*[@id='x']", `{"url":"https://example.com/a#b","xpath":"//*[@id='x']"}`},
+		{"---\na: 08\nb: 1.2.3\nc: 0x10", `{"a":8,"b":"1.2.3","c":"0x10"}`},
+		{"a:\nb: 1", `{"a":null,"b":1}`},
+	}
+	for _, tt := range tests {
+		value, err := parseYAML([]byte(tt.yaml))
+		if err != nil {
+			t.Errorf("parseYAML(%q) error: %v", tt.yaml, err)
+			continue
+		}
+		data, _ := json.Marshal(value)
+		if string(data) != tt.expected {
+			t.Errorf("parseYAML(%q) = %s, expected %s", tt.yaml, data, tt.expected)
+		}
+	}
+}
+
+func TestParseYAMLInvalid(t *testing.T) {
+	for _, invalid := range []string{
+		"a: 1\n  b: 2",
+		"a: 1\na: 2",
+		"a: |\n  text",
+		"a: &anchor 1",
+		"a: [1, 2",
+		"a: {b 1}",
+		`a: "unterminated`,
+		"a: 1\n- b",
+		"\ta: 1",
+		"just text",
+	} {
+		if _, err := parseYAML([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
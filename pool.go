@@ -0,0 +1,206 @@
+package goSpider
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolOptions configures a NavigatorPool.
+type PoolOptions struct {
+	Size            int               // number of browsers, and of concurrent pages; 1 if not positive
+	PagesPerTab     int               // pages opened in a tab before it is closed and replaced; never if not positive
+	PagesPerBrowser int               // pages opened in a browser before it is restarted; never if not positive
+	ProfilePath     string            // see NewNavigator
+	Headless        bool              // see NewNavigator
+	Timeout         time.Duration     // element timeout of the pooled Navigators, see SetTimeOut
+	Timeouts        Timeouts          // timeouts of the pooled Navigators, see SetTimeouts
+	Options         []NavigatorOption // see NewNavigator
+	Middleware      MiddlewareChain   // hooks of the pooled Navigators, see Navigator.Use
+	Proxies         *ProxyPool        // proxies rotated across the browsers, replacing a browser whose proxy is removed
+}
+
+// PoolMetrics counts the work of a NavigatorPool.
+type PoolMetrics struct {
+	Pages           int64
+	TabRecycles     int64
+	BrowserRecycles int64
+}
+
+// NavigatorPool keeps a fixed number of browsers for long-running workers. Each browser navigates in place in a single
+// tab, which is recycled every PagesPerTab pages, and the whole browser is restarted every PagesPerBrowser pages, so
+// memory leaked by pages doesn't grow for the life of the worker. Browsers are started on first use.
+type NavigatorPool struct {
+	options PoolOptions
+	slots   chan *poolSlot
+	metrics PoolMetrics
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// poolSlot is one browser of the pool and the tab currently used in it.
+type poolSlot struct {
+	browser      *Navigator // owns the browser process
+	tab          *Navigator
+	proxy        string // the proxy of the browser, from PoolOptions.Proxies
+	tabPages     int
+	browserPages int
+}
+
+// NewNavigatorPool creates a NavigatorPool.
+// Example:
+//
+//	pool := goSpider.NewNavigatorPool(goSpider.PoolOptions{Size: 4, Headless: true, PagesPerTab: 50, PagesPerBrowser: 500})
+//	defer pool.Close()
+//	results, err := goSpider.ParallelRequests(requests, 4, 0, pool.Fetch)
+func NewNavigatorPool(options PoolOptions) *NavigatorPool {
+	if options.Size <= 0 {
+		options.Size = 1
+	}
+	pool := &NavigatorPool{options: options, slots: make(chan *poolSlot, options.Size)}
+	for i := 0; i < options.Size; i++ {
+		pool.slots <- &poolSlot{}
+	}
+	return pool
+}
+
+// Do runs fn with a Navigator of the pool, waiting for one to be free, and counts it as one page. When fn fails with
+// ErrBrowserCrashed, the browser is restarted on the next call.
+// The Navigator must not be used after fn returns.
+// Example:
+//
+//	err := pool.Do(func(nav *goSpider.Navigator) error {
+//		return nav.OpenURL("https://www.example.com")
+//	})
+func (p *NavigatorPool) Do(fn func(nav *Navigator) error) error {
+	slot := <-p.slots
+	defer func() { p.slots <- slot }()
+
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return fmt.Errorf("error - navigator pool is closed")
+	}
+
+	err := p.prepare(slot)
+	if err != nil {
+		return err
+	}
+
+	slot.tabPages++
+	slot.browserPages++
+	atomic.AddInt64(&p.metrics.Pages, 1)
+	err = fn(slot.tab)
+	// a crashed browser is replaced on the next call
+	if errors.Is(err, ErrBrowserCrashed) || slot.tab.Ctx.Err() != nil {
+		slot.browser.Logger.Printf("Restarting crashed browser: %v\n", err)
+		slot.close()
+		atomic.AddInt64(&p.metrics.BrowserRecycles, 1)
+		return err
+	}
+	// a browser whose proxy was removed is replaced by one with the next proxy on the next call
+	if slot.proxy != "" {
+		p.options.Proxies.Record(slot.proxy, err)
+		if !p.options.Proxies.Alive(slot.proxy) {
+			slot.browser.Logger.Printf("Restarting browser without removed proxy: %v\n", err)
+			slot.close()
+			atomic.AddInt64(&p.metrics.BrowserRecycles, 1)
+		}
+	}
+	return err
+}
+
+// Fetch opens the url in a Navigator of the pool and returns the page source, so the pool can be used as a Fetcher.
+func (p *NavigatorPool) Fetch(url string) (*html.Node, error) {
+	var pageSource *html.Node
+	err := p.Do(func(nav *Navigator) error {
+		var err error
+		pageSource, err = nav.Fetch(url)
+		return err
+	})
+	return pageSource, err
+}
+
+// Metrics returns the pages opened and the tabs and browsers recycled so far.
+func (p *NavigatorPool) Metrics() PoolMetrics {
+	return PoolMetrics{
+		Pages:           atomic.LoadInt64(&p.metrics.Pages),
+		TabRecycles:     atomic.LoadInt64(&p.metrics.TabRecycles),
+		BrowserRecycles: atomic.LoadInt64(&p.metrics.BrowserRecycles),
+	}
+}
+
+// Close waits for the running calls of Do and closes every browser of the pool. Calls to Do after Close fail.
+func (p *NavigatorPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	for i := 0; i < p.options.Size; i++ {
+		slot := <-p.slots
+		slot.close()
+		defer func() { p.slots <- slot }()
+	}
+}
+
+// prepare starts the browser and tab of the slot, recycling them when they reached their page limits.
+func (p *NavigatorPool) prepare(slot *poolSlot) error {
+	if slot.browser != nil && p.options.PagesPerBrowser > 0 && slot.browserPages >= p.options.PagesPerBrowser {
+		slot.browser.Logger.Printf("Recycling browser after %d pages\n", slot.browserPages)
+		slot.close()
+		atomic.AddInt64(&p.metrics.BrowserRecycles, 1)
+	}
+	if slot.browser == nil {
+		options := p.options.Options
+		if p.options.Proxies != nil {
+			proxy, err := p.options.Proxies.Next()
+			if err != nil {
+				return err
+			}
+			options = append(append([]NavigatorOption(nil), options...), WithProxy(proxy))
+			slot.proxy = proxy
+		}
+		slot.browser = NewNavigator(p.options.ProfilePath, p.options.Headless, options...)
+		if p.options.Timeout > 0 {
+			slot.browser.SetTimeOut(p.options.Timeout)
+		}
+		slot.browser.SetTimeouts(p.options.Timeouts)
+		slot.browserPages = 0
+	}
+
+	if slot.tab != nil && p.options.PagesPerTab > 0 && slot.tabPages >= p.options.PagesPerTab {
+		slot.browser.Logger.Printf("Recycling tab after %d pages\n", slot.tabPages)
+		slot.tab.Cancel()
+		slot.tab = nil
+		atomic.AddInt64(&p.metrics.TabRecycles, 1)
+	}
+	if slot.tab == nil {
+		tab, err := slot.browser.NewTab()
+		if err != nil {
+			slot.close()
+			return err
+		}
+		tab.Middleware = p.options.Middleware
+		slot.tab = tab
+		slot.tabPages = 0
+	}
+	return nil
+}
+
+// close closes the tab and the browser of the slot.
+func (s *poolSlot) close() {
+	if s.tab != nil {
+		s.tab.Cancel()
+		s.tab = nil
+	}
+	if s.browser != nil {
+		s.browser.Close()
+		s.browser = nil
+	}
+	s.proxy = ""
+}
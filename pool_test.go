@@ -0,0 +1,37 @@
+package goSpider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNavigatorPool(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	pool := NewNavigatorPool(PoolOptions{Size: 1, Headless: true, Timeout: 600 * time.Millisecond, PagesPerTab: 2, PagesPerBrowser: 4})
+	defer pool.Close()
+
+	for i := 0; i < 5; i++ {
+		pageSource, err := pool.Fetch(server.URL + "/test.html")
+		if err != nil {
+			t.Fatalf("Fetch error: %v", err)
+		}
+		text, err := ExtractText(pageSource, "//h1", "")
+		if err != nil || text != "Main Content" {
+			t.Errorf("Expected the page content, got %q: %v", text, err)
+		}
+	}
+
+	metrics := pool.Metrics()
+	want := PoolMetrics{Pages: 5, TabRecycles: 1, BrowserRecycles: 1}
+	if metrics != want {
+		t.Errorf("Expected metrics %+v, got %+v", want, metrics)
+	}
+
+	pool.Close()
+	err := pool.Do(func(nav *Navigator) error { return nil })
+	if err == nil {
+		t.Errorf("Expected an error using a closed pool")
+	}
+}
@@ -0,0 +1,118 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestProxyPoolRotation(t *testing.T) {
+	pool := NewProxyPool("http://a:3128", "http://b:3128", "http://c:3128")
+	pool.MaxFailures = 2
+	var removed []string
+	pool.OnRemove = func(proxy string, err error) { removed = append(removed, proxy) }
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		proxy, _ := pool.Next()
+		order = append(order, proxy)
+	}
+	if !reflect.DeepEqual(order, []string{"http://a:3128", "http://b:3128", "http://c:3128", "http://a:3128"}) {
+		t.Errorf("Expected the proxies in turn, got %v", order)
+	}
+
+	failure := withKind(ErrNavigationFailed, errors.New("net::ERR_PROXY_CONNECTION_FAILED"))
+	pool.Record("http://b:3128", failure)
+	pool.Record("http://b:3128", nil)
+	pool.Record("http://b:3128", errors.New("element missing"))
+	pool.Record("http://b:3128", failure)
+	if !pool.Alive("http://b:3128") {
+		t.Error("Expected a proxy to stay after non consecutive failures")
+	}
+	pool.Record("http://b:3128", failure)
+	if pool.Alive("http://b:3128") || !reflect.DeepEqual(removed, []string{"http://b:3128"}) {
+		t.Errorf("Expected the proxy to be removed after 2 consecutive failures, got %v", removed)
+	}
+	for i := 0; i < 4; i++ {
+		if proxy, _ := pool.Next(); proxy == "http://b:3128" {
+			t.Error("Expected the removed proxy to leave the rotation")
+		}
+	}
+
+	pool.Record("http://a:3128", failure)
+	pool.Record("http://a:3128", failure)
+	pool.Record("http://c:3128", failure)
+	pool.Record("http://c:3128", failure)
+	if _, err := pool.Next(); !errors.Is(err, ErrNoProxies) {
+		t.Errorf("Expected ErrNoProxies, got %v", err)
+	}
+	alive, dead := pool.Proxies()
+	if len(alive) != 0 || len(dead) != 3 {
+		t.Errorf("Expected every proxy removed, got %v and %v", alive, dead)
+	}
+}
+
+func TestProxyPoolWrap(t *testing.T) {
+	pool := NewProxyPool("http://a:3128", "http://b:3128")
+	pool.MaxFailures = 1
+	crawler := pool.Wrap(func(search, proxy string) (*html.Node, error) {
+		if proxy == "http://b:3128" {
+			return nil, withKind(ErrTimeout, errors.New("timeout"))
+		}
+		return &html.Node{Data: proxy + " " + search}, nil
+	})
+
+	page, err := crawler("1017927")
+	if err != nil || page.Data != "http://a:3128 1017927" {
+		t.Errorf("Expected the first proxy, got %v: %v", page, err)
+	}
+	if _, err = crawler("1017928"); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected the error of the crawler, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		page, err = crawler("1017929")
+		if err != nil || !strings.HasPrefix(page.Data, "http://a:3128") {
+			t.Errorf("Expected the failing proxy to be removed, got %v: %v", page, err)
+		}
+	}
+}
+
+func TestProxyPoolCheck(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "check.example.test" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	pool := NewProxyPool(proxy.URL, closed.URL)
+	pool.CheckURL = "http://check.example.test/generate_204"
+	var removed []string
+	pool.OnRemove = func(proxy string, err error) { removed = append(removed, proxy) }
+
+	if alive := pool.Check(context.Background()); alive != 1 {
+		t.Errorf("Expected 1 live proxy, got %d", alive)
+	}
+	if !pool.Alive(proxy.URL) || pool.Alive(closed.URL) || !reflect.DeepEqual(removed, []string{closed.URL}) {
+		t.Errorf("Expected the unreachable proxy to be removed, got %v", removed)
+	}
+
+	pool.MaxFailures = 1
+	pool.Record(proxy.URL, withKind(ErrNavigationFailed, errors.New("net::ERR_TUNNEL_CONNECTION_FAILED")))
+	if pool.Alive(proxy.URL) {
+		t.Fatal("Expected the failing proxy to be removed")
+	}
+	if alive := pool.Check(context.Background()); alive != 1 || !pool.Alive(proxy.URL) {
+		t.Errorf("Expected the proxy to return after a passing check, got %d live", alive)
+	}
+}
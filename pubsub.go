@@ -0,0 +1,140 @@
+package goSpider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PubSubScope is the OAuth scope of the Google Cloud Pub/Sub API.
+const PubSubScope = "https://www.googleapis.com/auth/pubsub"
+
+// DefaultPubSubBatchSize is the number of messages published per request when PubSubSink.BatchSize is not set.
+const DefaultPubSubBatchSize = 100
+
+// pubSubMaxBatchSize and pubSubMaxBatchBytes keep publish requests under the limits of the API: 1000 messages and
+// 10MB per request.
+const (
+	pubSubMaxBatchSize  = 1000
+	pubSubMaxBatchBytes = 9 << 20
+)
+
+// PubSubSink is a Sink that publishes each record as a JSON message to a Google Cloud Pub/Sub topic. Records are
+// batched into publish requests of up to BatchSize messages. Messages carry the domain of the OrderingField URL as
+// ordering key, so subscriptions with message ordering enabled receive the records of each site in order; the topic
+// must then be published through a regional endpoint, e.g. "https://us-east1-pubsub.googleapis.com/v1".
+type PubSubSink struct {
+	Account       *ServiceAccount
+	Project       string
+	Topic         string
+	OrderingField string            // the record field holding the URL whose domain is the ordering key, none if empty
+	Attributes    map[string]string // attributes added to every message
+	BatchSize     int               // messages per request, DefaultPubSubBatchSize if not positive, at most 1000
+	APIURL        string            // the Pub/Sub API URL, "https://pubsub.googleapis.com/v1" by default
+	Client        *http.Client
+}
+
+// pubSubMessage is a message of a publish request.
+type pubSubMessage struct {
+	Data        string            `json:"data"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// NewPubSubSink creates a PubSubSink for the topic of the project, authenticated with the service account key file,
+// using the domain of the "url" field as ordering key. The service account needs the Pub/Sub Publisher role.
+// Example:
+//
+//	sink, err := goSpider.NewPubSubSink("service-account.json", "my-project", "lawsuits")
+//	record, err := goSpider.RecordOf(lawsuit.Cover)
+//	err = sink.Write([]goSpider.Record{record})
+func NewPubSubSink(credentialsFile, project, topic string) (*PubSubSink, error) {
+	account, err := LoadServiceAccount(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	return &PubSubSink{
+		Account:       account,
+		Project:       project,
+		Topic:         topic,
+		OrderingField: "url",
+		APIURL:        "https://pubsub.googleapis.com/v1",
+		Client:        account.Client,
+	}, nil
+}
+
+// Write publishes the records in order, in batches of BatchSize messages. It stops at the first failed batch, so
+// later records of an ordering key are never published before earlier ones.
+func (s *PubSubSink) Write(records []Record) error {
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultPubSubBatchSize
+	}
+	if batchSize > pubSubMaxBatchSize {
+		batchSize = pubSubMaxBatchSize
+	}
+
+	var batch []pubSubMessage
+	size := 0
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("error - failed to encode record: %w", err)
+		}
+		message := pubSubMessage{
+			Data:        base64.StdEncoding.EncodeToString(data),
+			Attributes:  s.Attributes,
+			OrderingKey: s.orderingKey(record),
+		}
+		if len(batch) > 0 && (len(batch) == batchSize || size+len(message.Data) > pubSubMaxBatchBytes) {
+			err = s.publish(batch)
+			if err != nil {
+				return err
+			}
+			batch, size = nil, 0
+		}
+		batch = append(batch, message)
+		size += len(message.Data)
+	}
+	if len(batch) > 0 {
+		return s.publish(batch)
+	}
+	return nil
+}
+
+// orderingKey returns the domain of the record URL, or "" when records are not ordered.
+func (s *PubSubSink) orderingKey(record Record) string {
+	if s.OrderingField == "" {
+		return ""
+	}
+	value, ok := record[s.OrderingField].(string)
+	if !ok || value == "" {
+		return ""
+	}
+	return hostOf(value)
+}
+
+// publish sends a publish request with the messages.
+func (s *PubSubSink) publish(messages []pubSubMessage) error {
+	apiURL := s.APIURL
+	if apiURL == "" {
+		apiURL = "https://pubsub.googleapis.com/v1"
+	}
+	endpoint := strings.TrimRight(apiURL, "/") + "/projects/" + url.PathEscape(s.Project) + "/topics/" +
+		url.PathEscape(s.Topic) + ":publish"
+	var response struct {
+		MessageIDs []string `json:"messageIds"`
+	}
+	err := s.Account.do(s.Client, PubSubScope, http.MethodPost, endpoint,
+		map[string]interface{}{"messages": messages}, &response)
+	if err != nil {
+		return fmt.Errorf("error - failed to publish to %s: %w", s.Topic, err)
+	}
+	if len(response.MessageIDs) != len(messages) {
+		return fmt.Errorf("error - published %d of %d messages to %s", len(response.MessageIDs), len(messages), s.Topic)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package goSpider
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPubSubSink(t *testing.T) {
+	var key *rsa.PrivateKey
+	tokens := 0
+	var batches [][]pubSubMessage
+	var paths []string
+	mux := http.NewServeMux()
+	mux.Handle("/token", tokenHandler(&key, &tokens))
+	mux.HandleFunc("/projects/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		var body struct {
+			Messages []pubSubMessage `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		batches = append(batches, body.Messages)
+		ids := []string{}
+		for i := range body.Messages {
+			ids = append(ids, fmt.Sprint(i))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"messageIds": ids})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	path, generated := newTestServiceAccount(t, server.URL+"/token")
+	key = generated
+	sink, err := NewPubSubSink(path, "my-project", "lawsuits")
+	if err != nil {
+		t.Fatalf("NewPubSubSink error: %v", err)
+	}
+	sink.APIURL = server.URL
+	sink.BatchSize = 2
+	sink.Attributes = map[string]string{"source": "goSpider"}
+
+	err = sink.Write([]Record{
+		{"url": "https://esaj.tjsp.jus.br/cpopg/show.do?id=1", "number": "1"},
+		{"url": "https://pje.trf3.jus.br/consulta?id=2", "number": "2"},
+		{"number": "3"},
+	})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("Expected batches of 2 and 1 messages, got %v", batches)
+	}
+	if paths[0] != "/projects/my-project/topics/lawsuits:publish" {
+		t.Errorf("Expected the publish endpoint of the topic, got %s", paths[0])
+	}
+	var keys []string
+	for _, batch := range batches {
+		for _, message := range batch {
+			keys = append(keys, message.OrderingKey)
+		}
+	}
+	if !reflect.DeepEqual(keys, []string{"esaj.tjsp.jus.br", "pje.trf3.jus.br", ""}) {
+		t.Errorf("Expected the domains as ordering keys, got %q", keys)
+	}
+	data, _ := base64.StdEncoding.DecodeString(batches[0][0].Data)
+	var record Record
+	json.Unmarshal(data, &record)
+	if record["number"] != "1" || batches[0][0].Attributes["source"] != "goSpider" {
+		t.Errorf("Expected the record as data with the attributes, got %s %v", data, batches[0][0].Attributes)
+	}
+	if tokens != 1 {
+		t.Errorf("Expected the token to be cached, got %d token requests", tokens)
+	}
+}
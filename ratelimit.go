@@ -0,0 +1,282 @@
+package goSpider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"golang.org/x/net/html"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces the requests made to a key, such as a domain.
+type RateLimiter interface {
+	// Wait blocks until a request to key may be made, or ctx is done.
+	Wait(ctx context.Context, key string) error
+}
+
+// RateLimited returns a crawler that waits for limiter, keyed by the domain of each search string, see requestDomain,
+// before calling crawler.
+// Example:
+//
+//	limiter := goSpider.NewRedisRateLimiter("redis://redis.local:6379/0", 2)
+//	defer limiter.Close()
+//	results, err := goSpider.ParallelRequests(requests, 10, 0, goSpider.RateLimited(limiter, fetcher.Fetch))
+func RateLimited(limiter RateLimiter, crawler CrawlerFunc) CrawlerFunc {
+	return func(search string) (*html.Node, error) {
+		err := limiter.Wait(context.Background(), requestDomain(search))
+		if err != nil {
+			return nil, err
+		}
+		return crawler(search)
+	}
+}
+
+// redisRateScript reserves the next request of a key with the generic cell rate algorithm: the key holds the
+// theoretical arrival time, in microseconds of the Redis clock, of the next request, and the script returns how many
+// microseconds the caller must wait for its reservation. ARGV are the interval between requests and the burst, in
+// microseconds and requests.
+const redisRateScript = `if redis.replicate_commands then redis.replicate_commands() end
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000000 + tonumber(clock[2])
+local interval = tonumber(ARGV[1])
+local tat = math.max(tonumber(redis.call('GET', KEYS[1]) or 0), now) + interval
+redis.call('SET', KEYS[1], string.format('%d', tat), 'PX', math.ceil((tat - now) / 1000) + 1000)
+return math.max(tat - now - interval * tonumber(ARGV[2]), 0)`
+
+// RedisRateLimiter is a RateLimiter backed by Redis, so crawler processes on several machines targeting the same
+// domain share one budget of requests per second. Each Wait reserves the next request of the key in Redis, using its
+// clock, and sleeps until the reservation. It is safe for concurrent use.
+type RedisRateLimiter struct {
+	Addr      string        // "redis://[:password@]host:6379[/db]", or "rediss://" for TLS
+	Rate      float64       // requests per second of each key
+	Burst     int           // requests of a key allowed at once after an idle period; 1 if not positive
+	Prefix    string        // prefix of the Redis keys; "gospider:ratelimit:" if empty
+	Timeout   time.Duration // timeout of connecting and of each command
+	TLSConfig *tls.Config
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisRateLimiter creates a RedisRateLimiter allowing rate requests per second to each key.
+// Example:
+//
+//	limiter := goSpider.NewRedisRateLimiter("redis://:secret@redis.local:6379/0", 2)
+//	defer limiter.Close()
+//	err := limiter.Wait(ctx, "esaj.tjsp.jus.br")
+func NewRedisRateLimiter(addr string, rate float64) *RedisRateLimiter {
+	return &RedisRateLimiter{Addr: addr, Rate: rate, Timeout: 10 * time.Second}
+}
+
+// Wait reserves the next request to key and waits for it, or until ctx is done.
+func (l *RedisRateLimiter) Wait(ctx context.Context, key string) error {
+	if l.Rate <= 0 {
+		return fmt.Errorf("error - invalid rate: %v", l.Rate)
+	}
+	burst := l.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	prefix := l.Prefix
+	if prefix == "" {
+		prefix = "gospider:ratelimit:"
+	}
+	interval := int64(float64(time.Second/time.Microsecond) / l.Rate)
+	if interval < 1 {
+		interval = 1
+	}
+
+	reply, err := l.do("EVAL", redisRateScript, "1", prefix+key, strconv.FormatInt(interval, 10), strconv.Itoa(burst))
+	if err != nil {
+		return fmt.Errorf("error - failed to reserve request: %w", err)
+	}
+	wait, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("error - unexpected Redis reply: %v", reply)
+	}
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(wait) * time.Microsecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the connection to Redis.
+func (l *RedisRateLimiter) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn, l.reader = nil, nil
+	return err
+}
+
+// do runs a command, reconnecting once if the connection was closed.
+func (l *RedisRateLimiter) do(args ...string) (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	reply, err := l.command(args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		// Redis may have closed an idle connection
+		l.closeConn()
+		reply, err = l.command(args...)
+	}
+	if _, ok := err.(redisError); err != nil && !ok {
+		l.closeConn()
+	}
+	return reply, err
+}
+
+// command sends a command and reads its reply.
+func (l *RedisRateLimiter) command(args ...string) (interface{}, error) {
+	if l.conn == nil {
+		err := l.connect()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if l.Timeout > 0 {
+		l.conn.SetDeadline(time.Now().Add(l.Timeout))
+	}
+	_, err := l.conn.Write(redisCommand(args...))
+	if err != nil {
+		return nil, err
+	}
+	return readRedisReply(l.reader)
+}
+
+// connect opens the connection, authenticates and selects the database of Addr.
+func (l *RedisRateLimiter) connect() error {
+	addr, err := url.Parse(l.Addr)
+	if err != nil {
+		return fmt.Errorf("error - invalid Redis address: %w", err)
+	}
+	timeout := l.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	switch addr.Scheme {
+	case "redis":
+		conn, err = dialer.Dial("tcp", hostWithPort(addr, "6379"))
+	case "rediss":
+		config := l.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: addr.Hostname()}
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", hostWithPort(addr, "6379"), config)
+	default:
+		return fmt.Errorf("error - unsupported Redis address scheme: %s", addr.Scheme)
+	}
+	if err != nil {
+		return fmt.Errorf("error - failed to connect to Redis: %w", err)
+	}
+	l.conn = conn
+	l.reader = bufio.NewReader(conn)
+
+	if password, ok := addr.User.Password(); ok {
+		args := []string{"AUTH", password}
+		if username := addr.User.Username(); username != "" {
+			args = []string{"AUTH", username, password}
+		}
+		_, err = l.command(args...)
+		if err != nil {
+			l.closeConn()
+			return fmt.Errorf("error - failed to authenticate to Redis: %w", err)
+		}
+	}
+	if db := strings.Trim(addr.Path, "/"); db != "" && db != "0" {
+		_, err = l.command("SELECT", db)
+		if err != nil {
+			l.closeConn()
+			return fmt.Errorf("error - failed to select Redis database %s: %w", db, err)
+		}
+	}
+	return nil
+}
+
+// closeConn drops the connection.
+func (l *RedisRateLimiter) closeConn() {
+	if l.conn != nil {
+		l.conn.Close()
+	}
+	l.conn, l.reader = nil, nil
+}
+
+// redisError is an error reply of Redis.
+type redisError string
+
+func (e redisError) Error() string {
+	return "error - Redis: " + string(e)
+}
+
+// redisCommand encodes a command as a RESP array of bulk strings.
+func redisCommand(args ...string) []byte {
+	b := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b = append(b, "$"+strconv.Itoa(len(arg))+"\r\n"+arg+"\r\n"...)
+	}
+	return b
+}
+
+// readRedisReply reads a RESP reply: a string, an int64, nil, a []interface{} or a redisError.
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("error - invalid Redis reply: %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, redisError(value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i], err = readRedisReply(r)
+			if _, ok := err.(redisError); err != nil && !ok {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("error - invalid Redis reply: %q", line)
+}
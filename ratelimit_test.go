@@ -0,0 +1,199 @@
+package goSpider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// startTestRedis accepts Redis connections, running the rate script of RedisRateLimiter in Go, and sends the commands
+// other than EVAL to commands. Connections are closed after closeAfter commands, unless zero.
+func startTestRedis(t *testing.T, commands chan<- string, closeAfter int) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	var mu sync.Mutex
+	arrivals := map[string]int64{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for n := 1; ; n++ {
+					reply, err := readRedisReply(reader)
+					if err != nil {
+						return
+					}
+					var args []string
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, arg.(string))
+					}
+					switch args[0] {
+					case "EVAL":
+						interval, _ := strconv.ParseInt(args[4], 10, 64)
+						burst, _ := strconv.ParseInt(args[5], 10, 64)
+						now := time.Now().UnixMicro()
+						mu.Lock()
+						tat := arrivals[args[3]]
+						if tat < now {
+							tat = now
+						}
+						tat += interval
+						arrivals[args[3]] = tat
+						mu.Unlock()
+						wait := tat - now - interval*burst
+						if wait < 0 {
+							wait = 0
+						}
+						fmt.Fprintf(conn, ":%d\r\n", wait)
+					case "AUTH":
+						commands <- strings.Join(args, " ")
+						if args[len(args)-1] != "secret" {
+							conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+							continue
+						}
+						conn.Write([]byte("+OK\r\n"))
+					default:
+						commands <- strings.Join(args, " ")
+						conn.Write([]byte("+OK\r\n"))
+					}
+					if n == closeAfter {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	commands := make(chan string, 10)
+	addr := startTestRedis(t, commands, 0)
+
+	// two processes sharing a budget of 20 requests per second
+	first := NewRedisRateLimiter("redis://:secret@"+addr+"/2", 20)
+	second := NewRedisRateLimiter("redis://:secret@"+addr+"/2", 20)
+	defer first.Close()
+	defer second.Close()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		limiter := first
+		if i%2 == 1 {
+			limiter = second
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- limiter.Wait(context.Background(), "esaj.tjsp.jus.br")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Wait error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 240*time.Millisecond {
+		t.Errorf("Expected 6 requests at 20 per second to take 250ms, but took %v", elapsed)
+	}
+
+	// another key has its own budget
+	start = time.Now()
+	err := first.Wait(context.Background(), "www3.tjrj.jus.br")
+	if err != nil || time.Since(start) > 40*time.Millisecond {
+		t.Errorf("Expected no wait for another key, but waited %v: %v", time.Since(start), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 2; i++ {
+		err = first.Wait(ctx, "www3.tjrj.jus.br")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the wait to be canceled, but got %v", err)
+	}
+
+	close(commands)
+	var setup []string
+	for command := range commands {
+		setup = append(setup, command)
+	}
+	sort.Strings(setup)
+	if strings.Join(setup, ", ") != "AUTH secret, AUTH secret, SELECT 2, SELECT 2" {
+		t.Errorf("Unexpected setup commands: %v", setup)
+	}
+}
+
+func TestRedisRateLimiterErrors(t *testing.T) {
+	commands := make(chan string, 10)
+	addr := startTestRedis(t, commands, 1)
+
+	limiter := NewRedisRateLimiter("redis://:wrong@"+addr, 10)
+	err := limiter.Wait(context.Background(), "example.com")
+	if err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Expected an authentication error, but got %v", err)
+	}
+
+	// the server closes the connection after each command, so each wait reconnects
+	limiter = NewRedisRateLimiter("redis://"+addr, 1000)
+	defer limiter.Close()
+	for i := 0; i < 3; i++ {
+		err = limiter.Wait(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Wait error: %v", err)
+		}
+	}
+
+	limiter.Rate = 0
+	if limiter.Wait(context.Background(), "example.com") == nil {
+		t.Error("Expected an error for a rate of zero")
+	}
+}
+
+// keyRecorder is a RateLimiter recording the keys it waits for.
+type keyRecorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (r *keyRecorder) Wait(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func TestRateLimited(t *testing.T) {
+	limiter := &keyRecorder{}
+	crawler := RateLimited(limiter, func(search string) (*html.Node, error) {
+		return &html.Node{Type: html.TextNode, Data: search}, nil
+	})
+	page, err := crawler("https://esaj.tjsp.jus.br/cpopg/show.do")
+	if err != nil || page.Data != "https://esaj.tjsp.jus.br/cpopg/show.do" {
+		t.Errorf("Unexpected page %v: %v", page, err)
+	}
+	if len(limiter.keys) != 1 || limiter.keys[0] != "esaj.tjsp.jus.br" {
+		t.Errorf("Expected to wait for the domain, but got %v", limiter.keys)
+	}
+}
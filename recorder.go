@@ -0,0 +1,288 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Actions of a RecordedAction.
+const (
+	RecordedOpen   = "open"
+	RecordedClick  = "click"
+	RecordedFill   = "fill"
+	RecordedSelect = "select"
+)
+
+// RecordedAction is an action of a user recorded by RecordSession.
+type RecordedAction struct {
+	Action   string // RecordedOpen, RecordedClick, RecordedFill or RecordedSelect
+	URL      string // the page opened, or the page of the element
+	Selector string // css selector or xpath of the element
+	Value    string // the text filled or the option selected
+	Secret   bool   // a password field, whose value is not recorded
+}
+
+// SessionRecorder records the actions of a user in a Navigator, see RecordSession.
+type SessionRecorder struct {
+	nav        *Navigator
+	stop       context.CancelFunc
+	identifier page.ScriptIdentifier
+
+	mu        sync.Mutex
+	actions   []RecordedAction
+	requested map[cdp.FrameID]bool // frames whose next navigation was started by the page, e.g. with a link
+}
+
+// recorderBinding is the function the recorder script calls with each action.
+const recorderBinding = "__goSpiderRecord"
+
+// recorderScript reports the clicks and the changes of the fields of the page to recorderBinding. Elements are
+// identified by a unique id, a unique name or an xpath from the closest element with an id.
+const recorderScript = `(function() {
+	if (window.__goSpiderRecording) return;
+	window.__goSpiderRecording = true;
+	var fields = /^(submit|button|checkbox|radio|image|reset|file)$/;
+	function unique(selector) {
+		try { return document.querySelectorAll(selector).length === 1; } catch (e) { return false; }
+	}
+	function selector(el) {
+		if (el.id && /^[\w-]+$/.test(el.id) && unique('#' + el.id)) return '#' + el.id;
+		var name = el.getAttribute('name');
+		if (name && /^[\w-]+$/.test(name)) {
+			var byName = el.tagName.toLowerCase() + '[name="' + name + '"]';
+			if (unique(byName)) return byName;
+		}
+		var path = '';
+		for (; el && el.nodeType === 1; el = el.parentNode) {
+			if (el.id && /^[\w-]+$/.test(el.id) && unique('#' + el.id)) return "//*[@id='" + el.id + "']" + path;
+			var i = 1;
+			for (var s = el.previousElementSibling; s; s = s.previousElementSibling) if (s.tagName === el.tagName) i++;
+			path = '/' + el.tagName.toLowerCase() + '[' + i + ']' + path;
+		}
+		return path;
+	}
+	function send(action, el, value) {
+		if (!window.` + recorderBinding + `) return;
+		window.` + recorderBinding + `(JSON.stringify({
+			action: action, selector: selector(el), value: value || '', secret: el.type === 'password', url: location.href
+		}));
+	}
+	document.addEventListener('click', function(e) {
+		var el = e.target.closest('a, button, input, select, textarea, label, [onclick], [role=button]') || e.target;
+		if (el.tagName === 'SELECT' || el.tagName === 'TEXTAREA' || el.tagName === 'INPUT' && !fields.test(el.type)) return;
+		send('click', el);
+	}, true);
+	document.addEventListener('change', function(e) {
+		var el = e.target;
+		if (el.tagName === 'SELECT') send('select', el, el.value);
+		else if (el.tagName === 'TEXTAREA' || el.tagName === 'INPUT' && !fields.test(el.type)) send('fill', el, el.value);
+	}, true);
+})();`
+
+// RecordSession starts recording the actions of a user in the browser of nav, usually not headless: the pages opened
+// from the address bar or with OpenURL, the clicks and the fields filled and selected, which GenerateGoCode and
+// GeneratePipeline turn into the code of a scraper. Navigations started by the page itself, such as by a link or a
+// form, follow from the recorded clicks and are not recorded. The values of password fields are not recorded.
+// Example:
+//
+//	nav := goSpider.NewNavigator("", false)
+//	recorder, err := nav.RecordSession()
+//	err = nav.OpenURL("https://esaj.tjsp.jus.br/cpopg/open.do")
+//	// ... the user searches a lawsuit in the browser window
+//	actions := recorder.Stop()
+//	fmt.Print(goSpider.GenerateGoCode(actions))
+func (nav *Navigator) RecordSession() (*SessionRecorder, error) {
+	ctx, cancel := context.WithCancel(nav.Ctx)
+	r := &SessionRecorder{nav: nav, stop: cancel, requested: map[cdp.FrameID]bool{}}
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *runtime.EventBindingCalled:
+			if ev.Name == recorderBinding {
+				r.record(ev.Payload)
+			}
+		case *page.EventFrameRequestedNavigation:
+			r.requestNavigation(ev.FrameID)
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				r.navigated(ev.Frame.ID, ev.Frame.URL)
+			}
+		}
+	})
+
+	err := chromedp.Run(nav.Ctx,
+		runtime.AddBinding(recorderBinding),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			r.identifier, err = page.AddScriptToEvaluateOnNewDocument(recorderScript).Do(ctx)
+			return err
+		}),
+		chromedp.Evaluate(recorderScript, nil),
+	)
+	if err != nil {
+		cancel()
+		nav.Logger.Printf("Error - Failed to record session: %v\n", err)
+		return nil, fmt.Errorf("error - failed to record session: %w", classifyError(err))
+	}
+	nav.Logger.Println("Recording session")
+	return r, nil
+}
+
+// record adds the action reported by the recorder script. Consecutive changes of a field are kept as one.
+func (r *SessionRecorder) record(payload string) {
+	var action struct {
+		Action   string `json:"action"`
+		Selector string `json:"selector"`
+		Value    string `json:"value"`
+		Secret   bool   `json:"secret"`
+		URL      string `json:"url"`
+	}
+	err := json.Unmarshal([]byte(payload), &action)
+	if err != nil || action.Selector == "" {
+		return
+	}
+	switch action.Action {
+	case RecordedClick, RecordedFill, RecordedSelect:
+	default:
+		return
+	}
+	recorded := RecordedAction{Action: action.Action, URL: action.URL, Selector: action.Selector, Value: action.Value,
+		Secret: action.Secret}
+	if recorded.Secret {
+		recorded.Value = ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := len(r.actions); n > 0 && recorded.Action != RecordedClick {
+		last := r.actions[n-1]
+		if last.Action == recorded.Action && last.Selector == recorded.Selector {
+			r.actions[n-1] = recorded
+			return
+		}
+	}
+	r.actions = append(r.actions, recorded)
+}
+
+// requestNavigation notes that the page started the next navigation of the frame.
+func (r *SessionRecorder) requestNavigation(frame cdp.FrameID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requested[frame] = true
+}
+
+// navigated records the navigation of the main frame to url as an open action, unless the page started it or it
+// reloads the page just opened.
+func (r *SessionRecorder) navigated(frame cdp.FrameID, url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.requested[frame] {
+		delete(r.requested, frame)
+		return
+	}
+	if url == "" || url == "about:blank" {
+		return
+	}
+	if n := len(r.actions); n > 0 && r.actions[n-1].Action == RecordedOpen && r.actions[n-1].URL == url {
+		return
+	}
+	r.actions = append(r.actions, RecordedAction{Action: RecordedOpen, URL: url})
+}
+
+// Actions returns the actions recorded so far.
+func (r *SessionRecorder) Actions() []RecordedAction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedAction(nil), r.actions...)
+}
+
+// Stop stops recording and returns the actions recorded.
+func (r *SessionRecorder) Stop() []RecordedAction {
+	r.stop()
+	err := chromedp.Run(r.nav.Ctx,
+		runtime.RemoveBinding(recorderBinding),
+		page.RemoveScriptToEvaluateOnNewDocument(r.identifier),
+	)
+	if err != nil {
+		r.nav.Logger.Printf("Error - Failed to stop recording session: %v\n", err)
+	}
+	r.nav.Logger.Println("Stopped recording session")
+	return r.Actions()
+}
+
+// GenerateGoCode returns a Go program repeating the actions with a Navigator. Passwords are read from the PASSWORD
+// environment variable.
+// Example:
+//
+//	err := os.WriteFile("main.go", []byte(goSpider.GenerateGoCode(recorder.Stop())), 0644)
+func GenerateGoCode(actions []RecordedAction) string {
+	var body strings.Builder
+	secret := false
+	assign := ":="
+	for _, action := range actions {
+		value := strconv.Quote(action.Value)
+		if action.Secret {
+			value = `os.Getenv("PASSWORD")`
+			secret = true
+		}
+		switch action.Action {
+		case RecordedOpen:
+			fmt.Fprintf(&body, "\terr %s nav.OpenURL(%s)\n", assign, strconv.Quote(action.URL))
+		case RecordedClick:
+			fmt.Fprintf(&body, "\terr %s nav.ClickButton(%s)\n", assign, strconv.Quote(action.Selector))
+		case RecordedFill:
+			fmt.Fprintf(&body, "\terr %s nav.FillField(%s, %s)\n", assign, strconv.Quote(action.Selector), value)
+		case RecordedSelect:
+			fmt.Fprintf(&body, "\terr %s nav.SelectDropdown(%s, %s)\n", assign, strconv.Quote(action.Selector), value)
+		default:
+			continue
+		}
+		body.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+		assign = "="
+	}
+
+	var code strings.Builder
+	code.WriteString("package main\n\nimport (\n\t\"github.com/DanielFillol/goSpider\"\n\t\"log\"\n")
+	if secret {
+		code.WriteString("\t\"os\"\n")
+	}
+	code.WriteString(")\n\nfunc main() {\n\tnav := goSpider.NewNavigator(\"\", true)\n\tdefer nav.Close()\n\n")
+	code.WriteString(body.String())
+	code.WriteString("}\n")
+	return code.String()
+}
+
+// GeneratePipeline returns a pipeline file, in YAML, repeating the actions, see package pipeline. Passwords are read
+// from the PASSWORD environment variable; pipelines have no action selecting an option, so selections are left as
+// comments.
+// Example:
+//
+//	err := os.WriteFile("tjsp.yaml", []byte(goSpider.GeneratePipeline("tjsp", recorder.Stop())), 0644)
+func GeneratePipeline(name string, actions []RecordedAction) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "name: %s\nsteps:\n", strconv.Quote(name))
+	for _, action := range actions {
+		value := strconv.Quote(action.Value)
+		if action.Secret {
+			value = `"{{env.PASSWORD}}"`
+		}
+		switch action.Action {
+		case RecordedOpen:
+			fmt.Fprintf(&sb, "  - action: open\n    url: %s\n", strconv.Quote(action.URL))
+		case RecordedClick:
+			fmt.Fprintf(&sb, "  - action: click\n    selector: %s\n", strconv.Quote(action.Selector))
+		case RecordedFill:
+			fmt.Fprintf(&sb, "  - action: fill\n    selector: %s\n    value: %s\n", strconv.Quote(action.Selector), value)
+		case RecordedSelect:
+			fmt.Fprintf(&sb, "  # select %s in %s\n", value, strconv.Quote(action.Selector))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package goSpider
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+)
+
+func TestSessionRecorder(t *testing.T) {
+	r := &SessionRecorder{requested: map[cdp.FrameID]bool{}}
+	r.navigated("main", "about:blank")
+	r.navigated("main", "https://example.com/login")
+	r.navigated("main", "https://example.com/login")
+	r.record(`{"action": "fill", "selector": "#user", "value": "a", "url": "https://example.com/login"}`)
+	r.record(`{"action": "fill", "selector": "#user", "value": "alice", "url": "https://example.com/login"}`)
+	r.record(`{"action": "fill", "selector": "input[name=\"pass\"]", "value": "hunter2", "secret": true}`)
+	r.record(`{"action": "click", "selector": "//*[@id='form']/button[1]"}`)
+	r.record(`{"action": "scroll", "selector": "#user"}`)
+	r.record(`not json`)
+	r.requestNavigation("main")
+	r.navigated("main", "https://example.com/home")
+	r.record(`{"action": "select", "selector": "#court", "value": "TJSP"}`)
+	r.navigated("main", "https://example.com/search?q=1")
+
+	actions := r.Actions()
+	var summary []string
+	for _, action := range actions {
+		summary = append(summary, action.Action+" "+action.URL+action.Selector+" "+action.Value)
+	}
+	expected := []string{
+		"open https://example.com/login ",
+		"fill https://example.com/login#user alice",
+		`fill input[name="pass"] `,
+		"click //*[@id='form']/button[1] ",
+		"select #court TJSP",
+		"open https://example.com/search?q=1 ",
+	}
+	if strings.Join(summary, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected actions:\n%s", strings.Join(summary, "\n"))
+	}
+	if !actions[2].Secret {
+		t.Error("Expected the password to be secret")
+	}
+}
+
+func TestGenerateGoCode(t *testing.T) {
+	actions := []RecordedAction{
+		{Action: RecordedOpen, URL: "https://example.com/login"},
+		{Action: RecordedFill, Selector: "#user", Value: `al"ice`},
+		{Action: RecordedFill, Selector: "#pass", Secret: true},
+		{Action: RecordedSelect, Selector: "#court", Value: "TJSP"},
+		{Action: RecordedClick, Selector: "//button[1]"},
+	}
+	code := GenerateGoCode(actions)
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("Invalid Go code: %v\n%s", err, code)
+	}
+	if string(formatted) != code {
+		t.Errorf("Expected formatted code, but got:\n%s", code)
+	}
+	for _, line := range []string{
+		`err := nav.OpenURL("https://example.com/login")`,
+		`err = nav.FillField("#user", "al\"ice")`,
+		`err = nav.FillField("#pass", os.Getenv("PASSWORD"))`,
+		`err = nav.SelectDropdown("#court", "TJSP")`,
+		`err = nav.ClickButton("//button[1]")`,
+	} {
+		if !strings.Contains(code, line) {
+			t.Errorf("Expected the code to contain %s, but got:\n%s", line, code)
+		}
+	}
+	if strings.Contains(GenerateGoCode(actions[:2]), `"os"`) {
+		t.Error("Expected no os import without passwords")
+	}
+
+	pipeline := GeneratePipeline("login", actions)
+	expected := `name: "login"
+steps:
+  - action: open
+    url: "https://example.com/login"
+  - action: fill
+    selector: "#user"
+    value: "al\"ice"
+  - action: fill
+    selector: "#pass"
+    value: "{{env.PASSWORD}}"
+  # select "TJSP" in "#court"
+  - action: click
+    selector: "//button[1]"
+`
+	if pipeline != expected {
+		t.Errorf("Unexpected pipeline:\n%s", pipeline)
+	}
+}
@@ -0,0 +1,249 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunReport is the summary of a crawl run, see RunRecorder. Durations are encoded in JSON as nanoseconds.
+type RunReport struct {
+	Name     string         `json:"name,omitempty"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Pages    int            `json:"pages"`    // distinct search strings crawled
+	OK       int            `json:"ok"`       // pages whose last attempt succeeded
+	Failed   int            `json:"failed"`   // pages whose last attempt failed
+	Attempts int            `json:"attempts"` // calls of the crawler
+	Retries  int            `json:"retries"`  // attempts after the first of each page
+	Errors   map[string]int `json:"errors,omitempty"`
+	Domains  []DomainStats  `json:"domains"`
+	Slowest  []PageTiming   `json:"slowest"`
+}
+
+// DomainStats are the statistics of the attempts of a domain in a RunReport.
+type DomainStats struct {
+	Domain   string        `json:"domain"`
+	Attempts int           `json:"attempts"`
+	Failed   int           `json:"failed"`
+	P50      time.Duration `json:"p50"`
+	P90      time.Duration `json:"p90"`
+	P99      time.Duration `json:"p99"`
+	Max      time.Duration `json:"max"`
+}
+
+// PageTiming is an attempt of a RunReport.
+type PageTiming struct {
+	URL     string        `json:"url"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// RunRecorder records the attempts of a crawl run, to summarize them in a RunReport. It is safe for concurrent use.
+type RunRecorder struct {
+	name  string
+	start time.Time
+
+	mu       sync.Mutex
+	attempts []runAttempt
+}
+
+// runAttempt is a call of the crawler recorded by a RunRecorder.
+type runAttempt struct {
+	search  string
+	latency time.Duration
+	err     error
+}
+
+// slowestPages is the number of pages listed in RunReport.Slowest.
+const slowestPages = 10
+
+// NewRunRecorder starts recording a crawl run with the given name.
+// Example:
+//
+//	recorder := goSpider.NewRunRecorder("tjsp")
+//	results, err := goSpider.ParallelRequests(requests, 10, 0, recorder.Track(fetcher.Fetch))
+//	report := recorder.Report()
+//	fmt.Print(report)
+//	err = notifier.Notify(report.Notification())
+func NewRunRecorder(name string) *RunRecorder {
+	return &RunRecorder{name: name, start: time.Now()}
+}
+
+// Track returns a crawler recording the latency and the outcome of each call of crawler.
+func (r *RunRecorder) Track(crawler CrawlerFunc) CrawlerFunc {
+	return func(search string) (*html.Node, error) {
+		start := time.Now()
+		page, err := crawler(search)
+		r.Record(search, time.Since(start), err)
+		return page, err
+	}
+}
+
+// Record records an attempt to crawl search, for crawlers not wrapped by Track.
+func (r *RunRecorder) Record(search string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, runAttempt{search: search, latency: latency, err: err})
+}
+
+// Report summarizes the attempts recorded so far.
+func (r *RunRecorder) Report() *RunReport {
+	r.mu.Lock()
+	attempts := append([]runAttempt(nil), r.attempts...)
+	r.mu.Unlock()
+
+	report := &RunReport{Name: r.name, Start: r.start, End: time.Now(), Attempts: len(attempts)}
+	last := map[string]error{}
+	latencies := map[string][]time.Duration{}
+	failures := map[string]int{}
+	var timings []PageTiming
+	for _, attempt := range attempts {
+		if _, ok := last[attempt.search]; ok {
+			report.Retries++
+		}
+		last[attempt.search] = attempt.err
+		domain := requestDomain(attempt.search)
+		latencies[domain] = append(latencies[domain], attempt.latency)
+		timing := PageTiming{URL: attempt.search, Latency: attempt.latency}
+		if attempt.err != nil {
+			failures[domain]++
+			timing.Error = attempt.err.Error()
+		}
+		timings = append(timings, timing)
+	}
+
+	report.Pages = len(last)
+	for _, err := range last {
+		if err == nil {
+			report.OK++
+			continue
+		}
+		report.Failed++
+		if report.Errors == nil {
+			report.Errors = map[string]int{}
+		}
+		report.Errors[errorKind(err)]++
+	}
+
+	for domain, durations := range latencies {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		report.Domains = append(report.Domains, DomainStats{
+			Domain:   domain,
+			Attempts: len(durations),
+			Failed:   failures[domain],
+			P50:      percentile(durations, 50),
+			P90:      percentile(durations, 90),
+			P99:      percentile(durations, 99),
+			Max:      durations[len(durations)-1],
+		})
+	}
+	sort.Slice(report.Domains, func(i, j int) bool { return report.Domains[i].Domain < report.Domains[j].Domain })
+
+	sort.SliceStable(timings, func(i, j int) bool { return timings[i].Latency > timings[j].Latency })
+	if len(timings) > slowestPages {
+		timings = timings[:slowestPages]
+	}
+	report.Slowest = timings
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// errorKind names the kind of a failure for the breakdown of a RunReport: a sentinel error, an HTTP status or "other".
+func errorKind(err error) string {
+	kinds := append([]error{ErrRetriesExhausted, ErrCircuitOpen}, errorKinds...)
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind.Error()
+		}
+	}
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		return fmt.Sprintf("status %d", statusError.StatusCode)
+	}
+	return "other"
+}
+
+// JSON returns the report as indented JSON, e.g. to save it with the results of a job.
+func (report *RunReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// String returns a human-readable summary of the report.
+func (report *RunReport) String() string {
+	var sb strings.Builder
+	name := report.Name
+	if name == "" {
+		name = "Crawl"
+	}
+	fmt.Fprintf(&sb, "%s: %d pages, %d ok, %d failed, %d retries in %v\n", name, report.Pages, report.OK, report.Failed,
+		report.Retries, report.End.Sub(report.Start).Round(time.Millisecond))
+
+	if len(report.Errors) > 0 {
+		kinds := make([]string, 0, len(report.Errors))
+		for kind := range report.Errors {
+			kinds = append(kinds, kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			if report.Errors[kinds[i]] != report.Errors[kinds[j]] {
+				return report.Errors[kinds[i]] > report.Errors[kinds[j]]
+			}
+			return kinds[i] < kinds[j]
+		})
+		sb.WriteString("Errors:\n")
+		for _, kind := range kinds {
+			fmt.Fprintf(&sb, "  %s: %d\n", kind, report.Errors[kind])
+		}
+	}
+
+	if len(report.Domains) > 0 {
+		sb.WriteString("Domains:\n")
+		for _, domain := range report.Domains {
+			name := domain.Domain
+			if name == "" {
+				name = "(no domain)"
+			}
+			fmt.Fprintf(&sb, "  %s: %d attempts, %d failed, p50 %v, p90 %v, p99 %v, max %v\n", name, domain.Attempts,
+				domain.Failed, domain.P50.Round(time.Millisecond), domain.P90.Round(time.Millisecond),
+				domain.P99.Round(time.Millisecond), domain.Max.Round(time.Millisecond))
+		}
+	}
+
+	if len(report.Slowest) > 0 {
+		sb.WriteString("Slowest pages:\n")
+		for _, timing := range report.Slowest {
+			fmt.Fprintf(&sb, "  %v %s", timing.Latency.Round(time.Millisecond), timing.URL)
+			if timing.Error != "" {
+				fmt.Fprintf(&sb, " (%s)", timing.Error)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// Notification returns the summary of the report as a notification, see Notifier.
+func (report *RunReport) Notification() Notification {
+	name := report.Name
+	if name == "" {
+		name = "Crawl"
+	}
+	title := fmt.Sprintf("%s finished: %d of %d pages ok", name, report.OK, report.Pages)
+	if report.Failed > 0 {
+		title = fmt.Sprintf("%s finished with %d failed pages of %d", name, report.Failed, report.Pages)
+	}
+	return Notification{Title: title, Message: report.String()}
+}
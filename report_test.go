@@ -0,0 +1,78 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestRunRecorder(t *testing.T) {
+	recorder := NewRunRecorder("tjsp")
+	for i := 1; i <= 10; i++ {
+		recorder.Record(fmt.Sprintf("https://esaj.tjsp.jus.br/%d", i), time.Duration(i)*100*time.Millisecond, nil)
+	}
+	recorder.Record("https://www3.tjrj.jus.br/1", time.Second, &StatusError{StatusCode: 429})
+	recorder.Record("https://www3.tjrj.jus.br/1", 2*time.Second, nil)
+	recorder.Record("https://www3.tjrj.jus.br/2", 3*time.Second, withKind(ErrTimeout, errors.New("slow")))
+	recorder.Record("https://www3.tjrj.jus.br/3", 50*time.Millisecond, &StatusError{StatusCode: 429})
+	crawler := recorder.Track(func(search string) (*html.Node, error) {
+		return nil, errors.New("broken")
+	})
+	crawler("1017927-35.2023.8.26.0008")
+
+	report := recorder.Report()
+	if report.Pages != 14 || report.OK != 11 || report.Failed != 3 || report.Attempts != 15 || report.Retries != 1 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+	expected := map[string]int{"timeout": 1, "status 429": 1, "other": 1}
+	if fmt.Sprint(report.Errors) != fmt.Sprint(expected) {
+		t.Errorf("Expected errors %v, but got %v", expected, report.Errors)
+	}
+	if len(report.Domains) != 3 || report.Domains[1].Domain != "esaj.tjsp.jus.br" {
+		t.Fatalf("Unexpected domains: %+v", report.Domains)
+	}
+	tjsp := report.Domains[1]
+	if tjsp.P50 != 500*time.Millisecond || tjsp.P90 != 900*time.Millisecond || tjsp.P99 != time.Second ||
+		tjsp.Max != time.Second {
+		t.Errorf("Unexpected percentiles: %+v", tjsp)
+	}
+	if tjrj := report.Domains[2]; tjrj.Attempts != 4 || tjrj.Failed != 3 {
+		t.Errorf("Unexpected stats: %+v", tjrj)
+	}
+	if len(report.Slowest) != slowestPages || report.Slowest[0].URL != "https://www3.tjrj.jus.br/2" ||
+		report.Slowest[0].Error == "" {
+		t.Errorf("Unexpected slowest pages: %+v", report.Slowest)
+	}
+
+	summary := report.String()
+	for _, line := range []string{
+		"tjsp: 14 pages, 11 ok, 3 failed, 1 retries",
+		"  other: 1\n  status 429: 1\n  timeout: 1\n",
+		"  esaj.tjsp.jus.br: 10 attempts, 0 failed, p50 500ms, p90 900ms, p99 1s, max 1s",
+		"  (no domain): 1 attempts, 1 failed",
+		"  3s https://www3.tjrj.jus.br/2 (slow)",
+	} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Expected the summary to contain %q, but got:\n%s", line, summary)
+		}
+	}
+
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON error: %v", err)
+	}
+	var decoded RunReport
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Failed != 3 || decoded.Domains[1].P90 != tjsp.P90 {
+		t.Errorf("Unexpected JSON %s: %v", data, err)
+	}
+
+	notification := report.Notification()
+	if notification.Title != "tjsp finished with 3 failed pages of 14" || notification.Message != summary {
+		t.Errorf("Unexpected notification: %+v", notification)
+	}
+}
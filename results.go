@@ -0,0 +1,192 @@
+package goSpider
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultResultBatchSize is the number of records written per batch when ResultPipeline.BatchSize is not set.
+const DefaultResultBatchSize = 100
+
+// ResultPipeline buffers the records of a crawl and writes them to its sinks in batches, when BatchSize records are
+// buffered or FlushInterval has passed since the last write. Add blocks while BufferSize records wait to be written,
+// so slow sinks slow the crawl down instead of piling up results in memory.
+type ResultPipeline struct {
+	Sinks         []Sink
+	BatchSize     int           // records per write, DefaultResultBatchSize if not positive
+	FlushInterval time.Duration // writes a partial batch after this, never if not positive
+	BufferSize    int           // records waiting to be written before Add blocks, BatchSize if not positive
+	// Convert returns the records of a result of the Middleware. The default converts the Data of the result with
+	// RecordOf and skips the results without Data.
+	Convert func(result *PageSource) ([]Record, error)
+	Logger  *log.Logger
+
+	once    sync.Once
+	records chan Record
+	done    chan struct{}
+	mu      sync.RWMutex // guards closed against Add
+	closed  bool
+	errMu   sync.Mutex
+	err     error
+}
+
+// NewResultPipeline creates a ResultPipeline writing to sinks in batches of DefaultResultBatchSize records, at least
+// every 5 seconds.
+// Example:
+//
+//	results := goSpider.NewResultPipeline(sink)
+//	results.Convert = func(result *goSpider.PageSource) ([]goSpider.Record, error) {
+//		record, err := goSpider.RecordOf(parseLawsuit(result.Page))
+//		return []goSpider.Record{record}, err
+//	}
+//	chain := goSpider.MiddlewareChain{results.Middleware()}
+//	_, err := goSpider.ParallelRequestsWithMiddleware(requests, 10, 0, pool.Fetch, chain)
+//	err = results.Close()
+func NewResultPipeline(sinks ...Sink) *ResultPipeline {
+	return &ResultPipeline{
+		Sinks:         sinks,
+		BatchSize:     DefaultResultBatchSize,
+		FlushInterval: 5 * time.Second,
+		Logger:        log.New(os.Stdout, "goSpider: ", log.LstdFlags),
+	}
+}
+
+// Add buffers records to be written, blocking while the buffer is full. It fails once the pipeline is closed.
+func (p *ResultPipeline) Add(records ...Record) error {
+	p.start()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return errors.New("error - failed to add records: result pipeline closed")
+	}
+	for _, record := range records {
+		p.records <- record
+	}
+	return nil
+}
+
+// Middleware returns a Middleware adding the records of each successful result to the pipeline from the workers of
+// ParallelRequestsWithMiddleware, which wait while the buffer is full. The Page of the results is dropped once added,
+// so the results returned by the crawl hold no pages. It should be the last middleware of the chain, as results
+// crawled again by another AfterRequest hook are added on each attempt.
+func (p *ResultPipeline) Middleware() Middleware {
+	return Middleware{
+		AfterRequest: func(req Request, result *PageSource, attempt int) bool {
+			if result.Error != nil {
+				return false
+			}
+			records, err := p.convert(result)
+			if err == nil {
+				err = p.Add(records...)
+			}
+			if err != nil {
+				result.Error = err
+				return false
+			}
+			result.Page = nil
+			return false
+		},
+	}
+}
+
+// Close writes the buffered records and returns the first error of the sinks.
+func (p *ResultPipeline) Close() error {
+	p.start()
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.records)
+	}
+	p.mu.Unlock()
+	<-p.done
+
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	return p.err
+}
+
+// start starts writing the records on the first use of the pipeline.
+func (p *ResultPipeline) start() {
+	p.once.Do(func() {
+		batchSize := p.BatchSize
+		if batchSize <= 0 {
+			batchSize = DefaultResultBatchSize
+		}
+		bufferSize := p.BufferSize
+		if bufferSize <= 0 {
+			bufferSize = batchSize
+		}
+		p.records = make(chan Record, bufferSize)
+		p.done = make(chan struct{})
+		go p.run(batchSize)
+	})
+}
+
+// run batches the records until the pipeline is closed.
+func (p *ResultPipeline) run(batchSize int) {
+	defer close(p.done)
+	var tick <-chan time.Time
+	if p.FlushInterval > 0 {
+		ticker := time.NewTicker(p.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var batch []Record
+	for {
+		select {
+		case record, ok := <-p.records:
+			if !ok {
+				p.write(batch)
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= batchSize {
+				p.write(batch)
+				batch = nil
+			}
+		case <-tick:
+			p.write(batch)
+			batch = nil
+		}
+	}
+}
+
+// write writes a batch to every sink, keeping the first error.
+func (p *ResultPipeline) write(batch []Record) {
+	if len(batch) == 0 {
+		return
+	}
+	for _, sink := range p.Sinks {
+		err := sink.Write(batch)
+		if err == nil {
+			continue
+		}
+		if p.Logger != nil {
+			p.Logger.Printf("Error - Failed to write %d records: %v\n", len(batch), err)
+		}
+		p.errMu.Lock()
+		if p.err == nil {
+			p.err = err
+		}
+		p.errMu.Unlock()
+	}
+}
+
+// convert returns the records of a result with Convert, or the Data of the result.
+func (p *ResultPipeline) convert(result *PageSource) ([]Record, error) {
+	if p.Convert != nil {
+		return p.Convert(result)
+	}
+	if result.Data == nil {
+		return nil, nil
+	}
+	record, err := RecordOf(result.Data)
+	if err != nil {
+		return nil, err
+	}
+	return []Record{record}, nil
+}
@@ -0,0 +1,121 @@
+package goSpider
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestResultPipelineBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches []int
+	sink := SinkFunc(func(records []Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, len(records))
+		return nil
+	})
+	errFull := errors.New("full")
+	failing := SinkFunc(func(records []Record) error { return errFull })
+
+	p := NewResultPipeline(sink, failing)
+	p.BatchSize = 2
+	p.FlushInterval = 50 * time.Millisecond
+	p.Logger = nil
+	err := p.Add(Record{"n": 1.0}, Record{"n": 2.0}, Record{"n": 3.0})
+	if err != nil {
+		t.Fatalf("Expected the records to be added, got %v", err)
+	}
+	// the partial batch is written after the flush interval
+	time.Sleep(200 * time.Millisecond)
+	mu.Lock()
+	got := len(batches)
+	mu.Unlock()
+	if got != 2 {
+		t.Errorf("Expected the full and the partial batch, got %v", batches)
+	}
+
+	p.Add(Record{"n": 4.0})
+	err = p.Close()
+	if !errors.Is(err, errFull) {
+		t.Errorf("Expected the error of the sink, got %v", err)
+	}
+	if len(batches) != 3 || batches[0] != 2 || batches[1] != 1 || batches[2] != 1 {
+		t.Errorf("Expected the last record to be written on close, got %v", batches)
+	}
+	if err := p.Add(Record{"n": 5.0}); err == nil {
+		t.Errorf("Expected adding to a closed pipeline to fail")
+	}
+}
+
+func TestResultPipelineBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	var written int
+	sink := SinkFunc(func(records []Record) error {
+		<-release
+		written += len(records)
+		return nil
+	})
+	p := NewResultPipeline(sink)
+	p.BatchSize = 1
+	p.BufferSize = 1
+
+	// one record is being written and one waits in the buffer, so the third blocks
+	added := make(chan struct{})
+	go func() {
+		p.Add(Record{"n": 1.0}, Record{"n": 2.0}, Record{"n": 3.0})
+		close(added)
+	}()
+	select {
+	case <-added:
+		t.Fatalf("Expected Add to block while the sink is slow")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	<-added
+	p.Close()
+	if written != 3 {
+		t.Errorf("Expected 3 records written, got %d", written)
+	}
+}
+
+func TestResultPipelineMiddleware(t *testing.T) {
+	var records []Record
+	p := NewResultPipeline(SinkFunc(func(batch []Record) error {
+		records = append(records, batch...)
+		return nil
+	}))
+	crawl := func(req Request) (*html.Node, error) {
+		if req.ID == "missing" {
+			return nil, errors.New("not found")
+		}
+		return html.Parse(strings.NewReader("<h1>" + req.ID + "</h1>"))
+	}
+	chain := MiddlewareChain{
+		{
+			AfterRequest: func(req Request, result *PageSource, attempt int) bool {
+				result.Data = map[string]string{"id": req.ID}
+				return false
+			},
+		},
+		p.Middleware(),
+	}
+	requests := []Request{{ID: "a"}, {ID: "missing"}, {ID: "b"}}
+
+	results, _ := parallelRequests(requests, 2, 0, crawl, chain)
+	if err := p.Close(); err != nil {
+		t.Fatalf("Expected the records to be written, got %v", err)
+	}
+	if len(results) != 3 || len(records) != 2 {
+		t.Fatalf("Expected 3 results and 2 records, got %v and %v", results, records)
+	}
+	for _, result := range results {
+		if result.Page != nil {
+			t.Errorf("Expected the pages to be dropped, got %+v", result)
+		}
+	}
+}
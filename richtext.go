@@ -0,0 +1,119 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"regexp"
+)
+
+// richTextMarkup matches the tags that make the content of FillRichText HTML rather than plain text.
+var richTextMarkup = regexp.MustCompile(`<[a-zA-Z!/]`)
+
+// jsFillRichText is a JavaScript function setting the content of an element through the API of the editor it belongs
+// to, or else as a user typing in a contenteditable region, and returning the kind of editor filled.
+const jsFillRichText = `function(el, content, isHTML) {
+	if (!el) throw new Error("element not found");
+	var html = content;
+	if (!isHTML) {
+		var div = document.createElement("div");
+		div.textContent = content;
+		html = div.innerHTML;
+	}
+
+	// CKEditor 5, on its editable element, and CKEditor 4, on its replaced textarea or editable element
+	if (el.ckeditorInstance) {
+		el.ckeditorInstance.setData(html);
+		return "CKEditor";
+	}
+	if (window.CKEDITOR && CKEDITOR.instances) {
+		for (var name in CKEDITOR.instances) {
+			var ck = CKEDITOR.instances[name];
+			var editable = ck.editable && ck.editable();
+			if ((ck.element && ck.element.$ === el) || (editable && editable.$ === el)) {
+				ck.setData(html);
+				ck.updateElement();
+				return "CKEditor";
+			}
+		}
+	}
+
+	// TinyMCE, on its replaced textarea or its container
+	if (window.tinymce && tinymce.get) {
+		var editors = tinymce.get() || [];
+		for (var i = 0; i < editors.length; i++) {
+			var ed = editors[i];
+			var container = ed.getContainer && ed.getContainer();
+			if (ed.getElement() === el || (container && container.contains(el))) {
+				ed.setContent(html);
+				ed.fire("change");
+				ed.save();
+				return "TinyMCE";
+			}
+		}
+	}
+
+	// Quill, on its container or editor
+	var quillContainer = el.closest(".ql-container");
+	var quill = quillContainer && (quillContainer.__quill || (window.Quill && Quill.find && Quill.find(quillContainer)));
+	if (quill) {
+		if (isHTML) {
+			quill.clipboard.dangerouslyPasteHTML(content);
+		} else {
+			quill.setText(content);
+		}
+		return "Quill";
+	}
+
+	if (el.tagName === "TEXTAREA" || el.tagName === "INPUT") {
+		el.value = content;
+		el.dispatchEvent(new Event("input", {bubbles: true}));
+		el.dispatchEvent(new Event("change", {bubbles: true}));
+		return "field";
+	}
+
+	// a contenteditable region: replace the selected content, firing the beforeinput and input events of typing
+	var region = el.isContentEditable ? el : el.querySelector("[contenteditable]:not([contenteditable=false])");
+	if (!region) throw new Error("not an editor nor a contenteditable element");
+	region.focus();
+	var range = document.createRange();
+	range.selectNodeContents(region);
+	var selection = window.getSelection();
+	selection.removeAllRanges();
+	selection.addRange(range);
+	if (!document.execCommand(isHTML ? "insertHTML" : "insertText", false, content)) {
+		region.innerHTML = html;
+		region.dispatchEvent(new InputEvent("input", {bubbles: true, inputType: "insertReplacementText"}));
+	}
+	region.dispatchEvent(new Event("change", {bubbles: true}));
+	return "contenteditable";
+}`
+
+// FillRichText sets the content of a rich-text editor, where FillField and typed keys don't reliably work: through the
+// API of CKEditor, TinyMCE or Quill when the selector matches one of their elements, such as the textarea they replace,
+// or else by replacing the content of a contenteditable region as a user would, firing the input events frameworks
+// listen to. Content with tags is set as HTML, any other as plain text.
+// Example:
+//
+//	err := nav.FillRichText("#peticao", "<p>Excelentíssimo Senhor Doutor Juiz</p><p>...</p>")
+func (nav *Navigator) FillRichText(selector, content string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
+	nav.Logger.Printf("Filling rich text with selector: %s\n", selector)
+
+	err := nav.WaitForElement(selector, nav.Timeout)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
+	}
+
+	var editor string
+	err = chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(%s)(%s, %s, %t)`, jsFillRichText, jsElement(selector), jsString(content),
+			richTextMarkup.MatchString(content)), &editor),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to fill rich text: %v\n", err)
+		return fmt.Errorf("error - failed to fill rich text: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Rich text filled successfully in %s\n", editor)
+	return nil
+}
@@ -0,0 +1,61 @@
+package goSpider
+
+import (
+	"testing"
+)
+
+func TestRichTextMarkup(t *testing.T) {
+	for content, expected := range map[string]bool{
+		"<p>Excelentíssimo</p>": true,
+		"a<br>b":                true,
+		"<!-- note -->":         true,
+		"valor < 10 & > 5":      false,
+		"Excelentíssimo":        false,
+	} {
+		if richTextMarkup.MatchString(content) != expected {
+			t.Errorf("Expected %q to be HTML %v", content, expected)
+		}
+	}
+}
+
+func TestFillRichText(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/richtext.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	err = nav.FillRichText("#editor", "<p>Excelentíssimo <b>Senhor</b></p>")
+	if err != nil {
+		t.Fatalf("FillRichText error: %v", err)
+	}
+	html, err := nav.EvaluateScript(`document.getElementById("editor").innerHTML`)
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	if html != "<p>Excelentíssimo <b>Senhor</b></p>" {
+		t.Errorf("Expected the HTML to replace the draft, but got %v", html)
+	}
+	inputs, err := nav.GetElement("#inputs")
+	if err != nil {
+		t.Fatalf("GetElement error: %v", err)
+	}
+	if inputs == "0" {
+		t.Errorf("Expected input events to be fired")
+	}
+
+	err = nav.FillRichText("#tiny", "a < b")
+	if err != nil {
+		t.Fatalf("FillRichText error: %v", err)
+	}
+	value, err := nav.EvaluateScript(`document.getElementById("tiny").value`)
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	if value != "a &lt; b" {
+		t.Errorf("Expected the escaped text set through the editor, but got %v", value)
+	}
+}
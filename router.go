@@ -0,0 +1,77 @@
+package goSpider
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// CrawlerFunc crawls the page of a search string and returns its parsed HTML, as the crawlerFunc of ParallelRequests.
+type CrawlerFunc func(string) (*html.Node, error)
+
+// Router picks the crawler of each request of ParallelRequestsWithRouter, e.g. by court or by flow. A nil crawler
+// fails the request.
+type Router func(Request) CrawlerFunc
+
+// ParallelRequestsWithRouter is ParallelRequests for heterogeneous batches: the crawler of each request is picked by
+// router, so requests of different sites or flows share one pool of workers and one delay.
+// Example:
+//
+//	router := goSpider.RouteByDomain(map[string]goSpider.CrawlerFunc{
+//		"esaj.tjsp.jus.br":   tjspCrawler,
+//		"www3.tjrj.jus.br": tjrjCrawler,
+//	}, nil)
+//	results, err := goSpider.ParallelRequestsWithRouter(requests, 10, time.Second, router)
+func ParallelRequestsWithRouter(requests []Request, numberOfWorkers int, delay time.Duration, router Router) ([]PageSource, error) {
+	return parallelRequests(requests, numberOfWorkers, delay, router.crawl, nil)
+}
+
+// crawl crawls the request with the crawler picked by the router.
+func (r Router) crawl(req Request) (*html.Node, error) {
+	crawler := r(req)
+	if crawler == nil {
+		return nil, fmt.Errorf("error - no crawler for request: %s", req.SearchString)
+	}
+	return crawler(req.SearchString)
+}
+
+// RouteByDomain returns a Router picking the crawler of the domain of each search string, see requestDomain, or of a
+// parent domain, falling back to fallback, which may be nil, for the other requests.
+// Example:
+//
+//	router := goSpider.RouteByDomain(map[string]goSpider.CrawlerFunc{"tjsp.jus.br": tjspCrawler}, fetcher.Fetch)
+func RouteByDomain(crawlers map[string]CrawlerFunc, fallback CrawlerFunc) Router {
+	return func(req Request) CrawlerFunc {
+		for domain := requestDomain(req.SearchString); domain != ""; {
+			if crawler, ok := crawlers[domain]; ok {
+				return crawler
+			}
+			_, parent, found := strings.Cut(domain, ".")
+			if !found {
+				break
+			}
+			domain = parent
+		}
+		return fallback
+	}
+}
+
+// RouteByPayload returns a Router picking the crawler of the Payload of each request, for requests that are not
+// URLs, e.g. with the name of a court as payload, falling back to fallback, which may be nil, for the other requests.
+// Example:
+//
+//	requests := []goSpider.Request{{SearchString: "1017927-35.2023.8.26.0008", Payload: "TJSP"}}
+//	router := goSpider.RouteByPayload(map[interface{}]goSpider.CrawlerFunc{"TJSP": tjspCrawler, "TJRJ": tjrjCrawler}, nil)
+func RouteByPayload(crawlers map[interface{}]CrawlerFunc, fallback CrawlerFunc) Router {
+	return func(req Request) CrawlerFunc {
+		if req.Payload == nil || !reflect.TypeOf(req.Payload).Comparable() {
+			return fallback
+		}
+		if crawler, ok := crawlers[req.Payload]; ok {
+			return crawler
+		}
+		return fallback
+	}
+}
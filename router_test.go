@@ -0,0 +1,55 @@
+package goSpider
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func namedCrawler(name string) CrawlerFunc {
+	return func(search string) (*html.Node, error) {
+		return &html.Node{Type: html.TextNode, Data: name}, nil
+	}
+}
+
+func TestParallelRequestsWithRouter(t *testing.T) {
+	router := RouteByDomain(map[string]CrawlerFunc{
+		"tjsp.jus.br":      namedCrawler("tjsp"),
+		"www3.tjrj.jus.br": namedCrawler("tjrj"),
+	}, nil)
+	requests := []Request{
+		{SearchString: "https://esaj.tjsp.jus.br/cpopg/show.do", ID: "tjsp"},
+		{SearchString: "https://www3.tjrj.jus.br/consultaprocessual", ID: "tjrj"},
+		{SearchString: "https://www.example.com", ID: "none"},
+	}
+	results, err := ParallelRequestsWithRouter(requests, 2, 0, router)
+	if err == nil {
+		t.Error("Expected an error for the request without crawler")
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, but got %d", len(results))
+	}
+	for _, result := range results {
+		if result.ID == "none" {
+			if result.Error == nil {
+				t.Error("Expected the request without crawler to fail")
+			}
+		} else if result.Page == nil || result.Page.Data != result.ID {
+			t.Errorf("Expected %s to be crawled by its crawler, but got %+v", result.ID, result.Page)
+		}
+	}
+}
+
+func TestRouteByPayload(t *testing.T) {
+	router := RouteByPayload(map[interface{}]CrawlerFunc{"TJSP": namedCrawler("tjsp")}, namedCrawler("fallback"))
+	for payload, expected := range map[interface{}]string{"TJSP": "tjsp", "TJRJ": "fallback", nil: "fallback"} {
+		page, _ := router(Request{Payload: payload})("")
+		if page.Data != expected {
+			t.Errorf("Expected payload %v to be routed to %s, but got %s", payload, expected, page.Data)
+		}
+	}
+	page, _ := router(Request{Payload: []string{"TJSP"}})("")
+	if page.Data != "fallback" {
+		t.Errorf("Expected an uncomparable payload to fall back, but got %s", page.Data)
+	}
+}
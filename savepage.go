@@ -0,0 +1,312 @@
+package goSpider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// cssURLPattern matches the url() references and the @import rules of a stylesheet.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)|@import\s+(?:"([^"]*)"|'([^']*)')`)
+
+// pageSaver downloads the assets of a page into a directory, each URL once.
+type pageSaver struct {
+	nav   *Navigator
+	dir   string
+	saved map[string]string // file name by URL, empty for failed downloads
+}
+
+// SavePageComplete stores the current page in dir as a browsable offline copy: the rendered HTML is written to
+// index.html and the stylesheets, scripts, images, media and icons it references, including the url() references of
+// the stylesheets, are saved under dir/assets with the links rewritten to the local files. Assets are downloaded from
+// inside the page, reusing its cookies and session. Assets that fail to download are linked by their absolute URL.
+// Example:
+//
+//	err := nav.OpenURL("https://www.example.com")
+//	err = nav.SavePageComplete("archive/example")
+func (nav *Navigator) SavePageComplete(dir string) error {
+	var pageURL string
+	err := chromedp.Run(nav.Ctx, chromedp.Location(&pageURL))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get page URL: %v\n", err)
+		return fmt.Errorf("error - failed to get page URL: %w", classifyError(err))
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return fmt.Errorf("error - failed to parse URL: %w", classifyError(err))
+	}
+
+	pageSource, err := nav.GetPageSource()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Join(dir, "assets"), os.ModePerm)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to create directory: %v\n", err)
+		return fmt.Errorf("error - failed to create directory: %w", classifyError(err))
+	}
+
+	saver := &pageSaver{nav: nav, dir: dir, saved: map[string]string{}}
+	saver.rewriteNode(base, pageSource)
+
+	var buffer bytes.Buffer
+	err = html.Render(&buffer, pageSource)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to render page HTML: %v\n", err)
+		return fmt.Errorf("error - failed to render page HTML: %w", classifyError(err))
+	}
+	err = os.WriteFile(filepath.Join(dir, "index.html"), buffer.Bytes(), 0644)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to write page: %v\n", err)
+		return fmt.Errorf("error - failed to write page: %w", classifyError(err))
+	}
+
+	assets := 0
+	for _, name := range saver.saved {
+		if name != "" {
+			assets++
+		}
+	}
+	nav.Logger.Printf("Page saved to %s with %d assets\n", dir, assets)
+	return nil
+}
+
+// SaveMHTML stores the current page as an MHTML file at path: a single, self-contained archive of the rendered page
+// with its stylesheets, images and frames, which Chrome opens offline. Parent directories are created as needed.
+// Example:
+//
+//	err := nav.OpenURL("https://www.example.com")
+//	err = nav.SaveMHTML("archive/1017927-35.2023.8.26.0008.mhtml")
+func (nav *Navigator) SaveMHTML(path string) error {
+	var snapshot string
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		snapshot, err = page.CaptureSnapshot().WithFormat(page.CaptureSnapshotFormatMhtml).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to capture snapshot: %v\n", err)
+		return fmt.Errorf("error - failed to capture snapshot: %w", classifyError(err))
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to create directory: %v\n", err)
+		return fmt.Errorf("error - failed to create directory: %w", classifyError(err))
+	}
+	err = os.WriteFile(path, []byte(snapshot), 0644)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to write snapshot: %v\n", err)
+		return fmt.Errorf("error - failed to write snapshot: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Page snapshot saved to %s\n", path)
+	return nil
+}
+
+// rewriteNode saves the assets referenced by the document and points its links to the local files. The base element
+// is used to resolve the links and then removed, so the copy resolves them against its own directory.
+func (s *pageSaver) rewriteNode(base *url.URL, n *html.Node) {
+	var baseElements []*html.Node
+	var elements []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if n.Data == "base" {
+				baseElements = append(baseElements, n)
+			} else {
+				elements = append(elements, n)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	for _, element := range baseElements {
+		if href := nodeAttribute(element, "href"); href != "" {
+			if u, err := base.Parse(href); err == nil {
+				base = u
+			}
+		}
+		element.Parent.RemoveChild(element)
+	}
+
+	for _, element := range elements {
+		rewritten := false
+		for i, attribute := range element.Attr {
+			value := attribute.Val
+			switch {
+			case attribute.Key == "style":
+				value = s.rewriteCSS(base, value, "assets/")
+			case attribute.Key == "srcset" && (element.Data == "img" || element.Data == "source"):
+				value = s.rewriteSrcset(base, value)
+			case isAssetAttribute(element, attribute.Key):
+				value = s.link(base, value, "assets/")
+			}
+			if value != attribute.Val {
+				element.Attr[i].Val = value
+				rewritten = true
+			}
+		}
+		if element.Data == "style" && element.FirstChild != nil && element.FirstChild.Type == html.TextNode {
+			element.FirstChild.Data = s.rewriteCSS(base, element.FirstChild.Data, "assets/")
+		}
+		if rewritten {
+			// the local copies can't be checked against the original integrity hashes and CORS settings
+			removeAttributes(element, "integrity", "crossorigin")
+		}
+	}
+}
+
+// isAssetAttribute reports whether the attribute of the element references an asset of the page.
+func isAssetAttribute(element *html.Node, key string) bool {
+	switch element.Data {
+	case "link":
+		if key != "href" {
+			return false
+		}
+		for _, rel := range strings.Fields(strings.ToLower(nodeAttribute(element, "rel"))) {
+			if rel == "stylesheet" || rel == "icon" || rel == "apple-touch-icon" {
+				return true
+			}
+		}
+		return false
+	case "script", "img", "source", "audio", "track", "embed":
+		return key == "src"
+	case "video":
+		return key == "src" || key == "poster"
+	case "input":
+		return key == "src" && strings.EqualFold(nodeAttribute(element, "type"), "image")
+	}
+	return false
+}
+
+// removeAttributes removes the attributes with the given keys from the element.
+func removeAttributes(element *html.Node, keys ...string) {
+	attributes := element.Attr[:0]
+	for _, attribute := range element.Attr {
+		keep := true
+		for _, key := range keys {
+			if attribute.Key == key {
+				keep = false
+			}
+		}
+		if keep {
+			attributes = append(attributes, attribute)
+		}
+	}
+	element.Attr = attributes
+}
+
+// rewriteSrcset saves the candidates of a srcset attribute and points them to the local files.
+func (s *pageSaver) rewriteSrcset(base *url.URL, srcset string) string {
+	candidates := strings.Split(srcset, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = s.link(base, fields[0], "assets/")
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// rewriteCSS saves the assets referenced by the stylesheet and points them to the local files, prefixed with prefix.
+func (s *pageSaver) rewriteCSS(base *url.URL, css, prefix string) string {
+	return rewriteCSSURLs(css, func(raw string) string {
+		return s.link(base, raw, prefix)
+	})
+}
+
+// link saves the asset at raw and returns its local file prefixed with prefix. When the asset can't be saved, the
+// absolute URL of http(s) links is returned so the copy still loads it online, and raw is returned for other links.
+func (s *pageSaver) link(base *url.URL, raw, prefix string) string {
+	if local := s.save(base, raw); local != "" {
+		return prefix + local
+	}
+	u, err := base.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return raw
+	}
+	return u.String()
+}
+
+// rewriteCSSURLs replaces the URLs of the url() references and @import rules of the stylesheet with the result of
+// rewrite.
+func rewriteCSSURLs(css string, rewrite func(raw string) string) string {
+	return cssURLPattern.ReplaceAllStringFunc(css, func(match string) string {
+		groups := cssURLPattern.FindStringSubmatch(match)
+		for _, raw := range groups[1:] {
+			if raw == "" {
+				continue
+			}
+			rewritten := rewrite(raw)
+			if rewritten == raw {
+				return match
+			}
+			if strings.HasPrefix(match, "@import") {
+				return fmt.Sprintf("@import %q", rewritten)
+			}
+			return fmt.Sprintf("url(%q)", rewritten)
+		}
+		return match
+	})
+}
+
+// save downloads the asset at raw, resolved against base, into the assets directory and returns its file name, or an
+// empty string when the URL is not an http(s) URL or the download fails. The references of stylesheets are saved too.
+func (s *pageSaver) save(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := base.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	u.Fragment = ""
+	assetURL := u.String()
+	if name, done := s.saved[assetURL]; done {
+		return name
+	}
+
+	data, contentType, err := s.nav.FetchFile(assetURL)
+	if err != nil {
+		s.nav.Logger.Printf("Error - Failed to save asset %s: %v\n", assetURL, err)
+		s.saved[assetURL] = ""
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(assetURL))
+	extension := documentExtension("", assetURL, contentType)
+	if len(extension) > 8 {
+		extension = ""
+	}
+	name := hex.EncodeToString(sum[:8]) + extension
+	// set before the stylesheet references are saved, so import cycles end here
+	s.saved[assetURL] = name
+
+	if extension == ".css" || strings.HasPrefix(contentType, "text/css") {
+		data = []byte(s.rewriteCSS(u, string(data), ""))
+	}
+	err = os.WriteFile(filepath.Join(s.dir, "assets", name), data, 0644)
+	if err != nil {
+		s.nav.Logger.Printf("Error - Failed to save asset %s: %v\n", assetURL, err)
+		s.saved[assetURL] = ""
+		return ""
+	}
+	return name
+}
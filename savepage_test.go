@@ -0,0 +1,99 @@
+package goSpider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSavePageComplete(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/savepage.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	dir := t.TempDir()
+	err = nav.SavePageComplete(dir)
+	if err != nil {
+		t.Fatalf("SavePageComplete error: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read saved page: %v", err)
+	}
+	missing := server.URL + "/files/missing.png"
+	if !strings.Contains(string(page), missing) || strings.Contains(strings.ReplaceAll(string(page), missing, ""), "files/") {
+		t.Errorf("Expected the missing image to be linked online and the other links to be local, got %s", page)
+	}
+	if !strings.Contains(string(page), "Archived page") {
+		t.Errorf("Expected the page content to be saved, got %s", page)
+	}
+
+	assets, err := os.ReadDir(filepath.Join(dir, "assets"))
+	if err != nil {
+		t.Fatalf("Failed to read assets: %v", err)
+	}
+	extensions := map[string]int{}
+	for _, asset := range assets {
+		extensions[filepath.Ext(asset.Name())]++
+	}
+	if extensions[".css"] != 1 || extensions[".js"] != 1 || extensions[".png"] != 1 || len(assets) != 3 {
+		t.Errorf("Expected the stylesheet, the script and the image, got %v", extensions)
+	}
+	for _, asset := range assets {
+		if filepath.Ext(asset.Name()) != ".css" {
+			continue
+		}
+		css, _ := os.ReadFile(filepath.Join(dir, "assets", asset.Name()))
+		if strings.Contains(string(css), "pixel.png") || !strings.Contains(string(css), ".png") {
+			t.Errorf("Expected the stylesheet image to be rewritten, got %s", css)
+		}
+	}
+}
+
+func TestSaveMHTML(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/savepage.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive", "page.mhtml")
+	err = nav.SaveMHTML(path)
+	if err != nil {
+		t.Fatalf("SaveMHTML error: %v", err)
+	}
+	snapshot, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	if !strings.Contains(string(snapshot), "multipart/related") || !strings.Contains(string(snapshot), "Archived page") {
+		t.Errorf("Expected an MHTML archive of the page, got %.500s", snapshot)
+	}
+	if !strings.Contains(string(snapshot), "Content-Type: text/css") {
+		t.Errorf("Expected the stylesheet in the archive, got %.500s", snapshot)
+	}
+}
+
+func TestRewriteCSSURLs(t *testing.T) {
+	css := `@import "theme.css"; body { background: url(bg.png) } h1 { background: url('data:image/png;base64,AA==') }`
+	got := rewriteCSSURLs(css, func(raw string) string {
+		if strings.HasPrefix(raw, "data:") {
+			return raw
+		}
+		return "local/" + raw
+	})
+	want := `@import "local/theme.css"; body { background: url("local/bg.png") } h1 { background: url('data:image/png;base64,AA==') }`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,331 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule returns the next time a crawl should run after a given time.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule runs every fixed interval.
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// Every returns a Schedule that runs every interval.
+// Example:
+//
+//	schedule := goSpider.Every(30 * time.Minute)
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule(interval)
+}
+
+// cronSchedule runs at the times matching a cron spec.
+type cronSchedule struct {
+	minute, hour, day, month, weekday map[int]bool
+	anyDay, anyWeekday                bool
+}
+
+// ParseCron parses a standard 5 field cron spec: minute, hour, day of month, month and day of week, with "*", lists,
+// ranges and steps, e.g. "*/15 8-18 * * 1-5". Times are in the local time zone.
+// Example:
+//
+//	schedule, err := goSpider.ParseCron("0 6 * * 1-5")
+func ParseCron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("error - cron spec must have 5 fields: %s", spec)
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var sets [5]map[int]bool
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid cron field %q: %w", field, err)
+		}
+		sets[i] = set
+	}
+	// both 0 and 7 are Sunday
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+	return &cronSchedule{
+		minute:     sets[0],
+		hour:       sets[1],
+		day:        sets[2],
+		month:      sets[3],
+		weekday:    sets[4],
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the values of a cron field between min and max.
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if slash := strings.Index(part, "/"); slash >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[slash+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part[slash+1:])
+			}
+			part = part[:slash]
+		}
+
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", bounds[0])
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", bounds[1])
+				}
+			} else if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value out of range %d-%d", min, max)
+		}
+		for value := start; value <= end; value += step {
+			set[value] = true
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first minute after the given time that matches the spec.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// a matching time is always found within 5 years, unless the spec can never match (e.g. February 30)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay follows cron: when both the day of month and the day of week are restricted, either may match.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	day := s.day[t.Day()]
+	weekday := s.weekday[int(t.Weekday())]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// CrawlFunc is a crawl run by a Scheduler with its Navigator pool.
+type CrawlFunc func(ctx context.Context, pool *NavigatorPool) error
+
+// schedulerJob is a crawl registered in a Scheduler.
+type schedulerJob struct {
+	name     string
+	schedule Schedule
+	crawl    CrawlFunc
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs registered crawls on their schedules with a shared NavigatorPool, without the need of a server.
+// A crawl that is still running when it is due again is skipped rather than run twice, and the time of the last run of
+// each crawl is saved, so a restarted scheduler catches up on the runs it missed instead of starting over.
+type Scheduler struct {
+	Pool   *NavigatorPool
+	Logger *log.Logger
+
+	mu        sync.Mutex
+	statePath string
+	jobs      []*schedulerJob
+	lastRuns  map[string]time.Time
+	wg        sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler that runs crawls with pool and saves their last runs at statePath.
+// An empty statePath keeps the last runs in memory only.
+// Example:
+//
+//	scheduler, err := goSpider.NewScheduler(pool, "scheduler.json")
+//	schedule, err := goSpider.ParseCron("0 6 * * 1-5")
+//	err = scheduler.Register("tjsp", schedule, func(ctx context.Context, pool *goSpider.NavigatorPool) error {
+//		_, err := goSpider.ParallelRequests(requests, 4, 0, pool.Fetch)
+//		return err
+//	})
+//	err = scheduler.Run(ctx)
+func NewScheduler(pool *NavigatorPool, statePath string) (*Scheduler, error) {
+	scheduler := &Scheduler{
+		Pool:      pool,
+		Logger:    log.New(os.Stdout, "goSpider: ", log.LstdFlags),
+		statePath: statePath,
+		lastRuns:  map[string]time.Time{},
+	}
+	if statePath == "" {
+		return scheduler, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return scheduler, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read scheduler state: %w", err)
+	}
+	err = json.Unmarshal(data, &scheduler.lastRuns)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to decode scheduler state: %w", err)
+	}
+	return scheduler, nil
+}
+
+// Register adds a crawl with a unique name. Its first run is due one schedule after its last saved run, or one schedule
+// after now when it never ran.
+func (s *Scheduler) Register(name string, schedule Schedule, crawl CrawlFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.name == name {
+			return fmt.Errorf("error - crawl already registered: %s", name)
+		}
+	}
+
+	after := time.Now()
+	if lastRun, ok := s.lastRuns[name]; ok {
+		after = lastRun
+	}
+	s.jobs = append(s.jobs, &schedulerJob{name: name, schedule: schedule, crawl: crawl, next: schedule.Next(after)})
+	return nil
+}
+
+// LastRuns returns the time each crawl last started.
+func (s *Scheduler) LastRuns() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastRuns := make(map[string]time.Time, len(s.lastRuns))
+	for name, lastRun := range s.lastRuns {
+		lastRuns[name] = lastRun
+	}
+	return lastRuns
+}
+
+// Run starts the due crawls until ctx is done, then waits for the running ones to finish.
+func (s *Scheduler) Run(ctx context.Context) error {
+	defer s.wg.Wait()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		next := s.startDue(ctx, now)
+		wait := time.Minute
+		if !next.IsZero() {
+			wait = next.Sub(now)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// startDue starts the crawls due at now and returns the time the next one is due.
+func (s *Scheduler) startDue(ctx context.Context, now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, job := range s.jobs {
+		if !job.next.After(now) {
+			if job.running {
+				s.Logger.Printf("Skipping crawl %s: previous run still running\n", job.name)
+			} else {
+				s.start(ctx, job, now)
+			}
+			job.next = job.schedule.Next(now)
+		}
+		if !job.next.IsZero() && (next.IsZero() || job.next.Before(next)) {
+			next = job.next
+		}
+	}
+	return next
+}
+
+// start runs the crawl in its own goroutine and saves its start time. It is called with s.mu held.
+func (s *Scheduler) start(ctx context.Context, job *schedulerJob, now time.Time) {
+	job.running = true
+	s.lastRuns[job.name] = now
+	err := s.save()
+	if err != nil {
+		s.Logger.Printf("Error - Failed to save scheduler state: %v\n", err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.Logger.Printf("Starting crawl %s\n", job.name)
+		err := job.crawl(ctx, s.Pool)
+		if err != nil {
+			s.Logger.Printf("Error - Crawl %s failed: %v\n", job.name, err)
+		} else {
+			s.Logger.Printf("Crawl %s finished in %v\n", job.name, time.Since(now))
+		}
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+	}()
+}
+
+// save writes the last runs to the state path. It is called with s.mu held.
+func (s *Scheduler) save() error {
+	if s.statePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.lastRuns, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath, data, 0644)
+}
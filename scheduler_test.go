@@ -0,0 +1,89 @@
+package goSpider
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	after := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.Local) // a Friday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 15, 0, 0, time.Local)},
+		{"0 6 * * *", time.Date(2024, time.March, 16, 6, 0, 0, 0, time.Local)},
+		{"30 8-18 * * 1-5", time.Date(2024, time.March, 15, 10, 30, 0, 0, time.Local)},
+		{"0 9 * * 1", time.Date(2024, time.March, 18, 9, 0, 0, 0, time.Local)},
+		{"0 0 1 1,7 *", time.Date(2024, time.July, 1, 0, 0, 0, 0, time.Local)},
+		{"0 12 * * 7", time.Date(2024, time.March, 17, 12, 0, 0, 0, time.Local)},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCron(test.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error: %v", test.spec, err)
+		}
+		if got := schedule.Next(after); !got.Equal(test.want) {
+			t.Errorf("ParseCron(%q).Next = %v, want %v", test.spec, got, test.want)
+		}
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("Expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "scheduler.json")
+	scheduler, err := NewScheduler(nil, statePath)
+	if err != nil {
+		t.Fatalf("NewScheduler error: %v", err)
+	}
+
+	var fastRuns, slowRuns int32
+	err = scheduler.Register("fast", Every(20*time.Millisecond), func(ctx context.Context, pool *NavigatorPool) error {
+		atomic.AddInt32(&fastRuns, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	// runs longer than its interval, so overlapping runs must be skipped
+	err = scheduler.Register("slow", Every(20*time.Millisecond), func(ctx context.Context, pool *NavigatorPool) error {
+		atomic.AddInt32(&slowRuns, 1)
+		time.Sleep(150 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if scheduler.Register("fast", Every(time.Second), nil) == nil {
+		t.Errorf("Expected an error registering a duplicated name")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	scheduler.Run(ctx)
+
+	if fast := atomic.LoadInt32(&fastRuns); fast < 5 {
+		t.Errorf("Expected the fast crawl to run several times, got %d", fast)
+	}
+	if slow := atomic.LoadInt32(&slowRuns); slow < 1 || slow > 2 {
+		t.Errorf("Expected overlapping runs of the slow crawl to be skipped, got %d runs", slow)
+	}
+
+	// the last runs are restored by a new scheduler
+	restored, err := NewScheduler(nil, statePath)
+	if err != nil {
+		t.Fatalf("NewScheduler error: %v", err)
+	}
+	lastRuns := restored.LastRuns()
+	if lastRuns["fast"].IsZero() || lastRuns["slow"].IsZero() {
+		t.Errorf("Expected the last runs to be saved, got %v", lastRuns)
+	}
+}
@@ -0,0 +1,72 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"net/url"
+	"strings"
+)
+
+// RestrictNavigation makes the browser fail every navigation, of the page or of its frames, to a host outside the
+// allowed host patterns, so a crawl redirected off-site, e.g. to an ad network or a login wall, fails with
+// ErrNavigationFailed instead of scraping another site. A pattern is a host, as in "esaj.tjsp.jus.br", or a domain and
+// its subdomains, as in "*.jus.br". Each call replaces the patterns allowed before; calling it without patterns stops
+// restricting. It combines with BlockResourceTypes, EnableChaos and cassettes, which share Fetch interception: the
+// navigations blocked never reach them.
+// Example:
+//
+//	err := nav.RestrictNavigation("*.tjsp.jus.br", "www.jusbrasil.com.br")
+//	err = nav.OpenURL("https://esaj.tjsp.jus.br/cpopg/open.do")
+func (nav *Navigator) RestrictNavigation(allowedHostPatterns ...string) error {
+	var rule *interceptRule
+	if len(allowedHostPatterns) > 0 {
+		rule = &interceptRule{types: []ResourceType{Document}, handle: func(paused *fetch.EventRequestPaused) func(ctx context.Context) bool {
+			if allowedHost(paused.Request.URL, allowedHostPatterns) {
+				return nil
+			}
+			return func(ctx context.Context) bool {
+				nav.Logger.Printf("Blocked navigation to %s\n", paused.Request.URL)
+				_ = chromedp.Run(ctx, fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient))
+				return true
+			}
+		}}
+	}
+	err := nav.setInterceptRule(interceptRestriction, rule)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to restrict navigation: %v\n", err)
+		return fmt.Errorf("error - failed to restrict navigation: %w", classifyError(err))
+	}
+	if rule == nil {
+		nav.Logger.Printf("Stopped restricting navigation\n")
+		return nil
+	}
+	nav.Logger.Printf("Restricting navigation to %v\n", allowedHostPatterns)
+	return nil
+}
+
+// allowedHost reports whether the host of rawURL matches one of the host patterns, see RestrictNavigation. URLs
+// without a host, such as data URLs, are allowed.
+func allowedHost(rawURL string, patterns []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return true
+	}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
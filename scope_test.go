@@ -0,0 +1,84 @@
+package goSpider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAllowedHost(t *testing.T) {
+	patterns := []string{"esaj.tjsp.jus.br", "*.example.com"}
+	tests := map[string]bool{
+		"https://esaj.tjsp.jus.br/cpopg/open.do":  true,
+		"https://ESAJ.tjsp.jus.br:8443/":          true,
+		"https://tjsp.jus.br/":                    false,
+		"https://example.com/":                    true,
+		"https://www.example.com/a":               true,
+		"https://a.b.example.com/":                true,
+		"https://notexample.com/":                 false,
+		"https://example.com.evil.net/":           false,
+		"https://ads.doubleclick.net/?r=tjsp.jus": false,
+		"data:text/html,<p>inline</p>":            true,
+	}
+	for rawURL, expected := range tests {
+		if allowedHost(rawURL, patterns) != expected {
+			t.Errorf("allowedHost(%q) = %v, expected %v", rawURL, !expected, expected)
+		}
+	}
+}
+
+func TestRestrictNavigation(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.RestrictNavigation("127.0.0.1")
+	if err != nil {
+		t.Fatalf("RestrictNavigation error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("Expected the allowed host to open, got %v", err)
+	}
+	offsite := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/test.html"
+	err = nav.OpenURL(offsite)
+	if !errors.Is(err, ErrNavigationFailed) {
+		t.Errorf("Expected the navigation to another host to fail, got %v", err)
+	}
+
+	err = nav.RestrictNavigation()
+	if err != nil {
+		t.Fatalf("RestrictNavigation error: %v", err)
+	}
+	err = nav.OpenURL(offsite)
+	if err != nil {
+		t.Errorf("Expected the navigation to succeed after lifting the restriction, got %v", err)
+	}
+}
+
+func TestRestrictNavigationWithBlocking(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.BlockResourceTypes(FastScrape...)
+	if err != nil {
+		t.Fatalf("BlockResourceTypes error: %v", err)
+	}
+	err = nav.RestrictNavigation("127.0.0.1")
+	if err != nil {
+		t.Fatalf("RestrictNavigation error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/images.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	width, err := nav.EvaluateScript(`document.getElementById("photo").naturalWidth`)
+	if err != nil || width != float64(0) {
+		t.Errorf("Expected the image to stay blocked, got width %v: %v", width, err)
+	}
+	err = nav.OpenURL(strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/images.html")
+	if !errors.Is(err, ErrNavigationFailed) {
+		t.Errorf("Expected the navigation to another host to fail, got %v", err)
+	}
+}
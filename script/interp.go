@@ -0,0 +1,740 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// value is a script value: nil, bool, float64, string, *table, *closure or builtin.
+type value interface{}
+
+// builtin is a function implemented in Go.
+type builtin func(args []value) (value, error)
+
+// closure is a function defined by the script, with the scope it was defined in.
+type closure struct {
+	fn    *functionExpr
+	scope *scope
+}
+
+// table is the only data structure of scripts, used as a list and as a map. Items 1 to n of a list are stored in
+// array.
+type table struct {
+	array []value
+	hash  map[value]value
+}
+
+func newTable() *table {
+	return &table{hash: make(map[value]value)}
+}
+
+// listOf returns a table of the values as a list.
+func listOf(values ...value) *table {
+	t := newTable()
+	t.array = values
+	return t
+}
+
+func (t *table) get(key value) value {
+	if i, ok := arrayIndex(key); ok && i <= len(t.array) {
+		return t.array[i-1]
+	}
+	return t.hash[key]
+}
+
+func (t *table) set(key, v value) error {
+	switch key := key.(type) {
+	case nil:
+		return errors.New("table index is nil")
+	case builtin:
+		return errors.New("table index is a builtin function")
+	case float64:
+		if math.IsNaN(key) {
+			return errors.New("table index is NaN")
+		}
+	}
+
+	if i, ok := arrayIndex(key); ok {
+		switch {
+		case i <= len(t.array):
+			t.array[i-1] = v
+			for len(t.array) > 0 && t.array[len(t.array)-1] == nil {
+				t.array = t.array[:len(t.array)-1]
+			}
+			return nil
+		case i == len(t.array)+1 && v != nil:
+			t.array = append(t.array, v)
+			// the following items may have been set before
+			for {
+				next, ok := t.hash[float64(len(t.array)+1)]
+				if !ok {
+					return nil
+				}
+				delete(t.hash, float64(len(t.array)+1))
+				t.array = append(t.array, next)
+			}
+		}
+	}
+	if v == nil {
+		delete(t.hash, key)
+	} else {
+		t.hash[key] = v
+	}
+	return nil
+}
+
+// arrayIndex returns the list index of a key, if it is a positive integer.
+func arrayIndex(key value) (int, bool) {
+	n, ok := key.(float64)
+	if !ok || n < 1 || n > math.MaxInt32 || n != math.Trunc(n) {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// entries returns the items of the list part, then the other keys sorted: numbers, strings and the rest.
+func (t *table) entries() ([]value, []value) {
+	keys := make([]value, 0, len(t.array)+len(t.hash))
+	values := make([]value, 0, len(t.array)+len(t.hash))
+	for i, v := range t.array {
+		if v != nil {
+			keys, values = append(keys, float64(i+1)), append(values, v)
+		}
+	}
+	rest := make([]value, 0, len(t.hash))
+	for key := range t.hash {
+		rest = append(rest, key)
+	}
+	rank := func(v value) int {
+		switch v.(type) {
+		case float64:
+			return 0
+		case string:
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		a, b := rest[i], rest[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		switch a := a.(type) {
+		case float64:
+			return a < b.(float64)
+		case string:
+			return a < b.(string)
+		}
+		return fmt.Sprint(a) < fmt.Sprint(b)
+	})
+	for _, key := range rest {
+		keys, values = append(keys, key), append(values, t.hash[key])
+	}
+	return keys, values
+}
+
+// scope holds the variables of a block.
+type scope struct {
+	vars   map[string]value
+	parent *scope
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{vars: make(map[string]value), parent: parent}
+}
+
+// lookup returns the scope defining the variable, if any.
+func (s *scope) lookup(name string) (*scope, bool) {
+	for ; s != nil; s = s.parent {
+		if _, ok := s.vars[name]; ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Error is an error of a running script, with the line it happened on. It wraps the errors of the Browser, so
+// errors.Is(err, goSpider.ErrElementNotFound) works on them.
+type Error struct {
+	Script string
+	Line   int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.Script, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// maxDepth is the maximum depth of nested function calls.
+const maxDepth = 200
+
+// flowKind is how the execution of a block ended.
+type flowKind int
+
+const (
+	flowNormal flowKind = iota
+	flowBreak
+	flowReturn
+)
+
+// interpreter runs the syntax tree of a script.
+type interpreter struct {
+	name     string
+	globals  *scope
+	steps    int
+	maxSteps int
+	deadline time.Time
+	depth    int
+}
+
+// errorf returns an Error on the line, keeping errors that already have a line.
+func (in *interpreter) errorf(line int, err error) error {
+	var scriptErr *Error
+	if errors.As(err, &scriptErr) {
+		return err
+	}
+	return &Error{Script: in.name, Line: line, Err: err}
+}
+
+// step counts a statement or call, failing when the script runs out of steps or time.
+func (in *interpreter) step(line int) error {
+	in.steps++
+	if in.maxSteps > 0 && in.steps > in.maxSteps {
+		return in.errorf(line, fmt.Errorf("script exceeded %d steps", in.maxSteps))
+	}
+	if !in.deadline.IsZero() && in.steps%100 == 0 && time.Now().After(in.deadline) {
+		return in.errorf(line, errors.New("script timed out"))
+	}
+	return nil
+}
+
+// exec runs the statements of a block in a new scope.
+func (in *interpreter) exec(b *block, parent *scope) (flowKind, value, error) {
+	s := newScope(parent)
+	for _, st := range b.stmts {
+		flow, v, err := in.execStmt(st, s)
+		if err != nil || flow != flowNormal {
+			return flow, v, err
+		}
+	}
+	return flowNormal, nil, nil
+}
+
+func (in *interpreter) execStmt(st stmt, s *scope) (flowKind, value, error) {
+	switch st := st.(type) {
+	case *localStmt:
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		values, err := in.evalList(st.values, s)
+		if err != nil {
+			return flowNormal, nil, err
+		}
+		for i, name := range st.names {
+			s.vars[name] = nil
+			if i < len(values) {
+				s.vars[name] = values[i]
+			}
+		}
+	case *localFunctionStmt:
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		// the closure is in the scope defining it, so the function can call itself
+		s.vars[st.name] = &closure{fn: st.fn, scope: s}
+	case *assignStmt:
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		return flowNormal, nil, in.assign(st, s)
+	case *callStmt:
+		_, err := in.eval(st.call, s)
+		return flowNormal, nil, err
+	case *ifStmt:
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		for i, cond := range st.conds {
+			v, err := in.eval(cond, s)
+			if err != nil {
+				return flowNormal, nil, err
+			}
+			if truthy(v) {
+				return in.exec(st.blocks[i], s)
+			}
+		}
+		if st.orElse != nil {
+			return in.exec(st.orElse, s)
+		}
+	case *whileStmt:
+		for {
+			if err := in.step(st.line); err != nil {
+				return flowNormal, nil, err
+			}
+			v, err := in.eval(st.cond, s)
+			if err != nil || !truthy(v) {
+				return flowNormal, nil, err
+			}
+			flow, v, err := in.exec(st.body, s)
+			if err != nil || flow == flowReturn {
+				return flow, v, err
+			}
+			if flow == flowBreak {
+				return flowNormal, nil, nil
+			}
+		}
+	case *numericForStmt:
+		return in.numericFor(st, s)
+	case *genericForStmt:
+		return in.genericFor(st, s)
+	case *returnStmt:
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		if st.value == nil {
+			return flowReturn, nil, nil
+		}
+		v, err := in.eval(st.value, s)
+		return flowReturn, v, err
+	case *breakStmt:
+		return flowBreak, nil, nil
+	case *doStmt:
+		return in.exec(st.body, s)
+	}
+	return flowNormal, nil, nil
+}
+
+func (in *interpreter) assign(st *assignStmt, s *scope) error {
+	values, err := in.evalList(st.values, s)
+	if err != nil {
+		return err
+	}
+	for i, target := range st.targets {
+		var v value
+		if i < len(values) {
+			v = values[i]
+		}
+		switch target := target.(type) {
+		case *nameExpr:
+			if defining, ok := s.lookup(target.name); ok {
+				defining.vars[target.name] = v
+			} else {
+				in.globals.vars[target.name] = v
+			}
+		case *indexExpr:
+			object, err := in.eval(target.object, s)
+			if err != nil {
+				return err
+			}
+			t, ok := object.(*table)
+			if !ok {
+				return in.errorf(target.line, fmt.Errorf("attempt to index a %s value%s", typeName(object), describeExpr(target.object)))
+			}
+			key, err := in.eval(target.key, s)
+			if err != nil {
+				return err
+			}
+			if err := t.set(key, v); err != nil {
+				return in.errorf(target.line, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (in *interpreter) numericFor(st *numericForStmt, s *scope) (flowKind, value, error) {
+	bounds := []expr{st.start, st.stop, st.step}
+	numbers := []float64{0, 0, 1}
+	for i, e := range bounds {
+		if e == nil {
+			continue
+		}
+		v, err := in.eval(e, s)
+		if err != nil {
+			return flowNormal, nil, err
+		}
+		n, ok := v.(float64)
+		if !ok {
+			return flowNormal, nil, in.errorf(st.line, fmt.Errorf("'for' bound must be a number, got %s", typeName(v)))
+		}
+		numbers[i] = n
+	}
+	start, stop, step := numbers[0], numbers[1], numbers[2]
+	if step == 0 {
+		return flowNormal, nil, in.errorf(st.line, errors.New("'for' step is zero"))
+	}
+	for i := start; step > 0 && i <= stop || step < 0 && i >= stop; i += step {
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		loop := newScope(s)
+		loop.vars[st.name] = i
+		flow, v, err := in.exec(st.body, loop)
+		if err != nil || flow == flowReturn {
+			return flow, v, err
+		}
+		if flow == flowBreak {
+			break
+		}
+	}
+	return flowNormal, nil, nil
+}
+
+func (in *interpreter) genericFor(st *genericForStmt, s *scope) (flowKind, value, error) {
+	v, err := in.eval(st.iterable, s)
+	if err != nil {
+		return flowNormal, nil, err
+	}
+	t, ok := v.(*table)
+	if !ok {
+		return flowNormal, nil, in.errorf(st.line, fmt.Errorf("attempt to iterate over a %s value%s", typeName(v), describeExpr(st.iterable)))
+	}
+	keys, values := t.entries()
+	for i := range keys {
+		if err := in.step(st.line); err != nil {
+			return flowNormal, nil, err
+		}
+		loop := newScope(s)
+		loop.vars[st.key] = keys[i]
+		if st.value != "" {
+			loop.vars[st.value] = values[i]
+		}
+		flow, v, err := in.exec(st.body, loop)
+		if err != nil || flow == flowReturn {
+			return flow, v, err
+		}
+		if flow == flowBreak {
+			break
+		}
+	}
+	return flowNormal, nil, nil
+}
+
+func (in *interpreter) evalList(exprs []expr, s *scope) ([]value, error) {
+	values := make([]value, len(exprs))
+	for i, e := range exprs {
+		v, err := in.eval(e, s)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (in *interpreter) eval(e expr, s *scope) (value, error) {
+	switch e := e.(type) {
+	case *constExpr:
+		return e.value, nil
+	case *nameExpr:
+		if defining, ok := s.lookup(e.name); ok {
+			return defining.vars[e.name], nil
+		}
+		return nil, nil
+	case *indexExpr:
+		object, err := in.eval(e.object, s)
+		if err != nil {
+			return nil, err
+		}
+		t, ok := object.(*table)
+		if !ok {
+			return nil, in.errorf(e.line, fmt.Errorf("attempt to index a %s value%s", typeName(object), describeExpr(e.object)))
+		}
+		key, err := in.eval(e.key, s)
+		if err != nil {
+			return nil, err
+		}
+		return t.get(key), nil
+	case *callExpr:
+		fn, err := in.eval(e.fn, s)
+		if err != nil {
+			return nil, err
+		}
+		args, err := in.evalList(e.args, s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := fn.(builtin); !ok {
+			if _, ok := fn.(*closure); !ok {
+				return nil, in.errorf(e.line, fmt.Errorf("attempt to call a %s value%s", typeName(fn), describeExpr(e.fn)))
+			}
+		}
+		return in.call(fn, args, e.line)
+	case *functionExpr:
+		return &closure{fn: e, scope: s}, nil
+	case *tableExpr:
+		t := newTable()
+		for _, item := range e.items {
+			v, err := in.eval(item, s)
+			if err != nil {
+				return nil, err
+			}
+			// nil items keep their position
+			t.array = append(t.array, v)
+		}
+		for i := range e.keys {
+			key, err := in.eval(e.keys[i], s)
+			if err != nil {
+				return nil, err
+			}
+			v, err := in.eval(e.values[i], s)
+			if err != nil {
+				return nil, err
+			}
+			if err := t.set(key, v); err != nil {
+				return nil, in.errorf(e.line, err)
+			}
+		}
+		for len(t.array) > 0 && t.array[len(t.array)-1] == nil {
+			t.array = t.array[:len(t.array)-1]
+		}
+		return t, nil
+	case *unaryExpr:
+		v, err := in.eval(e.operand, s)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "not":
+			return !truthy(v), nil
+		case "-":
+			n, ok := v.(float64)
+			if !ok {
+				return nil, in.errorf(e.line, fmt.Errorf("attempt to perform arithmetic on a %s value%s", typeName(v), describeExpr(e.operand)))
+			}
+			return -n, nil
+		case "#":
+			switch v := v.(type) {
+			case string:
+				return float64(len(v)), nil
+			case *table:
+				return float64(len(v.array)), nil
+			}
+			return nil, in.errorf(e.line, fmt.Errorf("attempt to get length of a %s value%s", typeName(v), describeExpr(e.operand)))
+		}
+	case *binaryExpr:
+		return in.binary(e, s)
+	}
+	return nil, fmt.Errorf("unknown expression %T", e)
+}
+
+func (in *interpreter) binary(e *binaryExpr, s *scope) (value, error) {
+	left, err := in.eval(e.left, s)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "and":
+		if !truthy(left) {
+			return left, nil
+		}
+		return in.eval(e.right, s)
+	case "or":
+		if truthy(left) {
+			return left, nil
+		}
+		return in.eval(e.right, s)
+	}
+	right, err := in.eval(e.right, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return equal(left, right), nil
+	case "~=":
+		return !equal(left, right), nil
+	case "..":
+		a, okA := concatenable(left)
+		b, okB := concatenable(right)
+		if !okA || !okB {
+			bad, operand := left, e.left
+			if okA {
+				bad, operand = right, e.right
+			}
+			return nil, in.errorf(e.line, fmt.Errorf("attempt to concatenate a %s value%s", typeName(bad), describeExpr(operand)))
+		}
+		return a + b, nil
+	case "<", "<=", ">", ">=":
+		return in.compare(e, left, right)
+	}
+
+	a, okA := left.(float64)
+	b, okB := right.(float64)
+	if !okA || !okB {
+		bad, operand := left, e.left
+		if okA {
+			bad, operand = right, e.right
+		}
+		return nil, in.errorf(e.line, fmt.Errorf("attempt to perform arithmetic on a %s value%s", typeName(bad), describeExpr(operand)))
+	}
+	switch e.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		return a / b, nil
+	case "%":
+		return a - math.Floor(a/b)*b, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", e.op)
+}
+
+func (in *interpreter) compare(e *binaryExpr, left, right value) (value, error) {
+	var less, equal bool
+	switch a := left.(type) {
+	case float64:
+		b, ok := right.(float64)
+		if !ok {
+			return nil, in.errorf(e.line, fmt.Errorf("attempt to compare number with %s", typeName(right)))
+		}
+		less, equal = a < b, a == b
+	case string:
+		b, ok := right.(string)
+		if !ok {
+			return nil, in.errorf(e.line, fmt.Errorf("attempt to compare string with %s", typeName(right)))
+		}
+		less, equal = a < b, a == b
+	default:
+		return nil, in.errorf(e.line, fmt.Errorf("attempt to compare %s with %s", typeName(left), typeName(right)))
+	}
+	switch e.op {
+	case "<":
+		return less, nil
+	case "<=":
+		return less || equal, nil
+	case ">":
+		return !less && !equal, nil
+	}
+	return !less, nil
+}
+
+// call calls a function with the arguments and returns its result.
+func (in *interpreter) call(fn value, args []value, line int) (value, error) {
+	if err := in.step(line); err != nil {
+		return nil, err
+	}
+	switch fn := fn.(type) {
+	case builtin:
+		v, err := fn(args)
+		if err != nil {
+			return nil, in.errorf(line, err)
+		}
+		return v, nil
+	case *closure:
+		if in.depth >= maxDepth {
+			return nil, in.errorf(line, errors.New("stack overflow"))
+		}
+		in.depth++
+		defer func() { in.depth-- }()
+		s := newScope(fn.scope)
+		for i, param := range fn.fn.params {
+			s.vars[param] = nil
+			if i < len(args) {
+				s.vars[param] = args[i]
+			}
+		}
+		_, v, err := in.exec(fn.fn.body, s)
+		return v, err
+	}
+	return nil, in.errorf(line, fmt.Errorf("attempt to call a %s value", typeName(fn)))
+}
+
+// equal reports whether two values are the same; tables and functions are equal only to themselves.
+func equal(a, b value) bool {
+	fa, okA := a.(builtin)
+	fb, okB := b.(builtin)
+	if okA || okB {
+		// functions can't be compared with ==
+		return okA && okB && reflect.ValueOf(fa).Pointer() == reflect.ValueOf(fb).Pointer()
+	}
+	return a == b
+}
+
+// truthy reports whether a value holds in a condition: every value but nil and false.
+func truthy(v value) bool {
+	return v != nil && v != false
+}
+
+// typeName returns the script type of a value.
+func typeName(v value) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *table:
+		return "table"
+	}
+	return "function"
+}
+
+// describeExpr names the variable or field of an expression in errors, as in " (variable 'foo')".
+func describeExpr(e expr) string {
+	switch e := e.(type) {
+	case *nameExpr:
+		return fmt.Sprintf(" (variable '%s')", e.name)
+	case *indexExpr:
+		if key, ok := e.key.(*constExpr); ok {
+			if name, ok := key.value.(string); ok {
+				return fmt.Sprintf(" (field '%s')", name)
+			}
+		}
+	}
+	return ""
+}
+
+// concatenable returns the text of a string or number.
+func concatenable(v value) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return formatNumber(v), true
+	}
+	return "", false
+}
+
+// formatNumber formats integers without a fraction.
+func formatNumber(n float64) string {
+	if n == math.Trunc(n) && math.Abs(n) < 1e15 {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return strconv.FormatFloat(n, 'g', 14, 64)
+}
+
+// toString returns the text of a value, as printed.
+func toString(v value) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return formatNumber(v)
+	case string:
+		return v
+	case *table:
+		return fmt.Sprintf("table: %p", v)
+	case *closure:
+		return fmt.Sprintf("function: %p", v)
+	}
+	return "function: builtin"
+}
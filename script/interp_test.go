@@ -0,0 +1,101 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// eval runs the source and returns the value of its result global.
+func eval(t *testing.T, source string) (value, error) {
+	t.Helper()
+	body, err := parse(source)
+	if err != nil {
+		t.Fatalf("parse error in %q: %v", source, err)
+	}
+	in := &interpreter{name: "test.lua", globals: newScope(nil), maxSteps: 100000}
+	bindLibrary(in.globals.vars)
+	_, _, err = in.exec(body, in.globals)
+	return in.globals.vars["result"], err
+}
+
+func TestInterpreter(t *testing.T) {
+	tests := map[string]value{
+		"result = 1 + 2 * 3 - 4 / 2":                                          float64(5),
+		"result = (1 + 2) * 3 % 4":                                            float64(1),
+		"result = -2 - -3":                                                    float64(1),
+		"result = 'a' .. 1 .. \"b\"":                                          "a1b",
+		"result = #'abc' + #{1, 2}":                                           float64(5),
+		"result = 1 < 2 and 'yes' or 'no'":                                    "yes",
+		"result = nil or false":                                               false,
+		"result = not nil":                                                    true,
+		"result = 'a' < 'b' and 1 ~= 2 and 1 == 1.0":                          true,
+		"result = 0x10 + 1e2":                                                 float64(116),
+		"result = [[long\nstring]] -- comment":                                "long\nstring",
+		"--[[ block\ncomment ]] result = 'after'":                             "after",
+		"local t = {1, 2, x = 3, ['y z'] = 4} result = t[2] + t.x + t['y z']": float64(9),
+		"local t = {} t.a = {} t.a.b = 5 result = t.a.b":                      float64(5),
+		"local t = {1, 2, 3} table.insert(t, 4) table.remove(t) result = table.concat(t, '-')": "1-2-3",
+		"local a, b = 1 result = b":                                                                                              nil,
+		"local a, b = 1, 2 a, b = b, a result = a .. b":                                                                          "21",
+		"result = 0 for i = 1, 10 do result = result + i end":                                                                    float64(55),
+		"result = '' for i = 3, 1, -1 do result = result .. i end":                                                               "321",
+		"result = 0 local i = 0 while true do i = i + 1 if i > 4 then break end result = result + i end":                         float64(10),
+		"result = '' for k, v in {10, 20, b = 2, a = 1} do result = result .. k .. '=' .. v .. ' ' end":                          "1=10 2=20 a=1 b=2 ",
+		"result = 0 for _, v in ipairs({1, 2, x = 5}) do result = result + v end":                                                float64(3),
+		"local function fact(n) if n <= 1 then return 1 end return n * fact(n - 1) end result = fact(5)":                         float64(120),
+		"local function counter() local n = 0 return function() n = n + 1 return n end end local c = counter() c() result = c()": float64(2),
+		"local m = {} function m.double(x) return x * 2 end result = m.double(4)":                                                float64(8),
+		"local x = 1 do local x = 2 end result = x":                                                                              float64(1),
+		"if false then result = 1 elseif nil then result = 2 else result = 3 end":                                                float64(3),
+		"result = string.upper 'abc'":                                                                                            "ABC",
+		"result = string.trim('  a b  ') .. string.lower('C')":                                                                   "a bc",
+		"result = #string.split('a,b,c', ',')":                                                                                   float64(3),
+		"result = string.match('Lawsuit 123-45', '(\\\\d+)-')":                                                                   "123",
+		"result = string.match('abc', '\\\\d')":                                                                                  nil,
+		"result = string.sub('goSpider', 3) .. string.sub('goSpider', 1, 2) .. string.sub('goSpider', -2)":                       "Spidergoer",
+		"result = string.replace('a.b.c', '.', '/')":                                                                             "a/b/c",
+		"result = string.contains('abc', 'b')":                                                                                   true,
+		"result = tonumber(' 42 ') + tonumber('0x10')":                                                                           float64(58),
+		"result = tonumber('x')":                                                                                                 nil,
+		"result = tostring(1.5) .. tostring(nil) .. tostring(true)":                                                              "1.5niltrue",
+		"result = type({}) .. type(tostring) .. type(1) .. type('')":                                                             "tablefunctionnumberstring",
+		"result = 10 / 4": 2.5,
+	}
+	for source, expected := range tests {
+		result, err := eval(t, source)
+		if err != nil {
+			t.Errorf("Unexpected error in %q: %v", source, err)
+			continue
+		}
+		if result != expected {
+			t.Errorf("Expected %q to set result to %v, got %v", source, expected, result)
+		}
+	}
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	tests := map[string]string{
+		"result = nil + 1":                      "attempt to perform arithmetic on a nil value",
+		"result = {} .. 'a'":                    "attempt to concatenate a table value",
+		"local t = nil result = t.x":            "attempt to index a nil value (variable 't')",
+		"missing()":                             "attempt to call a nil value (variable 'missing')",
+		"local t = {} t.f()":                    "attempt to call a nil value (field 'f')",
+		"result = 1 < 'a'":                      "attempt to compare number with string",
+		"local t = {} t[nil] = 1":               "index is nil",
+		"local function f() return f() end f()": "stack overflow",
+		"\n\nerror('custom')":                   "test.lua:3: custom",
+		"for i = 1, 10, 0 do end":               "'for' step is zero",
+		"while true do end":                     "exceeded 100000 steps",
+	}
+	for source, expected := range tests {
+		_, err := eval(t, source)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error containing %q in %q, got %v", expected, source, err)
+		}
+		var scriptErr *Error
+		if !errors.As(err, &scriptErr) {
+			t.Errorf("Expected a script error in %q, got %T", source, err)
+		}
+	}
+}
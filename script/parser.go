@@ -0,0 +1,824 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenNumber
+	tokenString
+	tokenKeyword
+	tokenSymbol
+)
+
+// token is a lexical token of a script.
+type token struct {
+	kind tokenKind
+	text string // the name, keyword, symbol or string value
+	num  float64
+	line int
+}
+
+var keywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true, "elseif": true, "end": true, "false": true, "for": true,
+	"function": true, "if": true, "in": true, "local": true, "nil": true, "not": true, "or": true, "return": true,
+	"then": true, "true": true, "while": true,
+}
+
+// symbols are the operators and punctuation, longest first.
+var symbols = []string{"..", "==", "~=", "<=", ">=", "+", "-", "*", "/", "%", "#", "(", ")", "{", "}", "[", "]", ",",
+	".", ";", "=", "<", ">"}
+
+// lex splits the source into tokens.
+func lex(source string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(source[i:], "--"):
+			i += 2
+			if strings.HasPrefix(source[i:], "[[") {
+				end := strings.Index(source[i:], "]]")
+				if end < 0 {
+					return nil, fmt.Errorf("line %d: unfinished long comment", line)
+				}
+				line += strings.Count(source[i:i+end], "\n")
+				i += end + 2
+				continue
+			}
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			text, n, err := lexString(source[i:], line)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, line: line})
+			i += n
+		case strings.HasPrefix(source[i:], "[["):
+			end := strings.Index(source[i+2:], "]]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unfinished long string", line)
+			}
+			text := source[i+2 : i+2+end]
+			// a newline right after the opening brackets is skipped, as in Lua
+			text = strings.TrimPrefix(strings.TrimPrefix(text, "\r"), "\n")
+			tokens = append(tokens, token{kind: tokenString, text: text, line: line})
+			line += strings.Count(source[i:i+2+end], "\n")
+			i += end + 4
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(source) && source[i+1] >= '0' && source[i+1] <= '9':
+			j := i
+			for j < len(source) && (isNameChar(source[j]) || source[j] == '.' ||
+				(source[j] == '-' || source[j] == '+') && (source[j-1] == 'e' || source[j-1] == 'E')) {
+				j++
+			}
+			num, err := parseNumber(source[i:j])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: malformed number %s", line, source[i:j])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, num: num, text: source[i:j], line: line})
+			i = j
+		case isNameChar(c):
+			j := i
+			for j < len(source) && isNameChar(source[j]) {
+				j++
+			}
+			kind := tokenName
+			if keywords[source[i:j]] {
+				kind = tokenKeyword
+			}
+			tokens = append(tokens, token{kind: kind, text: source[i:j], line: line})
+			i = j
+		default:
+			symbol := ""
+			for _, s := range symbols {
+				if strings.HasPrefix(source[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, line: line})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+// isNameChar reports whether c can be part of a name.
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseNumber parses a decimal or hexadecimal number.
+func parseNumber(text string) (float64, error) {
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		n, err := strconv.ParseUint(text[2:], 16, 64)
+		return float64(n), err
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// lexString reads the quoted string at the start of source and returns its value and length.
+func lexString(source string, line int) (string, int, error) {
+	quote := source[0]
+	var b strings.Builder
+	for i := 1; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\n':
+			return "", 0, fmt.Errorf("line %d: unfinished string", line)
+		case c == '\\' && i+1 < len(source):
+			i++
+			switch source[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '"', '\'':
+				b.WriteByte(source[i])
+			default:
+				return "", 0, fmt.Errorf("line %d: invalid escape sequence \\%c", line, source[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("line %d: unfinished string", line)
+}
+
+// The nodes of the syntax tree.
+type (
+	expr interface{}
+	stmt interface{}
+
+	block struct {
+		stmts []stmt
+	}
+
+	constExpr struct {
+		value value
+	}
+	nameExpr struct {
+		name string
+		line int
+	}
+	indexExpr struct {
+		object, key expr
+		line        int
+	}
+	callExpr struct {
+		fn   expr
+		args []expr
+		line int
+	}
+	functionExpr struct {
+		params []string
+		body   *block
+	}
+	tableExpr struct {
+		items        []expr // the values without keys, in order
+		keys, values []expr
+		line         int
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+		line        int
+	}
+	unaryExpr struct {
+		op      string
+		operand expr
+		line    int
+	}
+
+	localStmt struct {
+		names  []string
+		values []expr
+		line   int
+	}
+	localFunctionStmt struct {
+		name string
+		fn   *functionExpr
+		line int
+	}
+	assignStmt struct {
+		targets []expr // nameExpr or indexExpr
+		values  []expr
+		line    int
+	}
+	callStmt struct {
+		call *callExpr
+	}
+	ifStmt struct {
+		conds  []expr
+		blocks []*block
+		orElse *block
+		line   int
+	}
+	whileStmt struct {
+		cond expr
+		body *block
+		line int
+	}
+	numericForStmt struct {
+		name              string
+		start, stop, step expr
+		body              *block
+		line              int
+	}
+	genericForStmt struct {
+		key, value string
+		iterable   expr
+		body       *block
+		line       int
+	}
+	returnStmt struct {
+		value expr
+		line  int
+	}
+	breakStmt struct {
+		line int
+	}
+	doStmt struct {
+		body *block
+	}
+)
+
+// maxNesting is the maximum nesting of expressions and blocks, so deeply nested scripts fail to parse instead of
+// overflowing the stack.
+const maxNesting = 200
+
+// parser builds the syntax tree of the tokens.
+type parser struct {
+	tokens []token
+	i      int
+	depth  int // nesting of the expressions and blocks being parsed
+}
+
+// parse parses the source of a script.
+func parse(source string) (*block, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return body, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// is reports whether the current token is the keyword or symbol text.
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokenKeyword || t.kind == tokenSymbol) && t.text == text
+}
+
+// accept skips the keyword or symbol text if it is the current token.
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// expect skips the keyword or symbol text, failing if it isn't the current token.
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("line %d: expected '%s' near %s", p.peek().line, text, describe(p.peek()))
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokenName {
+		return "", fmt.Errorf("line %d: expected a name near %s", t.line, describe(t))
+	}
+	p.i++
+	return t.text, nil
+}
+
+func (p *parser) unexpected() error {
+	return fmt.Errorf("line %d: unexpected %s", p.peek().line, describe(p.peek()))
+}
+
+// describe describes a token in syntax errors.
+func describe(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of script"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+// enter counts a nested expression or block, failing past maxNesting; leave undoes it.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxNesting {
+		return fmt.Errorf("line %d: too many nested expressions or blocks", p.peek().line)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+// block parses statements until the end of a block.
+func (p *parser) block() (*block, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	b := &block{}
+	for {
+		if p.peek().kind == tokenEOF || p.is("end") || p.is("else") || p.is("elseif") {
+			return b, nil
+		}
+		if p.accept(";") {
+			continue
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		b.stmts = append(b.stmts, s)
+		if _, ok := s.(*returnStmt); ok {
+			// return is the last statement of its block
+			p.accept(";")
+			if !(p.peek().kind == tokenEOF || p.is("end") || p.is("else") || p.is("elseif")) {
+				return nil, fmt.Errorf("line %d: return must be the last statement of its block", p.peek().line)
+			}
+			return b, nil
+		}
+	}
+}
+
+func (p *parser) statement() (stmt, error) {
+	line := p.peek().line
+	switch {
+	case p.accept("if"):
+		s := &ifStmt{line: line}
+		for {
+			cond, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("then"); err != nil {
+				return nil, err
+			}
+			body, err := p.block()
+			if err != nil {
+				return nil, err
+			}
+			s.conds, s.blocks = append(s.conds, cond), append(s.blocks, body)
+			if !p.accept("elseif") {
+				break
+			}
+		}
+		if p.accept("else") {
+			body, err := p.block()
+			if err != nil {
+				return nil, err
+			}
+			s.orElse = body
+		}
+		return s, p.expect("end")
+	case p.accept("while"):
+		cond, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.doBlock()
+		if err != nil {
+			return nil, err
+		}
+		return &whileStmt{cond: cond, body: body, line: line}, nil
+	case p.accept("for"):
+		return p.forStatement(line)
+	case p.accept("do"):
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &doStmt{body: body}, p.expect("end")
+	case p.accept("function"):
+		var target expr
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		target = &nameExpr{name: name, line: line}
+		for p.accept(".") {
+			key, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			target = &indexExpr{object: target, key: &constExpr{value: key}, line: line}
+		}
+		fn, err := p.functionBody()
+		if err != nil {
+			return nil, err
+		}
+		return &assignStmt{targets: []expr{target}, values: []expr{fn}, line: line}, nil
+	case p.accept("local"):
+		if p.accept("function") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			fn, err := p.functionBody()
+			if err != nil {
+				return nil, err
+			}
+			return &localFunctionStmt{name: name, fn: fn, line: line}, nil
+		}
+		s := &localStmt{line: line}
+		for {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			s.names = append(s.names, name)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if p.accept("=") {
+			values, err := p.exprList()
+			if err != nil {
+				return nil, err
+			}
+			s.values = values
+		}
+		return s, nil
+	case p.accept("return"):
+		s := &returnStmt{line: line}
+		if !(p.peek().kind == tokenEOF || p.is("end") || p.is("else") || p.is("elseif") || p.is(";")) {
+			value, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			s.value = value
+		}
+		return s, nil
+	case p.accept("break"):
+		return &breakStmt{line: line}, nil
+	}
+
+	e, err := p.suffixedExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.is("=") || p.is(",") {
+		s := &assignStmt{targets: []expr{e}, line: line}
+		for p.accept(",") {
+			target, err := p.suffixedExpr()
+			if err != nil {
+				return nil, err
+			}
+			s.targets = append(s.targets, target)
+		}
+		for _, target := range s.targets {
+			switch target.(type) {
+			case *nameExpr, *indexExpr:
+			default:
+				return nil, fmt.Errorf("line %d: cannot assign to this expression", line)
+			}
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		s.values, err = p.exprList()
+		return s, err
+	}
+	call, ok := e.(*callExpr)
+	if !ok {
+		return nil, fmt.Errorf("line %d: syntax error, expected a statement", line)
+	}
+	return &callStmt{call: call}, nil
+}
+
+// forStatement parses a numeric for, "for i = 1, 10 do", or a generic for, "for k, v in t do".
+func (p *parser) forStatement(line int) (stmt, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("=") {
+		s := &numericForStmt{name: name, line: line}
+		if s.start, err = p.expr(0); err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if s.stop, err = p.expr(0); err != nil {
+			return nil, err
+		}
+		if p.accept(",") {
+			if s.step, err = p.expr(0); err != nil {
+				return nil, err
+			}
+		}
+		s.body, err = p.doBlock()
+		return s, err
+	}
+
+	s := &genericForStmt{key: name, line: line}
+	if p.accept(",") {
+		if s.value, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("in"); err != nil {
+		return nil, err
+	}
+	if s.iterable, err = p.expr(0); err != nil {
+		return nil, err
+	}
+	s.body, err = p.doBlock()
+	return s, err
+}
+
+// doBlock parses "do ... end".
+func (p *parser) doBlock() (*block, error) {
+	if err := p.expect("do"); err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	return body, p.expect("end")
+}
+
+// functionBody parses the parameters and the body of a function.
+func (p *parser) functionBody() (*functionExpr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	fn := &functionExpr{}
+	if !p.is(")") {
+		for {
+			param, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			fn.params = append(fn.params, param)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	fn.body = body
+	return fn, p.expect("end")
+}
+
+func (p *parser) exprList() ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(",") {
+			return list, nil
+		}
+	}
+}
+
+// binaryPriority is the left and right priority of the binary operators; ".." is right associative.
+var binaryPriority = map[string][2]int{
+	"or": {1, 1}, "and": {2, 2},
+	"<": {3, 3}, ">": {3, 3}, "<=": {3, 3}, ">=": {3, 3}, "~=": {3, 3}, "==": {3, 3},
+	"..": {9, 8}, "+": {10, 10}, "-": {10, 10}, "*": {11, 11}, "/": {11, 11}, "%": {11, 11},
+}
+
+// unaryPriority is the priority of "not", "#" and unary "-".
+const unaryPriority = 12
+
+// expr parses an expression whose binary operators have a priority higher than limit.
+func (p *parser) expr(limit int) (expr, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	var left expr
+	line := p.peek().line
+	if p.is("not") || p.is("#") || p.is("-") {
+		op := p.next().text
+		operand, err := p.expr(unaryPriority)
+		if err != nil {
+			return nil, err
+		}
+		left = &unaryExpr{op: op, operand: operand, line: line}
+	} else {
+		var err error
+		left, err = p.simpleExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		t := p.peek()
+		priority, ok := binaryPriority[t.text]
+		if !ok || (t.kind != tokenSymbol && t.kind != tokenKeyword) || priority[0] <= limit {
+			return left, nil
+		}
+		p.next()
+		right, err := p.expr(priority[1])
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: t.text, left: left, right: right, line: t.line}
+	}
+}
+
+func (p *parser) simpleExpr() (expr, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenNumber:
+		p.next()
+		return &constExpr{value: t.num}, nil
+	case t.kind == tokenString:
+		p.next()
+		return &constExpr{value: t.text}, nil
+	case p.accept("nil"):
+		return &constExpr{value: nil}, nil
+	case p.accept("true"):
+		return &constExpr{value: true}, nil
+	case p.accept("false"):
+		return &constExpr{value: false}, nil
+	case p.accept("function"):
+		return p.functionBody()
+	case p.is("{"):
+		return p.table()
+	}
+	return p.suffixedExpr()
+}
+
+// suffixedExpr parses a name or parenthesized expression followed by fields, indexes and calls.
+func (p *parser) suffixedExpr() (expr, error) {
+	var e expr
+	t := p.peek()
+	switch {
+	case t.kind == tokenName:
+		p.next()
+		e = &nameExpr{name: t.text, line: t.line}
+	case p.accept("("):
+		inner, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		e = inner
+	default:
+		return nil, p.unexpected()
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case p.accept("."):
+			key, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			e = &indexExpr{object: e, key: &constExpr{value: key}, line: t.line}
+		case p.accept("["):
+			key, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			e = &indexExpr{object: e, key: key, line: t.line}
+		case p.accept("("):
+			call := &callExpr{fn: e, line: t.line}
+			if !p.is(")") {
+				args, err := p.exprList()
+				if err != nil {
+					return nil, err
+				}
+				call.args = args
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			e = call
+		case t.kind == tokenString:
+			// f "text" calls f with a string, as in Lua
+			p.next()
+			e = &callExpr{fn: e, args: []expr{&constExpr{value: t.text}}, line: t.line}
+		case p.is("{"):
+			table, err := p.table()
+			if err != nil {
+				return nil, err
+			}
+			e = &callExpr{fn: e, args: []expr{table}, line: t.line}
+		default:
+			return e, nil
+		}
+	}
+}
+
+// table parses a table constructor: {1, 2, name = value, [key] = value}.
+func (p *parser) table() (expr, error) {
+	t := &tableExpr{line: p.peek().line}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !p.is("}") {
+		switch {
+		case p.is("["):
+			p.next()
+			key, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			t.keys, t.values = append(t.keys, key), append(t.values, value)
+		case p.peek().kind == tokenName && p.tokens[p.i+1].kind == tokenSymbol && p.tokens[p.i+1].text == "=":
+			key := p.next().text
+			p.next()
+			value, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			t.keys, t.values = append(t.keys, &constExpr{value: key}), append(t.values, value)
+		default:
+			item, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			t.items = append(t.items, item)
+		}
+		if !p.accept(",") && !p.accept(";") {
+			break
+		}
+	}
+	return t, p.expect("}")
+}
@@ -0,0 +1,72 @@
+package script
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"open(":                           "line 1",
+		"x = 'unfinished":                 "unfinished string",
+		"\nx = [[unfinished":              "line 2: unfinished long string",
+		"--[[ unfinished":                 "unfinished long comment",
+		"x = 1 +":                         "line 1",
+		"if x then":                       "expected 'end'",
+		"x = 1 @ 2":                       "unexpected character '@'",
+		"x = 0x":                          "malformed number",
+		"1 = x":                           "unexpected '1'",
+		"f() = 1":                         "cannot assign to this expression",
+		"return 1 x = 2":                  "return must be the last statement",
+		"for i = 1 do end":                "line 1",
+		"local function () end":           "line 1",
+		"t = {a = }":                      "line 1",
+		"x = 1\ny = 2\nwhile true do\n z": "line 4",
+	}
+	for source, expected := range tests {
+		_, err := parse(source)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error containing %q for %q, got %v", expected, source, err)
+		}
+	}
+}
+
+func TestParseValid(t *testing.T) {
+	sources := []string{
+		"",
+		";;",
+		"-- only a comment",
+		"local t = {1, 2; 3,}",
+		"f{a = 1} f'x' f[[y]]",
+		"a.b.c = 1",
+		"return",
+		"if a then elseif b then else end",
+		"for k, v in t do end",
+		"local f = function(a, b) end",
+	}
+	for _, source := range sources {
+		if _, err := parse(source); err != nil {
+			t.Errorf("Unexpected error for %q: %v", source, err)
+		}
+	}
+}
+
+func TestParseNesting(t *testing.T) {
+	tests := map[string]string{
+		"return " + strings.Repeat("(", 2000000) + "1" + strings.Repeat(")", 2000000): "too many nested",
+		"return " + strings.Repeat("not ", 1000) + "1":                                "too many nested",
+		strings.Repeat("do ", 1000) + strings.Repeat("end ", 1000):                    "too many nested",
+		"x = " + strings.Repeat("{", 1000) + strings.Repeat("}", 1000):                "too many nested",
+	}
+	for source, expected := range tests {
+		_, err := parse(source)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error containing %q for %.20q, got %v", expected, source, err)
+		}
+	}
+
+	nested := "return " + strings.Repeat("(", 50) + "1" + strings.Repeat(")", 50)
+	if _, err := parse(nested); err != nil {
+		t.Errorf("Unexpected error for %d nested parentheses: %v", 50, err)
+	}
+}
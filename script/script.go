@@ -0,0 +1,678 @@
+/*
+Package script runs scrape scripts written in a small subset of Lua, bound to the methods of a Navigator, so a new
+site can be scraped from the gospider command or uploaded to its HTTP server without recompiling Go.
+
+	-- search.lua
+	open("https://esaj.tjsp.jus.br/cpopg/open.do")
+	fill("#numeroDigitoAnoUnificado", number)
+	click("#botaoConsultarProcessos")
+	if exists("#mensagemRetorno") then
+	  error("lawsuit not found: " .. number)
+	end
+	wait("#tabelaTodasMovimentacoes", "10s")
+
+	local cover = extract({class = "//*[@id='classeProcesso']", judge = "//*[@id='juizProcesso']"})
+	while true do
+	  for _, movement in ipairs(extract({date = "./td[1]", title = "./td[3]"}, "//*[@id='tabelaTodasMovimentacoes']/tr")) do
+	    movement.class = cover.class
+	    emit(movement)
+	  end
+	  if not exists("#nextPage") then break end
+	  click("#nextPage")
+	end
+
+The language has nil, booleans, numbers, strings, tables and functions; local and global variables; if, while,
+numeric and generic for (over the items of a table, then its other keys in sorted order), break and return; function
+definitions with closures; and the operators of Lua but "^" and "//". There are no ":" method calls nor multiple return
+values. The variables given to Run are globals, shadowing the functions of the same name.
+
+The Navigator functions are open, click, fill, select, wait, sleep, exists, count, text, attr, url, eval and login;
+extract and texts read the page source with xpaths; emit outputs a record; env reads the environment when the Runner
+allows it; and print, tostring, tonumber, type, pairs, ipairs, error, string.lower, string.upper, string.trim,
+string.split, string.contains, string.replace, string.match, string.sub, table.insert, table.remove and table.concat
+help with the data. See Runner.Run for their arguments.
+*/
+package script
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Script is a parsed script.
+type Script struct {
+	Name string // used in errors, as in "search.lua:12: attempt to call a nil value"
+	body *block
+}
+
+// Parse parses the source of a script.
+// Example:
+//
+//	s, err := script.Parse("search.lua", []byte(`open("https://example.com") emit({title = text("h1")})`))
+func Parse(name string, source []byte) (*Script, error) {
+	body, err := parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("error - invalid script %s: %w", name, err)
+	}
+	return &Script{Name: name, body: body}, nil
+}
+
+// Load reads and parses a script file.
+// Example:
+//
+//	s, err := script.Load("search.lua")
+func Load(path string) (*Script, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read script: %w", err)
+	}
+	return Parse(filepath.Base(path), source)
+}
+
+// Runner runs scripts and delivers the records they emit.
+type Runner struct {
+	Sinks  map[string]goSpider.Sink // sinks of emit(record, "name")
+	Output io.Writer                // JSON lines of the records emitted without a sink; discarded if nil
+	// Getenv reads the environment variables for env(name), which fails when it is nil, as scripts sent by others
+	// shouldn't read the environment.
+	Getenv func(name string) string
+	// MaxSteps is the number of statements and calls a run may execute, and Timeout how long it may take; both
+	// unlimited if not positive. They stop runaway scripts sent by others.
+	MaxSteps int
+	Timeout  time.Duration
+}
+
+// Result is the outcome of a script run.
+type Result struct {
+	Records []goSpider.Record // every record emitted
+	Steps   int               // statements and calls executed
+}
+
+// Run runs the script in nav, with vars as global variables. It stops at the first error, which is an *Error with the
+// line of the script, and returns the result so far with it.
+//
+// The functions of the script are:
+//   - open(url), click(selector), fill(selector, value), select(selector, value): see the Navigator methods
+//   - wait(selector [, timeout]): waits for the element, up to the timeout of nav or timeout, as "10s" or seconds
+//   - sleep(duration): waits the duration, as "500ms" or seconds
+//   - exists(selector), count(selector): whether elements match, and how many, without waiting
+//   - text(selector), attr(selector, attribute): the text or an attribute of an element
+//   - url(): the current URL
+//   - eval(javascript): the result of the script in the page
+//   - login(url, username, password, usernameSelector, passwordSelector, submitSelector [, failureMessage])
+//   - extract(fields [, rows]): a table of the texts of the xpaths of fields in the page, or a list of such tables,
+//     one per node of the rows xpath, the fields being relative to the rows
+//   - texts(xpath): a list of the texts of the nodes of the xpath
+//   - emit(record [, sink]): outputs a record table to the sink or to the output of the runner
+//   - env(name): an environment variable
+//   - print(...): writes to the logger of nav
+//
+// Example:
+//
+//	s, err := script.Load("search.lua")
+//	runner := &script.Runner{Output: os.Stdout, Getenv: os.Getenv}
+//	result, err := runner.Run(nav, s, map[string]string{"number": "1017927-35.2023.8.26.0008"})
+func (r *Runner) Run(nav goSpider.Browser, s *Script, vars map[string]string) (*Result, error) {
+	in := &interpreter{name: s.Name, globals: newScope(nil), maxSteps: r.MaxSteps}
+	if r.Timeout > 0 {
+		in.deadline = time.Now().Add(r.Timeout)
+	}
+	result := &Result{}
+	r.bind(in, nav, result)
+	for name, value := range vars {
+		in.globals.vars[name] = value
+	}
+
+	_, _, err := in.exec(s.body, in.globals)
+	result.Steps = in.steps
+	if err != nil {
+		nav.GetLogger().Printf("Error - Script %s failed: %v\n", s.Name, err)
+		return result, err
+	}
+	return result, nil
+}
+
+// bind defines the functions of the scripts as globals.
+func (r *Runner) bind(in *interpreter, nav goSpider.Browser, result *Result) {
+	g := in.globals.vars
+
+	// navigation
+	g["open"] = builtin(func(args []value) (value, error) {
+		url, err := stringArg(args, 0, "open")
+		if err != nil {
+			return nil, err
+		}
+		return nil, nav.OpenURL(url)
+	})
+	g["click"] = builtin(func(args []value) (value, error) {
+		selector, err := stringArg(args, 0, "click")
+		if err != nil {
+			return nil, err
+		}
+		return nil, nav.ClickButton(selector)
+	})
+	g["fill"] = builtin(func(args []value) (value, error) {
+		selector, value, err := stringArgs2(args, "fill")
+		if err != nil {
+			return nil, err
+		}
+		return nil, nav.FillField(selector, value)
+	})
+	g["select"] = builtin(func(args []value) (value, error) {
+		selector, value, err := stringArgs2(args, "select")
+		if err != nil {
+			return nil, err
+		}
+		return nil, nav.SelectDropdown(selector, value)
+	})
+	g["wait"] = builtin(func(args []value) (value, error) {
+		selector, err := stringArg(args, 0, "wait")
+		if err != nil {
+			return nil, err
+		}
+		timeout := nav.GetTimeOut()
+		if len(args) > 1 {
+			timeout, err = durationArg(args, 1, "wait")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nav.WaitForElement(selector, timeout)
+	})
+	g["sleep"] = builtin(func(args []value) (value, error) {
+		d, err := durationArg(args, 0, "sleep")
+		if err != nil {
+			return nil, err
+		}
+		if !in.deadline.IsZero() && time.Now().Add(d).After(in.deadline) {
+			time.Sleep(time.Until(in.deadline))
+			return nil, errors.New("script timed out")
+		}
+		time.Sleep(d)
+		return nil, nil
+	})
+	g["exists"] = builtin(func(args []value) (value, error) {
+		selector, err := stringArg(args, 0, "exists")
+		if err != nil {
+			return nil, err
+		}
+		return nav.ElementExists(selector)
+	})
+	g["count"] = builtin(func(args []value) (value, error) {
+		selector, err := stringArg(args, 0, "count")
+		if err != nil {
+			return nil, err
+		}
+		n, err := nav.CountElements(selector)
+		return float64(n), err
+	})
+	g["text"] = builtin(func(args []value) (value, error) {
+		selector, err := stringArg(args, 0, "text")
+		if err != nil {
+			return nil, err
+		}
+		return nav.GetElement(selector)
+	})
+	g["attr"] = builtin(func(args []value) (value, error) {
+		selector, attribute, err := stringArgs2(args, "attr")
+		if err != nil {
+			return nil, err
+		}
+		return nav.GetElementAttribute(selector, attribute)
+	})
+	g["url"] = builtin(func(args []value) (value, error) {
+		return nav.GetCurrentURL()
+	})
+	g["eval"] = builtin(func(args []value) (value, error) {
+		javascript, err := stringArg(args, 0, "eval")
+		if err != nil {
+			return nil, err
+		}
+		v, err := nav.EvaluateScript(javascript)
+		if err != nil {
+			return nil, err
+		}
+		return fromJSON(v), nil
+	})
+	g["login"] = builtin(func(args []value) (value, error) {
+		var params [7]string
+		for i := range params {
+			if i == 6 && len(args) < 7 {
+				break
+			}
+			var err error
+			params[i], err = stringArg(args, i, "login")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nav.Login(params[0], params[1], params[2], params[3], params[4], params[5], params[6])
+	})
+
+	// page source
+	g["extract"] = builtin(func(args []value) (value, error) {
+		fields, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'extract' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		pageSource, err := nav.GetPageSource()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) < 2 {
+			return extractFields(pageSource, fields)
+		}
+		rows, err := stringArg(args, 1, "extract")
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := findNodes(pageSource, rows)
+		if err != nil {
+			return nil, err
+		}
+		list := listOf()
+		for _, node := range nodes {
+			record, err := extractFields(node, fields)
+			if err != nil {
+				return nil, err
+			}
+			list.array = append(list.array, record)
+		}
+		return list, nil
+	})
+	g["texts"] = builtin(func(args []value) (value, error) {
+		xpath, err := stringArg(args, 0, "texts")
+		if err != nil {
+			return nil, err
+		}
+		pageSource, err := nav.GetPageSource()
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := findNodes(pageSource, xpath)
+		if err != nil {
+			return nil, err
+		}
+		list := listOf()
+		for _, node := range nodes {
+			list.array = append(list.array, goSpider.NormalizedText(node))
+		}
+		return list, nil
+	})
+
+	// output
+	g["emit"] = builtin(func(args []value) (value, error) {
+		t, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'emit' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		record, err := toRecord(t)
+		if err != nil {
+			return nil, err
+		}
+		result.Records = append(result.Records, record)
+		if len(args) > 1 {
+			name, err := stringArg(args, 1, "emit")
+			if err != nil {
+				return nil, err
+			}
+			sink, ok := r.Sinks[name]
+			if !ok {
+				return nil, fmt.Errorf("no sink %q", name)
+			}
+			return nil, sink.Write([]goSpider.Record{record})
+		}
+		if r.Output == nil {
+			return nil, nil
+		}
+		return nil, json.NewEncoder(r.Output).Encode(record)
+	})
+	g["env"] = builtin(func(args []value) (value, error) {
+		name, err := stringArg(args, 0, "env")
+		if err != nil {
+			return nil, err
+		}
+		if r.Getenv == nil {
+			return nil, fmt.Errorf("environment variable %q not allowed", name)
+		}
+		return r.Getenv(name), nil
+	})
+	g["print"] = builtin(func(args []value) (value, error) {
+		texts := make([]string, len(args))
+		for i, a := range args {
+			texts[i] = toString(a)
+		}
+		nav.GetLogger().Printf("Script %s: %s\n", in.name, strings.Join(texts, "\t"))
+		return nil, nil
+	})
+
+	bindLibrary(g)
+}
+
+// bindLibrary defines the language functions as globals.
+func bindLibrary(g map[string]value) {
+	g["tostring"] = builtin(func(args []value) (value, error) {
+		return toString(arg(args, 0)), nil
+	})
+	g["tonumber"] = builtin(func(args []value) (value, error) {
+		switch v := arg(args, 0).(type) {
+		case float64:
+			return v, nil
+		case string:
+			if n, err := parseNumber(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+		}
+		return nil, nil
+	})
+	g["type"] = builtin(func(args []value) (value, error) {
+		return typeName(arg(args, 0)), nil
+	})
+	g["pairs"] = builtin(func(args []value) (value, error) {
+		t, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'pairs' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		return t, nil
+	})
+	g["ipairs"] = builtin(func(args []value) (value, error) {
+		t, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'ipairs' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		// the list items only
+		return listOf(append([]value(nil), t.array...)...), nil
+	})
+	g["error"] = builtin(func(args []value) (value, error) {
+		return nil, errors.New(toString(arg(args, 0)))
+	})
+
+	strs := newTable()
+	strs.hash["lower"] = stringFunc("lower", strings.ToLower)
+	strs.hash["upper"] = stringFunc("upper", strings.ToUpper)
+	strs.hash["trim"] = stringFunc("trim", strings.TrimSpace)
+	strs.hash["split"] = builtin(func(args []value) (value, error) {
+		s, sep, err := stringArgs2(args, "split")
+		if err != nil {
+			return nil, err
+		}
+		list := listOf()
+		for _, part := range strings.Split(s, sep) {
+			list.array = append(list.array, part)
+		}
+		return list, nil
+	})
+	strs.hash["contains"] = builtin(func(args []value) (value, error) {
+		s, substr, err := stringArgs2(args, "contains")
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(s, substr), nil
+	})
+	strs.hash["replace"] = builtin(func(args []value) (value, error) {
+		s, old, err := stringArgs2(args, "replace")
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := stringArg(args, 2, "replace")
+		if err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(s, old, replacement), nil
+	})
+	strs.hash["match"] = builtin(func(args []value) (value, error) {
+		s, pattern, err := stringArgs2(args, "match")
+		if err != nil {
+			return nil, err
+		}
+		// Go regular expressions rather than Lua patterns
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		match := re.FindStringSubmatch(s)
+		switch {
+		case match == nil:
+			return nil, nil
+		case len(match) > 1:
+			return match[1], nil
+		}
+		return match[0], nil
+	})
+	strs.hash["sub"] = builtin(func(args []value) (value, error) {
+		s, err := stringArg(args, 0, "sub")
+		if err != nil {
+			return nil, err
+		}
+		start, end := 1.0, -1.0
+		if n, ok := arg(args, 1).(float64); ok {
+			start = n
+		}
+		if n, ok := arg(args, 2).(float64); ok {
+			end = n
+		}
+		// negative positions count from the end, as in Lua
+		i, j := int(start), int(end)
+		if i < 0 {
+			i += len(s) + 1
+		}
+		if j < 0 {
+			j += len(s) + 1
+		}
+		if i < 1 {
+			i = 1
+		}
+		if j > len(s) {
+			j = len(s)
+		}
+		if i > j {
+			return "", nil
+		}
+		return s[i-1 : j], nil
+	})
+	g["string"] = strs
+
+	tables := newTable()
+	tables.hash["insert"] = builtin(func(args []value) (value, error) {
+		t, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'insert' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		return nil, t.set(float64(len(t.array)+1), arg(args, 1))
+	})
+	tables.hash["remove"] = builtin(func(args []value) (value, error) {
+		t, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'remove' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		if len(t.array) == 0 {
+			return nil, nil
+		}
+		last := t.array[len(t.array)-1]
+		t.array = t.array[:len(t.array)-1]
+		return last, nil
+	})
+	tables.hash["concat"] = builtin(func(args []value) (value, error) {
+		t, ok := arg(args, 0).(*table)
+		if !ok {
+			return nil, fmt.Errorf("bad argument #1 to 'concat' (table expected, got %s)", typeName(arg(args, 0)))
+		}
+		sep, _ := arg(args, 1).(string)
+		texts := make([]string, len(t.array))
+		for i, item := range t.array {
+			text, ok := concatenable(item)
+			if !ok {
+				return nil, fmt.Errorf("invalid value (a %s) at index %d in table for 'concat'", typeName(item), i+1)
+			}
+			texts[i] = text
+		}
+		return strings.Join(texts, sep), nil
+	})
+	g["table"] = tables
+}
+
+// arg returns the argument i, nil if missing.
+func arg(args []value, i int) value {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+// stringArg returns the argument i of the function name as a string; numbers are converted.
+func stringArg(args []value, i int, name string) (string, error) {
+	switch v := arg(args, i).(type) {
+	case string:
+		return v, nil
+	case float64:
+		return formatNumber(v), nil
+	}
+	return "", fmt.Errorf("bad argument #%d to '%s' (string expected, got %s)", i+1, name, typeName(arg(args, i)))
+}
+
+// stringArgs2 returns the first two arguments of the function name as strings.
+func stringArgs2(args []value, name string) (string, string, error) {
+	a, err := stringArg(args, 0, name)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := stringArg(args, 1, name)
+	return a, b, err
+}
+
+// durationArg returns the argument i of the function name as a duration: a Go duration string or seconds.
+func durationArg(args []value, i int, name string) (time.Duration, error) {
+	switch v := arg(args, i).(type) {
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d, nil
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+	}
+	return 0, fmt.Errorf("bad argument #%d to '%s' (duration expected, got %s)", i+1, name, toString(arg(args, i)))
+}
+
+// stringFunc adapts a string function.
+func stringFunc(name string, f func(string) string) builtin {
+	return func(args []value) (value, error) {
+		s, err := stringArg(args, 0, name)
+		if err != nil {
+			return nil, err
+		}
+		return f(s), nil
+	}
+}
+
+// findNodes returns the nodes of the xpath, none when it doesn't match.
+func findNodes(node *html.Node, xpath string) ([]*html.Node, error) {
+	nodes, err := goSpider.FindNodes(node, xpath)
+	if errors.Is(err, goSpider.ErrElementNotFound) {
+		return nil, nil
+	}
+	return nodes, err
+}
+
+// extractFields returns a table of the texts of the first node of each field xpath, relative to node; an empty string
+// when the xpath doesn't match.
+func extractFields(node *html.Node, fields *table) (*table, error) {
+	record := newTable()
+	names, xpaths := fields.entries()
+	for i, name := range names {
+		xpath, ok := xpaths[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("field %s: xpath expected, got %s", toString(name), typeName(xpaths[i]))
+		}
+		text, err := goSpider.ExtractText(node, xpath, "")
+		if err != nil && !errors.Is(err, goSpider.ErrElementNotFound) {
+			return nil, fmt.Errorf("field %s: %w", toString(name), err)
+		}
+		if err := record.set(name, strings.Join(strings.Fields(text), " ")); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// toRecord converts a table to a Record: its string keys with their values as JSON.
+func toRecord(t *table) (goSpider.Record, error) {
+	record := make(goSpider.Record, len(t.hash))
+	for key, v := range t.hash {
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("record keys must be strings, got %s", typeName(key))
+		}
+		value, err := toJSON(v, 0)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		record[name] = value
+	}
+	return record, nil
+}
+
+// toJSON converts a value to its JSON types: tables with list items only become lists.
+func toJSON(v value, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("table nested too deep, or a cycle")
+	}
+	switch v := v.(type) {
+	case nil, bool, float64, string:
+		return v, nil
+	case *table:
+		if len(v.hash) == 0 {
+			list := make([]interface{}, len(v.array))
+			for i, item := range v.array {
+				var err error
+				if list[i], err = toJSON(item, depth+1); err != nil {
+					return nil, err
+				}
+			}
+			return list, nil
+		}
+		object := make(map[string]interface{}, len(v.array)+len(v.hash))
+		keys, values := v.entries()
+		for i, key := range keys {
+			var err error
+			if object[toString(key)], err = toJSON(values[i], depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+	return nil, fmt.Errorf("cannot convert a %s to JSON", typeName(v))
+}
+
+// fromJSON converts a JSON value to a script value.
+func fromJSON(v interface{}) value {
+	switch v := v.(type) {
+	case bool, float64, string:
+		return v
+	case []interface{}:
+		list := listOf()
+		for _, item := range v {
+			list.array = append(list.array, fromJSON(item))
+		}
+		return list
+	case map[string]interface{}:
+		t := newTable()
+		for key, item := range v {
+			t.set(key, fromJSON(item))
+		}
+		return t
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanielFillol/goSpider"
+	"golang.org/x/net/html"
+)
+
+// siteBrowser is a Browser over HTML pages by URL. Clicking an element with a data-href attribute opens its page and
+// selectors are xpaths.
+type siteBrowser struct {
+	goSpider.Browser
+	pages  map[string]string
+	page   string
+	filled map[string]string
+	logs   bytes.Buffer
+}
+
+func (b *siteBrowser) GetLogger() *log.Logger    { return log.New(&b.logs, "", 0) }
+func (b *siteBrowser) GetTimeOut() time.Duration { return time.Millisecond }
+func (b *siteBrowser) GetCurrentURL() (string, error) {
+	return b.page, nil
+}
+func (b *siteBrowser) GetPageSource() (*html.Node, error) {
+	return html.Parse(strings.NewReader(b.pages[b.page]))
+}
+
+func (b *siteBrowser) OpenURL(url string) error {
+	if _, ok := b.pages[url]; !ok {
+		return errors.New("net::ERR_NAME_NOT_RESOLVED")
+	}
+	b.page = url
+	return nil
+}
+
+func (b *siteBrowser) find(selector string) ([]*html.Node, error) {
+	pageSource, _ := b.GetPageSource()
+	nodes, err := goSpider.FindNodes(pageSource, selector)
+	if errors.Is(err, goSpider.ErrElementNotFound) {
+		return nil, nil
+	}
+	return nodes, err
+}
+
+func (b *siteBrowser) ElementExists(selector string) (bool, error) {
+	nodes, err := b.find(selector)
+	return len(nodes) > 0, err
+}
+
+func (b *siteBrowser) CountElements(selector string) (int, error) {
+	nodes, err := b.find(selector)
+	return len(nodes), err
+}
+
+func (b *siteBrowser) GetElement(selector string) (string, error) {
+	nodes, err := b.find(selector)
+	if err != nil || len(nodes) == 0 {
+		return "", goSpider.ErrElementNotFound
+	}
+	return goSpider.NormalizedText(nodes[0]), nil
+}
+
+func (b *siteBrowser) WaitForElement(selector string, timeout time.Duration) error {
+	nodes, err := b.find(selector)
+	if err == nil && len(nodes) == 0 {
+		err = goSpider.ErrTimeout
+	}
+	return err
+}
+
+func (b *siteBrowser) FillField(selector, value string) error {
+	b.filled[selector] = value
+	return nil
+}
+
+func (b *siteBrowser) ClickButton(selector string) error {
+	nodes, err := b.find(selector)
+	if err != nil || len(nodes) == 0 {
+		return goSpider.ErrElementNotFound
+	}
+	for _, attr := range nodes[0].Attr {
+		if attr.Key == "data-href" {
+			return b.OpenURL(attr.Val)
+		}
+	}
+	return nil
+}
+
+func (b *siteBrowser) EvaluateScript(script string) (interface{}, error) {
+	return map[string]interface{}{"items": []interface{}{"a", "b"}, "total": float64(2)}, nil
+}
+
+func newSiteBrowser() *siteBrowser {
+	return &siteBrowser{filled: map[string]string{}, pages: map[string]string{
+		"https://example.com/search": `<h1>Search</h1><input id="number"><button id="go" data-href="https://example.com/results?page=1">Go</button>`,
+		"https://example.com/results?page=1": `<h1 id="title">Lawsuit 123</h1>
+			<table><tr><td>01/02</td><td>Filed</td></tr><tr><td>03/04</td><td>Decided</td></tr></table>
+			<a id="next" data-href="https://example.com/results?page=2">Next</a>`,
+		"https://example.com/results?page=2": `<h1 id="title">Lawsuit 123</h1>
+			<table><tr><td>05/06</td><td>Archived</td></tr></table>`,
+	}}
+}
+
+const searchScript = `
+-- search a lawsuit and emit its movements
+open("https://example.com/search")
+fill("//*[@id='number']", number)
+click("//*[@id='go']")
+wait("//table", "1s")
+
+local cover = extract({title = "//*[@id='title']"})
+emit(cover, "cover")
+while true do
+  for _, movement in ipairs(extract({date = "./td[1]", text = "./td[2]"}, "//tr")) do
+    movement.page = url()
+    emit(movement)
+  end
+  if not exists("//*[@id='next']") then break end
+  click("//*[@id='next']")
+end
+print("movements of", cover.title, count("//tr"))
+`
+
+func TestRun(t *testing.T) {
+	s, err := Parse("search.lua", []byte(searchScript))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	nav := newSiteBrowser()
+	var covers []goSpider.Record
+	var output bytes.Buffer
+	runner := &Runner{Output: &output, Sinks: map[string]goSpider.Sink{
+		"cover": goSpider.SinkFunc(func(records []goSpider.Record) error {
+			covers = append(covers, records...)
+			return nil
+		}),
+	}}
+	result, err := runner.Run(nav, s, map[string]string{"number": "1017927-35.2023.8.26.0008"})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if nav.filled["//*[@id='number']"] != "1017927-35.2023.8.26.0008" {
+		t.Errorf("Expected the number variable to be filled, got %v", nav.filled)
+	}
+	if len(covers) != 1 || covers[0]["title"] != "Lawsuit 123" {
+		t.Errorf("Expected the cover record in its sink, got %v", covers)
+	}
+	if len(result.Records) != 4 {
+		t.Fatalf("Expected 4 records, got %d", len(result.Records))
+	}
+	expected := `{"date":"01/02","page":"https://example.com/results?page=1","text":"Filed"}
+{"date":"03/04","page":"https://example.com/results?page=1","text":"Decided"}
+{"date":"05/06","page":"https://example.com/results?page=2","text":"Archived"}
+`
+	if output.String() != expected {
+		t.Errorf("Unexpected output %q", output.String())
+	}
+	if nav.logs.String() != "Script search.lua: movements of\tLawsuit 123\t1\n" {
+		t.Errorf("Unexpected log %q", nav.logs.String())
+	}
+}
+
+func TestRunBindings(t *testing.T) {
+	s, err := Parse("bindings.lua", []byte(`
+open("https://example.com/results?page=1")
+local result = eval("({items: ['a', 'b'], total: 2})")
+local dates = texts("//tr/td[1]")
+emit({
+  items = table.concat(result.items, ","),
+  total = result.total,
+  dates = dates,
+  title = text("//h1"),
+  missing = extract({x = "//*[@id='missing']"}).x,
+  secret = env("SCRIPT_SECRET"),
+})
+`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	result, err := (&Runner{Getenv: func(name string) string { return "secret" }}).Run(newSiteBrowser(), s, nil)
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	record := result.Records[0]
+	dates, _ := record["dates"].([]interface{})
+	if record["items"] != "a,b" || record["total"] != float64(2) || len(dates) != 2 || dates[1] != "03/04" ||
+		record["title"] != "Lawsuit 123" || record["missing"] != "" || record["secret"] != "secret" {
+		t.Errorf("Unexpected record %v", record)
+	}
+
+	// without Getenv, the environment can't be read
+	_, err = (&Runner{}).Run(newSiteBrowser(), s, nil)
+	if err == nil || !strings.Contains(err.Error(), "bindings.lua:11:") {
+		t.Errorf("Expected an error for an environment variable without Getenv, got %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		line   int
+		err    error
+	}{
+		{"browser error", "open('https://example.com/search')\nclick('//*[@id=\"missing\"]')", 2, goSpider.ErrElementNotFound},
+		{"wait timeout", "open('https://example.com/search')\n\nwait('//table')", 3, goSpider.ErrTimeout},
+		{"unknown sink", "emit({a = 1}, 'db')", 1, nil},
+		{"bad argument", "open({})", 1, nil},
+		{"script error", "local function check(n)\n  error('bad number ' .. n)\nend\ncheck(7)", 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse("test.lua", []byte(tt.script))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			_, err = (&Runner{}).Run(newSiteBrowser(), s, nil)
+			var scriptErr *Error
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("Expected a script error, got %v", err)
+			}
+			if scriptErr.Line != tt.line {
+				t.Errorf("Expected an error on line %d, got %v", tt.line, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	s, err := Parse("loop.lua", []byte("while true do end"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	result, err := (&Runner{MaxSteps: 1000}).Run(newSiteBrowser(), s, nil)
+	if err == nil || result.Steps != 1001 {
+		t.Errorf("Expected the script to stop after 1000 steps, got %d (%v)", result.Steps, err)
+	}
+
+	start := time.Now()
+	_, err = (&Runner{Timeout: 50 * time.Millisecond}).Run(newSiteBrowser(), s, nil)
+	if err == nil || time.Since(start) > time.Second {
+		t.Errorf("Expected the script to time out, got %v after %v", err, time.Since(start))
+	}
+
+	s, err = Parse("sleep.lua", []byte("sleep(10)"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	start = time.Now()
+	_, err = (&Runner{Timeout: 50 * time.Millisecond}).Run(newSiteBrowser(), s, nil)
+	if err == nil || time.Since(start) > time.Second {
+		t.Errorf("Expected the sleep to time out, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.lua")
+	if err := os.WriteFile(path, []byte("open(\"https://example.com\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "broken.lua") {
+		t.Errorf("Expected a syntax error naming the script, got %v", err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.lua")); err == nil {
+		t.Error("Expected an error for a missing script")
+	}
+}
@@ -0,0 +1,371 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TextMatch defines how FindByText compares the visible text of elements.
+type TextMatch int
+
+const (
+	// TextExact matches elements whose whitespace-normalized text equals the text.
+	TextExact TextMatch = iota
+	// TextContains matches elements whose whitespace-normalized text contains the text.
+	TextContains
+	// TextRegex matches elements whose text matches the text as a Go regular expression.
+	TextRegex
+)
+
+// SelectorSeparator separates the alternatives of a selector chain, see Selectors.
+const SelectorSeparator = " || "
+
+// Selectors builds a selector chain: any selector argument of the Navigator methods and any expression of FindNodes,
+// ExtractTable and ExtractText may list alternatives that are tried in order, the first one matching being used.
+// Example:
+//
+//	people := goSpider.Selectors("//*[@id=\"tableTodasPartes\"]/tbody/tr", "//*[@id=\"tablePartesPrincipais\"]/tbody/tr")
+//	nodes, err := goSpider.FindNodes(pageSource, people)
+func Selectors(alternatives ...string) string {
+	return strings.Join(alternatives, SelectorSeparator)
+}
+
+// SplitSelectors returns the alternatives of a selector chain; a plain selector is returned as its only alternative.
+func SplitSelectors(selector string) []string {
+	var alternatives []string
+	for _, alternative := range strings.Split(selector, SelectorSeparator) {
+		if alternative = strings.TrimSpace(alternative); alternative != "" {
+			alternatives = append(alternatives, alternative)
+		}
+	}
+	if len(alternatives) == 0 {
+		return []string{selector}
+	}
+	return alternatives
+}
+
+// ResolveSelector returns the alternative of a selector chain that matched, waiting up to nav.Timeout for one of them to
+// be on the page. Plain selectors are returned unchanged without querying the page. The error wraps ErrElementNotFound
+// when no alternative matched, or the failure of the browser otherwise.
+// Example:
+//
+//	matched, err := nav.ResolveSelector(goSpider.Selectors("#tablePartesPrincipais", "#tableTodasPartes"))
+func (nav *Navigator) ResolveSelector(selector string) (string, error) {
+	matched, ok, err := nav.matchSelector(selector, nav.Timeout)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to resolve selector: %v\n", err)
+		return "", fmt.Errorf("error - failed to resolve selector: %w", classifyError(err))
+	}
+	if !ok {
+		nav.Logger.Printf("Error - No selector matched: %s\n", selector)
+		return "", withKind(ErrElementNotFound, fmt.Errorf("error - no selector matched: %s", selector))
+	}
+	return matched, nil
+}
+
+// ResolveExpression returns the alternative of an expression chain that finds nodes in node.
+// Example:
+//
+//	matched, err := goSpider.ResolveExpression(pageSource, goSpider.Selectors("//*[@id=\"tableTodasPartes\"]", "//*[@id=\"tablePartesPrincipais\"]"))
+func ResolveExpression(node *html.Node, expression string) (string, error) {
+	for _, alternative := range SplitSelectors(expression) {
+		nodes, err := htmlquery.Find(node, alternative)
+		if err == nil && len(nodes) > 0 {
+			return alternative, nil
+		}
+	}
+	return "", withKind(ErrElementNotFound, fmt.Errorf("error - no expression matched: %s", expression))
+}
+
+// firstMatch returns the matched alternative of a selector chain, or its first alternative when none matched, so the
+// caller fails with its usual error.
+func (nav *Navigator) firstMatch(selector string, timeout time.Duration) string {
+	matched, _, _ := nav.matchSelector(selector, timeout)
+	return matched
+}
+
+// matchSelector resolves a selector chain, healing it from recorded locators when self-healing is enabled. It reports
+// whether an alternative matched, and returns an error only when the page could not be queried.
+func (nav *Navigator) matchSelector(selector string, timeout time.Duration) (string, bool, error) {
+	if nav.Locators != nil {
+		return nav.heal(selector, timeout)
+	}
+
+	alternatives := SplitSelectors(selector)
+	if len(alternatives) == 1 {
+		return alternatives[0], true, nil
+	}
+	return nav.observeSelector(selector, timeout)
+}
+
+// observeSelector waits until an alternative of a selector chain is on the page or timeout expires, and returns the first
+// alternative, in order, that matches. The page is always checked at least once.
+func (nav *Navigator) observeSelector(selector string, timeout time.Duration) (string, bool, error) {
+	alternatives := SplitSelectors(selector)
+	found, err := nav.waitForCondition(anyElementCondition(alternatives), timeout)
+	if err != nil || !found {
+		return alternatives[0], false, err
+	}
+
+	for i, alternative := range alternatives {
+		count, err := nav.countElements(alternative)
+		if err != nil {
+			return alternatives[0], false, err
+		}
+		if count > 0 {
+			if len(alternatives) > 1 {
+				nav.Logger.Printf("Selector chain matched alternative %d: %s\n", i+1, alternative)
+			}
+			return alternative, true, nil
+		}
+	}
+	return alternatives[0], false, nil
+}
+
+// firstExpressionMatch returns the alternative of an expression chain that finds nodes in node, or its first alternative.
+// Plain expressions are returned without evaluating them.
+func firstExpressionMatch(node *html.Node, expression string) string {
+	alternatives := SplitSelectors(expression)
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	matched, err := ResolveExpression(node, expression)
+	if err != nil {
+		return alternatives[0]
+	}
+	return matched
+}
+
+// jsAbsoluteXPath is a JavaScript function that returns the absolute XPath of an element, e.g. "/html[1]/body[1]/div[2]".
+const jsAbsoluteXPath = `function(el) {
+	var parts = [];
+	for (; el && el.nodeType === 1; el = el.parentNode) {
+		var index = 1;
+		for (var sibling = el.previousElementSibling; sibling; sibling = sibling.previousElementSibling) {
+			if (sibling.nodeName === el.nodeName) index++;
+		}
+		parts.unshift(el.nodeName.toLowerCase() + "[" + index + "]");
+	}
+	return "/" + parts.join("/");
+}`
+
+// elementInfo describes an element found by queryElements.
+type elementInfo struct {
+	Path    string
+	Text    string
+	Visible bool
+}
+
+// FindByText returns an absolute XPath selector for the first visible element showing text, to be used with any other
+// Navigator method. Labels change far less than ids and classes on most sites, so they make steadier selectors.
+// Example:
+//
+//	selector, err := nav.FindByText("Pesquisar processos", goSpider.TextContains)
+//	if err == nil {
+//		err = nav.ClickButton(selector)
+//	}
+func (nav *Navigator) FindByText(text string, match TextMatch) (string, error) {
+	var re *regexp.Regexp
+	var expression string
+	if match == TextRegex {
+		var err error
+		re, err = regexp.Compile(text)
+		if err != nil {
+			return "", fmt.Errorf("error - invalid text regex: %w", classifyError(err))
+		}
+		expression = `//body//*[not(self::script or self::style)]`
+	} else {
+		expression = textXPath(text, match)
+	}
+
+	elements, err := nav.queryElements(expression)
+	if err != nil {
+		return "", err
+	}
+	if re != nil {
+		elements = deepestMatches(elements, re)
+	}
+
+	for _, element := range elements {
+		if element.Visible {
+			nav.Logger.Printf("Found element with text %q: %s\n", text, element.Path)
+			return element.Path, nil
+		}
+	}
+
+	nav.Logger.Printf("Error - No visible element with text: %s\n", text)
+	return "", withKind(ErrElementNotFound, fmt.Errorf("error - no visible element with text: %s", text))
+}
+
+// ClickByText clicks the first visible element whose text is exactly text, ignoring surrounding and repeated whitespace.
+// Example:
+//
+//	err := nav.ClickByText("Consultar")
+func (nav *Navigator) ClickByText(text string) error {
+	selector, err := nav.FindByText(text, TextExact)
+	if err != nil {
+		return err
+	}
+	return nav.ClickButton(selector)
+}
+
+// GetByRole returns an absolute XPath selector for the first element exposed in the accessibility tree with the ARIA role
+// and accessible name, e.g. a button labelled "Pesquisar". An empty name matches any name. Roles and names are computed by
+// the browser, so this keeps working when the markup behind a component changes.
+// Example:
+//
+//	selector, err := nav.GetByRole("button", "Pesquisar")
+//	if err == nil {
+//		err = nav.ClickButton(selector)
+//	}
+func (nav *Navigator) GetByRole(role, name string) (string, error) {
+	var selector string
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		document, exception, err := runtime.Evaluate("document").Do(ctx)
+		if err != nil {
+			return err
+		}
+		if exception != nil {
+			return exception
+		}
+
+		query := accessibility.QueryAXTree().WithObjectID(document.ObjectID).WithRole(role)
+		if name != "" {
+			query = query.WithAccessibleName(name)
+		}
+		nodes, err := query.Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			if node.Ignored || node.BackendDOMNodeID == 0 {
+				continue
+			}
+			object, err := dom.ResolveNode().WithBackendNodeID(node.BackendDOMNodeID).Do(ctx)
+			if err != nil {
+				return err
+			}
+			result, exception, err := runtime.CallFunctionOn("function() { return (" + jsAbsoluteXPath + ")(this); }").
+				WithObjectID(object.ObjectID).
+				WithReturnByValue(true).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			if exception != nil {
+				return exception
+			}
+			return json.Unmarshal(result.Value, &selector)
+		}
+		return withKind(ErrElementNotFound, fmt.Errorf("no element with role %s and name %q", role, name))
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get element by role: %v\n", err)
+		return "", fmt.Errorf("error - failed to get element by role: %w", classifyError(err))
+	}
+
+	nav.Logger.Printf("Found element with role %s and name %q: %s\n", role, name, selector)
+	return selector, nil
+}
+
+// textXPath builds the XPath of the innermost elements whose normalized text (or button value) matches text.
+func textXPath(text string, match TextMatch) string {
+	literal := xpathLiteral(strings.Join(strings.Fields(text), " "))
+	condition := func(node string) string {
+		if match == TextContains {
+			return fmt.Sprintf("contains(normalize-space(%s), %s)", node, literal)
+		}
+		return fmt.Sprintf("normalize-space(%s)=%s", node, literal)
+	}
+
+	return fmt.Sprintf(`(//body//*[not(self::script or self::style)][%s][not(*[%s])] | //input[@type="submit" or @type="button" or @type="reset"][%s])`,
+		condition("."), condition("."), condition("@value"))
+}
+
+// xpathLiteral quotes s as an XPath 1.0 string literal, using concat() when s has both kinds of quotes.
+func xpathLiteral(s string) string {
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+
+	parts := strings.Split(s, `"`)
+	quoted := make([]string, 0, len(parts)*2)
+	for i, part := range parts {
+		if i > 0 {
+			quoted = append(quoted, `'"'`)
+		}
+		if part != "" {
+			quoted = append(quoted, `"`+part+`"`)
+		}
+	}
+	return "concat(" + strings.Join(quoted, ", ") + ")"
+}
+
+// deepestMatches keeps the elements whose text matches re and that have no matching descendant, in document order.
+func deepestMatches(elements []elementInfo, re *regexp.Regexp) []elementInfo {
+	var matches []elementInfo
+	for _, element := range elements {
+		if re.MatchString(element.Text) {
+			matches = append(matches, element)
+		}
+	}
+
+	var deepest []elementInfo
+	for i, element := range matches {
+		hasMatchingDescendant := false
+		for _, other := range matches[i+1:] {
+			if strings.HasPrefix(other.Path, element.Path+"/") {
+				hasMatchingDescendant = true
+				break
+			}
+		}
+		if !hasMatchingDescendant {
+			deepest = append(deepest, element)
+		}
+	}
+	return deepest
+}
+
+// queryElements evaluates the XPath expression on the page and returns the absolute path, normalized text and
+// visibility of every matching element, in document order.
+func (nav *Navigator) queryElements(expression string) ([]elementInfo, error) {
+	var elements []elementInfo
+	err := chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function() {
+			var absolute = %s;
+			var result = document.evaluate(%s, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			var elements = [];
+			for (var i = 0; i < result.snapshotLength; i++) {
+				var el = result.snapshotItem(i);
+				if (el.nodeType !== 1) continue;
+				var rect = el.getBoundingClientRect();
+				var style = window.getComputedStyle(el);
+				elements.push({
+					Path: absolute(el),
+					Text: (el.innerText || el.value || el.textContent || "").replace(/\s+/g, " ").trim(),
+					Visible: rect.width > 0 && rect.height > 0 && style.visibility !== "hidden"
+				});
+			}
+			return elements;
+		})()`, jsAbsoluteXPath, jsString(expression)), &elements),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to query elements: %v\n", err)
+		return nil, fmt.Errorf("error - failed to query elements: %w", classifyError(err))
+	}
+	return elements, nil
+}
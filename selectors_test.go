@@ -0,0 +1,241 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestXpathLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Consultar", `"Consultar"`},
+		{`Nome "completo"`, `'Nome "completo"'`},
+		{`Nome "completo" d'Ávila`, `concat("Nome ", '"', "completo", '"', " d'Ávila")`},
+	}
+
+	for _, test := range tests {
+		literal := xpathLiteral(test.input)
+		if literal != test.expected {
+			t.Errorf("xpathLiteral(%q) = %s, expected %s", test.input, literal, test.expected)
+		}
+	}
+}
+
+func TestDeepestMatches(t *testing.T) {
+	elements := []elementInfo{
+		{Path: "/html[1]/body[1]", Text: "Processo 123 Outro"},
+		{Path: "/html[1]/body[1]/div[1]", Text: "Processo 123"},
+		{Path: "/html[1]/body[1]/div[1]/span[1]", Text: "Processo 123"},
+		{Path: "/html[1]/body[1]/div[2]", Text: "Outro"},
+		{Path: "/html[1]/body[1]/div[10]", Text: "Processo 456"},
+	}
+
+	matches := deepestMatches(elements, regexp.MustCompile(`Processo \d+`))
+	if len(matches) != 2 || matches[0].Path != "/html[1]/body[1]/div[1]/span[1]" || matches[1].Path != "/html[1]/body[1]/div[10]" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestFindByText(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	tests := []struct {
+		text     string
+		match    TextMatch
+		expected string
+	}{
+		{"Consultar", TextExact, "consultar"},
+		{"Pesquisar", TextContains, "pesquisar"},
+		{`Nome "completo" d'Ávila`, TextExact, "quoted"},
+		{`\d{7}-\d{2}\.\d{4}`, TextRegex, "process"},
+		{"Enviar", TextExact, "enviar"},
+	}
+
+	for _, test := range tests {
+		selector, err := nav.FindByText(test.text, test.match)
+		if err != nil {
+			t.Errorf("FindByText(%q) error: %v", test.text, err)
+			continue
+		}
+		id, err := nav.GetElementAttribute(selector, "id")
+		if err != nil {
+			// the innermost match may be a child without id, so check its parent
+			id, err = nav.GetElementAttribute(selector+"/..", "id")
+		}
+		if err != nil || id != test.expected {
+			t.Errorf("FindByText(%q) = %s (id %q), expected #%s", test.text, selector, id, test.expected)
+		}
+	}
+
+	_, err := nav.FindByText("Missing label", TextExact)
+	if err == nil {
+		t.Errorf("Expected error for a missing text")
+	}
+}
+
+func TestClickByText(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	err := nav.ClickByText("Consultar")
+	if err != nil {
+		t.Fatalf("ClickByText error: %v", err)
+	}
+
+	result, err := nav.GetElement("#textResult")
+	if err != nil {
+		t.Fatalf("GetElement error: %v", err)
+	}
+	if result != "consultar" {
+		t.Errorf("Expected the visible button to be clicked, but got %q", result)
+	}
+}
+
+func TestGetByRole(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	tests := []struct {
+		role     string
+		name     string
+		expected string
+	}{
+		{"button", "Pesquisar", "searchButton"},
+		{"button", "Fechar", "iconButton"},
+		{"textbox", "Número do processo", "processNumber"},
+		{"heading", "", "heading"},
+	}
+
+	for _, test := range tests {
+		selector, err := nav.GetByRole(test.role, test.name)
+		if err != nil {
+			t.Errorf("GetByRole(%s, %q) error: %v", test.role, test.name, err)
+			continue
+		}
+		id, err := nav.GetElementAttribute(selector, "id")
+		if err != nil || id != test.expected {
+			t.Errorf("GetByRole(%s, %q) = %s (id %q), expected #%s", test.role, test.name, selector, id, test.expected)
+		}
+	}
+
+	selector, err := nav.GetByRole("button", "Fechar")
+	if err != nil {
+		t.Fatalf("GetByRole error: %v", err)
+	}
+	err = nav.ClickButton(selector)
+	if err != nil {
+		t.Fatalf("ClickButton error: %v", err)
+	}
+	result, err := nav.GetElement("#roleResult")
+	if err != nil || result != "fechar" {
+		t.Errorf("Expected the Fechar button to be clicked, but got %q (%v)", result, err)
+	}
+
+	_, err = nav.GetByRole("button", "Missing")
+	if err == nil {
+		t.Errorf("Expected error for a missing role")
+	}
+}
+
+func TestSplitSelectors(t *testing.T) {
+	chain := Selectors("#tablePartesPrincipais > tbody > tr", "#tableTodasPartes > tbody > tr")
+	alternatives := SplitSelectors(chain)
+	if len(alternatives) != 2 || alternatives[0] != "#tablePartesPrincipais > tbody > tr" || alternatives[1] != "#tableTodasPartes > tbody > tr" {
+		t.Errorf("Unexpected alternatives: %q", alternatives)
+	}
+
+	alternatives = SplitSelectors("//*[@id=\"a\"] | //*[@id=\"b\"]")
+	if len(alternatives) != 1 {
+		t.Errorf("Expected an xpath union to be a single selector, but got %q", alternatives)
+	}
+}
+
+func TestResolveExpression(t *testing.T) {
+	pageSource, err := html.Parse(strings.NewReader(`<table id="tableTodasPartes"><tbody><tr><td>Autor</td></tr></tbody></table>`))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	chain := Selectors("//*[@id=\"tablePartesPrincipais\"]/tbody/tr", "//*[@id=\"tableTodasPartes\"]/tbody/tr")
+	matched, err := ResolveExpression(pageSource, chain)
+	if err != nil {
+		t.Fatalf("ResolveExpression error: %v", err)
+	}
+	if matched != "//*[@id=\"tableTodasPartes\"]/tbody/tr" {
+		t.Errorf("Unexpected matched expression: %s", matched)
+	}
+
+	nodes, err := FindNodes(pageSource, chain)
+	if err != nil || len(nodes) != 1 {
+		t.Errorf("Expected FindNodes to use the second alternative, but got %d nodes (%v)", len(nodes), err)
+	}
+
+	_, err = ResolveExpression(pageSource, Selectors("//table[@id=\"missing\"]", "//div"))
+	if err == nil {
+		t.Errorf("Expected error when no alternative matches")
+	}
+	// plain expressions are not evaluated, not even on a missing page
+	if expression := firstExpressionMatch(nil, "//td"); expression != "//td" {
+		t.Errorf("Expected the plain expression unchanged, but got %s", expression)
+	}
+}
+
+func TestResolveSelector(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	nav.OpenURL(server.URL + "/elements.html")
+
+	matched, err := nav.ResolveSelector(Selectors("#missingButton", "//*[@id=\"consultar\"]"))
+	if err != nil {
+		t.Fatalf("ResolveSelector error: %v", err)
+	}
+	if matched != "//*[@id=\"consultar\"]" {
+		t.Errorf("Unexpected matched selector: %s", matched)
+	}
+
+	err = nav.ClickButton(Selectors("#missingButton", "#consultar"))
+	if err != nil {
+		t.Fatalf("ClickButton error: %v", err)
+	}
+	result, err := nav.GetElement("#textResult")
+	if err != nil || result != "consultar" {
+		t.Errorf("Expected #consultar to be clicked, but got %q (%v)", result, err)
+	}
+
+	_, err = nav.ResolveSelector(Selectors("#missingButton", "#otherMissingButton"))
+	if err == nil {
+		t.Errorf("Expected error when no alternative matches")
+	}
+}
+
+func TestResolveSelectorWithoutBrowser(t *testing.T) {
+	nav := &Navigator{Ctx: context.Background(), Logger: log.New(io.Discard, "", 0)}
+	_, err := nav.ResolveSelector(Selectors("#missingButton", "#consultar"))
+	if !errors.Is(err, chromedp.ErrInvalidContext) || !errors.Is(err, ErrBrowserCrashed) {
+		t.Errorf("Expected the browser error, but got %v", err)
+	}
+	if errors.Is(err, ErrElementNotFound) {
+		t.Errorf("Expected a browser failure not to be reported as a missing element: %v", err)
+	}
+}
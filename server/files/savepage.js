@@ -0,0 +1 @@
+document.documentElement.setAttribute("data-script", "loaded");
@@ -0,0 +1,8 @@
+// Answers serviceworker.txt from the worker, like a stale offline cache
+self.addEventListener('install', function () { self.skipWaiting(); });
+self.addEventListener('activate', function (event) { event.waitUntil(self.clients.claim()); });
+self.addEventListener('fetch', function (event) {
+    if (new URL(event.request.url).pathname === '/serviceworker.txt') {
+        event.respondWith(new Response('service worker'));
+    }
+});
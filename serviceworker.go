@@ -0,0 +1,50 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// jsUnregisterServiceWorkers is a JavaScript expression unregistering the service workers of the origin of the page
+// and returning how many were registered.
+const jsUnregisterServiceWorkers = `(function() {
+	if (!navigator.serviceWorker) return Promise.resolve(0);
+	return navigator.serviceWorker.getRegistrations().then(function(registrations) {
+		return Promise.all(registrations.map(function(registration) { return registration.unregister(); }));
+	}).then(function(results) { return results.length; });
+})()`
+
+// BypassServiceWorkers makes the requests of the page skip its service workers when bypass is set, so a PWA serves
+// fresh network content instead of the stale copies of its offline cache, and the requests a worker would answer still
+// go through the network, where TrackAssets, cassettes and BlockResourceTypes see them.
+// Example:
+//
+//	err := nav.BypassServiceWorkers(true)
+func (nav *Navigator) BypassServiceWorkers(bypass bool) error {
+	err := chromedp.Run(nav.Ctx, network.Enable(), network.SetBypassServiceWorker(bypass))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to set service worker bypass: %v\n", err)
+		return fmt.Errorf("error - failed to set service worker bypass: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Bypassing service workers: %t\n", bypass)
+	return nil
+}
+
+// UnregisterServiceWorkers unregisters the service workers of the origin of the current page, returning how many there
+// were, so the next loads of the origin come from the network. The page open keeps its worker until it is reloaded.
+// Example:
+//
+//	err := nav.OpenURL("https://pje.tjsp.jus.br")
+//	count, err := nav.UnregisterServiceWorkers()
+//	err = nav.ReloadPage(3)
+func (nav *Navigator) UnregisterServiceWorkers() (int, error) {
+	var count int
+	err := chromedp.Run(nav.Ctx, chromedp.Evaluate(jsUnregisterServiceWorkers, &count, awaitPromise))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to unregister service workers: %v\n", err)
+		return 0, fmt.Errorf("error - failed to unregister service workers: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Unregistered %d service workers\n", count)
+	return count, nil
+}
@@ -0,0 +1,61 @@
+package goSpider
+
+import (
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestServiceWorkers(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/serviceworker.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	var controlled bool
+	err = chromedp.Run(nav.Ctx, chromedp.Evaluate(`window.controlled`, &controlled, awaitPromise))
+	if err != nil || !controlled {
+		t.Fatalf("Expected the service worker to control the page, got %v: %v", controlled, err)
+	}
+
+	fetchText := func() string {
+		var text string
+		err := chromedp.Run(nav.Ctx,
+			chromedp.Evaluate(`fetch("/serviceworker.txt").then(function(r) { return r.text(); })`, &text, awaitPromise))
+		if err != nil {
+			t.Fatalf("Failed to fetch: %v", err)
+		}
+		return text
+	}
+	if text := fetchText(); text != "service worker" {
+		t.Fatalf("Expected the service worker to answer, got %q", text)
+	}
+
+	err = nav.BypassServiceWorkers(true)
+	if err != nil {
+		t.Fatalf("BypassServiceWorkers error: %v", err)
+	}
+	if text := fetchText(); text != "network" {
+		t.Errorf("Expected the network to answer with the bypass, got %q", text)
+	}
+	err = nav.BypassServiceWorkers(false)
+	if err != nil {
+		t.Fatalf("BypassServiceWorkers error: %v", err)
+	}
+
+	count, err := nav.UnregisterServiceWorkers()
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 service worker unregistered, got %d: %v", count, err)
+	}
+	err = nav.OpenURL(server.URL + "/serviceworker.txt")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	text, err := nav.EvaluateScript(`document.body.innerText`)
+	if err != nil || text != "network" {
+		t.Errorf("Expected the network to answer after unregistering, got %v: %v", text, err)
+	}
+}
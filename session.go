@@ -0,0 +1,136 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Session is the state of a logged in browser saved by SaveSession: its cookies, and the localStorage and
+// sessionStorage of the origin of the page.
+type Session struct {
+	Saved          time.Time         `json:"saved"`
+	URL            string            `json:"url"`
+	Origin         string            `json:"origin"`
+	Cookies        []*network.Cookie `json:"cookies"`
+	LocalStorage   map[string]string `json:"localStorage"`
+	SessionStorage map[string]string `json:"sessionStorage"`
+}
+
+// jsReadStorage is a JavaScript expression returning the page URL, origin and storage of a Session.
+const jsReadStorage = `(function() {
+	var read = function(storage) {
+		var items = {};
+		try {
+			for (var i = 0; i < storage.length; i++) items[storage.key(i)] = storage.getItem(storage.key(i));
+		} catch (e) {}
+		return items;
+	};
+	return {URL: location.href, Origin: location.origin, LocalStorage: read(window.localStorage),
+		SessionStorage: read(window.sessionStorage)};
+})()`
+
+// jsWriteStorage is a JavaScript function setting the items of the localStorage and sessionStorage of the page.
+const jsWriteStorage = `function(local, session) {
+	for (var key in local) localStorage.setItem(key, local[key]);
+	for (var key in session) sessionStorage.setItem(key, session[key]);
+}`
+
+// SaveSession saves the cookies of the browser and the localStorage and sessionStorage of the origin of the current
+// page to path, so a long job can resume with RestoreSession after a crash without logging in again. The file holds
+// credentials and is readable by the owner only.
+// Example:
+//
+//	err := nav.Login(loginURL, username, password, "#usernameField", "#passwordField", "#loginButton", "")
+//	err = nav.SaveSession("session/tjsp.json")
+func (nav *Navigator) SaveSession(path string) error {
+	cookies, err := nav.GetCookies()
+	if err != nil {
+		return err
+	}
+	session := Session{Saved: time.Now().UTC(), Cookies: cookies}
+	err = chromedp.Run(nav.Ctx, chromedp.Evaluate(jsReadStorage, &session))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to read session storage: %v\n", err)
+		return fmt.Errorf("error - failed to read session storage: %w", classifyError(err))
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error - failed to encode session: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err == nil {
+		err = os.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to save session: %v\n", err)
+		return fmt.Errorf("error - failed to save session: %w", err)
+	}
+	nav.Logger.Printf("Session of %s saved to %s\n", session.Origin, path)
+	return nil
+}
+
+// RestoreSession restores the session saved by SaveSession to path: the cookies not expired, and the localStorage and
+// sessionStorage of its origin. As storage belongs to an origin, the origin is opened first when the current page is
+// elsewhere; open the page to resume from afterwards.
+// Example:
+//
+//	nav := goSpider.NewNavigator("", true)
+//	err := nav.RestoreSession("session/tjsp.json")
+//	err = nav.OpenURL(nextLawsuitURL)
+func (nav *Navigator) RestoreSession(path string) error {
+	session, err := LoadSession(path)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to read session: %v\n", err)
+		return err
+	}
+	err = nav.SetCookies(liveCookies(session.Cookies, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	stored := len(session.LocalStorage) > 0 || len(session.SessionStorage) > 0
+	if stored && session.Origin != "" && session.Origin != "null" {
+		var origin string
+		err = chromedp.Run(nav.Ctx, chromedp.Evaluate(`location.origin`, &origin))
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to get current origin: %v\n", err)
+			return fmt.Errorf("error - failed to get current origin: %w", classifyError(err))
+		}
+		if origin != session.Origin {
+			err = nav.OpenURL(session.Origin + "/")
+			if err != nil {
+				return err
+			}
+		}
+		local, _ := json.Marshal(session.LocalStorage)
+		sessionStorage, _ := json.Marshal(session.SessionStorage)
+		err = chromedp.Run(nav.Ctx,
+			chromedp.Evaluate(fmt.Sprintf(`(%s)(%s, %s)`, jsWriteStorage, local, sessionStorage), nil))
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to restore session storage: %v\n", err)
+			return fmt.Errorf("error - failed to restore session storage: %w", classifyError(err))
+		}
+	}
+	nav.Logger.Printf("Session of %s restored from %s\n", session.Origin, path)
+	return nil
+}
+
+// LoadSession reads the session saved by SaveSession to path.
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read session: %w", err)
+	}
+	var session Session
+	err = json.Unmarshal(data, &session)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to decode session: %w", err)
+	}
+	return &session, nil
+}
@@ -0,0 +1,64 @@
+package goSpider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestSaveRestoreSession(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	err = nav.SetCookies([]*network.Cookie{{Name: "JSESSIONID", Value: "abc123", Domain: "127.0.0.1", Path: "/",
+		Session: true}})
+	if err != nil {
+		t.Fatalf("SetCookies error: %v", err)
+	}
+	_, err = nav.EvaluateScript(`localStorage.setItem("token", "t0k3n"); sessionStorage.setItem("step", "7")`)
+	if err != nil {
+		t.Fatalf("Failed to set storage: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	err = nav.SaveSession(path)
+	if err != nil {
+		t.Fatalf("SaveSession error: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the session to be readable by the owner only, got %v: %v", info.Mode(), err)
+	}
+
+	fresh := setupNavigator(t)
+	err = fresh.RestoreSession(path)
+	if err != nil {
+		t.Fatalf("RestoreSession error: %v", err)
+	}
+	state, err := fresh.EvaluateScript(`document.cookie + " " + localStorage.getItem("token") + " " + sessionStorage.getItem("step")`)
+	if err != nil || state != "JSESSIONID=abc123 t0k3n 7" {
+		t.Errorf("Expected the cookies and storage to be restored, got %v: %v", state, err)
+	}
+}
+
+func TestLoadSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	os.WriteFile(path, []byte(`{"origin": "https://esaj.tjsp.jus.br", "cookies": [{"name": "a", "value": "1"}],
+		"localStorage": {"token": "x"}}`), 0600)
+	session, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession error: %v", err)
+	}
+	if session.Origin != "https://esaj.tjsp.jus.br" || len(session.Cookies) != 1 || session.LocalStorage["token"] != "x" {
+		t.Errorf("Unexpected session %+v", session)
+	}
+	if _, err := LoadSession(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing session")
+	}
+}
@@ -0,0 +1,156 @@
+package goSpider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SheetsScope is the OAuth scope of the Google Sheets API.
+const SheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// DefaultSheetsBatchSize is the number of rows appended per request when SheetsSink.BatchSize is not set.
+const DefaultSheetsBatchSize = 500
+
+// SheetsSink is a Sink that appends records as rows of a Google Sheets spreadsheet. The first row of the sheet holds
+// the header: it is read on the first write, written when the sheet is empty and extended when records bring new
+// fields, so each field keeps its column. Nested values are written as JSON. It is safe for concurrent use.
+type SheetsSink struct {
+	Account       *ServiceAccount
+	SpreadsheetID string
+	Sheet         string // the sheet title
+	BatchSize     int    // rows appended per request, DefaultSheetsBatchSize if not positive
+	APIURL        string // the Sheets API URL, "https://sheets.googleapis.com/v4" by default
+	Client        *http.Client
+
+	mu      sync.Mutex
+	columns []string // nil until the header is read
+}
+
+// NewSheetsSink creates a SheetsSink for the sheet of the spreadsheet, authenticated with the service account key
+// file. The spreadsheet must be shared with the service account email.
+// Example:
+//
+//	sink, err := goSpider.NewSheetsSink("service-account.json", "1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms", "Lawsuits")
+//	record, err := goSpider.RecordOf(lawsuit.Cover)
+//	err = sink.Write([]goSpider.Record{record})
+func NewSheetsSink(credentialsFile, spreadsheetID, sheet string) (*SheetsSink, error) {
+	account, err := LoadServiceAccount(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	return &SheetsSink{
+		Account:       account,
+		SpreadsheetID: spreadsheetID,
+		Sheet:         sheet,
+		APIURL:        "https://sheets.googleapis.com/v4",
+		Client:        account.Client,
+	}, nil
+}
+
+// Write appends the records as rows, in batches of BatchSize rows.
+func (s *SheetsSink) Write(records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.columns == nil {
+		columns, err := s.readHeader()
+		if err != nil {
+			return err
+		}
+		s.columns = columns
+	}
+	known := map[string]bool{}
+	for _, column := range s.columns {
+		known[column] = true
+	}
+	var added []string
+	for _, field := range recordFields(records) {
+		if !known[field] {
+			added = append(added, field)
+		}
+	}
+	if len(added) > 0 {
+		columns := append(append([]string(nil), s.columns...), added...)
+		err := s.writeHeader(columns)
+		if err != nil {
+			return err
+		}
+		s.columns = columns
+	}
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultSheetsBatchSize
+	}
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		rows := make([][]string, 0, end-start)
+		for _, record := range records[start:end] {
+			row := make([]string, len(s.columns))
+			for i, column := range s.columns {
+				row[i] = cellValue(record[column])
+			}
+			rows = append(rows, row)
+		}
+		err := s.do(http.MethodPost, s.valuesURL("A1")+":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS",
+			map[string]interface{}{"values": rows}, nil)
+		if err != nil {
+			return fmt.Errorf("error - failed to append rows: %w", err)
+		}
+	}
+	return nil
+}
+
+// readHeader returns the first row of the sheet.
+func (s *SheetsSink) readHeader() ([]string, error) {
+	var response struct {
+		Values [][]string `json:"values"`
+	}
+	err := s.do(http.MethodGet, s.valuesURL("1:1"), nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read header: %w", err)
+	}
+	columns := []string{}
+	if len(response.Values) > 0 {
+		columns = append(columns, response.Values[0]...)
+	}
+	return columns, nil
+}
+
+// writeHeader writes the columns to the first row of the sheet.
+func (s *SheetsSink) writeHeader(columns []string) error {
+	err := s.do(http.MethodPut, s.valuesURL("1:1")+"?valueInputOption=RAW",
+		map[string]interface{}{"values": [][]string{columns}}, nil)
+	if err != nil {
+		return fmt.Errorf("error - failed to write header: %w", err)
+	}
+	return nil
+}
+
+// valuesURL returns the values endpoint of a range of the sheet, e.g. "1:1".
+func (s *SheetsSink) valuesURL(cells string) string {
+	apiURL := s.APIURL
+	if apiURL == "" {
+		apiURL = "https://sheets.googleapis.com/v4"
+	}
+	sheetRange := cells
+	if s.Sheet != "" {
+		sheetRange = "'" + strings.ReplaceAll(s.Sheet, "'", "''") + "'!" + cells
+	}
+	return strings.TrimRight(apiURL, "/") + "/spreadsheets/" + url.PathEscape(s.SpreadsheetID) + "/values/" +
+		url.PathEscape(sheetRange)
+}
+
+// do sends an authenticated request with payload as JSON body and decodes the JSON response into out.
+func (s *SheetsSink) do(method, endpoint string, payload interface{}, out interface{}) error {
+	return s.Account.do(s.Client, SheetsScope, method, endpoint, payload, out)
+}
@@ -0,0 +1,83 @@
+package goSpider
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSheetsSink(t *testing.T) {
+	var key *rsa.PrivateKey
+	tokens := 0
+	var header []string
+	var rows [][]string
+	var paths []string
+	mux := http.NewServeMux()
+	mux.Handle("/token", tokenHandler(&key, &tokens))
+	mux.HandleFunc("/spreadsheets/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		var body struct {
+			Values [][]string `json:"values"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": [][]string{header}})
+		case r.Method == http.MethodPut && r.URL.Query().Get("valueInputOption") == "RAW":
+			header = body.Values[0]
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":append"):
+			rows = append(rows, body.Values...)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	path, generated := newTestServiceAccount(t, server.URL+"/token")
+	key = generated
+	sink, err := NewSheetsSink(path, "sheet-id", "Lawsuit's")
+	if err != nil {
+		t.Fatalf("NewSheetsSink error: %v", err)
+	}
+	sink.APIURL = server.URL
+	sink.BatchSize = 2
+	header = []string{"number"}
+
+	err = sink.Write([]Record{
+		{"number": "1017927-35.2023.8.26.0008", "class": "Procedimento Comum"},
+		{"number": "0001234-55.2024.8.26.0100", "value": 1500.5},
+		{"number": "0009999-11.2024.8.26.0100", "persons": []interface{}{"Ana"}},
+	})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+
+	if !reflect.DeepEqual(header, []string{"number", "class", "value", "persons"}) {
+		t.Errorf("Expected the new fields after the existing header, got %v", header)
+	}
+	want := [][]string{
+		{"1017927-35.2023.8.26.0008", "Procedimento Comum", "", ""},
+		{"0001234-55.2024.8.26.0100", "", "1500.5", ""},
+		{"0009999-11.2024.8.26.0100", "", "", `["Ana"]`},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Expected rows %v, got %v", want, rows)
+	}
+	if len(paths) != 4 || paths[0] != "GET /spreadsheets/sheet-id/values/'Lawsuit''s'!1:1" {
+		t.Errorf("Expected a header read, a header write and two appends, got %v", paths)
+	}
+
+	// the header is known now, so only the rows are appended
+	paths = nil
+	err = sink.Write([]Record{{"number": "1", "class": "B"}})
+	if err != nil || len(paths) != 1 || tokens != 1 {
+		t.Errorf("Expected a single append with the cached token, got %v after %d tokens: %v", paths, tokens, err)
+	}
+}
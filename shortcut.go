@@ -0,0 +1,231 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// shortcutModifiers are the names of the modifiers of a shortcut, in lower case.
+var shortcutModifiers = map[string]input.Modifier{
+	"ctrl":    input.ModifierCtrl,
+	"control": input.ModifierCtrl,
+	"shift":   input.ModifierShift,
+	"alt":     input.ModifierAlt,
+	"option":  input.ModifierAlt,
+	"meta":    input.ModifierMeta,
+	"cmd":     input.ModifierMeta,
+	"command": input.ModifierMeta,
+}
+
+// shortcutAliases are the names of keys that are not their DOM key values, in lower case.
+var shortcutAliases = map[string]string{
+	"esc":    kb.Escape,
+	"del":    kb.Delete,
+	"return": kb.Enter,
+	"space":  " ",
+	"up":     kb.ArrowUp,
+	"down":   kb.ArrowDown,
+	"left":   kb.ArrowLeft,
+	"right":  kb.ArrowRight,
+	"pgup":   kb.PageUp,
+	"pgdn":   kb.PageDown,
+}
+
+// shortcutCommands are the editing commands of the shortcuts of the clipboard and of the selection, which the browser
+// only runs for key events that carry them, e.g. on macOS.
+var shortcutCommands = map[string]string{
+	"a": "selectAll",
+	"c": "copy",
+	"v": "paste",
+	"x": "cut",
+	"z": "undo",
+	"y": "redo",
+}
+
+// namedKeys are the runes of kb.Keys by their DOM key value, in lower case, e.g. "end" or "f5".
+var namedKeys = func() map[string]rune {
+	keys := make(map[string]rune)
+	for r, key := range kb.Keys {
+		if len(key.Key) > 1 {
+			keys[strings.ToLower(key.Key)] = r
+		}
+	}
+	return keys
+}()
+
+// shortcut is a key pressed with modifiers, see parseShortcut.
+type shortcut struct {
+	modifiers input.Modifier
+	key       *kb.Key
+	command   string
+}
+
+// parseShortcut parses a shortcut such as "Ctrl+End", "Ctrl+Shift+Z" or "Enter": modifiers and a key joined by "+",
+// case-insensitive. Keys are characters or DOM key values, such as "Tab", "ArrowDown", "PageUp" or "F5".
+func parseShortcut(s string) (shortcut, error) {
+	parts := strings.Split(s, "+")
+	if len(parts) > 1 && parts[len(parts)-1] == "" && parts[len(parts)-2] == "" {
+		// the "+" key, as in "Ctrl++"
+		parts = append(parts[:len(parts)-2], "+")
+	}
+
+	var sc shortcut
+	for _, part := range parts[:len(parts)-1] {
+		modifier, ok := shortcutModifiers[strings.ToLower(strings.TrimSpace(part))]
+		if !ok {
+			return shortcut{}, fmt.Errorf("error - invalid shortcut %q: unknown modifier %q", s, part)
+		}
+		sc.modifiers |= modifier
+	}
+
+	name := parts[len(parts)-1]
+	if name != " " {
+		name = strings.TrimSpace(name)
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	switch {
+	case name == "":
+		return shortcut{}, fmt.Errorf("error - invalid shortcut %q: missing key", s)
+	case size == len(name):
+		r = unicode.ToLower(r)
+	default:
+		lower := strings.ToLower(name)
+		if alias, ok := shortcutAliases[lower]; ok {
+			r, _ = utf8.DecodeRuneInString(alias)
+		} else if named, ok := namedKeys[lower]; ok {
+			r = named
+		} else {
+			return shortcut{}, fmt.Errorf("error - invalid shortcut %q: unknown key %q", s, name)
+		}
+	}
+	key, ok := kb.Keys[r]
+	if !ok {
+		return shortcut{}, fmt.Errorf("error - invalid shortcut %q: unknown key %q", s, name)
+	}
+	sc.key = key
+
+	if sc.modifiers&^input.ModifierShift == input.ModifierCtrl || sc.modifiers&^input.ModifierShift == input.ModifierMeta {
+		sc.command = shortcutCommands[key.Key]
+		if sc.command == "undo" && sc.modifiers&input.ModifierShift != 0 {
+			sc.command = "redo"
+		}
+	}
+	return sc, nil
+}
+
+// events returns the key events pressing the shortcut. Printable keys type their text only without Ctrl, Alt or Meta.
+func (sc shortcut) events() []*input.DispatchKeyEventParams {
+	down := input.DispatchKeyEvent(input.KeyDown).
+		WithKey(sc.key.Key).
+		WithCode(sc.key.Code).
+		WithWindowsVirtualKeyCode(sc.key.Windows).
+		WithModifiers(sc.modifiers)
+	if runtime.GOOS != "darwin" {
+		down = down.WithNativeVirtualKeyCode(sc.key.Native)
+	}
+	up := *down
+	up.Type = input.KeyUp
+
+	if sc.command != "" {
+		down = down.WithCommands([]string{sc.command})
+	}
+	if sc.key.Print && sc.modifiers&^input.ModifierShift == 0 {
+		text := sc.key.Text
+		if sc.modifiers&input.ModifierShift != 0 {
+			text = strings.ToUpper(text)
+		}
+		down = down.WithText(text).WithUnmodifiedText(sc.key.Unmodified)
+	}
+	return []*input.DispatchKeyEventParams{down, &up}
+}
+
+// Shortcut presses a keyboard shortcut in the focused element of the page, such as "Ctrl+End" in a grid, "Ctrl+A" in
+// an editor or "Escape" in a dialog. Shortcuts are modifiers (Ctrl, Shift, Alt, Meta) and a key joined by "+", see
+// ShortcutOn to focus an element first.
+// Example:
+//
+//	err := nav.Shortcut("Ctrl+End")
+//	err = nav.Shortcut("Shift+Tab")
+func (nav *Navigator) Shortcut(keys string) error {
+	sc, err := parseShortcut(keys)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to press shortcut: %v\n", err)
+		return err
+	}
+
+	var actions []chromedp.Action
+	for _, event := range sc.events() {
+		actions = append(actions, event)
+	}
+	err = chromedp.Run(nav.Ctx, actions...)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to press shortcut %s: %v\n", keys, err)
+		return fmt.Errorf("error - failed to press shortcut %s: %w", keys, classifyError(err))
+	}
+	nav.Logger.Printf("Shortcut %s pressed\n", keys)
+	return nil
+}
+
+// ShortcutOn focuses the element with the given selector and presses a keyboard shortcut in it, see Shortcut.
+// Example:
+//
+//	err := nav.ShortcutOn("#grid", "Ctrl+Home")
+func (nav *Navigator) ShortcutOn(selector, keys string) error {
+	err := nav.focus(selector)
+	if err != nil {
+		return err
+	}
+	return nav.Shortcut(keys)
+}
+
+// TypeOver replaces the content of the element with the given selector, e.g. an input, a grid cell or a rich text
+// editor, by selecting it all with Ctrl+A, deleting it and typing value, as a user would where clicks alone can't
+// select or clear the content.
+// Example:
+//
+//	err := nav.TypeOver("#editor", "Sentença publicada")
+func (nav *Navigator) TypeOver(selector, value string) error {
+	err := nav.focus(selector)
+	if err != nil {
+		return err
+	}
+	err = nav.Shortcut("Ctrl+A")
+	if err != nil {
+		return err
+	}
+	err = nav.Shortcut("Backspace")
+	if err != nil {
+		return err
+	}
+
+	err = chromedp.Run(nav.Ctx, chromedp.KeyEvent(value))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to type over field: %v\n", err)
+		return fmt.Errorf("error - failed to type over field: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Typed over field with selector: %s\n", selector)
+	return nil
+}
+
+// focus waits for the element with the given selector and focuses it.
+func (nav *Navigator) focus(selector string) error {
+	selector = nav.firstMatch(selector, nav.Timeout)
+	err := nav.WaitForElement(selector, nav.Timeout)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed waiting for element: %v\n", err)
+		return fmt.Errorf("error - failed waiting for element: %w", classifyError(err))
+	}
+
+	err = chromedp.Run(nav.Ctx, chromedp.Focus(selector))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to focus element: %v\n", err)
+		return fmt.Errorf("error - failed to focus element: %w", classifyError(err))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package goSpider
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/input"
+)
+
+func TestParseShortcut(t *testing.T) {
+	tests := []struct {
+		shortcut  string
+		modifiers input.Modifier
+		key       string
+		command   string
+	}{
+		{"Ctrl+End", input.ModifierCtrl, "End", ""},
+		{"ctrl+a", input.ModifierCtrl, "a", "selectAll"},
+		{"Cmd+V", input.ModifierMeta, "v", "paste"},
+		{"Ctrl+Shift+Z", input.ModifierCtrl | input.ModifierShift, "z", "redo"},
+		{"Ctrl+Alt+A", input.ModifierCtrl | input.ModifierAlt, "a", ""},
+		{"Shift+Tab", input.ModifierShift, "Tab", ""},
+		{"Enter", 0, "Enter", ""},
+		{"esc", 0, "Escape", ""},
+		{"Ctrl + Down", input.ModifierCtrl, "ArrowDown", ""},
+		{"PageUp", 0, "PageUp", ""},
+		{"F5", 0, "F5", ""},
+		{"Space", 0, " ", ""},
+		{"Ctrl++", input.ModifierCtrl, "+", ""},
+	}
+	for _, test := range tests {
+		sc, err := parseShortcut(test.shortcut)
+		if err != nil {
+			t.Errorf("parseShortcut(%q) error: %v", test.shortcut, err)
+			continue
+		}
+		if sc.modifiers != test.modifiers || sc.key.Key != test.key || sc.command != test.command {
+			t.Errorf("parseShortcut(%q) = %v %q %q, expected %v %q %q", test.shortcut, sc.modifiers, sc.key.Key,
+				sc.command, test.modifiers, test.key, test.command)
+		}
+	}
+
+	for _, shortcut := range []string{"", "Ctrl+", "Hyperdrive+A", "Ctrl+Warp"} {
+		_, err := parseShortcut(shortcut)
+		if err == nil {
+			t.Errorf("Expected parseShortcut(%q) to fail", shortcut)
+		}
+	}
+}
+
+func TestShortcutEvents(t *testing.T) {
+	sc, _ := parseShortcut("Ctrl+A")
+	events := sc.events()
+	if len(events) != 2 || events[0].Type != input.KeyDown || events[1].Type != input.KeyUp {
+		t.Fatalf("Expected a key down and a key up, but got %+v", events)
+	}
+	if events[0].Text != "" || len(events[0].Commands) != 1 || events[1].Commands != nil {
+		t.Errorf("Expected Ctrl+A to select all without typing, but got %+v", events)
+	}
+
+	sc, _ = parseShortcut("Shift+A")
+	if text := sc.events()[0].Text; text != "A" {
+		t.Errorf("Expected Shift+A to type A, but got %q", text)
+	}
+}
+
+func TestTypeOver(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/clipboard.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	_, err = nav.EvaluateScript(`document.getElementById("editor").textContent = "old content"`)
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+
+	err = nav.TypeOver("#editor", "new content")
+	if err != nil {
+		t.Fatalf("TypeOver error: %v", err)
+	}
+	text, err := nav.EvaluateScript(`document.getElementById("editor").textContent`)
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	if text != "new content" {
+		t.Errorf("Expected the content to be replaced, but got %q", text)
+	}
+
+	err = nav.ShortcutOn("#editor", "Ctrl+Home")
+	if err != nil {
+		t.Errorf("ShortcutOn error: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultDuplicateDistance is the largest Hamming distance between the SimHashes of near-duplicate pages used when no
+// threshold is given.
+const DefaultDuplicateDistance = 3
+
+// SimHash returns the 64 bit SimHash of the text, computed from its word 3-shingles. Texts that differ only in a few
+// words have hashes that differ only in a few bits, see HammingDistance.
+// Example:
+//
+//	hash := goSpider.SimHash(goSpider.NormalizedText(pageSource))
+func SimHash(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle string) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	if len(words) < 3 {
+		add(strings.Join(words, " "))
+	}
+	for i := 0; i+3 <= len(words); i++ {
+		add(strings.Join(words[i:i+3], " "))
+	}
+
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash
+}
+
+// PageSimHash returns the SimHash of the visible text of the page, so markup differences such as session ids in links
+// or print view styles don't change it.
+func PageSimHash(pageSource *html.Node) uint64 {
+	return SimHash(NormalizedText(pageSource))
+}
+
+// HammingDistance returns the number of bits that differ between two hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// DuplicateDetector remembers the SimHashes of the pages seen and tells whether a new page is a near-duplicate of one of
+// them, e.g. to skip the print view or the session id variants of a page already crawled. It is safe for concurrent use.
+type DuplicateDetector struct {
+	Distance int // largest Hamming distance of near-duplicates, DefaultDuplicateDistance if not positive
+
+	mu     sync.Mutex
+	hashes []uint64
+	urls   []string
+}
+
+// NewDuplicateDetector creates a DuplicateDetector for near-duplicates up to distance bits apart.
+// Example:
+//
+//	detector := goSpider.NewDuplicateDetector(goSpider.DefaultDuplicateDistance)
+//	if original, duplicate := detector.Check(url, pageSource); duplicate {
+//		log.Printf("%s is a duplicate of %s", url, original)
+//	}
+func NewDuplicateDetector(distance int) *DuplicateDetector {
+	return &DuplicateDetector{Distance: distance}
+}
+
+// Check returns the URL of the page seen before that the page is a near-duplicate of. When it is not a duplicate, the
+// page is remembered and false is returned.
+func (d *DuplicateDetector) Check(url string, pageSource *html.Node) (string, bool) {
+	hash := PageSimHash(pageSource)
+	distance := d.Distance
+	if distance <= 0 {
+		distance = DefaultDuplicateDistance
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, seen := range d.hashes {
+		if HammingDistance(hash, seen) <= distance {
+			return d.urls[i], true
+		}
+	}
+	d.hashes = append(d.hashes, hash)
+	d.urls = append(d.urls, url)
+	return "", false
+}
+
+// DedupPageSources returns the results without the pages that are near-duplicates of an earlier one, up to distance
+// bits apart. Results with errors or without a page are kept.
+// Example:
+//
+//	results, err := goSpider.ParallelRequests(requests, 10, 0, nav.Fetch)
+//	results = goSpider.DedupPageSources(results, goSpider.DefaultDuplicateDistance)
+func DedupPageSources(results []PageSource, distance int) []PageSource {
+	detector := NewDuplicateDetector(distance)
+	var unique []PageSource
+	for _, result := range results {
+		if result.Error == nil && result.Page != nil {
+			if _, duplicate := detector.Check(result.Request, result.Page); duplicate {
+				continue
+			}
+		}
+		unique = append(unique, result)
+	}
+	return unique
+}
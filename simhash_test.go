@@ -0,0 +1,55 @@
+package goSpider
+
+import (
+	"golang.org/x/net/html"
+	"strings"
+	"testing"
+)
+
+const simHashArticle = `O Tribunal de Justiça do Estado de São Paulo julgou procedente o pedido formulado pelo autor na ação de
+cobrança, condenando o réu ao pagamento do valor de dez mil reais, acrescido de correção monetária desde o vencimento e juros
+de mora a partir da citação. As custas processuais e os honorários advocatícios, fixados em dez por cento do valor da
+condenação, serão suportados pelo réu. A decisão foi publicada no diário oficial e as partes foram intimadas para, querendo,
+interpor recurso no prazo legal de quinze dias úteis contados da intimação.`
+
+func TestSimHash(t *testing.T) {
+	variant := strings.Replace(simHashArticle, "dez mil reais", "onze mil reais", 1)
+	other := "Search results for the civil court of the district, listing the hearings scheduled for next week and the judges assigned to each case."
+
+	if distance := HammingDistance(SimHash(simHashArticle), SimHash(simHashArticle)); distance != 0 {
+		t.Errorf("Expected equal texts to have equal hashes, got distance %d", distance)
+	}
+	near := HammingDistance(SimHash(simHashArticle), SimHash(variant))
+	far := HammingDistance(SimHash(simHashArticle), SimHash(other))
+	if near > DefaultDuplicateDistance*3 || far <= near {
+		t.Errorf("Expected near-duplicates closer than different texts, got near %d and far %d", near, far)
+	}
+}
+
+func TestDedupPageSources(t *testing.T) {
+	parse := func(body string) *html.Node {
+		node, err := html.Parse(strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	page := parse(`<html><body><a href="/case?session=1">Case</a><p>` + simHashArticle + `</p></body></html>`)
+	printView := parse(`<html><head><style>a { display: none }</style></head><body><a href="/case?session=2">Case</a><div><p>` + simHashArticle + `</p></div></body></html>`)
+	other := parse(`<html><body><p>Search results for the civil court of the district, listing the hearings scheduled for next week.</p></body></html>`)
+
+	results := []PageSource{
+		{Page: page, Request: "/case?session=1"},
+		{Page: printView, Request: "/case/print?session=2"},
+		{Request: "/failed"},
+		{Page: other, Request: "/hearings"},
+	}
+	unique := DedupPageSources(results, DefaultDuplicateDistance)
+	var requests []string
+	for _, result := range unique {
+		requests = append(requests, result.Request)
+	}
+	if strings.Join(requests, ",") != "/case?session=1,/failed,/hearings" {
+		t.Errorf("Unexpected deduplicated results %v", requests)
+	}
+}
@@ -0,0 +1,80 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Record is an extracted record, by field name, as written to a Sink. Values are the JSON types: strings, float64
+// numbers, booleans, nil, []interface{} and map[string]interface{}.
+type Record map[string]interface{}
+
+// Sink receives the records extracted by a crawl, e.g. a spreadsheet, a queue or an HTTP endpoint.
+type Sink interface {
+	Write(records []Record) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(records []Record) error
+
+// Write calls f(records).
+func (f SinkFunc) Write(records []Record) error {
+	return f(records)
+}
+
+// RecordOf converts a struct, or any value encoding to a JSON object, to a Record with its JSON field names.
+// Example:
+//
+//	record, err := goSpider.RecordOf(lawsuit.Cover)
+//	err = sink.Write([]goSpider.Record{record})
+func RecordOf(value interface{}) (Record, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to encode record: %w", err)
+	}
+	var record Record
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return nil, fmt.Errorf("error - record is not an object: %w", err)
+	}
+	return record, nil
+}
+
+// recordFields returns the field names of the records in a stable order: the fields of each record sorted, in the
+// order the records introduce them.
+func recordFields(records []Record) []string {
+	var fields []string
+	seen := map[string]bool{}
+	for _, record := range records {
+		keys := make([]string, 0, len(record))
+		for key := range record {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			seen[key] = true
+			fields = append(fields, key)
+		}
+	}
+	return fields
+}
+
+// cellValue formats a record value for a table cell: strings as they are, nested values as JSON.
+func cellValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64, bool:
+		return fmt.Sprint(value)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,51 @@
+package goSpider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordOf(t *testing.T) {
+	type cover struct {
+		Class   string `json:"class"`
+		Value   float64
+		Lawyers []string `json:"lawyers,omitempty"`
+	}
+	record, err := RecordOf(cover{Class: "Procedimento Comum", Value: 1500.5, Lawyers: []string{"Ana"}})
+	if err != nil {
+		t.Fatalf("RecordOf error: %v", err)
+	}
+	want := Record{"class": "Procedimento Comum", "Value": 1500.5, "lawyers": []interface{}{"Ana"}}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("Expected %v, got %v", want, record)
+	}
+
+	_, err = RecordOf([]string{"not", "an", "object"})
+	if err == nil {
+		t.Errorf("Expected an error for a value that is not an object")
+	}
+}
+
+func TestRecordFields(t *testing.T) {
+	fields := recordFields([]Record{{"number": "1", "class": "A"}, {"number": "2", "judge": "B", "area": "C"}})
+	want := []string{"class", "number", "area", "judge"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected %v, got %v", want, fields)
+	}
+}
+
+func TestCellValue(t *testing.T) {
+	tests := map[string]interface{}{
+		"":               nil,
+		"text":           "text",
+		"1500.5":         1500.5,
+		"true":           true,
+		`["a","b"]`:      []interface{}{"a", "b"},
+		`{"name":"Ana"}`: map[string]interface{}{"name": "Ana"},
+	}
+	for want, value := range tests {
+		if got := cellValue(value); got != want {
+			t.Errorf("cellValue(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package goSpider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Storage stores the files produced by a crawl, such as downloaded images and manifests, by key. Keys are slash
+// separated paths, e.g. "images/3a7bd3e2.png".
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Exists(key string) (bool, error)
+}
+
+// DirStorage is a Storage that keeps the files in a local directory.
+type DirStorage struct {
+	Dir string
+}
+
+// NewDirStorage creates a DirStorage in dir, creating the directory if needed.
+// Example:
+//
+//	storage, err := goSpider.NewDirStorage("output")
+func NewDirStorage(dir string) (*DirStorage, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to create storage directory: %w", err)
+	}
+	return &DirStorage{Dir: dir}, nil
+}
+
+// Put writes data to the file of key, creating its directories. The content type is not kept.
+func (s *DirStorage) Put(key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error - failed to create directory: %w", err)
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get reads the file of key.
+func (s *DirStorage) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Exists reports whether the file of key exists.
+func (s *DirStorage) Exists(key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error - failed to stat %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// path returns the file of key, which can't escape the directory.
+func (s *DirStorage) path(key string) (string, error) {
+	clean := filepath.Clean(string(filepath.Separator) + filepath.FromSlash(key))
+	if clean == string(filepath.Separator) {
+		return "", fmt.Errorf("error - invalid storage key: %s", key)
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
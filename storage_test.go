@@ -0,0 +1,44 @@
+package goSpider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirStorage(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewDirStorage(dir)
+	if err != nil {
+		t.Fatalf("NewDirStorage error: %v", err)
+	}
+
+	err = storage.Put("images/a.png", []byte("data"), "image/png")
+	if err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+	data, err := storage.Get("images/a.png")
+	if err != nil || string(data) != "data" {
+		t.Errorf("Expected the stored data, got %q: %v", data, err)
+	}
+	exists, err := storage.Exists("images/a.png")
+	if err != nil || !exists {
+		t.Errorf("Expected the key to exist: %v", err)
+	}
+	exists, err = storage.Exists("images/b.png")
+	if err != nil || exists {
+		t.Errorf("Expected the key not to exist: %v", err)
+	}
+
+	// keys can't escape the directory
+	err = storage.Put("../escaped.txt", []byte("data"), "text/plain")
+	if err != nil {
+		t.Fatalf("Put error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.txt")); err != nil {
+		t.Errorf("Expected the file inside the storage directory: %v", err)
+	}
+	if err := storage.Put("", nil, ""); err == nil {
+		t.Errorf("Expected an error for an empty key")
+	}
+}
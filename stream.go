@@ -0,0 +1,131 @@
+package goSpider
+
+import (
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"io"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// PageSourceTruncated is the marker ending the page sources cut by StreamPageSource.
+const PageSourceTruncated = "<!-- goSpider: page source truncated -->"
+
+// pageSourceChunk is the number of characters of the page source read from the page at once.
+const pageSourceChunk = 1 << 18
+
+// pageSourceStreams numbers the page sources streamed, naming the page variables holding them.
+var pageSourceStreams int64
+
+// StreamPageSource returns a reader of the HTML of the current page that reads it from the browser in chunks, so a
+// pathological page of hundreds of megabytes doesn't have to be held in memory at once. When maxBytes is positive, the
+// HTML is cut after maxBytes bytes and ends with PageSourceTruncated. Close releases the copy of the HTML kept by the
+// page while it is read.
+// Example:
+//
+//	source, err := nav.StreamPageSource(20 << 20)
+//	defer source.Close()
+//	pageSource, err := htmlquery.Parse(source)
+func (nav *Navigator) StreamPageSource(maxBytes int64) (io.ReadCloser, error) {
+	nav.Logger.Println("Streaming the HTML content of the page")
+	_, err := nav.WaitPageLoad()
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("__goSpiderPageSource%d", atomic.AddInt64(&pageSourceStreams, 1))
+	var length int
+	err = chromedp.Run(nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(window[%s] = document.documentElement.outerHTML).length`, jsString(key)), &length),
+	)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to get page HTML: %v\n", err)
+		return nil, fmt.Errorf("error - failed to get page HTML: %w", classifyError(err))
+	}
+	if maxBytes <= 0 {
+		maxBytes = -1
+	}
+	return &pageSourceReader{nav: nav, key: key, length: length, remaining: maxBytes}, nil
+}
+
+// pageSourceReader reads a page source kept in a variable of the page.
+type pageSourceReader struct {
+	nav       *Navigator
+	key       string // the page variable holding the page source
+	length    int    // UTF-16 code units of the page source
+	offset    int
+	remaining int64 // bytes left before cutting the page source, negative for no limit
+	buffer    []byte
+	done      bool
+}
+
+// Read reads the page source, fetching the next chunk from the page when the previous one is read.
+func (r *pageSourceReader) Read(p []byte) (int, error) {
+	for len(r.buffer) == 0 {
+		if r.done || r.offset >= r.length {
+			return 0, io.EOF
+		}
+		err := r.fill()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+	return n, nil
+}
+
+// fill reads the next chunk of the page source, never splitting a surrogate pair, cutting it at the limit.
+func (r *pageSourceReader) fill() error {
+	var chunk struct {
+		Text string
+		End  int
+	}
+	err := chromedp.Run(r.nav.Ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function(source, start, end) {
+			var code = source.charCodeAt(end - 1);
+			if (end < source.length && code >= 0xD800 && code <= 0xDBFF) end--;
+			return {Text: source.slice(start, end), End: end};
+		})(window[%s], %d, %d)`, jsString(r.key), r.offset, r.offset+pageSourceChunk), &chunk),
+	)
+	if err != nil {
+		r.nav.Logger.Printf("Error - Failed to read page HTML: %v\n", err)
+		return fmt.Errorf("error - failed to read page HTML: %w", classifyError(err))
+	}
+	r.offset = chunk.End
+
+	r.buffer = []byte(chunk.Text)
+	if r.remaining < 0 {
+		return nil
+	}
+	if int64(len(r.buffer)) > r.remaining {
+		r.buffer = append(cutUTF8(r.buffer, int(r.remaining)), PageSourceTruncated...)
+		r.done = true
+		r.nav.Logger.Println("Page HTML truncated")
+		return nil
+	}
+	r.remaining -= int64(len(r.buffer))
+	return nil
+}
+
+// Close removes the page source from the page.
+func (r *pageSourceReader) Close() error {
+	r.done = true
+	r.buffer = nil
+	err := chromedp.Run(r.nav.Ctx, chromedp.Evaluate(fmt.Sprintf(`delete window[%s]`, jsString(r.key)), nil))
+	if err != nil {
+		return fmt.Errorf("error - failed to release page HTML: %w", classifyError(err))
+	}
+	return nil
+}
+
+// cutUTF8 returns the first n bytes of data at most, without splitting a character.
+func cutUTF8(data []byte, n int) []byte {
+	if n >= len(data) {
+		return data
+	}
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return data[:n:n]
+}
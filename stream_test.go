@@ -0,0 +1,66 @@
+package goSpider
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCutUTF8(t *testing.T) {
+	for _, test := range []struct {
+		data     string
+		n        int
+		expected string
+	}{
+		{"Petição", 10, "Petição"},
+		{"Petição", 5, "Peti"},
+		{"Petição", 6, "Petiç"},
+		{"Petição", 0, ""},
+	} {
+		if cut := string(cutUTF8([]byte(test.data), test.n)); cut != test.expected {
+			t.Errorf("Expected %q cut at %d to be %q, got %q", test.data, test.n, test.expected, cut)
+		}
+	}
+}
+
+func TestStreamPageSource(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	// a page larger than a chunk, with characters outside the BMP across the chunk boundaries
+	err = nav.ExecuteScript(`document.body.appendChild(document.createElement("pre")).textContent = "⚖️😀".repeat(200000)`)
+	if err != nil {
+		t.Fatalf("ExecuteScript error: %v", err)
+	}
+
+	source, err := nav.StreamPageSource(0)
+	if err != nil {
+		t.Fatalf("StreamPageSource error: %v", err)
+	}
+	data, err := io.ReadAll(source)
+	source.Close()
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if strings.Count(string(data), "😀") != 200000 || !strings.HasSuffix(string(data), "</html>") {
+		t.Errorf("Expected the whole page, got %d bytes", len(data))
+	}
+
+	source, err = nav.StreamPageSource(1000)
+	if err != nil {
+		t.Fatalf("StreamPageSource error: %v", err)
+	}
+	defer source.Close()
+	data, err = io.ReadAll(source)
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if len(data) > 1000+len(PageSourceTruncated) || !strings.HasSuffix(string(data), PageSourceTruncated) {
+		t.Errorf("Expected the page cut at 1000 bytes, got %d bytes", len(data))
+	}
+}
@@ -0,0 +1,81 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// NewTab opens a new tab in the browser of nav and returns an independent Navigator for it, with the same logger,
+// timeout, cookies, locators, cache, middleware and init scripts. A Navigator is not safe for concurrent use, since its
+// methods drive a single tab; goroutines that scrape in parallel should each use their own tab. Closing the returned
+// Navigator closes only the tab, while closing nav closes the browser and all its tabs.
+// Example:
+//
+//	var wg sync.WaitGroup
+//	for _, url := range urls {
+//		tab, err := nav.NewTab()
+//		if err != nil {
+//			return err
+//		}
+//		wg.Add(1)
+//		go func(tab *goSpider.Navigator, url string) {
+//			defer wg.Done()
+//			defer tab.Close()
+//			pageSource, err := tab.Fetch(url)
+//			...
+//		}(tab, url)
+//	}
+//	wg.Wait()
+func (nav *Navigator) NewTab() (*Navigator, error) {
+	// the browser must be running, otherwise the tab would start and own a browser of its own
+	err := chromedp.Run(nav.Ctx)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to start browser: %v\n", err)
+		return nil, fmt.Errorf("error - failed to start browser: %w", classifyError(err))
+	}
+
+	ctx, cancel := chromedp.NewContext(nav.Ctx)
+	err = chromedp.Run(ctx)
+	if err != nil {
+		cancel()
+		nav.Logger.Printf("Error - Failed to open new tab: %v\n", err)
+		return nil, fmt.Errorf("error - failed to open new tab: %w", classifyError(err))
+	}
+
+	tab, err := nav.child(ctx, cancel)
+	if err != nil {
+		return nil, err
+	}
+	nav.Logger.Println("New tab opened successfully")
+	return tab, nil
+}
+
+// child returns a Navigator for the page of ctx with the settings of nav, see NewTab and AttachWindow.
+func (nav *Navigator) child(ctx context.Context, cancel context.CancelFunc) (*Navigator, error) {
+	child := &Navigator{
+		Ctx:        ctx,
+		Cancel:     cancel,
+		Logger:     nav.Logger,
+		Timeout:    nav.Timeout,
+		Cookies:    append([]*network.Cookie(nil), nav.Cookies...),
+		Locators:   nav.Locators,
+		Cache:      nav.Cache,
+		Middleware: nav.Middleware,
+		intercept:  &interception{},
+		timeouts:   nav.timeouts,
+		proxyAuth:  nav.proxyAuth,
+	}
+	if child.proxyAuth != nil {
+		child.authenticateProxy()
+	}
+	for _, script := range nav.initScripts {
+		_, err := child.AddInitScript(script.source)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	return child, nil
+}
@@ -0,0 +1,56 @@
+package goSpider
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewTab(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+
+	pages := []string{"/test.html", "/elements.html", "/pagination.html"}
+	titles := make([]string, len(pages))
+	errs := make([]error, len(pages))
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		tab, err := nav.NewTab()
+		if err != nil {
+			t.Fatalf("NewTab error: %v", err)
+		}
+		wg.Add(1)
+		go func(i int, tab *Navigator, url string) {
+			defer wg.Done()
+			defer tab.Close()
+			err := tab.OpenURL(url)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			title, err := tab.EvaluateScript("document.title")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			titles[i], _ = title.(string)
+		}(i, tab, server.URL+page)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Tab %d error: %v", i, err)
+		}
+	}
+	if titles[0] == titles[1] || titles[1] == titles[2] {
+		t.Errorf("Expected each tab to keep its own page, got titles %v", titles)
+	}
+
+	// closing the tabs keeps the browser of nav running
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Errorf("OpenURL after closing tabs error: %v", err)
+	}
+}
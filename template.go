@@ -0,0 +1,164 @@
+package goSpider
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// TemplateFormatter is a Sink rendering records through Go templates, so results can be written in custom formats
+// such as HTML reports or fixed-width files. The record template is executed for each record, with the Record as dot,
+// and the summary template once on Close, with a TemplateSummary. Either template may be empty.
+//
+// Besides the builtin functions, the templates can call:
+//   - cell VALUE: the value as a table cell, nested values as JSON
+//   - pad WIDTH VALUE, padLeft WIDTH VALUE: the cell padded with spaces, or cut, to WIDTH characters
+//   - json VALUE: the value as JSON
+//   - join SEP LIST, upper TEXT, lower TEXT, trim TEXT
+type TemplateFormatter struct {
+	w       io.Writer
+	record  templateExecutor
+	summary templateExecutor
+	records []Record
+}
+
+// TemplateSummary is the data of the summary template of a TemplateFormatter.
+type TemplateSummary struct {
+	Records []Record // every record written
+	Fields  []string // the field names of the records, in a stable order
+	Count   int
+}
+
+// templateExecutor is a text or HTML template.
+type templateExecutor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// templateFuncs are the functions of the templates of a TemplateFormatter.
+var templateFuncs = map[string]interface{}{
+	"cell": cellValue,
+	"pad": func(width int, value interface{}) string {
+		return padCell(cellValue(value), width, false)
+	},
+	"padLeft": func(width int, value interface{}) string {
+		return padCell(cellValue(value), width, true)
+	},
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"join": func(sep string, list interface{}) string {
+		switch list := list.(type) {
+		case []string:
+			return strings.Join(list, sep)
+		case []interface{}:
+			cells := make([]string, len(list))
+			for i, value := range list {
+				cells[i] = cellValue(value)
+			}
+			return strings.Join(cells, sep)
+		}
+		return cellValue(list)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// NewTemplateFormatter creates a TemplateFormatter writing to w with text templates.
+// Example:
+//
+//	// a fixed-width file
+//	formatter, err := goSpider.NewTemplateFormatter(file,
+//		`{{pad 25 .number}}{{pad 40 .class}}{{padLeft 12 .value}}`+"\n", `TOTAL {{padLeft 6 .Count}}`+"\n")
+//	err = formatter.Write(records)
+//	err = formatter.Close()
+func NewTemplateFormatter(w io.Writer, recordTemplate, summaryTemplate string) (*TemplateFormatter, error) {
+	formatter := &TemplateFormatter{w: w}
+	if recordTemplate != "" {
+		t, err := template.New("record").Funcs(templateFuncs).Parse(recordTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid record template: %w", err)
+		}
+		formatter.record = t
+	}
+	if summaryTemplate != "" {
+		t, err := template.New("summary").Funcs(templateFuncs).Parse(summaryTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid summary template: %w", err)
+		}
+		formatter.summary = t
+	}
+	return formatter, nil
+}
+
+// NewHTMLTemplateFormatter creates a TemplateFormatter writing to w with HTML templates, which escape the values of the
+// records.
+// Example:
+//
+//	formatter, err := goSpider.NewHTMLTemplateFormatter(file, "", `<h1>{{.Count}} lawsuits</h1>
+//	<table>{{range .Records}}<tr><td>{{.number}}</td><td>{{.class}}</td></tr>{{end}}</table>`)
+func NewHTMLTemplateFormatter(w io.Writer, recordTemplate, summaryTemplate string) (*TemplateFormatter, error) {
+	formatter := &TemplateFormatter{w: w}
+	if recordTemplate != "" {
+		t, err := htmltemplate.New("record").Funcs(templateFuncs).Parse(recordTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid record template: %w", err)
+		}
+		formatter.record = t
+	}
+	if summaryTemplate != "" {
+		t, err := htmltemplate.New("summary").Funcs(templateFuncs).Parse(summaryTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("error - invalid summary template: %w", err)
+		}
+		formatter.summary = t
+	}
+	return formatter, nil
+}
+
+// Write renders the records with the record template, and keeps them for the summary template.
+func (f *TemplateFormatter) Write(records []Record) error {
+	for _, record := range records {
+		if f.record != nil {
+			err := f.record.Execute(f.w, record)
+			if err != nil {
+				return fmt.Errorf("error - failed to render record: %w", err)
+			}
+		}
+		if f.summary != nil {
+			f.records = append(f.records, record)
+		}
+	}
+	return nil
+}
+
+// Close renders the summary template with the records written. It doesn't close the writer.
+func (f *TemplateFormatter) Close() error {
+	if f.summary == nil {
+		return nil
+	}
+	summary := TemplateSummary{Records: f.records, Fields: recordFields(f.records), Count: len(f.records)}
+	err := f.summary.Execute(f.w, summary)
+	if err != nil {
+		return fmt.Errorf("error - failed to render summary: %w", err)
+	}
+	return nil
+}
+
+// padCell pads the text with spaces to width characters, on the left when left is set, or cuts it to width.
+func padCell(text string, width int, left bool) string {
+	n := utf8.RuneCountInString(text)
+	if n >= width {
+		return string([]rune(text)[:width])
+	}
+	padding := strings.Repeat(" ", width-n)
+	if left {
+		return padding + text
+	}
+	return text + padding
+}
@@ -0,0 +1,52 @@
+package goSpider
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	var output bytes.Buffer
+	formatter, err := NewTemplateFormatter(&output, `{{pad 8 .number}}|{{padLeft 6 .value}}|{{join "," .parties}}`+"\n",
+		`{{.Count}} records: {{join "," .Fields}}`+"\n")
+	if err != nil {
+		t.Fatalf("NewTemplateFormatter error: %v", err)
+	}
+	records := []Record{
+		{"number": "123", "value": 1500.5, "parties": []interface{}{"Ana", "Bia"}},
+		{"number": "123456789", "value": "7", "parties": []interface{}{}},
+	}
+	if err := formatter.Write(records); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if err := formatter.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	expected := "123     |1500.5|Ana,Bia\n" +
+		"12345678|     7|\n" +
+		"2 records: number,parties,value\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected output %q", output.String())
+	}
+
+	if _, err := NewTemplateFormatter(&output, "{{.number", ""); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
+
+func TestHTMLTemplateFormatter(t *testing.T) {
+	var output bytes.Buffer
+	formatter, err := NewHTMLTemplateFormatter(&output, "",
+		`<p>{{.Count}}</p>{{range .Records}}<td>{{.name}}</td><td>{{json .data}}</td>{{end}}`)
+	if err != nil {
+		t.Fatalf("NewHTMLTemplateFormatter error: %v", err)
+	}
+	formatter.Write([]Record{{"name": "<script>", "data": map[string]interface{}{"a": 1.0}}})
+	if err := formatter.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	expected := `<p>1</p><td>&lt;script&gt;</td><td>{&#34;a&#34;:1}</td>`
+	if output.String() != expected {
+		t.Errorf("Expected the values to be escaped, got %q", output.String())
+	}
+}
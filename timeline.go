@@ -0,0 +1,353 @@
+package goSpider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/DanielFillol/goSpider/htmlQuery"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimelineInterval is the interval between the snapshots of a timeline, see RecordTimeline.
+const DefaultTimelineInterval = time.Second
+
+// TimelineIndexName is the name of the index of a saved timeline, listing its snapshots.
+const TimelineIndexName = "timeline.json"
+
+// TimelineOptions configures RecordTimeline.
+type TimelineOptions struct {
+	Interval     time.Duration // between periodic snapshots; DefaultTimelineInterval if not positive
+	Screenshots  bool          // capture a screenshot with each snapshot
+	MaxSnapshots int           // snapshots kept, dropping the oldest; all if not positive
+}
+
+// TimelineSnapshot is the DOM of the page at a moment of a flow.
+type TimelineSnapshot struct {
+	Index          int       `json:"index"`
+	Time           time.Time `json:"time"`
+	Label          string    `json:"label,omitempty"` // the step marked with Mark, empty for periodic snapshots
+	URL            string    `json:"url"`
+	Title          string    `json:"title"`
+	HTMLFile       string    `json:"html"`
+	ScreenshotFile string    `json:"screenshot,omitempty"`
+	HTML           string    `json:"-"`
+	Screenshot     []byte    `json:"-"` // PNG
+}
+
+// Timeline is the sequence of snapshots of a flow recorded by RecordTimeline.
+type Timeline struct {
+	Started   time.Time          `json:"started"`
+	Snapshots []TimelineSnapshot `json:"snapshots"`
+}
+
+// TimelineRecorder records the snapshots of a Timeline, see RecordTimeline.
+type TimelineRecorder struct {
+	nav     *Navigator
+	options TimelineOptions
+	stop    context.CancelFunc
+	done    chan struct{}
+
+	mu       sync.Mutex
+	timeline Timeline
+	next     int
+	last     string // the HTML of the last snapshot
+}
+
+// RecordTimeline starts capturing the DOM of the page, and a screenshot when options.Screenshots is set, every
+// options.Interval while a flow runs, skipping the snapshots where the page didn't change. Mark captures a snapshot
+// labeled with a step of the flow. Stop returns the Timeline, which Save writes as a directory with a viewer to step
+// through the snapshots afterwards, e.g. to see why a selector didn't match at a step.
+// Example:
+//
+//	recorder, err := nav.RecordTimeline(goSpider.TimelineOptions{Interval: 500 * time.Millisecond, Screenshots: true})
+//	err = nav.OpenURL("https://esaj.tjsp.jus.br/cpopg/open.do")
+//	recorder.Mark("search page")
+//	err = nav.ClickButton("#botaoConsultarProcessos")
+//	recorder.Mark("results")
+//	timeline := recorder.Stop()
+//	err = timeline.Save("output/timeline")
+func (nav *Navigator) RecordTimeline(options TimelineOptions) (*TimelineRecorder, error) {
+	if options.Interval <= 0 {
+		options.Interval = DefaultTimelineInterval
+	}
+	ctx, cancel := context.WithCancel(nav.Ctx)
+	r := &TimelineRecorder{nav: nav, options: options, stop: cancel, done: make(chan struct{}),
+		timeline: Timeline{Started: time.Now()}}
+
+	err := r.capture("start", true)
+	if err != nil {
+		cancel()
+		nav.Logger.Printf("Error - Failed to record timeline: %v\n", err)
+		return nil, fmt.Errorf("error - failed to record timeline: %w", classifyError(err))
+	}
+	go r.run(ctx)
+	nav.Logger.Println("Recording timeline")
+	return r, nil
+}
+
+// run captures the periodic snapshots until ctx is done.
+func (r *TimelineRecorder) run(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a page navigating away can't be read; the next tick captures the new one
+			r.capture("", false)
+		}
+	}
+}
+
+// Mark captures a snapshot labeled with label, such as the step of the flow just done.
+// Example:
+//
+//	recorder.Mark("step 7: open lawsuit")
+func (r *TimelineRecorder) Mark(label string) error {
+	err := r.capture(label, true)
+	if err != nil {
+		r.nav.Logger.Printf("Error - Failed to capture timeline snapshot: %v\n", err)
+		return fmt.Errorf("error - failed to capture timeline snapshot: %w", classifyError(err))
+	}
+	return nil
+}
+
+// capture reads the page and adds its snapshot, unless the page is unchanged and always isn't set.
+func (r *TimelineRecorder) capture(label string, always bool) error {
+	var state struct {
+		URL   string
+		Title string
+		HTML  string
+	}
+	err := chromedp.Run(r.nav.Ctx, chromedp.Evaluate(
+		`({URL: location.href, Title: document.title, HTML: document.documentElement.outerHTML})`, &state))
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	unchanged := state.HTML == r.last
+	r.mu.Unlock()
+	if unchanged && !always {
+		return nil
+	}
+
+	var screenshot []byte
+	if r.options.Screenshots {
+		err = chromedp.Run(r.nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			screenshot, err = page.CaptureScreenshot().WithFormat(page.CaptureScreenshotFormatPng).Do(ctx)
+			return err
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	r.add(TimelineSnapshot{Time: time.Now(), Label: label, URL: state.URL, Title: state.Title, HTML: state.HTML,
+		Screenshot: screenshot})
+	return nil
+}
+
+// add appends snapshot to the timeline, dropping the oldest snapshot past MaxSnapshots.
+func (r *TimelineRecorder) add(snapshot TimelineSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot.Index = r.next
+	r.next++
+	r.last = snapshot.HTML
+	r.timeline.Snapshots = append(r.timeline.Snapshots, snapshot)
+	if extra := len(r.timeline.Snapshots) - r.options.MaxSnapshots; r.options.MaxSnapshots > 0 && extra > 0 {
+		r.timeline.Snapshots = append([]TimelineSnapshot(nil), r.timeline.Snapshots[extra:]...)
+	}
+}
+
+// Timeline returns the timeline recorded so far.
+func (r *TimelineRecorder) Timeline() *Timeline {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Timeline{Started: r.timeline.Started, Snapshots: append([]TimelineSnapshot(nil), r.timeline.Snapshots...)}
+}
+
+// Stop stops recording, capturing a last snapshot, and returns the timeline.
+func (r *TimelineRecorder) Stop() *Timeline {
+	r.stop()
+	<-r.done
+	err := r.capture("stop", false)
+	if err != nil {
+		r.nav.Logger.Printf("Error - Failed to capture timeline snapshot: %v\n", err)
+	}
+	r.nav.Logger.Println("Stopped recording timeline")
+	return r.Timeline()
+}
+
+// Find returns the snapshots where xpath matches a node, to find when an element appeared or disappeared.
+// Example:
+//
+//	for _, snapshot := range timeline.Find("//table[@id='tabelaTodasMovimentacoes']") {
+//		fmt.Println(snapshot.Index, snapshot.Label, snapshot.URL)
+//	}
+func (t *Timeline) Find(xpath string) []TimelineSnapshot {
+	var found []TimelineSnapshot
+	for _, snapshot := range t.Snapshots {
+		pageSource, err := snapshot.Parse()
+		if err != nil {
+			continue
+		}
+		nodes, err := FindNodes(pageSource, xpath)
+		if err == nil && len(nodes) > 0 {
+			found = append(found, snapshot)
+		}
+	}
+	return found
+}
+
+// Parse parses the DOM of the snapshot, to run the selectors of a flow against it.
+func (s TimelineSnapshot) Parse() (*html.Node, error) {
+	return htmlquery.Parse(strings.NewReader(s.HTML))
+}
+
+// Save writes the timeline to dir: the HTML of each snapshot and its screenshot, TimelineIndexName listing them and
+// an index.html viewer stepping through them with the arrow keys. The snapshots are shown without their scripts.
+// Example:
+//
+//	err := recorder.Stop().Save("output/timeline")
+func (t *Timeline) Save(dir string) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error - failed to create timeline directory: %w", err)
+	}
+	saved := Timeline{Started: t.Started, Snapshots: make([]TimelineSnapshot, len(t.Snapshots))}
+	for i, snapshot := range t.Snapshots {
+		snapshot.HTMLFile = fmt.Sprintf("%04d.html", snapshot.Index)
+		err = os.WriteFile(filepath.Join(dir, snapshot.HTMLFile), []byte(snapshot.HTML), 0644)
+		if err != nil {
+			return fmt.Errorf("error - failed to save timeline snapshot: %w", err)
+		}
+		snapshot.ScreenshotFile = ""
+		if len(snapshot.Screenshot) > 0 {
+			snapshot.ScreenshotFile = fmt.Sprintf("%04d.png", snapshot.Index)
+			err = os.WriteFile(filepath.Join(dir, snapshot.ScreenshotFile), snapshot.Screenshot, 0644)
+			if err != nil {
+				return fmt.Errorf("error - failed to save timeline screenshot: %w", err)
+			}
+		}
+		saved.Snapshots[i] = snapshot
+	}
+
+	index, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error - failed to encode timeline: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, TimelineIndexName), index, 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to save timeline: %w", err)
+	}
+	// json.Marshal escapes "<", so the index can't close the script element of the viewer
+	err = os.WriteFile(filepath.Join(dir, "index.html"), []byte(strings.Replace(timelineViewer, "{{TIMELINE}}",
+		string(index), 1)), 0644)
+	if err != nil {
+		return fmt.Errorf("error - failed to save timeline viewer: %w", err)
+	}
+	return nil
+}
+
+// LoadTimeline reads a timeline saved by Save, with the HTML and screenshots of its snapshots.
+// Example:
+//
+//	timeline, err := goSpider.LoadTimeline("output/timeline")
+func LoadTimeline(dir string) (*Timeline, error) {
+	data, err := os.ReadFile(filepath.Join(dir, TimelineIndexName))
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to read timeline: %w", err)
+	}
+	var timeline Timeline
+	err = json.Unmarshal(data, &timeline)
+	if err != nil {
+		return nil, fmt.Errorf("error - failed to decode timeline: %w", err)
+	}
+	for i := range timeline.Snapshots {
+		snapshot := &timeline.Snapshots[i]
+		content, err := os.ReadFile(filepath.Join(dir, filepath.Base(snapshot.HTMLFile)))
+		if err != nil {
+			return nil, fmt.Errorf("error - failed to read timeline snapshot: %w", err)
+		}
+		snapshot.HTML = string(content)
+		if snapshot.ScreenshotFile != "" {
+			snapshot.Screenshot, err = os.ReadFile(filepath.Join(dir, filepath.Base(snapshot.ScreenshotFile)))
+			if err != nil {
+				return nil, fmt.Errorf("error - failed to read timeline screenshot: %w", err)
+			}
+		}
+	}
+	return &timeline, nil
+}
+
+// timelineViewer is the page stepping through a saved timeline, whose index replaces {{TIMELINE}}.
+const timelineViewer = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goSpider timeline</title>
+<style>
+	body { margin: 0; font-family: sans-serif; display: flex; height: 100vh; }
+	#steps { width: 280px; overflow-y: auto; border-right: 1px solid #ccc; margin: 0; padding: 0; list-style: none; }
+	#steps li { padding: 6px 8px; cursor: pointer; font-size: 13px; border-bottom: 1px solid #eee; }
+	#steps li.label { font-weight: bold; }
+	#steps li.current { background: #cde; }
+	#view { flex: 1; display: flex; flex-direction: column; }
+	#info { padding: 6px 8px; font-size: 13px; border-bottom: 1px solid #ccc; }
+	#panes { flex: 1; display: flex; }
+	#panes iframe, #panes div { flex: 1; border: 0; overflow: auto; }
+	#panes img { max-width: 100%; }
+</style>
+</head>
+<body>
+<ul id="steps"></ul>
+<div id="view">
+	<div id="info"></div>
+	<div id="panes"><iframe id="dom" sandbox></iframe><div id="shot"></div></div>
+</div>
+<script>
+var timeline = {{TIMELINE}};
+var current = 0;
+var steps = document.getElementById("steps");
+function show(i) {
+	if (i < 0 || i >= timeline.snapshots.length) return;
+	current = i;
+	var snapshot = timeline.snapshots[i];
+	Array.prototype.forEach.call(steps.children, function(li, j) { li.className = (j === i ? "current " : "") + (timeline.snapshots[j].label ? "label" : ""); });
+	steps.children[i].scrollIntoView({block: "nearest"});
+	document.getElementById("info").textContent = "#" + snapshot.index + " " + snapshot.time + " " + (snapshot.label || "") + " " + snapshot.url;
+	document.getElementById("dom").src = snapshot.html;
+	var shot = document.getElementById("shot");
+	shot.innerHTML = "";
+	shot.style.display = snapshot.screenshot ? "" : "none";
+	if (snapshot.screenshot) {
+		var img = document.createElement("img");
+		img.src = snapshot.screenshot;
+		shot.appendChild(img);
+	}
+}
+timeline.snapshots.forEach(function(snapshot, i) {
+	var li = document.createElement("li");
+	li.textContent = "#" + snapshot.index + " " + (snapshot.label || snapshot.title || snapshot.url);
+	li.onclick = function() { show(i); };
+	steps.appendChild(li);
+});
+document.addEventListener("keydown", function(e) {
+	if (e.key === "ArrowDown" || e.key === "ArrowRight") show(current + 1);
+	if (e.key === "ArrowUp" || e.key === "ArrowLeft") show(current - 1);
+});
+show(0);
+</script>
+</body>
+</html>
+`
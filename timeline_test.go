@@ -0,0 +1,87 @@
+package goSpider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordTimeline(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/dynamic.html?rows=5&delay=200")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	recorder, err := nav.RecordTimeline(TimelineOptions{Interval: 100 * time.Millisecond, Screenshots: true})
+	if err != nil {
+		t.Fatalf("RecordTimeline error: %v", err)
+	}
+	err = nav.WaitForElement("#done", 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForElement error: %v", err)
+	}
+	err = recorder.Mark("loaded")
+	if err != nil {
+		t.Fatalf("Mark error: %v", err)
+	}
+	timeline := recorder.Stop()
+
+	snapshots := timeline.Snapshots
+	if len(snapshots) < 3 || snapshots[0].Label != "start" || snapshots[len(snapshots)-1].Label != "loaded" {
+		t.Fatalf("Expected the start, periodic and marked snapshots, got %d", len(snapshots))
+	}
+	if len(snapshots[0].Screenshot) == 0 || !strings.Contains(snapshots[0].URL, "dynamic.html") {
+		t.Errorf("Expected the screenshot and URL of the snapshots, got %+v", snapshots[0].URL)
+	}
+	found := timeline.Find("//span[@id='done']")
+	if len(found) == 0 || found[0].Index == 0 {
+		t.Errorf("Expected the rows to be loaded after the start, got %d snapshots", len(found))
+	}
+}
+
+func TestTimelineSaveLoad(t *testing.T) {
+	r := &TimelineRecorder{options: TimelineOptions{MaxSnapshots: 2}}
+	r.add(TimelineSnapshot{Label: "start", URL: "https://example.com", HTML: "<html><body>empty</body></html>"})
+	r.add(TimelineSnapshot{URL: "https://example.com", HTML: "<html><body><table id='t'></table></body></html>",
+		Screenshot: []byte("png")})
+	r.add(TimelineSnapshot{Label: "results", URL: "https://example.com/results",
+		HTML: "<html><body><table id='t'><tr><td>1</td></tr></table><script>alert(1)</script></body></html>"})
+
+	timeline := r.Timeline()
+	if len(timeline.Snapshots) != 2 || timeline.Snapshots[0].Index != 1 || timeline.Snapshots[1].Label != "results" {
+		t.Fatalf("Expected the last 2 snapshots, got %+v", timeline.Snapshots)
+	}
+	if found := timeline.Find("//table[@id='t']//td"); len(found) != 1 || found[0].Index != 2 {
+		t.Errorf("Expected the last snapshot to match, got %+v", found)
+	}
+
+	dir := t.TempDir()
+	err := timeline.Save(dir)
+	if err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	for _, name := range []string{"0001.html", "0001.png", "0002.html", TimelineIndexName, "index.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be saved: %v", name, err)
+		}
+	}
+	viewer, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	if strings.Contains(string(viewer), "{{TIMELINE}}") || strings.Contains(string(viewer), "<script>alert") {
+		t.Errorf("Expected the index in the viewer, without the snapshots inlined")
+	}
+
+	loaded, err := LoadTimeline(dir)
+	if err != nil {
+		t.Fatalf("LoadTimeline error: %v", err)
+	}
+	if len(loaded.Snapshots) != 2 || loaded.Snapshots[1].HTML != timeline.Snapshots[1].HTML ||
+		string(loaded.Snapshots[0].Screenshot) != "png" || loaded.Snapshots[1].ScreenshotFile != "" {
+		t.Errorf("Expected the saved snapshots, got %+v", loaded.Snapshots)
+	}
+}
@@ -0,0 +1,78 @@
+package goSpider
+
+import (
+	"context"
+	"github.com/chromedp/chromedp"
+	"time"
+)
+
+// Default timeouts of the operations of a Navigator other than element waits, see Timeouts.
+const (
+	DefaultNavigationTimeout = time.Minute
+	DefaultScriptTimeout     = 30 * time.Second
+)
+
+// Timeouts are the timeouts of a Navigator by class of operation, as a page load takes far longer than an element to
+// show up. Zero timeouts are left unchanged by SetTimeouts.
+type Timeouts struct {
+	Navigation time.Duration // loading pages: OpenURL, ReloadPage, WaitPageLoad and Paginate; DefaultNavigationTimeout
+	Element    time.Duration // waiting for elements: the Timeout of the Navigator, see SetTimeOut; DefaultTimeout
+	Script     time.Duration // evaluating scripts: ExecuteScript and EvaluateScript; DefaultScriptTimeout
+	Download   time.Duration // downloads without a timeout of their own, and FetchFile; DefaultDownloadTimeout
+}
+
+// SetTimeouts sets the timeouts of nav by class of operation, leaving the zero ones unchanged.
+// Example:
+//
+//	nav.SetTimeouts(goSpider.Timeouts{Navigation: 2 * time.Minute, Element: 5 * time.Second})
+func (nav *Navigator) SetTimeouts(timeouts Timeouts) {
+	if timeouts.Element > 0 {
+		nav.Timeout = timeouts.Element
+	}
+	if timeouts.Navigation > 0 {
+		nav.timeouts.Navigation = timeouts.Navigation
+	}
+	if timeouts.Script > 0 {
+		nav.timeouts.Script = timeouts.Script
+	}
+	if timeouts.Download > 0 {
+		nav.timeouts.Download = timeouts.Download
+	}
+}
+
+// GetTimeouts returns the timeouts of nav by class of operation, with the defaults of the ones never set.
+func (nav *Navigator) GetTimeouts() Timeouts {
+	timeouts := nav.timeouts
+	timeouts.Element = nav.Timeout
+	if timeouts.Navigation <= 0 {
+		timeouts.Navigation = DefaultNavigationTimeout
+	}
+	if timeouts.Script <= 0 {
+		timeouts.Script = DefaultScriptTimeout
+	}
+	if timeouts.Download <= 0 {
+		timeouts.Download = DefaultDownloadTimeout
+	}
+	return timeouts
+}
+
+// WithTimeouts returns a copy of nav, driving the same tab, with the non-zero timeouts overridden, for calls slower or
+// faster than usual. The copy is meant for the call only: settings changed through it aren't seen by nav.
+// Example:
+//
+//	err := nav.WithTimeouts(goSpider.Timeouts{Navigation: 5 * time.Minute}).OpenURL(reportURL)
+func (nav *Navigator) WithTimeouts(timeouts Timeouts) *Navigator {
+	override := *nav
+	override.SetTimeouts(timeouts)
+	return &override
+}
+
+// withTimeout runs actions with a timeout. Run it on nav.Ctx rather than on a context with the timeout: chromedp starts
+// Chrome on the first Run of a Navigator, bound to the context of that Run, so the deadline is only set inside it.
+func withTimeout(timeout time.Duration, actions ...chromedp.Action) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return chromedp.Tasks(actions).Do(ctx)
+	})
+}
@@ -0,0 +1,69 @@
+package goSpider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestTimeouts(t *testing.T) {
+	nav := &Navigator{Timeout: DefaultTimeout}
+	expected := Timeouts{
+		Navigation: DefaultNavigationTimeout,
+		Element:    DefaultTimeout,
+		Script:     DefaultScriptTimeout,
+		Download:   DefaultDownloadTimeout,
+	}
+	if timeouts := nav.GetTimeouts(); timeouts != expected {
+		t.Errorf("Expected the default timeouts, got %+v", timeouts)
+	}
+
+	// zero timeouts are left unchanged
+	nav.SetTimeouts(Timeouts{Navigation: 2 * time.Minute, Element: 5 * time.Second})
+	expected.Navigation, expected.Element = 2*time.Minute, 5*time.Second
+	if timeouts := nav.GetTimeouts(); timeouts != expected || nav.Timeout != 5*time.Second {
+		t.Errorf("Expected the navigation and element timeouts to be set, got %+v", timeouts)
+	}
+
+	override := nav.WithTimeouts(Timeouts{Script: time.Minute})
+	if override.GetTimeouts().Script != time.Minute || override.GetTimeouts().Navigation != 2*time.Minute {
+		t.Errorf("Expected the script timeout overridden, got %+v", override.GetTimeouts())
+	}
+	if nav.GetTimeouts() != expected {
+		t.Errorf("Expected the timeouts of the navigator to be unchanged, got %+v", nav.GetTimeouts())
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := withTimeout(time.Millisecond, chromedp.ActionFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})).Do(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline of the action to be exceeded, but got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("Expected the context running the action to outlive its timeout")
+	}
+}
+
+func TestOpenURLTwice(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	// the first call starts the browser, which must outlive the timeout of the call
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	err = nav.OpenURL(server.URL + "/elements.html")
+	if err != nil {
+		t.Errorf("Expected the browser to be still running for a second OpenURL, but got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package goSpider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SelectorSpec is a selector used by a crawler and what it must match on a page, checked by Validate.
+type SelectorSpec struct {
+	Name     string // a label for the report, e.g. "search button"
+	URL      string // the page the selector is used on; the current page if empty
+	Selector string // a css selector, xpath or selector chain, see Selectors
+	Unique   bool   // exactly one element must match, e.g. a form field
+	Visible  bool   // the first matching element must be visible, e.g. a button to click
+	Optional bool   // no element may match, e.g. a captcha shown only sometimes
+}
+
+// SelectorResult is the result of checking a SelectorSpec.
+type SelectorResult struct {
+	Spec     SelectorSpec
+	Matched  string   // the alternative of the selector chain that matched, empty if none
+	Fallback bool     // whether the alternative that matched is not the first one of the chain
+	Count    int      // the number of elements matching
+	Visible  bool     // whether the first matching element is visible
+	Problems []string // why the selector failed, empty if it passed
+}
+
+// OK reports whether the selector passed every check.
+func (r SelectorResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// SelectorReport is the result of Validate, in the order of the specs.
+type SelectorReport struct {
+	Results []SelectorResult
+}
+
+// OK reports whether every selector passed.
+func (r SelectorReport) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of the selectors that failed.
+func (r SelectorReport) Failed() []SelectorResult {
+	var failed []SelectorResult
+	for _, result := range r.Results {
+		if !result.OK() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// String formats the report with a line per selector.
+func (r SelectorReport) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		status := "OK  "
+		if !result.OK() {
+			status = "FAIL"
+		}
+		name := result.Spec.Name
+		if name == "" {
+			name = result.Spec.Selector
+		}
+		fmt.Fprintf(&b, "%s %s: %d match", status, name, result.Count)
+		if result.Count != 1 {
+			b.WriteString("es")
+		}
+		if result.Fallback {
+			fmt.Fprintf(&b, " with fallback %s", result.Matched)
+		}
+		if !result.OK() {
+			fmt.Fprintf(&b, " (%s)", strings.Join(result.Problems, ", "))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d of %d selectors passed\n", len(r.Results)-len(r.Failed()), len(r.Results))
+	return b.String()
+}
+
+// Validate loads the page of each spec and checks that its selector matches, is unique and is visible as required,
+// without running the crawl. Pages are loaded once for consecutive specs of the same URL. Required selectors are waited
+// for up to the timeout of nav, so they may render after the page loads. Running it after a site redesign reports the
+// broken selectors at once, before a full crawl wastes hours failing on them. Only failures to load a page are errors.
+// Example:
+//
+//	report, err := goSpider.Validate(nav, []goSpider.SelectorSpec{
+//		{Name: "number field", URL: searchURL, Selector: "#numeroDigitoAnoUnificado", Unique: true, Visible: true},
+//		{Name: "search button", URL: searchURL, Selector: "#botaoConsultarProcessos", Visible: true},
+//		{Name: "captcha", URL: searchURL, Selector: "#captcha", Optional: true},
+//	})
+//	if err == nil && !report.OK() {
+//		log.Print(report)
+//	}
+func Validate(nav Browser, specs []SelectorSpec) (SelectorReport, error) {
+	var report SelectorReport
+	loaded := ""
+	for _, spec := range specs {
+		if spec.URL != "" && spec.URL != loaded {
+			err := nav.OpenURL(spec.URL)
+			if err != nil {
+				return report, fmt.Errorf("error - failed to load %s: %w", spec.URL, err)
+			}
+			loaded = spec.URL
+		}
+		report.Results = append(report.Results, validateSelector(nav, spec))
+	}
+	return report, nil
+}
+
+// validateSelector checks a selector on the current page.
+func validateSelector(nav Browser, spec SelectorSpec) SelectorResult {
+	result := SelectorResult{Spec: spec}
+	if !spec.Optional {
+		// a missing element is reported below
+		_ = nav.WaitForNode(spec.Selector, nav.GetTimeOut())
+	}
+
+	for i, alternative := range SplitSelectors(spec.Selector) {
+		count, err := nav.CountElements(alternative)
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("invalid selector %s: %v", alternative, err))
+			continue
+		}
+		if count > 0 {
+			result.Matched, result.Fallback, result.Count = alternative, i > 0, count
+			break
+		}
+	}
+
+	if result.Count == 0 {
+		if !spec.Optional {
+			result.Problems = append(result.Problems, "no element matched")
+		}
+		return result
+	}
+	if spec.Unique && result.Count > 1 {
+		result.Problems = append(result.Problems, fmt.Sprintf("%d elements matched instead of one", result.Count))
+	}
+	box, err := nav.GetElementBox(result.Matched)
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("failed to check visibility: %v", err))
+		return result
+	}
+	result.Visible = box.Visible
+	if spec.Visible && !box.Visible {
+		result.Problems = append(result.Problems, "not visible")
+	}
+	return result
+}
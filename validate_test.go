@@ -0,0 +1,116 @@
+package goSpider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pageBrowser is a Browser whose pages are maps of selectors to the number of elements they match; selectors starting
+// with "hidden" match hidden elements.
+type pageBrowser struct {
+	Browser
+	pages  map[string]map[string]int
+	page   string
+	opened []string
+}
+
+func (b *pageBrowser) OpenURL(url string) error {
+	if _, ok := b.pages[url]; !ok {
+		return errors.New("net::ERR_NAME_NOT_RESOLVED")
+	}
+	b.page = url
+	b.opened = append(b.opened, url)
+	return nil
+}
+
+func (b *pageBrowser) GetTimeOut() time.Duration { return 0 }
+
+func (b *pageBrowser) WaitForNode(selector string, timeout time.Duration) error { return nil }
+
+func (b *pageBrowser) CountElements(selector string) (int, error) {
+	if strings.HasPrefix(selector, "//[") {
+		return 0, errors.New("invalid xpath")
+	}
+	return b.pages[b.page][selector], nil
+}
+
+func (b *pageBrowser) GetElementBox(selector string) (ElementBox, error) {
+	return ElementBox{Visible: !strings.HasPrefix(selector, "hidden")}, nil
+}
+
+func TestValidate(t *testing.T) {
+	nav := &pageBrowser{pages: map[string]map[string]int{
+		"https://example.com/search":  {"#number": 1, "#search": 1, "input": 3, "hiddenButton": 1},
+		"https://example.com/results": {"#table tr": 10, ".new-table tr": 5},
+	}}
+	specs := []SelectorSpec{
+		{Name: "number", URL: "https://example.com/search", Selector: "#number", Unique: true, Visible: true},
+		{Name: "inputs", URL: "https://example.com/search", Selector: "input", Unique: true},
+		{Name: "button", URL: "https://example.com/search", Selector: "hiddenButton", Visible: true},
+		{Name: "captcha", URL: "https://example.com/search", Selector: "#captcha", Optional: true},
+		{Name: "rows", URL: "https://example.com/results", Selector: Selectors("#old-table tr", ".new-table tr")},
+		{Name: "links", Selector: "a.details"},
+		{Name: "broken", Selector: "//[bad"},
+	}
+
+	report, err := Validate(nav, specs)
+	if err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if len(nav.opened) != 2 {
+		t.Errorf("Expected each page to be loaded once, got %v", nav.opened)
+	}
+
+	ok := map[string]bool{"number": true, "inputs": false, "button": false, "captcha": true, "rows": true,
+		"links": false, "broken": false}
+	for _, result := range report.Results {
+		if result.OK() != ok[result.Spec.Name] {
+			t.Errorf("Expected %s OK to be %v, got problems %v", result.Spec.Name, ok[result.Spec.Name], result.Problems)
+		}
+	}
+	rows := report.Results[4]
+	if !rows.Fallback || rows.Matched != ".new-table tr" || rows.Count != 5 {
+		t.Errorf("Expected the fallback alternative to match, got %+v", rows)
+	}
+	if report.OK() || len(report.Failed()) != 4 {
+		t.Errorf("Expected 4 failed selectors, got %d", len(report.Failed()))
+	}
+
+	text := report.String()
+	for _, line := range []string{
+		"OK   number: 1 match\n",
+		"FAIL inputs: 3 matches (3 elements matched instead of one)\n",
+		"FAIL button: 1 match (not visible)\n",
+		"OK   rows: 5 matches with fallback .new-table tr\n",
+		"FAIL links: 0 matches (no element matched)\n",
+		"3 of 7 selectors passed\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", line, text)
+		}
+	}
+
+	_, err = Validate(nav, []SelectorSpec{{URL: "https://example.com/missing", Selector: "#x"}})
+	if err == nil {
+		t.Errorf("Expected an error for a page that fails to load")
+	}
+}
+
+func TestValidateNavigator(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	report, err := Validate(nav, []SelectorSpec{
+		{Name: "heading", URL: server.URL + "/test.html", Selector: "h1", Unique: true, Visible: true},
+		{Name: "missing", URL: server.URL + "/test.html", Selector: "#missing"},
+	})
+	if err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if !report.Results[0].OK() || report.Results[1].OK() {
+		t.Errorf("Expected only the heading to pass:\n%s", report)
+	}
+}
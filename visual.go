@@ -0,0 +1,211 @@
+package goSpider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/chromedp/chromedp"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// DefaultPixelTolerance is the color distance, from 0 to 1, under which two pixels are considered equal by
+// CompareScreenshots, so antialiasing and compression noise are not reported as differences.
+const DefaultPixelTolerance = 0.1
+
+// ScreenshotDiff is the result of comparing two screenshots.
+type ScreenshotDiff struct {
+	Width, Height      int     // the size of the compared area, the largest of both images
+	DiffPixels         int     // the number of pixels whose color differs
+	DiffRatio          float64 // DiffPixels over the number of pixels of the compared area
+	PerceptualDistance int     // the Hamming distance between the perceptual hashes of the images, from 0 to 64
+	Match              bool    // whether DiffRatio is at most the threshold
+	Image              []byte  // a PNG of the first image faded, with the differing pixels in red
+}
+
+// CompareScreenshots compares two PNG or JPEG screenshots pixel by pixel. Pixels whose color distance is above
+// DefaultPixelTolerance differ, as do pixels outside one of the images when their sizes differ. The screenshots match
+// when the ratio of differing pixels is at most threshold, e.g. 0.01 to allow 1% of the page to change. The perceptual
+// distance tells how different the images look overall: layout changes raise it, small text changes barely do.
+// Example:
+//
+//	diff, err := goSpider.CompareScreenshots(baseline, current, 0.01)
+//	if !diff.Match {
+//		err = os.WriteFile("diff.png", diff.Image, 0644)
+//	}
+func CompareScreenshots(a, b []byte, threshold float64) (ScreenshotDiff, error) {
+	imageA, _, err := image.Decode(bytes.NewReader(a))
+	if err != nil {
+		return ScreenshotDiff{}, fmt.Errorf("error - failed to decode first screenshot: %w", err)
+	}
+	imageB, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return ScreenshotDiff{}, fmt.Errorf("error - failed to decode second screenshot: %w", err)
+	}
+
+	boundsA, boundsB := imageA.Bounds(), imageB.Bounds()
+	width, height := boundsA.Dx(), boundsA.Dy()
+	if boundsB.Dx() > width {
+		width = boundsB.Dx()
+	}
+	if boundsB.Dy() > height {
+		height = boundsB.Dy()
+	}
+	diff := ScreenshotDiff{
+		Width:              width,
+		Height:             height,
+		PerceptualDistance: HammingDistance(perceptualHash(imageA), perceptualHash(imageB)),
+	}
+
+	output := image.NewRGBA(image.Rect(0, 0, width, height))
+	red := color.RGBA{R: 255, A: 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pointA := image.Pt(boundsA.Min.X+x, boundsA.Min.Y+y)
+			pointB := image.Pt(boundsB.Min.X+x, boundsB.Min.Y+y)
+			inA, inB := pointA.In(boundsA), pointB.In(boundsB)
+			if !inA || !inB || colorDistance(imageA.At(pointA.X, pointA.Y), imageB.At(pointB.X, pointB.Y)) > DefaultPixelTolerance {
+				diff.DiffPixels++
+				output.Set(x, y, red)
+				continue
+			}
+			// fade the unchanged pixels, so the red ones stand out
+			gray := color.GrayModel.Convert(imageA.At(pointA.X, pointA.Y)).(color.Gray)
+			faded := 255 - (255-gray.Y)/4
+			output.Set(x, y, color.RGBA{R: faded, G: faded, B: faded, A: 255})
+		}
+	}
+	if width*height > 0 {
+		diff.DiffRatio = float64(diff.DiffPixels) / float64(width*height)
+	}
+	diff.Match = diff.DiffRatio <= threshold
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, output)
+	if err != nil {
+		return diff, fmt.Errorf("error - failed to encode diff image: %w", err)
+	}
+	diff.Image = buf.Bytes()
+	return diff, nil
+}
+
+// CompareWithBaseline captures a screenshot of the page and compares it with the baseline PNG at path, see
+// CompareScreenshots. When the baseline doesn't exist yet, the screenshot is saved as the baseline and matches. When
+// it doesn't match, the diff image is saved next to the baseline with the suffix "_diff.png", and the screenshot with
+// "_current.png"; to accept a change, replace the baseline with the current screenshot.
+// Example:
+//
+//	diff, err := nav.CompareWithBaseline("testdata/search_page.png", 0.01)
+//	if err == nil && !diff.Match {
+//		log.Printf("The search page changed: %.1f%% of the pixels differ", diff.DiffRatio*100)
+//	}
+func (nav *Navigator) CompareWithBaseline(path string, threshold float64) (ScreenshotDiff, error) {
+	var current []byte
+	err := chromedp.Run(nav.Ctx, chromedp.CaptureScreenshot(&current))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to capture screenshot: %v\n", err)
+		return ScreenshotDiff{}, fmt.Errorf("error - failed to capture screenshot: %w", classifyError(err))
+	}
+
+	baseline, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.WriteFile(path, current, 0644)
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to save baseline: %v\n", err)
+			return ScreenshotDiff{}, fmt.Errorf("error - failed to save baseline: %w", err)
+		}
+		nav.Logger.Printf("Baseline saved: %s\n", path)
+		return CompareScreenshots(current, current, threshold)
+	}
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to read baseline: %v\n", err)
+		return ScreenshotDiff{}, fmt.Errorf("error - failed to read baseline: %w", err)
+	}
+
+	diff, err := CompareScreenshots(baseline, current, threshold)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to compare screenshots: %v\n", err)
+		return diff, err
+	}
+	if !diff.Match {
+		name := strings.TrimSuffix(path, ".png")
+		err = os.WriteFile(name+"_diff.png", diff.Image, 0644)
+		if err == nil {
+			err = os.WriteFile(name+"_current.png", current, 0644)
+		}
+		if err != nil {
+			nav.Logger.Printf("Error - Failed to save diff image: %v\n", err)
+			return diff, fmt.Errorf("error - failed to save diff image: %w", err)
+		}
+		nav.Logger.Printf("Screenshot differs from baseline %s: %d pixels (%.2f%%)\n", path, diff.DiffPixels, diff.DiffRatio*100)
+	}
+	return diff, nil
+}
+
+// colorDistance returns the distance between two colors, from 0 for equal colors to 1 for black and white.
+func colorDistance(a, b color.Color) float64 {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	distance := 0.0
+	for _, d := range []float64{
+		float64(r1) - float64(r2), float64(g1) - float64(g2), float64(b1) - float64(b2), float64(a1) - float64(a2),
+	} {
+		if d < 0 {
+			d = -d
+		}
+		if d > distance {
+			distance = d
+		}
+	}
+	return distance / 0xffff
+}
+
+// perceptualHash returns the difference hash of the image: the image is reduced to 9x8 gray cells, and each bit tells
+// whether a cell is brighter than its right neighbour. Similar looking images have hashes that differ in a few bits.
+func perceptualHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0
+	}
+	var cells [8][9]float64
+	for row := 0; row < 8; row++ {
+		for column := 0; column < 9; column++ {
+			// average the pixels of the cell, sampling at most 16x16 of them
+			x0 := bounds.Min.X + column*bounds.Dx()/9
+			x1 := bounds.Min.X + (column+1)*bounds.Dx()/9
+			y0 := bounds.Min.Y + row*bounds.Dy()/8
+			y1 := bounds.Min.Y + (row+1)*bounds.Dy()/8
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			if y1 <= y0 {
+				y1 = y0 + 1
+			}
+			stepX, stepY := (x1-x0+15)/16, (y1-y0+15)/16
+			sum, count := 0.0, 0
+			for y := y0; y < y1 && y < bounds.Max.Y; y += stepY {
+				for x := x0; x < x1 && x < bounds.Max.X; x += stepX {
+					sum += float64(color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y)
+					count++
+				}
+			}
+			if count > 0 {
+				cells[row][column] = sum / float64(count)
+			}
+		}
+	}
+
+	var hash uint64
+	for row := 0; row < 8; row++ {
+		for column := 0; column < 8; column++ {
+			if cells[row][column] > cells[row][column+1] {
+				hash |= 1 << uint(row*8+column)
+			}
+		}
+	}
+	return hash
+}
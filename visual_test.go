@@ -0,0 +1,140 @@
+package goSpider
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testScreenshot returns a PNG of a white image with a black rectangle.
+func testScreenshot(t *testing.T, width, height int, rect image.Rectangle) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if image.Pt(x, y).In(rect) {
+				c = color.RGBA{A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareScreenshots(t *testing.T) {
+	baseline := testScreenshot(t, 100, 50, image.Rect(10, 10, 30, 30))
+
+	diff, err := CompareScreenshots(baseline, baseline, 0)
+	if err != nil {
+		t.Fatalf("CompareScreenshots error: %v", err)
+	}
+	if !diff.Match || diff.DiffPixels != 0 || diff.PerceptualDistance != 0 || diff.Width != 100 || diff.Height != 50 {
+		t.Errorf("Expected equal screenshots to match: %+v", diff)
+	}
+
+	// the rectangle moves 10 pixels right: 2 strips of 10x20 pixels change
+	moved := testScreenshot(t, 100, 50, image.Rect(20, 10, 40, 30))
+	diff, err = CompareScreenshots(baseline, moved, 0.01)
+	if err != nil {
+		t.Fatalf("CompareScreenshots error: %v", err)
+	}
+	if diff.Match || diff.DiffPixels != 400 || diff.DiffRatio != 0.08 {
+		t.Errorf("Expected 400 differing pixels: %+v", diff)
+	}
+	if diff, _ = CompareScreenshots(baseline, moved, 0.1); !diff.Match {
+		t.Errorf("Expected the screenshots to match with a threshold of 10%%")
+	}
+
+	diffImage, err := png.Decode(bytes.NewReader(diff.Image))
+	if err != nil {
+		t.Fatalf("Expected a PNG diff image: %v", err)
+	}
+	if r, g, _, _ := diffImage.At(35, 20).RGBA(); r != 0xffff || g != 0 {
+		t.Errorf("Expected a changed pixel in red")
+	}
+	if r, g, _, _ := diffImage.At(5, 5).RGBA(); r != 0xffff || g != 0xffff {
+		t.Errorf("Expected an unchanged white pixel to stay white")
+	}
+}
+
+func TestCompareScreenshotsSizes(t *testing.T) {
+	diff, err := CompareScreenshots(testScreenshot(t, 100, 50, image.Rectangle{}), testScreenshot(t, 100, 60, image.Rectangle{}), 0)
+	if err != nil {
+		t.Fatalf("CompareScreenshots error: %v", err)
+	}
+	if diff.Height != 60 || diff.DiffPixels != 1000 || diff.Match {
+		t.Errorf("Expected the extra rows to differ: %+v", diff)
+	}
+
+	_, err = CompareScreenshots([]byte("not an image"), nil, 0)
+	if err == nil {
+		t.Errorf("Expected an error for invalid screenshots")
+	}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	baseline := testScreenshot(t, 180, 80, image.Rect(0, 0, 90, 80))
+	similar := testScreenshot(t, 180, 80, image.Rect(0, 0, 91, 80))
+	different := testScreenshot(t, 180, 80, image.Rect(90, 0, 180, 80))
+
+	near, err := CompareScreenshots(baseline, similar, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	far, err := CompareScreenshots(baseline, different, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if near.PerceptualDistance > 2 || far.PerceptualDistance <= near.PerceptualDistance {
+		t.Errorf("Expected a small distance for similar images, got %d and %d", near.PerceptualDistance, far.PerceptualDistance)
+	}
+}
+
+func TestCompareWithBaseline(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/test.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	baseline := filepath.Join(t.TempDir(), "test.png")
+	diff, err := nav.CompareWithBaseline(baseline, 0)
+	if err != nil {
+		t.Fatalf("CompareWithBaseline error: %v", err)
+	}
+	if !diff.Match {
+		t.Errorf("Expected the first screenshot to be saved as the baseline")
+	}
+	if _, err := os.Stat(baseline); err != nil {
+		t.Fatalf("Expected the baseline file: %v", err)
+	}
+
+	err = nav.ExecuteScript(`document.body.style.background = 'black'`)
+	if err != nil {
+		t.Fatalf("ExecuteScript error: %v", err)
+	}
+	diff, err = nav.CompareWithBaseline(baseline, 0.01)
+	if err != nil {
+		t.Fatalf("CompareWithBaseline error: %v", err)
+	}
+	if diff.Match {
+		t.Errorf("Expected the changed page not to match the baseline")
+	}
+	if _, err := os.Stat(strings.TrimSuffix(baseline, ".png") + "_diff.png"); err != nil {
+		t.Errorf("Expected the diff image: %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WaitForNode waits until a node matching the css selector or xpath is attached to the page. A MutationObserver installed
+// in the page resolves as soon as the DOM changes to match, instead of polling, so dynamic pages are handled with less
+// latency and CPU. Unlike WaitForElement, the node does not need to be visible.
+// Example:
+//
+//	err := nav.WaitForNode("#resultsTable > tbody > tr", 10*time.Second)
+func (nav *Navigator) WaitForNode(selector string, timeout time.Duration) error {
+	nav.Logger.Printf("Waiting for node with selector: %s\n", selector)
+
+	found, err := nav.waitForCondition(anyElementCondition(SplitSelectors(selector)), timeout)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to wait for node: %v\n", err)
+		return fmt.Errorf("error - failed to wait for node: %w", classifyError(err))
+	}
+	if !found {
+		nav.Logger.Printf("Error - Node not found within %v: %s\n", timeout, selector)
+		return withKind(ErrElementNotFound, fmt.Errorf("error - node not found within %v: %s", timeout, selector))
+	}
+
+	nav.Logger.Printf("Node is now attached with selector: %s\n", selector)
+	return nil
+}
+
+// CountMatch defines how WaitForSelectorCount compares the number of matching elements.
+type CountMatch int
+
+const (
+	// AtLeast waits for n or more matching elements.
+	AtLeast CountMatch = iota
+	// Exactly waits for exactly n matching elements.
+	Exactly
+)
+
+// WaitForSelectorCount waits until the number of elements matching the css selector or xpath is at least or exactly n,
+// so list pages are only considered loaded once the expected rows have rendered.
+// Example:
+//
+//	err := nav.WaitForSelectorCount("#resultsTable > tbody > tr", 20, goSpider.AtLeast, 10*time.Second)
+func (nav *Navigator) WaitForSelectorCount(selector string, n int, match CountMatch, timeout time.Duration) error {
+	nav.Logger.Printf("Waiting for %d elements with selector: %s\n", n, selector)
+
+	operator := ">="
+	if match == Exactly {
+		operator = "==="
+	}
+	var conditions []string
+	for _, alternative := range SplitSelectors(selector) {
+		conditions = append(conditions, fmt.Sprintf("%s.length %s %d", jsElements(alternative), operator, n))
+	}
+
+	met, err := nav.waitForCondition(strings.Join(conditions, " || "), timeout)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to wait for selector count: %v\n", err)
+		return fmt.Errorf("error - failed to wait for selector count: %w", classifyError(err))
+	}
+	if !met {
+		count, _ := nav.CountElements(selector)
+		nav.Logger.Printf("Error - Expected %d elements but found %d within %v: %s\n", n, count, timeout, selector)
+		return withKind(ErrTimeout, fmt.Errorf("error - expected %d elements but found %d within %v: %s", n, count, timeout, selector))
+	}
+
+	nav.Logger.Printf("Found %d elements with selector: %s\n", n, selector)
+	return nil
+}
+
+// WaitCondition is what ClickAndWait waits for. It is armed before the click, so events fired by the click itself are
+// not missed, and returns the function that blocks until the condition is met or ctx is done.
+type WaitCondition func(ctx context.Context, nav *Navigator) (wait func() error)
+
+// UntilNavigation waits for the main frame to navigate, including same-document navigations of single page apps,
+// and for the new document to load.
+func UntilNavigation() WaitCondition {
+	return func(ctx context.Context, nav *Navigator) func() error {
+		navigated := make(chan struct{}, 1)
+		loaded := make(chan struct{}, 1)
+		var mu sync.Mutex
+		started := false
+
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch ev := ev.(type) {
+			case *page.EventFrameNavigated:
+				if ev.Frame.ParentID == "" {
+					started = true
+				}
+			case *page.EventNavigatedWithinDocument:
+				select {
+				case navigated <- struct{}{}:
+				default:
+				}
+			case *page.EventLoadEventFired:
+				if started {
+					select {
+					case loaded <- struct{}{}:
+					default:
+					}
+				}
+			}
+		})
+
+		return func() error {
+			select {
+			case <-navigated:
+				return nil
+			case <-loaded:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("no navigation: %w", classifyError(ctx.Err()))
+			}
+		}
+	}
+}
+
+// UntilNetworkIdle waits until no request started after the click has been in flight for the idle duration,
+// 500ms if not positive.
+func UntilNetworkIdle(idle time.Duration) WaitCondition {
+	if idle <= 0 {
+		idle = 500 * time.Millisecond
+	}
+	return func(ctx context.Context, nav *Navigator) func() error {
+		var mu sync.Mutex
+		inFlight := map[network.RequestID]bool{}
+		lastActivity := time.Now()
+
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch ev := ev.(type) {
+			case *network.EventRequestWillBeSent:
+				inFlight[ev.RequestID] = true
+			case *network.EventLoadingFinished:
+				delete(inFlight, ev.RequestID)
+			case *network.EventLoadingFailed:
+				delete(inFlight, ev.RequestID)
+			default:
+				return
+			}
+			lastActivity = time.Now()
+		})
+
+		return func() error {
+			ticker := time.NewTicker(idle / 4)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					mu.Lock()
+					idleFor := time.Since(lastActivity)
+					pending := len(inFlight)
+					mu.Unlock()
+					if pending == 0 && idleFor >= idle {
+						return nil
+					}
+				case <-ctx.Done():
+					return fmt.Errorf("network not idle: %w", classifyError(ctx.Err()))
+				}
+			}
+		}
+	}
+}
+
+// UntilElement waits for an element matching the css selector or xpath to be visible.
+func UntilElement(selector string) WaitCondition {
+	return func(ctx context.Context, nav *Navigator) func() error {
+		return func() error {
+			deadline, _ := ctx.Deadline()
+			return nav.WaitForElement(selector, time.Until(deadline))
+		}
+	}
+}
+
+// ClickAndWait clicks the element and waits up to timeout for the condition, replacing the fixed sleep of ClickButton
+// with an explicit signal that the page has changed.
+// Example:
+//
+//	err := nav.ClickAndWait("#btnPesquisar", goSpider.UntilNavigation(), 10*time.Second)
+//	err = nav.ClickAndWait("#loadMore", goSpider.UntilNetworkIdle(500*time.Millisecond), 10*time.Second)
+//	err = nav.ClickAndWait("#btnConsultar", goSpider.UntilElement("#resultsTable"), 10*time.Second)
+func (nav *Navigator) ClickAndWait(selector string, condition WaitCondition, timeout time.Duration) error {
+	nav.Logger.Printf("Clicking element with selector: %s and waiting\n", selector)
+	selector = nav.firstMatch(selector, nav.Timeout)
+
+	err := nav.WaitForElement(selector, nav.Timeout)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(nav.Ctx, timeout)
+	defer cancel()
+	wait := condition(ctx, nav)
+
+	err = chromedp.Run(nav.Ctx, chromedp.Click(selector))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to click element: %v\n", err)
+		return fmt.Errorf("error - failed to click element: %w", classifyError(err))
+	}
+
+	err = wait()
+	if err != nil {
+		nav.Logger.Printf("Error - Failed waiting after click: %v\n", err)
+		return fmt.Errorf("error - failed waiting after click: %w", classifyError(err))
+	}
+
+	nav.Logger.Printf("Clicked and waited successfully with selector: %s\n", selector)
+	return nil
+}
+
+// anyElementCondition returns a JavaScript condition that is true when any of the selectors matches an element.
+func anyElementCondition(selectors []string) string {
+	conditions := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		conditions = append(conditions, jsElements(selector)+".length > 0")
+	}
+	return strings.Join(conditions, " || ")
+}
+
+// waitForCondition evaluates the JavaScript condition whenever the DOM changes, through a MutationObserver, until it is
+// true or timeout expires. It reports whether the condition became true; navigations during the wait are survived by
+// installing the observer again in the new document.
+func (nav *Navigator) waitForCondition(condition string, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		var met bool
+		err := chromedp.Run(nav.Ctx, withTimeout(remaining+time.Second,
+			chromedp.Evaluate(fmt.Sprintf(`new Promise(function(resolve) {
+				var check = function() {
+					try { return !!(%s); } catch (e) { return false; }
+				};
+				if (check()) return resolve(true);
+				var observer = new MutationObserver(function() {
+					if (check()) {
+						observer.disconnect();
+						clearTimeout(timer);
+						resolve(true);
+					}
+				});
+				observer.observe(document, {childList: true, subtree: true, attributes: true, characterData: true});
+				var timer = setTimeout(function() {
+					observer.disconnect();
+					resolve(check());
+				}, %d);
+			})`, condition, remaining.Milliseconds()), &met, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}),
+		))
+		if err == nil {
+			return met, nil
+		}
+		if !isContextDestroyed(err) || time.Now().After(deadline) {
+			return false, err
+		}
+		// the page navigated while waiting, wait for the new document and observe it again
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// isContextDestroyed reports whether a script failed because the page navigated while it ran.
+func isContextDestroyed(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "context was destroyed") || strings.Contains(message, "Cannot find context")
+}
@@ -0,0 +1,101 @@
+package goSpider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitForNode(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/dynamic.html?rows=3&delay=200")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	// #done is hidden, WaitForNode only needs it attached
+	start := time.Now()
+	err = nav.WaitForNode("#done", 10*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForNode error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected WaitForNode to resolve as soon as the node is attached, but took %v", elapsed)
+	}
+
+	err = nav.WaitForNode("#missingNode", 300*time.Millisecond)
+	if err == nil {
+		t.Errorf("Expected error for a node that never appears")
+	}
+}
+
+func TestWaitForSelectorCount(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/dynamic.html?rows=5&delay=100")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	err = nav.WaitForSelectorCount("#dynamicTable > tbody > tr", 3, AtLeast, 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForSelectorCount (at least) error: %v", err)
+	}
+
+	err = nav.WaitForSelectorCount("//*[@id=\"dynamicTable\"]/tbody/tr", 5, Exactly, 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForSelectorCount (exactly) error: %v", err)
+	}
+	count, err := nav.CountElements("#dynamicTable > tbody > tr")
+	if err != nil || count != 5 {
+		t.Errorf("Expected 5 rows, but got %d (%v)", count, err)
+	}
+
+	err = nav.WaitForSelectorCount("#dynamicTable > tbody > tr", 6, AtLeast, 500*time.Millisecond)
+	if err == nil {
+		t.Errorf("Expected error when the rows never reach the count")
+	}
+}
+
+func TestClickAndWait(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/navigation.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+
+	err = nav.ClickAndWait("#reveal", UntilElement("#revealed"), 5*time.Second)
+	if err != nil {
+		t.Fatalf("ClickAndWait (element) error: %v", err)
+	}
+
+	err = nav.ClickAndWait("#fetch", UntilNetworkIdle(500*time.Millisecond), 5*time.Second)
+	if err != nil {
+		t.Fatalf("ClickAndWait (network idle) error: %v", err)
+	}
+	result, err := nav.GetElement("#result")
+	if err != nil || result != "Initial petition text" {
+		t.Errorf("Expected the fetched text after network idle, but got %q (%v)", result, err)
+	}
+
+	err = nav.ClickAndWait("#navigate", UntilNavigation(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("ClickAndWait (navigation) error: %v", err)
+	}
+	url, err := nav.GetCurrentURL()
+	if err != nil || url != server.URL+"/dynamic.html?rows=1&delay=10" {
+		t.Errorf("Expected to navigate to dynamic.html, but got %s (%v)", url, err)
+	}
+
+	err = nav.ClickAndWait("#dynamicTable", UntilNavigation(), 500*time.Millisecond)
+	if err == nil {
+		t.Errorf("Expected error when the click does not navigate")
+	}
+}
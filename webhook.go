@@ -0,0 +1,125 @@
+package goSpider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultWebhookDiffLines is the number of changed lines sent in a change payload when ChangeWebhook.MaxDiffLines is
+// not set.
+const DefaultWebhookDiffLines = 20
+
+// ChangePayload is the JSON body posted by a ChangeWebhook.
+type ChangePayload struct {
+	URL              string    `json:"url"`
+	ChangedAt        time.Time `json:"changed_at"`
+	PreviousHash     string    `json:"previous_hash"`
+	CurrentHash      string    `json:"current_hash"`
+	Added            int       `json:"added"`
+	Removed          int       `json:"removed"`
+	Changes          []string  `json:"changes"` // the first changed lines, formatted like DiffLine.String
+	PreviousSnapshot string    `json:"previous_snapshot,omitempty"`
+	CurrentSnapshot  string    `json:"current_snapshot,omitempty"`
+}
+
+// ChangeWebhook posts a signed JSON payload to an endpoint for every change found by a Monitor. The body is signed with
+// HMAC-SHA256 and the signature sent in the X-GoSpider-Signature header as "sha256=<hex>", see WebhookSignature.
+type ChangeWebhook struct {
+	URL          string
+	Secret       string // the signing key; the signature header is not sent when empty
+	SnapshotURL  string // base URL where the Monitor Storage is served, for the snapshot links; no links when empty
+	MaxDiffLines int    // changed lines sent, DefaultWebhookDiffLines if not positive
+	Client       *http.Client
+}
+
+// NewChangeWebhook creates a ChangeWebhook posting to url, signed with secret.
+// Example:
+//
+//	monitor := goSpider.NewMonitor(fetcher, time.Hour, nil)
+//	monitor.Storage, err = goSpider.NewDirStorage("/var/www/snapshots")
+//	monitor.Webhook = goSpider.NewChangeWebhook("https://example.com/hooks/cases", os.Getenv("WEBHOOK_SECRET"))
+//	monitor.Webhook.SnapshotURL = "https://files.example.com/snapshots/"
+func NewChangeWebhook(url, secret string) *ChangeWebhook {
+	return &ChangeWebhook{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send posts the payload of the change.
+func (w *ChangeWebhook) Send(event ChangeEvent) error {
+	body, err := json.Marshal(w.Payload(event))
+	if err != nil {
+		return fmt.Errorf("error - failed to encode payload: %w", err)
+	}
+	header := http.Header{}
+	if w.Secret != "" {
+		header.Set("X-GoSpider-Signature", WebhookSignature(w.Secret, body))
+	}
+	_, err = postBody(w.Client, w.URL, body, header)
+	if err != nil {
+		return fmt.Errorf("error - failed to send webhook: %w", err)
+	}
+	return nil
+}
+
+// Payload returns the payload posted for the change.
+func (w *ChangeWebhook) Payload(event ChangeEvent) ChangePayload {
+	maxLines := w.MaxDiffLines
+	if maxLines <= 0 {
+		maxLines = DefaultWebhookDiffLines
+	}
+	payload := ChangePayload{
+		URL:          event.URL,
+		ChangedAt:    event.Current.Time,
+		PreviousHash: event.Previous.Hash,
+		CurrentHash:  event.Current.Hash,
+		Changes:      []string{},
+	}
+	for _, line := range event.Diff {
+		switch line.Op {
+		case DiffInsert:
+			payload.Added++
+		case DiffDelete:
+			payload.Removed++
+		default:
+			continue
+		}
+		if len(payload.Changes) < maxLines {
+			payload.Changes = append(payload.Changes, line.String())
+		}
+	}
+	if w.SnapshotURL != "" {
+		base := strings.TrimRight(w.SnapshotURL, "/") + "/"
+		payload.PreviousSnapshot = base + SnapshotKey(event.Previous)
+		payload.CurrentSnapshot = base + SnapshotKey(event.Current)
+	}
+	return payload
+}
+
+// WebhookSignature returns the signature of a webhook body, "sha256=" and the hex HMAC-SHA256 of the body, so the
+// receiver can check it with hmac.Equal.
+// Example:
+//
+//	if !hmac.Equal([]byte(r.Header.Get("X-GoSpider-Signature")), []byte(goSpider.WebhookSignature(secret, body))) {
+//		http.Error(w, "invalid signature", http.StatusUnauthorized)
+//	}
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SnapshotKey returns the storage key of the text of a snapshot: "snapshots/", a hash of the page URL and the hash
+// of the text.
+func SnapshotKey(snapshot Snapshot) string {
+	sum := sha256.Sum256([]byte(snapshot.URL))
+	return "snapshots/" + hex.EncodeToString(sum[:8]) + "/" + snapshot.Hash + ".txt"
+}
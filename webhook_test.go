@@ -0,0 +1,95 @@
+package goSpider
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"golang.org/x/net/html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangeWebhook(t *testing.T) {
+	var payloads []ChangePayload
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := r.Header.Get("X-GoSpider-Signature")
+		if !hmac.Equal([]byte(signature), []byte(WebhookSignature("secret", body))) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var payload ChangePayload
+		json.Unmarshal(body, &payload)
+		payloads = append(payloads, payload)
+		signatures = append(signatures, signature)
+	}))
+	defer server.Close()
+
+	pages := []string{
+		`<html><body><ul><li>Distributed</li></ul></body></html>`,
+		`<html><body><ul><li>Distributed</li><li>Sentence</li></ul></body></html>`,
+	}
+	check := 0
+	fetcher := FetcherFunc(func(url string) (*html.Node, error) {
+		page := pages[check]
+		check++
+		return html.Parse(strings.NewReader(page))
+	})
+
+	storage, err := NewDirStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor := NewMonitor(fetcher, time.Hour, nil)
+	monitor.Storage = storage
+	monitor.Webhook = NewChangeWebhook(server.URL, "secret")
+	monitor.Webhook.SnapshotURL = "https://files.example.com/"
+	monitor.Add("https://example.com/case")
+	for range pages {
+		monitor.CheckAll()
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 signed payload, got %d", len(payloads))
+	}
+	payload := payloads[0]
+	if payload.URL != "https://example.com/case" || payload.Added != 1 || payload.Removed != 0 ||
+		len(payload.Changes) != 1 || payload.Changes[0] != "+ Sentence" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	for _, link := range []string{payload.PreviousSnapshot, payload.CurrentSnapshot} {
+		key := strings.TrimPrefix(link, "https://files.example.com/")
+		if exists, err := storage.Exists(key); !exists || err != nil {
+			t.Errorf("Expected the snapshot %s to be stored: %v", link, err)
+		}
+	}
+	current, _ := storage.Get(strings.TrimPrefix(payload.CurrentSnapshot, "https://files.example.com/"))
+	if string(current) != "Distributed\nSentence" {
+		t.Errorf("Unexpected current snapshot: %q", current)
+	}
+
+	monitor.Webhook.Secret = "other"
+	err = monitor.Webhook.Send(ChangeEvent{URL: "https://example.com/case"})
+	if err == nil {
+		t.Errorf("Expected an error for a rejected signature")
+	}
+}
+
+func TestChangeWebhookPayload(t *testing.T) {
+	webhook := NewChangeWebhook("https://example.com/hook", "")
+	webhook.MaxDiffLines = 1
+	payload := webhook.Payload(ChangeEvent{
+		URL:  "https://example.com/case",
+		Diff: []DiffLine{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}},
+	})
+	if payload.Added != 1 || payload.Removed != 1 || len(payload.Changes) != 1 || payload.Changes[0] != "- b" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	if payload.PreviousSnapshot != "" {
+		t.Errorf("Expected no snapshot links without SnapshotURL, got %s", payload.PreviousSnapshot)
+	}
+}
@@ -0,0 +1,169 @@
+package goSpider
+
+import (
+	"context"
+	"fmt"
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	"time"
+)
+
+// Window is a page of the browser: a tab, or a window opened by a page, such as a window.open popup.
+type Window struct {
+	ID       string // the target id of the page, see AttachWindow
+	URL      string
+	Title    string
+	OpenerID string // the id of the page that opened it; empty if none
+}
+
+// WindowID returns the id of the page of nav, as in Window.ID.
+func (nav *Navigator) WindowID() (string, error) {
+	err := chromedp.Run(nav.Ctx)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to start browser: %v\n", err)
+		return "", fmt.Errorf("error - failed to start browser: %w", classifyError(err))
+	}
+	return string(chromedp.FromContext(nav.Ctx).Target.TargetID), nil
+}
+
+// Windows lists the pages of the browser of nav, including its own, with the popups they opened.
+// Example:
+//
+//	windows, err := nav.Windows()
+//	for _, window := range windows {
+//		fmt.Println(window.ID, window.URL, window.OpenerID)
+//	}
+func (nav *Navigator) Windows() ([]Window, error) {
+	targets, err := chromedp.Targets(nav.Ctx)
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to list windows: %v\n", err)
+		return nil, fmt.Errorf("error - failed to list windows: %w", classifyError(err))
+	}
+	var windows []Window
+	for _, info := range targets {
+		if info.Type != "page" {
+			continue
+		}
+		windows = append(windows, Window{
+			ID:       string(info.TargetID),
+			URL:      info.URL,
+			Title:    info.Title,
+			OpenerID: string(info.OpenerID),
+		})
+	}
+	return windows, nil
+}
+
+// AttachWindow returns a Navigator driving the page of the browser with the given id, with the same logger, timeout,
+// cookies, locators, cache, middleware and init scripts as nav. Closing it closes the page.
+// Example:
+//
+//	windows, err := nav.Windows()
+//	popup, err := nav.AttachWindow(windows[1].ID)
+//	defer popup.Close()
+func (nav *Navigator) AttachWindow(id string) (*Navigator, error) {
+	ctx, cancel := chromedp.NewContext(nav.Ctx, chromedp.WithTargetID(target.ID(id)))
+	err := chromedp.Run(ctx)
+	if err != nil {
+		cancel()
+		nav.Logger.Printf("Error - Failed to attach window %s: %v\n", id, err)
+		return nil, fmt.Errorf("error - failed to attach window %s: %w", id, classifyError(err))
+	}
+	window, err := nav.child(ctx, cancel)
+	if err != nil {
+		return nil, err
+	}
+	nav.Logger.Printf("Window %s attached\n", id)
+	return window, nil
+}
+
+// WaitForPopup runs trigger and waits up to timeout for the page of nav to open a popup, or a tab, and returns a
+// Navigator driving it, see AttachWindow.
+// Example:
+//
+//	popup, err := nav.WaitForPopup(10*time.Second, func() error {
+//		return nav.ClickButton(".SocialButton")
+//	})
+//	err = popup.FillField("#identifierId", email)
+//	err = popup.WaitForClose(time.Minute)
+func (nav *Navigator) WaitForPopup(timeout time.Duration, trigger func() error) (*Navigator, error) {
+	self, err := nav.WindowID()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(nav.Ctx, timeout)
+	defer cancel()
+	popups := chromedp.WaitNewTarget(ctx, func(info *target.Info) bool {
+		return info.Type == "page" && string(info.OpenerID) == self
+	})
+
+	err = trigger()
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case id := <-popups:
+		return nav.AttachWindow(string(id))
+	case <-ctx.Done():
+		nav.Logger.Printf("Error - No popup opened: %v\n", ctx.Err())
+		return nil, fmt.Errorf("error - no popup opened: %w", classifyError(ctx.Err()))
+	}
+}
+
+// ResizeWindow sets the size of the window of the page of nav, in pixels.
+// Example:
+//
+//	err := popup.ResizeWindow(1280, 800)
+func (nav *Navigator) ResizeWindow(width, height int64) error {
+	err := chromedp.Run(nav.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		id, _, err := browser.GetWindowForTarget().Do(ctx)
+		if err != nil {
+			return err
+		}
+		return browser.SetWindowBounds(id, &browser.Bounds{
+			Width:       width,
+			Height:      height,
+			WindowState: browser.WindowStateNormal,
+		}).Do(ctx)
+	}))
+	if err != nil {
+		nav.Logger.Printf("Error - Failed to resize window: %v\n", err)
+		return fmt.Errorf("error - failed to resize window: %w", classifyError(err))
+	}
+	nav.Logger.Printf("Window resized to %dx%d\n", width, height)
+	return nil
+}
+
+// WaitForClose waits up to timeout for the page of nav to be closed, e.g. by a login popup that closes itself once
+// the user is logged in.
+// Example:
+//
+//	err := popup.ClickButton("#submit")
+//	err = popup.WaitForClose(30 * time.Second)
+func (nav *Navigator) WaitForClose(timeout time.Duration) error {
+	id, err := nav.WindowID()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		windows, err := nav.Windows()
+		if err != nil {
+			return err
+		}
+		open := false
+		for _, window := range windows {
+			open = open || window.ID == id
+		}
+		if !open {
+			nav.Logger.Printf("Window %s closed\n", id)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			nav.Logger.Printf("Error - Window %s was not closed\n", id)
+			return withKind(ErrTimeout, fmt.Errorf("error - window %s was not closed after %v", id, timeout))
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
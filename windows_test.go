@@ -0,0 +1,89 @@
+package goSpider
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForPopup(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/popup.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	self, err := nav.WindowID()
+	if err != nil {
+		t.Fatalf("WindowID error: %v", err)
+	}
+
+	popup, err := nav.WaitForPopup(5*time.Second, func() error {
+		return nav.ClickButton("#openPopup")
+	})
+	if err != nil {
+		t.Fatalf("WaitForPopup error: %v", err)
+	}
+	defer popup.Close()
+	_, err = popup.WaitPageLoad()
+	if err != nil {
+		t.Fatalf("WaitPageLoad error: %v", err)
+	}
+	url, err := popup.GetCurrentURL()
+	if err != nil || url != server.URL+"/test.html" {
+		t.Errorf("Expected the popup to open /test.html, but got %s: %v", url, err)
+	}
+
+	id, err := popup.WindowID()
+	if err != nil {
+		t.Fatalf("WindowID error: %v", err)
+	}
+	windows, err := nav.Windows()
+	if err != nil {
+		t.Fatalf("Windows error: %v", err)
+	}
+	found := false
+	for _, window := range windows {
+		found = found || window.ID == id && window.OpenerID == self
+	}
+	if !found {
+		t.Errorf("Expected the popup %s opened by %s in %+v", id, self, windows)
+	}
+
+	err = popup.ResizeWindow(500, 400)
+	if err != nil {
+		t.Errorf("ResizeWindow error: %v", err)
+	}
+
+	err = popup.WaitForClose(300 * time.Millisecond)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout while the popup is open, but got %v", err)
+	}
+	_, err = popup.EvaluateScript("setTimeout(function() { window.close(); }, 100)")
+	if err != nil {
+		t.Fatalf("EvaluateScript error: %v", err)
+	}
+	err = popup.WaitForClose(5 * time.Second)
+	if err != nil {
+		t.Errorf("WaitForClose error: %v", err)
+	}
+}
+
+func TestWaitForPopupNoPopup(t *testing.T) {
+	server := startTestServer()
+	defer server.Close()
+
+	nav := setupNavigator(t)
+	err := nav.OpenURL(server.URL + "/popup.html")
+	if err != nil {
+		t.Fatalf("OpenURL error: %v", err)
+	}
+	_, err = nav.WaitForPopup(500*time.Millisecond, func() error {
+		return nav.ClickButton("#nothing")
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, but got %v", err)
+	}
+}